@@ -0,0 +1,325 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Command rpcgen generates dispatch adapters for RPC services, which let the server
+// invoke their methods without reflect.Call. Add a directive like the following next to
+// the service type and run go generate:
+//
+//	//go:generate go run github.com/base/go-ethereum-rpc/cmd/rpcgen -type MyService
+//
+// The generated file defines RPCAdapters on *MyService, implementing rpc.AdapterProvider.
+// Services must be registered as pointers for the adapters to be used. Methods which
+// can't be adapted, e.g. variadic ones and methods promoted from embedded fields, keep
+// using reflection.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const defaultRPCPackage = "github.com/base/go-ethereum-rpc/rpc"
+
+// skipMethods are methods which the rpc package doesn't expose.
+var skipMethods = map[string]bool{
+	"RPCAdapters":        true,
+	"CachePolicies":      true,
+	"OnConnectionClosed": true,
+}
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "service type to generate adapters for (required)")
+		dir      = flag.String("dir", ".", "directory of the package containing the type")
+		out      = flag.String("out", "", "output file (default <type>_rpcadapters.go in dir)")
+		rpcPkg   = flag.String("rpcpkg", defaultRPCPackage, "import path of the rpc package")
+	)
+	flag.Parse()
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = filepath.Join(*dir, strings.ToLower(*typeName)+"_rpcadapters.go")
+	}
+	code, err := generate(*dir, *typeName, *rpcPkg, filepath.Base(*out))
+	if err != nil {
+		fatalf("rpcgen: %v", err)
+	}
+	if err := os.WriteFile(*out, code, 0644); err != nil {
+		fatalf("rpcgen: %v", err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// method is an adaptable method of the service type.
+type method struct {
+	name    string
+	hasCtx  bool
+	params  []string // parameter types, not including the context
+	results int      // number of results
+	errOnly bool     // the single result is an error
+}
+
+// generate returns the adapters of the given type, defined in the package in dir. The
+// file named skip is ignored, so existing generated code doesn't interfere.
+func generate(dir, typeName, rpcPkg, skip string) ([]byte, error) {
+	fset := token.NewFileSet()
+	filter := func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != skip
+	}
+	pkgs, err := parser.ParseDir(fset, dir, filter, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		pkgName string
+		methods []method
+		imports = make(map[string]string) // package name -> import path
+	)
+	for name, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || receiverType(fn) != typeName {
+					continue
+				}
+				pkgName = name
+				m, ok := adaptable(fset, fn)
+				if !ok {
+					continue
+				}
+				methods = append(methods, m)
+				if err := addImports(imports, file, fn.Type.Params); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if pkgName == "" {
+		return nil, fmt.Errorf("no methods of type %s found in %s", typeName, dir)
+	}
+	slices.SortFunc(methods, func(a, b method) int { return strings.Compare(a.name, b.name) })
+	return render(pkgName, typeName, rpcPkg, imports, methods)
+}
+
+// receiverType returns the name of the receiver type of fn, or "" if fn is a function
+// or the receiver type is generic.
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return ""
+	}
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// adaptable checks whether fn is exposed by the rpc package and returns its description.
+// The rules are those of the rpc package: at most two results, and if there are two, the
+// second one must be an error.
+func adaptable(fset *token.FileSet, fn *ast.FuncDecl) (method, bool) {
+	m := method{name: fn.Name.Name}
+	if !fn.Name.IsExported() || skipMethods[m.name] || fn.Type.TypeParams != nil {
+		return m, false
+	}
+	for _, field := range fn.Type.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return m, false
+		}
+		typ := exprString(fset, field.Type)
+		for range max(len(field.Names), 1) {
+			m.params = append(m.params, typ)
+		}
+	}
+	if len(m.params) > 0 && m.params[0] == "context.Context" {
+		m.hasCtx, m.params = true, m.params[1:]
+	}
+	var results []string
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			typ := exprString(fset, field.Type)
+			for range max(len(field.Names), 1) {
+				results = append(results, typ)
+			}
+		}
+	}
+	m.results = len(results)
+	switch {
+	case len(results) > 2:
+		return m, false
+	case len(results) == 2 && (results[0] == "error" || results[1] != "error"):
+		return m, false
+	case len(results) == 1:
+		m.errOnly = results[0] == "error"
+	}
+	return m, true
+}
+
+// addImports records the imports of file used by the parameter types.
+func addImports(imports map[string]string, file *ast.File, params *ast.FieldList) error {
+	var err error
+	ast.Inspect(params, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		path, found := importPath(file, ident.Name)
+		if !found {
+			err = fmt.Errorf("%s: unknown package %s", file.Name.Name, ident.Name)
+			return false
+		}
+		if prev, ok := imports[ident.Name]; ok && prev != path {
+			err = fmt.Errorf("package name %s refers to both %s and %s", ident.Name, prev, path)
+			return false
+		}
+		imports[ident.Name] = path
+		return false
+	})
+	return err
+}
+
+// importPath finds the import of file with the given package name.
+func importPath(file *ast.File, name string) (string, bool) {
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return p, true
+			}
+		} else if packageName(p) == name {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// packageName guesses the name of a package from its import path.
+func packageName(importPath string) string {
+	name := path.Base(importPath)
+	if strings.HasPrefix(name, "v") && len(name) > 1 && unicode.IsDigit(rune(name[1])) {
+		name = path.Base(path.Dir(importPath)) // major version suffix
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+func render(pkgName, typeName, rpcPkg string, imports map[string]string, methods []method) ([]byte, error) {
+	imports["context"] = "context"
+	imports["reflect"] = "reflect"
+	imports[packageName(rpcPkg)] = rpcPkg
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	// Standard library imports come first, then the others, each sorted by path.
+	isStd := func(name string) bool {
+		first, _, _ := strings.Cut(imports[name], "/")
+		return !strings.Contains(first, ".")
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(imports[a], imports[b])
+	})
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by rpcgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	for i, name := range names {
+		if i > 0 && isStd(names[i-1]) && !isStd(name) {
+			b.WriteString("\n")
+		}
+		if packageName(imports[name]) == name {
+			fmt.Fprintf(&b, "\t%q\n", imports[name])
+		} else {
+			fmt.Fprintf(&b, "\t%s %q\n", name, imports[name])
+		}
+	}
+	rpc := packageName(rpcPkg)
+	fmt.Fprintf(&b, ")\n\n// RPCAdapters implements %s.AdapterProvider.\n", rpc)
+	fmt.Fprintf(&b, "func (rcvr *%s) RPCAdapters() map[string]%s.MethodAdapter {\n", typeName, rpc)
+	fmt.Fprintf(&b, "\treturn map[string]%s.MethodAdapter{\n", rpc)
+	for _, m := range methods {
+		fmt.Fprintf(&b, "\t\t%q: func(ctx context.Context, args []reflect.Value) (any, error) {\n", formatName(m.name))
+		args := make([]string, 0, len(m.params)+1)
+		if m.hasCtx {
+			args = append(args, "ctx")
+		}
+		for i, typ := range m.params {
+			fmt.Fprintf(&b, "\t\t\ta%d, _ := args[%d].Interface().(%s)\n", i, i, typ)
+			args = append(args, fmt.Sprintf("a%d", i))
+		}
+		call := fmt.Sprintf("rcvr.%s(%s)", m.name, strings.Join(args, ", "))
+		switch {
+		case m.results == 0:
+			fmt.Fprintf(&b, "\t\t\t%s\n\t\t\treturn nil, nil\n", call)
+		case m.results == 1 && m.errOnly:
+			fmt.Fprintf(&b, "\t\t\treturn nil, %s\n", call)
+		case m.results == 1:
+			fmt.Fprintf(&b, "\t\t\treturn %s, nil\n", call)
+		default:
+			fmt.Fprintf(&b, "\t\t\treturn %s\n", call)
+		}
+		fmt.Fprintf(&b, "\t\t},\n")
+	}
+	fmt.Fprintf(&b, "\t}\n}\n")
+	return format.Source(b.Bytes())
+}
+
+// formatName converts a method name to its RPC name, like the rpc package does.
+func formatName(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "calc")
+	code, err := generate(dir, "Calc", defaultRPCPackage, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join(dir, "calc_rpcadapters.go.golden")
+	if *update {
+		if err := os.WriteFile(golden, code, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(code) != string(want) {
+		t.Errorf("wrong output:\n%s\nwant:\n%s", code, want)
+	}
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	if _, err := generate(filepath.Join("testdata", "calc"), "Missing", defaultRPCPackage, ""); err == nil {
+		t.Fatal("expected error for unknown type")
+	}
+}
@@ -0,0 +1,33 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	big2 "math/big"
+	"time"
+
+	"github.com/base/go-ethereum-rpc/rpc"
+)
+
+type Calc struct{}
+
+func (c *Calc) Add(a, b int) int { return a + b }
+
+func (c *Calc) Div(ctx context.Context, a, b *big2.Int) (*big2.Int, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+	return new(big2.Int).Div(a, b), nil
+}
+
+func (c Calc) Sleep(d time.Duration) error { return nil }
+
+func (c *Calc) Reset() {}
+
+func (c *Calc) Block(n rpc.BlockNumber) (rpc.BlockNumber, error) { return n, nil }
+
+func (c *Calc) Sum(values ...int) int { return 0 }
+
+func (c *Calc) unexported() {}
+
+func (c *Calc) Bad() (int, int) { return 0, 0 }
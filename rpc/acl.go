@@ -0,0 +1,154 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ACLRule allows or denies calls of the matching methods to the matching principals.
+type ACLRule struct {
+	// Methods lists the methods the rule applies to. A pattern ending in '*' matches
+	// all methods with the given prefix, e.g. "admin_*" matches a namespace.
+	Methods []string
+
+	// Principals and Roles select the clients the rule applies to, by principal name and
+	// by role. If both are empty, the rule applies to all clients, including anonymous
+	// ones.
+	Principals []string
+	Roles      []string
+
+	Deny bool // deny matching calls instead of allowing them
+}
+
+// matches reports whether the rule applies to a call of method by p.
+func (r *ACLRule) matches(p *Principal, method string) bool {
+	if !matchMethod(r.Methods, method) {
+		return false
+	}
+	if len(r.Principals) == 0 && len(r.Roles) == 0 {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	return slices.Contains(r.Principals, p.Name) || slices.ContainsFunc(r.Roles, p.HasRole)
+}
+
+// ACL restricts the methods available to clients based on their principal, see
+// SetACL.
+type ACL struct {
+	// Authenticate determines the principal of requests. If nil, clients are anonymous
+	// unless they are authenticated by the token validator, see SetTokenValidator.
+	Authenticate Authenticator
+
+	// Rules are checked in order, the first rule matching a call decides whether it is
+	// allowed. Calls not matched by any rule are allowed if DefaultAllow is set.
+	Rules        []ACLRule
+	DefaultAllow bool
+
+	// DenyCode is the error code of denied calls. By default, denied calls fail with
+	// the "method not found" error (-32601), which doesn't reveal that the method
+	// exists.
+	DenyCode int
+}
+
+// SetACL restricts the methods available to HTTP, websocket and gRPC clients. The
+// principal of a client is determined by the authenticator of the ACL when the request
+// is received, or on the websocket handshake, and is available to methods through
+// PeerInfo. Calls are checked against the rules before their arguments are parsed. IPC
+// and in-process connections are not restricted, see SetIPCPolicy for IPC. Passing nil
+// removes the ACL.
+func (s *Server) SetACL(acl *ACL) {
+	if acl != nil {
+		acl = &ACL{
+			Authenticate: acl.Authenticate,
+			Rules:        slices.Clone(acl.Rules),
+			DefaultAllow: acl.DefaultAllow,
+			DenyCode:     acl.DenyCode,
+		}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.acl = acl })
+}
+
+// authenticate checks the token of r, see SetTokenValidator, and determines the
+// principal of r. When authentication fails, it writes an error response using fail and
+// returns false.
+func (s *Server) authenticate(r *http.Request, fail func(msg string)) (*Principal, bool) {
+	var (
+		cfg = s.services.snapshot()
+		p   *Principal
+		err error
+	)
+	if v := cfg.tokenValidator; v != nil {
+		if token, ok := bearerToken(r); ok {
+			p, err = v.principal(token, time.Now())
+		} else {
+			err = errMissingToken
+		}
+	}
+	if err == nil && cfg.acl != nil && cfg.acl.Authenticate != nil {
+		var ap *Principal
+		if ap, err = cfg.acl.Authenticate(r); ap != nil {
+			p = ap
+		}
+	}
+	if err != nil {
+		log.Debug("RPC request authentication failed", "remote", r.RemoteAddr, "err", err)
+		fail("authentication failed: " + err.Error())
+		return nil, false
+	}
+	return p, true
+}
+
+// aclCheck returns the error of a call of method made by peer, or nil if the call is
+// allowed.
+func (c *registryConfig) aclCheck(peer PeerInfo, method string) error {
+	acl := c.acl
+	if acl == nil {
+		return nil
+	}
+	switch peer.Transport {
+	case "http", "ws", "grpc":
+	default:
+		return nil
+	}
+	allow := acl.DefaultAllow
+	for i := range acl.Rules {
+		if acl.Rules[i].matches(peer.Principal, method) {
+			allow = !acl.Rules[i].Deny
+			break
+		}
+	}
+	if allow {
+		return nil
+	}
+	if acl.DenyCode != 0 {
+		return &accessDeniedError{acl.DenyCode}
+	}
+	return &methodNotFoundError{method: method}
+}
+
+type accessDeniedError struct{ code int }
+
+func (e *accessDeniedError) Error() string { return errMsgAccessDenied }
+
+func (e *accessDeniedError) ErrorCode() int { return e.code }
@@ -0,0 +1,212 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func makeTestJWT(secret []byte, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	s := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(s))
+	return s + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func dialACLTest(t *testing.T, url string, opts ...ClientOption) *Client {
+	client, err := DialOptions(context.Background(), url, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func wantCallError(t *testing.T, err error, code int) {
+	t.Helper()
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected RPC error with code %d, got %v", code, err)
+	}
+	if rpcErr.ErrorCode() != code {
+		t.Fatalf("wrong error code %d, want %d (%v)", rpcErr.ErrorCode(), code, err)
+	}
+}
+
+func TestACLRoles(t *testing.T) {
+	t.Parallel()
+
+	acl := &ACL{
+		Authenticate: APIKeyAuthenticator("X-API-Key", map[string]Principal{
+			"admin-key": {Name: "alice", Roles: []string{"admin"}},
+			"user-key":  {Name: "bob"},
+		}),
+		Rules: []ACLRule{
+			{Methods: []string{"test_peerInfo"}},
+			{Methods: []string{"test_*"}, Roles: []string{"admin"}},
+			{Methods: []string{"test_echo"}, Principals: []string{"bob"}},
+		},
+	}
+	server := newTestServer()
+	defer server.Stop()
+	server.SetACL(acl)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	admin := dialACLTest(t, httpsrv.URL, WithHeader("X-API-Key", "admin-key"))
+	if err := admin.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal("admin call failed:", err)
+	}
+	var info PeerInfo
+	if err := admin.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Principal == nil || info.Principal.Name != "alice" || !info.Principal.HasRole("admin") {
+		t.Fatalf("wrong principal %+v", info.Principal)
+	}
+
+	user := dialACLTest(t, httpsrv.URL, WithHeader("X-API-Key", "user-key"))
+	var res echoResult
+	if err := user.Call(&res, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal("user call failed:", err)
+	}
+	wantCallError(t, user.Call(nil, "test_noArgsRets"), -32601)
+
+	anon := dialACLTest(t, httpsrv.URL)
+	if err := anon.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Principal != nil {
+		t.Fatalf("anonymous client has principal %+v", info.Principal)
+	}
+	wantCallError(t, anon.Call(&res, "test_echo", "x", 1, nil), -32601)
+
+	// Unknown keys are rejected before any call is processed.
+	bad := dialACLTest(t, httpsrv.URL, WithHeader("X-API-Key", "wrong"))
+	var httpErr HTTPError
+	if err := bad.Call(&info, "test_peerInfo"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 error, got %v", err)
+	}
+}
+
+func TestACLDenyCode(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetACL(&ACL{
+		Rules:        []ACLRule{{Methods: []string{"test_noArgsRets"}, Deny: true}},
+		DefaultAllow: true,
+		DenyCode:     -32040,
+	})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client := dialACLTest(t, httpsrv.URL)
+	err := client.Call(nil, "test_noArgsRets")
+	wantCallError(t, err, -32040)
+	if !strings.Contains(err.Error(), errMsgAccessDenied) {
+		t.Fatalf("wrong error message %q", err)
+	}
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal("allowed call failed:", err)
+	}
+}
+
+func TestACLJWTWebsocket(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	httpsrv := httptest.NewServer(nil)
+	server := newTestServer()
+	server.SetACL(&ACL{
+		Authenticate: JWTAuthenticator(secret, "roles"),
+		Rules:        []ACLRule{{Methods: []string{"test_*"}, Roles: []string{"reader"}}},
+	})
+	httpsrv.Config.Handler = server.WebsocketHandler([]string{"*"})
+	defer httpsrv.Close()
+	defer server.Stop()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	token := makeTestJWT(secret, map[string]interface{}{
+		"sub":   "carol",
+		"roles": "reader writer",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	client := dialACLTest(t, wsURL, WithHeader("Authorization", "Bearer "+token))
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Principal == nil || info.Principal.Name != "carol" || !info.Principal.HasRole("writer") {
+		t.Fatalf("wrong principal %+v", info.Principal)
+	}
+
+	// Anonymous clients connect, but their calls are denied.
+	anon := dialACLTest(t, wsURL)
+	wantCallError(t, anon.Call(&info, "test_peerInfo"), -32601)
+
+	// Expired tokens fail the handshake.
+	expired := makeTestJWT(secret, map[string]interface{}{"sub": "carol", "exp": time.Now().Add(-time.Minute).Unix()})
+	if _, err := DialOptions(context.Background(), wsURL, WithHeader("Authorization", "Bearer "+expired)); err == nil {
+		t.Fatal("dial with expired token succeeded")
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+	valid := makeTestJWT(secret, map[string]interface{}{"sub": "x", "nbf": now.Add(-time.Minute).Unix()})
+	if _, err := verifyJWT(valid, [][]byte{secret}, now); err != nil {
+		t.Fatal("valid token rejected:", err)
+	}
+	tests := map[string]string{
+		"signature": makeTestJWT([]byte("other"), map[string]interface{}{"sub": "x"}),
+		"nbf":       makeTestJWT(secret, map[string]interface{}{"nbf": now.Add(time.Minute).Unix()}),
+		"malformed": "abc.def",
+		"alg":       base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30.",
+	}
+	for name, token := range tests {
+		if _, err := verifyJWT(token, [][]byte{secret}, now); err == nil {
+			t.Errorf("%s: invalid token accepted", name)
+		}
+	}
+}
+
+func TestACLInProcUnrestricted(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetACL(&ACL{})
+	client := DialInProc(server)
+	defer client.Close()
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal("in-process call denied:", err)
+	}
+}
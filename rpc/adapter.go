@@ -0,0 +1,55 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// MethodAdapter invokes a method of a service receiver without reflect.Call. It receives
+// the decoded arguments of the call, not including the context, in the order of the
+// method's parameters, and returns the method's result and error.
+type MethodAdapter func(ctx context.Context, args []reflect.Value) (any, error)
+
+// AdapterProvider is implemented by services with generated dispatch adapters, see
+// cmd/rpcgen. RPCAdapters is called when the service is registered, and the returned
+// map is keyed by RPC method name without the namespace, like for CachePolicyProvider.
+// Calls of methods with an adapter are dispatched through it instead of reflect.Call,
+// which is considerably cheaper. Argument decoding, middlewares and validation work the
+// same as for other methods. RPCAdapters itself is not exposed as an RPC method.
+type AdapterProvider interface {
+	RPCAdapters() map[string]MethodAdapter
+}
+
+// attachAdapters makes the callbacks of rcvr use its generated adapters.
+func attachAdapters(rcvr any, callbacks map[string]*callback) error {
+	provider, ok := rcvr.(AdapterProvider)
+	if !ok {
+		return nil
+	}
+	delete(callbacks, "rPCAdapters")
+	for method, adapter := range provider.RPCAdapters() {
+		cb := callbacks[method]
+		if cb == nil {
+			return fmt.Errorf("adapter for unknown method %s of %T", method, rcvr)
+		}
+		cb.adapter = adapter
+	}
+	return nil
+}
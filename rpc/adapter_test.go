@@ -0,0 +1,151 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type adaptedService struct {
+	adapted atomic.Int32
+}
+
+func (s *adaptedService) Add(a, b int) int { return a + b }
+
+func (s *adaptedService) Div(ctx context.Context, a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+func (s *adaptedService) Opt(p *int) bool { return p != nil }
+
+func (s *adaptedService) Crash() { panic("boom") }
+
+// RPCAdapters is written like the output of cmd/rpcgen, and counts adapter calls.
+func (s *adaptedService) RPCAdapters() map[string]MethodAdapter {
+	return map[string]MethodAdapter{
+		"add": func(ctx context.Context, args []reflect.Value) (any, error) {
+			s.adapted.Add(1)
+			a0, _ := args[0].Interface().(int)
+			a1, _ := args[1].Interface().(int)
+			return s.Add(a0, a1), nil
+		},
+		"div": func(ctx context.Context, args []reflect.Value) (any, error) {
+			s.adapted.Add(1)
+			a0, _ := args[0].Interface().(int)
+			a1, _ := args[1].Interface().(int)
+			return s.Div(ctx, a0, a1)
+		},
+		"opt": func(ctx context.Context, args []reflect.Value) (any, error) {
+			s.adapted.Add(1)
+			a0, _ := args[0].Interface().(*int)
+			return s.Opt(a0), nil
+		},
+		"crash": func(ctx context.Context, args []reflect.Value) (any, error) {
+			s.adapted.Add(1)
+			s.Crash()
+			return nil, nil
+		},
+	}
+}
+
+func TestAdapterDispatch(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(adaptedService)
+	if err := server.RegisterName("calc", svc); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var sum int
+	if err := client.Call(&sum, "calc_add", 1, 2); err != nil || sum != 3 {
+		t.Fatalf("calc_add: %d, %v", sum, err)
+	}
+	if err := client.Call(&sum, "calc_div", 1, 0); err == nil || err.Error() != "division by zero" {
+		t.Fatalf("wrong calc_div error: %v", err)
+	}
+	var present bool
+	if err := client.Call(&present, "calc_opt"); err != nil || present {
+		t.Fatalf("calc_opt without argument: %v, %v", present, err)
+	}
+	if err := client.Call(&present, "calc_opt", 1); err != nil || !present {
+		t.Fatalf("calc_opt with argument: %v, %v", present, err)
+	}
+	wantCallError(t, client.Call(nil, "calc_crash"), errcodePanic)
+	if n := svc.adapted.Load(); n != 5 {
+		t.Fatalf("adapters called %d times, want 5", n)
+	}
+
+	// RPCAdapters is not a method of the service.
+	wantCallError(t, client.Call(nil, "calc_rPCAdapters"), -32601)
+}
+
+type badAdapterService struct{}
+
+func (badAdapterService) Echo(s string) string { return s }
+
+func (badAdapterService) RPCAdapters() map[string]MethodAdapter {
+	return map[string]MethodAdapter{"missing": nil}
+}
+
+func TestAdapterUnknownMethod(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	err := server.RegisterName("bad", badAdapterService{})
+	if err == nil || !strings.Contains(err.Error(), "adapter for unknown method missing") {
+		t.Fatalf("wrong error %v", err)
+	}
+}
+
+// BenchmarkAdapterCall compares method invocation through reflect.Call with a
+// generated adapter.
+func BenchmarkAdapterCall(b *testing.B) {
+	svc := new(adaptedService)
+	callbacks, err := receiverCallbacks(svc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cb := callbacks["add"]
+	args := []reflect.Value{reflect.ValueOf(1), reflect.ValueOf(2)}
+	ctx := context.Background()
+
+	b.Run("reflect", func(b *testing.B) {
+		reflective := *cb
+		reflective.adapter = nil
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reflective.call(ctx, "calc_add", args)
+		}
+	})
+	b.Run("adapter", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cb.call(ctx, "calc_add", args)
+		}
+	})
+}
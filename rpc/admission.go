@@ -0,0 +1,99 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const grpcStatusPermissionDenied = 7
+
+// AdmissionHook decides whether a connection or HTTP request is served. It is invoked
+// with the peer information and, for HTTP, websocket and gRPC, the request headers
+// before any message is read. Returning an error rejects the peer.
+//
+// The hook may attach custom data to the peer by adding entries to info.Metadata,
+// which is non-nil when the hook is invoked. Methods and middlewares can read it using
+// PeerInfoFromContext. Metadata is kept for the built-in transports.
+//
+// HTTP and websocket handshake requests are rejected with status 403 Forbidden. If the
+// error is an HTTPError, its status code and headers are used instead, e.g. to send
+// 429 Too Many Requests with a Retry-After header.
+//
+// To limit the number of connections per IP address, the hook can count the open
+// connections from the host of info.RemoteAddr using Server.Peers.
+type AdmissionHook func(info PeerInfo, header http.Header) error
+
+// SetAdmissionHook sets the hook which admits new connections and HTTP requests.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetAdmissionHook(hook AdmissionHook) {
+	s.admission = hook
+}
+
+// admit runs the admission hook for a new peer. Metadata attached by the hook is
+// stored in info.
+func (s *Server) admit(info *PeerInfo, header http.Header) error {
+	if s.admission == nil {
+		return nil
+	}
+	info.Metadata = make(map[string]any)
+	err := s.admission(*info, header)
+	if len(info.Metadata) == 0 {
+		info.Metadata = nil
+	}
+	if err != nil {
+		log.Debug("Rejected RPC peer", "transport", info.Transport, "addr", info.RemoteAddr, "err", err)
+	}
+	return err
+}
+
+// admitCodec runs the admission hook for a connection served by ServeCodec. Websocket
+// and gRPC connections are admitted during the HTTP handshake.
+func (s *Server) admitCodec(codec ServerCodec) bool {
+	switch codec := codec.(type) {
+	case *websocketCodec, *grpcCodec:
+		return true
+	case *jsonCodec:
+		info := codec.peerInfo()
+		if err := s.admit(&info, nil); err != nil {
+			return false
+		}
+		codec.metadata = info.Metadata
+		return true
+	default:
+		info := codec.peerInfo()
+		return s.admit(&info, nil) == nil
+	}
+}
+
+// rejectAdmission writes the HTTP response rejecting a peer.
+func rejectAdmission(w http.ResponseWriter, err error) {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode != 0 {
+		for key, values := range httpErr.Header {
+			w.Header()[key] = values
+		}
+		http.Error(w, err.Error(), httpErr.StatusCode)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusForbidden)
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdmissionHookHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetAdmissionHook(func(info PeerInfo, header http.Header) error {
+		if info.Transport != "http" {
+			t.Errorf("wrong transport %q", info.Transport)
+		}
+		switch key := header.Get("X-Api-Key"); key {
+		case "":
+			return errors.New("missing API key")
+		case "limited":
+			h := make(http.Header)
+			h.Set("Retry-After", "10")
+			return HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests", Header: h}
+		default:
+			info.Metadata["key"] = key
+			return nil
+		}
+	})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	post := func(key string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-Api-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := post(""); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("wrong status without key: %d", resp.StatusCode)
+	}
+	resp := post("limited")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("wrong status for limited key: %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") != "10" {
+		t.Fatalf("missing Retry-After header: %v", resp.Header)
+	}
+
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.SetHeader("X-Api-Key", "secret")
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Metadata["key"] != "secret" {
+		t.Fatalf("wrong metadata: %v", info.Metadata)
+	}
+}
+
+func TestAdmissionHookWebsocketConnLimit(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetAdmissionHook(func(info PeerInfo, header http.Header) error {
+		host, _, _ := net.SplitHostPort(info.RemoteAddr)
+		for _, p := range server.Peers() {
+			if h, _, _ := net.SplitHostPort(p.Info.RemoteAddr); h == host {
+				return HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}
+			}
+		}
+		info.Metadata["tier"] = "free"
+		return nil
+	})
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Transport != "ws" || info.Metadata["tier"] != "free" {
+		t.Fatalf("wrong peer info: %+v", info)
+	}
+
+	// The first connection is open, so a second one from the same address is rejected.
+	if _, err := DialWebsocket(context.Background(), wsURL, ""); err == nil {
+		t.Fatal("second connection was admitted")
+	}
+}
+
+func TestAdmissionHookCodec(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetAdmissionHook(func(info PeerInfo, header http.Header) error {
+		if header != nil {
+			t.Errorf("unexpected header %v", header)
+		}
+		info.Metadata["local"] = true
+		return nil
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Metadata["local"] != true {
+		t.Fatalf("wrong metadata: %v", info.Metadata)
+	}
+
+	server.SetAdmissionHook(func(PeerInfo, http.Header) error { return errors.New("rejected") })
+	rejected := DialInProc(server)
+	defer rejected.Close()
+	if err := rejected.Call(&info, "test_peerInfo"); err == nil {
+		t.Fatal("call on rejected connection succeeded")
+	}
+}
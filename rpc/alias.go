@@ -0,0 +1,147 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// AliasOption configures a method alias, see Server.Alias.
+type AliasOption interface {
+	applyAliasOption(*methodAlias)
+}
+
+type aliasOptionFunc func(*methodAlias)
+
+func (fn aliasOptionFunc) applyAliasOption(a *methodAlias) {
+	fn(a)
+}
+
+// DeprecationWarning marks an alias as deprecated. Calls of the alias still work, but
+// their responses carry a DeprecationNotice in the "ext" member and the deprecation
+// hook of the server is invoked, see Server.SetDeprecationHook.
+var DeprecationWarning AliasOption = aliasOptionFunc(func(a *methodAlias) { a.deprecated = true })
+
+// DeprecationMessage marks an alias as deprecated like DeprecationWarning and sets the
+// message of its notices, e.g. the date at which the alias will be removed.
+func DeprecationMessage(msg string) AliasOption {
+	return aliasOptionFunc(func(a *methodAlias) { a.deprecated, a.message = true, msg })
+}
+
+// DeprecationNotice is the warning attached to responses of deprecated calls.
+type DeprecationNotice struct {
+	Method      string `json:"method"`            // the deprecated method that was called
+	Replacement string `json:"replacement"`       // the method that served the call
+	Message     string `json:"message,omitempty"` // see DeprecationMessage
+}
+
+// DeprecationHook is invoked for calls of deprecated aliases, see SetDeprecationHook.
+type DeprecationHook func(ctx context.Context, notice DeprecationNotice)
+
+// methodAlias is an alternative name of a method.
+type methodAlias struct {
+	target     string
+	deprecated bool
+	message    string
+}
+
+// Alias makes name an alias of method target, so that calls of name are served by
+// target. This keeps renamed methods working for existing clients. Aliases take
+// precedence over methods registered under the same name, and apply to all kinds of
+// methods including those of factories and proxies. Access control, limits and other
+// per-method settings of target apply to calls of the alias.
+//
+// If the alias is deprecated, see DeprecationWarning, responses to its calls carry a
+// DeprecationNotice in their "ext" member. Clients can observe notices using
+// WithDeprecationHandler.
+func (s *Server) Alias(name, target string, opts ...AliasOption) error {
+	if !strings.Contains(name, serviceMethodSeparator) || !strings.Contains(target, serviceMethodSeparator) {
+		return fmt.Errorf("invalid alias %q of %q: names must include a namespace", name, target)
+	}
+	if name == target {
+		return fmt.Errorf("method %s can't be an alias of itself", name)
+	}
+	alias := methodAlias{target: target}
+	for _, opt := range opts {
+		opt.applyAliasOption(&alias)
+	}
+	var err error
+	s.services.updateConfig(func(cfg *registryConfig) {
+		if _, ok := cfg.aliases[target]; ok {
+			err = fmt.Errorf("%s is an alias itself", target)
+			return
+		}
+		for old, a := range cfg.aliases {
+			if a.target == name {
+				err = fmt.Errorf("%s is the target of alias %s", name, old)
+				return
+			}
+		}
+		aliases := maps.Clone(cfg.aliases)
+		if aliases == nil {
+			aliases = make(map[string]methodAlias)
+		}
+		aliases[name] = alias
+		cfg.aliases = aliases
+	})
+	return err
+}
+
+// SetDeprecationHook sets a function which is invoked for every call of a deprecated
+// alias, e.g. to log or count the callers that still need to migrate.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetDeprecationHook(fn DeprecationHook) {
+	s.services.updateConfig(func(cfg *registryConfig) { cfg.deprecationHook = fn })
+}
+
+// WithDeprecationHandler configures a function which is invoked with the deprecation
+// notice of every response that carries one, see Server.Alias.
+func WithDeprecationHandler(fn func(notice DeprecationNotice)) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.deprecationFn = fn
+	})
+}
+
+// resolveAlias returns the message to process for msg, which calls the target method
+// if msg calls an alias. The returned notice is non-nil for deprecated aliases.
+func (cfg *registryConfig) resolveAlias(msg *jsonrpcMessage) (*jsonrpcMessage, *DeprecationNotice) {
+	alias, ok := cfg.aliases[msg.Method]
+	if !ok {
+		return msg, nil
+	}
+	resolved := *msg
+	resolved.Method = alias.target
+	if !alias.deprecated {
+		return &resolved, nil
+	}
+	return &resolved, &DeprecationNotice{Method: msg.Method, Replacement: alias.target, Message: alias.message}
+}
+
+// reportDeprecation passes the deprecation notice of resp to the configured callback.
+func (c *Client) reportDeprecation(resp *jsonrpcMessage) {
+	if c.deprecationFn == nil {
+		return
+	}
+	if notice := resp.responseExt().Deprecation; notice != nil {
+		c.deprecationFn(*notice)
+	}
+}
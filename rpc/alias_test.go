@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.Alias("test_oldEcho", "test_echo"); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu      sync.Mutex
+		notices []DeprecationNotice
+	)
+	client := dialInProcWithOptions(server, WithDeprecationHandler(func(n DeprecationNotice) {
+		mu.Lock()
+		notices = append(notices, n)
+		mu.Unlock()
+	}))
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_oldEcho", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := (echoResult{"x", 1, nil}); !reflect.DeepEqual(result, want) {
+		t.Fatalf("wrong result %v, want %v", result, want)
+	}
+	if len(notices) != 0 {
+		t.Fatalf("unexpected deprecation notices %v", notices)
+	}
+}
+
+func TestAliasDeprecation(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.Alias("test_oldEcho", "test_echo", DeprecationMessage("removed in v2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Alias("test_oldError", "test_returnError", DeprecationWarning); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu     sync.Mutex
+		hooked []DeprecationNotice
+		seen   []DeprecationNotice
+	)
+	server.SetDeprecationHook(func(ctx context.Context, n DeprecationNotice) {
+		mu.Lock()
+		hooked = append(hooked, n)
+		mu.Unlock()
+	})
+	client := dialInProcWithOptions(server, WithDeprecationHandler(func(n DeprecationNotice) {
+		mu.Lock()
+		seen = append(seen, n)
+		mu.Unlock()
+	}))
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_oldEcho", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	err := client.Call(nil, "test_oldError")
+	wantCallError(t, err, 444)
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DeprecationNotice{
+		{Method: "test_oldEcho", Replacement: "test_echo", Message: "removed in v2"},
+		{Method: "test_oldError", Replacement: "test_returnError"},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(hooked, want) {
+		t.Errorf("wrong hook notices %v, want %v", hooked, want)
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("wrong client notices %v, want %v", seen, want)
+	}
+}
+
+func TestAliasErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.Alias("test_a", "test_echo"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range [][2]string{
+		{"a", "test_echo"},           // no namespace
+		{"test_echo", "test_echo"},   // self
+		{"test_b", "test_a"},         // chained
+		{"test_echo", "test_repeat"}, // target of another alias
+	} {
+		if err := server.Alias(tt[0], tt[1]); err == nil {
+			t.Errorf("Alias(%q, %q) succeeded", tt[0], tt[1])
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// APIVersionHeader is the HTTP request header in which clients select the versions of
+// versioned namespaces, as a comma-separated list of namespace=version pairs, e.g.
+// "eth=2, debug=1". It is accepted on HTTP requests and websocket handshakes.
+const APIVersionHeader = "X-Rpc-Api-Version"
+
+const (
+	apiVersionSeparator  = "@"
+	selectVersionsMethod = MetadataApi + "_selectVersions"
+)
+
+var errInvalidVersion = errors.New("invalid API version")
+
+type apiVersionsKey struct{}
+
+// parseVersionedName splits a service name of the form "namespace@version". Names
+// without version are version 1.
+func parseVersionedName(name string) (namespace string, version int, err error) {
+	namespace, v, found := strings.Cut(name, apiVersionSeparator)
+	if !found {
+		return name, 1, nil
+	}
+	version, err = strconv.Atoi(v)
+	if err != nil || version < 1 || namespace == "" {
+		return "", 0, fmt.Errorf("%w in service name %q", errInvalidVersion, name)
+	}
+	return namespace, version, nil
+}
+
+// versionedName returns the name under which version of namespace is registered.
+// Version 1 is registered under the plain namespace.
+func versionedName(namespace string, version int) string {
+	if version == 1 {
+		return namespace
+	}
+	return namespace + apiVersionSeparator + strconv.Itoa(version)
+}
+
+// SetDefaultVersion sets the version of a versioned namespace which serves callers that
+// haven't selected a version. By default, this is the unversioned registration of the
+// namespace if there is one, and the lowest registered version otherwise. Changing the
+// default completes a migration to a new version, while clients can still select the
+// old one. Passing a version of zero restores the default.
+func (s *Server) SetDefaultVersion(namespace string, version int) {
+	r := &s.services
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version == 0 {
+		delete(r.defaultVersions, namespace)
+		return
+	}
+	if r.defaultVersions == nil {
+		r.defaultVersions = make(map[string]int)
+	}
+	r.defaultVersions[namespace] = version
+}
+
+// WithAPIVersions selects versions of versioned namespaces, see Server.RegisterName.
+// The selection is sent in the APIVersionHeader, so it applies to HTTP and WebSocket
+// connections. On other transports, call rpc_selectVersions with the versions instead.
+func WithAPIVersions(versions map[string]int) ClientOption {
+	return WithHeader(APIVersionHeader, formatAPIVersions(versions))
+}
+
+// formatAPIVersions renders versions as the value of APIVersionHeader.
+func formatAPIVersions(versions map[string]int) string {
+	pairs := make([]string, 0, len(versions))
+	for ns, v := range versions {
+		pairs = append(pairs, ns+"="+strconv.Itoa(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// parseAPIVersions parses the value of APIVersionHeader. Malformed pairs are ignored.
+func parseAPIVersions(v string) map[string]int {
+	var versions map[string]int
+	for _, pair := range strings.Split(v, ",") {
+		ns, version, ok := strings.Cut(pair, "=")
+		ns = strings.TrimSpace(ns)
+		if !ok || ns == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(version))
+		if err != nil || n < 1 {
+			continue
+		}
+		if versions == nil {
+			versions = make(map[string]int)
+		}
+		versions[ns] = n
+	}
+	return versions
+}
+
+// registerVersion records a versioned registration of namespace.
+func (r *serviceRegistry) registerVersion(namespace string, version int) {
+	if version == 1 || slices.Contains(r.apiVersions[namespace], version) {
+		return
+	}
+	if r.apiVersions == nil {
+		r.apiVersions = make(map[string][]int)
+	}
+	r.apiVersions[namespace] = append(r.apiVersions[namespace], version)
+	slices.Sort(r.apiVersions[namespace])
+}
+
+// serviceName returns the name of the service which serves calls to namespace for a
+// caller which selected the given versions. Namespaces with an explicit version, such
+// as "eth@2", address that version directly. r.mu must be held.
+func (r *serviceRegistry) serviceName(namespace string, selected map[string]int) string {
+	if strings.Contains(namespace, apiVersionSeparator) {
+		if ns, v, err := parseVersionedName(namespace); err == nil {
+			return versionedName(ns, v)
+		}
+		return namespace
+	}
+	if v, ok := selected[namespace]; ok {
+		return versionedName(namespace, v)
+	}
+	versions := r.apiVersions[namespace]
+	if len(versions) == 0 {
+		return namespace
+	}
+	if v, ok := r.defaultVersions[namespace]; ok {
+		return versionedName(namespace, v)
+	}
+	if _, ok := r.services[namespace]; ok {
+		return namespace
+	}
+	return versionedName(namespace, versions[0])
+}
+
+// versions returns the registered versions of all versioned namespaces.
+func (r *serviceRegistry) versions() map[string][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make(map[string][]int, len(r.apiVersions))
+	for ns, versions := range r.apiVersions {
+		if _, ok := r.services[ns]; ok {
+			all[ns] = append([]int{1}, versions...)
+		} else {
+			all[ns] = slices.Clone(versions)
+		}
+	}
+	return all
+}
+
+// hasVersion reports whether version of namespace is registered.
+func (r *serviceRegistry) hasVersion(namespace string, version int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.services[versionedName(namespace, version)]
+	return ok
+}
+
+// ApiVersions returns the registered versions of all versioned namespaces. A version
+// can be selected with the APIVersionHeader or rpc_selectVersions.
+func (s *RPCService) ApiVersions() map[string][]int {
+	return s.server.services.versions()
+}
+
+// selectedVersions returns the versions selected by the caller of a method, either for
+// the connection with rpc_selectVersions, or in the APIVersionHeader. The connection
+// selection takes precedence.
+func (h *handler) selectedVersions(ctx context.Context) map[string]int {
+	fromHeader, _ := ctx.Value(apiVersionsKey{}).(map[string]int)
+	conn := h.versions.Load()
+	switch {
+	case conn == nil:
+		return fromHeader
+	case len(fromHeader) == 0:
+		return *conn
+	}
+	merged := make(map[string]int, len(fromHeader)+len(*conn))
+	for ns, v := range fromHeader {
+		merged[ns] = v
+	}
+	for ns, v := range *conn {
+		merged[ns] = v
+	}
+	return merged
+}
+
+// handleSelectVersions processes rpc_selectVersions calls, which select versions of
+// namespaces for subsequent calls on the connection. It returns the selection in effect
+// for the connection.
+func (h *handler) handleSelectVersions(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	args, err := parsePositionalArguments(msg.Params, []reflect.Type{reflect.TypeOf(map[string]int{})}, h.decodeConfig())
+	if err != nil {
+		return msg.errorResponse(&invalidParamsError{err.Error()})
+	}
+	requested, _ := args[0].Interface().(map[string]int)
+	for ns, v := range requested {
+		if !h.reg.hasVersion(ns, v) {
+			return msg.errorResponse(&invalidParamsError{fmt.Sprintf("%v %s%s%d", errInvalidVersion, ns, apiVersionSeparator, v)})
+		}
+	}
+	for {
+		old := h.versions.Load()
+		selected := make(map[string]int, len(requested))
+		if old != nil {
+			for ns, v := range *old {
+				selected[ns] = v
+			}
+		}
+		for ns, v := range requested {
+			selected[ns] = v
+		}
+		if h.versions.CompareAndSwap(old, &selected) {
+			return msg.response(h.selectedVersions(cp.ctx))
+		}
+	}
+}
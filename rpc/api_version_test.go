@@ -0,0 +1,192 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"maps"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type versionTestServiceV1 struct{}
+
+func (versionTestServiceV1) Balance(account string) string { return "v1:" + account }
+
+func (versionTestServiceV1) Legacy() string { return "legacy" }
+
+type versionTestServiceV2 struct{}
+
+func (versionTestServiceV2) Balance(account string, block int) string {
+	return "v2:" + account
+}
+
+func checkBalance(t *testing.T, client *Client, method string, want string, args ...interface{}) {
+	t.Helper()
+	var result string
+	if err := client.Call(&result, method, args...); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	if result != want {
+		t.Fatalf("%s: result %q, want %q", method, result, want)
+	}
+}
+
+func TestAPIVersionSelect(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("acct", versionTestServiceV1{})
+	server.RegisterName("acct@2", versionTestServiceV2{})
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Callers which don't select a version get version 1.
+	checkBalance(t, client, "acct_balance", "v1:a", "a")
+	checkBalance(t, client, "acct@2_balance", "v2:a", "a", 1)
+	checkBalance(t, client, "acct@1_balance", "v1:a", "a")
+
+	// Selecting version 2 applies to all further calls on the connection.
+	var selected map[string]int
+	if err := client.Call(&selected, "rpc_selectVersions", map[string]int{"acct": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(selected, map[string]int{"acct": 2}) {
+		t.Fatalf("wrong selection %v", selected)
+	}
+	checkBalance(t, client, "acct_balance", "v2:a", "a", 1)
+	if err := client.Call(nil, "acct_legacy"); err == nil {
+		t.Fatal("removed method callable in version 2")
+	}
+	// Subscriptions are looked up in the selected version too.
+	err := client.Call(nil, "acct_subscribe", "updates")
+	wantCallError(t, err, -32601)
+
+	// Unknown versions can't be selected.
+	err = client.Call(nil, "rpc_selectVersions", map[string]int{"acct": 3})
+	wantCallError(t, err, -32602)
+	err = client.Call(nil, "rpc_selectVersions", map[string]int{"nope": 2})
+	wantCallError(t, err, -32602)
+
+	// Other connections are not affected.
+	client2 := DialInProc(server)
+	defer client2.Close()
+	checkBalance(t, client2, "acct_balance", "v1:a", "a")
+}
+
+func TestAPIVersionDefault(t *testing.T) {
+	t.Parallel()
+
+	// Without an unversioned registration, the lowest version is the default.
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("acct@3", versionTestServiceV2{})
+	server.RegisterName("acct@2", versionTestServiceV2{})
+	client := DialInProc(server)
+	defer client.Close()
+	checkBalance(t, client, "acct_balance", "v2:a", "a", 1)
+
+	server.SetDefaultVersion("acct", 3)
+	checkBalance(t, client, "acct_balance", "v2:a", "a", 1)
+	var modules map[string]string
+	if err := client.Call(&modules, "rpc_modules"); err != nil {
+		t.Fatal(err)
+	}
+	if modules["acct"] != "3.0" {
+		t.Fatalf("wrong module version %q", modules["acct"])
+	}
+
+	server.SetDefaultVersion("acct", 0)
+	if err := client.Call(&modules, "rpc_modules"); err != nil {
+		t.Fatal(err)
+	}
+	if modules["acct"] != "2.0" {
+		t.Fatalf("wrong module version %q", modules["acct"])
+	}
+
+	var versions map[string][]int
+	if err := client.Call(&versions, "rpc_apiVersions"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(versions, map[string][]int{"acct": {2, 3}}) {
+		t.Fatalf("wrong versions %v", versions)
+	}
+}
+
+func TestAPIVersionHeader(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("acct", versionTestServiceV1{})
+	server.RegisterName("acct@2", versionTestServiceV2{})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+
+	for _, url := range []string{httpsrv.URL, "ws:" + strings.TrimPrefix(wssrv.URL, "http:")} {
+		client, err := DialOptions(context.Background(), url, WithAPIVersions(map[string]int{"acct": 2}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkBalance(t, client, "acct_balance", "v2:a", "a", 1)
+
+		// A selection made on the connection takes precedence over the header.
+		if err := client.Call(nil, "rpc_selectVersions", map[string]int{"acct": 1}); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(url, "ws:") {
+			checkBalance(t, client, "acct_balance", "v1:a", "a")
+		}
+		client.Close()
+	}
+}
+
+func TestAPIVersionRegister(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	for _, name := range []string{"acct@", "acct@0", "acct@x", "@2"} {
+		if err := server.RegisterName(name, versionTestServiceV1{}); err == nil {
+			t.Errorf("registered invalid name %q", name)
+		}
+	}
+	if err := server.RegisterName("acct@2", versionTestServiceV2{}); err != nil {
+		t.Fatal(err)
+	}
+	err := server.RegisterFactory("acct", func(context.Context, PeerInfo) (interface{}, error) { return versionTestServiceV1{}, nil })
+	if err == nil {
+		t.Fatal("registered factory for versioned namespace")
+	}
+}
+
+func TestParseAPIVersions(t *testing.T) {
+	t.Parallel()
+
+	versions := parseAPIVersions("eth=2, debug = 3,bad,x=0,y=z")
+	if want := map[string]int{"eth": 2, "debug": 3}; !maps.Equal(versions, want) {
+		t.Fatalf("parsed %v, want %v", versions, want)
+	}
+	if h := formatAPIVersions(versions); h != "debug=3, eth=2" {
+		t.Fatalf("formatted %q", h)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// auditRedacted replaces redacted values in audit records.
+const auditRedacted = "[REDACTED]"
+
+// AuditKind is the type of an audit record.
+type AuditKind string
+
+const (
+	// AuditCall is a method call answered by the server.
+	AuditCall AuditKind = "call"
+	// AuditNotification is a notification sent by the client, i.e. a call without id.
+	AuditNotification AuditKind = "notification"
+	// AuditSubscription is a subscription notification sent by the server. Its payload
+	// is not recorded, only its size.
+	AuditSubscription AuditKind = "subscription"
+)
+
+// AuditRecord is an entry of the audit log, see Server.SetAuditLog.
+type AuditRecord struct {
+	Time         time.Time       `json:"time"`
+	Kind         AuditKind       `json:"kind"`
+	Method       string          `json:"method"`
+	ID           json.RawMessage `json:"id,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"` // after redaction
+	Subscription ID              `json:"subscription,omitempty"`
+	Batch        *BatchInfo      `json:"batch,omitempty"` // set for calls sent in a batch
+	Transport    string          `json:"transport,omitempty"`
+	RemoteAddr   string          `json:"remoteAddr,omitempty"`
+	Principal    string          `json:"principal,omitempty"`
+	Latency      time.Duration   `json:"latency"`
+	ResultSize   int             `json:"resultSize"`
+	ErrorCode    int             `json:"errorCode,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// AuditSink receives the records of the audit log. WriteAudit is called synchronously
+// while serving the request, so slow sinks should buffer records. It may be called
+// concurrently.
+type AuditSink interface {
+	WriteAudit(rec *AuditRecord) error
+}
+
+// NewAuditWriter returns a sink which writes records to w in JSON lines format.
+func NewAuditWriter(w io.Writer) AuditSink {
+	return &auditWriter{enc: json.NewEncoder(w)}
+}
+
+type auditWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (w *auditWriter) WriteAudit(rec *AuditRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(rec)
+}
+
+// AuditConfig configures the audit log.
+type AuditConfig struct {
+	// Sink receives the records.
+	Sink AuditSink
+
+	// Methods lists the methods to record. An entry ending in '*' matches all methods
+	// with the given prefix, e.g. "admin_*". All methods are recorded when empty.
+	Methods []string
+
+	// RedactFields lists object keys whose values are replaced by "[REDACTED]" in the
+	// recorded parameters, at any depth. Keys are matched case-insensitively.
+	RedactFields []string
+
+	// RedactParams lists positional parameters to redact, by method.
+	RedactParams map[string][]int
+
+	// Subscriptions enables records of subscription notifications sent by the server.
+	Subscriptions bool
+}
+
+// SetAuditLog enables the audit log, which records every call handled by the server,
+// including calls in batches and subscription calls. Passing nil disables it.
+func (s *Server) SetAuditLog(config *AuditConfig) {
+	var a *auditLog
+	if config != nil && config.Sink != nil {
+		a = &auditLog{config: *config, fields: make(map[string]bool, len(config.RedactFields))}
+		for _, f := range config.RedactFields {
+			a.fields[strings.ToLower(f)] = true
+		}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.audit = a })
+}
+
+type auditLog struct {
+	config AuditConfig
+	fields map[string]bool // lower case
+}
+
+// recordCall writes the record of a call or client notification.
+func (a *auditLog) recordCall(ctx context.Context, msg, resp *jsonrpcMessage, start time.Time) {
+	if len(a.config.Methods) > 0 && !matchMethod(a.config.Methods, msg.Method) {
+		return
+	}
+	rec := &AuditRecord{
+		Time:    start,
+		Kind:    AuditCall,
+		Method:  msg.Method,
+		ID:      msg.ID,
+		Params:  a.redact(msg.Method, msg.Params),
+		Latency: time.Since(start),
+	}
+	if msg.isNotification() {
+		rec.Kind = AuditNotification
+	}
+	if info, ok := BatchInfoFromContext(ctx); ok {
+		rec.Batch = &info
+	}
+	a.setPeer(rec, PeerInfoFromContext(ctx))
+	if resp != nil {
+		rec.ResultSize = len(resp.Result)
+		if resp.Error != nil {
+			rec.ErrorCode, rec.Error = resp.Error.Code, resp.Error.Message
+		}
+	}
+	a.write(rec)
+}
+
+// recordNotification writes the record of a subscription notification.
+func (a *auditLog) recordNotification(h *handler, n *Notifier, sub *Subscription, data any, start time.Time, err error) {
+	method := n.namespace + notificationMethodSuffix
+	if !a.config.Subscriptions || len(a.config.Methods) > 0 && !matchMethod(a.config.Methods, method) {
+		return
+	}
+	rec := &AuditRecord{
+		Time:         start,
+		Kind:         AuditSubscription,
+		Method:       method,
+		Subscription: sub.ID,
+		Latency:      time.Since(start),
+	}
+	if enc, encErr := json.Marshal(data); encErr == nil {
+		rec.ResultSize = len(enc)
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	a.setPeer(rec, PeerInfoFromContext(h.rootCtx))
+	a.write(rec)
+}
+
+func (a *auditLog) setPeer(rec *AuditRecord, peer PeerInfo) {
+	rec.Transport, rec.RemoteAddr = peer.Transport, peer.RemoteAddr
+	if peer.Principal != nil {
+		rec.Principal = peer.Principal.Name
+	}
+}
+
+func (a *auditLog) write(rec *AuditRecord) {
+	if err := a.config.Sink.WriteAudit(rec); err != nil {
+		log.Warn("Failed to write audit record", "method", rec.Method, "err", err)
+	}
+}
+
+// redact returns params with the configured fields and positions redacted. Parameters
+// which can't be parsed are omitted, since they can't be redacted.
+func (a *auditLog) redact(method string, params json.RawMessage) json.RawMessage {
+	positions := a.config.RedactParams[method]
+	if len(params) == 0 || len(a.fields) == 0 && len(positions) == 0 {
+		return params
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	if list, ok := v.([]any); ok {
+		for _, i := range positions {
+			if i >= 0 && i < len(list) {
+				list[i] = auditRedacted
+			}
+		}
+	}
+	a.redactFields(v)
+	enc, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return enc
+}
+
+// redactFields replaces the values of redacted keys in v.
+func (a *auditLog) redactFields(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, elem := range v {
+			if a.fields[strings.ToLower(k)] {
+				v[k] = auditRedacted
+			} else {
+				a.redactFields(elem)
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			a.redactFields(elem)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// auditCollector is a sink keeping the records in memory.
+type auditCollector struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (c *auditCollector) WriteAudit(rec *AuditRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, *rec)
+	return nil
+}
+
+func (c *auditCollector) take() []AuditRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records := c.records
+	c.records = nil
+	return records
+}
+
+func TestAuditLog(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	sink := new(auditCollector)
+	server.SetAuditLog(&AuditConfig{
+		Sink:         sink,
+		Methods:      []string{"test_*"},
+		RedactFields: []string{"s"},
+		RedactParams: map[string][]int{"test_echo": {0}},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "test_echo", "secret", 1, &echoArgs{S: "hidden"}); err != nil {
+		t.Fatal(err)
+	}
+	client.Call(nil, "test_returnError")
+	client.Call(nil, "rpc_modules") // not matched by Methods
+	records := sink.take()
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %+v", len(records), records)
+	}
+	echo, failed := records[0], records[1]
+	if echo.Kind != AuditCall || echo.Method != "test_echo" || echo.Transport != "ipc" {
+		t.Errorf("wrong record %+v", echo)
+	}
+	if want := `["[REDACTED]",1,{"S":"[REDACTED]"}]`; string(echo.Params) != want {
+		t.Errorf("wrong redacted params %s, want %s", echo.Params, want)
+	}
+	if echo.ResultSize == 0 || echo.Latency <= 0 || echo.Error != "" {
+		t.Errorf("wrong result info in record %+v", echo)
+	}
+	if failed.ErrorCode != 444 || failed.Error != "testError" {
+		t.Errorf("wrong error in record %+v", failed)
+	}
+
+	// Calls in batches are recorded individually.
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []any{"x", 1, nil}, Result: new(echoResult)},
+		{Method: "test_echo", Args: []any{"y", 2, nil}, Result: new(echoResult)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	records = sink.take()
+	if len(records) != 2 {
+		t.Fatalf("got %d batch records, want 2", len(records))
+	}
+	for _, rec := range records {
+		if rec.Batch == nil || rec.Batch.Size != 2 {
+			t.Errorf("record without batch info: %+v", rec)
+		}
+	}
+}
+
+func TestAuditLogSubscriptions(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	sink := new(auditCollector)
+	server.SetAuditLog(&AuditConfig{Sink: sink, Subscriptions: true})
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 2, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	<-ch
+	<-ch
+
+	var subscribe, notifications int
+	for _, rec := range sink.take() {
+		switch {
+		case rec.Kind == AuditCall && rec.Method == "nftest_subscribe":
+			subscribe++
+		case rec.Kind == AuditSubscription && rec.Method == "nftest_subscription":
+			if string(rec.Subscription) != sub.id() || rec.ResultSize != 1 {
+				t.Errorf("wrong notification record %+v", rec)
+			}
+			notifications++
+		}
+	}
+	if subscribe != 1 || notifications != 2 {
+		t.Fatalf("got %d subscribe and %d notification records", subscribe, notifications)
+	}
+}
+
+func TestAuditWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewAuditWriter(&buf)
+	w.WriteAudit(&AuditRecord{Time: time.Unix(0, 0).UTC(), Kind: AuditCall, Method: "a_b"})
+	w.WriteAudit(&AuditRecord{Time: time.Unix(0, 0).UTC(), Kind: AuditCall, Method: "a_c", Error: "x"})
+
+	scanner := bufio.NewScanner(&buf)
+	var lines int
+	for scanner.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("got %d lines, want 2", lines)
+	}
+}
+
+func TestAuditRedactUnparseable(t *testing.T) {
+	a := &auditLog{fields: map[string]bool{"key": true}}
+	if p := a.redact("m", json.RawMessage(`[1,`)); p != nil {
+		t.Fatalf("unparseable params recorded: %s", p)
+	}
+	if p := a.redact("m", json.RawMessage(`[{"Key":12345678901234567890}]`)); string(p) != `[{"Key":"[REDACTED]"}]` {
+		t.Fatalf("wrong redaction %s", p)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Principal is an authenticated client, see Authenticator.
+type Principal struct {
+	Name  string   // e.g. the subject of a JWT, the common name of a certificate
+	Roles []string // roles granted to the principal, used by ACL rules
+
+	// Claims of the JWT the principal was authenticated with, see JWTClaimsFromContext.
+	Claims JWTClaims `json:",omitempty"`
+}
+
+// HasRole reports whether the principal has the given role.
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+// Authenticator determines the principal making an HTTP, websocket or gRPC request. It
+// returns a nil principal for anonymous requests. Requests for which it returns an error
+// are rejected with status 401. For websocket connections, the authenticator runs once
+// on the handshake request.
+type Authenticator func(r *http.Request) (*Principal, error)
+
+// FirstAuthenticator returns an authenticator which tries the given authenticators in
+// order and uses the first principal found.
+func FirstAuthenticator(auths ...Authenticator) Authenticator {
+	return func(r *http.Request) (*Principal, error) {
+		for _, auth := range auths {
+			if p, err := auth(r); p != nil || err != nil {
+				return p, err
+			}
+		}
+		return nil, nil
+	}
+}
+
+// APIKeyAuthenticator authenticates requests by the API key in the given header, using
+// keys to map keys to principals. Requests without the header are anonymous, unknown
+// keys are rejected.
+func APIKeyAuthenticator(header string, keys map[string]Principal) Authenticator {
+	return func(r *http.Request) (*Principal, error) {
+		key := r.Header.Get(header)
+		if key == "" {
+			return nil, nil
+		}
+		p, ok := keys[key]
+		if !ok {
+			return nil, errors.New("unknown API key")
+		}
+		return &p, nil
+	}
+}
+
+// ClientCertAuthenticator authenticates requests by the common name of the verified
+// TLS client certificate. The roles of each name are taken from roles. This requires
+// the HTTP server to verify client certificates, see tls.Config.ClientAuth. Requests
+// without verified certificate are anonymous.
+func ClientCertAuthenticator(roles map[string][]string) Authenticator {
+	return func(r *http.Request) (*Principal, error) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return nil, nil
+		}
+		name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		return &Principal{Name: name, Roles: roles[name]}, nil
+	}
+}
+
+// JWTAuthenticator authenticates requests by the HS256-signed JSON web token in the
+// Authorization header ("Bearer <token>"). The principal name is the "sub" claim, and
+// its roles are read from rolesClaim, which may hold a list of strings or a
+// space-separated string. Tokens must not be expired. Requests without token are
+// anonymous. See TokenValidator for more options.
+func JWTAuthenticator(secret []byte, rolesClaim string) Authenticator {
+	v := &TokenValidator{Secrets: [][]byte{secret}, RolesClaim: rolesClaim}
+	return func(r *http.Request) (*Principal, error) {
+		token, ok := bearerToken(r)
+		if !ok {
+			return nil, nil
+		}
+		return v.principal(token, time.Now())
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BaggageHeader is the HTTP header carrying W3C baggage.
+const BaggageHeader = "Baggage"
+
+// Limits from the W3C Baggage specification.
+const (
+	maxBaggageMembers = 180
+	maxBaggageBytes   = 8192
+)
+
+// BaggageMember is an entry of W3C baggage.
+type BaggageMember struct {
+	Key        string
+	Value      string   // decoded value
+	Properties []string // metadata, e.g. "ttl=60", passed through as is
+}
+
+// Baggage is a list of W3C baggage members, as carried in the Baggage header. It
+// propagates application-defined identifiers, such as a tenant or experiment, across
+// RPC calls.
+//
+// The server adds the baggage of incoming requests to the context of method calls,
+// see BaggageFromContext. Clients send the baggage of the call context along with
+// requests, see NewContextWithBaggage. Services which make calls to other servers using
+// the context of their method call thus forward the baggage they received. Over HTTP,
+// baggage is sent in the BaggageHeader. Other transports carry it in the request
+// metadata.
+type Baggage []BaggageMember
+
+// ParseBaggage parses a Baggage header value.
+func ParseBaggage(s string) (Baggage, error) {
+	if len(s) > maxBaggageBytes {
+		return nil, errors.New("baggage too large")
+	}
+	var b Baggage
+	for _, member := range strings.Split(s, ",") {
+		member = strings.Trim(member, " \t")
+		if member == "" {
+			continue
+		}
+		if len(b) == maxBaggageMembers {
+			return nil, errors.New("too many baggage members")
+		}
+		m, err := parseBaggageMember(member)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, m)
+	}
+	return b, nil
+}
+
+func parseBaggageMember(s string) (BaggageMember, error) {
+	parts := strings.Split(s, ";")
+	key, value, ok := strings.Cut(parts[0], "=")
+	key, value = strings.Trim(key, " \t"), strings.Trim(value, " \t")
+	if !ok || !isBaggageKey(key) {
+		return BaggageMember{}, fmt.Errorf("invalid baggage member %q", s)
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' && !isBaggageOctet(value[i]) {
+			return BaggageMember{}, fmt.Errorf("invalid baggage value for key %q", key)
+		}
+	}
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return BaggageMember{}, fmt.Errorf("invalid baggage value for key %q", key)
+	}
+	m := BaggageMember{Key: key, Value: decoded}
+	for _, prop := range parts[1:] {
+		if prop = strings.Trim(prop, " \t"); prop != "" {
+			m.Properties = append(m.Properties, prop)
+		}
+	}
+	return m, nil
+}
+
+// String returns the Baggage header value of b. Members exceeding the size limits of
+// the specification are omitted.
+func (b Baggage) String() string {
+	var sb strings.Builder
+	for i, m := range b {
+		if i == maxBaggageMembers {
+			break
+		}
+		var ms strings.Builder
+		ms.WriteString(m.Key)
+		ms.WriteByte('=')
+		ms.WriteString(escapeBaggageValue(m.Value))
+		for _, prop := range m.Properties {
+			ms.WriteByte(';')
+			ms.WriteString(prop)
+		}
+		if sb.Len()+ms.Len()+1 > maxBaggageBytes {
+			break
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(ms.String())
+	}
+	return sb.String()
+}
+
+// Get returns the value of the first member with the given key.
+func (b Baggage) Get(key string) (string, bool) {
+	for _, m := range b {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// With returns a copy of b in which the member with the given key has the given value.
+// The member is appended if b does not contain the key.
+func (b Baggage) With(key, value string) Baggage {
+	cpy := make(Baggage, 0, len(b)+1)
+	found := false
+	for _, m := range b {
+		if m.Key == key {
+			if found {
+				continue
+			}
+			m = BaggageMember{Key: key, Value: value}
+			found = true
+		}
+		cpy = append(cpy, m)
+	}
+	if !found {
+		cpy = append(cpy, BaggageMember{Key: key, Value: value})
+	}
+	return cpy
+}
+
+func isBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isBaggageOctet reports whether c may appear unescaped in a baggage value.
+func isBaggageOctet(c byte) bool {
+	return c == 0x21 || (c >= 0x23 && c <= 0x2B) || (c >= 0x2D && c <= 0x3A) ||
+		(c >= 0x3C && c <= 0x5B) || (c >= 0x5D && c <= 0x7E)
+}
+
+func escapeBaggageValue(v string) string {
+	const hex = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c != '%' && isBaggageOctet(c) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hex[c>>4])
+		sb.WriteByte(hex[c&15])
+	}
+	return sb.String()
+}
+
+type baggageKey struct{}
+
+// NewContextWithBaggage returns a context carrying the given baggage. Calls made by
+// Client using the returned context send the baggage to the server.
+func NewContextWithBaggage(ctx context.Context, b Baggage) context.Context {
+	return context.WithValue(ctx, baggageKey{}, b)
+}
+
+// BaggageFromContext returns the baggage carried by ctx. In method handlers, this is
+// the baggage sent by the client.
+func BaggageFromContext(ctx context.Context) Baggage {
+	b, _ := ctx.Value(baggageKey{}).(Baggage)
+	return b
+}
+
+// baggageFromHeader parses the Baggage header values. Invalid baggage is ignored.
+func baggageFromHeader(values []string) Baggage {
+	if len(values) == 0 {
+		return nil
+	}
+	b, _ := ParseBaggage(strings.Join(values, ","))
+	return b
+}
+
+// requestBaggage returns the baggage in the request metadata of msg.
+func (msg *jsonrpcMessage) requestBaggage() Baggage {
+	if len(msg.Ext) == 0 {
+		return nil
+	}
+	ext := msg.requestExt()
+	if ext.Baggage == "" {
+		return nil
+	}
+	b, _ := ParseBaggage(ext.Baggage)
+	return b
+}
+
+// attachBaggage stores the baggage of ctx in the request metadata of msgs. Over HTTP,
+// baggage is sent in the request header instead.
+func (c *Client) attachBaggage(ctx context.Context, msgs ...*jsonrpcMessage) {
+	if c.isHTTP {
+		return
+	}
+	b := BaggageFromContext(ctx)
+	if len(b) == 0 {
+		return
+	}
+	value := b.String()
+	for _, msg := range msgs {
+		ext := msg.requestExt()
+		ext.Baggage = value
+		msg.Ext, _ = json.Marshal(ext)
+	}
+}
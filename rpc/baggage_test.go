@@ -0,0 +1,112 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseBaggage(t *testing.T) {
+	t.Parallel()
+
+	b, err := ParseBaggage("tenant=acme, exp=a%2Cb;ttl=60 ,,user=%E2%9C%93")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Baggage{
+		{Key: "tenant", Value: "acme"},
+		{Key: "exp", Value: "a,b", Properties: []string{"ttl=60"}},
+		{Key: "user", Value: "✓"},
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Fatalf("wrong baggage %+v", b)
+	}
+	if s := b.String(); s != "tenant=acme,exp=a%2Cb;ttl=60,user=%E2%9C%93" {
+		t.Fatalf("wrong header value %q", s)
+	}
+
+	for _, invalid := range []string{"novalue", "=x", "k=a b", "k=%zz", "k y=1"} {
+		if _, err := ParseBaggage(invalid); err == nil {
+			t.Errorf("no error for %q", invalid)
+		}
+	}
+
+	b = b.With("tenant", "other").With("region", "eu")
+	if v, _ := b.Get("tenant"); v != "other" {
+		t.Fatalf("wrong tenant %q", v)
+	}
+	if v, _ := b.Get("region"); v != "eu" {
+		t.Fatalf("wrong region %q", v)
+	}
+}
+
+type baggageService struct {
+	next *Client // forwards calls if set
+}
+
+func (s *baggageService) Get(ctx context.Context) (string, error) {
+	if s.next != nil {
+		var result string
+		err := s.next.CallContext(ctx, &result, "baggage_get")
+		return result, err
+	}
+	return BaggageFromContext(ctx).String(), nil
+}
+
+func TestBaggagePropagation(t *testing.T) {
+	t.Parallel()
+
+	// The backend is reached over HTTP through a gateway connected in-process.
+	backend := NewServer()
+	defer backend.Stop()
+	backend.RegisterName("baggage", new(baggageService))
+	httpsrv := httptest.NewServer(backend)
+	defer httpsrv.Close()
+	backendClient, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backendClient.Close()
+
+	gateway := NewServer()
+	defer gateway.Stop()
+	gateway.RegisterName("baggage", &baggageService{next: backendClient})
+	client := DialInProc(gateway)
+	defer client.Close()
+
+	b := Baggage{{Key: "tenant", Value: "acme corp"}}
+	ctx := NewContextWithBaggage(context.Background(), b)
+	var result string
+	if err := client.CallContext(ctx, &result, "baggage_get"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "tenant=acme%20corp" {
+		t.Fatalf("wrong baggage at backend %q", result)
+	}
+
+	// Baggage applies to the calls it was sent with.
+	batch := []BatchElem{{Method: "baggage_get", Result: new(string)}}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil || *batch[0].Result.(*string) != "" {
+		t.Fatalf("unexpected baggage %q (err %v)", *batch[0].Result.(*string), batch[0].Error)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+)
+
+// SetBatchConcurrency makes the server execute the calls of a batch on up to n
+// goroutines, instead of one after another. Responses are sent in the order in which the
+// calls complete, unless request order is configured using SetBatchOrder. Batches
+// containing calls to an ordered namespace (see SetOrderedNamespaces) are executed
+// sequentially. A value of zero or one disables concurrent execution.
+//
+// Since calls of a batch may run at the same time, this should only be enabled when the
+// calls in a batch don't depend on each other, e.g. for read-only methods.
+func (s *Server) SetBatchConcurrency(n int) {
+	s.services.updateConfig(func(c *registryConfig) { c.batchConcurrency = max(n, 0) })
+}
+
+// BatchOrder says how the responses of a batch are ordered, see SetBatchOrder.
+type BatchOrder int
+
+const (
+	// BatchOrderAny allows responses in any order. Clients match them to the calls
+	// using their ids, as required by the JSON-RPC specification.
+	BatchOrderAny BatchOrder = iota
+	// BatchOrderRequest sends responses in the order of the calls.
+	BatchOrderRequest
+)
+
+// SetBatchOrder sets the order of batch responses. The default, BatchOrderAny, is what the
+// JSON-RPC specification requires clients to support. Legacy clients which index batch
+// responses by position, which is common for HTTP clients, need BatchOrderRequest. Note
+// that notifications in a batch never get a response, so positions only match when the
+// batch contains calls only.
+//
+// The order only makes a difference when calls are executed concurrently, see
+// SetBatchConcurrency.
+func (s *Server) SetBatchOrder(order BatchOrder) {
+	s.services.updateConfig(func(c *registryConfig) { c.batchOrder = order })
+}
+
+// runBatchConcurrently executes the calls of a batch on up to n goroutines. Responses are
+// passed to push as the calls complete, or in call order if inOrder is set, until push
+// returns false. It returns after all started calls have finished.
+func (h *handler) runBatchConcurrently(cp *callProc, cancel context.CancelFunc, calls []*jsonrpcMessage, n int, inOrder bool,
+	handle func(ctx context.Context, index int, msg *jsonrpcMessage) (*jsonrpcMessage, []*Notifier),
+	push func(msg, resp *jsonrpcMessage) bool,
+) {
+	type result struct {
+		resp      *jsonrpcMessage
+		notifiers []*Notifier
+		done      chan struct{}
+	}
+	var (
+		results  = make([]result, len(calls))
+		finished = make(chan int, len(calls)) // indexes of completed calls
+		sem      = make(chan struct{}, n)
+		wg       sync.WaitGroup
+	)
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, msg := range calls {
+			select {
+			case sem <- struct{}{}:
+			case <-cp.ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				r := &results[i]
+				r.resp, r.notifiers = handle(cp.ctx, i, msg)
+				close(r.done)
+				finished <- i
+			}()
+		}
+	}()
+	defer wg.Wait()
+
+	for k := range results {
+		i := k
+		if inOrder {
+			select {
+			case <-results[i].done:
+			case <-cp.ctx.Done():
+				// Timed out, the remaining calls have been answered with an error.
+				return
+			}
+		} else {
+			select {
+			case i = <-finished:
+			case <-cp.ctx.Done():
+				return
+			}
+		}
+		cp.notifiers = append(cp.notifiers, results[i].notifiers...)
+		if !push(calls[i], results[i].resp) {
+			cancel()
+			return
+		}
+	}
+}
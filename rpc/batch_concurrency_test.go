@@ -0,0 +1,178 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyService tracks the number of concurrently running calls.
+type concurrencyService struct {
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+func (s *concurrencyService) Sleep(ctx context.Context, n int, d time.Duration) int {
+	s.mu.Lock()
+	s.running++
+	s.max = max(s.max, s.running)
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	return n
+}
+
+func TestBatchConcurrency(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(concurrencyService)
+	server.RegisterName("conc", svc)
+	server.SetBatchConcurrency(3)
+	client := DialInProc(server)
+	defer client.Close()
+
+	batch := make([]BatchElem, 9)
+	for i := range batch {
+		// Earlier calls take longer, so they finish out of order.
+		d := time.Duration(len(batch)-i) * 10 * time.Millisecond
+		batch[i] = BatchElem{Method: "conc_sleep", Args: []any{i, d}, Result: new(int)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			t.Fatalf("call %d failed: %v", i, elem.Error)
+		}
+		if n := *elem.Result.(*int); n != i {
+			t.Errorf("call %d: got result %d", i, n)
+		}
+	}
+	if svc.max != 3 {
+		t.Errorf("max concurrency %d, want 3", svc.max)
+	}
+
+	// Sequential execution.
+	server.SetBatchConcurrency(0)
+	svc.max = 0
+	if err := client.BatchCall(batch[:3]); err != nil {
+		t.Fatal(err)
+	}
+	if svc.max != 1 {
+		t.Errorf("max concurrency %d with concurrency disabled", svc.max)
+	}
+}
+
+func TestBatchConcurrencyTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("conc", new(concurrencyService))
+	server.SetBatchConcurrency(2)
+	server.SetBatchOrder(BatchOrderRequest)
+	// The batch times out before the HTTP write timeout.
+	httpsrv := httptest.NewUnstartedServer(server)
+	httpsrv.Config.WriteTimeout = 300 * time.Millisecond
+	httpsrv.Start()
+	defer httpsrv.Close()
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batch := []BatchElem{
+		{Method: "conc_sleep", Args: []any{0, time.Duration(0)}, Result: new(int)},
+		{Method: "conc_sleep", Args: []any{1, time.Hour}, Result: new(int)},
+		{Method: "conc_sleep", Args: []any{2, time.Duration(0)}, Result: new(int)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil || *batch[0].Result.(*int) != 0 {
+		t.Errorf("first call: %v", batch[0].Error)
+	}
+	for _, elem := range batch[1:] {
+		if re, ok := elem.Error.(Error); !ok || re.ErrorCode() != errcodeTimeout {
+			t.Errorf("expected timeout error, got %v", elem.Error)
+		}
+	}
+}
+
+func TestBatchOrder(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("conc", new(concurrencyService))
+	server.SetBatchConcurrency(3)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	// Earlier calls take longer, so they finish in reverse order.
+	var calls []string
+	for i := 0; i < 3; i++ {
+		d := time.Duration(3-i) * 50 * time.Millisecond
+		calls = append(calls, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"conc_sleep","params":[%d,%d]}`, i, i, d))
+	}
+	body := "[" + strings.Join(calls, ",") + "]"
+
+	check := func(order BatchOrder, want []int) {
+		t.Helper()
+		server.SetBatchOrder(order)
+		resp, err := http.Post(httpsrv.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var responses []struct {
+			ID int `json:"id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, r := range responses {
+			ids = append(ids, r.ID)
+		}
+		if !slices.Equal(ids, want) {
+			t.Errorf("order %d: got response ids %v, want %v", order, ids, want)
+		}
+	}
+	check(BatchOrderAny, []int{2, 1, 0})
+	check(BatchOrderRequest, []int{0, 1, 2})
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// durationSamples is the number of recent execution times kept per method.
+const durationSamples = 16
+
+// methodDurations tracks recent execution times of methods. It is used to skip batch
+// items which cannot complete before the request deadline.
+type methodDurations struct {
+	mu      sync.Mutex
+	methods map[string]*durationRing
+}
+
+type durationRing struct {
+	samples [durationSamples]time.Duration
+	n, next int
+}
+
+func (md *methodDurations) record(method string, d time.Duration) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+	if md.methods == nil {
+		md.methods = make(map[string]*durationRing)
+	}
+	r := md.methods[method]
+	if r == nil {
+		r = new(durationRing)
+		md.methods[method] = r
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % durationSamples
+	r.n = min(r.n+1, durationSamples)
+}
+
+// fastest returns the shortest recent execution time of method. This is used as a lower
+// bound for the time the next call will take.
+func (md *methodDurations) fastest(method string) (time.Duration, bool) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+	r := md.methods[method]
+	if r == nil || r.n == 0 {
+		return 0, false
+	}
+	d := r.samples[0]
+	for _, s := range r.samples[1:r.n] {
+		d = min(d, s)
+	}
+	return d, true
+}
+
+// deadlineSkippedError is returned for batch items which were not executed because the
+// remaining time until the request deadline is shorter than any recent execution of the
+// method.
+type deadlineSkippedError struct {
+	method    string
+	remaining time.Duration
+	estimate  time.Duration
+}
+
+func (e *deadlineSkippedError) ErrorCode() int { return errcodeDeadlineSkipped }
+
+func (e *deadlineSkippedError) Error() string { return errMsgDeadlineSkipped }
+
+func (e *deadlineSkippedError) ErrorData() interface{} {
+	data := retryHintData(0)
+	data["method"] = e.method
+	data["remaining"] = e.remaining.String()
+	data["estimated"] = e.estimate.String()
+	return data
+}
+
+// checkBatchDeadline returns an error if msg cannot complete before the deadline. A zero
+// deadline means the request has no deadline.
+func (h *handler) checkBatchDeadline(msg *jsonrpcMessage, deadline mclock.AbsTime) error {
+	if deadline == 0 {
+		return nil
+	}
+	est, ok := h.reg.durations.fastest(msg.Method)
+	if !ok {
+		return nil
+	}
+	if remaining := deadline.Sub(h.clock.Now()); est > remaining {
+		return &deadlineSkippedError{method: msg.Method, remaining: max(remaining, 0), estimate: est}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// batchCaptureConn is a jsonWriter which forwards written messages to a channel.
+type batchCaptureConn struct {
+	middlewareTestConn
+	out chan interface{}
+}
+
+func (c *batchCaptureConn) writeJSON(ctx context.Context, v interface{}, isError bool) error {
+	c.out <- v
+	return nil
+}
+
+func TestBatchDeadlineSkip(t *testing.T) {
+	t.Parallel()
+
+	reg := new(serviceRegistry)
+	if err := reg.registerName("test", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	reg.durations.record("test_sleep", 5*time.Second)
+	reg.durations.record("test_sleep", 2*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn := &batchCaptureConn{out: make(chan interface{}, 1)}
+	h := newHandler(ctx, conn, randomIDGenerator(), reg, 0, 0)
+	defer h.close(nil, nil)
+
+	h.handleBatch([]*jsonrpcMessage{
+		{Version: vsn, ID: json.RawMessage("1"), Method: "test_sleep", Params: json.RawMessage(`[60000000000]`)},
+		{Version: vsn, ID: json.RawMessage("2"), Method: "test_rets", Params: json.RawMessage(`[]`)},
+		{Version: vsn, ID: json.RawMessage("3"), Method: "test_sleep", Params: json.RawMessage(`[0]`)},
+	})
+	var resps []*jsonrpcMessage
+	select {
+	case v := <-conn.out:
+		resps = v.([]*jsonrpcMessage)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch response")
+	}
+	if len(resps) != 3 {
+		t.Fatalf("wrong number of responses %d", len(resps))
+	}
+
+	// test_sleep never ran faster than 2s, so it can't complete within the 1s deadline.
+	for _, i := range []int{0, 2} {
+		if resps[i].Error == nil || resps[i].Error.Code != errcodeDeadlineSkipped {
+			t.Fatalf("response %d: expected deadline error, got %v", i, resps[i])
+		}
+		data := resps[i].Error.Data.(map[string]interface{})
+		if data["method"] != "test_sleep" || data["estimated"] != "2s" || data["retryable"] != true {
+			t.Errorf("response %d: wrong error data %v", i, data)
+		}
+	}
+	if resps[1].Error != nil {
+		t.Fatalf("test_rets failed: %v", resps[1].Error)
+	}
+}
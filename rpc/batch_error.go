@@ -0,0 +1,72 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// BatchError is returned by BatchCall and BatchCallContext when a batch fails at the
+// transport level. It identifies the elements of the batch which were definitely not
+// sent, so callers can safely retry them even if the batch contains calls with side
+// effects. Elements with indeterminate state may have been executed by the server, but
+// their responses were not received. Elements listed in neither have their result or
+// error assigned.
+type BatchError struct {
+	Err           error // the transport error
+	Unsent        []int // indexes of elements which were not sent
+	Indeterminate []int // indexes of elements which may have been executed
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch failed: %v (%d unsent, %d indeterminate elements)", e.Err, len(e.Unsent), len(e.Indeterminate))
+}
+
+func (e *BatchError) Unwrap() error { return e.Err }
+
+// newBatchError creates the error of a batch with n elements whose chunk [start, end)
+// failed. Chunks after it were not sent.
+func newBatchError(err error, n, start, end int, sent bool) *BatchError {
+	e := &BatchError{Err: err}
+	if sent {
+		e.Indeterminate = indexRange(start, end)
+		e.Unsent = indexRange(end, n)
+	} else {
+		e.Unsent = indexRange(start, n)
+	}
+	return e
+}
+
+func indexRange(start, end int) []int {
+	if start >= end {
+		return nil
+	}
+	r := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		r = append(r, i)
+	}
+	return r
+}
+
+// isDialError reports whether err happened while establishing a connection, i.e. before
+// any request data was written.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchErrorIndexes(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	// The second chunk fails after reaching the server.
+	var requests atomic.Int32
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 2 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithBatchChunking(2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batch := make([]BatchElem, 5)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []interface{}{"x", i, nil}, Result: new(echoResult)}
+	}
+	err = client.BatchCall(batch)
+	var berr *BatchError
+	if !errors.As(err, &berr) {
+		t.Fatalf("expected *BatchError, got %v", err)
+	}
+	if !reflect.DeepEqual(berr.Indeterminate, []int{2, 3}) {
+		t.Errorf("wrong indeterminate elements %v", berr.Indeterminate)
+	}
+	if !reflect.DeepEqual(berr.Unsent, []int{4}) {
+		t.Errorf("wrong unsent elements %v", berr.Unsent)
+	}
+	for i := 0; i < 2; i++ {
+		if batch[i].Error != nil || batch[i].Result.(*echoResult).Int != i {
+			t.Errorf("element %d not completed: %v", i, batch[i].Error)
+		}
+	}
+}
+
+func TestBatchErrorNotSent(t *testing.T) {
+	httpsrv := httptest.NewServer(http.NotFoundHandler())
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	httpsrv.Close()
+
+	batch := []BatchElem{{Method: "test_echo"}, {Method: "test_echo"}}
+	err = client.BatchCall(batch)
+	var berr *BatchError
+	if !errors.As(err, &berr) {
+		t.Fatalf("expected *BatchError, got %v", err)
+	}
+	if !reflect.DeepEqual(berr.Unsent, []int{0, 1}) || len(berr.Indeterminate) != 0 {
+		t.Errorf("wrong indexes: unsent %v, indeterminate %v", berr.Unsent, berr.Indeterminate)
+	}
+}
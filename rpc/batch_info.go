@@ -0,0 +1,39 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "context"
+
+// BatchInfo describes the position of a method call within a batch request.
+type BatchInfo struct {
+	Size  int // number of calls and notifications in the batch
+	Index int // position of the call in the batch, starting at zero
+}
+
+type batchInfoKey struct{}
+
+func withBatchInfo(ctx context.Context, info BatchInfo) context.Context {
+	return context.WithValue(ctx, batchInfoKey{}, info)
+}
+
+// BatchInfoFromContext returns the batch position of the method call. The boolean is
+// false for calls which were not sent as part of a batch. Handlers and middleware can use
+// this to adapt to large batches, for example by reducing logging.
+func BatchInfoFromContext(ctx context.Context) (BatchInfo, bool) {
+	info, ok := ctx.Value(batchInfoKey{}).(BatchInfo)
+	return info, ok
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+)
+
+type batchInfoService struct{}
+
+func (batchInfoService) Get(ctx context.Context) *BatchInfo {
+	if info, ok := BatchInfoFromContext(ctx); ok {
+		return &info
+	}
+	return nil
+}
+
+func TestBatchInfo(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("batch", batchInfoService{})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var single *BatchInfo
+	if err := client.Call(&single, "batch_get"); err != nil {
+		t.Fatal(err)
+	}
+	if single != nil {
+		t.Fatalf("batch info for single call: %+v", single)
+	}
+
+	batch := make([]BatchElem, 3)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "batch_get", Result: new(BatchInfo)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			t.Fatal(elem.Error)
+		}
+		want := BatchInfo{Size: 3, Index: i}
+		if got := *elem.Result.(*BatchInfo); got != want {
+			t.Errorf("call %d: wrong batch info %+v, want %+v", i, got, want)
+		}
+	}
+}
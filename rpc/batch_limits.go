@@ -0,0 +1,83 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "sync/atomic"
+
+// Names of batch limits, as reported in the data of batchLimitError.
+const (
+	batchLimitItems         = "items"
+	batchLimitResponseBytes = "responseBytes"
+)
+
+// maxBatchExcess is the factor by which a batch may exceed the item limit and still get
+// an error for each call beyond the limit. Larger batches are rejected as a whole, so the
+// size of the response is bounded.
+const maxBatchExcess = 2
+
+// batchServeLimits are the limits applied to incoming batch requests on a connection. A
+// limit of zero disables the respective check.
+type batchServeLimits struct {
+	items         atomic.Int64
+	responseBytes atomic.Int64
+}
+
+func newBatchServeLimits(items, responseBytes int) *batchServeLimits {
+	l := new(batchServeLimits)
+	l.set(items, responseBytes)
+	return l
+}
+
+func (l *batchServeLimits) set(items, responseBytes int) {
+	l.items.Store(int64(items))
+	l.responseBytes.Store(int64(responseBytes))
+}
+
+func (l *batchServeLimits) get() (items, responseBytes int) {
+	return int(l.items.Load()), int(l.responseBytes.Load())
+}
+
+// SetBatchLimits changes the limits applied to batch requests received by the client,
+// like WithBatchItemLimit and WithBatchResponseSizeLimit. The limits apply to batches
+// received after the call. They don't affect batch requests sent by the client.
+func (c *Client) SetBatchLimits(maxItems, maxResponseBytes int) {
+	c.batchLimits.set(maxItems, maxResponseBytes)
+}
+
+// batchLimitError is the error returned for calls of a batch which exceed a batch limit.
+// Calls beyond the item limit are not executed, and calls after the response size limit
+// is reached are not answered. The error data names the exceeded limit and its value,
+// e.g. {"limit":"items","max":100}, so proxies can report partial failure of a batch.
+type batchLimitError struct {
+	code    int
+	message string
+	limit   string
+	max     int
+}
+
+func (e *batchLimitError) ErrorCode() int { return e.code }
+
+func (e *batchLimitError) Error() string { return e.message }
+
+func (e *batchLimitError) ErrorData() interface{} {
+	return batchLimitErrorData{Limit: e.limit, Max: e.max}
+}
+
+type batchLimitErrorData struct {
+	Limit string `json:"limit"`
+	Max   int    `json:"max"`
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// This test checks that Client.SetBatchLimits applies to batch requests
+// received by the client on an existing connection.
+func TestClientSetBatchLimits(t *testing.T) {
+	t.Parallel()
+
+	p1, p2 := net.Pipe()
+	client, err := DialIO(context.Background(), p1, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer p2.Close()
+	if err := client.RegisterName("test", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		batch = `[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",2]}]`
+		dec   = json.NewDecoder(p2)
+	)
+	send := func() []*jsonrpcMessage {
+		t.Helper()
+		p2.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := p2.Write([]byte(batch)); err != nil {
+			t.Fatal("write error:", err)
+		}
+		var resp []*jsonrpcMessage
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal("read error:", err)
+		}
+		if len(resp) != 2 {
+			t.Fatalf("wrong number of responses %d", len(resp))
+		}
+		return resp
+	}
+
+	// Without limits, both calls are executed.
+	for i, msg := range send() {
+		if msg.Error != nil {
+			t.Fatalf("response %d has error: %v", i, msg.Error)
+		}
+	}
+
+	// With an item limit of one, the second call is answered with an error.
+	client.SetBatchLimits(1, 0)
+	resp := send()
+	if resp[0].Error != nil {
+		t.Fatalf("response 0 has error: %v", resp[0].Error)
+	}
+	if resp[1].Error == nil || resp[1].Error.Code != -32600 || resp[1].Error.Message != errMsgBatchTooLarge {
+		t.Fatalf("wrong error in response 1: %+v", resp[1].Error)
+	}
+	wantData := map[string]interface{}{"limit": "items", "max": float64(1)}
+	if !reflect.DeepEqual(resp[1].Error.Data, wantData) {
+		t.Fatalf("wrong error data %v", resp[1].Error.Data)
+	}
+}
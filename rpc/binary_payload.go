@@ -0,0 +1,356 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BinaryPayloadHeader is sent in the websocket handshake by clients supporting binary
+// payloads, and echoed by the server when it accepts them, see SetBinaryPayloads.
+const BinaryPayloadHeader = "X-Rpc-Binary-Payloads"
+
+// multipartRelated is the media type of HTTP messages carrying binary payloads.
+const multipartRelated = "multipart/related"
+
+// Blob is a byte string which may be large, such as a raw transaction or a state
+// witness. In JSON, it is encoded as a hex string with 0x prefix. When binary payloads
+// are enabled, see Server.SetBinaryPayloads and WithBinaryPayloads, large blobs are
+// sent as raw bytes next to the JSON message instead of being hex-encoded in it. This
+// is transparent to methods and clients, which use Blob like any other type.
+type Blob []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Blob) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(b).MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Blob) UnmarshalJSON(input []byte) error {
+	return (*hexutil.Bytes)(b).UnmarshalJSON(input)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Blob) UnmarshalText(input []byte) error {
+	return (*hexutil.Bytes)(b).UnmarshalText(input)
+}
+
+// String returns the hex encoding of b.
+func (b Blob) String() string {
+	return hexutil.Encode(b)
+}
+
+// Binary payloads are sent over websocket as binary messages which start with blobMagic,
+// followed by the number of payloads as a big-endian uint32 and the size of each payload
+// as a big-endian uint64. The payloads come next, then the JSON message. In the JSON
+// message, each payload is replaced by the string "$blob:<index>".
+var blobMagic = []byte{0, 'R', 'P', 'C', 'B'}
+
+const blobRefPrefix = "$blob:"
+
+var (
+	errBlobInvalid   = errors.New("invalid binary payload message")
+	errBlobTooLarge  = errors.New("binary payload message too large")
+	errBlobsDisabled = errors.New("binary payloads not negotiated")
+)
+
+// SetBinaryPayloads enables binary payloads for hex strings, such as Blob values, of at
+// least threshold bytes. Over WebSocket, they are used for connections of clients which
+// enable them using WithBinaryPayloads. Over HTTP, requests carrying binary payloads
+// are accepted, and responses use them if the client accepts multipart/related
+// responses. A threshold of zero disables binary payloads, which is the default.
+//
+// This method should be called before serving any requests.
+func (s *Server) SetBinaryPayloads(threshold int) {
+	s.binaryPayloads = max(threshold, 0)
+}
+
+// WithBinaryPayloads configures the client to send hex strings, such as Blob values, of
+// at least threshold bytes as binary payloads. Binary payloads are only used if the
+// server supports them. Over HTTP, responses carrying binary payloads are accepted.
+func WithBinaryPayloads(threshold int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.binaryPayloads = max(threshold, 0)
+	})
+}
+
+// scanJSONStrings calls fn with the offsets of the content of each string in the JSON
+// value data. The offsets exclude the quotes.
+func scanJSONStrings(data []byte, fn func(start, end int)) {
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			continue
+		}
+		start := i + 1
+		for i = start; i < len(data) && data[i] != '"'; i++ {
+			if data[i] == '\\' {
+				i++
+			}
+		}
+		if i < len(data) {
+			fn(start, i)
+		}
+	}
+}
+
+// isBlobHex reports whether s is the encoding of a blob of at least threshold bytes.
+// Only lower case hex is accepted, so restoring the blob reproduces the message.
+func isBlobHex(s []byte, threshold int) bool {
+	if len(s) < 2+2*threshold || len(s)%2 != 0 || s[0] != '0' || s[1] != 'x' {
+		return false
+	}
+	for _, c := range s[2:] {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// extractBlobs replaces hex strings of at least threshold bytes in the JSON message data
+// with references to binary payloads. It returns nil payloads if there are none, or if
+// the message contains strings which could be mistaken for references.
+func extractBlobs(data []byte, threshold int) ([]byte, [][]byte) {
+	var (
+		out      []byte
+		blobs    [][]byte
+		last     int
+		conflict bool
+	)
+	scanJSONStrings(data, func(start, end int) {
+		s := data[start:end]
+		if bytes.HasPrefix(s, []byte(blobRefPrefix)) {
+			conflict = true
+		}
+		if conflict || !isBlobHex(s, threshold) {
+			return
+		}
+		blob := make([]byte, (len(s)-2)/2)
+		hex.Decode(blob, s[2:])
+		out = append(out, data[last:start]...)
+		out = append(out, blobRefPrefix...)
+		out = strconv.AppendInt(out, int64(len(blobs)), 10)
+		blobs = append(blobs, blob)
+		last = end
+	})
+	if conflict || len(blobs) == 0 {
+		return data, nil
+	}
+	return append(out, data[last:]...), blobs
+}
+
+// restoreBlobs replaces the references to binary payloads in the JSON message data with
+// the hex encoding of the payloads. Each payload must be referenced exactly once, and
+// the restored message may not exceed limit bytes. A limit of zero means no limit.
+func restoreBlobs(data []byte, blobs [][]byte, limit int64) ([]byte, error) {
+	var (
+		out  []byte
+		last int
+		used = make([]bool, len(blobs))
+		size = int64(len(data))
+		err  error
+	)
+	scanJSONStrings(data, func(start, end int) {
+		s, ok := bytes.CutPrefix(data[start:end], []byte(blobRefPrefix))
+		if !ok || err != nil {
+			return
+		}
+		i, perr := strconv.Atoi(string(s))
+		if perr != nil || i < 0 || i >= len(blobs) {
+			err = fmt.Errorf("%w: bad reference %q", errBlobInvalid, data[start:end])
+			return
+		}
+		if used[i] {
+			err = fmt.Errorf("%w: repeated reference %q", errBlobInvalid, data[start:end])
+			return
+		}
+		used[i] = true
+		size += int64(2 + 2*len(blobs[i]) - (end - start))
+		if limit > 0 && size > limit {
+			err = errBlobTooLarge
+			return
+		}
+		out = append(out, data[last:start]...)
+		out = append(out, "0x"...)
+		out = hex.AppendEncode(out, blobs[i])
+		last = end
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range used {
+		if !used[i] {
+			return nil, fmt.Errorf("%w: payload %d not referenced", errBlobInvalid, i)
+		}
+	}
+	return append(out, data[last:]...), nil
+}
+
+// packBlobs encodes a JSON message with its binary payloads as a websocket message.
+func packBlobs(data []byte, blobs [][]byte) []byte {
+	size := len(blobMagic) + 4 + 8*len(blobs) + len(data)
+	for _, b := range blobs {
+		size += len(b)
+	}
+	msg := make([]byte, 0, size)
+	msg = append(msg, blobMagic...)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(blobs)))
+	for _, b := range blobs {
+		msg = binary.BigEndian.AppendUint64(msg, uint64(len(b)))
+	}
+	for _, b := range blobs {
+		msg = append(msg, b...)
+	}
+	return append(msg, data...)
+}
+
+// unpackBlobs decodes a websocket message created by packBlobs and returns the JSON
+// message with the payloads restored. The restored message is limited to readLimit
+// bytes, see restoreBlobs.
+func unpackBlobs(msg []byte, readLimit int64) ([]byte, error) {
+	msg = msg[len(blobMagic):]
+	if len(msg) < 4 {
+		return nil, errBlobInvalid
+	}
+	n := uint64(binary.BigEndian.Uint32(msg))
+	msg = msg[4:]
+	if uint64(len(msg)) < 8*n {
+		return nil, errBlobInvalid
+	}
+	sizes, msg := msg[:8*n], msg[8*n:]
+	blobs := make([][]byte, n)
+	for i := range blobs {
+		size := binary.BigEndian.Uint64(sizes[8*i:])
+		if uint64(len(msg)) < size {
+			return nil, errBlobInvalid
+		}
+		blobs[i], msg = msg[:size], msg[size:]
+	}
+	return restoreBlobs(msg, blobs, readLimit)
+}
+
+// writeMultipart writes a JSON message and its binary payloads as a multipart/related
+// body. The first part is the JSON message, and each payload follows as a part of type
+// application/octet-stream. It returns the content type of the body.
+func writeMultipart(w io.Writer, data []byte, blobs [][]byte) (string, error) {
+	mw := multipart.NewWriter(w)
+	part := func(ct string, content []byte) error {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {ct}})
+		if err == nil {
+			_, err = pw.Write(content)
+		}
+		return err
+	}
+	if err := part(contentType, data); err != nil {
+		return "", err
+	}
+	for _, b := range blobs {
+		if err := part("application/octet-stream", b); err != nil {
+			return "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	ct := mime.FormatMediaType(multipartRelated, map[string]string{"type": contentType, "boundary": mw.Boundary()})
+	return ct, nil
+}
+
+// readMultipart reads a body written by writeMultipart and returns the JSON message
+// with the payloads restored. The restored message is limited to limit bytes, see
+// restoreBlobs.
+func readMultipart(r io.Reader, boundary string, limit int64) ([]byte, error) {
+	mr := multipart.NewReader(r, boundary)
+	var parts [][]byte
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, content)
+	}
+	if len(parts) == 0 {
+		return nil, errBlobInvalid
+	}
+	return restoreBlobs(parts[0], parts[1:], limit)
+}
+
+// multipartBody returns a reader which provides the JSON message of a multipart/related
+// body, or nil if h doesn't declare such a body. The JSON message is limited to limit
+// bytes once the payloads are restored. A limit of zero means no limit.
+func multipartBody(h http.Header, r io.Reader, limit int64) io.Reader {
+	mt, params, err := mime.ParseMediaType(h.Get("content-type"))
+	if err != nil || mt != multipartRelated {
+		return nil
+	}
+	return &lazyReader{read: func() ([]byte, error) {
+		data, err := readMultipart(r, params["boundary"], limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart message: %w", err)
+		}
+		return data, nil
+	}}
+}
+
+// lazyReader reads the result of the read function when it is first read.
+type lazyReader struct {
+	read func() ([]byte, error)
+	r    io.Reader
+}
+
+func (lr *lazyReader) Read(p []byte) (int, error) {
+	if lr.r == nil {
+		data, err := lr.read()
+		if err != nil {
+			return 0, err
+		}
+		lr.r = bytes.NewReader(data)
+	}
+	return lr.r.Read(p)
+}
+
+// acceptsMultipart reports whether the client of an HTTP request accepts responses
+// with binary payloads.
+func acceptsMultipart(r *http.Request) bool {
+	for _, accept := range r.Header.Values("accept") {
+		for _, v := range strings.Split(accept, ",") {
+			if mt, _, err := mime.ParseMediaType(strings.TrimSpace(v)); err == nil && mt == multipartRelated {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+type blobService struct{}
+
+func (blobService) Reverse(b Blob) Blob {
+	r := make(Blob, len(b))
+	for i, c := range b {
+		r[len(b)-1-i] = c
+	}
+	return r
+}
+
+func testBlob(n int) Blob {
+	b := make(Blob, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestExtractBlobs(t *testing.T) {
+	t.Parallel()
+
+	big := testBlob(40).String()
+	tests := []struct {
+		msg, want string
+		blobs     int
+	}{
+		{`{"a":"` + big + `","b":"0x0102","c":["` + big + `"]}`, `{"a":"$blob:0","b":"0x0102","c":["$blob:1"]}`, 2},
+		// Upper case and odd-length hex are not extracted.
+		{`["0x` + strings.ToUpper(big[2:]) + `","` + big[:len(big)-1] + `"]`, "", 0},
+		// Strings looking like references prevent extraction.
+		{`["` + big + `","$blob:0"]`, "", 0},
+		// Escaped quotes don't end strings.
+		{`["x\"` + big + `","` + big + `"]`, `["x\"` + big + `","$blob:0"]`, 1},
+	}
+	for _, test := range tests {
+		js, blobs := extractBlobs([]byte(test.msg), 32)
+		if len(blobs) != test.blobs {
+			t.Errorf("%s: got %d blobs, want %d", test.msg, len(blobs), test.blobs)
+			continue
+		}
+		if blobs == nil {
+			if string(js) != test.msg {
+				t.Errorf("%s: message modified: %s", test.msg, js)
+			}
+			continue
+		}
+		if string(js) != test.want {
+			t.Errorf("%s: wrong message %s", test.msg, js)
+		}
+		restored, err := unpackBlobs(packBlobs(js, blobs), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(restored) != test.msg {
+			t.Errorf("%s: wrong restored message %s", test.msg, restored)
+		}
+	}
+	if _, err := restoreBlobs([]byte(`["$blob:1"]`), [][]byte{{1}}, 0); err == nil {
+		t.Error("invalid reference accepted")
+	}
+	if _, err := restoreBlobs([]byte(`["$blob:0","$blob:0"]`), [][]byte{{1}}, 0); err == nil {
+		t.Error("repeated reference accepted")
+	}
+	if _, err := restoreBlobs([]byte(`["$blob:0"]`), [][]byte{{1}, {2}}, 0); err == nil {
+		t.Error("unreferenced payload accepted")
+	}
+	if _, err := restoreBlobs([]byte(`["$blob:0"]`), [][]byte{testBlob(8)}, 19); err != errBlobTooLarge {
+		t.Errorf("wrong error for oversized message: %v", err)
+	}
+	if _, err := restoreBlobs([]byte(`["$blob:0"]`), [][]byte{testBlob(8)}, 22); err != nil {
+		t.Errorf("message within limit rejected: %v", err)
+	}
+}
+
+func TestBinaryPayloadsHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("blob", blobService{})
+	server.SetBinaryPayloads(64)
+	var (
+		mu       sync.Mutex
+		reqType  string
+		respType string
+	)
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.ServeHTTP(w, r)
+		mu.Lock()
+		reqType, respType = mediaType(r.Header), mediaType(w.Header())
+		mu.Unlock()
+	}))
+	defer httpsrv.Close()
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithBinaryPayloads(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	blob := testBlob(4096)
+	var result Blob
+	if err := client.Call(&result, "blob_reverse", blob); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, blobService{}.Reverse(blob)) {
+		t.Fatal("wrong result")
+	}
+	mu.Lock()
+	if reqType != multipartRelated || respType != multipartRelated {
+		t.Errorf("wrong content types: request %q, response %q", reqType, respType)
+	}
+	mu.Unlock()
+
+	// Small blobs are sent as JSON.
+	if err := client.Call(&result, "blob_reverse", testBlob(8)); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if reqType != contentType || respType != contentType {
+		t.Errorf("wrong content types: request %q, response %q", reqType, respType)
+	}
+	mu.Unlock()
+}
+
+func TestBinaryPayloadsHTTPUnsupported(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("blob", blobService{})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithBinaryPayloads(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	blob := testBlob(4096)
+	var result Blob
+	if err := client.Call(&result, "blob_reverse", blob); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, blobService{}.Reverse(blob)) {
+		t.Fatal("wrong result")
+	}
+}
+
+func TestBinaryPayloadsWebsocket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("blob", blobService{})
+	server.SetBinaryPayloads(64)
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpsrv.URL, "http")
+
+	t.Run("client", func(t *testing.T) {
+		client, err := DialOptions(context.Background(), wsURL, WithBinaryPayloads(64))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		blob := testBlob(4096)
+		var result Blob
+		if err := client.Call(&result, "blob_reverse", blob); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(result, blobService{}.Reverse(blob)) {
+			t.Fatal("wrong result")
+		}
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		header := http.Header{BinaryPayloadHeader: {"1"}}
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if resp.Header.Get(BinaryPayloadHeader) == "" {
+			t.Fatal("server didn't accept binary payloads")
+		}
+		blob := testBlob(256)
+		req := `{"jsonrpc":"2.0","id":1,"method":"blob_reverse","params":["$blob:0"]}`
+		if err := conn.WriteMessage(websocket.BinaryMessage, packBlobs([]byte(req), [][]byte{blob})); err != nil {
+			t.Fatal(err)
+		}
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != websocket.BinaryMessage || !bytes.HasPrefix(data, blobMagic) {
+			t.Fatalf("response has no binary payload: %q", data)
+		}
+		data, err = unpackBlobs(data, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg struct{ Result Blob }
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(msg.Result, blobService{}.Reverse(blob)) {
+			t.Fatal("wrong result")
+		}
+	})
+
+	t.Run("not-negotiated", func(t *testing.T) {
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if resp.Header.Get(BinaryPayloadHeader) != "" {
+			t.Fatal("server accepted binary payloads without offer")
+		}
+		req := `{"jsonrpc":"2.0","id":1,"method":"blob_reverse","params":["` + testBlob(256).String() + `"]}`
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+			t.Fatal(err)
+		}
+		typ, _, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != websocket.TextMessage {
+			t.Fatal("response uses binary payloads")
+		}
+
+		// Binary payloads are rejected on this connection.
+		req = `{"jsonrpc":"2.0","id":2,"method":"blob_reverse","params":["$blob:0"]}`
+		if err := conn.WriteMessage(websocket.BinaryMessage, packBlobs([]byte(req), [][]byte{testBlob(256)})); err != nil {
+			t.Fatal(err)
+		}
+		if _, data, err := conn.ReadMessage(); err == nil && bytes.Contains(data, []byte(`"result"`)) {
+			t.Fatalf("binary payload accepted: %s", data)
+		}
+	})
+}
+
+// TestBinaryPayloadsLimits checks that messages with payloads which expand beyond the
+// request limits are rejected.
+func TestBinaryPayloadsLimits(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("blob", blobService{})
+	server.SetBinaryPayloads(64)
+	server.SetHTTPConfig(HTTPConfig{MaxRequestBytes: 4096})
+
+	tests := []struct {
+		name  string
+		req   string
+		blobs [][]byte
+	}{
+		{"repeated", `{"jsonrpc":"2.0","id":"$blob:0","method":"blob_reverse","params":["$blob:0"]}`, [][]byte{testBlob(64)}},
+		{"oversized", `{"jsonrpc":"2.0","id":1,"method":"blob_reverse","params":["$blob:0"]}`, [][]byte{testBlob(3000)}},
+	}
+
+	t.Run("websocket", func(t *testing.T) {
+		httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+		defer httpsrv.Close()
+		wsURL := "ws" + strings.TrimPrefix(httpsrv.URL, "http")
+		for _, test := range tests {
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{BinaryPayloadHeader: {"1"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, packBlobs([]byte(test.req), test.blobs)); err != nil {
+				t.Fatal(err)
+			}
+			if _, data, err := conn.ReadMessage(); err == nil && bytes.Contains(data, []byte(`"result"`)) {
+				t.Errorf("%s: message accepted: %s", test.name, data)
+			}
+			conn.Close()
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		httpsrv := httptest.NewServer(server)
+		defer httpsrv.Close()
+		for _, test := range tests {
+			var body bytes.Buffer
+			ct, err := writeMultipart(&body, []byte(test.req), test.blobs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.Post(httpsrv.URL, ct, &body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if bytes.Contains(data, []byte(`"result"`)) {
+				t.Errorf("%s: message accepted: %s", test.name, data)
+			}
+		}
+	})
+}
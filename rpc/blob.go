@@ -0,0 +1,249 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlobRef refers to a large binary result which is transferred outside of the JSON-RPC
+// connection. Methods producing very large outputs can store them with StoreBlob and
+// return the reference instead, keeping the RPC connection responsive. Clients retrieve
+// the content with Client.FetchBlob.
+type BlobRef struct {
+	Hash string `json:"hash"`          // content hash, "sha256:<hex>"
+	Size int64  `json:"size"`          // content size in bytes
+	URL  string `json:"url,omitempty"` // where the blob can be downloaded
+}
+
+const blobHashPrefix = "sha256:"
+
+var (
+	errBlobStoreFull    = errors.New("blob store full")
+	errNoBlobStore      = errors.New("blob store not configured")
+	errBlobNoURL        = errors.New("blob reference has no URL")
+	errBlobHashMismatch = errors.New("blob content hash mismatch")
+)
+
+// BlobStore holds blobs for download over HTTP. Blobs expire after a fixed time. The
+// store is an http.Handler serving GET requests for "<prefix>/<hash>", with support for
+// range requests, so interrupted downloads can be resumed.
+type BlobStore struct {
+	ttl      time.Duration
+	maxBytes int64
+
+	mu    sync.Mutex
+	blobs map[string]*blobEntry
+	used  int64
+}
+
+type blobEntry struct {
+	size    int64
+	data    []byte // set for in-memory blobs
+	path    string // set for file blobs
+	expires time.Time
+}
+
+// NewBlobStore creates a blob store. Blobs are kept for ttl. maxBytes limits the total
+// size of in-memory blobs, zero means no limit.
+func NewBlobStore(ttl time.Duration, maxBytes int64) *BlobStore {
+	return &BlobStore{ttl: ttl, maxBytes: maxBytes, blobs: make(map[string]*blobEntry)}
+}
+
+// Put stores data as a blob.
+func (s *BlobStore) Put(data []byte) (BlobRef, error) {
+	sum := sha256.Sum256(data)
+	ref := BlobRef{Hash: blobHashPrefix + hex.EncodeToString(sum[:]), Size: int64(len(data))}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	if e := s.blobs[ref.Hash]; e != nil {
+		e.expires = time.Now().Add(s.ttl)
+		return ref, nil
+	}
+	if s.maxBytes > 0 && s.used+ref.Size > s.maxBytes {
+		return BlobRef{}, errBlobStoreFull
+	}
+	s.used += ref.Size
+	s.blobs[ref.Hash] = &blobEntry{size: ref.Size, data: data, expires: time.Now().Add(s.ttl)}
+	return ref, nil
+}
+
+// PutFile stores the content of a file as a blob. The file is read when the blob is
+// downloaded, so it must not be modified or removed until the blob has expired.
+func (s *BlobStore) PutFile(path string) (BlobRef, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return BlobRef{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return BlobRef{}, err
+	}
+	ref := BlobRef{Hash: blobHashPrefix + hex.EncodeToString(h.Sum(nil)), Size: size}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	if e := s.blobs[ref.Hash]; e != nil {
+		e.expires = time.Now().Add(s.ttl)
+		return ref, nil
+	}
+	s.blobs[ref.Hash] = &blobEntry{size: size, path: path, expires: time.Now().Add(s.ttl)}
+	return ref, nil
+}
+
+// expire removes expired blobs. It must be called with s.mu held.
+func (s *BlobStore) expire() {
+	now := time.Now()
+	for hash, e := range s.blobs {
+		if now.After(e.expires) {
+			s.used -= int64(len(e.data))
+			delete(s.blobs, hash)
+		}
+	}
+}
+
+func (s *BlobStore) get(hash string) *blobEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	return s.blobs[hash]
+}
+
+// ServeHTTP serves blob downloads.
+func (s *BlobStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	hash := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+	e := s.get(hash)
+	if e == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("content-type", "application/octet-stream")
+	w.Header().Set("etag", `"`+hash+`"`)
+	if e.data != nil {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(e.data))
+		return
+	}
+	f, err := os.Open(e.path)
+	if err != nil {
+		http.Error(w, "blob unavailable", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	http.ServeContent(w, r, "", time.Time{}, io.NewSectionReader(f, 0, e.size))
+}
+
+// blobConfig is the blob store configuration of a server.
+type blobConfig struct {
+	store   *BlobStore
+	baseURL string
+}
+
+type blobConfigKey struct{}
+
+// SetBlobStore configures the blob store used by StoreBlob in methods of this server.
+// baseURL is the address at which the store is served, e.g. "https://node/blobs". The
+// store must be made reachable there by the application, for example by registering it
+// on an http.ServeMux.
+func (s *Server) SetBlobStore(store *BlobStore, baseURL string) {
+	var cfg *blobConfig
+	if store != nil {
+		cfg = &blobConfig{store: store, baseURL: strings.TrimSuffix(baseURL, "/")}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.blobs = cfg })
+}
+
+// StoreBlob stores data in the blob store of the server handling the current call and
+// returns a reference which can be sent to the client instead of the data.
+func StoreBlob(ctx context.Context, data []byte) (BlobRef, error) {
+	cfg, _ := ctx.Value(blobConfigKey{}).(*blobConfig)
+	if cfg == nil {
+		return BlobRef{}, errNoBlobStore
+	}
+	ref, err := cfg.store.Put(data)
+	if err != nil {
+		return BlobRef{}, err
+	}
+	ref.URL = cfg.baseURL + "/" + ref.Hash
+	return ref, nil
+}
+
+// FetchBlob downloads the blob referenced by ref and writes it to w. The content is
+// verified against the hash of the reference. For HTTP clients, the client's HTTP
+// client and headers are used for the download.
+func (c *Client) FetchBlob(ctx context.Context, ref BlobRef, w io.Writer) error {
+	if ref.URL == "" {
+		return errBlobNoURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.URL, nil)
+	if err != nil {
+		return err
+	}
+	httpClient := http.DefaultClient
+	if hc, ok := c.writeConn.(*httpConn); ok {
+		httpClient = hc.client
+		hc.mu.Lock()
+		req.Header = hc.headers.Clone()
+		hc.mu.Unlock()
+		if hc.auth != nil {
+			if err := hc.auth(req.Header); err != nil {
+				return err
+			}
+		}
+		req.Header.Del("content-type")
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Header: resp.Header}
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), io.LimitReader(resp.Body, ref.Size+1))
+	if err != nil {
+		return err
+	}
+	if n != ref.Size {
+		return fmt.Errorf("blob size mismatch: got %d bytes, want %d", n, ref.Size)
+	}
+	if blobHashPrefix+hex.EncodeToString(h.Sum(nil)) != ref.Hash {
+		return errBlobHashMismatch
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type blobTestService struct{}
+
+func (blobTestService) Dump(ctx context.Context, n int) (BlobRef, error) {
+	return StoreBlob(ctx, blobTestData(n))
+}
+
+func blobTestData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	return data
+}
+
+func TestBlobFetch(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("blob", blobTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	store := NewBlobStore(time.Minute, 0)
+	mux := http.NewServeMux()
+	mux.Handle("/", server)
+	mux.Handle("/blobs/", store)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	server.SetBlobStore(store, ts.URL+"/blobs/")
+
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var ref BlobRef
+	if err := client.Call(&ref, "blob_dump", 100000); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Size != 100000 || ref.URL != ts.URL+"/blobs/"+ref.Hash {
+		t.Fatalf("wrong reference %+v", ref)
+	}
+	var buf bytes.Buffer
+	if err := client.FetchBlob(context.Background(), ref, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), blobTestData(100000)) {
+		t.Fatal("wrong blob content")
+	}
+
+	// Range requests are supported.
+	req, _ := http.NewRequest(http.MethodGet, ref.URL, nil)
+	req.Header.Set("Range", "bytes=10-19")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(part, blobTestData(20)[10:]) {
+		t.Fatalf("wrong range response: status %d, body %x", resp.StatusCode, part)
+	}
+
+	// The content is verified.
+	bad := ref
+	bad.Size--
+	if err := client.FetchBlob(context.Background(), bad, io.Discard); err == nil {
+		t.Fatal("expected size mismatch error")
+	}
+	other, _ := store.Put([]byte("other"))
+	bad = ref
+	bad.Hash = other.Hash
+	bad.URL = ts.URL + "/blobs/" + ref.Hash
+	if err := client.FetchBlob(context.Background(), bad, io.Discard); !errors.Is(err, errBlobHashMismatch) {
+		t.Fatalf("expected hash mismatch, got %v", err)
+	}
+}
+
+func TestBlobStoreExpiry(t *testing.T) {
+	t.Parallel()
+
+	store := NewBlobStore(10*time.Millisecond, 10)
+	ref, err := store.Put([]byte("12345678"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put([]byte("abcdefgh")); !errors.Is(err, errBlobStoreFull) {
+		t.Fatalf("expected store full error, got %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if store.get(ref.Hash) != nil {
+		t.Fatal("blob not expired")
+	}
+	if _, err := store.Put([]byte("abcdefgh")); err != nil {
+		t.Fatalf("store not freed after expiry: %v", err)
+	}
+}
+
+func TestStoreBlobNotConfigured(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	server.RegisterName("blob", blobTestService{})
+	client := DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "blob_dump", 10)
+	if err == nil || err.Error() != errNoBlobStore.Error() {
+		t.Fatalf("expected %q, got %v", errNoBlobStore, err)
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// CachePolicy describes the cacheability of the results of a method.
+type CachePolicy struct {
+	// TTL is the time for which a result may be reused.
+	TTL time.Duration
+
+	// VaryBy lists the positions of the parameters which determine the result. Calls
+	// which differ only in other parameters share cache entries. If nil, all parameters
+	// are significant.
+	VaryBy []int
+
+	// Cacheable reports whether the result of a call with the given parameters can be
+	// cached. For example, blocks may only be cached when they are requested by number
+	// and not by tag. If nil, all results are cacheable.
+	Cacheable func(params json.RawMessage) bool
+}
+
+// Cache stores results of method calls on the server, see Server.SetCache. Keys are
+// derived from the method name and parameters. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	// Get returns the cached result for key and its remaining TTL.
+	Get(key string) (result json.RawMessage, ttl time.Duration, ok bool)
+	// Put stores a result for the given TTL. The result must not be modified.
+	Put(key string, result json.RawMessage, ttl time.Duration)
+}
+
+// CachePolicyProvider is implemented by services which declare the cacheability of
+// their methods. CachePolicies is called when the service is registered. The returned
+// map is keyed by RPC method name without the namespace, e.g. "getBlockByHash" for
+// "eth_getBlockByHash". CachePolicies itself is not exposed as an RPC method.
+//
+// Declared policies apply to the server-side response cache (see Server.SetResponseCache
+// and Server.SetCache), the Cache-Control header of HTTP responses and the client
+// response cache (see WithResponseCache). Method handlers can override the TTL of
+// individual results using SetResponseTTL.
+type CachePolicyProvider interface {
+	CachePolicies() map[string]CachePolicy
+}
+
+// SetResponseCache enables caching of method results on the server. Results of methods
+// with a declared CachePolicy are reused until their TTL expires. At most maxEntries
+// results are kept, least recently used ones are evicted first. A size of zero disables
+// the cache. Expiry is driven by the clock set through SetClock before the call.
+func (s *Server) SetResponseCache(maxEntries int) {
+	var cache Cache
+	if maxEntries > 0 {
+		cache = &memoryCache{ttl: newTTLCache(maxEntries, 0), clock: s.clock}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.responseCache = cache })
+}
+
+// SetCache enables caching of method results on the server in the given cache, which
+// can be backed by external storage. Results of methods with a CachePolicy are reused
+// until their TTL expires. Policies are keyed by method name, e.g. "eth_chainId", and
+// take precedence over the policies declared by services. Results of services created by
+// a ServiceFactory are never cached. Passing a nil cache disables caching.
+//
+// Cache hits are answered before the call parameters are decoded and before middlewares
+// run.
+func (s *Server) SetCache(cache Cache, policies map[string]CachePolicy) {
+	policies = maps.Clone(policies)
+	s.services.updateConfig(func(c *registryConfig) {
+		c.responseCache = cache
+		c.cachePolicies = policies
+	})
+}
+
+// cachePolicy returns the cache policy of a call. It returns nil for calls whose
+// results can't be cached.
+func (cfg *registryConfig) cachePolicy(msg *jsonrpcMessage, callb *callback, factory bool) *CachePolicy {
+	if factory {
+		return nil
+	}
+	policy := callb.cachePolicy
+	if p, ok := cfg.cachePolicies[msg.Method]; ok {
+		policy = &p
+	}
+	if policy != nil && policy.Cacheable != nil && !policy.Cacheable(msg.Params) {
+		return nil
+	}
+	return policy
+}
+
+// NewMemoryCache creates an in-memory Cache. At most maxEntries results taking up at
+// most maxBytes are kept, least recently used ones are evicted first. A maxBytes of zero
+// only limits the number of entries.
+func NewMemoryCache(maxEntries int, maxBytes int64) Cache {
+	return &memoryCache{ttl: newTTLCache(maxEntries, maxBytes), clock: mclock.System{}}
+}
+
+type memoryCache struct {
+	ttl   *ttlCache
+	clock mclock.Clock
+}
+
+func (c *memoryCache) Get(key string) (json.RawMessage, time.Duration, bool) {
+	return c.ttl.get(key, c.clock.Now())
+}
+
+func (c *memoryCache) Put(key string, result json.RawMessage, ttl time.Duration) {
+	c.ttl.put(key, result, ttl, c.clock.Now())
+}
+
+// defaultClientCacheMaxTTL is the longest time a client keeps a cached result unless
+// configured otherwise with WithResponseCacheMaxTTL.
+const defaultClientCacheMaxTTL = time.Hour
+
+// WithResponseCache makes the client cache results of calls made through Call and
+// CallContext for the TTL announced by the server. At most maxEntries results are kept.
+// TTLs are limited to one hour, see WithResponseCacheMaxTTL.
+func WithResponseCache(maxEntries int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.responseCacheSize = maxEntries
+	})
+}
+
+// WithResponseCacheMaxTTL limits the time results are kept in the client response
+// cache, regardless of the TTL announced by the server. See WithResponseCache.
+func WithResponseCacheMaxTTL(d time.Duration) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.responseCacheMaxTTL = d
+	})
+}
+
+// clientCacheTTL converts the cache TTL announced by the server to a duration, limiting
+// it to maxTTL. It returns zero if the result must not be cached.
+func clientCacheTTL(ttlMs int64, maxTTL time.Duration) time.Duration {
+	if ttlMs <= 0 {
+		return 0
+	}
+	if ttlMs >= maxTTL.Milliseconds() {
+		return maxTTL
+	}
+	return time.Duration(ttlMs) * time.Millisecond
+}
+
+type responseMetaKey struct{}
+
+// responseMeta holds the cache TTL chosen by a method handler through SetResponseTTL.
+type responseMeta struct {
+	mu  sync.Mutex
+	ttl *time.Duration
+}
+
+// SetResponseTTL overrides the cache TTL of the result of the current call. A TTL of
+// zero marks the result as not cacheable. Results of methods without a declared
+// CachePolicy are not cached by the server, but the TTL is still announced to the
+// client. SetResponseTTL has no effect when ctx does not belong to a method call.
+func SetResponseTTL(ctx context.Context, ttl time.Duration) {
+	if meta, ok := ctx.Value(responseMetaKey{}).(*responseMeta); ok {
+		meta.mu.Lock()
+		meta.ttl = &ttl
+		meta.mu.Unlock()
+	}
+}
+
+// resultTTL returns the TTL of a result, given the policy of its method.
+func (m *responseMeta) resultTTL(policy *CachePolicy) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.ttl != nil:
+		return max(*m.ttl, 0)
+	case policy != nil:
+		return policy.TTL
+	default:
+		return 0
+	}
+}
+
+// callCacheKey returns the cache key of a call with the given parameters. Only parameters
+// at the positions in varyBy are included, or all of them if varyBy is nil. Calls with
+// parameters which are not a JSON array cannot be cached.
+func callCacheKey(method string, params json.RawMessage, varyBy []int) (string, bool) {
+	if len(params) == 0 {
+		return method, true
+	}
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", false
+	}
+	if varyBy == nil {
+		varyBy = make([]int, len(args))
+		for i := range varyBy {
+			varyBy[i] = i
+		}
+	}
+	key := []byte(method)
+	for _, pos := range varyBy {
+		key = append(key, 0)
+		key = strconv.AppendInt(key, int64(pos), 10)
+		key = append(key, ':')
+		if pos < 0 || pos >= len(args) {
+			key = append(key, "null"...)
+			continue
+		}
+		arg := args[pos]
+		if canonical, err := canonicalizeJSON(arg); err == nil {
+			arg = canonical
+		}
+		key = append(key, arg...)
+	}
+	return string(key), true
+}
+
+// setCacheTTL announces the cache TTL of a response to the client.
+func (msg *jsonrpcMessage) setCacheTTL(ttl time.Duration) {
+	msg.cacheTTL = ttl
+	msg.updateResponseExt(func(ext *responseExt) { ext.CacheTTLMs = max(ttl.Milliseconds(), 1) })
+}
+
+// responseCacheTTL returns the cache TTL of an outgoing message or batch. The TTL of a
+// batch is the shortest TTL of its responses.
+func responseCacheTTL(v interface{}) time.Duration {
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		return v.cacheTTL
+	case []*jsonrpcMessage:
+		var ttl time.Duration
+		for i, msg := range v {
+			if msg == nil || msg.cacheTTL <= 0 {
+				return 0
+			}
+			if i == 0 || msg.cacheTTL < ttl {
+				ttl = msg.cacheTTL
+			}
+		}
+		return ttl
+	}
+	return 0
+}
+
+// ttlCache is a size-bounded cache of results with per-entry expiry.
+type ttlCache struct {
+	mu       sync.Mutex
+	max      int
+	maxBytes int64 // limit of size, zero if unlimited
+	size     int64 // total size of keys and values
+	entries  map[string]*list.Element
+	lru      list.List // front is most recently used
+}
+
+type ttlCacheEntry struct {
+	key     string
+	value   json.RawMessage
+	expires mclock.AbsTime
+}
+
+func newTTLCache(maxEntries int, maxBytes int64) *ttlCache {
+	return &ttlCache{max: maxEntries, maxBytes: maxBytes, entries: make(map[string]*list.Element)}
+}
+
+func (e *ttlCacheEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// get returns the cached value for key and its remaining TTL at time now.
+func (c *ttlCache) get(key string, now mclock.AbsTime) (json.RawMessage, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+	entry := elem.Value.(*ttlCacheEntry)
+	remaining := entry.expires.Sub(now)
+	if remaining <= 0 {
+		c.remove(elem)
+		return nil, 0, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.value, remaining, true
+}
+
+// put stores value under key for the given TTL, starting at time now.
+func (c *ttlCache) put(key string, value json.RawMessage, ttl time.Duration, now mclock.AbsTime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &ttlCacheEntry{key: key, value: value, expires: now.Add(ttl)}
+	if c.maxBytes > 0 && entry.size() > c.maxBytes {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += entry.size()
+	for c.lru.Len() > c.max || (c.maxBytes > 0 && c.size > c.maxBytes) {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *ttlCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*ttlCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size()
+}
@@ -0,0 +1,352 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+type cacheTestService struct {
+	calls atomic.Int64
+}
+
+func (s *cacheTestService) Block(number int, fullTx bool) int64 {
+	return s.calls.Add(1)
+}
+
+func (s *cacheTestService) Latest(ctx context.Context, number int) int64 {
+	if number < 0 {
+		SetResponseTTL(ctx, 0)
+	}
+	return s.calls.Add(1)
+}
+
+func (s *cacheTestService) Dynamic(ctx context.Context) int64 {
+	SetResponseTTL(ctx, time.Minute)
+	return s.calls.Add(1)
+}
+
+func (s *cacheTestService) CachePolicies() map[string]CachePolicy {
+	return map[string]CachePolicy{
+		"block":  {TTL: time.Minute, VaryBy: []int{0}},
+		"latest": {TTL: time.Minute},
+	}
+}
+
+type badCachePolicyService struct{}
+
+func (badCachePolicyService) Foo() {}
+
+func (badCachePolicyService) CachePolicies() map[string]CachePolicy {
+	return map[string]CachePolicy{"bar": {TTL: time.Second}}
+}
+
+func TestServerResponseCache(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(cacheTestService)
+	server.RegisterName("cache", svc)
+	server.SetResponseCache(10)
+	client := DialInProc(server)
+	defer client.Close()
+
+	call := func(method string, args ...interface{}) int64 {
+		t.Helper()
+		var n int64
+		if err := client.Call(&n, method, args...); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	// Calls differing only in parameters outside VaryBy share an entry.
+	if n := call("cache_block", 1, true); n != 1 {
+		t.Fatalf("first call returned %d", n)
+	}
+	if n := call("cache_block", 1, false); n != 1 {
+		t.Fatalf("expected cached result, got %d", n)
+	}
+	if n := call("cache_block", 2, true); n != 2 {
+		t.Fatalf("expected new result for different block, got %d", n)
+	}
+	// SetResponseTTL can opt individual results out of caching.
+	call("cache_latest", -1)
+	call("cache_latest", -1)
+	if calls := svc.calls.Load(); calls != 4 {
+		t.Fatalf("method called %d times, want 4", calls)
+	}
+	// CachePolicies is not exposed.
+	if err := client.Call(nil, "cache_cachePolicies"); err == nil {
+		t.Fatal("CachePolicies callable over RPC")
+	}
+}
+
+func TestCachePolicyUnknownMethod(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	err := server.RegisterName("bad", badCachePolicyService{})
+	if err == nil || !strings.Contains(err.Error(), "bad_bar") {
+		t.Fatalf("expected error for unknown method, got %v", err)
+	}
+}
+
+func TestResponseCacheControlHeader(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("cache", new(cacheTestService))
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"cache_block","params":[1,true]}`, "max-age=60"},
+		{`{"jsonrpc":"2.0","id":1,"method":"cache_latest","params":[-1]}`, ""},
+		{`[{"jsonrpc":"2.0","id":1,"method":"cache_block","params":[1,true]},{"jsonrpc":"2.0","id":2,"method":"cache_dynamic"}]`, "max-age=60"},
+		{`[{"jsonrpc":"2.0","id":1,"method":"cache_block","params":[1,true]},{"jsonrpc":"2.0","id":2,"method":"cache_latest","params":[-1]}]`, ""},
+	}
+	for i, test := range tests {
+		resp, err := http.Post(httpsrv.URL, contentType, strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("Cache-Control"); got != test.want {
+			t.Errorf("test %d: wrong Cache-Control %q, want %q", i, got, test.want)
+		}
+	}
+}
+
+func TestClientResponseCache(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(cacheTestService)
+	server.RegisterName("cache", svc)
+	client := dialInProcWithOptions(server, WithResponseCache(10))
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		var n int64
+		if err := client.Call(&n, "cache_dynamic"); err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Fatalf("call %d: expected cached result, got %d", i, n)
+		}
+	}
+	// The client cache distinguishes all parameters.
+	client.Call(nil, "cache_block", 1, true)
+	client.Call(nil, "cache_block", 1, false)
+	if calls := svc.calls.Load(); calls != 3 {
+		t.Fatalf("method called %d times, want 3", calls)
+	}
+}
+
+func TestClientResponseCacheMaxTTL(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(cacheTestService)
+	server.RegisterName("cache", svc)
+	clock := new(mclock.Simulated)
+	client := dialInProcWithOptions(server, WithClock(clock), WithResponseCache(10), WithResponseCacheMaxTTL(time.Second))
+	defer client.Close()
+
+	// The result announced with a TTL of one minute is only kept for a second.
+	var n int64
+	client.Call(&n, "cache_dynamic")
+	clock.Run(2 * time.Second)
+	if err := client.Call(&n, "cache_dynamic"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected expired result, got %d", n)
+	}
+}
+
+func TestClientCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ms   int64
+		want time.Duration
+	}{
+		{1500, 1500 * time.Millisecond},
+		{0, 0},
+		{-1, 0},
+		{math.MaxInt64, time.Hour},
+	}
+	for _, test := range tests {
+		if got := clientCacheTTL(test.ms, time.Hour); got != test.want {
+			t.Errorf("%d: got %v, want %v", test.ms, got, test.want)
+		}
+	}
+}
+
+func TestTTLCache(t *testing.T) {
+	t.Parallel()
+
+	var now mclock.AbsTime
+	c := newTTLCache(2, 0)
+	c.put("a", []byte("1"), time.Minute, now)
+	c.put("b", []byte("2"), time.Minute, now)
+	c.get("a", now)
+	c.put("c", []byte("3"), time.Minute, now)
+	if _, _, ok := c.get("b", now); ok {
+		t.Error("least recently used entry not evicted")
+	}
+	if _, _, ok := c.get("a", now); !ok {
+		t.Error("recently used entry evicted")
+	}
+	c.put("d", []byte("4"), -time.Second, now)
+	if _, _, ok := c.get("d", now); ok {
+		t.Error("expired entry returned")
+	}
+
+	// Size limit.
+	c = newTTLCache(10, 10)
+	c.put("a", []byte("1234"), time.Minute, now)
+	c.put("b", []byte("1234"), time.Minute, now)
+	c.put("c", []byte("1234"), time.Minute, now)
+	if _, _, ok := c.get("a", now); ok {
+		t.Error("entry exceeding size limit not evicted")
+	}
+	if _, _, ok := c.get("c", now); !ok || c.size != 10 {
+		t.Errorf("wrong entries after eviction, size %d", c.size)
+	}
+	c.put("d", make([]byte, 10), time.Minute, now)
+	if _, _, ok := c.get("d", now); ok || c.size != 10 {
+		t.Error("stored entry larger than cache")
+	}
+}
+
+type mapCache struct {
+	mu      sync.Mutex
+	entries map[string]json.RawMessage
+	gets    int
+}
+
+func (c *mapCache) Get(key string) (json.RawMessage, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	v, ok := c.entries[key]
+	return v, time.Minute, ok
+}
+
+func (c *mapCache) Put(key string, result json.RawMessage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = result
+}
+
+func TestServerSetCache(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	var middlewareCalls atomic.Int64
+	server.SetMiddlewares([]Middleware{
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			middlewareCalls.Add(1)
+			return next(ctx, method, args)
+		},
+	})
+	cache := &mapCache{entries: make(map[string]json.RawMessage)}
+	server.SetCache(cache, map[string]CachePolicy{
+		"test_echo": {
+			TTL:    time.Minute,
+			VaryBy: []int{0},
+			// Don't cache "latest".
+			Cacheable: func(params json.RawMessage) bool {
+				return !strings.Contains(string(params), "latest")
+			},
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result echoResult
+	for i := 0; i < 3; i++ {
+		if err := client.Call(&result, "test_echo", "x", i); err != nil {
+			t.Fatal(err)
+		}
+		if result.Int != 0 {
+			t.Fatalf("call %d: expected cached result, got %+v", i, result)
+		}
+	}
+	if n := middlewareCalls.Load(); n != 1 {
+		t.Fatalf("middleware ran %d times, want 1", n)
+	}
+	for i := 0; i < 2; i++ {
+		client.Call(&result, "test_echo", "latest", i)
+	}
+	if n := middlewareCalls.Load(); n != 3 || len(cache.entries) != 1 {
+		t.Fatalf("uncacheable call served from cache: %d middleware calls, %d entries", n, len(cache.entries))
+	}
+	// Methods without policy don't use the cache.
+	cache.gets = 0
+	client.Call(nil, "test_null")
+	if cache.gets != 0 {
+		t.Fatal("cache used for method without policy")
+	}
+
+	server.SetCache(nil, nil)
+	client.Call(&result, "test_echo", "x", 5)
+	if result.Int != 5 {
+		t.Fatal("cache used after disabling")
+	}
+}
+
+func TestCallCacheKey(t *testing.T) {
+	t.Parallel()
+
+	k1, _ := callCacheKey("m", []byte(`[{"b":1,"a":2}, 1.0]`), []int{0})
+	k2, _ := callCacheKey("m", []byte(`[{"a":2,"b":1}, 7]`), []int{0})
+	if k1 != k2 {
+		t.Errorf("keys differ: %q != %q", k1, k2)
+	}
+	k3, _ := callCacheKey("m", []byte(`[{"a":2,"b":1}]`), nil)
+	k4, _ := callCacheKey("m", []byte(`[{"a":2,"b":1}, null]`), nil)
+	if k3 == k4 {
+		t.Error("keys of calls with different parameters are equal")
+	}
+	if _, ok := callCacheKey("m", []byte(`{"a":1}`), nil); ok {
+		t.Error("named parameters should not be cacheable")
+	}
+}
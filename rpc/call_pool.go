@@ -0,0 +1,175 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// CallWorkers configures a bounded pool of goroutines executing method calls, see
+// SetCallWorkers.
+type CallWorkers struct {
+	// Workers is the total number of worker goroutines. Zero disables the pool.
+	Workers int
+
+	// QueueDepth is the number of calls waiting for a worker. While the queue is full,
+	// the server stops reading requests from the connection submitting the call.
+	QueueDepth int
+
+	// Reserved maps namespaces to a number of workers which execute only calls of that
+	// namespace. Calls of a namespace with reservations use the shared workers when all
+	// of its reserved workers are busy. Workers is raised if needed, so that at least
+	// one worker is shared.
+	Reserved map[string]int
+}
+
+// SetCallWorkers makes the server execute method calls on a bounded pool of worker
+// goroutines instead of starting a goroutine for every request. This reduces scheduler
+// churn at very high request rates, and provides backpressure: when all workers are busy
+// and the queue is full, connections submitting calls are not read until a worker frees
+// up. Reserved workers keep critical namespaces responsive while other calls saturate
+// the pool. A batch is executed by a single worker, which is reserved for the namespace
+// of the batch only if all of its calls belong to it.
+//
+// Methods executed by the pool must not wait for calls arriving later on the same
+// connection, including responses to reverse calls made through ClientFromContext,
+// because reading from the connection stops while the queue is full.
+//
+// Calling SetCallWorkers with zero workers disables the pool. Calls already submitted to
+// a replaced pool still run on its workers.
+func (s *Server) SetCallWorkers(cfg CallWorkers) {
+	var pool *callPool
+	if cfg.Workers > 0 {
+		pool = newCallPool(cfg)
+	}
+	if old := s.services.workers.Swap(pool); old != nil {
+		old.stop()
+	}
+}
+
+// callPool implements CallWorkers.
+type callPool struct {
+	shared     chan func()
+	reserved   map[string]chan func()
+	submitting atomic.Int64 // number of submit calls in progress
+	quit       chan struct{}
+}
+
+func newCallPool(cfg CallWorkers) *callPool {
+	p := &callPool{
+		shared:   make(chan func(), cfg.QueueDepth),
+		reserved: make(map[string]chan func(), len(cfg.Reserved)),
+		quit:     make(chan struct{}),
+	}
+	var nreserved int
+	for ns, n := range cfg.Reserved {
+		if n <= 0 {
+			continue
+		}
+		ch := make(chan func())
+		p.reserved[ns] = ch
+		for i := 0; i < n; i++ {
+			go p.work(ch, false)
+		}
+		nreserved += n
+	}
+	for i := 0; i < max(cfg.Workers-nreserved, 1); i++ {
+		go p.work(p.shared, true)
+	}
+	return p
+}
+
+// submit hands fn to a worker, waiting while all workers are busy and the queue is
+// full. Calls of a namespace with reservations go to an idle reserved worker if there
+// is one. It returns false if the pool was stopped, in which case fn was not run.
+func (p *callPool) submit(namespace string, fn func()) bool {
+	p.submitting.Add(1)
+	defer p.submitting.Add(-1)
+
+	select {
+	case <-p.quit:
+		return false
+	default:
+	}
+	reserved := p.reserved[namespace] // nil if there is no reservation
+	if reserved != nil {
+		select {
+		case reserved <- fn:
+			return true
+		default:
+		}
+	}
+	select {
+	case p.shared <- fn:
+		return true
+	case reserved <- fn:
+		return true
+	case <-p.quit:
+		return false
+	}
+}
+
+// stop terminates the workers once the calls submitted so far have been executed.
+func (p *callPool) stop() {
+	close(p.quit)
+}
+
+func (p *callPool) work(queue chan func(), shared bool) {
+	for {
+		select {
+		case fn := <-queue:
+			fn()
+		case <-p.quit:
+			if shared {
+				p.drain()
+			}
+			return
+		}
+	}
+}
+
+// drain runs the calls which were queued before the pool was stopped. A submit racing
+// with stop may still add one, so draining continues until no submit is in progress.
+func (p *callPool) drain() {
+	for {
+		select {
+		case fn := <-p.shared:
+			fn()
+		default:
+			if p.submitting.Load() == 0 && len(p.shared) == 0 {
+				return
+			}
+			runtime.Gosched()
+		}
+	}
+}
+
+// poolNamespace returns the namespace shared by all calls, or the empty string if they
+// belong to different namespaces.
+func poolNamespace(calls []*jsonrpcMessage) string {
+	if len(calls) == 0 {
+		return ""
+	}
+	ns := calls[0].namespace()
+	for _, msg := range calls[1:] {
+		if msg.namespace() != ns {
+			return ""
+		}
+	}
+	return ns
+}
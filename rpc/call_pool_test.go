@@ -0,0 +1,185 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type workerTestService struct {
+	running atomic.Int32
+	release chan struct{}
+}
+
+func (s *workerTestService) Block() {
+	s.running.Add(1)
+	defer s.running.Add(-1)
+	<-s.release
+}
+
+func (s *workerTestService) waitRunning(t *testing.T, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.running.Load() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %d running calls, have %d", n, s.running.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCallWorkersBound(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &workerTestService{release: make(chan struct{})}
+	server.RegisterName("pool", svc)
+	server.RegisterName("admin", pushService{})
+	server.SetCallWorkers(CallWorkers{Workers: 2, QueueDepth: 4})
+	client := DialInProc(server)
+	defer client.Close()
+
+	errc := make(chan error, 4)
+	for range 4 {
+		go func() { errc <- client.Call(nil, "pool_block") }()
+	}
+	svc.waitRunning(t, 2)
+	time.Sleep(50 * time.Millisecond)
+	if n := svc.running.Load(); n != 2 {
+		t.Fatalf("%d calls running on two workers", n)
+	}
+	close(svc.release)
+	for range 4 {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCallWorkersReserved(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &workerTestService{release: make(chan struct{})}
+	server.RegisterName("pool", svc)
+	server.RegisterName("admin", pushService{})
+	server.SetCallWorkers(CallWorkers{Workers: 2, Reserved: map[string]int{"admin": 1}})
+	c1, c2, c3 := DialInProc(server), DialInProc(server), DialInProc(server)
+	defer c1.Close()
+	defer c2.Close()
+	defer c3.Close()
+
+	// Occupy the shared worker.
+	errc := make(chan error, 2)
+	go func() { errc <- c1.Call(nil, "pool_block") }()
+	svc.waitRunning(t, 1)
+
+	// Other calls wait for the shared worker, while the reserved namespace is served.
+	go func() { errc <- c2.Call(nil, "test_noArgsRets") }()
+	var greeting string
+	if err := c3.Call(&greeting, "admin_greet", "pool"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errc:
+		t.Fatalf("call completed while the shared worker is busy: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(svc.release)
+	for range 2 {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCallWorkersDisable(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &workerTestService{release: make(chan struct{})}
+	server.RegisterName("pool", svc)
+	server.RegisterName("admin", pushService{})
+	server.SetCallWorkers(CallWorkers{Workers: 1})
+	client := DialInProc(server)
+	defer client.Close()
+
+	server.SetCallWorkers(CallWorkers{})
+	errc := make(chan error, 3)
+	for range 3 {
+		go func() { errc <- client.Call(nil, "pool_block") }()
+	}
+	svc.waitRunning(t, 3)
+	close(svc.release)
+	for range 3 {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPoolNamespace(t *testing.T) {
+	calls := func(methods ...string) []*jsonrpcMessage {
+		msgs := make([]*jsonrpcMessage, len(methods))
+		for i, m := range methods {
+			msgs[i] = &jsonrpcMessage{Method: m}
+		}
+		return msgs
+	}
+	if ns := poolNamespace(calls("eth_call", "eth_blockNumber")); ns != "eth" {
+		t.Errorf("wrong namespace %q", ns)
+	}
+	if ns := poolNamespace(calls("eth_call", "debug_traceCall")); ns != "" {
+		t.Errorf("wrong namespace %q for mixed batch", ns)
+	}
+	if ns := poolNamespace(nil); ns != "" {
+		t.Errorf("wrong namespace %q without calls", ns)
+	}
+}
+
+func TestCallPoolStopRunsQueuedCalls(t *testing.T) {
+	t.Parallel()
+
+	pool := newCallPool(CallWorkers{Workers: 1, QueueDepth: 8})
+	block := make(chan struct{})
+	var ran atomic.Int32
+	pool.submit("", func() { <-block })
+	for range 5 {
+		pool.submit("", func() { ran.Add(1) })
+	}
+	pool.stop()
+	close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for ran.Load() != 5 {
+		if ctx.Err() != nil {
+			t.Fatalf("only %d of 5 queued calls ran after stop", ran.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if pool.submit("", func() {}) {
+		t.Fatal("submit succeeded on stopped pool")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SetCanonicalJSON enables canonical JSON encoding of results and notification payloads.
+// In canonical form, object keys are sorted, insignificant whitespace is removed and
+// numbers are formatted consistently: integers are written without fraction or exponent,
+// other numbers use the shortest representation which round-trips through float64. This
+// is useful for deployments which hash or sign responses.
+//
+// Canonicalization decodes and re-encodes every result, which adds processing overhead.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCanonicalJSON(enabled bool) {
+	s.canonicalJSON = enabled
+}
+
+// canonicalizeJSON re-encodes a JSON value in canonical form.
+func canonicalizeJSON(raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	// encoding/json sorts map keys, so only numbers need to be handled here.
+	return json.Marshal(canonicalNumbers(v))
+}
+
+func canonicalNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			v[k] = canonicalNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = canonicalNumbers(elem)
+		}
+	case json.Number:
+		return canonicalNumber(v)
+	}
+	return v
+}
+
+// canonicalNumber formats a JSON number. Integers are kept as-is to preserve precision.
+func canonicalNumber(n json.Number) json.Number {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		if s == "-0" {
+			return "0"
+		}
+		return n
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return n
+	}
+	if f == 0 {
+		return "0"
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	// Remove leading zeros of the exponent, e.g. 1e-07 becomes 1e-7.
+	mant, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	return json.Number(mant + "e" + exp[:1] + strings.TrimLeft(exp[1:], "0"))
+}
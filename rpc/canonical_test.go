@@ -0,0 +1,76 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct{ input, want string }{
+		{`{"b": 1, "a": [true, null, {"d": 1, "c": 2}]}`, `{"a":[true,null,{"c":2,"d":1}],"b":1}`},
+		{`[1.0, 1.50, 1e3, 1E-7, 2.5e21, -0, 0.0, 123456789012345678901234567890]`, `[1,1.5,1000,1e-7,2.5e+21,0,0,123456789012345678901234567890]`},
+		{`"aA"`, `"aA"`},
+	}
+	for _, test := range tests {
+		got, err := canonicalizeJSON([]byte(test.input))
+		if err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.input, got, test.want)
+		}
+	}
+}
+
+func TestServerCanonicalJSON(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	server.SetCanonicalJSON(true)
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	// echoResult has its fields in non-alphabetical order.
+	var result json.RawMessage
+	if err := client.Call(&result, "test_echo", "x", 1, &echoArgs{S: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Args":{"S":"y"},"Int":1,"String":"x"}`; string(result) != want {
+		t.Fatalf("wrong result %s, want %s", result, want)
+	}
+
+	// Notifications are canonicalized too.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ch := make(chan json.RawMessage, 1)
+	sub, err := client.Subscribe(ctx, "nftest", ch, "someSubscription", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if n := <-ch; string(n) != "0" {
+		t.Fatalf("wrong notification %s", n)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxCaptureDuration is the longest time a capture can run.
+	maxCaptureDuration = time.Hour
+
+	// Default and maximum size of the capture buffer. When the buffer is full, the oldest
+	// entries are dropped.
+	defaultCaptureEntries = 1000
+	maxCaptureEntries     = 100000
+	maxCaptureBytes       = 64 * 1024 * 1024
+)
+
+var errCaptureDuration = errors.New("capture duration must be positive and at most " + maxCaptureDuration.String())
+
+// CaptureFilter selects the calls recorded by a capture.
+type CaptureFilter struct {
+	// Methods lists the methods to record. An entry ending in '*' matches all methods
+	// with the given prefix, e.g. "eth_*". All methods are recorded when empty.
+	Methods []string `json:"methods,omitempty"`
+
+	// ErrorsOnly restricts the capture to calls which returned an error.
+	ErrorsOnly bool `json:"errorsOnly,omitempty"`
+
+	// MaxEntries is the size of the capture buffer. It defaults to 1000 entries.
+	MaxEntries int `json:"maxEntries,omitempty"`
+}
+
+func (f *CaptureFilter) match(method string, failed bool) bool {
+	if f.ErrorsOnly && !failed {
+		return false
+	}
+	return len(f.Methods) == 0 || matchMethod(f.Methods, method)
+}
+
+// matchMethod reports whether method is matched by any of the patterns. A pattern ending
+// in '*' matches all methods with the given prefix.
+func matchMethod(patterns []string, method string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(method, prefix) {
+				return true
+			}
+		} else if p == method {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureEntry is a request/response pair recorded by a capture.
+type CaptureEntry struct {
+	Time       time.Time       `json:"time"`
+	Duration   time.Duration   `json:"duration"`
+	Transport  string          `json:"transport,omitempty"`
+	RemoteAddr string          `json:"remoteAddr,omitempty"`
+	Request    json.RawMessage `json:"request"`
+	Response   json.RawMessage `json:"response"`
+}
+
+// Capture is the result of admin_getCapture.
+type Capture struct {
+	Active  bool           `json:"active"`
+	Started time.Time      `json:"started"`
+	Until   time.Time      `json:"until"`
+	Filter  CaptureFilter  `json:"filter"`
+	Dropped int            `json:"dropped"` // entries dropped because the buffer was full
+	Entries []CaptureEntry `json:"entries"`
+}
+
+// requestCapture records calls into a bounded buffer while a capture is active.
+type requestCapture struct {
+	active atomic.Bool // fast path check, avoids locking while no capture runs
+
+	mu      sync.Mutex
+	started time.Time
+	until   time.Time
+	filter  CaptureFilter
+	entries []CaptureEntry
+	size    int
+	dropped int
+}
+
+// start begins a new capture, discarding the entries of the previous one.
+func (c *requestCapture) start(d time.Duration, filter CaptureFilter) error {
+	if d <= 0 || d > maxCaptureDuration {
+		return errCaptureDuration
+	}
+	if filter.MaxEntries <= 0 {
+		filter.MaxEntries = defaultCaptureEntries
+	}
+	filter.MaxEntries = min(filter.MaxEntries, maxCaptureEntries)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = time.Now()
+	c.until = c.started.Add(d)
+	c.filter = filter
+	c.entries, c.size, c.dropped = nil, 0, 0
+	c.active.Store(true)
+	return nil
+}
+
+// record adds the call to the capture buffer if it matches the filter.
+func (c *requestCapture) record(ctx context.Context, msg, resp *jsonrpcMessage, duration time.Duration) {
+	if !c.active.Load() {
+		return
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !now.Before(c.until) {
+		c.active.Store(false)
+		return
+	}
+	if !c.filter.match(msg.Method, resp != nil && resp.Error != nil) {
+		return
+	}
+	// Retrieving the capture is not recorded, its response contains the capture itself.
+	if strings.HasSuffix(msg.Method, serviceMethodSeparator+"getCapture") {
+		return
+	}
+	req, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	var res json.RawMessage
+	if resp != nil {
+		if res, err = json.Marshal(resp); err != nil {
+			return
+		}
+	}
+	peer := PeerInfoFromContext(ctx)
+	e := CaptureEntry{
+		Time:       now,
+		Duration:   duration,
+		Transport:  peer.Transport,
+		RemoteAddr: peer.RemoteAddr,
+		Request:    req,
+		Response:   res,
+	}
+	c.entries = append(c.entries, e)
+	c.size += len(req) + len(res)
+	for len(c.entries) > c.filter.MaxEntries || (c.size > maxCaptureBytes && len(c.entries) > 1) {
+		c.size -= len(c.entries[0].Request) + len(c.entries[0].Response)
+		c.entries[0] = CaptureEntry{}
+		c.entries = c.entries[1:]
+		c.dropped++
+	}
+}
+
+// get returns the recorded entries.
+func (c *requestCapture) get() *Capture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active.Load() && !time.Now().Before(c.until) {
+		c.active.Store(false)
+	}
+	return &Capture{
+		Active:  c.active.Load(),
+		Started: c.started,
+		Until:   c.until,
+		Filter:  c.filter,
+		Dropped: c.dropped,
+		Entries: append([]CaptureEntry{}, c.entries...),
+	}
+}
+
+// CaptureAPI returns the API for capturing requests. Once registered, operators can
+// record the calls handled by the server for a limited time:
+//
+//	admin_startCapture(duration, filter) starts recording matching calls, discarding the
+//	entries of any previous capture. The duration is a time.Duration parameter, i.e.
+//	nanoseconds unless configured otherwise with SetTimeFormat, of at most one hour.
+//	admin_getCapture() returns the recorded request/response pairs.
+//
+// The capture buffer holds full requests and responses, which may contain sensitive
+// data. The API should only be exposed on trusted endpoints.
+//
+//	api := server.CaptureAPI()
+//	server.RegisterName(api.Namespace, api.Service)
+func (s *Server) CaptureAPI() API {
+	return API{Namespace: "admin", Service: &captureService{s}}
+}
+
+type captureService struct {
+	server *Server
+}
+
+// StartCapture starts recording the calls matching filter for the given duration.
+func (api *captureService) StartCapture(duration time.Duration, filter *CaptureFilter) error {
+	var f CaptureFilter
+	if filter != nil {
+		f = *filter
+	}
+	if err := api.server.services.capture.start(duration, f); err != nil {
+		return &invalidParamsError{err.Error()}
+	}
+	return nil
+}
+
+// GetCapture returns the calls recorded by the current or last capture.
+func (api *captureService) GetCapture() *Capture {
+	return api.server.services.capture.get()
+}
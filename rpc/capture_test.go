@@ -0,0 +1,113 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCaptureAPI(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	api := server.CaptureAPI()
+	if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Calls before the capture is started are not recorded.
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	filter := CaptureFilter{Methods: []string{"test_e*", "test_returnError"}, MaxEntries: 2}
+	if err := client.Call(nil, "admin_startCapture", time.Minute, filter); err != nil {
+		t.Fatal("can't start capture:", err)
+	}
+	client.Call(nil, "test_echo", "x", 2)
+	client.Call(nil, "test_noArgsRets")
+	client.Call(nil, "test_echo", "x", 3)
+	client.Call(nil, "test_returnError")
+
+	var capture Capture
+	if err := client.Call(&capture, "admin_getCapture"); err != nil {
+		t.Fatal(err)
+	}
+	if !capture.Active {
+		t.Error("capture not active")
+	}
+	if capture.Dropped != 1 {
+		t.Errorf("wrong dropped count %d, want 1", capture.Dropped)
+	}
+	if len(capture.Entries) != 2 {
+		t.Fatalf("wrong number of entries %d, want 2", len(capture.Entries))
+	}
+	var req, resp jsonrpcMessage
+	if err := json.Unmarshal(capture.Entries[0].Request, &req); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(capture.Entries[0].Response, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "test_echo" || string(req.Params) != `["x",3]` {
+		t.Errorf("wrong request %s", capture.Entries[0].Request)
+	}
+	if string(resp.Result) != `{"String":"x","Int":3,"Args":null}` || string(resp.ID) != string(req.ID) {
+		t.Errorf("wrong response %s", capture.Entries[0].Response)
+	}
+	if capture.Entries[0].Transport != "ipc" {
+		t.Errorf("wrong transport %q", capture.Entries[0].Transport)
+	}
+	if err := json.Unmarshal(capture.Entries[1].Response, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == nil || resp.Error.Code != 444 {
+		t.Errorf("wrong error response %s", capture.Entries[1].Response)
+	}
+
+	// Invalid durations are rejected.
+	for _, d := range []time.Duration{0, 2 * time.Hour} {
+		err := client.Call(nil, "admin_startCapture", d)
+		if err == nil || err.(Error).ErrorCode() != -32602 {
+			t.Errorf("duration %v: wrong error %v", d, err)
+		}
+	}
+}
+
+func TestCaptureExpiry(t *testing.T) {
+	t.Parallel()
+
+	var c requestCapture
+	if err := c.start(time.Millisecond, CaptureFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	msg := &jsonrpcMessage{Version: vsn, ID: json.RawMessage("1"), Method: "test_echo"}
+	c.record(context.Background(), msg, msg.response(nil), 0)
+	capture := c.get()
+	if capture.Active {
+		t.Error("capture still active after its duration")
+	}
+	if len(capture.Entries) != 0 {
+		t.Errorf("expired capture recorded %d entries", len(capture.Entries))
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	authChallengeMethod = MetadataApi + "_authChallenge"
+	authenticateMethod  = MetadataApi + "_authenticate"
+
+	challengeNonceSize = 32
+)
+
+var (
+	errNoChallenge       = errors.New("no pending authentication challenge")
+	errChallengeRejected = errors.New("challenge response rejected")
+)
+
+// ChallengeVerifier checks the response of the client called name to the challenge
+// nonce, and returns the principal of the client. See SetChallengeAuth.
+type ChallengeVerifier func(name string, nonce, response []byte) (*Principal, error)
+
+// ChallengeSigner computes the response to a challenge nonce, see
+// Client.AuthenticateChallenge.
+type ChallengeSigner func(nonce []byte) ([]byte, error)
+
+// SetChallengeAuth requires websocket clients to authenticate with a challenge-response
+// handshake before any method is dispatched. The client requests a random nonce with
+// rpc_authChallenge and answers with rpc_authenticate, passing its name and the response
+// computed from the nonce, which is checked by verify. Until then, all other calls fail
+// with error code -32012. A nonce can be answered only once.
+//
+// The principal returned by verify is used for the ACL, see SetACL, and is available to
+// methods through PeerInfo. Connections which have a principal from the handshake
+// request, e.g. through a JWT, don't need to authenticate again. Passing nil disables
+// the handshake.
+func (s *Server) SetChallengeAuth(verify ChallengeVerifier) {
+	s.services.updateConfig(func(c *registryConfig) { c.challenge = verify })
+}
+
+// HMACChallengeVerifier returns a verifier for responses computed with
+// HMACChallengeSigner. The secret of each client is taken from secrets, and its roles
+// from roles.
+func HMACChallengeVerifier(secrets map[string][]byte, roles map[string][]string) ChallengeVerifier {
+	return func(name string, nonce, response []byte) (*Principal, error) {
+		secret, ok := secrets[name]
+		if !ok {
+			return nil, errChallengeRejected
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(nonce)
+		if !hmac.Equal(response, mac.Sum(nil)) {
+			return nil, errChallengeRejected
+		}
+		return &Principal{Name: name, Roles: roles[name]}, nil
+	}
+}
+
+// HMACChallengeSigner returns a signer which answers challenges with the HMAC-SHA256
+// of the nonce.
+func HMACChallengeSigner(secret []byte) ChallengeSigner {
+	return func(nonce []byte) ([]byte, error) {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(nonce)
+		return mac.Sum(nil), nil
+	}
+}
+
+// ECDSAChallengeVerifier returns a verifier for responses computed with
+// ECDSAChallengeSigner. The public key of each client is taken from keys, and its roles
+// from roles.
+func ECDSAChallengeVerifier(keys map[string]*ecdsa.PublicKey, roles map[string][]string) ChallengeVerifier {
+	return func(name string, nonce, response []byte) (*Principal, error) {
+		key, ok := keys[name]
+		if !ok {
+			return nil, errChallengeRejected
+		}
+		hash := sha256.Sum256(nonce)
+		if !ecdsa.VerifyASN1(key, hash[:], response) {
+			return nil, errChallengeRejected
+		}
+		return &Principal{Name: name, Roles: roles[name]}, nil
+	}
+}
+
+// ECDSAChallengeSigner returns a signer which answers challenges with the ASN.1 encoded
+// ECDSA signature of the SHA-256 hash of the nonce.
+func ECDSAChallengeSigner(key *ecdsa.PrivateKey) ChallengeSigner {
+	return func(nonce []byte) ([]byte, error) {
+		hash := sha256.Sum256(nonce)
+		return ecdsa.SignASN1(crand.Reader, key, hash[:])
+	}
+}
+
+// AuthenticateChallenge performs the challenge-response handshake required by servers
+// using SetChallengeAuth. The response to the challenge is computed by sign. The
+// handshake authenticates the current connection only, so it must be performed again
+// when the client reconnects.
+func (c *Client) AuthenticateChallenge(ctx context.Context, name string, sign ChallengeSigner) error {
+	var nonce hexutil.Bytes
+	if err := c.CallContext(ctx, &nonce, authChallengeMethod); err != nil {
+		return err
+	}
+	response, err := sign(nonce)
+	if err != nil {
+		return err
+	}
+	return c.CallContext(ctx, nil, authenticateMethod, name, hexutil.Bytes(response))
+}
+
+// challengeState is the challenge-response state of a server connection.
+type challengeState struct {
+	mu        sync.Mutex
+	nonce     []byte     // pending challenge
+	principal *Principal // set after successful authentication
+}
+
+func (s *challengeState) authenticated() *Principal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.principal
+}
+
+// handleChallenge processes calls on connections which require challenge-response
+// authentication. It returns the response for calls which are not dispatched, and the
+// principal of the connection for calls which are.
+func (h *handler) handleChallenge(cp *callProc, msg *jsonrpcMessage) (*jsonrpcMessage, *Principal) {
+	verify := cp.cfg.challenge
+	info := PeerInfoFromContext(cp.ctx)
+	if verify == nil || info.Transport != "ws" || info.Principal != nil {
+		return nil, nil
+	}
+	switch msg.Method {
+	case authChallengeMethod:
+		nonce := make([]byte, challengeNonceSize)
+		if _, err := crand.Read(nonce); err != nil {
+			return msg.errorResponse(err), nil
+		}
+		h.challenge.mu.Lock()
+		h.challenge.nonce = nonce
+		h.challenge.mu.Unlock()
+		return msg.response(hexutil.Bytes(nonce)), nil
+
+	case authenticateMethod:
+		args, err := parsePositionalArguments(msg.Params, []reflect.Type{stringType, reflect.TypeOf(hexutil.Bytes{})}, h.decodeConfig())
+		if err != nil {
+			return msg.errorResponse(&invalidParamsError{err.Error()}), nil
+		}
+		name, response := args[0].String(), args[1].Interface().(hexutil.Bytes)
+
+		h.challenge.mu.Lock()
+		defer h.challenge.mu.Unlock()
+		nonce := h.challenge.nonce
+		h.challenge.nonce = nil
+		if nonce == nil {
+			return msg.errorResponse(&unauthenticatedError{errNoChallenge}), nil
+		}
+		p, err := verify(name, nonce, response)
+		if err == nil && p == nil {
+			err = errChallengeRejected
+		}
+		if err != nil {
+			log.Debug("RPC challenge authentication failed", "remote", info.RemoteAddr, "name", name, "err", err)
+			return msg.errorResponse(&unauthenticatedError{err}), nil
+		}
+		h.challenge.principal = p
+		return msg.response(nil), nil
+	}
+
+	if p := h.challenge.authenticated(); p != nil {
+		return nil, p
+	}
+	return msg.errorResponse(&unauthenticatedError{nil}), nil
+}
+
+type unauthenticatedError struct{ err error }
+
+func (e *unauthenticatedError) Error() string {
+	if e.err == nil {
+		return errMsgUnauthenticated
+	}
+	return errMsgUnauthenticated + ": " + e.err.Error()
+}
+
+func (e *unauthenticatedError) ErrorCode() int { return errcodeUnauthenticated }
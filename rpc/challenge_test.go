@@ -0,0 +1,143 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestChallengeAuthHMAC(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	server := newTestServer()
+	defer server.Stop()
+	server.SetChallengeAuth(HMACChallengeVerifier(
+		map[string][]byte{"alice": secret},
+		map[string][]string{"alice": {"admin"}},
+	))
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	url := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	server.SetACL(&ACL{Rules: []ACLRule{{Methods: []string{"test_*"}, Roles: []string{"admin"}}}})
+
+	client := dialACLTest(t, url)
+	wantCallError(t, client.Call(nil, "test_noArgsRets"), errcodeUnauthenticated)
+
+	// Wrong secret.
+	if err := client.AuthenticateChallenge(context.Background(), "alice", HMACChallengeSigner([]byte("wrong"))); err == nil {
+		t.Fatal("authentication with wrong secret succeeded")
+	}
+	wantCallError(t, client.Call(nil, "test_noArgsRets"), errcodeUnauthenticated)
+
+	if err := client.AuthenticateChallenge(context.Background(), "alice", HMACChallengeSigner(secret)); err != nil {
+		t.Fatal("authentication failed:", err)
+	}
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Principal == nil || info.Principal.Name != "alice" || !info.Principal.HasRole("admin") {
+		t.Fatalf("wrong principal %+v", info.Principal)
+	}
+
+	// Other connections still have to authenticate.
+	other := dialACLTest(t, url)
+	wantCallError(t, other.Call(nil, "test_noArgsRets"), errcodeUnauthenticated)
+}
+
+func TestChallengeAuthECDSA(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer()
+	defer server.Stop()
+	server.SetChallengeAuth(ECDSAChallengeVerifier(map[string]*ecdsa.PublicKey{"node": &key.PublicKey}, nil))
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	url := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	client := dialACLTest(t, url)
+	if err := client.AuthenticateChallenge(context.Background(), "node", ECDSAChallengeSigner(key)); err != nil {
+		t.Fatal("authentication failed:", err)
+	}
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unknown name is rejected.
+	other := dialACLTest(t, url)
+	err = other.AuthenticateChallenge(context.Background(), "someone", ECDSAChallengeSigner(key))
+	wantCallError(t, err, errcodeUnauthenticated)
+}
+
+func TestChallengeNonceSingleUse(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	server := newTestServer()
+	defer server.Stop()
+	server.SetChallengeAuth(HMACChallengeVerifier(map[string][]byte{"alice": secret}, nil))
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	url := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	client := dialACLTest(t, url)
+
+	var nonce hexutil.Bytes
+	if err := client.Call(&nonce, authChallengeMethod); err != nil {
+		t.Fatal(err)
+	}
+	if len(nonce) != challengeNonceSize {
+		t.Fatalf("wrong nonce size %d", len(nonce))
+	}
+	response, _ := HMACChallengeSigner(secret)(nonce)
+	if err := client.Call(nil, authenticateMethod, "bob", hexutil.Bytes(response)); err == nil {
+		t.Fatal("unknown client authenticated")
+	}
+	// The failed attempt has used up the nonce.
+	err := client.Call(nil, authenticateMethod, "alice", hexutil.Bytes(response))
+	wantCallError(t, err, errcodeUnauthenticated)
+	if !strings.Contains(err.Error(), errNoChallenge.Error()) {
+		t.Fatalf("wrong error %q", err)
+	}
+}
+
+func TestChallengeAuthHTTPUnaffected(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetChallengeAuth(HMACChallengeVerifier(nil, nil))
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client := dialACLTest(t, httpsrv.URL)
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal("HTTP call failed:", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// ErrResponseChecksum is returned by the client when the result of a response doesn't
+// match the checksum provided by the server. This indicates that the response was
+// corrupted in transit.
+var ErrResponseChecksum = errors.New("response checksum mismatch")
+
+const checksumPrefixCRC32C = "crc32c:"
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SetResponseChecksums enables checksums for responses. When enabled, responses carry a
+// 'checksum' field containing a CRC-32C checksum of the result. Clients verify the
+// checksum to detect responses corrupted by intermediaries such as proxies. Clients which
+// don't support checksums ignore the field.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetResponseChecksums(enabled bool) {
+	s.responseChecksums = enabled
+}
+
+// resultChecksum computes the checksum of a response result.
+func resultChecksum(result []byte) string {
+	return fmt.Sprintf("%s%08x", checksumPrefixCRC32C, crc32.Checksum(result, crc32cTable))
+}
+
+// addChecksum adds the checksum of the result to a response.
+func (msg *jsonrpcMessage) addChecksum() {
+	if msg.Result != nil {
+		msg.Checksum = resultChecksum(msg.Result)
+	}
+}
+
+// verifyChecksum checks the result of a response against its checksum. Responses without
+// checksum and checksums of unknown type are accepted.
+func (msg *jsonrpcMessage) verifyChecksum() error {
+	if !strings.HasPrefix(msg.Checksum, checksumPrefixCRC32C) {
+		return nil
+	}
+	if msg.Checksum != resultChecksum(msg.Result) {
+		return ErrResponseChecksum
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerResponseChecksums(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	server.SetResponseChecksums(true)
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`
+	resp, err := httpsrv.Client().Post(httpsrv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var msg jsonrpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Checksum == "" || msg.Checksum != resultChecksum(msg.Result) {
+		t.Fatalf("wrong checksum %q for result %s", msg.Checksum, msg.Result)
+	}
+
+	// Clients verify the checksum.
+	client := DialInProc(server)
+	defer client.Close()
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientVerifyChecksum(t *testing.T) {
+	t.Parallel()
+
+	client, enc, dec := newRawServerClient(t)
+	respond := func(result, checksum string) {
+		var req jsonrpcMessage
+		if err := dec.Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		enc.Encode(&jsonrpcMessage{Version: vsn, ID: req.ID, Result: json.RawMessage(result), Checksum: checksum})
+	}
+
+	go respond(`"hello"`, resultChecksum([]byte(`"hello"`)))
+	var res string
+	if err := client.Call(&res, "test_method"); err != nil || res != "hello" {
+		t.Fatalf("valid checksum: result %q, err %v", res, err)
+	}
+
+	go respond(`"hellp"`, resultChecksum([]byte(`"hello"`)))
+	if err := client.Call(&res, "test_method"); !errors.Is(err, ErrResponseChecksum) {
+		t.Fatalf("corrupted result: wrong error %v", err)
+	}
+
+	go respond(`"hello"`, "unknown:1234")
+	if err := client.Call(&res, "test_method"); err != nil {
+		t.Fatalf("unknown checksum type: unexpected error %v", err)
+	}
+}
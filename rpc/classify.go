@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// RequestClassifier assigns a class label, such as "read", "write" or "trace", to an
+// incoming call. An empty label means the call is unclassified.
+//
+// The label is used as a common policy key across subsystems: it is attached to the
+// request log, tracked in per-class metrics, and made available to middlewares through
+// RequestClass, so that quotas and scheduling can be keyed by class.
+type RequestClassifier func(ctx context.Context, method string, params json.RawMessage) string
+
+// ClassifierRule maps methods matching Pattern to Class. Patterns use the syntax of
+// path.Match, e.g. "eth_get*" or "debug_trace*".
+type ClassifierRule struct {
+	Pattern string
+	Class   string
+}
+
+type requestClassKey struct{}
+
+// RequestClass returns the class assigned to the current call by the server's
+// RequestClassifier, or the empty string if the call is unclassified.
+func RequestClass(ctx context.Context) string {
+	class, _ := ctx.Value(requestClassKey{}).(string)
+	return class
+}
+
+func withRequestClass(ctx context.Context, class string) context.Context {
+	if class == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestClassKey{}, class)
+}
+
+// SetRequestClassifier configures the function used to classify incoming calls.
+// Passing nil disables classification.
+func (s *Server) SetRequestClassifier(fn RequestClassifier) {
+	s.services.setClassifier(fn)
+}
+
+// NewRuleClassifier creates a classifier from a list of rules. The first rule matching
+// the method name determines the class. Methods matching no rule are assigned
+// defaultClass.
+func NewRuleClassifier(rules []ClassifierRule, defaultClass string) (RequestClassifier, error) {
+	for _, r := range rules {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", r.Pattern, err)
+		}
+	}
+	rules = append([]ClassifierRule(nil), rules...)
+	return func(ctx context.Context, method string, params json.RawMessage) string {
+		for _, r := range rules {
+			if ok, _ := path.Match(r.Pattern, method); ok {
+				return r.Class
+			}
+		}
+		return defaultClass
+	}, nil
+}
+
+// ParseClassifierRules reads classifier rules from a rules file. Each non-empty line
+// holds a method pattern and a class separated by whitespace. Lines starting with '#'
+// are comments.
+//
+//	# reads
+//	eth_get*      read
+//	eth_call      read
+//	debug_trace*  trace
+func ParseClassifierRules(r io.Reader) ([]ClassifierRule, error) {
+	var (
+		rules   []ClassifierRule
+		scanner = bufio.NewScanner(r)
+	)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected pattern and class", line)
+		}
+		if _, err := path.Match(fields[0], ""); err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %v", line, fields[0], err)
+		}
+		rules = append(rules, ClassifierRule{Pattern: fields[0], Class: fields[1]})
+	}
+	return rules, scanner.Err()
+}
+
+// updateClassMetrics tracks the serving time and outcome of a classified call.
+func updateClassMetrics(class string, success bool, elapsed time.Duration) {
+	prefix := "rpc/class/" + class
+	metrics.GetOrRegisterCounter(prefix+"/requests", nil).Inc(1)
+	if !success {
+		metrics.GetOrRegisterCounter(prefix+"/failure", nil).Inc(1)
+	}
+	metrics.GetOrRegisterTimer(prefix+"/duration", nil).Update(elapsed)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseClassifierRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseClassifierRules(strings.NewReader(`
+# reads
+test_echo*   read
+test_sleep   slow
+
+debug_trace* trace
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ClassifierRule{{"test_echo*", "read"}, {"test_sleep", "slow"}, {"debug_trace*", "trace"}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("wrong rules %v", rules)
+	}
+
+	if _, err := ParseClassifierRules(strings.NewReader("test_echo read extra")); err == nil {
+		t.Error("expected error for malformed line")
+	}
+	if _, err := ParseClassifierRules(strings.NewReader("test_[ read")); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+
+	classify, err := NewRuleClassifier(rules, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for method, class := range map[string]string{
+		"test_echo":        "read",
+		"test_echoWithCtx": "read",
+		"debug_traceCall":  "trace",
+		"test_rets":        "other",
+	} {
+		if got := classify(context.Background(), method, nil); got != class {
+			t.Errorf("%s: got class %q, want %q", method, got, class)
+		}
+	}
+}
+
+func TestServerRequestClassifier(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+
+	var (
+		mu      sync.Mutex
+		classes = make(map[string]string)
+	)
+	server.SetMiddlewares([]Middleware{
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			mu.Lock()
+			classes[method] = RequestClass(ctx)
+			mu.Unlock()
+			return next(ctx, method, args)
+		},
+	})
+	server.SetRequestClassifier(func(ctx context.Context, method string, params json.RawMessage) string {
+		if method == "test_echo" {
+			return "read"
+		}
+		return ""
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1, &echoArgs{S: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	var rets string
+	if err := client.Call(&rets, "test_rets"); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]string{"test_echo": "read", "test_rets": ""}
+	if !reflect.DeepEqual(classes, want) {
+		t.Fatalf("wrong classes %v", classes)
+	}
+}
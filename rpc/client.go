@@ -25,9 +25,11 @@ import (
 	"os"
 	"reflect"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -46,6 +48,8 @@ const (
 	defaultDialTimeout = 10 * time.Second // used if context has no deadline
 	subscribeTimeout   = 10 * time.Second // overall timeout eth_subscribe, rpc_modules calls
 	unsubscribeTimeout = 10 * time.Second // timeout for *_unsubscribe calls
+
+	limitsDiscoveryBackoff = 30 * time.Second // delay before retrying failed rpc_limits calls
 )
 
 const (
@@ -78,17 +82,108 @@ type BatchElem struct {
 // Client represents a connection to an RPC server.
 type Client struct {
 	idgen    func() ID // for subscriptions
+	epochIDs bool      // ids created by idgen start with the epoch
+	epoch    string    // epoch of the server, see Server.Epoch
 	isHTTP   bool      // connection type: http, ws or ipc
 	services *serviceRegistry
 
 	idCounter atomic.Uint32
 
+	// interceptors wrap outgoing requests, see SetInterceptors
+	interceptors atomic.Pointer[[]ClientInterceptor]
+
 	// This function, if non-nil, is called when the connection is lost.
 	reconnectFunc reconnectFunc
 
 	// config fields
-	batchItemLimit       int
-	batchResponseMaxSize int
+	batchLimits        *batchServeLimits
+	checkDuplicateIDs  bool
+	synchronousCalls   bool
+	orderNotifications bool
+	subscriptionOwner  SubscriptionOwnerFunc
+	sessions           *sessionRegistry
+	responseChecksums  bool
+	executionReports   bool
+	canonicalJSON      bool
+	numberPolicy       NumberPolicy
+	timeFormat         TimeFormat
+	serverEvents       *serverEvents
+	connID             ConnID // preassigned by Server.ServeCodec
+
+	// for diagnostics
+	stats          StatsHandler
+	strictProtocol bool
+
+	// batch chunking limits, see batchChunkLimits
+	batchLimitsMu     sync.Mutex
+	batchChunk        batchLimits
+	discoverLimits    bool // query rpc_limits before the first batch
+	limitsDiscovered  bool
+	limitsDiscovering bool           // rpc_limits call in progress
+	limitsRetry       mclock.AbsTime // when discovery may be retried after a failure
+
+	// number of notification dispatch workers, see WithDispatchWorkers
+	dispatchWorkers int
+
+	// subscription spill files, see WithSubscriptionSpill
+	spillDir   string
+	spillSize  int64
+	spillCodec StorageCodec // compresses spilled notifications, nil if disabled
+
+	// clock drives timers and cache expiry, see WithClock
+	clock mclock.Clock
+
+	// number of resubscription attempts, zero if disabled, see WithResubscribe
+	resubscribeAttempts int
+
+	// shadow mirrors calls to a secondary endpoint, nil if disabled.
+	shadow *shadower
+
+	// retry implements the retry policy, nil if calls are not retried.
+	retry *retrier
+
+	// hedging is the hedging policy, nil if calls are not hedged.
+	hedging *HedgePolicy
+
+	// nonIdempotent matches methods which must not be sent twice.
+	nonIdempotent []string
+
+	// propagateDeadline sends the time budget of calls, see WithDeadlinePropagation.
+	propagateDeadline bool
+
+	// drift compares results with their Go types, nil if disabled.
+	drift *driftDetector
+
+	// journal records state-changing calls, see WithJournal.
+	journal *Journal
+
+	// executionReportFn receives server execution reports, see WithExecutionReports.
+	executionReportFn func(method string, report ExecutionReport)
+
+	// computeUnitsFn receives compute unit usage, see WithComputeUnits.
+	computeUnitsFn func(method string, usage ComputeUnitUsage)
+
+	// deprecationFn receives deprecation notices, see WithDeprecationHandler.
+	deprecationFn func(notice DeprecationNotice)
+
+	// cache holds results of calls, nil if disabled.
+	cache       *ttlCache
+	cacheMaxTTL time.Duration
+
+	// consistency tracks consistency tokens, nil if disabled.
+	consistency *consistencyTracker
+
+	// limiter bounds the number of in-flight requests, nil if unbounded.
+	limiter *inflightLimiter
+
+	// queue bounds the number of requests waiting for the write lock, nil if unbounded.
+	queue *requestQueue
+
+	// reconnector redials lost connections in the background, see WithReconnect.
+	reconnector *reconnector
+
+	// redirects follows redirect errors, nil if disabled. See WithFollowRedirects.
+	redirects *redirector
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -120,7 +215,43 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
-	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	if c.connID != 0 {
+		ctx = context.WithValue(ctx, connIDKey{}, c.connID)
+	}
+	if carrier, ok := conn.(interface{ spanContext() (SpanContext, bool) }); ok {
+		if sc, ok := carrier.spanContext(); ok {
+			ctx = NewContextWithSpanContext(ctx, sc)
+		}
+	}
+	if carrier, ok := conn.(interface{ apiVersions() map[string]int }); ok {
+		if versions := carrier.apiVersions(); versions != nil {
+			ctx = context.WithValue(ctx, apiVersionsKey{}, versions)
+		}
+	}
+	handler := newHandler(ctx, conn, c.idgen, c.services, 0, 0)
+	handler.batchLimits = c.batchLimits
+	handler.checkDuplicateIDs = c.checkDuplicateIDs
+	handler.epoch = c.epoch
+	handler.epochIDs = c.epochIDs
+	handler.synchronousCalls = c.synchronousCalls
+	handler.events = c.serverEvents
+	handler.subOwner = c.subscriptionOwner
+	handler.sessions = c.sessions
+	handler.checksums = c.responseChecksums
+	handler.executionReports = c.executionReports
+	handler.canonicalJSON = c.canonicalJSON
+	handler.numberPolicy = c.numberPolicy
+	handler.timeFormat = c.timeFormat
+	handler.clock = c.clock
+	if c.dispatchWorkers > 0 {
+		handler.dispatch = newDispatchPool(c.dispatchWorkers)
+	}
+	if c.orderNotifications {
+		handler.notifySeq = new(notificationSequencer)
+	}
+	if c.stats != nil || c.strictProtocol {
+		handler.diag = newClientDiagnostics(c.stats, c.strictProtocol)
+	}
 	return &clientConn{conn, handler}
 }
 
@@ -162,9 +293,9 @@ func (op *requestOp) wait(ctx context.Context, c *Client) ([]*jsonrpcMessage, er
 
 // Dial creates a new client for the given URL.
 //
-// The currently supported URL schemes are "http", "https", "ws" and "wss". If rawurl is a
-// file name with no URL scheme, a local socket connection is established using UNIX
-// domain sockets on supported platforms and named pipes on Windows.
+// The currently supported URL schemes are "http", "https", "ws", "wss" and "grpcs". If
+// rawurl is a file name with no URL scheme, a local socket connection is established
+// using UNIX domain sockets on supported platforms and named pipes on Windows.
 //
 // If you want to further configure the transport, use DialOptions instead of this
 // function.
@@ -201,6 +332,18 @@ func DialOptions(ctx context.Context, rawurl string, options ...ClientOption) (*
 	for _, opt := range options {
 		opt.applyOption(cfg)
 	}
+	if err := cfg.setupSOCKS(u.Scheme); err != nil {
+		return nil, err
+	}
+	if err := cfg.setupEyeballs(); err != nil {
+		return nil, err
+	}
+	if err := cfg.setupTCPOptions(); err != nil {
+		return nil, err
+	}
+	if err := cfg.setupResolver(); err != nil {
+		return nil, err
+	}
 
 	var reconnect reconnectFunc
 	switch u.Scheme {
@@ -212,15 +355,28 @@ func DialOptions(ctx context.Context, rawurl string, options ...ClientOption) (*
 			return nil, err
 		}
 		reconnect = rc
+	case "grpcs":
+		rc, err := newClientTransportGRPC(rawurl, cfg)
+		if err != nil {
+			return nil, err
+		}
+		reconnect = rc
 	case "stdio":
 		reconnect = newClientTransportIO(os.Stdin, os.Stdout)
 	case "":
-		reconnect = newClientTransportIPC(rawurl)
+		reconnect = newClientTransportIPC(rawurl, cfg)
 	default:
 		return nil, fmt.Errorf("no known transport for URL scheme %q", u.Scheme)
 	}
 
-	return newClient(ctx, cfg, reconnect)
+	c, err := newClient(ctx, cfg, reconnect)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.maxRedirects > 0 {
+		c.redirects = newRedirector(cfg.maxRedirects, rawurl, options)
+	}
+	return c, nil
 }
 
 // ClientFromContext retrieves the client from the context, if any. This can be used to perform
@@ -231,6 +387,11 @@ func ClientFromContext(ctx context.Context) (*Client, bool) {
 }
 
 func newClient(initctx context.Context, cfg *clientConfig, connect reconnectFunc) (*Client, error) {
+	if p := cfg.reconnectPolicy; p != nil && p.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		initctx, cancel = context.WithTimeout(initctx, p.DialTimeout)
+		defer cancel()
+	}
 	conn, err := connect(initctx)
 	if err != nil {
 		return nil, err
@@ -243,27 +404,89 @@ func newClient(initctx context.Context, cfg *clientConfig, connect reconnectFunc
 func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
-		isHTTP:               isHTTP,
-		services:             services,
-		idgen:                cfg.idgen,
-		batchItemLimit:       cfg.batchItemLimit,
-		batchResponseMaxSize: cfg.batchResponseLimit,
-		writeConn:            conn,
-		close:                make(chan struct{}),
-		closing:              make(chan struct{}),
-		didClose:             make(chan struct{}),
-		reconnected:          make(chan ServerCodec),
-		readOp:               make(chan readOp),
-		readErr:              make(chan error),
-		reqInit:              make(chan *requestOp),
-		reqSent:              make(chan error, 1),
-		reqTimeout:           make(chan *requestOp),
+		isHTTP:              isHTTP,
+		services:            services,
+		idgen:               cfg.idgen,
+		epochIDs:            cfg.epochIDs,
+		epoch:               cfg.epoch,
+		batchLimits:         newBatchServeLimits(cfg.batchItemLimit, cfg.batchResponseLimit),
+		checkDuplicateIDs:   cfg.checkDuplicateIDs,
+		synchronousCalls:    cfg.synchronousCalls,
+		orderNotifications:  cfg.orderNotifications,
+		subscriptionOwner:   cfg.subscriptionOwner,
+		sessions:            cfg.sessions,
+		responseChecksums:   cfg.responseChecksums,
+		executionReports:    cfg.executionReports,
+		canonicalJSON:       cfg.canonicalJSON,
+		numberPolicy:        cfg.numberPolicy,
+		timeFormat:          cfg.timeFormat,
+		serverEvents:        cfg.serverEvents,
+		connID:              cfg.connID,
+		executionReportFn:   cfg.executionReportFn,
+		computeUnitsFn:      cfg.computeUnitsFn,
+		deprecationFn:       cfg.deprecationFn,
+		journal:             cfg.journal,
+		stats:               cfg.statsHandler,
+		batchChunk:          batchLimits{cfg.batchChunkItems, cfg.batchChunkBytes},
+		discoverLimits:      cfg.discoverBatchLimits,
+		strictProtocol:      cfg.strictProtocol,
+		dispatchWorkers:     cfg.dispatchWorkers,
+		spillDir:            cfg.subscriptionSpillDir,
+		spillSize:           cfg.subscriptionSpillSize,
+		spillCodec:          cfg.subscriptionSpillCodec,
+		clock:               cfg.clock,
+		resubscribeAttempts: cfg.resubscribeAttempts,
+		writeConn:           conn,
+		close:               make(chan struct{}),
+		closing:             make(chan struct{}),
+		didClose:            make(chan struct{}),
+		reconnected:         make(chan ServerCodec),
+		readOp:              make(chan readOp),
+		readErr:             make(chan error),
+		reqInit:             make(chan *requestOp),
+		reqSent:             make(chan error, 1),
+		reqTimeout:          make(chan *requestOp),
+		hedging:             cfg.hedgePolicy,
+		nonIdempotent:       cfg.nonIdempotent,
+		propagateDeadline:   cfg.propagateDeadline,
+	}
+
+	if cfg.retryPolicy != nil {
+		c.retry = &retrier{policy: *cfg.retryPolicy}
+	}
+	if cfg.reconnectPolicy != nil && !isHTTP {
+		c.reconnector = &reconnector{policy: *cfg.reconnectPolicy}
+	}
+	if cfg.shadow != nil {
+		c.shadow = newShadower(*cfg.shadow)
+	}
+	if cfg.schemaDrift != nil {
+		c.drift = newDriftDetector(*cfg.schemaDrift)
+	}
+	if cfg.responseCacheSize > 0 {
+		c.cache = newTTLCache(cfg.responseCacheSize, 0)
+		c.cacheMaxTTL = cfg.responseCacheMaxTTL
+		if c.cacheMaxTTL <= 0 {
+			c.cacheMaxTTL = defaultClientCacheMaxTTL
+		}
+	}
+	if cfg.consistencyTokens {
+		c.consistency = new(consistencyTracker)
+	}
+	if cfg.maxInflight > 0 {
+		c.limiter = newInflightLimiter(cfg.maxInflight)
+	}
+	if cfg.requestQueueSize > 0 && !isHTTP {
+		c.queue = newRequestQueue(cfg.requestQueueSize, cfg.requestQueuePolicy)
 	}
 
 	// Set defaults.
 	if c.idgen == nil {
 		c.idgen = randomIDGenerator()
 	}
+	if c.clock == nil {
+		c.clock = mclock.System{}
+	}
 
 	// Launch the main loop.
 	if !isHTTP {
@@ -297,6 +520,9 @@ func (c *Client) SupportedModules() (map[string]string, error) {
 
 // Close closes the client, aborting any in-flight requests.
 func (c *Client) Close() {
+	if c.redirects != nil {
+		c.redirects.close()
+	}
 	if c.isHTTP {
 		return
 	}
@@ -339,14 +565,75 @@ func (c *Client) CallContext(ctx context.Context, result interface{}, method str
 	if result != nil && reflect.TypeOf(result).Kind() != reflect.Ptr {
 		return fmt.Errorf("call result parameter must be pointer or nil interface: %v", result)
 	}
+	req := &ClientRequest{Kind: CallRequest, Method: method, Args: args, Result: result}
+	return c.intercept(ctx, req, func(ctx context.Context, req *ClientRequest) error {
+		var err error
+		call := func() error {
+			if c.hedging != nil && c.isIdempotent(req.Method) {
+				return c.hedgedCall(ctx, req.Result, req.Method, req.Args...)
+			}
+			return c.callContext(ctx, req.Result, req.Method, req.Args...)
+		}
+		if c.retry != nil {
+			err = c.retry.do(ctx, c, req.Method, call)
+		} else {
+			err = call()
+		}
+		if err != nil && c.redirects != nil {
+			err = c.redirects.follow(ctx, req, err)
+		}
+		return err
+	})
+}
+
+// callContext performs a single attempt of a call.
+func (c *Client) callContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
 	msg, err := c.newMessage(method, args...)
 	if err != nil {
 		return err
 	}
+	_, err = c.callMessage(ctx, result, msg)
+	return err
+}
+
+// callMessage sends a call message and decodes the result. It returns the response
+// message if one was received.
+func (c *Client) callMessage(ctx context.Context, result interface{}, msg *jsonrpcMessage) (*jsonrpcMessage, error) {
+	var (
+		method   = msg.Method
+		cacheKey string
+		err      error
+	)
+	c.attachBaggage(ctx, msg)
+	c.attachDryRun(ctx, msg)
+	c.attachTimeout(ctx, msg)
+	if c.cache != nil && !msg.requestExt().wantsPage() && !msg.isDryRun() {
+		if key, ok := callCacheKey(method, msg.Params, nil); ok {
+			if cached, _, hit := c.cache.get(key, c.clock.Now()); hit {
+				if result == nil {
+					return nil, nil
+				}
+				return nil, c.decodeResult(method, cached, result)
+			}
+			cacheKey = key
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer c.limiter.release()
+	}
 	op := &requestOp{
 		ids:  []json.RawMessage{msg.ID},
 		resp: make(chan []*jsonrpcMessage, 1),
 	}
+	var journalSeq uint64
+	if c.journal.tracks(method) {
+		if journalSeq, err = c.journal.begin(method, msg.Params); err != nil {
+			return nil, err
+		}
+	}
 
 	if c.isHTTP {
 		err = c.sendHTTP(ctx, op, msg)
@@ -354,25 +641,56 @@ func (c *Client) CallContext(ctx context.Context, result interface{}, method str
 		err = c.send(ctx, op, msg)
 	}
 	if err != nil {
-		return err
+		if journalSeq != 0 {
+			c.journal.failed(journalSeq, err)
+		}
+		return nil, err
+	}
+	if journalSeq != 0 {
+		c.journal.sent(journalSeq)
 	}
 
 	// dispatch has accepted the request and will close the channel when it quits.
 	batchresp, err := op.wait(ctx, c)
+	if err != nil && c.reconnector.replays(method, op, err) {
+		batchresp, err = c.replayCall(ctx, msg)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 	resp := batchresp[0]
+	if journalSeq != 0 {
+		c.journal.acknowledged(journalSeq, resp)
+	}
+	if err := resp.verifyChecksum(); err != nil {
+		return resp, err
+	}
+	c.reportExecution(method, resp)
+	c.reportComputeUnits(method, resp)
+	c.reportDeprecation(resp)
+	c.observeConsistency(resp)
+	if cacheKey != "" && resp.Error == nil && len(resp.Result) > 0 {
+		if ttl := clientCacheTTL(resp.responseExt().CacheTTLMs, c.cacheMaxTTL); ttl > 0 {
+			c.cache.put(cacheKey, resp.Result, ttl, c.clock.Now())
+		}
+	}
+	if c.shadow != nil && !IsDryRun(ctx) && c.isIdempotent(method) {
+		primary := ShadowResult{Result: resp.Result}
+		if resp.Error != nil {
+			primary.Err = resp.Error
+		}
+		c.shadow.mirror(ctx, method, msg.Params, primary)
+	}
 	switch {
 	case resp.Error != nil:
-		return resp.Error
+		return resp, resp.Error
 	case len(resp.Result) == 0:
-		return ErrNoResult
+		return resp, ErrNoResult
 	default:
 		if result == nil {
-			return nil
+			return resp, nil
 		}
-		return json.Unmarshal(resp.Result, result)
+		return resp, c.decodeResult(method, resp.Result, result)
 	}
 }
 
@@ -396,39 +714,162 @@ func (c *Client) BatchCall(b []BatchElem) error {
 // while sending the request. Any error specific to a request is reported through the
 // Error field of the corresponding BatchElem.
 //
-// Note that batch calls may not be executed atomically on the server side.
+// Note that batch calls may not be executed atomically on the server side. When batch
+// chunking is enabled using the WithBatchChunking option, large batches are sent as
+// multiple requests.
+//
+// If sending the batch or receiving the responses fails, the error is a *BatchError
+// telling which elements were not sent and which may have been executed.
 func (c *Client) BatchCallContext(ctx context.Context, b []BatchElem) error {
-	var (
-		msgs = make([]*jsonrpcMessage, len(b))
-		byID = make(map[string]int, len(b))
-	)
-	op := &requestOp{
-		ids:  make([]json.RawMessage, len(b)),
-		resp: make(chan []*jsonrpcMessage, 1),
-	}
+	req := &ClientRequest{Kind: BatchRequest, Batch: b}
+	return c.intercept(ctx, req, func(ctx context.Context, req *ClientRequest) error {
+		return c.batchCallContext(ctx, req.Batch)
+	})
+}
+
+func (c *Client) batchCallContext(ctx context.Context, b []BatchElem) error {
+	msgs := make([]*jsonrpcMessage, len(b))
 	for i, elem := range b {
 		msg, err := c.newMessage(elem.Method, elem.Args...)
 		if err != nil {
 			return err
 		}
 		msgs[i] = msg
+	}
+	c.attachBaggage(ctx, msgs...)
+	c.attachDryRun(ctx, msgs...)
+	c.attachTimeout(ctx, msgs...)
+	limits := c.batchChunkLimits(ctx)
+	for start := 0; start < len(b); {
+		end := limits.chunkEnd(msgs, start)
+		if sent, err := c.sendBatch(ctx, b[start:end], msgs[start:end]); err != nil {
+			return newBatchError(err, len(b), start, end, sent)
+		}
+		start = end
+	}
+	return nil
+}
+
+// batchLimits configures the splitting of batches. Zero values mean no limit.
+type batchLimits struct {
+	items int // maximum number of calls in a batch request
+	bytes int // maximum size of a batch request
+}
+
+// chunkEnd returns the end index of the wire batch starting at msgs[start]. Every chunk
+// contains at least one message, even if it exceeds the size limit.
+func (l batchLimits) chunkEnd(msgs []*jsonrpcMessage, start int) int {
+	end, size := start, 0
+	for ; end < len(msgs); end++ {
+		if l.items > 0 && end-start == l.items {
+			break
+		}
+		if l.bytes > 0 {
+			size += msgs[end].encodedSizeHint()
+			if end > start && size > l.bytes {
+				break
+			}
+		}
+	}
+	return end
+}
+
+// lower applies the limit of other if it is stricter.
+func (l *batchLimits) lower(other batchLimits) {
+	if other.items > 0 && (l.items == 0 || other.items < l.items) {
+		l.items = other.items
+	}
+	if other.bytes > 0 && (l.bytes == 0 || other.bytes < l.bytes) {
+		l.bytes = other.bytes
+	}
+}
+
+// batchChunkLimits returns the limits for splitting batches. If limit discovery is
+// enabled, the server's limits are queried using rpc_limits when this is called for the
+// first time. Batches sent while the query is running use the configured limits. When
+// the query fails, it is retried after limitsDiscoveryBackoff.
+func (c *Client) batchChunkLimits(ctx context.Context) batchLimits {
+	c.batchLimitsMu.Lock()
+	if !c.discoverLimits || c.limitsDiscovered || c.limitsDiscovering || c.clock.Now() < c.limitsRetry {
+		defer c.batchLimitsMu.Unlock()
+		return c.batchChunk
+	}
+	c.limitsDiscovering = true
+	c.batchLimitsMu.Unlock()
+
+	limits, err := c.ServerLimits(ctx)
+
+	c.batchLimitsMu.Lock()
+	defer c.batchLimitsMu.Unlock()
+	c.limitsDiscovering = false
+	var rpcErr Error
+	switch {
+	case err == nil:
+		discovered := batchLimits{items: limits.BatchRequestLimit}
+		if c.isHTTP {
+			discovered.bytes = limits.HTTPBodyLimit
+		}
+		c.batchChunk.lower(discovered)
+		c.limitsDiscovered = true
+	case errors.As(err, &rpcErr) && rpcErr.ErrorCode() == errcodeMethodNotFound:
+		// rpc_limits is not available, don't ask again.
+		c.limitsDiscovered = true
+	default:
+		log.Debug("RPC limits discovery failed", "err", err)
+		c.limitsRetry = c.clock.Now().Add(limitsDiscoveryBackoff)
+	}
+	return c.batchChunk
+}
+
+// ServerLimits calls the rpc_limits method, retrieving the request limits configured on
+// the server.
+func (c *Client) ServerLimits(ctx context.Context) (*ServerLimits, error) {
+	var limits ServerLimits
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, subscribeTimeout)
+		defer cancel()
+	}
+	if err := c.CallContext(ctx, &limits, "rpc_limits"); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+// sendBatch sends msgs as a single batch request and waits for the responses. Results are
+// assigned to the corresponding elements of b. When an error is returned, sent reports
+// whether the request may have reached the server.
+func (c *Client) sendBatch(ctx context.Context, b []BatchElem, msgs []*jsonrpcMessage) (sent bool, err error) {
+	if c.limiter != nil {
+		if err := c.limiter.acquire(ctx); err != nil {
+			return false, err
+		}
+		defer c.limiter.release()
+	}
+
+	byID := make(map[string]int, len(b))
+	op := &requestOp{
+		ids:  make([]json.RawMessage, len(b)),
+		resp: make(chan []*jsonrpcMessage, 1),
+	}
+	for i, msg := range msgs {
 		op.ids[i] = msg.ID
 		byID[string(msg.ID)] = i
 	}
 
-	var err error
 	if c.isHTTP {
 		err = c.sendBatchHTTP(ctx, op, msgs)
+		sent = !isDialError(err)
 	} else {
-		err = c.send(ctx, op, msgs)
+		sent, err = c.sendOp(ctx, op, msgs)
 	}
 	if err != nil {
-		return err
+		return sent, err
 	}
 
 	batchresp, err := op.wait(ctx, c)
 	if err != nil {
-		return err
+		return true, err
 	}
 
 	// Wait for all responses to come back.
@@ -448,13 +889,19 @@ func (c *Client) BatchCallContext(ctx context.Context, b []BatchElem) error {
 
 		// Assign result and error.
 		elem := &b[index]
+		c.reportExecution(elem.Method, resp)
+		c.reportComputeUnits(elem.Method, resp)
+		c.reportDeprecation(resp)
+		c.observeConsistency(resp)
 		switch {
 		case resp.Error != nil:
 			elem.Error = resp.Error
 		case resp.Result == nil:
 			elem.Error = ErrNoResult
+		case resp.verifyChecksum() != nil:
+			elem.Error = ErrResponseChecksum
 		default:
-			elem.Error = json.Unmarshal(resp.Result, elem.Result)
+			elem.Error = c.decodeResult(elem.Method, resp.Result, elem.Result)
 		}
 	}
 
@@ -464,17 +911,32 @@ func (c *Client) BatchCallContext(ctx context.Context, b []BatchElem) error {
 		elem.Error = ErrMissingBatchResponse
 	}
 
-	return err
+	return true, nil
 }
 
 // Notify sends a notification, i.e. a method call that doesn't expect a response.
 func (c *Client) Notify(ctx context.Context, method string, args ...interface{}) error {
+	req := &ClientRequest{Kind: NotifyRequest, Method: method, Args: args}
+	return c.intercept(ctx, req, func(ctx context.Context, req *ClientRequest) error {
+		return c.notify(ctx, req.Method, req.Args...)
+	})
+}
+
+func (c *Client) notify(ctx context.Context, method string, args ...interface{}) error {
 	op := new(requestOp)
 	msg, err := c.newMessage(method, args...)
 	if err != nil {
 		return err
 	}
 	msg.ID = nil
+	c.attachBaggage(ctx, msg)
+	c.attachDryRun(ctx, msg)
+	if c.limiter != nil {
+		if err := c.limiter.acquire(ctx); err != nil {
+			return err
+		}
+		defer c.limiter.release()
+	}
 
 	if c.isHTTP {
 		return c.sendHTTP(ctx, op, msg)
@@ -496,15 +958,17 @@ func (c *Client) ShhSubscribe(ctx context.Context, channel interface{}, args ...
 // Subscribe calls the "<namespace>_subscribe" method with the given arguments,
 // registering a subscription. Server notifications for the subscription are
 // sent to the given channel. The element type of the channel must match the
-// expected type of content returned by the subscription.
+// expected type of content returned by the subscription. Use SequencedNotification as
+// the element type to receive notification sequence numbers.
 //
 // The context argument cancels the RPC request that sets up the subscription but has no
 // effect on the subscription after Subscribe has returned.
 //
 // Slow subscribers will be dropped eventually. Client buffers up to 20000 notifications
-// before considering the subscriber dead. The subscription Err channel will receive
-// ErrSubscriptionQueueOverflow. Use a sufficiently large buffer on the channel or ensure
-// that the channel usually has at least one reader to prevent this issue.
+// before considering the subscriber dead, unless WithSubscriptionSpill is used. The
+// subscription Err channel will receive ErrSubscriptionQueueOverflow. Use a sufficiently
+// large buffer on the channel or ensure that the channel usually has at least one reader
+// to prevent this issue.
 func (c *Client) Subscribe(ctx context.Context, namespace string, channel interface{}, args ...interface{}) (*ClientSubscription, error) {
 	// Check type of channel first.
 	chanVal := reflect.ValueOf(channel)
@@ -517,15 +981,67 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	if c.isHTTP {
 		return nil, ErrNotificationsUnsupported
 	}
+	return c.interceptSubscribe(ctx, namespace, args, func(namespace string) *ClientSubscription {
+		return newClientSubscription(c, namespace, chanVal)
+	})
+}
 
+// SubscribeWithHandler is like Subscribe, but calls fn for each notification instead
+// of sending notifications to a channel. The client runs fn on its own goroutine, one
+// notification at a time. If fn returns an error, the subscription is ended with that
+// error.
+//
+// Notifications are not buffered: while fn is running, the client stops reading from the
+// connection, which applies backpressure to the server. Note that this also delays
+// responses to other calls made through the client, so fn must not make calls using the
+// same client. The context passed to fn is canceled when the subscription is ended.
+func (c *Client) SubscribeWithHandler(ctx context.Context, namespace string, fn func(context.Context, json.RawMessage) error, args ...interface{}) (*ClientSubscription, error) {
+	if fn == nil {
+		panic("nil handler given to SubscribeWithHandler")
+	}
+	if c.isHTTP {
+		return nil, ErrNotificationsUnsupported
+	}
+	return c.interceptSubscribe(ctx, namespace, args, func(namespace string) *ClientSubscription {
+		return newHandlerSubscription(c, namespace, fn)
+	})
+}
+
+// interceptSubscribe runs a subscription request through the interceptor chain. The
+// subscription is created by newSub once the interceptors have run, so it uses the
+// final namespace.
+func (c *Client) interceptSubscribe(ctx context.Context, namespace string, args []interface{}, newSub func(namespace string) *ClientSubscription) (*ClientSubscription, error) {
+	req := &ClientRequest{Kind: SubscribeRequest, Namespace: namespace, Args: args}
+	err := c.intercept(ctx, req, func(ctx context.Context, req *ClientRequest) error {
+		sub, err := c.subscribe(ctx, req.Namespace, newSub(req.Namespace), req.Args...)
+		req.Subscription = sub
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req.Subscription, nil
+}
+
+func (c *Client) subscribe(ctx context.Context, namespace string, sub *ClientSubscription, args ...interface{}) (*ClientSubscription, error) {
 	msg, err := c.newMessage(namespace+subscribeMethodSuffix, args...)
 	if err != nil {
 		return nil, err
 	}
+	sub.args = args
+	c.attachBaggage(ctx, msg)
+	c.attachDryRun(ctx, msg)
+	c.attachTimeout(ctx, msg)
 	op := &requestOp{
 		ids:  []json.RawMessage{msg.ID},
 		resp: make(chan []*jsonrpcMessage, 1),
-		sub:  newClientSubscription(c, namespace, chanVal),
+		sub:  sub,
+	}
+	if c.limiter != nil {
+		if err := c.limiter.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer c.limiter.release()
 	}
 
 	// Send the subscription request.
@@ -548,6 +1064,16 @@ func (c *Client) SupportsSubscriptions() bool {
 
 func (c *Client) newMessage(method string, paramsIn ...interface{}) (*jsonrpcMessage, error) {
 	msg := &jsonrpcMessage{Version: vsn, ID: c.nextID(), Method: method}
+	ext := requestExt{Timing: c.executionReportFn != nil, Consistency: c.ConsistencyToken()}
+	if ext != (requestExt{}) {
+		msg.Ext, _ = json.Marshal(ext)
+	}
+	if len(paramsIn) == 1 {
+		if params, ok := paramsIn[0].(forwardedParams); ok {
+			msg.Params = json.RawMessage(params)
+			return msg, nil
+		}
+	}
 	if paramsIn != nil { // prevent sending "params":null
 		var err error
 		if msg.Params, err = json.Marshal(paramsIn); err != nil {
@@ -560,35 +1086,59 @@ func (c *Client) newMessage(method string, paramsIn ...interface{}) (*jsonrpcMes
 // send registers op with the dispatch loop, then sends msg on the connection.
 // if sending fails, op is deregistered.
 func (c *Client) send(ctx context.Context, op *requestOp, msg interface{}) error {
+	_, err := c.sendOp(ctx, op, msg)
+	return err
+}
+
+// sendOp is like send. It also reports whether writing msg was attempted, i.e. whether
+// the message may have reached the server even if an error is returned.
+func (c *Client) sendOp(ctx context.Context, op *requestOp, msg interface{}) (bool, error) {
+	var ticket *queueTicket
+	if c.queue != nil {
+		var err error
+		if ticket, err = c.queue.enter(ctx); err != nil {
+			return false, err
+		}
+		defer c.queue.leave(ticket)
+	}
+
 	select {
 	case c.reqInit <- op:
-		err := c.write(ctx, msg, false)
+		if ticket != nil {
+			// The request is no longer queued once it holds the write lock.
+			c.queue.leave(ticket)
+		}
+		attempted, err := c.write(ctx, msg, false)
 		c.reqSent <- err
-		return err
+		return attempted, err
 	case <-ctx.Done():
 		// This can happen if the client is overloaded or unable to keep up with
 		// subscription notifications.
-		return ctx.Err()
+		return false, ctx.Err()
+	case <-ticket.droppedCh():
+		return false, ErrRequestDropped
 	case <-c.closing:
-		return ErrClientQuit
+		return false, ErrClientQuit
 	}
 }
 
-func (c *Client) write(ctx context.Context, msg interface{}, retry bool) error {
+// write sends msg on the connection, reconnecting if there is none. A failed write is
+// retried once on a new connection. It reports whether writing was attempted.
+func (c *Client) write(ctx context.Context, msg interface{}, retry bool) (bool, error) {
 	if c.writeConn == nil {
 		// The previous write failed. Try to establish a new connection.
 		if err := c.reconnect(ctx); err != nil {
-			return err
+			return retry, err
 		}
 	}
 	err := c.writeConn.writeJSON(ctx, msg, false)
 	if err != nil {
 		c.writeConn = nil
 		if !retry {
-			return c.write(ctx, msg, true)
+			_, err = c.write(ctx, msg, true)
 		}
 	}
-	return err
+	return true, err
 }
 
 func (c *Client) reconnect(ctx context.Context) error {
@@ -598,7 +1148,7 @@ func (c *Client) reconnect(ctx context.Context) error {
 
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel func()
-		ctx, cancel = context.WithTimeout(ctx, defaultDialTimeout)
+		ctx, cancel = context.WithTimeout(ctx, c.dialTimeout())
 		defer cancel()
 	}
 	newconn, err := c.reconnectFunc(ctx)
@@ -625,16 +1175,31 @@ func (c *Client) dispatch(codec ServerCodec) {
 		reqInitLock = c.reqInit // nil while the send lock is held
 		conn        = c.newClientConn(codec)
 		reading     = true
+		closedLocal bool // set when the connection was closed by closeOnViolation
 	)
 	defer func() {
 		close(c.closing)
 		if reading {
 			conn.close(ErrClientQuit, nil)
 			c.drainRead()
+			c.reportDisconnect(DisconnectLocalClose, 0, ErrClientQuit)
 		}
 		close(c.didClose)
 	}()
 
+	// closeOnViolation drops the connection if the server has violated the protocol and
+	// the client is in strict mode. This is deferred while a request is being written
+	// because the in-flight request can't be canceled. Closing makes the read loop exit,
+	// which is handled by the readErr case below.
+	closeOnViolation := func() {
+		if diag := conn.handler.diag; diag != nil && diag.violation != nil {
+			conn.handler.log.Debug("Closing RPC connection", "err", diag.violation)
+			conn.close(diag.violation, nil)
+			diag.violation = nil
+			closedLocal = true
+		}
+	}
+
 	// Spawn the initial read loop.
 	go c.read(codec)
 
@@ -650,11 +1215,21 @@ func (c *Client) dispatch(codec ServerCodec) {
 			} else {
 				conn.handler.handleMsg(op.msgs[0])
 			}
+			if lastOp == nil {
+				closeOnViolation()
+			}
 
 		case err := <-c.readErr:
 			conn.handler.log.Debug("RPC connection read error", "err", err)
 			conn.close(err, lastOp)
 			reading = false
+			if closedLocal {
+				c.reportDisconnect(DisconnectLocalClose, 0, err)
+			} else {
+				cause, code := classifyDisconnect(err)
+				c.reportDisconnect(cause, code, err)
+			}
+			c.connectionLost(conn.codec)
 
 		// Reconnect:
 		case newcodec := <-c.reconnected:
@@ -667,9 +1242,14 @@ func (c *Client) dispatch(codec ServerCodec) {
 				// lastOp, which will be transferred to the new handler.
 				conn.close(errClientReconnected, lastOp)
 				c.drainRead()
+				c.reportDisconnect(DisconnectWriteError, 0, errClientReconnected)
+			}
+			if c.reconnector != nil {
+				c.reconnector.restored()
 			}
 			go c.read(newcodec)
 			reading = true
+			closedLocal = false
 			conn = c.newClientConn(newcodec)
 			// Re-register the in-flight request on the new handler
 			// because that's where it will be sent.
@@ -691,9 +1271,10 @@ func (c *Client) dispatch(codec ServerCodec) {
 			// Let the next request in.
 			reqInitLock = c.reqInit
 			lastOp = nil
+			closeOnViolation()
 
 		case op := <-c.reqTimeout:
-			conn.handler.removeRequestOp(op)
+			conn.handler.abandonRequestOp(op)
 		}
 	}
 }
@@ -716,6 +1297,9 @@ func (c *Client) read(codec ServerCodec) {
 		if _, ok := err.(*json.SyntaxError); ok {
 			msg := errorMessage(&parseError{err.Error()})
 			codec.writeJSON(context.Background(), msg, true)
+			if c.stats != nil {
+				c.stats.HandleClientEvent(ClientEvent{Kind: EventProtocolViolation, Err: err})
+			}
 		}
 		if err != nil {
 			c.readErr <- err
@@ -0,0 +1,117 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"slices"
+)
+
+// ClientRequestKind identifies the client method that created a ClientRequest.
+type ClientRequestKind int
+
+const (
+	CallRequest      ClientRequestKind = iota // CallContext
+	BatchRequest                              // BatchCallContext
+	SubscribeRequest                          // Subscribe and SubscribeWithHandler
+	NotifyRequest                             // Notify
+)
+
+func (k ClientRequestKind) String() string {
+	switch k {
+	case CallRequest:
+		return "call"
+	case BatchRequest:
+		return "batch"
+	case SubscribeRequest:
+		return "subscribe"
+	case NotifyRequest:
+		return "notify"
+	default:
+		return "unknown"
+	}
+}
+
+// ClientRequest describes an outgoing request passed through the client interceptor
+// chain. Interceptors may modify its fields before invoking the next handler.
+type ClientRequest struct {
+	Kind ClientRequestKind
+
+	// Method is the name of the called method. It is empty for batches and
+	// subscriptions.
+	Method string
+
+	// Namespace is the subscription namespace. It is only set for subscriptions.
+	Namespace string
+
+	// Args holds the call arguments. For subscriptions, the first argument is the
+	// subscription name.
+	Args []interface{}
+
+	// Result is the result pointer given to CallContext.
+	Result interface{}
+
+	// Batch holds the elements of a batch request. Results and errors are assigned to
+	// these elements.
+	Batch []BatchElem
+
+	// Subscription is set when a subscription request has succeeded.
+	Subscription *ClientSubscription
+}
+
+// ClientInvoker sends a request. It is the continuation passed to a ClientInterceptor.
+type ClientInvoker func(ctx context.Context, req *ClientRequest) error
+
+// ClientInterceptor wraps outgoing requests made through a Client. An interceptor
+// must call next to send the request. It may modify the context (for example, to add
+// HTTP headers using NewContextWithHeaders) or the request before doing so, call next
+// more than once to retry the request, or return without calling next at all.
+type ClientInterceptor func(ctx context.Context, req *ClientRequest, next ClientInvoker) error
+
+// SetInterceptors configures the interceptors applied to calls, batches, notifications
+// and subscription requests. The first interceptor in the list is the outermost one.
+// Interceptors run once per request, outside of the retry policy configured with
+// WithRetry. Calls issued by the client itself, such as unsubscribe requests, also go
+// through the interceptors.
+//
+// It is safe to call SetInterceptors while requests are in flight. Requests that have
+// already entered the interceptor chain are not affected.
+func (c *Client) SetInterceptors(interceptors []ClientInterceptor) {
+	if len(interceptors) == 0 {
+		c.interceptors.Store(nil)
+		return
+	}
+	interceptors = slices.Clone(interceptors)
+	c.interceptors.Store(&interceptors)
+}
+
+// intercept runs req through the interceptor chain, ending in send.
+func (c *Client) intercept(ctx context.Context, req *ClientRequest, send ClientInvoker) error {
+	list := c.interceptors.Load()
+	if list == nil {
+		return send(ctx, req)
+	}
+	next := send
+	for i := len(*list) - 1; i >= 0; i-- {
+		interceptor := (*list)[i]
+		nextFunc := next
+		next = func(ctx context.Context, req *ClientRequest) error {
+			return interceptor(ctx, req, nextFunc)
+		}
+	}
+	return next(ctx, req)
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientInterceptors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var trace []string
+	record := func(name string) ClientInterceptor {
+		return func(ctx context.Context, req *ClientRequest, next ClientInvoker) error {
+			trace = append(trace, name+">"+req.Kind.String())
+			err := next(ctx, req)
+			trace = append(trace, "<"+name)
+			return err
+		}
+	}
+	// This interceptor rewrites calls of test_shout to test_repeat.
+	rewrite := func(ctx context.Context, req *ClientRequest, next ClientInvoker) error {
+		if req.Kind == CallRequest && req.Method == "test_shout" {
+			req.Method = "test_repeat"
+			req.Args = append(req.Args, 2)
+		}
+		return next(ctx, req)
+	}
+	client.SetInterceptors([]ClientInterceptor{record("a"), record("b"), rewrite})
+
+	var result string
+	if err := client.Call(&result, "test_shout", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "xx" {
+		t.Errorf("wrong result %q", result)
+	}
+	want := []string{"a>call", "b>call", "<b", "<a"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("wrong call trace %v, want %v", trace, want)
+	}
+
+	// Batches pass through the chain once.
+	trace = nil
+	batch := []BatchElem{
+		{Method: "test_repeat", Args: []interface{}{"y", 3}, Result: new(string)},
+		{Method: "test_repeat", Args: []interface{}{"z", 1}, Result: new(string)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if r := *batch[0].Result.(*string); r != "yyy" {
+		t.Errorf("wrong batch result %q", r)
+	}
+	want = []string{"a>batch", "b>batch", "<b", "<a"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("wrong batch trace %v, want %v", trace, want)
+	}
+
+	// Removing the interceptors disables them.
+	trace = nil
+	client.SetInterceptors(nil)
+	if err := client.Call(&result, "test_repeat", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 0 {
+		t.Errorf("interceptors ran after removal: %v", trace)
+	}
+}
+
+func TestClientInterceptorSubscribe(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var seen *ClientSubscription
+	client.SetInterceptors([]ClientInterceptor{
+		func(ctx context.Context, req *ClientRequest, next ClientInvoker) error {
+			if req.Kind != SubscribeRequest {
+				return next(ctx, req)
+			}
+			if req.Namespace != "nftest" {
+				t.Errorf("wrong namespace %q", req.Namespace)
+			}
+			req.Args[1] = 2 // request two notifications instead of five
+			err := next(ctx, req)
+			seen = req.Subscription
+			return err
+		},
+	})
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if seen != sub {
+		t.Fatal("interceptor did not observe the subscription")
+	}
+	for i := 0; i < 2; i++ {
+		if v := <-ch; v != i {
+			t.Fatalf("wrong notification %d, want %d", v, i)
+		}
+	}
+}
+
+func TestClientInterceptorHeaders(t *testing.T) {
+	t.Parallel()
+
+	var traceHeader string
+	rpcServer := newTestServer()
+	defer rpcServer.Stop()
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceHeader = r.Header.Get("X-Trace")
+		rpcServer.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	client, err := Dial(httpServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.SetInterceptors([]ClientInterceptor{
+		func(ctx context.Context, req *ClientRequest, next ClientInvoker) error {
+			h := http.Header{}
+			h.Set("X-Trace", req.Kind.String()+":"+req.Method)
+			return next(NewContextWithHeaders(ctx, h), req)
+		},
+	})
+
+	var result string
+	if err := client.Call(&result, "test_repeat", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if traceHeader != "call:test_repeat" {
+		t.Errorf("wrong trace header %q", traceHeader)
+	}
+}
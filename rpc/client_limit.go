@@ -0,0 +1,129 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+)
+
+type callerTagKey struct{}
+
+// WithCallerTag returns a context which attributes requests made using it to the given
+// caller. When the client limits the number of in-flight requests (see WithMaxInflight),
+// waiting requests of different callers are admitted in round-robin order, so a caller
+// issuing many requests can't starve other callers sharing the client.
+//
+// Requests made with a context that has no caller tag belong to the caller "".
+func WithCallerTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, callerTagKey{}, tag)
+}
+
+// CallerTagFromContext returns the caller tag set by WithCallerTag.
+func CallerTagFromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(callerTagKey{}).(string)
+	return tag
+}
+
+// inflightLimiter bounds the number of concurrent requests of a client. Waiting requests
+// are queued per caller and admitted round-robin across callers.
+type inflightLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	queues map[string]*list.List // waiting requests per caller tag, of chan struct{}
+	order  []string              // tags with waiting requests, next to be served first
+}
+
+func newInflightLimiter(limit int) *inflightLimiter {
+	return &inflightLimiter{limit: limit, queues: make(map[string]*list.List)}
+}
+
+// acquire waits for an in-flight slot. Every successful call to acquire must be followed
+// by a call to release.
+func (l *inflightLimiter) acquire(ctx context.Context) error {
+	tag := CallerTagFromContext(ctx)
+
+	l.mu.Lock()
+	if l.active < l.limit && len(l.order) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	q := l.queues[tag]
+	if q == nil {
+		q = list.New()
+		l.queues[tag] = q
+		l.order = append(l.order, tag)
+	}
+	ready := make(chan struct{})
+	elem := q.PushBack(ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-ready:
+			// The slot was handed over concurrently, pass it on.
+			l.mu.Unlock()
+			l.release()
+			return ctx.Err()
+		default:
+		}
+		q.Remove(elem)
+		if q.Len() == 0 {
+			l.removeCaller(tag)
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// release gives up an in-flight slot. If requests are waiting, the slot is handed to the
+// first request of the next caller.
+func (l *inflightLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.order) == 0 {
+		l.active--
+		return
+	}
+	tag := l.order[0]
+	q := l.queues[tag]
+	close(q.Remove(q.Front()).(chan struct{}))
+	l.order = l.order[1:]
+	if q.Len() > 0 {
+		l.order = append(l.order, tag)
+	} else {
+		delete(l.queues, tag)
+	}
+}
+
+// removeCaller removes a caller without waiting requests.
+// This assumes l.mu is held.
+func (l *inflightLimiter) removeCaller(tag string) {
+	delete(l.queues, tag)
+	if i := slices.Index(l.order, tag); i >= 0 {
+		l.order = slices.Delete(l.order, i, i+1)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until the limiter has n queued requests.
+func (l *inflightLimiter) waitForWaiters(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		queued := 0
+		for _, q := range l.queues {
+			queued += q.Len()
+		}
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %d queued requests", n)
+}
+
+func TestInflightLimiterFairness(t *testing.T) {
+	t.Parallel()
+
+	l := newInflightLimiter(1)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queue three bulk requests, then one request of another caller.
+	admitted := make(chan string, 4)
+	enqueue := func(tag string, n int) {
+		go func() {
+			l.acquire(WithCallerTag(context.Background(), tag))
+			admitted <- tag
+		}()
+		l.waitForWaiters(t, n)
+	}
+	enqueue("bulk", 1)
+	enqueue("bulk", 2)
+	enqueue("bulk", 3)
+	enqueue("api", 4)
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		l.release()
+		order = append(order, <-admitted)
+	}
+	want := []string{"bulk", "api", "bulk", "bulk"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("wrong admission order %v, want %v", order, want)
+	}
+}
+
+func TestInflightLimiterCancel(t *testing.T) {
+	t.Parallel()
+
+	l := newInflightLimiter(1)
+	l.acquire(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if len(l.queues) != 0 || len(l.order) != 0 {
+		t.Fatal("canceled request still queued")
+	}
+	l.release()
+	if l.active != 0 {
+		t.Fatalf("wrong active count %d after release", l.active)
+	}
+}
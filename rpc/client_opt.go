@@ -17,8 +17,13 @@
 package rpc
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/gorilla/websocket"
 )
 
@@ -36,11 +41,99 @@ type clientConfig struct {
 	// WebSocket options
 	wsDialer           *websocket.Dialer
 	wsMessageSizeLimit *int64 // wsMessageSizeLimit nil = default, 0 = no limit
+	wsFragmentSize     int
+	wsCompressors      []WebsocketCompressor
+
+	// Wire encoding, see WithWireCodec
+	wireCodec      WireCodec
+	binaryPayloads int // see WithBinaryPayloads
+
+	// Dialing
+	socksProxy    *SOCKSProxy
+	eyeballsDelay time.Duration // zero if multi-address dialing is disabled
+	tcpOptions    *TCPOptions
+	resolver      Resolver // resolves endpoint host names, nil for the system resolver
 
 	// RPC handler options
 	idgen              func() ID
+	epochIDs           bool // ids created by idgen start with epoch
+	epoch              string
 	batchItemLimit     int
 	batchResponseLimit int
+	checkDuplicateIDs  bool
+	synchronousCalls   bool
+	orderNotifications bool
+	subscriptionOwner  SubscriptionOwnerFunc
+	sessions           *sessionRegistry
+	responseChecksums  bool
+	executionReports   bool
+	canonicalJSON      bool
+	numberPolicy       NumberPolicy
+	timeFormat         TimeFormat
+	serverEvents       *serverEvents // set when serving connections of a Server
+	connID             ConnID        // set when serving connections of a Server
+	clock              mclock.Clock
+
+	// Diagnostics
+	statsHandler   StatsHandler
+	strictProtocol bool
+
+	// Batch chunking
+	batchChunkItems     int
+	batchChunkBytes     int
+	discoverBatchLimits bool
+
+	// Concurrency limit
+	maxInflight int
+
+	// Notification dispatch
+	dispatchWorkers int
+
+	// Subscription spill
+	subscriptionSpillDir   string
+	subscriptionSpillSize  int64
+	subscriptionSpillCodec StorageCodec
+
+	// Resubscription, see WithResubscribe
+	resubscribeAttempts int
+
+	// Reconnecting mode, see WithReconnect
+	reconnectPolicy *ReconnectPolicy
+
+	// Shadow traffic
+	shadow *ShadowConfig
+
+	// Retries and hedging
+	retryPolicy   *RetryPolicy
+	hedgePolicy   *HedgePolicy
+	nonIdempotent []string
+
+	// Deadline propagation, see WithDeadlinePropagation
+	propagateDeadline bool
+
+	// Redirects, see WithFollowRedirects
+	maxRedirects int
+
+	// HTTP/2 without TLS, see WithH2C
+	h2c bool
+
+	// Result schema checks, see WithSchemaDrift
+	schemaDrift *SchemaDriftConfig
+
+	// Request journal
+	journal *Journal
+
+	// Execution reports
+	executionReportFn   func(method string, report ExecutionReport)
+	computeUnitsFn      func(method string, usage ComputeUnitUsage)
+	deprecationFn       func(notice DeprecationNotice)
+	consistencyTokens   bool
+	responseCacheSize   int
+	responseCacheMaxTTL time.Duration
+
+	// Request queue
+	requestQueueSize   int
+	requestQueuePolicy QueueOverflowPolicy
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -54,6 +147,28 @@ func (cfg *clientConfig) setHeader(key, value string) {
 	cfg.httpHeaders.Set(key, value)
 }
 
+// setDialer makes the client open HTTP and websocket connections using dial. Requests
+// are sent through the proxy returned by proxy, if it is non-nil.
+func (cfg *clientConfig) setDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error), proxy func(*http.Request) (*url.URL, error)) {
+	cfg.httpClient = &http.Client{
+		Transport: &http.Transport{
+			Proxy:               proxy,
+			DialContext:         dial,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+	cfg.wsDialer = &websocket.Dialer{
+		ReadBufferSize:  wsReadBuffer,
+		WriteBufferSize: wsWriteBuffer,
+		WriteBufferPool: wsBufferPool,
+		Proxy:           proxy,
+		NetDialContext:  dial,
+	}
+}
+
 type optionFunc func(*clientConfig)
 
 func (fn optionFunc) applyOption(opt *clientConfig) {
@@ -69,12 +184,29 @@ func WithWebsocketDialer(dialer websocket.Dialer) ClientOption {
 
 // WithWebsocketMessageSizeLimit configures the websocket message size limit used by the RPC
 // client. Passing a limit of 0 means no limit.
+//
+// The limit is also requested from the server during the handshake. Servers which
+// negotiate message sizes (see Server.SetWebsocketMessageSizePolicy) replace responses
+// larger than the limit they grant by an error.
 func WithWebsocketMessageSizeLimit(messageSizeLimit int64) ClientOption {
 	return optionFunc(func(cfg *clientConfig) {
 		cfg.wsMessageSizeLimit = &messageSizeLimit
 	})
 }
 
+// WithWebsocketFragmentSize makes the websocket client split messages larger than size
+// bytes into multiple websocket messages, which are reassembled by the server. Use this
+// when requests must pass through infrastructure which limits the size of websocket
+// messages. Fragmentation is only used if the server supports reassembly, see
+// Server.SetWebsocketFragmentSize.
+//
+// The client always offers to reassemble fragmented messages sent by the server.
+func WithWebsocketFragmentSize(size int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.wsFragmentSize = size
+	})
+}
+
 // WithHeader configures HTTP headers set by the RPC client. Headers set using this option
 // will be used for both HTTP and WebSocket connections.
 func WithHeader(key, value string) ClientOption {
@@ -122,6 +254,7 @@ func WithHTTPAuth(a HTTPAuth) ClientOption {
 type HTTPAuth func(h http.Header) error
 
 // WithBatchItemLimit changes the maximum number of items allowed in batch requests.
+// Calls beyond the limit are answered with an error. See also Client.SetBatchLimits.
 //
 // Note: this option applies when processing incoming batch requests. It does not affect
 // batch requests sent by the client.
@@ -131,9 +264,47 @@ func WithBatchItemLimit(limit int) ClientOption {
 	})
 }
 
+// WithMaxInflight limits the number of requests the client processes concurrently. Calls,
+// notifications, subscription requests and batches (or batch chunks) each count as a
+// single request. Further requests wait for a slot until their context is canceled.
+//
+// Waiting requests are admitted fairly across callers identified by WithCallerTag.
+func WithMaxInflight(n int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.maxInflight = n
+	})
+}
+
+// WithBatchChunking makes the client split batch requests sent by BatchCallContext into
+// multiple requests when they exceed the given limits. 'maxItems' is the maximum number of
+// calls in a single request, and 'maxBytes' is the maximum approximate size of a request.
+// A limit of zero disables the respective check. Use this option to work with servers
+// which reject large batches.
+//
+// The results of all chunks are assigned to the batch elements in their original order.
+// Chunks are sent one after another, and BatchCallContext returns when the first I/O
+// error occurs.
+func WithBatchChunking(maxItems, maxBytes int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.batchChunkItems = maxItems
+		cfg.batchChunkBytes = maxBytes
+	})
+}
+
+// WithBatchLimitDiscovery makes the client query the server's limits using the
+// rpc_limits method before sending the first batch request. Batches are then split into
+// chunks which don't exceed the server's batch item limit and, for HTTP, request body size
+// limit. Limits configured using WithBatchChunking are used if they are stricter. The
+// option has no effect when the server doesn't provide rpc_limits.
+func WithBatchLimitDiscovery() ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.discoverBatchLimits = true
+	})
+}
+
 // WithBatchResponseSizeLimit changes the maximum number of response bytes that can be
 // generated for batch requests. When this limit is reached, further calls in the batch
-// will not be processed.
+// will not be processed and are answered with an error.
 //
 // Note: this option applies when processing incoming batch requests. It does not affect
 // batch requests sent by the client.
@@ -142,3 +313,96 @@ func WithBatchResponseSizeLimit(sizeLimit int) ClientOption {
 		cfg.batchResponseLimit = sizeLimit
 	})
 }
+
+// WithRequestQueue bounds the number of outgoing requests that can wait for the
+// connection, e.g. while a websocket connection is being re-established or is slow to
+// accept writes. When the queue already holds size requests, the policy decides how a new
+// request is handled. By default, the number of waiting requests is unbounded.
+//
+// Note: this option has no effect for HTTP clients, which do not share a connection
+// between requests.
+func WithRequestQueue(size int, policy QueueOverflowPolicy) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.requestQueueSize = size
+		cfg.requestQueuePolicy = policy
+	})
+}
+
+// WithStatsHandler configures a handler for diagnostic events of the client, such as
+// responses with unknown ids or responses arriving after the request has timed out.
+//
+// Note: diagnostics are not reported for HTTP clients.
+func WithStatsHandler(h StatsHandler) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.statsHandler = h
+	})
+}
+
+// WithStrictProtocol makes the client close its connection when the server sends an
+// invalid message or a response that doesn't match any request. Pending requests fail
+// with an error wrapping ErrProtocolViolation. Late responses to requests that have timed
+// out are tolerated.
+//
+// The client reconnects on the next request, just like it does after other connection
+// failures.
+func WithStrictProtocol() ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.strictProtocol = true
+	})
+}
+
+// WithSubscriptionSpill makes subscriptions store notifications in a file when the
+// in-memory buffer of a subscription is full, instead of dropping the subscription. Use
+// this for consumers which value completeness over latency.
+//
+// Each subscription gets its own file in dir, holding at most maxBytes of notification
+// data. The subscription is dropped with ErrSubscriptionQueueOverflow when the file is full
+// as well. Files are removed when the subscription ends.
+func WithSubscriptionSpill(dir string, maxBytes int64) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.subscriptionSpillDir = dir
+		cfg.subscriptionSpillSize = maxBytes
+	})
+}
+
+// WithSubscriptionSpillCodec makes subscriptions compress notifications stored in spill
+// files using codec, see WithSubscriptionSpill. The size limit of the files applies to the
+// compressed data. Use NewEncryptedCodec to keep notifications from being stored in
+// plaintext.
+func WithSubscriptionSpillCodec(codec StorageCodec) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.subscriptionSpillCodec = codec
+	})
+}
+
+// WithDispatchWorkers makes the client deliver subscription notifications on a pool of n
+// worker goroutines instead of its read loop. Use this when subscriptions are slow to
+// process notifications, e.g. due to expensive unmarshaling, so they don't delay
+// responses and notifications of other subscriptions. Notifications of a single
+// subscription are always delivered in order.
+//
+// Note: this option has no effect for HTTP clients, which do not support subscriptions.
+func WithDispatchWorkers(n int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.dispatchWorkers = n
+	})
+}
+
+// WithNumberPolicy configures how numbers in results and subscription notifications are
+// decoded when the destination has interface type, e.g. when calling with a result of
+// type *interface{}. The default is NumberFloat64. The policy also applies to parameters
+// of calls served by the client.
+func WithNumberPolicy(p NumberPolicy) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.numberPolicy = p
+	})
+}
+
+// WithIDGenerator sets the function creating the ids of subscriptions served by the
+// client, i.e. subscriptions of services registered using Client.RegisterName. See
+// Server.SetIDGenerator.
+func WithIDGenerator(gen func() ID) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.idgen = gen
+	})
+}
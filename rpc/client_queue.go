@@ -0,0 +1,136 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrRequestQueueFull = errors.New("request queue full")
+	ErrRequestDropped   = errors.New("request dropped from full queue")
+)
+
+// QueueOverflowPolicy selects what happens when a request is sent while the client's
+// request queue is full. See WithRequestQueue.
+type QueueOverflowPolicy int
+
+const (
+	// QueueOverflowError fails the new request with ErrRequestQueueFull.
+	QueueOverflowError QueueOverflowPolicy = iota
+	// QueueOverflowBlock makes the new request wait for a free slot, bounded by the
+	// request context.
+	QueueOverflowBlock
+	// QueueOverflowDropOldest fails the longest-waiting request with ErrRequestDropped
+	// and queues the new request in its place.
+	QueueOverflowDropOldest
+)
+
+// requestQueue bounds the number of requests waiting to be written to the connection.
+// Requests are 'queued' from the time they are sent until they acquire the client's write
+// lock, i.e. while the connection is busy writing other requests or reconnecting.
+type requestQueue struct {
+	size   int
+	policy QueueOverflowPolicy
+
+	mu      sync.Mutex
+	waiting *list.List    // of *queueTicket, oldest first
+	wake    chan struct{} // closed when a slot is released
+}
+
+type queueTicket struct {
+	elem    *list.Element
+	dropped chan struct{} // closed when the ticket is evicted by QueueOverflowDropOldest
+}
+
+// droppedCh returns a channel that is closed when the ticket is evicted from the queue.
+// It returns nil for the nil ticket, which is used when the queue is disabled.
+func (t *queueTicket) droppedCh() <-chan struct{} {
+	if t == nil {
+		return nil
+	}
+	return t.dropped
+}
+
+func newRequestQueue(size int, policy QueueOverflowPolicy) *requestQueue {
+	return &requestQueue{
+		size:    size,
+		policy:  policy,
+		waiting: list.New(),
+		wake:    make(chan struct{}),
+	}
+}
+
+// enter adds a request to the queue, applying the overflow policy if it is full.
+func (q *requestQueue) enter(ctx context.Context) (*queueTicket, error) {
+	q.mu.Lock()
+	for q.waiting.Len() >= q.size {
+		switch q.policy {
+		case QueueOverflowDropOldest:
+			q.remove(q.waiting.Front().Value.(*queueTicket), true)
+		case QueueOverflowBlock:
+			wake := q.wake
+			q.mu.Unlock()
+			select {
+			case <-wake:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			q.mu.Lock()
+		default:
+			q.mu.Unlock()
+			return nil, ErrRequestQueueFull
+		}
+	}
+	t := &queueTicket{dropped: make(chan struct{})}
+	t.elem = q.waiting.PushBack(t)
+	q.mu.Unlock()
+	return t, nil
+}
+
+// leave removes a request from the queue. It is safe to call leave for tickets that have
+// already been dropped.
+func (q *requestQueue) leave(t *queueTicket) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.remove(t, false)
+}
+
+// remove takes the ticket out of the queue and wakes up blocked senders.
+// This assumes q.mu is held.
+func (q *requestQueue) remove(t *queueTicket, drop bool) {
+	if t.elem == nil {
+		return
+	}
+	q.waiting.Remove(t.elem)
+	t.elem = nil
+	if drop {
+		close(t.dropped)
+	}
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+// len returns the number of queued requests.
+func (q *requestQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.waiting.Len()
+}
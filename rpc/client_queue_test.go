@@ -0,0 +1,100 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestQueueOverflowError(t *testing.T) {
+	t.Parallel()
+
+	q := newRequestQueue(2, QueueOverflowError)
+	ctx := context.Background()
+	t1, _ := q.enter(ctx)
+	q.enter(ctx)
+	if _, err := q.enter(ctx); !errors.Is(err, ErrRequestQueueFull) {
+		t.Fatalf("wrong error for full queue: %v", err)
+	}
+	q.leave(t1)
+	q.leave(t1) // leaving twice is a no-op
+	if _, err := q.enter(ctx); err != nil {
+		t.Fatalf("unexpected error after leave: %v", err)
+	}
+	if q.len() != 2 {
+		t.Fatalf("wrong queue length %d", q.len())
+	}
+}
+
+func TestRequestQueueOverflowBlock(t *testing.T) {
+	t.Parallel()
+
+	q := newRequestQueue(1, QueueOverflowBlock)
+	t1, _ := q.enter(context.Background())
+
+	// Entering the full queue blocks until the context is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := q.enter(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wrong error for blocked enter: %v", err)
+	}
+
+	// ...or until a slot is released.
+	entered := make(chan error)
+	go func() {
+		_, err := q.enter(context.Background())
+		entered <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	q.leave(t1)
+	select {
+	case err := <-entered:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked enter did not return after leave")
+	}
+}
+
+func TestRequestQueueOverflowDropOldest(t *testing.T) {
+	t.Parallel()
+
+	q := newRequestQueue(2, QueueOverflowDropOldest)
+	ctx := context.Background()
+	t1, _ := q.enter(ctx)
+	t2, _ := q.enter(ctx)
+	if _, err := q.enter(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-t1.droppedCh():
+	default:
+		t.Fatal("oldest ticket was not dropped")
+	}
+	select {
+	case <-t2.droppedCh():
+		t.Fatal("second ticket was dropped")
+	default:
+	}
+	if q.len() != 2 {
+		t.Fatalf("wrong queue length %d", q.len())
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/gorilla/websocket"
+)
+
+// ErrProtocolViolation is returned for pending requests when a client in strict mode
+// closes the connection because the server violated the protocol.
+var ErrProtocolViolation = errors.New("server violated JSON-RPC protocol")
+
+// ClientEventKind identifies the type of a ClientEvent.
+type ClientEventKind int
+
+const (
+	// EventUnmatchedResponse is reported for responses whose id does not belong to any
+	// request sent by the client.
+	EventUnmatchedResponse ClientEventKind = iota
+	// EventLateResponse is reported for responses arriving after the request has timed
+	// out or was canceled.
+	EventLateResponse
+	// EventProtocolViolation is reported for messages which are not valid JSON-RPC.
+	EventProtocolViolation
+	// EventDisconnect is reported when the connection of a websocket, IPC or stdio
+	// client is lost or closed. The Cause field of the event says why.
+	EventDisconnect
+)
+
+func (k ClientEventKind) String() string {
+	switch k {
+	case EventUnmatchedResponse:
+		return "unmatched response"
+	case EventLateResponse:
+		return "late response"
+	case EventProtocolViolation:
+		return "protocol violation"
+	case EventDisconnect:
+		return "disconnect"
+	default:
+		return fmt.Sprintf("ClientEventKind(%d)", int(k))
+	}
+}
+
+// ClientEvent describes unexpected behavior of the server observed by the client.
+type ClientEvent struct {
+	Kind ClientEventKind
+	ID   json.RawMessage // id of the offending message, if any
+	Err  error           // describes the problem
+
+	// Set for EventDisconnect.
+	Cause     DisconnectCause
+	CloseCode int // websocket close code sent by the server, zero if none
+}
+
+// DisconnectCause categorizes the reasons for losing a client connection.
+type DisconnectCause int
+
+const (
+	// DisconnectReadError is a failure to read from the connection, e.g. a connection
+	// reset.
+	DisconnectReadError DisconnectCause = iota
+	// DisconnectPingTimeout means the server did not answer a websocket ping in time.
+	DisconnectPingTimeout
+	// DisconnectServerClose means the server closed the connection, either with a
+	// websocket close message or by closing the socket.
+	DisconnectServerClose
+	// DisconnectWriteError means the connection was replaced after a request could
+	// not be sent.
+	DisconnectWriteError
+	// DisconnectLocalClose means the client closed the connection, because Close was
+	// called or the server violated the protocol in strict mode.
+	DisconnectLocalClose
+
+	numDisconnectCauses = iota
+)
+
+func (c DisconnectCause) String() string {
+	switch c {
+	case DisconnectReadError:
+		return "readError"
+	case DisconnectPingTimeout:
+		return "pingTimeout"
+	case DisconnectServerClose:
+		return "serverClose"
+	case DisconnectWriteError:
+		return "writeError"
+	case DisconnectLocalClose:
+		return "localClose"
+	default:
+		return fmt.Sprintf("DisconnectCause(%d)", int(c))
+	}
+}
+
+// disconnectCounters count lost client connections by cause. They are registered as
+// rpc/client/disconnect/<cause>.
+var disconnectCounters = func() (c [numDisconnectCauses]*metrics.Counter) {
+	for i := range c {
+		c[i] = metrics.NewRegisteredCounter("rpc/client/disconnect/"+DisconnectCause(i).String(), nil)
+	}
+	return c
+}()
+
+// classifyDisconnect returns the cause of a read error.
+func classifyDisconnect(err error) (cause DisconnectCause, closeCode int) {
+	var (
+		closeErr *websocket.CloseError
+		netErr   net.Error
+	)
+	switch {
+	case errors.As(err, &closeErr):
+		return DisconnectServerClose, closeErr.Code
+	case errors.Is(err, io.EOF):
+		return DisconnectServerClose, 0
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return DisconnectPingTimeout, 0
+	default:
+		return DisconnectReadError, 0
+	}
+}
+
+// reportDisconnect records the loss of the client connection.
+func (c *Client) reportDisconnect(cause DisconnectCause, closeCode int, err error) {
+	if c.serverEvents != nil {
+		return // connection of a Server, not a client
+	}
+	disconnectCounters[cause].Inc(1)
+	if c.stats != nil {
+		c.stats.HandleClientEvent(ClientEvent{Kind: EventDisconnect, Err: err, Cause: cause, CloseCode: closeCode})
+	}
+}
+
+// StatsHandler receives diagnostic events from the RPC client. It is configured using the
+// WithStatsHandler option. Implementations must be safe for concurrent use and should not
+// block, since events are delivered on the client's message dispatch loop.
+type StatsHandler interface {
+	HandleClientEvent(ev ClientEvent)
+}
+
+// maxTrackedTimeouts is the number of timed-out request ids remembered for detecting
+// late responses.
+const maxTrackedTimeouts = 512
+
+// clientDiagnostics tracks unexpected messages received on a client connection.
+// It is only accessed by the client's dispatch loop.
+type clientDiagnostics struct {
+	stats  StatsHandler
+	strict bool
+
+	timedOut      map[string]struct{}
+	timedOutOrder []string
+
+	// violation is set when a strict client should drop the connection.
+	violation error
+}
+
+func newClientDiagnostics(stats StatsHandler, strict bool) *clientDiagnostics {
+	return &clientDiagnostics{
+		stats:    stats,
+		strict:   strict,
+		timedOut: make(map[string]struct{}),
+	}
+}
+
+// addTimeout remembers the ids of a request that was abandoned by the caller.
+func (d *clientDiagnostics) addTimeout(op *requestOp) {
+	for _, id := range op.ids {
+		if len(d.timedOutOrder) == maxTrackedTimeouts {
+			delete(d.timedOut, d.timedOutOrder[0])
+			d.timedOutOrder = d.timedOutOrder[1:]
+		}
+		d.timedOut[string(id)] = struct{}{}
+		d.timedOutOrder = append(d.timedOutOrder, string(id))
+	}
+}
+
+// unexpectedResponse is called for responses that don't belong to a pending request.
+func (d *clientDiagnostics) unexpectedResponse(msg *jsonrpcMessage) {
+	if _, ok := d.timedOut[string(msg.ID)]; ok {
+		delete(d.timedOut, string(msg.ID))
+		d.report(EventLateResponse, msg.ID, fmt.Errorf("response for id %s arrived after request was abandoned", msg.ID))
+		return
+	}
+	d.report(EventUnmatchedResponse, msg.ID, fmt.Errorf("response for unknown id %s", msg.ID))
+}
+
+func (d *clientDiagnostics) report(kind ClientEventKind, id json.RawMessage, err error) {
+	if d.stats != nil {
+		d.stats.HandleClientEvent(ClientEvent{Kind: kind, ID: id, Err: err})
+	}
+	if d.strict && kind != EventLateResponse && d.violation == nil {
+		d.violation = fmt.Errorf("%w: %v", ErrProtocolViolation, err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type testStatsHandler chan ClientEvent
+
+func (h testStatsHandler) HandleClientEvent(ev ClientEvent) { h <- ev }
+
+// newRawServerClient creates a client connected to a fake server, which is driven
+// by the test through the returned encoder and decoder.
+func newRawServerClient(t *testing.T, opts ...ClientOption) (*Client, *json.Encoder, *json.Decoder) {
+	p1, p2 := net.Pipe()
+	t.Cleanup(func() { p1.Close() })
+	cfg := new(clientConfig)
+	for _, opt := range opts {
+		opt.applyOption(cfg)
+	}
+	client := initClient(NewCodec(p2), new(serviceRegistry), cfg)
+	t.Cleanup(client.Close)
+	return client, json.NewEncoder(p1), json.NewDecoder(p1)
+}
+
+func TestClientStatsLateResponse(t *testing.T) {
+	t.Parallel()
+
+	stats := make(testStatsHandler, 1)
+	client, enc, dec := newRawServerClient(t, WithStatsHandler(stats), WithStrictProtocol())
+
+	// Send a call and let it time out.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- client.CallContext(ctx, nil, "test_sleep") }()
+	var req jsonrpcMessage
+	if err := dec.Decode(&req); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	// Now answer it. The late response is reported, but tolerated in strict mode.
+	enc.Encode(&jsonrpcMessage{Version: vsn, ID: req.ID, Result: null})
+	ev := <-stats
+	if ev.Kind != EventLateResponse || string(ev.ID) != string(req.ID) {
+		t.Fatalf("wrong event: %+v", ev)
+	}
+	go func() { errc <- client.Call(nil, "test_echo") }()
+	if err := dec.Decode(&req); err != nil {
+		t.Fatal(err)
+	}
+	enc.Encode(&jsonrpcMessage{Version: vsn, ID: req.ID, Result: null})
+	if err := <-errc; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientStatsStrictUnmatchedResponse(t *testing.T) {
+	t.Parallel()
+
+	stats := make(testStatsHandler, 1)
+	client, enc, dec := newRawServerClient(t, WithStatsHandler(stats), WithStrictProtocol())
+
+	errc := make(chan error, 1)
+	go func() { errc <- client.Call(nil, "test_echo") }()
+	var req jsonrpcMessage
+	if err := dec.Decode(&req); err != nil {
+		t.Fatal(err)
+	}
+	enc.Encode(&jsonrpcMessage{Version: vsn, ID: json.RawMessage(`"bogus"`), Result: null})
+
+	ev := <-stats
+	if ev.Kind != EventUnmatchedResponse || string(ev.ID) != `"bogus"` {
+		t.Fatalf("wrong event: %+v", ev)
+	}
+	if err := <-errc; !errors.Is(err, ErrProtocolViolation) {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
+
+func TestClientStatsDisconnect(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetDrainOptions(0, 50*time.Millisecond)
+	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	stats := make(testStatsHandler, 1)
+	client, err := DialOptions(context.Background(), wsURL, WithStatsHandler(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The draining server closes the idle connection with a close message.
+	srv.StartDraining()
+	ev := <-stats
+	if ev.Kind != EventDisconnect || ev.Cause != DisconnectServerClose || ev.CloseCode != websocket.CloseGoingAway {
+		t.Fatalf("wrong event: %+v", ev)
+	}
+
+	// Closing the client is reported as a local close.
+	client2, err := DialOptions(context.Background(), wsURL, WithStatsHandler(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client2.Close()
+	ev = <-stats
+	if ev.Kind != EventDisconnect || ev.Cause != DisconnectLocalClose {
+		t.Fatalf("wrong event: %+v", ev)
+	}
+}
+
+func TestClassifyDisconnect(t *testing.T) {
+	tests := []struct {
+		err  error
+		want DisconnectCause
+	}{
+		{io.EOF, DisconnectServerClose},
+		{&websocket.CloseError{Code: websocket.CloseServiceRestart}, DisconnectServerClose},
+		{&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, DisconnectPingTimeout},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, DisconnectReadError},
+	}
+	for _, test := range tests {
+		if cause, _ := classifyDisconnect(test.err); cause != test.want {
+			t.Errorf("classifyDisconnect(%v) = %v, want %v", test.err, cause, test.want)
+		}
+	}
+}
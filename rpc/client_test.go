@@ -17,10 +17,12 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"net/http"
@@ -30,10 +32,12 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -283,21 +287,176 @@ func TestClientBatchRequestLimit(t *testing.T) {
 		t.Fatal("unexpected error:", err)
 	}
 
-	// Check that the first response indicates an error with batch size.
-	var err0 Error
-	if !errors.As(batch[0].Error, &err0) {
-		t.Log("error zero:", batch[0].Error)
-		t.Fatalf("batch elem 0 has wrong error type: %T", batch[0].Error)
-	} else {
-		if err0.ErrorCode() != -32600 || err0.Error() != errMsgBatchTooLarge {
-			t.Fatalf("wrong error on batch elem zero: %v", err0)
+	// Check that the calls within the limit were executed.
+	for i, elem := range batch[:2] {
+		var err Error
+		if !errors.As(elem.Error, &err) || err.ErrorCode() != -32601 {
+			t.Fatalf("batch elem %d has unexpected error: %v", i, elem.Error)
+		}
+	}
+
+	// Check that the call beyond the limit indicates an error with batch size.
+	var err2 DataError
+	if !errors.As(batch[2].Error, &err2) {
+		t.Fatalf("batch elem 2 has wrong error type: %T", batch[2].Error)
+	}
+	if err2.(Error).ErrorCode() != -32600 || err2.Error() != errMsgBatchTooLarge {
+		t.Fatalf("wrong error on batch elem 2: %v", err2)
+	}
+	wantData := map[string]interface{}{"limit": "items", "max": float64(2)}
+	if !reflect.DeepEqual(err2.ErrorData(), wantData) {
+		t.Fatalf("wrong error data on batch elem 2: %v", err2.ErrorData())
+	}
+}
+
+func TestClientBatchChunking(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetBatchLimits(2, 100000)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithBatchChunking(2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var batch []BatchElem
+	for i := 0; i < 5; i++ {
+		batch = append(batch, BatchElem{
+			Method: "test_echo",
+			Args:   []any{"x", i},
+			Result: new(echoResult),
+		})
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			t.Fatalf("batch elem %d has unexpected error: %v", i, elem.Error)
+		}
+		if res := elem.Result.(*echoResult); res.Int != i {
+			t.Fatalf("batch elem %d has wrong result: %+v", i, res)
 		}
 	}
+}
+
+func TestClientBatchLimitDiscovery(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetBatchLimits(2, 100000)
+	server.SetHTTPBodyLimit(50000)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithBatchLimitDiscovery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
 
-	// Check that remaining response batch elements are reported as absent.
-	for i, elem := range batch[1:] {
-		if elem.Error != ErrMissingBatchResponse {
-			t.Fatalf("batch elem %d has unexpected error: %v", i+1, elem.Error)
+	limits, err := client.ServerLimits(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ServerLimits{BatchRequestLimit: 2, BatchResponseMaxSize: 100000, HTTPBodyLimit: 50000}
+	if *limits != want {
+		t.Fatalf("wrong limits %+v, want %+v", *limits, want)
+	}
+
+	batch := make([]BatchElem, 5)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []any{"x", i}, Result: new(echoResult)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			t.Fatalf("batch elem %d has unexpected error: %v", i, elem.Error)
+		}
+	}
+	if client.batchChunk != (batchLimits{items: 2, bytes: 50000}) {
+		t.Fatalf("wrong batch limits after discovery: %+v", client.batchChunk)
+	}
+}
+
+func TestClientBatchLimitDiscoveryFailure(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	var discoveries atomic.Int32
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "rpc_limits") {
+			discoveries.Add(1)
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		server.ServeHTTP(w, r)
+	}))
+	defer httpsrv.Close()
+
+	clock := new(mclock.Simulated)
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithBatchLimitDiscovery(), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batch := []BatchElem{{Method: "test_echo", Args: []any{"x", 1}, Result: new(echoResult)}}
+	for i := 0; i < 3; i++ {
+		if err := client.BatchCall(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := discoveries.Load(); n != 1 {
+		t.Fatalf("discovery attempted %d times, want 1", n)
+	}
+	// Discovery is retried after the backoff.
+	clock.Run(limitsDiscoveryBackoff)
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if n := discoveries.Load(); n != 2 {
+		t.Fatalf("discovery attempted %d times after backoff, want 2", n)
+	}
+}
+
+func TestBatchLimitsChunkEnd(t *testing.T) {
+	t.Parallel()
+
+	var msgs []*jsonrpcMessage
+	for i := 0; i < 5; i++ {
+		msgs = append(msgs, &jsonrpcMessage{Version: vsn, ID: json.RawMessage("1"), Method: "test_echo", Params: json.RawMessage(`["x"]`)})
+	}
+	size := msgs[0].encodedSizeHint()
+	tests := []struct {
+		items, bytes int
+		want         []int
+	}{
+		{0, 0, []int{5}},
+		{2, 0, []int{2, 4, 5}},
+		{0, 3 * size, []int{3, 5}},
+		{2, 3 * size, []int{2, 4, 5}},
+		{0, 1, []int{1, 2, 3, 4, 5}}, // chunks contain at least one message
+	}
+	for _, test := range tests {
+		limits := batchLimits{items: test.items, bytes: test.bytes}
+		var ends []int
+		for start := 0; start < len(msgs); start = ends[len(ends)-1] {
+			ends = append(ends, limits.chunkEnd(msgs, start))
+		}
+		if !reflect.DeepEqual(ends, test.want) {
+			t.Errorf("limits (%d, %d): wrong chunks %v, want %v", test.items, test.bytes, ends, test.want)
 		}
 	}
 }
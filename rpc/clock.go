@@ -0,0 +1,50 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "github.com/ethereum/go-ethereum/common/mclock"
+
+// SetClock sets the clock used by the server for timers and expiry. It drives request
+// timeouts and the skipping of batch items which cannot finish in time, notification
+// rate limits, websocket keepalive pings and the expiry of cached responses and detached
+// sessions. Tests can pass an *mclock.Simulated to exercise these without waiting. By
+// default, and when clock is nil, the server uses the system clock with timers kept on a
+// timing wheel of millisecond resolution, which makes arming and stopping per-request
+// timers cheap.
+//
+// Context deadlines, network deadlines and timestamps reported to observers always use
+// the system clock. Methods which look at the deadline of their context therefore do
+// not observe simulated time.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetClock(clock mclock.Clock) {
+	if clock == nil {
+		clock = newTimerWheel(mclock.System{}, wheelTick)
+	}
+	s.clock = clock
+}
+
+// WithClock sets the clock used by the client for timers and expiry. It drives retry
+// backoff, websocket keepalive pings and the expiry of cached responses. Tests can pass
+// an *mclock.Simulated to exercise these without waiting. Context deadlines always use
+// the system clock.
+func WithClock(clock mclock.Clock) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.clock = clock
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestServerClockCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	clock := new(mclock.Simulated)
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(cacheTestService)
+	server.RegisterName("cache", svc)
+	server.SetClock(clock)
+	server.SetResponseCache(16)
+	client := DialInProc(server)
+	defer client.Close()
+
+	client.Call(nil, "cache_block", 1, false)
+	client.Call(nil, "cache_block", 1, false)
+	if calls := svc.calls.Load(); calls != 1 {
+		t.Fatalf("method called %d times before expiry, want 1", calls)
+	}
+	clock.Run(time.Minute)
+	client.Call(nil, "cache_block", 1, false)
+	if calls := svc.calls.Load(); calls != 2 {
+		t.Fatalf("method called %d times after expiry, want 2", calls)
+	}
+}
+
+func TestClientClockCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(cacheTestService)
+	server.RegisterName("cache", svc)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	clock := new(mclock.Simulated)
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithResponseCache(16), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	client.Call(nil, "cache_block", 1, false)
+	clock.Run(time.Minute - time.Second)
+	client.Call(nil, "cache_block", 1, false)
+	if calls := svc.calls.Load(); calls != 1 {
+		t.Fatalf("method called %d times before expiry, want 1", calls)
+	}
+	clock.Run(time.Second)
+	client.Call(nil, "cache_block", 1, false)
+	if calls := svc.calls.Load(); calls != 2 {
+		t.Fatalf("method called %d times after expiry, want 2", calls)
+	}
+}
+
+func TestServerClockRateLimit(t *testing.T) {
+	t.Parallel()
+
+	clock := new(mclock.Simulated)
+	server := newTestServer()
+	defer server.Stop()
+	server.SetClock(clock)
+	client := DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(ctx, "nftest", ch, "rateLimitedSubscription", 10, true)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+
+	if v := <-ch; v != 1 {
+		t.Fatalf("wrong first notification %d", v)
+	}
+	// The remaining values are sent when the simulated clock reaches the next slot.
+	clock.WaitForTimers(1)
+	select {
+	case v := <-ch:
+		t.Fatalf("notification %d sent before rate limit allows it", v)
+	default:
+	}
+	clock.Run(100 * time.Millisecond)
+	select {
+	case v := <-ch:
+		if v != 54 {
+			t.Fatalf("wrong merged notification %d", v)
+		}
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for merged notification")
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// Coalescing configures the deduplication of identical concurrent calls, see
+// Server.SetCoalescing.
+type Coalescing struct {
+	// Methods lists the methods whose calls are coalesced. A name ending in '*' matches
+	// all methods with the given prefix, e.g. "eth_get*". Only methods whose result
+	// depends solely on their parameters should be listed: coalesced calls receive the
+	// result of another client's call.
+	Methods []string
+
+	// Exclude lists methods matched by Methods which must not be coalesced.
+	Exclude []string
+
+	// Window is the maximum time after the start of a call during which identical calls
+	// join it. Calls arriving later execute the method again. Zero means calls join for
+	// as long as the first call is running.
+	Window time.Duration
+}
+
+// SetCoalescing enables in-flight deduplication of calls. When a call arrives while an
+// identical call, i.e. one with the same method and parameters, is being executed, it
+// waits for that call and is answered with its result instead of executing the method
+// again. This helps when many clients request the same data at once, e.g. the latest
+// block after it was announced.
+//
+// Streamed results, calls of services created by a ServiceFactory, paginated calls and
+// dry runs are never coalesced. If the first call fails because its client went away,
+// the waiting calls execute the method themselves. Passing a config without methods
+// disables coalescing.
+func (s *Server) SetCoalescing(cfg Coalescing) {
+	var co *coalescer
+	if len(cfg.Methods) > 0 {
+		co = &coalescer{cfg: cfg, clock: s.clock, flights: make(map[flightKey]*flight)}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.coalescing = co })
+}
+
+// coalescer tracks the calls in flight of coalesced methods.
+type coalescer struct {
+	cfg   Coalescing
+	clock mclock.Clock
+
+	mu      sync.Mutex
+	flights map[flightKey]*flight
+}
+
+// flightKey identifies identical calls. Calls resolving to different callbacks, e.g.
+// because clients selected different API versions, are never identical.
+type flightKey struct {
+	callb  *callback
+	params string
+}
+
+// flight is a call in flight.
+type flight struct {
+	start  mclock.AbsTime
+	done   chan struct{}
+	result *jsonrpcMessage // answer used by the joined calls, nil if it can't be shared
+}
+
+// applies reports whether calls of method are coalesced.
+func (co *coalescer) applies(method string) bool {
+	return co != nil && matchMethod(co.cfg.Methods, method) && !matchMethod(co.cfg.Exclude, method)
+}
+
+// join returns the flight of an identical call, or starts a new flight if there is
+// none. It reports whether the caller leads the returned flight.
+func (co *coalescer) join(key flightKey) (*flight, bool) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	now := co.clock.Now()
+	if f, ok := co.flights[key]; ok && (co.cfg.Window <= 0 || now.Sub(f.start) <= co.cfg.Window) {
+		return f, false
+	}
+	f := &flight{start: now, done: make(chan struct{})}
+	co.flights[key] = f
+	return f, true
+}
+
+// finish ends a flight led by a call with the given context and answer. The answer is
+// nil if the call panicked.
+func (co *coalescer) finish(key flightKey, f *flight, ctx context.Context, answer *jsonrpcMessage) {
+	co.mu.Lock()
+	if co.flights[key] == f {
+		delete(co.flights, key)
+	}
+	co.mu.Unlock()
+
+	if answer != nil && answer.stream == nil && (answer.Error == nil || ctx.Err() == nil) {
+		f.result = &jsonrpcMessage{Result: answer.Result, cacheTTL: answer.cacheTTL}
+		if answer.Error != nil {
+			e := *answer.Error
+			f.result.Error = &e
+		}
+	}
+	close(f.done)
+}
+
+// wait waits for the flight to end and returns the answer to msg. It returns false if
+// the result of the flight can't be used, so the call must be executed.
+func (f *flight) wait(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, bool) {
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return msg.errorResponse(ctx.Err()), true
+	}
+	if f.result == nil {
+		return nil, false
+	}
+	resp := &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: f.result.Result}
+	if f.result.Error != nil {
+		e := *f.result.Error
+		resp.Error = &e
+	}
+	if ttl := f.result.cacheTTL; ttl > 0 {
+		resp.setCacheTTL(ttl)
+	}
+	coalescedCallCounter.Inc(1)
+	return resp, true
+}
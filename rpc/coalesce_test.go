@@ -0,0 +1,139 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+type coalesceTestService struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *coalesceTestService) Get(ctx context.Context, key string) (string, error) {
+	if s.calls.Add(1) == 1 {
+		close(s.started)
+	}
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return "value of " + key, nil
+}
+
+func TestCoalescing(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := &coalesceTestService{started: make(chan struct{}), release: make(chan struct{})}
+	if err := server.RegisterName("coal", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetCoalescing(Coalescing{Methods: []string{"coal_*"}})
+	client := DialInProc(server)
+	defer client.Close()
+
+	const n = 10
+	var (
+		wg      sync.WaitGroup
+		results = make([]string, n)
+		errs    = make([]error, n)
+	)
+	call := func(i int) {
+		defer wg.Done()
+		errs[i] = client.Call(&results[i], "coal_get", "a")
+	}
+	wg.Add(1)
+	go call(0)
+	<-service.started
+	for i := 1; i < n; i++ {
+		wg.Add(1)
+		go call(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(service.release)
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil || results[i] != "value of a" {
+			t.Fatalf("call %d: result %q, err %v", i, results[i], errs[i])
+		}
+	}
+	if calls := service.calls.Load(); calls >= n {
+		t.Fatalf("method executed %d times for %d calls", calls, n)
+	}
+
+	// Calls with other parameters are not coalesced.
+	var result string
+	if err := client.Call(&result, "coal_get", "b"); err != nil || result != "value of b" {
+		t.Fatalf("wrong result %q, err %v", result, err)
+	}
+}
+
+func TestCoalescerWindow(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		co    = &coalescer{cfg: Coalescing{Methods: []string{"*"}, Window: time.Second}, clock: clock, flights: make(map[flightKey]*flight)}
+		key   = flightKey{params: "test_echo\x000:1"}
+		msg   = &jsonrpcMessage{Version: vsn, ID: []byte("7"), Method: "test_echo"}
+	)
+	first, leader := co.join(key)
+	if !leader {
+		t.Fatal("first call doesn't lead")
+	}
+	joined, leader := co.join(key)
+	if leader || joined != first {
+		t.Fatal("second call didn't join")
+	}
+	clock.Run(2 * time.Second)
+	second, leader := co.join(key)
+	if !leader || second == first {
+		t.Fatal("call after window joined")
+	}
+
+	co.finish(key, first, context.Background(), &jsonrpcMessage{Result: []byte(`"ok"`)})
+	resp, ok := first.wait(context.Background(), msg)
+	if !ok || string(resp.Result) != `"ok"` || string(resp.ID) != "7" {
+		t.Fatalf("wrong response %+v", resp)
+	}
+	// Finishing the first flight doesn't remove the second one.
+	if co.flights[key] != second {
+		t.Fatal("second flight was removed")
+	}
+
+	// Errors caused by the cancellation of the leading call are not shared.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	co.finish(key, second, ctx, msg.errorResponse(errors.New("canceled")))
+	if _, ok := second.wait(context.Background(), msg); ok {
+		t.Fatal("error of canceled call was shared")
+	}
+	if len(co.flights) != 0 {
+		t.Fatal("flights not removed")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTP response headers reporting compute unit usage, see Server.SetComputeUnits.
+const (
+	ComputeUnitsCostHeader      = "X-Compute-Units-Cost"
+	ComputeUnitsRemainingHeader = "X-Compute-Units-Remaining"
+	ComputeUnitsLimitHeader     = "X-Compute-Units-Limit"
+)
+
+// ComputeUnitBudget limits the compute units (CU) a client can consume within a time
+// window. Unlike Quota, which counts calls, calls are weighted by the cost of the method.
+type ComputeUnitBudget struct {
+	Limit  int64         // units allowed per window
+	Window time.Duration // length of the window
+
+	// Costs maps method names to their cost in units. Methods not listed cost
+	// DefaultCost, which is one unit if not set. Methods with a cost of zero are free,
+	// but still report the usage.
+	Costs       map[string]int64
+	DefaultCost int64
+
+	// Key returns the key under which units are counted, or "" to exempt the call. By
+	// default, units are counted per remote IP address, and calls from transports
+	// without IP address, such as IPC, are not limited.
+	Key func(peer PeerInfo, method string) string
+}
+
+// ComputeUnitUsage is the compute unit accounting of a call, reported to the client
+// with the response.
+type ComputeUnitUsage struct {
+	Cost      int64 `json:"cost"`      // units charged for the call
+	Remaining int64 `json:"remaining"` // units left in the current window
+	Limit     int64 `json:"limit"`     // units allowed per window
+}
+
+// SetComputeUnits enforces a compute unit budget. The counters are kept in state, so
+// they can be shared between servers when state is backed by a shared store. Calls
+// exceeding the budget are rejected with the quota exceeded error (-32007).
+//
+// Every response reports the cost of the call and the remaining budget in the "cu"
+// member of its "ext" member. HTTP responses also carry them in the
+// ComputeUnitsCostHeader, ComputeUnitsRemainingHeader and ComputeUnitsLimitHeader
+// headers, which for batches hold the total cost and the lowest remaining budget. This
+// lets clients throttle themselves before reaching the limit, see WithComputeUnits.
+// If the state fails, calls are allowed without reporting usage.
+//
+// Passing a nil state disables the budget.
+func (s *Server) SetComputeUnits(state State, budget ComputeUnitBudget) {
+	var cu *computeUnits
+	if state != nil {
+		if budget.Key == nil {
+			budget.Key = quotaKeyByIP
+		}
+		if budget.DefaultCost == 0 {
+			budget.DefaultCost = 1
+		}
+		cu = &computeUnits{state: state, budget: budget}
+	}
+	s.services.updateConfig(func(cfg *registryConfig) { cfg.computeUnits = cu })
+}
+
+// WithComputeUnits sets a callback which receives the compute unit usage reported by
+// the server with responses to calls and batch calls, see Server.SetComputeUnits.
+func WithComputeUnits(fn func(method string, usage ComputeUnitUsage)) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.computeUnitsFn = fn
+	})
+}
+
+// computeUnits implements ComputeUnitBudget.
+type computeUnits struct {
+	state  State
+	budget ComputeUnitBudget
+}
+
+// charge accounts for a call of method by peer. It returns the usage to report, which is
+// nil for exempt calls, and an error if the budget is exhausted.
+func (cu *computeUnits) charge(peer PeerInfo, method string) (*ComputeUnitUsage, error) {
+	key := cu.budget.Key(peer, method)
+	if key == "" {
+		return nil, nil
+	}
+	cost, ok := cu.budget.Costs[method]
+	if !ok {
+		cost = cu.budget.DefaultCost
+	}
+	var (
+		used int64
+		err  error
+	)
+	key = "cu/" + key
+	if cost > 0 {
+		used, err = cu.state.Add(key, cost, cu.budget.Window)
+	} else {
+		cost = 0
+		used, err = cu.state.Get(key)
+	}
+	if err != nil {
+		return nil, nil
+	}
+	usage := &ComputeUnitUsage{Cost: cost, Remaining: max(cu.budget.Limit-used, 0), Limit: cu.budget.Limit}
+	if used > cu.budget.Limit {
+		return usage, &quotaExceededError{limit: cu.budget.Limit, window: cu.budget.Window}
+	}
+	return usage, nil
+}
+
+// chargeComputeUnits charges a call against the compute unit budget, if there is one.
+func (r *registryConfig) chargeComputeUnits(peer PeerInfo, method string) (*ComputeUnitUsage, error) {
+	if r.computeUnits == nil {
+		return nil, nil
+	}
+	return r.computeUnits.charge(peer, method)
+}
+
+// setComputeUnits stores the compute unit usage of the call in the response.
+func (msg *jsonrpcMessage) setComputeUnits(usage *ComputeUnitUsage) {
+	msg.computeUnits = usage
+	msg.updateResponseExt(func(ext *responseExt) { ext.ComputeUnits = usage })
+}
+
+// setComputeUnitHeaders reports the compute unit usage of the responses in v, a
+// response or batch response, in HTTP headers.
+func setComputeUnitHeaders(h http.Header, v any) {
+	var msgs []*jsonrpcMessage
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		msgs = []*jsonrpcMessage{v}
+	case []*jsonrpcMessage:
+		msgs = v
+	}
+	var total *ComputeUnitUsage
+	for _, msg := range msgs {
+		if msg == nil || msg.computeUnits == nil {
+			continue
+		}
+		if total == nil {
+			u := *msg.computeUnits
+			total = &u
+			continue
+		}
+		total.Cost += msg.computeUnits.Cost
+		total.Remaining = min(total.Remaining, msg.computeUnits.Remaining)
+	}
+	if total != nil {
+		h.Set(ComputeUnitsCostHeader, strconv.FormatInt(total.Cost, 10))
+		h.Set(ComputeUnitsRemainingHeader, strconv.FormatInt(total.Remaining, 10))
+		h.Set(ComputeUnitsLimitHeader, strconv.FormatInt(total.Limit, 10))
+	}
+}
+
+// reportComputeUnits passes the compute unit usage of resp to the configured callback.
+func (c *Client) reportComputeUnits(method string, resp *jsonrpcMessage) {
+	if c.computeUnitsFn == nil {
+		return
+	}
+	if usage := resp.responseExt().ComputeUnits; usage != nil {
+		c.computeUnitsFn(method, *usage)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeUnits(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetComputeUnits(NewMemoryState(), ComputeUnitBudget{
+		Limit:  10,
+		Window: time.Hour,
+		Costs:  map[string]int64{"test_echo": 4, "rpc_modules": 0},
+	})
+	httpsrv := httptest.NewServer(srv)
+	defer httpsrv.Close()
+
+	type report struct {
+		method string
+		usage  ComputeUnitUsage
+	}
+	var reports []report
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithComputeUnits(func(method string, usage ComputeUnitUsage) {
+		reports = append(reports, report{method, usage})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "rpc_modules"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	// The budget is exhausted.
+	wantCallError(t, client.Call(nil, "test_echo", "x", 1), errcodeQuotaExceeded)
+
+	want := []report{
+		{"test_echo", ComputeUnitUsage{Cost: 4, Remaining: 6, Limit: 10}},
+		{"test_noArgsRets", ComputeUnitUsage{Cost: 1, Remaining: 5, Limit: 10}},
+		{"rpc_modules", ComputeUnitUsage{Cost: 0, Remaining: 5, Limit: 10}},
+		{"test_echo", ComputeUnitUsage{Cost: 4, Remaining: 1, Limit: 10}},
+		{"test_echo", ComputeUnitUsage{Cost: 4, Remaining: 0, Limit: 10}},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("got %d reports, want %d: %v", len(reports), len(want), reports)
+	}
+	for i := range want {
+		if reports[i] != want[i] {
+			t.Errorf("report %d: got %+v, want %+v", i, reports[i], want[i])
+		}
+	}
+}
+
+func TestComputeUnitsHTTPHeaders(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetComputeUnits(NewMemoryState(), ComputeUnitBudget{Limit: 100, Window: time.Hour, DefaultCost: 3})
+	httpsrv := httptest.NewServer(srv)
+	defer httpsrv.Close()
+
+	body := `[{"jsonrpc":"2.0","id":1,"method":"test_noArgsRets"},{"jsonrpc":"2.0","id":2,"method":"test_noArgsRets"}]`
+	resp, err := http.Post(httpsrv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	for header, want := range map[string]string{
+		ComputeUnitsCostHeader:      "6",
+		ComputeUnitsRemainingHeader: "94",
+		ComputeUnitsLimitHeader:     "100",
+	} {
+		if have := resp.Header.Get(header); have != want {
+			t.Errorf("header %s: got %q, want %q", header, have, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var errQueuedConnClosed = errors.New("connection closed while call was queued")
+
+// ConcurrencyLimits bounds the number of method calls processed at the same time, see
+// SetConcurrencyLimits. Zero values mean no limit.
+type ConcurrencyLimits struct {
+	MaxConcurrentCalls int // calls running across all connections
+	MaxCallsPerConn    int // calls running on a single connection
+
+	// QueueDepth is the number of calls waiting for a slot across all connections.
+	// Calls arriving while the queue is full are rejected.
+	QueueDepth int
+}
+
+// ConcurrencyStats describes the utilization of the concurrency limits.
+type ConcurrencyStats struct {
+	Limits   ConcurrencyLimits `json:"limits"`
+	Running  int               `json:"running"`  // calls being processed
+	Queued   int               `json:"queued"`   // calls waiting for a slot
+	Rejected uint64            `json:"rejected"` // calls rejected because the queue was full
+}
+
+// SetConcurrencyLimits limits the number of calls processed at the same time, globally
+// and per connection. Calls over the limits wait in a queue, where calls of other
+// connections can overtake the calls of a connection which has reached its own limit,
+// so heavy callers can't starve other clients. When the queue is full, calls fail with
+// the "limit exceeded" error (-32005). Queued calls are abandoned when they time out or
+// their connection closes. Calls of the rpc namespace, including rpc_concurrency which
+// reports the utilization, are not limited.
+func (s *Server) SetConcurrencyLimits(limits ConcurrencyLimits) {
+	s.services.concurrency.setLimits(limits)
+}
+
+// ConcurrencyStats returns the utilization of the concurrency limits.
+func (s *Server) ConcurrencyStats() ConcurrencyStats {
+	return s.services.concurrency.stats()
+}
+
+// Concurrency returns the utilization of the concurrency limits of the server.
+func (s *RPCService) Concurrency() ConcurrencyStats {
+	return s.server.ConcurrencyStats()
+}
+
+// concurrencyLimiter implements ConcurrencyLimits.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	limits   ConcurrencyLimits
+	running  int
+	queue    []*callWaiter
+	rejected uint64
+}
+
+// connCalls counts the running calls of a connection.
+type connCalls struct {
+	running  int           // protected by concurrencyLimiter.mu
+	quit     chan struct{} // closed when the connection closes
+	quitOnce sync.Once
+}
+
+func newConnCalls() *connCalls {
+	return &connCalls{quit: make(chan struct{})}
+}
+
+// stop abandons the queued calls of the connection. Running calls are not affected.
+func (c *connCalls) stop() {
+	c.quitOnce.Do(func() { close(c.quit) })
+}
+
+type callWaiter struct {
+	conn    *connCalls
+	granted chan struct{}
+}
+
+func (l *concurrencyLimiter) setLimits(limits ConcurrencyLimits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+	l.grant()
+}
+
+func (l *concurrencyLimiter) stats() ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ConcurrencyStats{Limits: l.limits, Running: l.running, Queued: len(l.queue), Rejected: l.rejected}
+}
+
+// available reports whether a call of conn can start.
+func (l *concurrencyLimiter) available(conn *connCalls) bool {
+	return (l.limits.MaxConcurrentCalls <= 0 || l.running < l.limits.MaxConcurrentCalls) &&
+		(l.limits.MaxCallsPerConn <= 0 || conn.running < l.limits.MaxCallsPerConn)
+}
+
+func (l *concurrencyLimiter) start(conn *connCalls) {
+	l.running++
+	conn.running++
+}
+
+// acquire waits for a slot for a call of conn, until ctx is canceled or the connection
+// is closed. The returned function must be called when the call has finished.
+func (l *concurrencyLimiter) acquire(ctx context.Context, conn *connCalls) (func(), error) {
+	l.mu.Lock()
+	if l.available(conn) {
+		l.start(conn)
+		l.mu.Unlock()
+		return func() { l.release(conn) }, nil
+	}
+	if len(l.queue) >= l.limits.QueueDepth {
+		l.rejected++
+		l.mu.Unlock()
+		return nil, &limitExceededError{}
+	}
+	w := &callWaiter{conn: conn, granted: make(chan struct{})}
+	l.queue = append(l.queue, w)
+	l.mu.Unlock()
+
+	var err error
+	select {
+	case <-w.granted:
+		return func() { l.release(conn) }, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-conn.quit:
+		err = errQueuedConnClosed
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.granted:
+		// The slot was granted concurrently, hand it on.
+		l.finish(conn)
+	default:
+		l.remove(w)
+	}
+	return nil, err
+}
+
+func (l *concurrencyLimiter) release(conn *connCalls) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.finish(conn)
+}
+
+func (l *concurrencyLimiter) finish(conn *connCalls) {
+	l.running--
+	conn.running--
+	l.grant()
+}
+
+// grant starts queued calls in arrival order, skipping calls whose connection is at its
+// limit.
+func (l *concurrencyLimiter) grant() {
+	for i := 0; i < len(l.queue); {
+		w := l.queue[i]
+		if !l.available(w.conn) {
+			if l.limits.MaxConcurrentCalls > 0 && l.running >= l.limits.MaxConcurrentCalls {
+				return
+			}
+			i++
+			continue
+		}
+		l.start(w.conn)
+		close(w.granted)
+		l.queue = append(l.queue[:i:i], l.queue[i+1:]...)
+	}
+}
+
+func (l *concurrencyLimiter) remove(w *callWaiter) {
+	for i, qw := range l.queue {
+		if qw == w {
+			l.queue = append(l.queue[:i:i], l.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// acquireCallSlot applies the concurrency limits to a call.
+func (h *handler) acquireCallSlot(ctx context.Context, msg *jsonrpcMessage) (func(), error) {
+	if strings.HasPrefix(msg.Method, MetadataApi+serviceMethodSeparator) {
+		return func() {}, nil
+	}
+	return h.reg.concurrency.acquire(ctx, h.running)
+}
+
+type limitExceededError struct{}
+
+func (e *limitExceededError) Error() string { return errMsgLimitExceeded }
+
+func (e *limitExceededError) ErrorCode() int { return errcodeLimitExceeded }
@@ -0,0 +1,190 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateService has a method which blocks until its gate is opened.
+type gateService struct {
+	mu    sync.Mutex
+	gates map[string]chan struct{}
+}
+
+func (s *gateService) gate(name string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gates == nil {
+		s.gates = make(map[string]chan struct{})
+	}
+	if s.gates[name] == nil {
+		s.gates[name] = make(chan struct{})
+	}
+	return s.gates[name]
+}
+
+func (s *gateService) open(name string) { close(s.gate(name)) }
+
+func (s *gateService) Wait(ctx context.Context, name string) error {
+	select {
+	case <-s.gate(name):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func waitConcurrency(t *testing.T, server *Server, running, queued int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := server.ConcurrencyStats()
+		if stats.Running == running && stats.Queued == queued {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %d running and %d queued calls, have %+v", running, queued, stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimitQueue(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	gates := new(gateService)
+	server.RegisterName("gate", gates)
+	server.SetConcurrencyLimits(ConcurrencyLimits{MaxConcurrentCalls: 1, QueueDepth: 1})
+	c1, c2, c3 := DialInProc(server), DialInProc(server), DialInProc(server)
+	defer c1.Close()
+	defer c2.Close()
+	defer c3.Close()
+
+	errc := make(chan error, 2)
+	go func() { errc <- c1.Call(nil, "gate_wait", "a") }()
+	waitConcurrency(t, server, 1, 0)
+	go func() { errc <- c2.Call(nil, "gate_wait", "a") }()
+	waitConcurrency(t, server, 1, 1)
+
+	// The queue is full.
+	wantCallError(t, c3.Call(nil, "test_noArgsRets"), errcodeLimitExceeded)
+
+	// Introspection is not limited.
+	var stats ConcurrencyStats
+	if err := c3.Call(&stats, "rpc_concurrency"); err != nil {
+		t.Fatal(err)
+	}
+	want := ConcurrencyStats{Limits: ConcurrencyLimits{MaxConcurrentCalls: 1, QueueDepth: 1}, Running: 1, Queued: 1, Rejected: 1}
+	if stats != want {
+		t.Fatalf("wrong stats %+v, want %+v", stats, want)
+	}
+
+	gates.open("a")
+	for range 2 {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitConcurrency(t, server, 0, 0)
+}
+
+func TestConcurrencyLimitPerConn(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	gates := new(gateService)
+	server.RegisterName("gate", gates)
+	server.SetConcurrencyLimits(ConcurrencyLimits{MaxConcurrentCalls: 2, MaxCallsPerConn: 1, QueueDepth: 10})
+	heavy, other, light := DialInProc(server), DialInProc(server), DialInProc(server)
+	defer heavy.Close()
+	defer other.Close()
+	defer light.Close()
+
+	heavyErr := make(chan error, 2)
+	go func() { heavyErr <- heavy.Call(nil, "gate_wait", "heavy") }()
+	waitConcurrency(t, server, 1, 0)
+	otherErr := make(chan error, 1)
+	go func() { otherErr <- other.Call(nil, "gate_wait", "other") }()
+	waitConcurrency(t, server, 2, 0)
+	go func() { heavyErr <- heavy.Call(nil, "gate_wait", "heavy") }()
+	waitConcurrency(t, server, 2, 1)
+	lightErr := make(chan error, 1)
+	go func() { lightErr <- light.Call(nil, "test_noArgsRets") }()
+	waitConcurrency(t, server, 2, 2)
+
+	// When the other call finishes, the light call overtakes the queued heavy call
+	// because the heavy connection is at its limit.
+	gates.open("other")
+	if err := <-otherErr; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-lightErr:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("light call not processed")
+	}
+	waitConcurrency(t, server, 1, 1)
+
+	gates.open("heavy")
+	for range 2 {
+		if err := <-heavyErr; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrencyLimitConnClose(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	gates := new(gateService)
+	server.RegisterName("gate", gates)
+	server.SetConcurrencyLimits(ConcurrencyLimits{MaxConcurrentCalls: 1, QueueDepth: 1})
+	c1, c2, c3 := DialInProc(server), DialInProc(server), DialInProc(server)
+	defer c1.Close()
+	defer c3.Close()
+
+	errc := make(chan error, 1)
+	go func() { errc <- c1.Call(nil, "gate_wait", "a") }()
+	waitConcurrency(t, server, 1, 0)
+
+	// A queued call is abandoned when its connection closes.
+	go c2.Call(nil, "test_noArgsRets")
+	waitConcurrency(t, server, 1, 1)
+	c2.Close()
+	waitConcurrency(t, server, 1, 0)
+
+	gates.open("a")
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if err := c3.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	waitConcurrency(t, server, 0, 0)
+}
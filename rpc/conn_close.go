@@ -0,0 +1,161 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+// ConnID identifies a connection served by a Server. IDs are unique within the server
+// and start at one.
+type ConnID uint64
+
+type connIDKey struct{}
+
+// ConnIDFromContext returns the id of the connection on which the current call was
+// received. It returns zero when the context doesn't belong to a call served by the RPC
+// server.
+func ConnIDFromContext(ctx context.Context) ConnID {
+	id, _ := ctx.Value(connIDKey{}).(ConnID)
+	return id
+}
+
+// ConnectionCloseListener can be implemented by service receivers to release state kept
+// per connection. When a receiver implementing it is registered, OnConnectionClosed is
+// not exposed as an RPC method. Instead, it is called whenever a connection served by the
+// server terminates, after all calls on the connection have returned. Note that for HTTP,
+// every request is a connection of its own.
+type ConnectionCloseListener interface {
+	OnConnectionClosed(id ConnID)
+}
+
+// OnConnectionClosed registers fn to be called whenever a connection terminates, like
+// ConnectionCloseListener.
+func (s *Server) OnConnectionClosed(fn func(id ConnID)) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+	s.services.onClose = append(s.services.onClose, fn)
+}
+
+// connectionClosed runs the connection close listeners.
+func (r *serviceRegistry) connectionClosed(id ConnID) {
+	r.mu.Lock()
+	listeners := r.onClose
+	r.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(id)
+	}
+}
+
+type connCloserKey struct{}
+
+// connCloser holds the close request made by a call using CloseConnection.
+type connCloser struct {
+	disabled bool // set for single requests, e.g. over HTTP
+
+	mu        sync.Mutex
+	requested bool
+	code      int
+	reason    string
+}
+
+// CloseConnection makes the server close the connection on which the current call was
+// received, e.g. because the credentials of the client were revoked or the client should
+// reconnect to another endpoint. It can be called by methods and by middleware.
+//
+// The connection is closed gracefully after the response to the current call, or to the
+// batch containing it, has been written. Calls still running on the connection are
+// canceled. Websocket connections are closed with a close frame carrying the given
+// status code and reason, see RFC 6455 section 7.4. Application-defined codes are in
+// the range 4000-4999. Other stream connections, like IPC, are closed without status.
+//
+// An error is returned for HTTP requests, which don't belong to a connection the handler
+// can close, and if code or reason are not valid in a websocket close frame.
+func CloseConnection(ctx context.Context, code int, reason string) error {
+	closer, _ := ctx.Value(connCloserKey{}).(*connCloser)
+	if closer == nil || closer.disabled {
+		return errors.New("context does not belong to a closable connection")
+	}
+	if code < websocket.CloseNormalClosure || code > 4999 {
+		return errors.New("invalid close code")
+	}
+	if len(reason) > maxCloseReason {
+		return errors.New("close reason too long")
+	}
+	closer.mu.Lock()
+	defer closer.mu.Unlock()
+	if !closer.requested {
+		closer.requested, closer.code, closer.reason = true, code, reason
+	}
+	return nil
+}
+
+// maxCloseReason is the maximum length of a websocket close reason. Control frames
+// can carry 125 bytes, two of which are used by the status code.
+const maxCloseReason = 123
+
+// take returns the close request, if any.
+func (c *connCloser) take() (code int, reason string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.code, c.reason, c.requested
+}
+
+// closeIfRequested closes the connection if a call has asked for it using
+// CloseConnection. It is called after a response has been written.
+func (h *handler) closeIfRequested() {
+	code, reason, ok := h.closer.take()
+	if !ok {
+		return
+	}
+	h.log.Debug("Closing RPC connection on request of handler", "code", code, "reason", reason)
+	h.closeConn(code, reason)
+}
+
+// closeConn closes the connection. Websocket connections are closed with the given
+// status, other connections without.
+func (h *handler) closeConn(code int, reason string) {
+	switch conn := h.conn.(type) {
+	case interface{ closeWithStatus(int, string) }:
+		conn.closeWithStatus(code, reason)
+	case interface{ close() }:
+		conn.close()
+	}
+}
+
+// closeWithStatus writes pending messages and a close frame, then closes the
+// connection.
+func (wc *websocketCodec) closeWithStatus(code int, reason string) {
+	if wc.coalescer != nil {
+		for msg := wc.coalescer.take(); msg != nil; msg = wc.coalescer.take() {
+			if err := wc.writeNow(context.Background(), msg, false); err != nil {
+				log.Debug("WebSocket write failed", "err", err)
+				break
+			}
+		}
+	}
+	msg := websocket.FormatCloseMessage(code, reason)
+	wc.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(drainCloseWriteTimeout))
+	wc.jsonCodec.close()
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// filterService keeps state per connection, keyed by connection id.
+type filterService struct {
+	mu      sync.Mutex
+	filters map[ConnID]int
+	closed  chan ConnID
+}
+
+func (s *filterService) NewFilter(ctx context.Context) ConnID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := ConnIDFromContext(ctx)
+	s.filters[id]++
+	return id
+}
+
+func (s *filterService) OnConnectionClosed(id ConnID) {
+	s.mu.Lock()
+	delete(s.filters, id)
+	s.mu.Unlock()
+	s.closed <- id
+}
+
+func TestConnectionCloseListener(t *testing.T) {
+	t.Parallel()
+
+	service := &filterService{filters: make(map[ConnID]int), closed: make(chan ConnID, 1)}
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("filter", service); err != nil {
+		t.Fatal(err)
+	}
+	closedFn := make(chan ConnID, 1)
+	server.OnConnectionClosed(func(id ConnID) { closedFn <- id })
+
+	c1, c2 := DialInProc(server), DialInProc(server)
+	defer c2.Close()
+	var id1, id2 ConnID
+	if err := c1.Call(&id1, "filter_newFilter"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c2.Call(&id2, "filter_newFilter"); err != nil {
+		t.Fatal(err)
+	}
+	if id1 == 0 || id1 == id2 {
+		t.Fatalf("bad connection ids %d, %d", id1, id2)
+	}
+	if err := c1.Call(nil, "filter_onConnectionClosed", id2); err == nil {
+		t.Fatal("OnConnectionClosed should not be exposed")
+	}
+
+	c1.Close()
+	for _, ch := range []chan ConnID{service.closed, closedFn} {
+		select {
+		case id := <-ch:
+			if id != id1 {
+				t.Fatalf("wrong connection closed: %d, want %d", id, id1)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("close listener not called")
+		}
+	}
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if _, ok := service.filters[id1]; ok || service.filters[id2] != 1 {
+		t.Fatalf("wrong filters after close: %v", service.filters)
+	}
+}
+
+type closingService struct{}
+
+func (closingService) Revoke(ctx context.Context) (string, error) {
+	if err := CloseConnection(ctx, 4001, "auth revoked"); err != nil {
+		return "", err
+	}
+	return "bye", nil
+}
+
+func TestCloseConnection(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("closing", closingService{})
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpsrv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"closing_revoke"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The response arrives before the close frame.
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal("can't read response:", err)
+	}
+	if want := `{"jsonrpc":"2.0","id":1,"result":"bye"}`; strings.TrimSpace(string(msg)) != want {
+		t.Fatalf("wrong response %s", msg)
+	}
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != 4001 || closeErr.Text != "auth revoked" {
+		t.Fatalf("expected close frame, got %v", err)
+	}
+}
+
+func TestCloseConnectionHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("closing", closingService{})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Call(nil, "closing_revoke"); err == nil {
+		t.Fatal("CloseConnection succeeded for HTTP request")
+	}
+}
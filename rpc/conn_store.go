@@ -0,0 +1,122 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+)
+
+// ConnStorage is a key/value store scoped to a single connection. Services can use it to
+// keep per-connection state, such as negotiated options or cursors, without maintaining
+// global maps keyed by remote address. The store is discarded when the connection is
+// closed. For HTTP, every request is a connection of its own.
+//
+// ConnStorage is safe for concurrent use.
+type ConnStorage struct {
+	mu      sync.Mutex
+	values  map[any]any
+	onClose []func()
+	closed  bool
+}
+
+type connStoreKey struct{}
+
+// ConnStore returns the connection store of the connection on which the current call was
+// received. It returns nil when the context doesn't belong to a call served by the RPC
+// server.
+func ConnStore(ctx context.Context) *ConnStorage {
+	s, _ := ctx.Value(connStoreKey{}).(*ConnStorage)
+	return s
+}
+
+// Get returns the value stored for key.
+func (s *ConnStorage) Get(key any) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set stores value for key. Keys should be of an unexported type defined by the service
+// to avoid collisions, like context keys.
+func (s *ConnStorage) Set(key, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.values == nil {
+		s.values = make(map[any]any)
+	}
+	s.values[key] = value
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise, it stores and
+// returns value. The loaded result is true if the value was loaded.
+func (s *ConnStorage) LoadOrStore(key, value any) (actual any, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.values[key]; ok {
+		return v, true
+	}
+	if !s.closed {
+		if s.values == nil {
+			s.values = make(map[any]any)
+		}
+		s.values[key] = value
+	}
+	return value, false
+}
+
+// Delete removes the value stored for key.
+func (s *ConnStorage) Delete(key any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// OnClose registers fn to be called when the connection is closed. If the connection is
+// already closed, fn is called immediately.
+func (s *ConnStorage) OnClose(fn func()) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		fn()
+		return
+	}
+	s.onClose = append(s.onClose, fn)
+	s.mu.Unlock()
+}
+
+// close discards all values and runs the OnClose callbacks.
+func (s *ConnStorage) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.values = nil
+	callbacks := s.onClose
+	s.onClose = nil
+	s.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type connStoreCounterKey struct{}
+
+type connStoreService struct {
+	closed chan struct{}
+}
+
+// Incr increments and returns a per-connection counter.
+func (s *connStoreService) Incr(ctx context.Context) int {
+	store := ConnStore(ctx)
+	v, loaded := store.LoadOrStore(connStoreCounterKey{}, new(int))
+	if !loaded {
+		store.OnClose(func() { s.closed <- struct{}{} })
+	}
+	counter := v.(*int)
+	*counter++
+	return *counter
+}
+
+func TestConnStore(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	service := &connStoreService{closed: make(chan struct{}, 2)}
+	if err := server.RegisterName("store", service); err != nil {
+		t.Fatal(err)
+	}
+
+	client1 := DialInProc(server)
+	client2 := DialInProc(server)
+	defer client2.Close()
+
+	call := func(c *Client) int {
+		var n int
+		if err := c.Call(&n, "store_incr"); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	call(client1)
+	call(client1)
+	if n := call(client1); n != 3 {
+		t.Fatalf("client1: got counter %d, want 3", n)
+	}
+	if n := call(client2); n != 1 {
+		t.Fatalf("client2: got counter %d, want 1", n)
+	}
+
+	client1.Close()
+	select {
+	case <-service.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnClose callback not called after connection closed")
+	}
+	if n := call(client2); n != 2 {
+		t.Fatalf("client2: got counter %d, want 2", n)
+	}
+}
+
+func TestConnStoreClosed(t *testing.T) {
+	t.Parallel()
+
+	var s ConnStorage
+	s.Set("a", 1)
+	if v, ok := s.Get("a"); !ok || v != 1 {
+		t.Fatalf("wrong value %v", v)
+	}
+	s.close()
+	if _, ok := s.Get("a"); ok {
+		t.Fatal("value retained after close")
+	}
+	s.Set("b", 2)
+	if _, ok := s.Get("b"); ok {
+		t.Fatal("value stored after close")
+	}
+	called := false
+	s.OnClose(func() { called = true })
+	if !called {
+		t.Fatal("OnClose callback not called on closed store")
+	}
+	if ConnStore(context.Background()) != nil {
+		t.Fatal("ConnStore returned store for unrelated context")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+)
+
+// ConsistencyTokenHeader is the HTTP request header carrying the consistency token of a
+// client. Load balancers can use it to route requests to backends which have reached
+// the position in the token.
+const ConsistencyTokenHeader = "X-Rpc-Consistency"
+
+// ConsistencySource returns the current position of a backend, for example the number
+// of the latest block it has processed. Positions must not decrease.
+type ConsistencySource func() uint64
+
+type consistencyConfig struct {
+	source ConsistencySource
+	writes map[string]bool
+}
+
+type consistencyTokenKey struct{}
+
+// SetConsistencyTokens enables read-your-writes consistency tokens. Successful responses
+// to the given write methods carry the current position of the server as a token in
+// their "ext" member. Clients can include the highest token they have seen in
+// subsequent requests, see WithConsistencyTokens. Requests carrying a token ahead of the
+// server's position fail with a retryable error, so that the client can retry on a
+// backend which has caught up.
+//
+// Passing a nil source disables consistency tokens.
+func (s *Server) SetConsistencyTokens(source ConsistencySource, writeMethods ...string) {
+	var cfg *consistencyConfig
+	if source != nil {
+		cfg = &consistencyConfig{source: source, writes: make(map[string]bool)}
+		for _, m := range writeMethods {
+			cfg.writes[m] = true
+		}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.consistency = cfg })
+}
+
+// behindConsistencyError is returned for requests carrying a consistency token which
+// the server has not reached yet.
+type behindConsistencyError struct {
+	required, current uint64
+}
+
+func (e *behindConsistencyError) ErrorCode() int { return errcodeBehindConsistency }
+
+func (e *behindConsistencyError) Error() string { return errMsgBehindConsistency }
+
+func (e *behindConsistencyError) ErrorData() interface{} {
+	data := retryHintData(0)
+	data["required"] = e.required
+	data["current"] = e.current
+	return data
+}
+
+// checkConsistency returns an error if msg carries a consistency token ahead of the
+// server's position.
+func (c *consistencyConfig) checkConsistency(ctx context.Context, msg *jsonrpcMessage) error {
+	if c == nil {
+		return nil
+	}
+	required := msg.requestExt().Consistency
+	if fromHeader, ok := ctx.Value(consistencyTokenKey{}).(uint64); ok {
+		required = max(required, fromHeader)
+	}
+	if required == 0 {
+		return nil
+	}
+	if current := c.source(); current < required {
+		return &behindConsistencyError{required: required, current: current}
+	}
+	return nil
+}
+
+// addConsistencyToken stores the server's position in successful responses to write
+// methods.
+func (c *consistencyConfig) addConsistencyToken(msg, resp *jsonrpcMessage) {
+	if c == nil || resp == nil || resp.Error != nil || !c.writes[msg.Method] {
+		return
+	}
+	token := c.source()
+	resp.updateResponseExt(func(ext *responseExt) { ext.Consistency = token })
+}
+
+// parseConsistencyHeader returns the token in the ConsistencyTokenHeader value v.
+func parseConsistencyHeader(v string) (uint64, bool) {
+	if v == "" {
+		return 0, false
+	}
+	token, err := strconv.ParseUint(v, 10, 64)
+	return token, err == nil
+}
+
+// WithConsistencyTokens makes the client track the consistency tokens returned by the
+// server for write methods, and include the highest token seen in all subsequent
+// requests. Over HTTP, the token is also sent in the ConsistencyTokenHeader. The server
+// must have consistency tokens enabled, see Server.SetConsistencyTokens.
+func WithConsistencyTokens() ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.consistencyTokens = true
+	})
+}
+
+// consistencyTracker holds the highest consistency token seen by a client.
+type consistencyTracker struct {
+	token atomic.Uint64
+}
+
+// ConsistencyToken returns the highest consistency token received by the client, or
+// zero if consistency tokens are not enabled.
+func (c *Client) ConsistencyToken() uint64 {
+	if c.consistency == nil {
+		return 0
+	}
+	return c.consistency.token.Load()
+}
+
+// observeConsistency records the consistency token in resp.
+func (c *Client) observeConsistency(resp *jsonrpcMessage) {
+	if c.consistency == nil || len(resp.Ext) == 0 {
+		return
+	}
+	token := resp.responseExt().Consistency
+	for {
+		cur := c.consistency.token.Load()
+		if token <= cur {
+			return
+		}
+		if c.consistency.token.CompareAndSwap(cur, token) {
+			break
+		}
+	}
+	c.SetHeader(ConsistencyTokenHeader, strconv.FormatUint(token, 10))
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConsistencyTokens(t *testing.T) {
+	t.Parallel()
+
+	var head atomic.Uint64
+	head.Store(5)
+	server := newTestServer()
+	defer server.Stop()
+	server.SetConsistencyTokens(head.Load, "test_echo")
+	client := dialInProcWithOptions(server, WithConsistencyTokens())
+	defer client.Close()
+
+	// Reads do not produce tokens.
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+	if token := client.ConsistencyToken(); token != 0 {
+		t.Fatalf("token %d after read, want 0", token)
+	}
+	if err := client.Call(nil, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if token := client.ConsistencyToken(); token != 5 {
+		t.Fatalf("token %d after write, want 5", token)
+	}
+
+	// A backend behind the token rejects the read with a retryable error.
+	head.Store(3)
+	err := client.Call(nil, "test_null")
+	var dataErr DataError
+	if !errors.As(err, &dataErr) || err.(Error).ErrorCode() != errcodeBehindConsistency {
+		t.Fatalf("expected consistency error, got %v", err)
+	}
+	data := dataErr.ErrorData().(map[string]interface{})
+	if data["retryable"] != true || data["required"] != float64(5) || data["current"] != float64(3) {
+		t.Fatalf("wrong error data %v", data)
+	}
+
+	// Once the backend catches up, reads succeed again.
+	head.Store(6)
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsistencyTokensRetry(t *testing.T) {
+	t.Parallel()
+
+	var head atomic.Uint64
+	head.Store(5)
+	server := newTestServer()
+	defer server.Stop()
+	server.SetConsistencyTokens(head.Load, "test_echo")
+	client := dialInProcWithOptions(server,
+		WithConsistencyTokens(),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 10, Backoff: 5 * time.Millisecond}))
+	defer client.Close()
+
+	if err := client.Call(nil, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	head.Store(4)
+	time.AfterFunc(20*time.Millisecond, func() { head.Store(5) })
+	if err := client.CallContext(context.Background(), nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsistencyTokenHeader(t *testing.T) {
+	t.Parallel()
+
+	var head atomic.Uint64
+	head.Store(7)
+	server := newTestServer()
+	defer server.Stop()
+	server.SetConsistencyTokens(head.Load, "test_echo")
+
+	var lastHeader atomic.Value
+	lastHeader.Store("")
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastHeader.Store(r.Header.Get(ConsistencyTokenHeader))
+		server.ServeHTTP(w, r)
+	}))
+	defer httpsrv.Close()
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithConsistencyTokens())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Call(nil, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if h := lastHeader.Load(); h != "" {
+		t.Fatalf("header %q sent before any write", h)
+	}
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+	if h := lastHeader.Load(); h != "7" {
+		t.Fatalf("wrong consistency header %q, want \"7\"", h)
+	}
+
+	// The server also honors the header alone.
+	req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"test_null"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(ConsistencyTokenHeader, "8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var msg jsonrpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Error == nil || msg.Error.Code != errcodeBehindConsistency {
+		t.Fatalf("expected consistency error, got %+v", msg)
+	}
+}
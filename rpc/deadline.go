@@ -0,0 +1,154 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// RequestTimeoutHeader is the HTTP request header carrying the time budget of the
+// request in milliseconds, see WithDeadlinePropagation.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// maxRequestTimeout is the longest time budget accepted from clients. Longer budgets
+// are shortened to it.
+const maxRequestTimeout = 24 * time.Hour
+
+// WithDeadlinePropagation makes the client tell the server how long it will wait for
+// the response of calls with a context deadline, so the server can stop working on
+// calls which can no longer be answered in time. Over HTTP, the remaining time is sent
+// in the RequestTimeoutHeader. On other transports, it is sent in the "ext" member of
+// every call.
+//
+// Independent of this option, the deadline of the context limits the HTTP request and
+// the time spent writing the request to websocket and IPC connections.
+func WithDeadlinePropagation() ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.propagateDeadline = true
+	})
+}
+
+// RemainingTime returns the time left to answer the current call. It accounts for the
+// deadline of the request, the time budget sent by the client and the method timeout,
+// see SetMethodTimeout. Middlewares and methods can use it to skip expensive work
+// which can't finish in time. The boolean is false if the call has no time limit.
+func RemainingTime(ctx context.Context) (time.Duration, bool) {
+	remaining, ok := ContextRequestTimeout(ctx)
+	for _, key := range []any{clientDeadlineKey{}, callDeadlineKey{}} {
+		if d, found := ctx.Value(key).(callDeadline); found {
+			if left := d.remaining(); !ok || left < remaining {
+				remaining, ok = left, true
+			}
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return max(remaining, 0), true
+}
+
+type callDeadlineKey struct{}
+
+// callDeadline is the end of the execution time of a call.
+type callDeadline struct {
+	clock mclock.Clock
+	at    mclock.AbsTime
+}
+
+func (d callDeadline) remaining() time.Duration {
+	return d.at.Sub(d.clock.Now())
+}
+
+// timeoutMillis converts the time until the deadline of ctx to milliseconds, rounded up
+// so that short budgets are not sent as zero.
+func timeoutMillis(ctx context.Context) (int64, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return 1, true
+	}
+	return int64((remaining + time.Millisecond - 1) / time.Millisecond), true
+}
+
+// attachTimeout stores the remaining time of ctx in the request metadata of msgs. Over
+// HTTP, it is sent as a header instead.
+func (c *Client) attachTimeout(ctx context.Context, msgs ...*jsonrpcMessage) {
+	if !c.propagateDeadline || c.isHTTP {
+		return
+	}
+	ms, ok := timeoutMillis(ctx)
+	if !ok {
+		return
+	}
+	for _, msg := range msgs {
+		ext := msg.requestExt()
+		ext.TimeoutMs = ms
+		msg.Ext, _ = json.Marshal(ext)
+	}
+}
+
+// parseRequestTimeout parses the value of the RequestTimeoutHeader.
+func parseRequestTimeout(v string) (time.Duration, bool) {
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return millisTimeout(ms)
+}
+
+// millisTimeout converts a time budget in milliseconds, limiting it to maxRequestTimeout.
+func millisTimeout(ms int64) (time.Duration, bool) {
+	if ms <= 0 {
+		return 0, false
+	}
+	if ms > maxRequestTimeout.Milliseconds() {
+		return maxRequestTimeout, true
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// requestTimeout returns the time budget in the request metadata of msg.
+func (msg *jsonrpcMessage) requestTimeout() (time.Duration, bool) {
+	if len(msg.Ext) == 0 {
+		return 0, false
+	}
+	return millisTimeout(msg.requestExt().TimeoutMs)
+}
+
+// callTimeout returns the execution timeout of a call, which is the method timeout or
+// the time budget sent by the client, whichever is shorter. It returns zero if the call
+// has no timeout.
+func (h *handler) callTimeout(ctx context.Context, cfg *registryConfig, method string) time.Duration {
+	timeout := cfg.methodTimeout(method)
+	if deadline, ok := ctx.Value(clientDeadlineKey{}).(callDeadline); ok {
+		budget := max(deadline.remaining(), time.Nanosecond)
+		if timeout == 0 || budget < timeout {
+			timeout = budget
+		}
+	}
+	return timeout
+}
+
+type clientDeadlineKey struct{}
@@ -0,0 +1,194 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type deadlineTestService struct {
+	canceled chan struct{}
+}
+
+// Remaining returns the time left to answer the call, or -1 if there is no limit.
+func (s *deadlineTestService) Remaining(ctx context.Context) time.Duration {
+	if d, ok := RemainingTime(ctx); ok {
+		return d
+	}
+	return -1
+}
+
+func (s *deadlineTestService) Wait(ctx context.Context) {
+	<-ctx.Done()
+	close(s.canceled)
+}
+
+func callRemaining(t *testing.T, client *Client, timeout time.Duration) time.Duration {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var remaining time.Duration
+	if err := client.CallContext(ctx, &remaining, "deadline_remaining"); err != nil {
+		t.Fatal(err)
+	}
+	return remaining
+}
+
+func TestDeadlinePropagation(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &deadlineTestService{canceled: make(chan struct{})}
+	server.RegisterName("deadline", svc)
+	client := dialInProcWithOptions(server, WithDeadlinePropagation())
+	defer client.Close()
+
+	if d := callRemaining(t, client, 5*time.Second); d <= 0 || d > 5*time.Second {
+		t.Fatalf("wrong remaining time %v", d)
+	}
+
+	// The server cancels calls when the budget of the client is used up.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "deadline_wait"); err == nil {
+		t.Fatal("expected error")
+	}
+	select {
+	case <-svc.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call not canceled on server")
+	}
+}
+
+func TestDeadlinePropagationDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("deadline", &deadlineTestService{canceled: make(chan struct{})})
+	client := DialInProc(server)
+	defer client.Close()
+
+	if d := callRemaining(t, client, 5*time.Second); d != -1 {
+		t.Fatalf("server saw remaining time %v without propagation", d)
+	}
+}
+
+func TestDeadlinePropagationHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("deadline", &deadlineTestService{canceled: make(chan struct{})})
+	headers := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get(RequestTimeoutHeader)
+		server.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	client, err := DialOptions(context.Background(), ts.URL, WithDeadlinePropagation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if d := callRemaining(t, client, 5*time.Second); d <= 0 || d > 5*time.Second {
+		t.Fatalf("wrong remaining time %v", d)
+	}
+	h := <-headers
+	if d, ok := parseRequestTimeout(h); !ok || d > 5*time.Second {
+		t.Fatalf("wrong %s header %q", RequestTimeoutHeader, h)
+	}
+}
+
+func TestRemainingTimeMiddleware(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("deadline", &deadlineTestService{canceled: make(chan struct{})})
+	server.SetMethodTimeout("deadline_remaining", time.Second)
+	seen := make(chan time.Duration, 1)
+	server.SetMiddlewares([]Middleware{
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			d, _ := RemainingTime(ctx)
+			seen <- d
+			return next(ctx, method, args)
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var remaining time.Duration
+	if err := client.Call(&remaining, "deadline_remaining"); err != nil {
+		t.Fatal(err)
+	}
+	if d := <-seen; d <= 0 || d > time.Second {
+		t.Fatalf("wrong remaining time in middleware %v", d)
+	}
+	if remaining <= 0 || remaining > time.Second {
+		t.Fatalf("wrong remaining time in method %v", remaining)
+	}
+}
+
+func TestDeadlinePropagationConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := &timeoutTestService{release: make(chan struct{})}
+	defer close(service.release)
+	server.RegisterName("slow", service)
+	server.SetConcurrencyLimits(ConcurrencyLimits{MaxConcurrentCalls: 1})
+	client := dialInProcWithOptions(server, WithDeadlinePropagation())
+	defer client.Close()
+
+	// The call times out on the server, but the stuck method keeps its slot.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "slow_stuck"); err == nil {
+		t.Fatal("stuck call succeeded")
+	}
+	time.Sleep(100 * time.Millisecond)
+	wantCallError(t, client.Call(nil, "test_noArgsRets"), errcodeLimitExceeded)
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+		ok    bool
+	}{
+		{"250", 250 * time.Millisecond, true},
+		{"0", 0, false},
+		{"-5", 0, false},
+		{"x", 0, false},
+		{"9999999999999999", maxRequestTimeout, true},
+	}
+	for _, test := range tests {
+		d, ok := parseRequestTimeout(test.input)
+		if d != test.want || ok != test.ok {
+			t.Errorf("%q: got %v %t, want %v %t", test.input, d, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultAttemptDelay is the delay between connection attempts recommended by
+	// RFC 8305.
+	defaultAttemptDelay = 250 * time.Millisecond
+
+	// addrFailureRetention is the time for which a failed address is tried after
+	// the addresses which did not fail.
+	addrFailureRetention = time.Minute
+)
+
+// WithHappyEyeballs makes the client dial HTTP and websocket endpoints whose host name
+// resolves to multiple addresses as described in RFC 8305. Connection attempts start
+// with IPv6 and alternate between address families. A new attempt is started when the
+// previous one fails or after attemptDelay, and the first connection established is
+// used. A zero attemptDelay selects the default of 250ms.
+//
+// The client remembers the connection time of each address and the addresses which
+// failed recently. Later connections, e.g. when reconnecting, try fast addresses first
+// and failed ones last.
+//
+// This option can't be combined with WithHTTPClient, WithWebsocketDialer or
+// WithSOCKSProxy.
+func WithHappyEyeballs(attemptDelay time.Duration) ClientOption {
+	if attemptDelay <= 0 {
+		attemptDelay = defaultAttemptDelay
+	}
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.eyeballsDelay = attemptDelay
+	})
+}
+
+// setupEyeballs installs an HTTP client and websocket dialer which use multi-address
+// dialing.
+func (cfg *clientConfig) setupEyeballs() error {
+	if cfg.eyeballsDelay == 0 {
+		return nil
+	}
+	if cfg.socksProxy != nil {
+		return errors.New("multi-address dialing can't be used with a SOCKS proxy")
+	}
+	if cfg.httpClient != nil || cfg.wsDialer != nil {
+		return errors.New("multi-address dialing can't be used with a custom HTTP client or websocket dialer")
+	}
+	d := newMultiDialer(cfg.eyeballsDelay)
+	d.dial = cfg.dialTCP()
+	if cfg.resolver != nil {
+		d.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return cfg.resolver.LookupNetIP(ctx, "ip", host)
+		}
+	}
+	cfg.setDialer(d.DialContext, http.ProxyFromEnvironment)
+	return nil
+}
+
+// addrHealth is the connection history of an address.
+type addrHealth struct {
+	connectTime time.Duration // of the last successful attempt
+	lastFailure time.Time     // zero if the last attempt succeeded
+}
+
+// multiDialer implements RFC 8305 connection attempts.
+type multiDialer struct {
+	delay  time.Duration
+	lookup func(ctx context.Context, host string) ([]netip.Addr, error)
+	dial   func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu     sync.Mutex
+	health map[netip.Addr]*addrHealth
+}
+
+func newMultiDialer(delay time.Duration) *multiDialer {
+	var nd net.Dialer
+	return &multiDialer{
+		delay: delay,
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+		dial:   nd.DialContext,
+		health: make(map[netip.Addr]*addrHealth),
+	}
+}
+
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// DialContext connects to addr, trying all addresses of its host.
+func (d *multiDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+	var addrs []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{ip}
+	} else if addrs, err = d.lookup(ctx, host); err != nil {
+		return nil, err
+	}
+	addrs = d.order(network, addrs)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no suitable address found for %s", host)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		results  = make(chan dialResult, len(addrs))
+		timer    = time.NewTimer(d.delay)
+		next     int
+		pending  int
+		firstErr error
+	)
+	defer timer.Stop()
+	start := func() {
+		ip := addrs[next]
+		next++
+		pending++
+		timer.Reset(d.delay)
+		go func() {
+			begin := time.Now()
+			conn, err := d.dial(ctx, network, netip.AddrPortFrom(ip, uint16(port)).String())
+			// Attempts canceled because another one succeeded don't count as failures.
+			if err == nil || ctx.Err() == nil {
+				d.record(ip, time.Since(begin), err)
+			}
+			results <- dialResult{conn, err}
+		}()
+	}
+	// closePending closes connections established by the remaining attempts.
+	closePending := func() {
+		go func(n int) {
+			for ; n > 0; n-- {
+				if r := <-results; r.conn != nil {
+					r.conn.Close()
+				}
+			}
+		}(pending)
+	}
+
+	start()
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				cancel()
+				closePending()
+				return r.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if next < len(addrs) {
+				start()
+			}
+		case <-timer.C:
+			if next < len(addrs) {
+				start()
+			}
+		case <-ctx.Done():
+			closePending()
+			return nil, ctx.Err()
+		}
+	}
+	return nil, firstErr
+}
+
+// record updates the health of an address after a connection attempt.
+func (d *multiDialer) record(ip netip.Addr, connectTime time.Duration, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h := d.health[ip]
+	if h == nil {
+		h = new(addrHealth)
+		d.health[ip] = h
+	}
+	if err != nil {
+		h.lastFailure = time.Now()
+	} else {
+		h.connectTime = connectTime
+		h.lastFailure = time.Time{}
+	}
+}
+
+// order sorts addresses in the order of connection attempts. Within each family,
+// addresses are sorted by their connection history: addresses with a known connection
+// time come first, fastest first, followed by untried addresses in resolver order and
+// addresses which failed recently. The families are then interleaved, starting with
+// IPv6 unless its best address is worse than the best IPv4 address.
+func (d *multiDialer) order(network string, addrs []netip.Addr) []netip.Addr {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	type ranked struct {
+		ip   netip.Addr
+		rank int // 0: known connect time, 1: untried, 2: failed
+		time time.Duration
+		fail time.Time
+	}
+	var v4, v6 []ranked
+	for _, ip := range addrs {
+		ip = ip.Unmap()
+		r := ranked{ip: ip, rank: 1}
+		if h := d.health[ip]; h != nil {
+			switch {
+			case time.Since(h.lastFailure) < addrFailureRetention:
+				r.rank, r.fail = 2, h.lastFailure
+			case h.connectTime > 0:
+				r.rank, r.time = 0, h.connectTime
+			}
+		}
+		switch {
+		case ip.Is4() && network != "tcp6":
+			v4 = append(v4, r)
+		case ip.Is6() && network != "tcp4":
+			v6 = append(v6, r)
+		}
+	}
+	compare := func(a, b ranked) int {
+		if c := cmp.Compare(a.rank, b.rank); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.time, b.time); c != 0 {
+			return c
+		}
+		return a.fail.Compare(b.fail)
+	}
+	slices.SortStableFunc(v4, compare)
+	slices.SortStableFunc(v6, compare)
+
+	first, second := v6, v4
+	if len(v6) == 0 || (len(v4) > 0 && compare(v4[0], v6[0]) < 0) {
+		first, second = v4, v6
+	}
+	ordered := make([]netip.Addr, 0, len(addrs))
+	for i := 0; i < max(len(first), len(second)); i++ {
+		if i < len(first) {
+			ordered = append(ordered, first[i].ip)
+		}
+		if i < len(second) {
+			ordered = append(ordered, second[i].ip)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMultiDialerOrder(t *testing.T) {
+	d := newMultiDialer(defaultAttemptDelay)
+	var (
+		a4 = netip.MustParseAddr("192.0.2.1")
+		b4 = netip.MustParseAddr("192.0.2.2")
+		a6 = netip.MustParseAddr("2001:db8::1")
+		b6 = netip.MustParseAddr("2001:db8::2")
+	)
+	addrs := []netip.Addr{a4, b4, a6, b6}
+	if got := d.order("tcp", addrs); !slices.Equal(got, []netip.Addr{a6, a4, b6, b4}) {
+		t.Fatalf("wrong initial order %v", got)
+	}
+	if got := d.order("tcp4", addrs); !slices.Equal(got, []netip.Addr{a4, b4}) {
+		t.Fatalf("wrong order for tcp4 %v", got)
+	}
+
+	// Failed addresses move to the end of their family. When the best IPv6 address
+	// is worse than the best IPv4 address, IPv4 is tried first.
+	d.record(a6, 0, errors.New("refused"))
+	d.record(b6, 0, errors.New("refused"))
+	d.record(b4, 10*time.Millisecond, nil)
+	d.record(a4, 20*time.Millisecond, nil)
+	if got := d.order("tcp", addrs); !slices.Equal(got, []netip.Addr{b4, a6, a4, b6}) {
+		t.Fatalf("wrong order after failures %v", got)
+	}
+}
+
+func TestMultiDialerStaggered(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts []string
+	)
+	d := newMultiDialer(50 * time.Millisecond)
+	d.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		return []netip.Addr{
+			netip.MustParseAddr("192.0.2.1"),
+			netip.MustParseAddr("2001:db8::1"),
+			netip.MustParseAddr("2001:db8::2"),
+		}, nil
+	}
+	d.dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		attempts = append(attempts, addr)
+		mu.Unlock()
+		switch addr {
+		case "[2001:db8::1]:8545":
+			// Black-holed: never answers.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "[2001:db8::2]:8545":
+			return nil, errors.New("connection refused")
+		}
+		c1, c2 := net.Pipe()
+		c2.Close()
+		return c1, nil
+	}
+
+	start := time.Now()
+	conn, err := d.DialContext(context.Background(), "tcp", "rpc.node.invalid:8545")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("second attempt started before the attempt delay (%v)", elapsed)
+	}
+	mu.Lock()
+	want := []string{"[2001:db8::1]:8545", "192.0.2.1:8545"}
+	if !slices.Equal(attempts, want) {
+		t.Fatalf("wrong attempts %v, want %v", attempts, want)
+	}
+	attempts = nil
+	mu.Unlock()
+
+	// The black-holed address was canceled, so it isn't marked as failed. But the
+	// IPv4 address is known to work now, so it is tried first.
+	conn, err = d.DialContext(context.Background(), "tcp", "rpc.node.invalid:8545")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts[0] != "192.0.2.1:8545" {
+		t.Fatalf("wrong attempts %v", attempts)
+	}
+}
+
+func TestMultiDialerAllFail(t *testing.T) {
+	t.Parallel()
+
+	d := newMultiDialer(time.Hour)
+	d.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		return []netip.Addr{netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("2001:db8::1")}, nil
+	}
+	var calls int
+	d.dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		calls++
+		return nil, errors.New("refused " + addr)
+	}
+	// Failed attempts start the next one without waiting for the attempt delay.
+	_, err := d.DialContext(context.Background(), "tcp", "rpc.node.invalid:1")
+	if err == nil || !strings.Contains(err.Error(), "2001:db8::1") {
+		t.Fatalf("expected error of first attempt, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("wrong number of attempts %d", calls)
+	}
+}
+
+func TestHappyEyeballsClient(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+
+	url := "ws://localhost:" + httpsrv.URL[strings.LastIndexByte(httpsrv.URL, ':')+1:]
+	client, err := DialOptions(context.Background(), url, WithHappyEyeballs(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DialOptions(context.Background(), url, WithHappyEyeballs(0), WithSOCKSProxy(SOCKSProxy{Addr: "127.0.0.1:9050"}))
+	if err == nil {
+		t.Fatal("SOCKS proxy accepted with multi-address dialing")
+	}
+}
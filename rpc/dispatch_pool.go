@@ -0,0 +1,80 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "encoding/json"
+
+// dispatchQueueSize is the number of notifications queued per dispatch worker.
+const dispatchQueueSize = 64
+
+// dispatchPool delivers subscription notifications on a set of worker goroutines, so a
+// subscription which is slow to process notifications doesn't delay the client's
+// dispatch loop. Each subscription is served by a single worker to preserve the order of
+// its notifications.
+type dispatchPool struct {
+	queues []chan notification
+	next   int // worker assigned to the next subscription
+	quit   chan struct{}
+}
+
+type notification struct {
+	sub  *ClientSubscription
+	data json.RawMessage
+}
+
+func newDispatchPool(workers int) *dispatchPool {
+	p := &dispatchPool{
+		queues: make([]chan notification, workers),
+		quit:   make(chan struct{}),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan notification, dispatchQueueSize)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+// assign selects the worker for a new subscription. Workers are assigned round-robin.
+func (p *dispatchPool) assign(sub *ClientSubscription) {
+	sub.worker = p.next
+	p.next = (p.next + 1) % len(p.queues)
+}
+
+// deliver queues a notification. It blocks while the queue of the subscription's worker
+// is full.
+func (p *dispatchPool) deliver(sub *ClientSubscription, data json.RawMessage) {
+	select {
+	case p.queues[sub.worker] <- notification{sub, data}:
+	case <-p.quit:
+	}
+}
+
+// stop terminates the workers. Queued notifications are dropped.
+func (p *dispatchPool) stop() {
+	close(p.quit)
+}
+
+func (p *dispatchPool) work(queue chan notification) {
+	for {
+		select {
+		case n := <-queue:
+			n.sub.deliver(n.data)
+		case <-p.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingValue blocks unmarshaling until blockingValueRelease is closed.
+type blockingValue struct{}
+
+var blockingValueRelease chan struct{}
+
+func (v *blockingValue) UnmarshalJSON([]byte) error {
+	<-blockingValueRelease
+	return nil
+}
+
+func TestClientDispatchWorkers(t *testing.T) {
+	blockingValueRelease = make(chan struct{})
+	server := newTestServer()
+	defer server.Stop()
+	client := dialInProcWithOptions(server, WithDispatchWorkers(2))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The first subscription is stuck unmarshaling its notifications.
+	const count = 5
+	slow := make(chan blockingValue, count)
+	if _, err := client.Subscribe(ctx, "nftest", slow, "someSubscription", count, 0); err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	// The second one still receives notifications.
+	fast := make(chan int, count)
+	if _, err := client.Subscribe(ctx, "nftest", fast, "someSubscription", count, 0); err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case v := <-fast:
+			if v != i {
+				t.Fatalf("wrong value %d, want %d", v, i)
+			}
+		case <-ctx.Done():
+			t.Fatal("notifications of second subscription delayed")
+		}
+	}
+
+	close(blockingValueRelease)
+	for i := 0; i < count; i++ {
+		select {
+		case <-slow:
+		case <-ctx.Done():
+			t.Fatal("notifications of first subscription lost")
+		}
+	}
+}
@@ -98,6 +98,9 @@ Subscriptions are deleted when the user sends an unsubscribe request or when the
 connection which was used to create the subscription is closed. This can be initiated by
 the client and server. The server will close the connection for any write error.
 
+Services which produce notifications on a goroutine can use RunSubscription, which
+cancels the goroutine when the subscription is deleted.
+
 For more information about subscriptions, see https://github.com/ethereum/go-ethereum/wiki/RPC-PUB-SUB.
 
 # Reverse Calls
@@ -0,0 +1,185 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+// drainNoticeMethod is the method of the notification sent to websocket clients when
+// the server starts draining.
+const drainNoticeMethod = MetadataApi + "_draining"
+
+const (
+	defaultDrainHealthStatus = http.StatusServiceUnavailable
+	defaultDrainIdleTimeout  = 5 * time.Second
+	drainCloseWriteTimeout   = time.Second
+)
+
+// drainNotice is the parameter object of the drain notification.
+type drainNotice struct {
+	IdleTimeoutMs int64 `json:"idleTimeoutMs"`
+}
+
+// SetDrainOptions configures the behavior of the server while it is draining, see
+// StartDraining. healthStatus is the HTTP status returned to health checks, and
+// idleTimeout is the time after which idle websocket connections are closed. Zero
+// values select the defaults, which are 503 and five seconds.
+//
+// This method should be called before StartDraining.
+func (s *Server) SetDrainOptions(healthStatus int, idleTimeout time.Duration) {
+	if healthStatus == 0 {
+		healthStatus = defaultDrainHealthStatus
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = defaultDrainIdleTimeout
+	}
+	s.drainHealthStatus = healthStatus
+	s.drainIdleTimeout = idleTimeout
+}
+
+// StartDraining prepares the server for shutdown behind a load balancer. The server
+// keeps serving all requests, but
+//
+//   - HTTP health checks (GET requests without body) fail with the configured status,
+//   - HTTP responses ask the client to close the connection,
+//   - websocket clients receive an rpc_draining notification, and their connections
+//     are closed once no request has been received on them for the configured idle
+//     timeout and all calls have been answered.
+//
+// Websocket connections opened while draining are treated the same way. Subscriptions
+// do not keep a connection open: clients are expected to re-establish them on another
+// server when they receive the notification.
+func (s *Server) StartDraining() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.run.Load() || !s.draining.CompareAndSwap(false, true) {
+		return
+	}
+	log.Debug("RPC server draining")
+	for codec := range s.codecs {
+		s.drainCodec(codec)
+	}
+}
+
+// Draining reports whether StartDraining has been called.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// drainCodec starts draining on codec if its transport supports it.
+// This assumes s.mutex is held.
+func (s *Server) drainCodec(codec ServerCodec) {
+	if d, ok := codec.(interface{ startDrain(time.Duration) }); ok {
+		d.startDrain(s.drainIdleTimeout)
+	}
+}
+
+// drainHealthCheck writes the health check response of a draining server.
+func (s *Server) drainHealthCheck(w http.ResponseWriter) {
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(s.drainHealthStatus)
+}
+
+// connActivity tracks the calls of a connection in order to detect idleness.
+type connActivity struct {
+	pending  atomic.Int64 // units read which contain calls, minus responses written
+	lastRead atomic.Int64 // time of the last call read, in unix nanoseconds
+}
+
+// read records a message or batch read from the connection.
+func (a *connActivity) read(msgs []*jsonrpcMessage) {
+	for _, msg := range msgs {
+		if msg.isCall() {
+			a.pending.Add(1)
+			a.lastRead.Store(time.Now().UnixNano())
+			return
+		}
+	}
+}
+
+// wrote records a message written to the connection.
+func (a *connActivity) wrote(v interface{}) {
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		if !v.isResponse() {
+			return
+		}
+	case []*jsonrpcMessage:
+	default:
+		return
+	}
+	// Errors about unreadable messages are not matched by a read, so the counter
+	// must not drop below zero.
+	for {
+		n := a.pending.Load()
+		if n <= 0 || a.pending.CompareAndSwap(n, n-1) {
+			return
+		}
+	}
+}
+
+// idleFor returns how long the connection has been idle. It returns zero while calls
+// are pending.
+func (a *connActivity) idleFor() time.Duration {
+	if a.pending.Load() > 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, a.lastRead.Load()))
+}
+
+// startDrain sends the drain notification and closes the connection once it has been
+// idle for the given timeout.
+func (wc *websocketCodec) startDrain(idleTimeout time.Duration) {
+	wc.activity.lastRead.Store(time.Now().UnixNano())
+	go wc.drainLoop(idleTimeout)
+}
+
+func (wc *websocketCodec) drainLoop(idleTimeout time.Duration) {
+	params, _ := json.Marshal(drainNotice{IdleTimeoutMs: idleTimeout.Milliseconds()})
+	notice := &jsonrpcMessage{Version: vsn, Method: drainNoticeMethod, Params: params}
+	if err := wc.writeJSON(context.Background(), notice, false); err != nil {
+		return
+	}
+
+	timer := time.NewTimer(idleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-wc.closed():
+			return
+		case <-timer.C:
+		}
+		idle := wc.activity.idleFor()
+		if idle >= idleTimeout {
+			break
+		}
+		timer.Reset(max(idleTimeout-idle, idleTimeout/4))
+	}
+	log.Debug("Closing idle websocket connection of draining server", "conn", wc.remoteAddr())
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server draining")
+	wc.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(drainCloseWriteTimeout))
+	wc.jsonCodec.close()
+}
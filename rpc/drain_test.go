@@ -0,0 +1,137 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestDrainHTTP(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetDrainOptions(http.StatusGone, 0)
+	httpsrv := httptest.NewServer(srv)
+	defer httpsrv.Close()
+
+	healthCheck := func() int {
+		resp, err := http.Get(httpsrv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := healthCheck(); code != http.StatusOK {
+		t.Fatalf("wrong health check status %d before draining", code)
+	}
+	srv.StartDraining()
+	if !srv.Draining() {
+		t.Fatal("server not draining")
+	}
+	if code := healthCheck(); code != http.StatusGone {
+		t.Fatalf("wrong health check status %d while draining", code)
+	}
+
+	// Calls are still served, but the connection is not kept alive.
+	body := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`
+	resp, err := http.Post(httpsrv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong call status %d", resp.StatusCode)
+	}
+	if !resp.Close {
+		t.Fatal("connection not closed after response")
+	}
+}
+
+func TestDrainWebsocket(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+		timeout = 200 * time.Millisecond
+	)
+	srv.SetDrainOptions(0, timeout)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	srv.StartDraining()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var notice jsonrpcMessage
+	if err := conn.ReadJSON(&notice); err != nil {
+		t.Fatal(err)
+	}
+	if notice.Method != drainNoticeMethod || !bytes.Equal(notice.Params, []byte(`{"idleTimeoutMs":200}`)) {
+		t.Fatalf("wrong drain notice %+v", notice)
+	}
+
+	// A call running past the idle timeout is answered before the connection is closed.
+	start := time.Now()
+	sleep, _ := json.Marshal(2 * timeout)
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"test_sleep","params":[`+string(sleep)+`]}`))
+	var resp jsonrpcMessage
+	if err := conn.ReadJSON(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.ID) != "1" || resp.Error != nil {
+		t.Fatalf("wrong response %+v", resp)
+	}
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Fatalf("expected going away close, got %v", err)
+	}
+	if d := time.Since(start); d < 2*timeout {
+		t.Fatalf("connection closed after %v, before the call completed", d)
+	}
+
+	// New connections are drained as well.
+	conn2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn2.ReadJSON(&notice); err != nil {
+		t.Fatal(err)
+	}
+	if notice.Method != drainNoticeMethod {
+		t.Fatalf("wrong drain notice %+v", notice)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type dryRunKey struct{}
+
+// NewContextWithDryRun returns a context marking calls as dry runs. Calls made by the
+// client with this context ask the server to validate the call without performing it.
+//
+// The server handles dry-run calls like other calls: parameters are validated and
+// middlewares, including rate limits, apply. The method handler is invoked with a
+// context for which IsDryRun reports true. Handlers supporting dry runs should check
+// the call as far as possible, e.g. by estimating its cost, but skip its effects.
+// Handlers which don't check IsDryRun perform the call as usual. Results of dry runs
+// are never cached. Since the server passes the flag in the context of the call,
+// services making calls to other servers using that context forward it.
+func NewContextWithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx belongs to a dry-run call, see NewContextWithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// isDryRun reports whether the sender of msg marked it as a dry run.
+func (msg *jsonrpcMessage) isDryRun() bool {
+	return len(msg.Ext) > 0 && msg.requestExt().DryRun
+}
+
+// attachDryRun marks msgs as dry runs in their request metadata if ctx is a dry-run
+// context.
+func (c *Client) attachDryRun(ctx context.Context, msgs ...*jsonrpcMessage) {
+	if !IsDryRun(ctx) {
+		return
+	}
+	for _, msg := range msgs {
+		ext := msg.requestExt()
+		ext.DryRun = true
+		msg.Ext, _ = json.Marshal(ext)
+	}
+}
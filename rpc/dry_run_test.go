@@ -0,0 +1,110 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type dryRunService struct {
+	performed atomic.Int64
+}
+
+func (s *dryRunService) Transfer(ctx context.Context, amount int) (string, error) {
+	if amount <= 0 {
+		return "", &invalidParamsError{"amount must be positive"}
+	}
+	if IsDryRun(ctx) {
+		return "ok", nil
+	}
+	s.performed.Add(1)
+	return "done", nil
+}
+
+func (s *dryRunService) CachePolicies() map[string]CachePolicy {
+	return map[string]CachePolicy{"transfer": {TTL: time.Minute}}
+}
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := new(dryRunService)
+	server.RegisterName("wallet", svc)
+	server.SetResponseCache(16)
+	rl := NewRateLimiter(RateLimitConfig{Peer: RateLimit{Rate: 1000}})
+	var middlewareDryRuns atomic.Int64
+	server.SetMiddlewares([]Middleware{
+		rl.Middleware(),
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			if info, _ := RequestInfoFromContext(ctx); info.DryRun {
+				middlewareDryRuns.Add(1)
+			}
+			return next(ctx, method, args)
+		},
+	})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	for i, url := range []string{"inproc", httpsrv.URL} {
+		var client *Client
+		if url == "inproc" {
+			client = DialInProc(server)
+		} else {
+			client, _ = DialHTTP(url)
+		}
+		defer client.Close()
+
+		before := svc.performed.Load()
+		dry := NewContextWithDryRun(context.Background())
+		var result string
+		amount := 10 + i
+		if err := client.CallContext(dry, &result, "wallet_transfer", amount); err != nil {
+			t.Fatal(err)
+		}
+		if result != "ok" || svc.performed.Load() != before {
+			t.Fatalf("%s: dry run performed the call: %q", url, result)
+		}
+		if err := client.CallContext(dry, &result, "wallet_transfer", -1); err == nil {
+			t.Fatalf("%s: dry run skipped validation", url)
+		}
+		batch := []BatchElem{{Method: "wallet_transfer", Args: []any{1}, Result: &result}}
+		if err := client.BatchCallContext(dry, batch); err != nil || batch[0].Error != nil || result != "ok" {
+			t.Fatalf("%s: dry-run batch: %v %v %q", url, err, batch[0].Error, result)
+		}
+
+		// Dry-run results are not served to real calls from the cache.
+		if err := client.Call(&result, "wallet_transfer", amount); err != nil {
+			t.Fatal(err)
+		}
+		if result != "done" || svc.performed.Load() != before+1 {
+			t.Fatalf("%s: real call not performed: %q", url, result)
+		}
+	}
+	if n := middlewareDryRuns.Load(); n != 6 {
+		t.Errorf("middleware saw %d dry runs, want 6", n)
+	}
+	if stats := rl.Stats(); stats.DryRun != 6 || stats.Allowed != 8 {
+		t.Errorf("wrong rate limiter stats %+v", stats)
+	}
+}
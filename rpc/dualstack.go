@@ -0,0 +1,212 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DualStackConfig configures a listener which accepts connections on separate IPv4 and
+// IPv6 sockets. The IPv6 socket only accepts IPv6 connections, so IPv4 clients are never
+// seen as IPv4-mapped IPv6 addresses.
+type DualStackConfig struct {
+	// Listen addresses of the two sockets, e.g. "0.0.0.0:8545" and "[::]:8545".
+	// The socket of a family is not opened if its address is empty.
+	IPv4Addr string
+	IPv6Addr string
+
+	// Allowed client networks per address family. Connections from addresses outside
+	// these networks are closed on accept. An empty list allows all clients of the
+	// family.
+	IPv4Allow []netip.Prefix
+	IPv6Allow []netip.Prefix
+}
+
+// allowed reports whether a client with the given address may connect.
+func (cfg *DualStackConfig) allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	allow := cfg.IPv6Allow
+	if addr.Is4() {
+		allow = cfg.IPv4Allow
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	for _, p := range allow {
+		if p.Contains(addr.WithZone("")) {
+			return true
+		}
+	}
+	return false
+}
+
+// DualStackListener is a net.Listener accepting connections from the sockets
+// configured in a DualStackConfig. Use it with http.Serve to serve HTTP and websocket
+// endpoints.
+type DualStackListener struct {
+	cfg       DualStackConfig
+	listeners []net.Listener
+	accepted  chan acceptResult
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+// ListenDualStack opens the sockets configured in cfg.
+func ListenDualStack(cfg DualStackConfig) (*DualStackListener, error) {
+	for _, p := range cfg.IPv4Allow {
+		if !p.Addr().Is4() {
+			return nil, fmt.Errorf("IPv4 allowlist contains IPv6 network %v", p)
+		}
+	}
+	for _, p := range cfg.IPv6Allow {
+		if !p.Addr().Is6() || p.Addr().Is4In6() {
+			return nil, fmt.Errorf("IPv6 allowlist contains IPv4 network %v", p)
+		}
+	}
+	l := &DualStackListener{
+		cfg:      cfg,
+		accepted: make(chan acceptResult),
+		closed:   make(chan struct{}),
+	}
+	for _, bind := range []struct{ network, addr string }{{"tcp4", cfg.IPv4Addr}, {"tcp6", cfg.IPv6Addr}} {
+		if bind.addr == "" {
+			continue
+		}
+		ln, err := net.Listen(bind.network, bind.addr)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l.listeners = append(l.listeners, ln)
+	}
+	if len(l.listeners) == 0 {
+		return nil, errors.New("no listen address configured")
+	}
+	for _, ln := range l.listeners {
+		l.wg.Add(1)
+		go l.acceptLoop(ln)
+	}
+	return l, nil
+}
+
+func (l *DualStackListener) acceptLoop(ln net.Listener) {
+	defer l.wg.Done()
+
+	for {
+		conn, err := ln.Accept()
+		if err == nil && !l.allowedConn(conn) {
+			log.Debug("Rejected RPC connection from address outside of allowlist", "conn", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		select {
+		case l.accepted <- acceptResult{conn, err}:
+		case <-l.closed:
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				return
+			}
+		}
+	}
+}
+
+func (l *DualStackListener) allowedConn(conn net.Conn) bool {
+	ap, err := netip.ParseAddrPort(conn.RemoteAddr().String())
+	if err != nil {
+		return false
+	}
+	return l.cfg.allowed(ap.Addr())
+}
+
+// Accept waits for the next allowed connection on any of the sockets.
+func (l *DualStackListener) Accept() (net.Conn, error) {
+	select {
+	case r := <-l.accepted:
+		return r.conn, r.err
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes all sockets.
+func (l *DualStackListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		for _, ln := range l.listeners {
+			if cerr := ln.Close(); err == nil {
+				err = cerr
+			}
+		}
+		l.wg.Wait()
+	})
+	return err
+}
+
+// Addr returns the address of the first socket. Use Addrs to get all addresses.
+func (l *DualStackListener) Addr() net.Addr {
+	return l.listeners[0].Addr()
+}
+
+// Addrs returns the addresses of all sockets, IPv4 first.
+func (l *DualStackListener) Addrs() []net.Addr {
+	addrs := make([]net.Addr, len(l.listeners))
+	for i, ln := range l.listeners {
+		addrs[i] = ln.Addr()
+	}
+	return addrs
+}
+
+// RemoteIP returns the IP address of the peer. IPv4 clients connecting through an IPv6
+// socket are reported with their IPv4 address. It returns false for transports without
+// IP addresses, such as IPC.
+func (info PeerInfo) RemoteIP() (netip.Addr, bool) {
+	ap, err := netip.ParseAddrPort(info.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ap.Addr().Unmap(), true
+}
+
+// normalizeRemoteAddr returns the canonical form of a remote address. IPv6 addresses
+// are written in compressed lowercase notation and IPv4-mapped IPv6 addresses are
+// converted to IPv4. Addresses which are not IP:port pairs are returned unchanged.
+func normalizeRemoteAddr(addr string) string {
+	ap, err := netip.ParseAddrPort(addr)
+	if err != nil {
+		return addr
+	}
+	return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port()).String()
+}
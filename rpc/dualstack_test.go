@@ -0,0 +1,117 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestNormalizeRemoteAddr(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"127.0.0.1:8545", "127.0.0.1:8545"},
+		{"[::ffff:192.0.2.1]:8545", "192.0.2.1:8545"},
+		{"[2001:DB8:0:0:0:0:0:1]:8545", "[2001:db8::1]:8545"},
+		{"[fe80::1%eth0]:8545", "[fe80::1%eth0]:8545"},
+		{"/tmp/geth.ipc", "/tmp/geth.ipc"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := normalizeRemoteAddr(test.in); got != test.want {
+			t.Errorf("normalizeRemoteAddr(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+
+	ip, ok := PeerInfo{RemoteAddr: "[::ffff:10.0.0.1]:1"}.RemoteIP()
+	if !ok || ip != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("wrong remote IP %v", ip)
+	}
+	if _, ok := (PeerInfo{Transport: "ipc"}).RemoteIP(); ok {
+		t.Error("IPC peer has remote IP")
+	}
+}
+
+func TestDualStackConfigValidation(t *testing.T) {
+	_, err := ListenDualStack(DualStackConfig{
+		IPv4Addr:  "127.0.0.1:0",
+		IPv4Allow: []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")},
+	})
+	if err == nil {
+		t.Fatal("IPv6 network accepted in IPv4 allowlist")
+	}
+	if _, err := ListenDualStack(DualStackConfig{}); err == nil {
+		t.Fatal("listener without addresses created")
+	}
+}
+
+func TestDualStackListener(t *testing.T) {
+	if ln, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Skip("IPv6 loopback not available:", err)
+	} else {
+		ln.Close()
+	}
+
+	l, err := ListenDualStack(DualStackConfig{
+		IPv4Addr:  "127.0.0.1:0",
+		IPv6Addr:  "[::1]:0",
+		IPv4Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+		IPv6Allow: []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer()
+	defer srv.Stop()
+	httpsrv := &http.Server{Handler: srv}
+	go httpsrv.Serve(l)
+	defer httpsrv.Close()
+
+	addrs := l.Addrs()
+	if len(addrs) != 2 {
+		t.Fatalf("wrong number of addresses %v", addrs)
+	}
+
+	// The IPv4 client is allowed.
+	client, err := DialHTTP("http://" + addrs[0].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if ip, _ := info.RemoteIP(); ip != netip.MustParseAddr("127.0.0.1") {
+		t.Fatalf("wrong remote address %q", info.RemoteAddr)
+	}
+
+	// The IPv6 client is not in the allowlist.
+	client6, err := DialHTTP("http://" + addrs[1].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client6.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client6.CallContext(ctx, &info, "test_peerInfo"); err == nil {
+		t.Fatal("call from IPv6 client outside of allowlist succeeded")
+	}
+}
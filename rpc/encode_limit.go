@@ -0,0 +1,97 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// EncodeLimit limits the number of large results of a method which are encoded at the
+// same time. Encoding giant results, such as execution traces, can monopolize the CPU.
+// Limiting concurrent encodes keeps the latency of small calls stable while several
+// large responses are produced.
+type EncodeLimit struct {
+	// MaxConcurrent is the number of results which may be encoded at the same time.
+	// Further encodes wait until one finishes.
+	MaxConcurrent int
+
+	// SizeThreshold is the result size in bytes above which encodes are limited. Since
+	// the size is only known after encoding, the limit applies to calls of a method when
+	// the previous result of the method exceeded the threshold.
+	SizeThreshold int
+}
+
+// SetEncodeLimits configures encode limits by method name. The limit with key "*" is
+// shared by all methods without their own entry. Calls waiting to encode their result
+// fail with a timeout error if their context ends. Passing nil removes all limits.
+func (s *Server) SetEncodeLimits(limits map[string]EncodeLimit) {
+	var l *encodeLimiter
+	if len(limits) > 0 {
+		l = &encodeLimiter{sems: make(map[string]*encodeSemaphore, len(limits))}
+		for method, limit := range limits {
+			if limit.MaxConcurrent > 0 {
+				l.sems[method] = &encodeSemaphore{EncodeLimit: limit, slots: make(chan struct{}, limit.MaxConcurrent)}
+			}
+		}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.encodeLimits = l })
+}
+
+// encodeLimiter holds the semaphores of the configured encode limits.
+type encodeLimiter struct {
+	sems      map[string]*encodeSemaphore
+	lastSizes sync.Map // method name -> *atomic.Int64, size of the last result
+}
+
+type encodeSemaphore struct {
+	EncodeLimit
+	slots chan struct{}
+}
+
+// acquire waits until the result of method may be encoded. The returned function
+// must be called with the size of the encoded result. If ctx ends while waiting,
+// acquire returns false.
+func (l *encodeLimiter) acquire(ctx context.Context, method string) (release func(size int), ok bool) {
+	sem := l.sems[method]
+	if sem == nil {
+		if sem = l.sems["*"]; sem == nil {
+			return func(int) {}, true
+		}
+	}
+	v, _ := l.lastSizes.LoadOrStore(method, new(atomic.Int64))
+	last := v.(*atomic.Int64)
+	if last.Load() <= int64(sem.SizeThreshold) {
+		return func(size int) { last.Store(int64(size)) }, true
+	}
+
+	select {
+	case sem.slots <- struct{}{}:
+	default:
+		encodesQueuedCounter.Inc(1)
+		select {
+		case sem.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	return func(size int) {
+		last.Store(int64(size))
+		<-sem.slots
+	}, true
+}
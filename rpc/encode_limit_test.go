@@ -0,0 +1,126 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowResult is a result which takes a while to encode, tracking concurrent encodes.
+type slowResult struct {
+	size        int
+	active, max *atomic.Int32
+}
+
+func (r slowResult) MarshalJSON() ([]byte, error) {
+	n := r.active.Add(1)
+	defer r.active.Add(-1)
+	for {
+		m := r.max.Load()
+		if n <= m || r.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return []byte(`"` + strings.Repeat("x", r.size) + `"`), nil
+}
+
+type encodeTestService struct {
+	active, max atomic.Int32
+}
+
+func (s *encodeTestService) Big(size int) slowResult {
+	return slowResult{size: size, active: &s.active, max: &s.max}
+}
+
+func TestEncodeLimits(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := new(encodeTestService)
+	if err := server.RegisterName("enc", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetEncodeLimits(map[string]EncodeLimit{
+		"enc_big": {MaxConcurrent: 1, SizeThreshold: 1000},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	run := func(size int) int32 {
+		t.Helper()
+		service.max.Store(0)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := client.Call(nil, "enc_big", size); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		return service.max.Load()
+	}
+
+	// Small results are encoded concurrently.
+	if max := run(10); max < 2 {
+		t.Errorf("small results: max concurrent encodes %d", max)
+	}
+	// Once the method produced a large result, encodes are limited.
+	if err := client.Call(nil, "enc_big", 2000); err != nil {
+		t.Fatal(err)
+	}
+	if max := run(2000); max != 1 {
+		t.Errorf("large results: max concurrent encodes %d, want 1", max)
+	}
+}
+
+func TestEncodeLimiterContext(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	server.SetEncodeLimits(map[string]EncodeLimit{"*": {MaxConcurrent: 1}})
+	l := server.services.snapshot().encodeLimits
+
+	release, ok := l.acquire(context.Background(), "m")
+	if !ok {
+		t.Fatal("first acquire failed")
+	}
+	release(10)
+	release, ok = l.acquire(context.Background(), "m")
+	if !ok {
+		t.Fatal("second acquire failed")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, ok := l.acquire(ctx, "m"); ok {
+		t.Fatal("acquire succeeded while slot is held")
+	}
+	release(10)
+	if release, ok = l.acquire(context.Background(), "m"); !ok {
+		t.Fatal("acquire failed after release")
+	}
+	release(0)
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// epochLen is the number of hex digits of a server epoch.
+const epochLen = 8
+
+// newEpoch creates a random server epoch. The first digit is never zero, so that ids
+// starting with the epoch keep their length when leading zeros are trimmed.
+func newEpoch() []byte {
+	epoch := make([]byte, epochLen/2)
+	crand.Read(epoch)
+	epoch[0] |= 0x10
+	return epoch
+}
+
+// Epoch returns the epoch of the server, a random value chosen when the server is
+// created. Subscription ids and session tokens issued by the server start with the
+// epoch. Clients presenting an id of another epoch, for example after the server was
+// restarted, receive an error with code -32008, telling them to subscribe again. The
+// epoch is also served by the rpc_epoch method.
+func (s *Server) Epoch() string {
+	return s.epoch
+}
+
+// Epoch returns the epoch of the server.
+func (s *RPCService) Epoch() string {
+	return s.server.epoch
+}
+
+// idEpoch returns the epoch of a subscription id created by an epoch id generator.
+func idEpoch(id ID) (string, bool) {
+	hex := strings.TrimPrefix(string(id), "0x")
+	if len(hex) != 32 {
+		return "", false
+	}
+	return hex[:epochLen], true
+}
+
+// fromOtherEpoch reports whether an unknown id or token of the given epoch was issued
+// by another server instance. ok is false if the id doesn't carry an epoch.
+func (h *handler) fromOtherEpoch(epoch string, ok bool) bool {
+	return ok && h.epoch != "" && epoch != h.epoch
+}
+
+// sessionEpoch returns the epoch of a session token.
+func sessionEpoch(token string) (string, bool) {
+	if len(token) <= epochLen {
+		return "", false
+	}
+	if _, err := hex.DecodeString(token[:epochLen]); err != nil {
+		return "", false
+	}
+	return token[:epochLen], true
+}
+
+// unknownSubscriptionError is returned for subscription ids and session tokens issued by
+// another instance of the server.
+type unknownSubscriptionError struct{ epoch string }
+
+func (e *unknownSubscriptionError) ErrorCode() int { return errcodeUnknownSubscription }
+
+func (e *unknownSubscriptionError) Error() string { return errMsgUnknownSubscription }
+
+func (e *unknownSubscriptionError) ErrorData() interface{} {
+	return map[string]string{"epoch": e.epoch}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerEpoch(t *testing.T) {
+	t.Parallel()
+
+	// The servers use the default ID generator, which prefixes IDs with the epoch.
+	server := NewServer()
+	defer server.Stop()
+	server.RegisterName("nftest", new(notificationTestService))
+	server.SetSessionResumption(time.Minute, 10)
+	client := DialInProc(server)
+	defer client.Close()
+	other := NewServer()
+	defer other.Stop()
+	other.RegisterName("nftest", new(notificationTestService))
+	other.SetSessionResumption(time.Minute, 10)
+	otherClient := DialInProc(other)
+	defer otherClient.Close()
+	if len(server.Epoch()) != epochLen || server.Epoch() == other.Epoch() {
+		t.Fatalf("bad epochs %q, %q", server.Epoch(), other.Epoch())
+	}
+	var epoch string
+	if err := client.Call(&epoch, "rpc_epoch"); err != nil || epoch != server.Epoch() {
+		t.Fatalf("rpc_epoch returned %q, %v", epoch, err)
+	}
+
+	sub, err := otherClient.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if !strings.HasPrefix(sub.id(), "0x"+other.Epoch()) {
+		t.Fatalf("subscription id %s doesn't start with epoch %s", sub.id(), other.Epoch())
+	}
+
+	checkUnknown := func(err error) {
+		t.Helper()
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeUnknownSubscription {
+			t.Fatalf("wrong error %v", err)
+		}
+		var dataErr DataError
+		if !errors.As(err, &dataErr) || dataErr.ErrorData().(map[string]interface{})["epoch"] != server.Epoch() {
+			t.Fatalf("wrong error data %v", dataErr.ErrorData())
+		}
+	}
+
+	// Unsubscribing with the id of another server instance.
+	checkUnknown(client.Call(nil, "nftest_unsubscribe", sub.id()))
+
+	// Unknown ids of the same instance are just not found.
+	unknown := ID("0x" + server.Epoch() + strings.Repeat("0", 24))
+	err = client.Call(nil, "nftest_unsubscribe", unknown)
+	if err == nil || err.Error() != ErrSubscriptionNotFound.Error() {
+		t.Fatalf("wrong error for unknown id: %v", err)
+	}
+
+	// Resuming the session of another server instance.
+	var token string
+	if err := otherClient.Call(&token, "rpc_newSession"); err != nil {
+		t.Fatal(err)
+	}
+	checkUnknown(client.Call(nil, "rpc_resumeSession", token))
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "slices"
+
+// ErrorCatalogEntry describes an error code returned by the server.
+type ErrorCatalogEntry struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Retryable   bool   `json:"retryable"` // whether repeating the call may succeed
+}
+
+// builtinErrors is the taxonomy of errors produced by the RPC package itself.
+var builtinErrors = []ErrorCatalogEntry{
+	{-32700, "parse error", "the request is not valid JSON", false},
+	{-32600, "invalid request", "the request is not a valid JSON-RPC message, or the call exceeds the batch item limit", false},
+	{-32601, "method not found", "the method or subscription does not exist or is not available", false},
+	{-32602, "invalid params", "the parameters of the call are invalid", false},
+	{-32603, "internal error", "the method crashed or its result could not be encoded", false},
+	{errcodeDefault, "server error", "the method returned an error", false},
+	{legacyErrcodeNotificationsUnsupported, "notifications unsupported", "subscriptions are not supported on this transport", false},
+	{errcodeTimeout, "timeout", errMsgTimeout, true},
+	{errcodeResponseTooLarge, "response too large", "the response exceeds the batch response size limit or the negotiated message size", false},
+	{errcodeDeadlineSkipped, "deadline exceeded", errMsgDeadlineSkipped, true},
+	{errcodeLimitExceeded, "limit exceeded", "the server is processing too many calls and its queue is full", true},
+	{errcodeMemoryCeiling, "memory ceiling exceeded", "the call exceeds the memory ceiling of its method", false},
+	{errcodeQuotaExceeded, "quota exceeded", "the client has made too many calls in the current quota window", true},
+	{errcodeUnknownSubscription, "unknown subscription", "the subscription or session was created by another server instance, e.g. before a restart; subscribe again", false},
+	{errcodeRateLimited, "rate limited", "the call exceeds the rate limit of its method or of the client", true},
+	{errcodeReplayInProgress, "replay in progress", "the call is a replay of a non-idempotent call which is still executing", true},
+	{errcodeRedirect, "redirect", "the call must be sent to one of the endpoints listed in the error data", false},
+	{errcodeUnauthenticated, "unauthenticated", "the connection must complete the challenge-response handshake before calling methods", false},
+	{errcodeBehindConsistency, "behind consistency token", "the server has not reached the position of the consistency token sent with the request", true},
+}
+
+// RegisterErrorCodes adds application-defined error codes to the error catalog served by
+// the rpc_errorCatalog method. Registering a code which is already present replaces its
+// entry.
+func (s *Server) RegisterErrorCodes(entries ...ErrorCatalogEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.errorCodes == nil {
+		s.errorCodes = make(map[int]ErrorCatalogEntry)
+	}
+	for _, e := range entries {
+		s.errorCodes[e.Code] = e
+	}
+}
+
+// errorCatalog returns all known error codes, ordered by code.
+func (s *Server) errorCatalog() []ErrorCatalogEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	catalog := make([]ErrorCatalogEntry, 0, len(builtinErrors)+len(s.errorCodes))
+	for _, e := range builtinErrors {
+		if _, ok := s.errorCodes[e.Code]; !ok {
+			catalog = append(catalog, e)
+		}
+	}
+	for _, e := range s.errorCodes {
+		catalog = append(catalog, e)
+	}
+	slices.SortFunc(catalog, func(a, b ErrorCatalogEntry) int { return a.Code - b.Code })
+	return catalog
+}
+
+// ErrorCatalog returns the error codes used by the server, their meaning and whether
+// calls failing with them may be retried.
+func (s *RPCService) ErrorCatalog() []ErrorCatalogEntry {
+	return s.server.errorCatalog()
+}
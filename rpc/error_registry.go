@@ -0,0 +1,108 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrorRegistry maps errors returned by methods onto stable JSON-RPC error codes, so
+// services can return plain Go errors while clients see documented codes. Mapping is
+// done by a middleware, which makes the codes the same on all transports:
+//
+//	errs := rpc.NewErrorRegistry()
+//	errs.Register(ErrUnknownBlock, rpc.ErrorCatalogEntry{Code: 4001, Name: "unknown block"})
+//	server.UseMiddleware("errors", errs.Middleware(), rpc.WithPriority(100))
+//	server.RegisterErrorCodes(errs.Entries()...)
+//
+// An ErrorRegistry is safe for concurrent use.
+type ErrorRegistry struct {
+	mu       sync.RWMutex
+	mappings []errorMapping
+}
+
+type errorMapping struct {
+	target error
+	entry  ErrorCatalogEntry
+}
+
+// NewErrorRegistry creates an empty registry.
+func NewErrorRegistry() *ErrorRegistry {
+	return new(ErrorRegistry)
+}
+
+// Register maps errors matching target, as reported by errors.Is, to the code of entry.
+// When an error matches several targets, the one registered first is used.
+func (r *ErrorRegistry) Register(target error, entry ErrorCatalogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mappings = append(r.mappings, errorMapping{target, entry})
+}
+
+// Map returns the error to send to the client for err. If err matches a registered
+// target, the result has the registered code and the message of err, and keeps the data
+// of a DataError in the chain of err. Errors which carry a code themselves and errors
+// without a mapping are returned unchanged.
+func (r *ErrorRegistry) Map(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(Error); ok {
+		return err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, m := range r.mappings {
+		if !errors.Is(err, m.target) {
+			continue
+		}
+		var data interface{}
+		var de DataError
+		if errors.As(err, &de) {
+			data = de.ErrorData()
+		}
+		return WrapError(m.entry.Code, err, data)
+	}
+	return err
+}
+
+// Entries returns the catalog entries of the registered codes, for use with
+// Server.RegisterErrorCodes.
+func (r *ErrorRegistry) Entries() []ErrorCatalogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]ErrorCatalogEntry, len(r.mappings))
+	for i, m := range r.mappings {
+		entries[i] = m.entry
+	}
+	return entries
+}
+
+// Middleware returns a middleware which maps the errors of calls using Map. Install it
+// as the outermost middleware to also map the errors of other middlewares.
+func (r *ErrorRegistry) Middleware() Middleware {
+	return func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+		result := next(ctx, method, args)
+		if result != nil && result.Error != nil {
+			result.Error = r.Map(result.Error)
+		}
+		return result
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var errUnknownThing = errors.New("unknown thing")
+
+type errorService struct{}
+
+func (errorService) Lookup(kind string) error {
+	switch kind {
+	case "wrapped":
+		return fmt.Errorf("lookup failed: %w", errUnknownThing)
+	case "data":
+		return NewDataError(4100, "bad thing", map[string]int{"id": 7})
+	case "coded":
+		return NewError(4200, "coded")
+	}
+	return errors.New("plain")
+}
+
+func TestErrorRegistry(t *testing.T) {
+	errs := NewErrorRegistry()
+	errs.Register(errUnknownThing, ErrorCatalogEntry{Code: 4001, Name: "unknown thing"})
+	server := NewServer()
+	defer server.Stop()
+	server.RegisterName("errs", errorService{})
+	server.SetMiddlewares([]Middleware{errs.Middleware()})
+	server.RegisterErrorCodes(errs.Entries()...)
+	client := DialInProc(server)
+	defer client.Close()
+
+	tests := []struct {
+		kind    string
+		code    int
+		message string
+		data    interface{}
+	}{
+		{"wrapped", 4001, "lookup failed: unknown thing", nil},
+		{"data", 4100, "bad thing", map[string]interface{}{"id": float64(7)}},
+		{"coded", 4200, "coded", nil},
+		{"plain", errcodeDefault, "plain", nil},
+	}
+	for _, test := range tests {
+		err := client.Call(nil, "errs_lookup", test.kind)
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("%s: not an rpc.Error: %v", test.kind, err)
+		}
+		if rpcErr.ErrorCode() != test.code || rpcErr.Error() != test.message {
+			t.Errorf("%s: got error %d %q, want %d %q", test.kind, rpcErr.ErrorCode(), rpcErr.Error(), test.code, test.message)
+		}
+		var data interface{}
+		if de, ok := err.(DataError); ok {
+			data = de.ErrorData()
+		}
+		if !reflect.DeepEqual(data, test.data) {
+			t.Errorf("%s: wrong error data %v", test.kind, data)
+		}
+	}
+
+	var catalog []ErrorCatalogEntry
+	if err := client.Call(&catalog, "rpc_errorCatalog"); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range catalog {
+		found = found || e.Code == 4001 && e.Name == "unknown thing"
+	}
+	if !found {
+		t.Fatal("registered code missing from error catalog")
+	}
+}
+
+func TestErrorRegistryMap(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	errs := NewErrorRegistry()
+	errs.Register(errA, ErrorCatalogEntry{Code: 1})
+	errs.Register(errB, ErrorCatalogEntry{Code: 2})
+
+	both := fmt.Errorf("%w, %w", errB, errA)
+	mapped := errs.Map(both)
+	if code := mapped.(Error).ErrorCode(); code != 1 {
+		t.Fatalf("wrong code %d, first registered target should win", code)
+	}
+	if !errors.Is(mapped, errB) {
+		t.Fatal("mapped error doesn't wrap the original error")
+	}
+	if mapped := errs.Map(WrapError(5, errA, "data")); mapped.(Error).ErrorCode() != 5 {
+		t.Fatal("error with code was remapped")
+	}
+	if errs.Map(nil) != nil {
+		t.Fatal("nil error mapped to non-nil")
+	}
+	if other := errors.New("other"); errs.Map(other) != other {
+		t.Fatal("unmapped error changed")
+	}
+}
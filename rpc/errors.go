@@ -16,7 +16,11 @@
 
 package rpc
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
 
 // HTTPError is returned by client operations when the HTTP status code of the
 // response is not a 2xx status.
@@ -24,6 +28,7 @@ type HTTPError struct {
 	StatusCode int
 	Status     string
 	Body       []byte
+	Header     http.Header // response headers
 }
 
 func (err HTTPError) Error() string {
@@ -55,22 +60,48 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(duplicateIDError)
+	_ Error = new(codedError)
 )
 
 const (
-	errcodeDefault          = -32000
-	errcodeTimeout          = -32002
-	errcodeResponseTooLarge = -32003
-	errcodePanic            = -32603
-	errcodeMarshalError     = -32603
+	errcodeDefault             = -32000
+	errcodeMethodNotFound      = -32601
+	errcodeTimeout             = -32002
+	errcodeResponseTooLarge    = -32003
+	errcodeDeadlineSkipped     = -32004
+	errcodeLimitExceeded       = -32005
+	errcodeMemoryCeiling       = -32006
+	errcodeQuotaExceeded       = -32007
+	errcodeUnknownSubscription = -32008
+	errcodeRateLimited         = -32009
+	errcodeReplayInProgress    = -32010
+	errcodeRedirect            = -32011
+	errcodeUnauthenticated     = -32012
+	errcodeBehindConsistency   = -32014
+	errcodeBatchTooLarge       = -32600
+	errcodePanic               = -32603
+	errcodeMarshalError        = -32603
 
 	legacyErrcodeNotificationsUnsupported = -32001
 )
 
 const (
-	errMsgTimeout          = "request timed out"
-	errMsgResponseTooLarge = "response too large"
-	errMsgBatchTooLarge    = "batch too large"
+	errMsgTimeout             = "request timed out"
+	errMsgResponseTooLarge    = "response too large"
+	errMsgBatchTooLarge       = "batch too large"
+	errMsgDuplicateID         = "duplicate request id"
+	errMsgDeadlineSkipped     = "deadline exceeded before execution"
+	errMsgBehindConsistency   = "backend behind consistency token"
+	errMsgMemoryCeiling       = "memory ceiling exceeded"
+	errMsgQuotaExceeded       = "quota exceeded"
+	errMsgUnknownSubscription = "unknown subscription"
+	errMsgRateLimited         = "rate limit exceeded"
+	errMsgReplayInProgress    = "replayed request still in progress"
+	errMsgRedirect            = "call redirected"
+	errMsgAccessDenied        = "access denied"
+	errMsgUnauthenticated     = "authentication required"
+	errMsgLimitExceeded       = "limit exceeded"
 )
 
 type methodNotFoundError struct{ method string }
@@ -154,3 +185,48 @@ type internalServerError struct {
 func (e *internalServerError) ErrorCode() int { return e.code }
 
 func (e *internalServerError) Error() string { return e.message }
+
+// duplicateIDError is returned for calls which reuse the id of another call that is
+// still pending on the same connection, or of an earlier call in the same batch.
+// The offending id is included as error data.
+type duplicateIDError struct{ id json.RawMessage }
+
+func (e *duplicateIDError) ErrorCode() int { return -32600 }
+
+func (e *duplicateIDError) Error() string { return errMsgDuplicateID }
+
+func (e *duplicateIDError) ErrorData() interface{} { return e.id }
+
+// NewError returns an error with the given JSON-RPC error code and message. Methods can
+// return it to choose the error code sent to the client.
+func NewError(code int, message string) error {
+	return &codedError{code: code, message: message}
+}
+
+// NewDataError is like NewError, and additionally sends data as the "data" member of
+// the JSON-RPC error. The data must be encodable as JSON.
+func NewDataError(code int, message string, data interface{}) error {
+	return &codedError{code: code, message: message, data: data}
+}
+
+// WrapError returns an error with the given code and data which wraps err. The message
+// sent to the client is the message of err. The data may be nil.
+func WrapError(code int, err error, data interface{}) error {
+	return &codedError{code: code, message: err.Error(), data: data, cause: err}
+}
+
+// codedError is the error created by NewError, NewDataError and WrapError.
+type codedError struct {
+	code    int
+	message string
+	data    interface{}
+	cause   error
+}
+
+func (e *codedError) ErrorCode() int { return e.code }
+
+func (e *codedError) Error() string { return e.message }
+
+func (e *codedError) ErrorData() interface{} { return e.data }
+
+func (e *codedError) Unwrap() error { return e.cause }
@@ -0,0 +1,179 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrGoroutineBudget is returned by Go when the call has started as many goroutines as
+// the execution guard of the server allows.
+var ErrGoroutineBudget = errors.New("goroutine budget of call exhausted")
+
+var errCallReturned = errors.New("call has already returned")
+
+const defaultLeakGracePeriod = time.Second
+
+// ExecutionGuard contains method handlers which start goroutines. It limits the number
+// of goroutines a call may start through Go, and reports goroutines which keep running
+// after the call has returned.
+type ExecutionGuard struct {
+	// MaxGoroutines is the number of goroutines a single call may start. Zero means no
+	// limit.
+	MaxGoroutines int
+
+	// LeakGracePeriod is the time goroutines have to exit after the call returns before
+	// they are reported as leaked. The default is one second.
+	LeakGracePeriod time.Duration
+
+	// OnLeak is called with the number of goroutines of a call which were still running
+	// at the end of the grace period. Leaks are also logged.
+	OnLeak func(method string, running int)
+}
+
+// SetExecutionGuard enables the execution guard for all calls. Passing nil disables it.
+func (s *Server) SetExecutionGuard(guard *ExecutionGuard) {
+	var g *ExecutionGuard
+	if guard != nil {
+		cpy := *guard
+		if cpy.LeakGracePeriod <= 0 {
+			cpy.LeakGracePeriod = defaultLeakGracePeriod
+		}
+		g = &cpy
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.guard = g })
+}
+
+type callGuardKey struct{}
+
+// callGuard tracks the goroutines started by a single call.
+type callGuard struct {
+	config *ExecutionGuard
+	method string
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	started  int
+	running  int
+	returned bool
+	done     chan struct{} // closed when running drops to zero after return
+}
+
+func newCallGuard(ctx context.Context, config *ExecutionGuard, method string) *callGuard {
+	g := &callGuard{config: config, method: method, done: make(chan struct{})}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	return g
+}
+
+// Go runs fn on a new goroutine on behalf of the call which ctx belongs to. The context
+// passed to fn is canceled when the call returns, and fn is expected to exit then. When
+// the server has an execution guard (see Server.SetExecutionGuard), the number of
+// goroutines per call is limited and Go returns ErrGoroutineBudget if the limit is
+// reached. Goroutines which are still running some time after the call has returned
+// are reported as leaked. A panic in fn is logged instead of crashing the process.
+//
+// Outside of guarded calls, Go simply starts fn with ctx.
+func Go(ctx context.Context, fn func(ctx context.Context)) error {
+	g, _ := ctx.Value(callGuardKey{}).(*callGuard)
+	if g == nil {
+		go fn(ctx)
+		return nil
+	}
+	if err := g.add(); err != nil {
+		return err
+	}
+	go func() {
+		defer g.release()
+		defer func() {
+			if err := recover(); err != nil {
+				const size = 64 << 10
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+				log.Error("Goroutine of RPC method " + g.method + " crashed: " + fmt.Sprintf("%v\n%s", err, buf))
+			}
+		}()
+		fn(g.ctx)
+	}()
+	return nil
+}
+
+func (g *callGuard) add() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case g.returned:
+		return errCallReturned
+	case g.config.MaxGoroutines > 0 && g.started >= g.config.MaxGoroutines:
+		return ErrGoroutineBudget
+	}
+	g.started++
+	g.running++
+	return nil
+}
+
+func (g *callGuard) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.running--
+	if g.returned && g.running == 0 {
+		close(g.done)
+	}
+}
+
+// finish is called when the call has returned. It cancels the context of the call's
+// goroutines and reports those which do not exit within the grace period.
+func (g *callGuard) finish() {
+	g.cancel()
+	g.mu.Lock()
+	g.returned = true
+	running := g.running
+	if running == 0 {
+		close(g.done)
+	}
+	g.mu.Unlock()
+	if running == 0 {
+		return
+	}
+	go func() {
+		timer := time.NewTimer(g.config.LeakGracePeriod)
+		defer timer.Stop()
+		select {
+		case <-g.done:
+			return
+		case <-timer.C:
+		}
+		g.mu.Lock()
+		running := g.running
+		g.mu.Unlock()
+		if running == 0 {
+			return
+		}
+		leakedGoroutinesCounter.Inc(int64(running))
+		log.Warn("RPC method left goroutines running", "method", g.method, "count", running)
+		if g.config.OnLeak != nil {
+			g.config.OnLeak(g.method, running)
+		}
+	}()
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type guardTestService struct {
+	release chan struct{}
+}
+
+func (s *guardTestService) Spawn(ctx context.Context, n int) (int, error) {
+	for i := 0; i < n; i++ {
+		if err := Go(ctx, func(ctx context.Context) { <-ctx.Done() }); err != nil {
+			return i, err
+		}
+	}
+	return n, nil
+}
+
+func (s *guardTestService) Leak(ctx context.Context) error {
+	return Go(ctx, func(context.Context) { <-s.release })
+}
+
+func (s *guardTestService) Crash(ctx context.Context) error {
+	return Go(ctx, func(context.Context) { panic("boom") })
+}
+
+func TestExecutionGuardBudget(t *testing.T) {
+	t.Parallel()
+
+	leaks := make(chan string, 1)
+	server := newTestServer()
+	defer server.Stop()
+	svc := &guardTestService{release: make(chan struct{})}
+	defer close(svc.release)
+	server.RegisterName("guard", svc)
+	server.SetExecutionGuard(&ExecutionGuard{
+		MaxGoroutines:   3,
+		LeakGracePeriod: 50 * time.Millisecond,
+		OnLeak:          func(method string, running int) { leaks <- method },
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var n int
+	if err := client.Call(&n, "guard_spawn", 3); err != nil || n != 3 {
+		t.Fatalf("spawn within budget: n=%d, err=%v", n, err)
+	}
+	err := client.Call(&n, "guard_spawn", 5)
+	if err == nil || !strings.Contains(err.Error(), ErrGoroutineBudget.Error()) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	// Goroutines which exit when the call returns are not reported.
+	select {
+	case method := <-leaks:
+		t.Fatalf("unexpected leak report for %s", method)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestExecutionGuardLeak(t *testing.T) {
+	t.Parallel()
+
+	type leak struct {
+		method  string
+		running int
+	}
+	leaks := make(chan leak, 1)
+	server := newTestServer()
+	defer server.Stop()
+	svc := &guardTestService{release: make(chan struct{})}
+	defer close(svc.release)
+	server.RegisterName("guard", svc)
+	server.SetExecutionGuard(&ExecutionGuard{
+		LeakGracePeriod: 10 * time.Millisecond,
+		OnLeak:          func(method string, running int) { leaks <- leak{method, running} },
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "guard_leak"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case l := <-leaks:
+		if l.method != "guard_leak" || l.running != 1 {
+			t.Fatalf("wrong leak report %+v", l)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("leak not reported")
+	}
+
+	// Panics in guarded goroutines are contained.
+	if err := client.Call(nil, "guard_crash"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoWithoutGuard(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &guardTestService{release: make(chan struct{})}
+	defer close(svc.release)
+	server.RegisterName("guard", svc)
+	client := DialInProc(server)
+	defer client.Close()
+	var n int
+	if err := client.Call(&n, "guard_spawn", 10); err != nil || n != 10 {
+		t.Fatalf("n=%d, err=%v", n, err)
+	}
+}
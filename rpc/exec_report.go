@@ -0,0 +1,104 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"time"
+)
+
+// ExecutionReportHeader is the HTTP request header which asks the server to include
+// execution reports in all responses to the request.
+const ExecutionReportHeader = "X-Rpc-Timing"
+
+// ExecutionReport is the server-side timing breakdown of a call. Comparing it to the
+// total call latency helps to distinguish server slowness from network slowness.
+type ExecutionReport struct {
+	QueueWait time.Duration // time from receiving the request to starting the call
+	Execution time.Duration // time spent running the method
+	Encode    time.Duration // time spent encoding the result
+}
+
+type executionReportJSON struct {
+	QueueWaitNs int64 `json:"queueWaitNs"`
+	ExecutionNs int64 `json:"executionNs"`
+	EncodeNs    int64 `json:"encodeNs"`
+}
+
+type executionReportKey struct{}
+
+// SetExecutionReports enables execution reports. When enabled, the server appends a
+// timing breakdown to the "ext" member of responses to calls which ask for it, either
+// through the "ext" member of the request or the ExecutionReportHeader HTTP header.
+// Clients can request reports using WithExecutionReports.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetExecutionReports(enabled bool) {
+	s.executionReports = enabled
+}
+
+// WithExecutionReports makes the client ask the server for execution reports of calls
+// and batch calls. fn is invoked with the report of every response that carries one.
+// The server must have execution reports enabled, see Server.SetExecutionReports.
+func WithExecutionReports(fn func(method string, report ExecutionReport)) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.executionReportFn = fn
+	})
+}
+
+// wantsExecutionReport reports whether the sender of msg asked for an execution report.
+func (msg *jsonrpcMessage) wantsExecutionReport(ctx context.Context) bool {
+	if requested, _ := ctx.Value(executionReportKey{}).(bool); requested {
+		return true
+	}
+	return msg.requestExt().Timing
+}
+
+// addExecutionReport stores the execution report of the call in the response.
+func (msg *jsonrpcMessage) addExecutionReport(queueWait time.Duration) {
+	var report executionReportJSON
+	report.QueueWaitNs = int64(queueWait)
+	if msg.report != nil {
+		report.ExecutionNs = int64(msg.report.Execution)
+		report.EncodeNs = int64(msg.report.Encode)
+	}
+	msg.updateResponseExt(func(ext *responseExt) { ext.Timing = &report })
+}
+
+// executionReport returns the execution report contained in a response.
+func (msg *jsonrpcMessage) executionReport() (ExecutionReport, bool) {
+	ext := msg.responseExt()
+	if ext.Timing == nil {
+		return ExecutionReport{}, false
+	}
+	return ExecutionReport{
+		QueueWait: time.Duration(ext.Timing.QueueWaitNs),
+		Execution: time.Duration(ext.Timing.ExecutionNs),
+		Encode:    time.Duration(ext.Timing.EncodeNs),
+	}, true
+}
+
+// reportExecution passes the execution report of resp to the configured callback.
+func (c *Client) reportExecution(method string, resp *jsonrpcMessage) {
+	if c.executionReportFn == nil {
+		return
+	}
+	if report, ok := resp.executionReport(); ok {
+		c.executionReportFn(method, report)
+	}
+}
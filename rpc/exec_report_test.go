@@ -0,0 +1,126 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExecutionReports(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	server.SetExecutionReports(true)
+	defer server.Stop()
+
+	var (
+		mu      sync.Mutex
+		reports = make(map[string]ExecutionReport)
+	)
+	client := dialInProcWithOptions(server, WithExecutionReports(func(method string, r ExecutionReport) {
+		mu.Lock()
+		reports[method] = r
+		mu.Unlock()
+	}))
+	defer client.Close()
+
+	if err := client.Call(nil, "test_sleep", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []any{"x", 1}, Result: new(echoResult)},
+		{Method: "test_returnError", Result: new(any)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if r, ok := reports["test_sleep"]; !ok {
+		t.Fatal("no report for test_sleep")
+	} else if r.Execution < 20*time.Millisecond {
+		t.Errorf("execution time %v too short", r.Execution)
+	}
+	for _, method := range []string{"test_echo", "test_returnError"} {
+		if _, ok := reports[method]; !ok {
+			t.Errorf("no report for %s", method)
+		}
+	}
+}
+
+func TestExecutionReportsDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+
+	var called bool
+	client := dialInProcWithOptions(server, WithExecutionReports(func(string, ExecutionReport) {
+		called = true
+	}))
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Fatal("report received although server has reports disabled")
+	}
+}
+
+func TestExecutionReportHeader(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	server.SetExecutionReports(true)
+	defer server.Stop()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`
+	for _, header := range []bool{false, true} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		if header {
+			req.Header.Set(ExecutionReportHeader, "1")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		buf.ReadFrom(resp.Body)
+		resp.Body.Close()
+
+		var msg jsonrpcMessage
+		if err := json.Unmarshal(buf.Bytes(), &msg); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := msg.executionReport(); ok != header {
+			t.Errorf("header %t: report present = %t, response %s", header, ok, buf.Bytes())
+		}
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The gRPC transport maps JSON-RPC onto a generic gRPC service, "jsonrpc.JSONRPC", using
+// the JSON codec (content type "application/grpc+json"). Every gRPC message contains a
+// JSON-RPC message or batch. The service has two methods:
+//
+//   - Call is a unary method. Its request is a JSON-RPC call or batch, its response is
+//     the JSON-RPC response.
+//   - Stream is a bidirectional streaming method. The stream behaves like a websocket
+//     connection: it carries calls, responses and subscription notifications in both
+//     directions.
+//
+// Clients built with other gRPC libraries can use the service by registering a codec
+// named "json" which passes the message bytes through unchanged.
+const (
+	GRPCServiceName = "jsonrpc.JSONRPC"
+
+	grpcCallPath    = "/" + GRPCServiceName + "/Call"
+	grpcStreamPath  = "/" + GRPCServiceName + "/Stream"
+	grpcContentType = "application/grpc+json"
+	grpcFrameHeader = 5 // compression flag and message length
+)
+
+// gRPC status codes used by the transport.
+const (
+	grpcStatusOK              = 0
+	grpcStatusInvalid         = 3
+	grpcStatusResExhausted    = 8
+	grpcStatusUnimplemented   = 12
+	grpcStatusUnauthenticated = 16
+)
+
+var (
+	errGRPCCompressed   = errors.New("compressed gRPC messages are not supported")
+	errGRPCMessageLimit = errors.New("gRPC message exceeds size limit")
+)
+
+// grpcStatusError is returned by the client when a stream ends with a non-OK status.
+type grpcStatusError struct {
+	code    int
+	message string
+}
+
+func (e *grpcStatusError) Error() string {
+	return fmt.Sprintf("grpc status %d: %s", e.code, e.message)
+}
+
+// GRPCHandler returns a handler serving the gRPC transport, see GRPCServiceName. gRPC
+// requires HTTP/2, so the handler must be served by an HTTP server with TLS, or behind a
+// proxy terminating HTTP/2.
+//
+// The handler can share a listener with the HTTP transport by routing requests with a
+// content type starting with "application/grpc" to it.
+func (s *Server) GRPCHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if ct := r.Header.Get("content-type"); ct != grpcContentType {
+			http.Error(w, "unsupported content type "+strconv.Quote(ct), http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("content-type", grpcContentType)
+		principal, ok := s.authenticate(r, func(msg string) {
+			w.WriteHeader(http.StatusOK)
+			setGRPCStatus(w, grpcStatusUnauthenticated, msg)
+		})
+		if !ok {
+			return
+		}
+		info := grpcPeerInfo(r, principal)
+		if err := s.admit(&info, r.Header); err != nil {
+			w.WriteHeader(http.StatusOK)
+			setGRPCStatus(w, grpcStatusPermissionDenied, err.Error())
+			return
+		}
+		switch r.URL.Path {
+		case grpcCallPath:
+			s.serveGRPCCall(w, r, info)
+		case grpcStreamPath:
+			s.serveGRPCStream(w, r, info)
+		default:
+			setGRPCStatus(w, grpcStatusUnimplemented, "unknown method "+r.URL.Path)
+		}
+	})
+}
+
+// serveGRPCCall serves a unary call.
+func (s *Server) serveGRPCCall(w http.ResponseWriter, r *http.Request, info PeerInfo) {
+	ctx := r.Context()
+	if timeout, ok := parseGRPCTimeout(r.Header.Get("grpc-timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	ctx = context.WithValue(ctx, peerInfoContextKey{}, info)
+	if b := baggageFromHeader(r.Header.Values(BaggageHeader)); len(b) > 0 {
+		ctx = NewContextWithBaggage(ctx, b)
+	}
+	if sc, ok := spanContextFromHeader(r.Header); ok {
+		ctx = NewContextWithSpanContext(ctx, sc)
+	}
+
+	conn := newGRPCConn(r.Body, w, int64(s.httpBodyLimit), info)
+	codec := newGRPCCodec(conn)
+	s.serveSingleRequest(ctx, codec)
+	codec.close()
+	conn.finish(w)
+}
+
+// serveGRPCStream serves a bidirectional stream as a connection.
+func (s *Server) serveGRPCStream(w http.ResponseWriter, r *http.Request, info PeerInfo) {
+	// Send the response headers right away, so the client can start the stream.
+	w.WriteHeader(http.StatusOK)
+	if err := http.NewResponseController(w).Flush(); err != nil {
+		return
+	}
+	conn := newGRPCConn(r.Body, w, int64(s.httpBodyLimit), info)
+	s.ServeCodec(newGRPCCodec(conn), 0)
+	conn.finish(w)
+}
+
+func grpcPeerInfo(r *http.Request, principal *Principal) PeerInfo {
+	info := PeerInfo{Transport: "grpc", RemoteAddr: normalizeRemoteAddr(r.RemoteAddr), Principal: principal}
+	info.HTTP.Version = r.Proto
+	info.HTTP.Host = r.Host
+	info.HTTP.UserAgent = r.Header.Get("User-Agent")
+	return info
+}
+
+// setGRPCStatus sets the status trailers of a response.
+func setGRPCStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", url.PathEscape(message))
+	}
+}
+
+// parseGRPCTimeout parses the value of the grpc-timeout header, e.g. "100m". Timeouts
+// are limited to maxRequestTimeout.
+func parseGRPCTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	unit, ok := units[v[len(v)-1]]
+	if !ok {
+		return 0, false
+	}
+	if n >= int64(maxRequestTimeout/unit) {
+		return maxRequestTimeout, true
+	}
+	return time.Duration(n) * unit, true
+}
+
+// grpcConn reads and writes length-prefixed gRPC messages.
+type grpcConn struct {
+	r        io.Reader
+	w        io.Writer
+	flush    func() error
+	closeFn  func() error
+	limit    int64
+	info     PeerInfo
+	trailers func() http.Header // client side: trailers of the response
+
+	mu        sync.Mutex
+	closed    bool
+	err       error // read error ending the stream
+	errStatus int   // gRPC status code of err
+}
+
+func newGRPCConn(r io.Reader, w http.ResponseWriter, limit int64, info PeerInfo) *grpcConn {
+	rc := http.NewResponseController(w)
+	return &grpcConn{r: r, w: w, flush: rc.Flush, limit: limit, info: info}
+}
+
+// readMessage reads the payload of the next message.
+func (c *grpcConn) readMessage() ([]byte, error) {
+	var hdr [grpcFrameHeader]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		if err == io.EOF {
+			if status := c.status(); status != nil {
+				return nil, status
+			}
+		}
+		return nil, err
+	}
+	if hdr[0] != 0 {
+		return nil, c.fail(grpcStatusUnimplemented, errGRPCCompressed)
+	}
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if c.limit > 0 && int64(size) > c.limit {
+		return nil, c.fail(grpcStatusResExhausted, errGRPCMessageLimit)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// status returns the error for a non-OK status in the trailers of a client stream.
+func (c *grpcConn) status() error {
+	if c.trailers == nil {
+		return nil
+	}
+	t := c.trailers()
+	code, err := strconv.Atoi(t.Get("Grpc-Status"))
+	if err != nil || code == grpcStatusOK {
+		return nil
+	}
+	msg, _ := url.PathUnescape(t.Get("Grpc-Message"))
+	return &grpcStatusError{code: code, message: msg}
+}
+
+// fail records an error which ends the stream, and the status reported for it.
+func (c *grpcConn) fail(status int, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err, c.errStatus = err, status
+	}
+	return err
+}
+
+func (c *grpcConn) writeMessage(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return io.ErrClosedPipe
+	}
+	frame := make([]byte, grpcFrameHeader, grpcFrameHeader+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	if _, err := c.w.Write(append(frame, data...)); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+// Close stops writes to the stream.
+func (c *grpcConn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	if c.closeFn != nil {
+		return c.closeFn()
+	}
+	return nil
+}
+
+func (c *grpcConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *grpcConn) RemoteAddr() string { return c.info.RemoteAddr }
+
+// finish sets the status of a server stream once serving it has ended.
+func (c *grpcConn) finish(w http.ResponseWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.err == nil {
+		setGRPCStatus(w, grpcStatusOK, "")
+	} else {
+		setGRPCStatus(w, c.errStatus, c.err.Error())
+	}
+}
+
+// grpcCodec is the ServerCodec of gRPC streams.
+type grpcCodec struct {
+	*jsonCodec
+	conn *grpcConn
+}
+
+func newGRPCCodec(conn *grpcConn) *grpcCodec {
+	encode := func(v interface{}, isErrorResponse bool) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return conn.writeMessage(data)
+	}
+	decode := func(v interface{}) error {
+		data, err := conn.readMessage()
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(v); err != nil {
+			return conn.fail(grpcStatusInvalid, err)
+		}
+		return nil
+	}
+	return &grpcCodec{jsonCodec: NewFuncCodec(conn, encode, decode).(*jsonCodec), conn: conn}
+}
+
+func (gc *grpcCodec) peerInfo() PeerInfo {
+	return gc.conn.info
+}
+
+// newClientTransportGRPC creates the transport for "grpcs://" URLs. Every connection of
+// the client is a Stream call.
+func newClientTransportGRPC(endpoint string, cfg *clientConfig) (reconnectFunc, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = "https"
+	u.Path = strings.TrimSuffix(u.Path, "/") + grpcStreamPath
+	streamURL := u.String()
+
+	client := cfg.httpClient
+	if client == nil {
+		client = new(http.Client)
+	}
+	connect := func(ctx context.Context) (ServerCodec, error) {
+		// The stream outlives the dial context, it is canceled when the codec is closed.
+		streamCtx, cancel := context.WithCancel(context.Background())
+		pr, pw := io.Pipe()
+		req, err := http.NewRequestWithContext(streamCtx, http.MethodPost, streamURL, pr)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		for key, values := range cfg.httpHeaders {
+			req.Header[key] = values
+		}
+		req.Header.Set("content-type", grpcContentType)
+		req.Header.Set("te", "trailers")
+		if cfg.httpAuth != nil {
+			if err := cfg.httpAuth(req.Header); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+
+		type result struct {
+			resp *http.Response
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := client.Do(req)
+			done <- result{resp, err}
+		}()
+		var resp *http.Response
+		select {
+		case res := <-done:
+			if res.err != nil {
+				cancel()
+				return nil, res.err
+			}
+			resp = res.resp
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			cancel()
+			return nil, HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+
+		conn := &grpcConn{
+			r:        resp.Body,
+			w:        pw,
+			flush:    func() error { return nil },
+			limit:    wsDefaultReadLimit,
+			info:     PeerInfo{Transport: "grpc", RemoteAddr: streamURL},
+			trailers: func() http.Header { return resp.Trailer },
+		}
+		conn.closeFn = func() error {
+			pw.Close()
+			cancel()
+			return resp.Body.Close()
+		}
+		return newGRPCCodec(conn), nil
+	}
+	return connect, nil
+}
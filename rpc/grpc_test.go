@@ -0,0 +1,170 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGRPCStream(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewUnstartedServer(server.GRPCHandler())
+	httpsrv.EnableHTTP2 = true
+	httpsrv.StartTLS()
+	defer httpsrv.Close()
+	url := "grpcs:" + strings.TrimPrefix(httpsrv.URL, "https:")
+	client, err := DialOptions(context.Background(), url, WithHTTPClient(httpsrv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Calls.
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "x", 3); err != nil {
+		t.Fatal(err)
+	}
+	if res.String != "x" || res.Int != 3 {
+		t.Fatalf("wrong result %+v", res)
+	}
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Transport != "grpc" || info.HTTP.Version != "HTTP/2.0" {
+		t.Fatalf("wrong peer info %+v", info)
+	}
+
+	// Subscriptions.
+	nc := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", nc, "someSubscription", 3, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+	for i := 0; i < 3; i++ {
+		select {
+		case v := <-nc:
+			if v != i {
+				t.Fatalf("wrong value %d, want %d", v, i)
+			}
+		case err := <-sub.Err():
+			t.Fatal("subscription error:", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for notification")
+		}
+	}
+}
+
+// grpcFrame encodes a gRPC message.
+func grpcFrame(payload string) []byte {
+	frame := make([]byte, grpcFrameHeader, grpcFrameHeader+len(payload))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func TestGRPCCall(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewUnstartedServer(server.GRPCHandler())
+	httpsrv.EnableHTTP2 = true
+	httpsrv.StartTLS()
+	defer httpsrv.Close()
+	call := func(path string, body []byte) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL+path, bytes.NewReader(body))
+		req.Header.Set("content-type", grpcContentType)
+		req.Header.Set("te", "trailers")
+		req.Header.Set("grpc-timeout", "5S")
+		resp, err := httpsrv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, data
+	}
+
+	resp, data := call("/"+GRPCServiceName+"/Call", grpcFrame(`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`))
+	if status := resp.Trailer.Get("Grpc-Status"); status != "0" {
+		t.Fatalf("wrong grpc-status %q", status)
+	}
+	if len(data) < grpcFrameHeader || int(binary.BigEndian.Uint32(data[1:])) != len(data)-grpcFrameHeader {
+		t.Fatalf("invalid response frame %q", data)
+	}
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(data[grpcFrameHeader:], &msg); err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Result) != `{"String":"x","Int":1,"Args":null}` {
+		t.Fatalf("wrong response %s", data[grpcFrameHeader:])
+	}
+
+	// Unknown gRPC methods and compressed messages are rejected.
+	resp, _ = call("/"+GRPCServiceName+"/Other", nil)
+	if status := resp.Trailer.Get("Grpc-Status"); status != "12" {
+		t.Fatalf("wrong grpc-status %q for unknown method", status)
+	}
+	compressed := grpcFrame(`{}`)
+	compressed[0] = 1
+	resp, _ = call("/"+GRPCServiceName+"/Call", compressed)
+	if status := resp.Trailer.Get("Grpc-Status"); status != "12" {
+		t.Fatalf("wrong grpc-status %q for compressed message", status)
+	}
+}
+
+func TestParseGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		v    string
+		want time.Duration
+		ok   bool
+	}{
+		{"1S", time.Second, true},
+		{"250m", 250 * time.Millisecond, true},
+		{"2H", 2 * time.Hour, true},
+		{"25H", maxRequestTimeout, true},
+		{"99999999H", maxRequestTimeout, true},
+		{"99999999n", 99999999 * time.Nanosecond, true},
+		{"10", 0, false},
+		{"S", 0, false},
+		{"1x", 0, false},
+		{"1234567890S", 0, false},
+	}
+	for _, test := range tests {
+		d, ok := parseGRPCTimeout(test.v)
+		if d != test.want || ok != test.ok {
+			t.Errorf("%q: got %v %v, want %v %v", test.v, d, ok, test.want, test.ok)
+		}
+	}
+}
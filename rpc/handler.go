@@ -23,21 +23,40 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 )
 
-// MethodResult represents the result of a method call
+// MethodResult represents the result of a method call. Middlewares may modify the result
+// returned by the next handler, or return a result of their own without calling next, for
+// example to serve a cached value.
 type MethodResult struct {
+	// Result is the return value of the method. It is encoded as the JSON-RPC result
+	// unless Error is set. Middlewares redacting the result should replace it with a
+	// modified copy, since the value may be shared with the service.
 	Result interface{}
-	Error  error
+
+	// Error is the error returned by the method. The error code and data of the
+	// response are taken from the Error and DataError interfaces, so middlewares can
+	// rewrite the error to change them.
+	Error error
+
+	// Metadata carries values between the middlewares of a call. For example, a caching
+	// middleware can use it to mark cache hits for a logging middleware wrapping it.
+	// Metadata is not sent to the client.
+	Metadata map[string]interface{}
 }
 
-// Middleware defines a function that wraps around method execution
+// Middleware defines a function that wraps around method execution. The request being
+// executed is available through RequestInfoFromContext. Middlewares must not return a nil
+// result.
 type Middleware func(ctx context.Context, method string, args []reflect.Value, next func(ctx context.Context, method string, args []reflect.Value) *MethodResult) *MethodResult
 
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
@@ -61,44 +80,96 @@ type Middleware func(ctx context.Context, method string, args []reflect.Value, n
 //		h.removeRequestOp(op) // timeout, etc.
 //	}
 type handler struct {
-	reg                  *serviceRegistry
-	unsubscribeCb        *callback
-	idgen                func() ID                      // subscription ID generator
-	respWait             map[string]*requestOp          // active client requests
-	clientSubs           map[string]*ClientSubscription // active client subscriptions
-	callWG               sync.WaitGroup                 // pending call goroutines
-	rootCtx              context.Context                // canceled by close()
-	cancelRoot           func()                         // cancel function for rootCtx
-	conn                 jsonWriter                     // where responses will be sent
-	log                  log.Logger
-	allowSubscribe       bool
-	batchRequestLimit    int
-	batchResponseMaxSize int
+	reg               *serviceRegistry
+	unsubscribeCb     *callback
+	idgen             func() ID                      // subscription ID generator
+	respWait          map[string]*requestOp          // active client requests
+	clientSubs        map[string]*ClientSubscription // active client subscriptions
+	callWG            sync.WaitGroup                 // pending call goroutines
+	closer            *connCloser                    // close request of a call, see CloseConnection
+	rootCtx           context.Context                // canceled by close()
+	cancelRoot        func()                         // cancel function for rootCtx
+	store             *ConnStorage                   // connection store, see ConnStore
+	connID            ConnID                         // see ConnIDFromContext
+	conn              jsonWriter                     // where responses will be sent
+	log               log.Logger
+	allowSubscribe    bool
+	batchLimits       *batchServeLimits
+	checkDuplicateIDs bool
+	epoch             string                 // server epoch, set for server connections
+	epochIDs          bool                   // subscription ids start with the epoch
+	synchronousCalls  bool                   // run calls on the reading goroutine
+	diag              *clientDiagnostics     // set for clients with diagnostics enabled
+	events            *serverEvents          // set for server connections
+	notifySeq         *notificationSequencer // set if notifications are numbered
+	subOwner          SubscriptionOwnerFunc  // identifies owners of subscriptions
+	sessions          *sessionRegistry       // set if session resumption is enabled
+	dispatch          *dispatchPool          // delivers notifications, see WithDispatchWorkers
+	checksums         bool                   // add checksums to responses
+	executionReports  bool                   // add execution reports to responses on request
+	canonicalJSON     bool                   // canonicalize results
+	streamResults     bool                   // keep streamed results for the codec, see StreamedResult
+	numberPolicy      NumberPolicy           // for decoding params
+	timeFormat        TimeFormat             // for params and results
+	clock             mclock.Clock           // for timeouts, rate limits and cache expiry
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
+	session    *session // session held by the connection
+
+	challenge challengeState                 // see SetChallengeAuth
+	versions  atomic.Pointer[map[string]int] // selected with rpc_selectVersions
+	running   *connCalls                     // see SetConcurrencyLimits
+
+	idLock     sync.Mutex
+	pendingIDs map[string]struct{} // ids of calls being processed, see checkDuplicateIDs
+
+	// completion of the last call to each ordered namespace, see SetOrderedNamespaces.
+	// This is only accessed by the read loop.
+	orderTail map[string]chan struct{}
 }
 
 type callProc struct {
 	ctx       context.Context
+	cfg       *registryConfig // server config, loaded once per call or batch
 	notifiers []*Notifier
+	received  time.Time // when the request was received
+}
+
+// derive returns a callProc for a single call of cp, using ctx as the call context.
+// Notifiers created by the call must be moved to cp afterwards. This keeps values meant
+// for one call out of the context of the other calls in a batch.
+func (cp *callProc) derive(ctx context.Context) *callProc {
+	return &callProc{ctx: ctx, cfg: cp.cfg, received: cp.received}
 }
 
 func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, batchRequestLimit, batchResponseMaxSize int) *handler {
-	rootCtx, cancelRoot := context.WithCancel(connCtx)
+	store := new(ConnStorage)
+	connID, _ := connCtx.Value(connIDKey{}).(ConnID)
+	if connID == 0 {
+		connID = ConnID(reg.connIDs.Add(1))
+		connCtx = context.WithValue(connCtx, connIDKey{}, connID)
+	}
+	closer := new(connCloser)
+	connCtx = context.WithValue(connCtx, connCloserKey{}, closer)
+	rootCtx, cancelRoot := context.WithCancel(context.WithValue(connCtx, connStoreKey{}, store))
 	h := &handler{
-		reg:                  reg,
-		idgen:                idgen,
-		conn:                 conn,
-		respWait:             make(map[string]*requestOp),
-		clientSubs:           make(map[string]*ClientSubscription),
-		rootCtx:              rootCtx,
-		cancelRoot:           cancelRoot,
-		allowSubscribe:       true,
-		serverSubs:           make(map[ID]*Subscription),
-		log:                  log.Root(),
-		batchRequestLimit:    batchRequestLimit,
-		batchResponseMaxSize: batchResponseMaxSize,
+		reg:            reg,
+		connID:         connID,
+		idgen:          idgen,
+		conn:           conn,
+		respWait:       make(map[string]*requestOp),
+		clientSubs:     make(map[string]*ClientSubscription),
+		rootCtx:        rootCtx,
+		cancelRoot:     cancelRoot,
+		store:          store,
+		closer:         closer,
+		allowSubscribe: true,
+		serverSubs:     make(map[ID]*Subscription),
+		log:            log.Root(),
+		batchLimits:    newBatchServeLimits(batchRequestLimit, batchResponseMaxSize),
+		clock:          mclock.System{},
+		running:        newConnCalls(),
 	}
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
@@ -131,15 +202,21 @@ func (b *batchCallBuffer) nextCall() *jsonrpcMessage {
 	return msg
 }
 
-// pushResponse adds the response to last call returned by nextCall.
-func (b *batchCallBuffer) pushResponse(answer *jsonrpcMessage) {
+// pushResponse adds the response to call msg, which is no longer unprocessed.
+func (b *batchCallBuffer) pushResponse(msg, answer *jsonrpcMessage) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
 	if answer != nil {
 		b.resp = append(b.resp, answer)
 	}
-	b.calls = b.calls[1:]
+	// The calls slice is shared with the handler, so it is copied instead of modified.
+	switch i := slices.Index(b.calls, msg); {
+	case i == 0:
+		b.calls = b.calls[1:]
+	case i > 0:
+		b.calls = append(b.calls[:i:i], b.calls[i+1:]...)
+	}
 }
 
 // write sends the responses.
@@ -186,8 +263,14 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		})
 		return
 	}
-	// Apply limit on total number of requests.
-	if h.batchRequestLimit != 0 && len(msgs) > h.batchRequestLimit {
+	cfg := h.reg.snapshot()
+	if m := cfg.metrics; m != nil {
+		m.BatchReceived(len(msgs))
+	}
+	// Calls exceeding the item limit are answered with an error, unless the batch is
+	// so large that it is rejected as a whole.
+	itemLimit, responseLimit := h.batchLimits.get()
+	if itemLimit != 0 && len(msgs) > maxBatchExcess*itemLimit {
 		h.startCallProc(func(cp *callProc) {
 			h.respondWithBatchTooLarge(cp, msgs)
 		})
@@ -203,46 +286,92 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	if len(calls) == 0 {
 		return
 	}
+	dups := h.reserveCallIDs(calls)
 
 	// Process calls on a goroutine because they may block indefinitely:
-	h.startCallProc(func(cp *callProc) {
+	orderedNS := cfg.orderedNamespaces(calls)
+	ordered := len(orderedNS) > 0
+	h.startOrderedCallProc(calls, orderedNS, func(cp *callProc) {
 		var (
-			timer      *time.Timer
+			timer      mclock.Timer
 			cancel     context.CancelFunc
 			callBuffer = &batchCallBuffer{calls: calls, resp: make([]*jsonrpcMessage, 0, len(calls))}
 		)
 
+		cp.cfg = cfg
 		cp.ctx, cancel = context.WithCancel(cp.ctx)
 		defer cancel()
+		defer h.releaseCallIDs(calls, dups)
+		var span Span
+		if cp.ctx, span = cfg.startSpan(cp.ctx, "rpc.batch"); span != nil {
+			defer span.End(nil)
+		}
 
 		// Cancel the request context after timeout and send an error response. Since the
 		// currently-running method might not return immediately on timeout, we must wait
 		// for the timeout concurrently with processing the request.
+		var deadline mclock.AbsTime
 		if timeout, ok := ContextRequestTimeout(cp.ctx); ok {
-			timer = time.AfterFunc(timeout, func() {
+			deadline = h.clock.Now().Add(timeout)
+			timer = h.clock.AfterFunc(timeout, func() {
 				cancel()
 				err := &internalServerError{errcodeTimeout, errMsgTimeout}
 				callBuffer.respondWithError(cp.ctx, h.conn, err)
 			})
 		}
 
-		responseBytes := 0
-		for {
-			// No need to handle rest of calls if timed out.
-			if cp.ctx.Err() != nil {
-				break
+		handleItem := func(ctx context.Context, index int, msg *jsonrpcMessage) (*jsonrpcMessage, []*Notifier) {
+			if itemLimit != 0 && index >= itemLimit {
+				if msg.isCall() {
+					return msg.errorResponse(&batchLimitError{errcodeBatchTooLarge, errMsgBatchTooLarge, batchLimitItems, itemLimit}), nil
+				}
+				return nil, nil
+			}
+			if dups[msg] {
+				return msg.errorResponse(&duplicateIDError{msg.ID}), nil
 			}
-			msg := callBuffer.nextCall()
-			if msg == nil {
-				break
+			if err := h.checkBatchDeadline(msg, deadline); err != nil {
+				// Skip items that cannot complete before the client gives up.
+				if msg.isCall() {
+					return msg.errorResponse(err), nil
+				}
+				return nil, nil
 			}
-			resp := h.handleCallMsg(cp, msg)
-			callBuffer.pushResponse(resp)
-			if resp != nil && h.batchResponseMaxSize != 0 {
+			item := cp.derive(withBatchInfo(ctx, BatchInfo{Size: len(calls), Index: index}))
+			return h.handleCallMsg(item, msg), item.notifiers
+		}
+		// pushResponse stores the response of a call. It returns false when the
+		// response size limit is exceeded.
+		responseBytes := 0
+		pushResponse := func(msg, resp *jsonrpcMessage) bool {
+			callBuffer.pushResponse(msg, resp)
+			if resp != nil && responseLimit != 0 {
 				responseBytes += len(resp.Result)
-				if responseBytes > h.batchResponseMaxSize {
-					err := &internalServerError{errcodeResponseTooLarge, errMsgResponseTooLarge}
+				if responseBytes > responseLimit {
+					err := &batchLimitError{errcodeResponseTooLarge, errMsgResponseTooLarge, batchLimitResponseBytes, responseLimit}
 					callBuffer.respondWithError(cp.ctx, h.conn, err)
+					return false
+				}
+			}
+			return true
+		}
+
+		if n := cfg.batchConcurrency; n > 1 && len(calls) > 1 && !ordered {
+			inOrder := cfg.batchOrder == BatchOrderRequest
+			h.runBatchConcurrently(cp, cancel, calls, n, inOrder, handleItem, pushResponse)
+		} else {
+			for index := 0; ; index++ {
+				// No need to handle rest of calls if timed out.
+				if cp.ctx.Err() != nil {
+					break
+				}
+				msg := callBuffer.nextCall()
+				if msg == nil {
+					break
+				}
+				resp, notifiers := handleItem(cp.ctx, index, msg)
+				cp.notifiers = append(cp.notifiers, notifiers...)
+				if !pushResponse(msg, resp) {
 					break
 				}
 			}
@@ -256,6 +385,7 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		for _, n := range cp.notifiers {
 			n.activate()
 		}
+		h.closeIfRequested()
 	})
 }
 
@@ -277,7 +407,18 @@ func (h *handler) respondWithBatchTooLarge(cp *callProc, batch []*jsonrpcMessage
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	msgs := []*jsonrpcMessage{msg}
 	h.handleResponses(msgs, func(msg *jsonrpcMessage) {
-		h.startCallProc(func(cp *callProc) {
+		call := []*jsonrpcMessage{msg}
+		if dups := h.reserveCallIDs(call); dups[msg] {
+			h.startCallProc(func(cp *callProc) {
+				resp := msg.errorResponse(&duplicateIDError{msg.ID})
+				h.conn.writeJSON(cp.ctx, resp, true)
+			})
+			return
+		}
+		cfg := h.reg.snapshot()
+		h.startOrderedCallProc(call, cfg.orderedNamespaces(call), func(cp *callProc) {
+			cp.cfg = cfg
+			defer h.releaseCallIDs(call, nil)
 			h.handleNonBatchCall(cp, msg)
 		})
 	})
@@ -286,7 +427,7 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 	var (
 		responded sync.Once
-		timer     *time.Timer
+		timer     mclock.Timer
 		cancel    context.CancelFunc
 	)
 	cp.ctx, cancel = context.WithCancel(cp.ctx)
@@ -296,7 +437,7 @@ func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 	// running method might not return immediately on timeout, we must wait for the
 	// timeout concurrently with processing the request.
 	if timeout, ok := ContextRequestTimeout(cp.ctx); ok {
-		timer = time.AfterFunc(timeout, func() {
+		timer = h.clock.AfterFunc(timeout, func() {
 			cancel()
 			responded.Do(func() {
 				resp := msg.errorResponse(&internalServerError{errcodeTimeout, errMsgTimeout})
@@ -318,15 +459,70 @@ func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 	for _, n := range cp.notifiers {
 		n.activate()
 	}
+	h.closeIfRequested()
+}
+
+// reserveCallIDs marks the ids of the given calls as pending when duplicate id detection
+// is enabled. It returns the calls whose id is already pending on the connection or was
+// used by an earlier call in msgs. These calls must not be executed.
+func (h *handler) reserveCallIDs(msgs []*jsonrpcMessage) (dups map[*jsonrpcMessage]bool) {
+	if !h.checkDuplicateIDs {
+		return nil
+	}
+	h.idLock.Lock()
+	defer h.idLock.Unlock()
+
+	if h.pendingIDs == nil {
+		h.pendingIDs = make(map[string]struct{})
+	}
+	for _, msg := range msgs {
+		if !msg.isCall() {
+			continue
+		}
+		id := string(msg.ID)
+		if _, ok := h.pendingIDs[id]; ok {
+			h.log.Debug("Rejected RPC call with duplicate id", "reqid", idForLog{msg.ID}, "method", logMethod(msg.Method))
+			if dups == nil {
+				dups = make(map[*jsonrpcMessage]bool)
+			}
+			dups[msg] = true
+			continue
+		}
+		h.pendingIDs[id] = struct{}{}
+	}
+	return dups
+}
+
+// releaseCallIDs removes the ids reserved by reserveCallIDs.
+func (h *handler) releaseCallIDs(msgs []*jsonrpcMessage, dups map[*jsonrpcMessage]bool) {
+	if !h.checkDuplicateIDs {
+		return
+	}
+	h.idLock.Lock()
+	defer h.idLock.Unlock()
+
+	for _, msg := range msgs {
+		if msg.isCall() && !dups[msg] {
+			delete(h.pendingIDs, string(msg.ID))
+		}
+	}
 }
 
 // close cancels all requests except for inflightReq and waits for
 // call goroutines to shut down.
 func (h *handler) close(err error, inflightReq *requestOp) {
 	h.cancelAllRequests(err, inflightReq)
+	h.running.stop()
+	if h.dispatch != nil {
+		h.dispatch.stop()
+	}
 	h.callWG.Wait()
 	h.cancelRoot()
-	h.cancelServerSubscriptions(err)
+	if h.sessions == nil || !h.sessions.detach(h) {
+		h.cancelServerSubscriptions(err)
+	}
+	h.store.close()
+	h.reg.connectionClosed(h.connID)
 }
 
 // addRequestOp registers a request operation.
@@ -343,6 +539,15 @@ func (h *handler) removeRequestOp(op *requestOp) {
 	}
 }
 
+// abandonRequestOp stops waiting for the IDs of a request whose caller has given up
+// waiting for the response.
+func (h *handler) abandonRequestOp(op *requestOp) {
+	h.removeRequestOp(op)
+	if h.diag != nil {
+		h.diag.addTimeout(op)
+	}
+}
+
 // cancelAllRequests unblocks and removes pending requests and active subscriptions.
 func (h *handler) cancelAllRequests(err error, inflightReq *requestOp) {
 	didClose := make(map[*requestOp]bool)
@@ -362,6 +567,10 @@ func (h *handler) cancelAllRequests(err error, inflightReq *requestOp) {
 	}
 	for id, sub := range h.clientSubs {
 		delete(h.clientSubs, id)
+		if sub.client.canResubscribe(err) {
+			go sub.client.resubscribe(sub, err)
+			continue
+		}
 		sub.close(err)
 	}
 }
@@ -383,21 +592,36 @@ func (h *handler) cancelServerSubscriptions(err error) {
 	defer h.subLock.Unlock()
 
 	for id, s := range h.serverSubs {
-		s.err <- err
-		close(s.err)
+		s.close(err)
 		delete(h.serverSubs, id)
 	}
 }
 
 // startCallProc runs fn in a new goroutine and starts tracking it in the h.calls wait group.
+// In synchronous mode, fn runs on the calling goroutine instead.
 func (h *handler) startCallProc(fn func(*callProc)) {
+	h.startCallProcFor(nil, fn)
+}
+
+// startCallProcFor is like startCallProc, but fn processes the given calls. When the
+// server has a call pool, fn runs on a worker selected by the namespace of the calls.
+func (h *handler) startCallProcFor(calls []*jsonrpcMessage, fn func(*callProc)) {
 	h.callWG.Add(1)
-	go func() {
+	received := time.Now()
+	run := func() {
 		ctx, cancel := context.WithCancel(h.rootCtx)
 		defer h.callWG.Done()
 		defer cancel()
-		fn(&callProc{ctx: ctx})
-	}()
+		fn(&callProc{ctx: ctx, received: received})
+	}
+	if h.synchronousCalls {
+		run()
+		return
+	}
+	if pool := h.reg.workers.Load(); pool != nil && pool.submit(poolNamespace(calls), run) {
+		return
+	}
+	go run()
 }
 
 // handleResponses processes method call responses.
@@ -407,6 +631,9 @@ func (h *handler) handleResponses(batch []*jsonrpcMessage, handleCall func(*json
 		op := h.respWait[string(msg.ID)]
 		if op == nil {
 			h.log.Debug("Unsolicited RPC response", "reqid", idForLog{msg.ID})
+			if h.diag != nil {
+				h.diag.unexpectedResponse(msg)
+			}
 			return
 		}
 		resolvedops = append(resolvedops, op)
@@ -419,17 +646,23 @@ func (h *handler) handleResponses(batch []*jsonrpcMessage, handleCall func(*json
 			if msg.Error != nil {
 				op.err = msg.Error
 			} else {
-				op.err = json.Unmarshal(msg.Result, &op.sub.subid)
+				var id string
+				op.err = json.Unmarshal(msg.Result, &id)
 				if op.err == nil {
-					go op.sub.run()
-					h.clientSubs[op.sub.subid] = op.sub
+					h.startClientSubscription(op.sub, id)
 				}
 			}
 		}
 
 		if !op.hadResponse {
 			op.hadResponse = true
-			op.resp <- batch
+			if len(op.ids) == 1 && len(batch) > 1 {
+				// The server may combine responses to unrelated requests into
+				// one array, so single calls only receive their own response.
+				op.resp <- []*jsonrpcMessage{msg}
+			} else {
+				op.resp <- batch
+			}
 		}
 	}
 
@@ -445,9 +678,16 @@ func (h *handler) handleResponses(batch []*jsonrpcMessage, handleCall func(*json
 				h.handleSubscriptionResult(msg)
 				continue
 			}
+			if msg.Method == drainNoticeMethod {
+				h.log.Debug("Server is draining", "params", string(msg.Params))
+				continue
+			}
 			handleCall(msg)
 
 		default:
+			if h.diag != nil && !msg.isCall() {
+				h.diag.report(EventProtocolViolation, msg.ID, errors.New("invalid message"))
+			}
 			handleCall(msg)
 		}
 	}
@@ -462,10 +702,21 @@ func (h *handler) handleSubscriptionResult(msg *jsonrpcMessage) {
 	var result subscriptionResult
 	if err := json.Unmarshal(msg.Params, &result); err != nil {
 		h.log.Debug("Dropping invalid subscription message")
+		if h.diag != nil {
+			h.diag.report(EventProtocolViolation, nil, fmt.Errorf("invalid subscription message: %v", err))
+		}
 		return
 	}
-	if h.clientSubs[result.ID] != nil {
-		h.clientSubs[result.ID].deliver(result.Result)
+	if sub := h.clientSubs[result.ID]; sub != nil {
+		data := result.Result
+		if sub.sequenced {
+			data, _ = json.Marshal(SequencedNotification{Seq: result.Seq, Result: result.Result})
+		}
+		if h.dispatch != nil {
+			h.dispatch.deliver(sub, data)
+		} else {
+			sub.deliver(data)
+		}
 	}
 }
 
@@ -474,22 +725,69 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 	start := time.Now()
 	switch {
 	case msg.isNotification():
-		h.handleCall(ctx, msg)
-		h.log.Debug("Served "+msg.Method, "duration", time.Since(start))
+		class := ctx.cfg.classify(ctx.ctx, msg)
+		finish := h.instrumentCall(ctx.cfg, msg)
+		resp := h.handleCall(ctx, msg, class)
+		finish(resp)
+		if audit := ctx.cfg.audit; audit != nil {
+			audit.recordCall(ctx.ctx, msg, resp, start)
+		}
+		if class != "" {
+			h.log.Debug("Served "+logMethod(msg.Method), "class", class, "duration", time.Since(start))
+		} else {
+			h.log.Debug("Served "+logMethod(msg.Method), "duration", time.Since(start))
+		}
 		return nil
 
 	case msg.isCall():
-		resp := h.handleCall(ctx, msg)
+		class := ctx.cfg.classify(ctx.ctx, msg)
+		finish := h.instrumentCall(ctx.cfg, msg)
+		var resp *jsonrpcMessage
+		if replay := ctx.cfg.replay; replay != nil {
+			safety := replay.safety(msg.Method)
+			if safety == replayUnsafe {
+				resp = replay.run(ctx.ctx, msg, func() *jsonrpcMessage { return h.handleCall(ctx, msg, class) })
+			} else {
+				resp = h.handleCall(ctx, msg, class)
+			}
+			resp.replay = safety
+		} else {
+			resp = h.handleCall(ctx, msg, class)
+		}
+		finish(resp)
+		ctx.cfg.consistency.addConsistencyToken(msg, resp)
+		if h.executionReports && msg.wantsExecutionReport(ctx.ctx) {
+			resp.addExecutionReport(start.Sub(ctx.received))
+		}
 		var logctx []any
-		logctx = append(logctx, "reqid", idForLog{msg.ID}, "duration", time.Since(start))
+		logctx = append(logctx, "reqid", idForLog{msg.ID})
+		if class != "" {
+			logctx = append(logctx, "class", class)
+		}
+		logctx = append(logctx, "duration", time.Since(start))
 		if resp.Error != nil {
 			logctx = append(logctx, "err", resp.Error.Message)
 			if resp.Error.Data != nil {
 				logctx = append(logctx, "errdata", formatErrorData(resp.Error.Data))
 			}
-			h.log.Warn("Served "+msg.Method, logctx...)
+			if ctx.cfg.logParams {
+				logctx = append(logctx, "params", h.reg.formatParams(msg.Method, msg.Params))
+			}
+			h.log.Warn("Served "+logMethod(msg.Method), logctx...)
 		} else {
-			h.log.Debug("Served "+msg.Method, logctx...)
+			h.log.Debug("Served "+logMethod(msg.Method), logctx...)
+		}
+		if h.canonicalJSON && resp.Result != nil {
+			if enc, err := canonicalizeJSON(resp.Result); err == nil {
+				resp.Result = enc
+			}
+		}
+		if h.checksums {
+			resp.addChecksum()
+		}
+		h.reg.capture.record(ctx.ctx, msg, resp, time.Since(start))
+		if audit := ctx.cfg.audit; audit != nil {
+			audit.recordCall(ctx.ctx, msg, resp, start)
 		}
 		return resp
 
@@ -501,27 +799,207 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 	}
 }
 
-// handleCall processes method calls.
-func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+// handleCall processes method calls. The class assigned by the request classifier is
+// attached to the context of the method call.
+func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, class string) (resp *jsonrpcMessage) {
+	if msg.rejected != nil {
+		return msg.errorResponse(msg.rejected)
+	}
+	msg, notice := cp.cfg.resolveAlias(msg)
+	if notice != nil {
+		if hook := cp.cfg.deprecationHook; hook != nil {
+			hook(cp.ctx, *notice)
+		}
+		defer func() {
+			if resp != nil {
+				resp.updateResponseExt(func(ext *responseExt) { ext.Deprecation = notice })
+			}
+		}()
+	}
+	callCtx, done, err := h.reg.calls.start(cp.ctx)
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	// The call is finished when the method returns, which may be after the response
+	// has been sent, see callWithTimeout.
+	hold := newCallHold()
+	defer hold.release()
+	hold.onRelease(done)
+	parent := cp
+	cp = cp.derive(context.WithValue(callCtx, callHoldKey{}, hold))
+	defer func() { parent.notifiers = append(parent.notifiers, cp.notifiers...) }()
+
+	release, err := h.acquireCallSlot(cp.ctx, msg)
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	hold.onRelease(release)
+	if b := msg.requestBaggage(); len(b) > 0 {
+		parent := cp
+		cp = cp.derive(NewContextWithBaggage(cp.ctx, b))
+		defer func() { parent.notifiers = append(parent.notifiers, cp.notifiers...) }()
+	}
+	if timeout, ok := msg.requestTimeout(); ok {
+		// The client gives up after the timeout, see callTimeout.
+		parent := cp
+		cp = cp.derive(context.WithValue(cp.ctx, clientDeadlineKey{}, callDeadline{h.clock, h.clock.Now().Add(timeout)}))
+		defer func() { parent.notifiers = append(parent.notifiers, cp.notifiers...) }()
+	}
+	if resp, p := h.handleChallenge(cp, msg); resp != nil {
+		return resp
+	} else if p != nil {
+		info := PeerInfoFromContext(cp.ctx)
+		info.Principal = p
+		parent := cp
+		cp = cp.derive(context.WithValue(cp.ctx, peerInfoContextKey{}, info))
+		defer func() { parent.notifiers = append(parent.notifiers, cp.notifiers...) }()
+	}
+	if !cp.cfg.ipcAllowed(PeerInfoFromContext(cp.ctx), msg.namespace()) {
+		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
+	}
+	if !PeerInfoFromContext(cp.ctx).route.allows(msg.Method) {
+		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
+	}
+	if err := cp.cfg.aclCheck(PeerInfoFromContext(cp.ctx), msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
+	if usage, err := cp.cfg.chargeComputeUnits(PeerInfoFromContext(cp.ctx), msg.Method); usage != nil {
+		defer func() {
+			if resp != nil {
+				resp.setComputeUnits(usage)
+			}
+		}()
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+	}
+	if err := cp.cfg.consistency.checkConsistency(cp.ctx, msg); err != nil {
+		return msg.errorResponse(err)
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
-	var callb *callback
+	if h.sessions != nil && (msg.Method == newSessionMethod || msg.Method == resumeSessionMethod) {
+		return h.handleSession(cp, msg)
+	}
+	if msg.Method == selectVersionsMethod {
+		return h.handleSelectVersions(cp, msg)
+	}
+	if msg.Method == unsubscribeManyMethod || msg.Method == unsubscribeAllMethod {
+		return h.handleBulkUnsubscribe(cp, msg)
+	}
+	if msg.isSubscriptionControl() {
+		return h.handleSubscriptionControl(cp, msg)
+	}
+	if pre := cp.cfg.preDecode; len(pre) > 0 {
+		return h.runPreDecode(cp, msg, class, pre)
+	}
+	return h.callMethod(cp, msg, class)
+}
+
+// callMethod processes a call of a method provided by a service.
+func (h *handler) callMethod(cp *callProc, msg *jsonrpcMessage, class string) (answer *jsonrpcMessage) {
+	if err := cp.cfg.validateParams(msg.Method, msg.Params); err != nil {
+		return msg.errorResponse(err)
+	}
+	var (
+		callb   *callback
+		factory bool
+	)
 	if msg.isUnsubscribe() {
 		callb = h.unsubscribeCb
 	} else {
-		callb = h.reg.callback(msg.Method)
+		callb = h.reg.versionedCallback(msg.Method, h.selectedVersions(cp.ctx))
+	}
+	if callb == nil {
+		var err error
+		if callb, err = h.factoryMethod(cp.ctx, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+		factory = true
+	}
+	if callb == nil {
+		callb = h.reg.proxyMethod(msg.Method)
 	}
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
 
-	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
+	page, err := cp.cfg.pageRequest(msg)
 	if err != nil {
+		return msg.errorResponse(err)
+	}
+	policy := cp.cfg.cachePolicy(msg, callb, factory)
+	cache := cp.cfg.responseCache
+	var cacheKey string
+	dryRun := msg.isDryRun()
+	if cache != nil && policy != nil && page == nil && !dryRun {
+		if key, ok := callCacheKey(msg.Method, msg.Params, policy.VaryBy); ok {
+			if result, ttl, hit := cache.Get(key); hit && ttl > 0 {
+				resp := &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: result}
+				resp.setCacheTTL(ttl)
+				return resp
+			}
+			cacheKey = key
+		}
+	}
+	if co := cp.cfg.coalescing; co.applies(msg.Method) && !factory && page == nil && !dryRun && callb != h.unsubscribeCb {
+		if params, ok := callCacheKey(msg.Method, msg.Params, nil); ok {
+			key := flightKey{callb, params}
+			f, leader := co.join(key)
+			if !leader {
+				if resp, ok := f.wait(cp.ctx, msg); ok {
+					return resp
+				}
+			} else {
+				defer func() { co.finish(key, f, cp.ctx, answer) }()
+			}
+		}
+	}
+	var args []reflect.Value
+	if callb.raw != nil {
+		args = []reflect.Value{reflect.ValueOf(msg.Params)}
+	} else if args, err = parsePositionalArguments(msg.Params, callb.argTypes, h.decodeConfig()); err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
+
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx := withRequestClass(cp.ctx, class)
+	if dryRun {
+		ctx = NewContextWithDryRun(ctx)
+	}
+	if blobs := cp.cfg.blobs; blobs != nil {
+		ctx = context.WithValue(ctx, blobConfigKey{}, blobs)
+	}
+	if ceiling, ok := cp.cfg.memoryCeiling(msg.Method); ok {
+		account := &memoryAccount{method: msg.Method, limit: ceiling.Limit}
+		if err := account.charge(int64(len(msg.Params)) + ceiling.Weight); err != nil {
+			return msg.errorResponse(err)
+		}
+		ctx = context.WithValue(ctx, memoryAccountKey{}, account)
+	}
+	meta := new(responseMeta)
+	ctx = context.WithValue(ctx, responseMetaKey{}, meta)
+	var guard *callGuard
+	if cfg := cp.cfg.guard; cfg != nil {
+		guard = newCallGuard(ctx, cfg, msg.Method)
+		ctx = context.WithValue(ctx, callGuardKey{}, guard)
+	}
+	answer = h.runMethod(ctx, cp.cfg, msg, callb, args)
+	if guard != nil {
+		guard.finish()
+	}
+	if page != nil {
+		answer = page.apply(answer)
+	}
+	if answer.Error == nil && answer.stream == nil {
+		if ttl := meta.resultTTL(policy); ttl > 0 {
+			answer.setCacheTTL(ttl)
+			if cacheKey != "" {
+				cache.Put(cacheKey, answer.Result, ttl)
+			}
+		}
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -529,11 +1007,20 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		rpcRequestGauge.Inc(1)
 		if answer.Error != nil {
 			failedRequestGauge.Inc(1)
+			if h.events != nil {
+				h.events.recordError()
+			}
 		} else {
 			successfulRequestGauge.Inc(1)
 		}
 		rpcServingTimer.UpdateSince(start)
-		updateServeTimeHistogram(msg.Method, answer.Error == nil, time.Since(start))
+		if answer.Error == nil {
+			h.reg.durations.record(msg.Method, time.Since(start))
+		}
+		updateServeTimeHistogram(h.reg.labels.method(msg.Method), answer.Error == nil, time.Since(start))
+		if class != "" {
+			updateClassMetrics(class, answer.Error == nil, time.Since(start))
+		}
 	}
 
 	return answer
@@ -551,45 +1038,136 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	namespace := msg.namespace()
-	callb := h.reg.subscription(namespace, name)
+	callb := h.reg.versionedSubscription(namespace, name, h.selectedVersions(cp.ctx))
+	if callb == nil {
+		if callb, err = h.factorySubscription(cp.ctx, namespace, name); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
+	if callb == nil {
+		callb = h.reg.proxySubscription(namespace)
+	}
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
 
-	// Parse subscription name arg too, but remove it before calling the callback.
-	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
-	args, err := parsePositionalArguments(msg.Params, argTypes)
-	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+	var args []reflect.Value
+	if callb.raw != nil {
+		// Raw callbacks receive all parameters, including the subscription name.
+		args = []reflect.Value{reflect.ValueOf(msg.Params)}
+	} else {
+		// Parse subscription name arg too, but remove it before calling the callback.
+		argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
+		if args, err = parsePositionalArguments(msg.Params, argTypes, h.decodeConfig()); err != nil {
+			return msg.errorResponse(&invalidParamsError{err.Error()})
+		}
+		args = args[1:]
 	}
-	args = args[1:]
 
 	// Install notifier in context so the subscription handler can find it.
-	n := &Notifier{h: h, namespace: namespace}
+	n := &Notifier{h: h, namespace: namespace, name: name, ctx: context.WithoutCancel(cp.ctx)}
+	n.queue = cp.cfg.subscriptionQueue(namespace)
+	if h.subOwner != nil {
+		n.owner = h.subOwner(cp.ctx)
+	}
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
 
-	return h.runMethod(ctx, msg, callb, args)
+	return h.runMethod(ctx, cp.cfg, msg, callb, args)
 }
 
 // runMethod runs the Go callback for an RPC method.
-func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+func (h *handler) runMethod(ctx context.Context, cfg *registryConfig, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	ctx = withRequestInfo(ctx, msg)
+	panics := cfg.panics
 	next := func(ctx context.Context, method string, args []reflect.Value) *MethodResult {
-		result, err := callb.call(ctx, method, args)
+		result, err := callb.callWithPolicy(ctx, method, args, panics)
 		return &MethodResult{Result: result, Error: err}
 	}
-	for i := len(h.reg.middlewares) - 1; i >= 0; i-- {
-		middleware := h.reg.middlewares[i]
+	middlewares := cfg.methodMiddlewares(msg.Method)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware := middlewares[i]
 		nextFunc := next
 		next = func(ctx context.Context, method string, args []reflect.Value) *MethodResult {
 			return middleware(ctx, method, args, nextFunc)
 		}
 	}
-	result := next(ctx, msg.Method, args)
+	// Panics of the method are handled by callWithPolicy, but middlewares may crash as
+	// well. The chain may also run on its own goroutine, see callWithTimeout.
+	chain := next
+	next = func(ctx context.Context, method string, args []reflect.Value) *MethodResult {
+		return panics.run(method, func() *MethodResult { return chain(ctx, method, args) })
+	}
+	ctx, span := cfg.startSpan(ctx, msg.Method)
+	execStart := time.Now()
+	var result *MethodResult
+	if timeout := h.callTimeout(ctx, cfg, msg.Method); timeout > 0 {
+		ctx = context.WithValue(ctx, callDeadlineKey{}, callDeadline{h.clock, h.clock.Now().Add(timeout)})
+		result = h.callWithTimeout(ctx, msg.Method, args, timeout, next)
+	} else {
+		result = next(ctx, msg.Method, args)
+	}
+	execTime := time.Since(execStart)
+	if result == nil {
+		result = &MethodResult{Error: &internalServerError{errcodeDefault, "middleware returned no result"}}
+	}
+	if span != nil {
+		span.End(result.Error)
+	}
+	var resp *jsonrpcMessage
 	if result.Error != nil {
-		return msg.errorResponse(result.Error)
+		resp = msg.errorResponse(result.Error)
+	} else if stream, ok := h.streamable(ctx, result); ok {
+		resp = &jsonrpcMessage{Version: vsn, ID: msg.ID, stream: stream}
+	} else {
+		resp = h.encodeResult(ctx, cfg, msg, result.Result)
+	}
+	if h.executionReports {
+		if resp.report == nil {
+			resp.report = new(ExecutionReport)
+		}
+		resp.report.Execution = execTime
+	}
+	return resp
+}
+
+// encodeResult creates the response for a successful call, applying encode limits.
+func (h *handler) encodeResult(ctx context.Context, cfg *registryConfig, msg *jsonrpcMessage, result interface{}) *jsonrpcMessage {
+	release := func(int) {}
+	if limits := cfg.encodeLimits; limits != nil {
+		var ok bool
+		if release, ok = limits.acquire(ctx, msg.Method); !ok {
+			return msg.errorResponse(&internalServerError{errcodeTimeout, errMsgTimeout})
+		}
+	}
+	encStart := time.Now()
+	resp := msg.response(h.timeFormat.encodeResult(result))
+	encTime := time.Since(encStart)
+	release(len(resp.Result))
+	resp = chargeResult(ctx, msg, resp)
+	if h.executionReports {
+		resp.report = &ExecutionReport{Encode: encTime}
+	}
+	return resp
+}
+
+// decodeConfig returns the configuration for decoding call arguments.
+func (h *handler) decodeConfig() decodeConfig {
+	return decodeConfig{numbers: h.numberPolicy, times: h.timeFormat}
+}
+
+// lookupSubscription returns the server subscription with the given id, if it exists and
+// is owned by the caller. It must be called with h.subLock held.
+func (h *handler) lookupSubscription(ctx context.Context, id ID) *Subscription {
+	s := h.serverSubs[id]
+	if s == nil {
+		return nil
+	}
+	if h.subOwner != nil && h.subOwner(ctx) != s.owner {
+		// Report foreign subscriptions as missing, so ids can't be probed.
+		return nil
 	}
-	return msg.response(result.Result)
+	return s
 }
 
 // unsubscribe is the callback function for all *_unsubscribe calls.
@@ -597,11 +1175,14 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	h.subLock.Lock()
 	defer h.subLock.Unlock()
 
-	s := h.serverSubs[id]
+	s := h.lookupSubscription(ctx, id)
 	if s == nil {
+		if h.epochIDs && h.fromOtherEpoch(idEpoch(id)) {
+			return false, &unknownSubscriptionError{h.epoch}
+		}
 		return false, ErrSubscriptionNotFound
 	}
-	close(s.err)
+	s.close(nil)
 	delete(h.serverSubs, id)
 	return true, nil
 }
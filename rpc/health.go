@@ -0,0 +1,219 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Paths of the health and readiness endpoints, see HealthHandler and ReadinessHandler.
+// GET requests to these paths are also answered by ServeHTTP and by routers.
+const (
+	HealthPath = "/health"
+	ReadyPath  = "/ready"
+)
+
+// healthCheckTimeout limits the time of a single health check.
+const healthCheckTimeout = 5 * time.Second
+
+// HealthChecker checks a component the server depends on, e.g. the connection to an
+// upstream node or the backlog of a subscription feed. It returns an error describing
+// the problem if the component is unhealthy.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc is a HealthChecker implemented by a function.
+type HealthCheckFunc func(ctx context.Context) error
+
+// CheckHealth calls f.
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// healthCheck is a registered health checker.
+type healthCheck struct {
+	checker   HealthChecker
+	readiness bool // only checked by the readiness endpoint
+}
+
+// HealthStatus is the JSON body of the health and readiness endpoints.
+type HealthStatus struct {
+	Status   string                 `json:"status"` // "ok" or "fail"
+	Draining bool                   `json:"draining,omitempty"`
+	Checks   map[string]CheckStatus `json:"checks,omitempty"`
+}
+
+// CheckStatus is the result of a single health check.
+type CheckStatus struct {
+	Status     string `json:"status"` // "ok" or "fail"
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+const (
+	healthOK   = "ok"
+	healthFail = "fail"
+)
+
+// RegisterHealthCheck adds a check to both the health and the readiness endpoint.
+// Failing health checks signal that the server should be restarted. Registering a
+// name which is already present replaces its check.
+func (s *Server) RegisterHealthCheck(name string, checker HealthChecker) {
+	s.registerHealthCheck(name, healthCheck{checker: checker})
+}
+
+// RegisterReadinessCheck adds a check to the readiness endpoint only. Failing readiness
+// checks signal that the server is alive, but should not receive traffic, e.g. while
+// it is catching up with its upstream. Registering a name which is already present
+// replaces its check.
+func (s *Server) RegisterReadinessCheck(name string, checker HealthChecker) {
+	s.registerHealthCheck(name, healthCheck{checker: checker, readiness: true})
+}
+
+func (s *Server) registerHealthCheck(name string, check healthCheck) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.healthChecks == nil {
+		s.healthChecks = make(map[string]healthCheck)
+	}
+	s.healthChecks[name] = check
+}
+
+// HealthHandler returns a handler serving the health (liveness) status of the server.
+// It runs the health checks concurrently and answers with a HealthStatus object, and
+// status 200 if all checks passed or 503 otherwise. Draining doesn't affect health.
+func (s *Server) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serveHealth(w, r, false)
+	})
+}
+
+// ReadinessHandler returns a handler serving the readiness status of the server. It
+// runs the health and readiness checks concurrently and answers with a HealthStatus
+// object, and status 200 if all checks passed or 503 otherwise. While draining, the
+// server isn't ready and the status configured by SetDrainOptions is used.
+func (s *Server) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serveHealth(w, r, true)
+	})
+}
+
+// serveHealthPath answers GET requests to the health and readiness paths. It reports
+// whether r was handled.
+func (s *Server) serveHealthPath(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	switch r.URL.Path {
+	case HealthPath:
+		s.serveHealth(w, r, false)
+	case ReadyPath:
+		s.serveHealth(w, r, true)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request, readiness bool) {
+	status, code := s.healthStatus(r.Context(), readiness)
+	w.Header().Set("content-type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	if status.Draining {
+		w.Header().Set("Connection", "close")
+	}
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
+
+// healthStatus runs the checks and returns the status with its HTTP status code.
+func (s *Server) healthStatus(ctx context.Context, readiness bool) (HealthStatus, int) {
+	s.mutex.Lock()
+	names := make([]string, 0, len(s.healthChecks))
+	checks := make([]HealthChecker, 0, len(s.healthChecks))
+	for name, check := range s.healthChecks {
+		if readiness || !check.readiness {
+			names = append(names, name)
+			checks = append(checks, check.checker)
+		}
+	}
+	s.mutex.Unlock()
+
+	var (
+		status  = HealthStatus{Status: healthOK}
+		results = make([]CheckStatus, len(checks))
+		wg      sync.WaitGroup
+	)
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	if len(names) > 0 {
+		status.Checks = make(map[string]CheckStatus, len(names))
+	}
+	for i, name := range names {
+		status.Checks[name] = results[i]
+		if results[i].Status != healthOK {
+			status.Status = healthFail
+		}
+	}
+	code := http.StatusOK
+	if status.Status != healthOK {
+		code = http.StatusServiceUnavailable
+	}
+	if readiness && s.draining.Load() {
+		status.Status = healthFail
+		status.Draining = true
+		code = s.drainHealthStatus
+	}
+	if !s.run.Load() || s.shuttingDown() {
+		status.Status = healthFail
+		code = http.StatusServiceUnavailable
+	}
+	return status, code
+}
+
+// runHealthCheck runs a single check, which is abandoned when ctx is done.
+func runHealthCheck(ctx context.Context, check HealthChecker) CheckStatus {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.CheckHealth(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := CheckStatus{Status: healthOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status, result.Error = healthFail, err.Error()
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func getHealth(t *testing.T, url string) (HealthStatus, int) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var status HealthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	return status, resp.StatusCode
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	var upstreamDown, syncing atomic.Bool
+	server.RegisterHealthCheck("upstream", HealthCheckFunc(func(ctx context.Context) error {
+		if upstreamDown.Load() {
+			return errors.New("upstream unreachable")
+		}
+		return nil
+	}))
+	server.RegisterReadinessCheck("sync", HealthCheckFunc(func(ctx context.Context) error {
+		if syncing.Load() {
+			return errors.New("syncing")
+		}
+		return nil
+	}))
+
+	status, code := getHealth(t, httpsrv.URL+HealthPath)
+	if code != http.StatusOK || status.Status != "ok" || len(status.Checks) != 1 {
+		t.Fatalf("wrong health status %d %+v", code, status)
+	}
+	status, code = getHealth(t, httpsrv.URL+ReadyPath)
+	if code != http.StatusOK || status.Status != "ok" || len(status.Checks) != 2 {
+		t.Fatalf("wrong readiness status %d %+v", code, status)
+	}
+
+	// Failing readiness checks don't affect health.
+	syncing.Store(true)
+	if _, code := getHealth(t, httpsrv.URL+HealthPath); code != http.StatusOK {
+		t.Fatalf("wrong health status %d", code)
+	}
+	status, code = getHealth(t, httpsrv.URL+ReadyPath)
+	if code != http.StatusServiceUnavailable || status.Checks["sync"].Error != "syncing" {
+		t.Fatalf("wrong readiness status %d %+v", code, status)
+	}
+
+	upstreamDown.Store(true)
+	status, code = getHealth(t, httpsrv.URL+HealthPath)
+	if code != http.StatusServiceUnavailable || status.Status != "fail" || status.Checks["upstream"].Status != "fail" {
+		t.Fatalf("wrong health status %d %+v", code, status)
+	}
+}
+
+func TestHealthDraining(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	router, err := server.Router(Route{Prefix: "/rpc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(router)
+	defer httpsrv.Close()
+
+	server.StartDraining()
+	if _, code := getHealth(t, httpsrv.URL+HealthPath); code != http.StatusOK {
+		t.Fatalf("wrong health status while draining %d", code)
+	}
+	status, code := getHealth(t, httpsrv.URL+ReadyPath)
+	if code != http.StatusServiceUnavailable || !status.Draining {
+		t.Fatalf("wrong readiness status while draining %d %+v", code, status)
+	}
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterHealthCheck("stuck", HealthCheckFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status, code := server.healthStatus(ctx, false)
+	if code != http.StatusServiceUnavailable || status.Checks["stuck"].Error != context.Canceled.Error() {
+		t.Fatalf("wrong status %d %+v", code, status)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// HedgePolicy configures hedged calls, see WithHedging.
+type HedgePolicy struct {
+	Delay     time.Duration // time to wait for a response before sending another attempt
+	MaxHedges int           // number of additional attempts (default 1)
+}
+
+// WithHedging makes the client send additional attempts of calls made through
+// CallContext which haven't been answered after p.Delay. The first successful response
+// is used and the other attempts are canceled. Hedging reduces the latency of calls in
+// the tail of the distribution, e.g. when one backend behind a load balancer is slow,
+// at the cost of additional load.
+//
+// Error responses end the call immediately since they are answers of the server.
+// Failed attempts end it only when no other attempt is pending. Calls of methods
+// declared non-idempotent using WithNonIdempotentMethods are never hedged. Combined
+// with WithRetryPolicy, every retry is hedged.
+//
+// Every attempt decodes its result into a new value of the result type, which is
+// copied to the result when the attempt wins.
+func WithHedging(p HedgePolicy) ClientOption {
+	if p.MaxHedges <= 0 {
+		p.MaxHedges = 1
+	}
+	return optionFunc(func(cfg *clientConfig) {
+		if p.Delay > 0 {
+			cfg.hedgePolicy = &p
+		}
+	})
+}
+
+// hedgeOutcome is the outcome of an attempt of a hedged call.
+type hedgeOutcome struct {
+	result reflect.Value
+	err    error
+}
+
+// hedgedCall performs a call, sending additional attempts according to the hedging
+// policy.
+func (c *Client) hedgedCall(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		policy   = c.hedging
+		outcomes = make(chan hedgeOutcome, 1+policy.MaxHedges)
+		pending  int
+		hedges   int
+	)
+	attempt := func() {
+		pending++
+		go func() {
+			var o hedgeOutcome
+			var target interface{}
+			if result != nil {
+				o.result = reflect.New(reflect.TypeOf(result).Elem())
+				target = o.result.Interface()
+			}
+			o.err = c.callContext(ctx, target, method, args...)
+			outcomes <- o
+		}()
+	}
+	attempt()
+	timer := c.clock.NewTimer(policy.Delay)
+	defer func() { timer.Stop() }()
+
+	for {
+		select {
+		case o := <-outcomes:
+			pending--
+			if o.err == nil {
+				if result != nil {
+					reflect.ValueOf(result).Elem().Set(o.result.Elem())
+				}
+				return nil
+			}
+			var rpcErr Error
+			if pending == 0 || errors.As(o.err, &rpcErr) {
+				return o.err
+			}
+		case <-timer.C():
+			if hedges < policy.MaxHedges {
+				hedges++
+				attempt()
+				timer = c.clock.NewTimer(policy.Delay)
+			}
+		}
+	}
+}
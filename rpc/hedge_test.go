@@ -0,0 +1,110 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hedgeTestService answers the first call only when released, and all other calls
+// immediately.
+type hedgeTestService struct {
+	calls   atomic.Int64
+	release chan struct{}
+}
+
+func (s *hedgeTestService) Slow() int64 {
+	n := s.calls.Add(1)
+	if n == 1 {
+		<-s.release
+	}
+	return n
+}
+
+func (s *hedgeTestService) Fail() error {
+	s.calls.Add(1)
+	return errors.New("fail")
+}
+
+func TestHedging(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &hedgeTestService{release: make(chan struct{})}
+	defer close(svc.release)
+	server.RegisterName("hedge", svc)
+	client := dialInProcWithOptions(server, WithHedging(HedgePolicy{Delay: 20 * time.Millisecond}))
+	defer client.Close()
+	var n int64
+	if err := client.Call(&n, "hedge_slow"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("got result of attempt %d, want 2", n)
+	}
+	if calls := svc.calls.Load(); calls != 2 {
+		t.Fatalf("method called %d times, want 2", calls)
+	}
+}
+
+func TestHedgingNonIdempotent(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &hedgeTestService{release: make(chan struct{})}
+	defer close(svc.release)
+	server.RegisterName("hedge", svc)
+	client := dialInProcWithOptions(server,
+		WithHedging(HedgePolicy{Delay: 10 * time.Millisecond}),
+		WithNonIdempotentMethods("hedge_slow"),
+	)
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "hedge_slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if calls := svc.calls.Load(); calls != 1 {
+		t.Fatalf("method called %d times, want 1", calls)
+	}
+}
+
+func TestHedgingError(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &hedgeTestService{release: make(chan struct{})}
+	defer close(svc.release)
+	server.RegisterName("hedge", svc)
+	client := dialInProcWithOptions(server, WithHedging(HedgePolicy{Delay: 50 * time.Millisecond, MaxHedges: 2}))
+	defer client.Close()
+	err := client.Call(nil, "hedge_fail")
+	if err == nil || err.Error() != "fail" {
+		t.Fatalf("wrong error %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if calls := svc.calls.Load(); calls != 1 {
+		t.Fatalf("method called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+// These types encode numbers and byte slices as hexadecimal JSON strings with 0x prefix,
+// following the conventions of the Ethereum JSON-RPC API. They can be used in method
+// signatures without depending on other go-ethereum packages.
+
+const maxBigHexBits = 256
+
+var (
+	errHexEmpty       = errors.New("empty hex string")
+	errHexNonString   = errors.New("hex value must be a JSON string")
+	errHexPrefix      = errors.New("hex string without 0x prefix")
+	errHexSyntax      = errors.New("invalid hex string")
+	errHexOddLength   = errors.New("hex string of odd length")
+	errHexLeadingZero = errors.New("hex number with leading zero digits")
+	errHexUint64Range = errors.New("hex number > 64 bits")
+	errHexBigRange    = errors.New("hex number > 256 bits")
+	errHexNegative    = errors.New("negative hex number")
+)
+
+// Uint64Hex is a uint64 encoded as a hex quantity, e.g. "0x1f".
+type Uint64Hex uint64
+
+// MarshalText implements encoding.TextMarshaler.
+func (u Uint64Hex) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (u *Uint64Hex) UnmarshalJSON(input []byte) error {
+	raw, err := hexQuantity(input)
+	if err != nil {
+		return err
+	}
+	if len(raw) > 16 {
+		return errHexUint64Range
+	}
+	v, err := strconv.ParseUint(raw, 16, 64)
+	if err != nil {
+		return errHexSyntax
+	}
+	*u = Uint64Hex(v)
+	return nil
+}
+
+// String returns the hex encoding of u.
+func (u Uint64Hex) String() string {
+	return "0x" + strconv.FormatUint(uint64(u), 16)
+}
+
+// BigHex is a big.Int encoded as a hex quantity. Values of up to 256 bits are accepted
+// when decoding.
+type BigHex big.Int
+
+// MarshalText implements encoding.TextMarshaler.
+func (b BigHex) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BigHex) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' && len(input) > 2 && input[1] == '-' {
+		return errHexNegative
+	}
+	raw, err := hexQuantity(input)
+	if err != nil {
+		return err
+	}
+	if len(raw) > maxBigHexBits/4 {
+		return errHexBigRange
+	}
+	v, ok := new(big.Int).SetString(raw, 16)
+	if !ok {
+		return errHexSyntax
+	}
+	*b = BigHex(*v)
+	return nil
+}
+
+// ToInt converts b to a big.Int.
+func (b *BigHex) ToInt() *big.Int {
+	return (*big.Int)(b)
+}
+
+// String returns the hex encoding of b.
+func (b BigHex) String() string {
+	v := (*big.Int)(&b)
+	if v.Sign() < 0 {
+		return "-0x" + new(big.Int).Neg(v).Text(16)
+	}
+	return "0x" + v.Text(16)
+}
+
+// Bytes is a byte slice encoded as a hex string, e.g. "0x01ff". The empty slice is
+// encoded as "0x".
+type Bytes []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Bytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bytes) UnmarshalJSON(input []byte) error {
+	raw, err := hexString(input)
+	if err != nil {
+		return err
+	}
+	if len(raw)%2 != 0 {
+		return errHexOddLength
+	}
+	dec, err := hex.DecodeString(raw)
+	if err != nil {
+		return errHexSyntax
+	}
+	*b = dec
+	return nil
+}
+
+// String returns the hex encoding of b.
+func (b Bytes) String() string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// hexString returns the digits of a JSON hex string.
+func hexString(input []byte) (string, error) {
+	if len(input) < 2 || input[0] != '"' || input[len(input)-1] != '"' {
+		return "", errHexNonString
+	}
+	s := string(input[1 : len(input)-1])
+	if len(s) == 0 {
+		return "", errHexEmpty
+	}
+	if len(s) < 2 || s[0] != '0' || (s[1] != 'x' && s[1] != 'X') {
+		return "", errHexPrefix
+	}
+	return s[2:], nil
+}
+
+// hexQuantity returns the digits of a JSON hex quantity.
+func hexQuantity(input []byte) (string, error) {
+	raw, err := hexString(input)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) == 0 {
+		return "", errHexEmpty
+	}
+	if raw[0] == '+' || raw[0] == '-' {
+		// big.Int.SetString would accept the sign.
+		return "", errHexSyntax
+	}
+	if len(raw) > 1 && raw[0] == '0' {
+		return "", errHexLeadingZero
+	}
+	return raw, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestHexTypesMarshal(t *testing.T) {
+	t.Parallel()
+
+	big1, _ := new(big.Int).SetString("123456789abcdef0123456789", 16)
+	values := []struct {
+		v    any
+		want string
+	}{
+		{Uint64Hex(0), `"0x0"`},
+		{Uint64Hex(0x1f), `"0x1f"`},
+		{Uint64Hex(^uint64(0)), `"0xffffffffffffffff"`},
+		{(*BigHex)(big.NewInt(0)), `"0x0"`},
+		{(*BigHex)(big1), `"0x123456789abcdef0123456789"`},
+		{(*BigHex)(big.NewInt(-16)), `"-0x10"`},
+		{Bytes{}, `"0x"`},
+		{Bytes{0x01, 0xff}, `"0x01ff"`},
+	}
+	for _, test := range values {
+		enc, err := json.Marshal(test.v)
+		if err != nil {
+			t.Errorf("%v: %v", test.v, err)
+		} else if string(enc) != test.want {
+			t.Errorf("%v: got %s, want %s", test.v, enc, test.want)
+		}
+	}
+}
+
+func TestHexTypesUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		u64     error
+		big     error
+		bytes   error
+		wantU64 uint64
+	}{
+		{input: `"0x0"`, bytes: errHexOddLength},
+		{input: `"0x1f"`, wantU64: 0x1f},
+		{input: `"0xffffffffffffffff"`, wantU64: ^uint64(0)},
+		{input: `"0x10000000000000000"`, u64: errHexUint64Range, bytes: errHexOddLength},
+		{input: `"0x01"`, u64: errHexLeadingZero, big: errHexLeadingZero},
+		{input: `"0x"`, u64: errHexEmpty, big: errHexEmpty},
+		{input: `""`, u64: errHexEmpty, big: errHexEmpty, bytes: errHexEmpty},
+		{input: `"1f"`, u64: errHexPrefix, big: errHexPrefix, bytes: errHexPrefix},
+		{input: `"0xzz"`, u64: errHexSyntax, big: errHexSyntax, bytes: errHexSyntax},
+		{input: `31`, u64: errHexNonString, big: errHexNonString, bytes: errHexNonString},
+		{input: `"-0x1"`, u64: errHexPrefix, big: errHexNegative, bytes: errHexPrefix},
+		{input: `"0x-1"`, u64: errHexSyntax, big: errHexSyntax, bytes: errHexSyntax},
+		{input: `"0x+ff"`, u64: errHexSyntax, big: errHexSyntax, bytes: errHexOddLength},
+		{input: `"0x-ff"`, u64: errHexSyntax, big: errHexSyntax, bytes: errHexOddLength},
+		{input: `"0x1` + strings.Repeat("0", 64) + `"`, u64: errHexUint64Range, big: errHexBigRange, bytes: errHexOddLength},
+	}
+	for _, test := range tests {
+		var u Uint64Hex
+		if err := json.Unmarshal([]byte(test.input), &u); err != test.u64 {
+			t.Errorf("%s: Uint64Hex error %v, want %v", test.input, err, test.u64)
+		} else if err == nil && uint64(u) != test.wantU64 {
+			t.Errorf("%s: Uint64Hex value %d, want %d", test.input, u, test.wantU64)
+		}
+		var b BigHex
+		if err := json.Unmarshal([]byte(test.input), &b); err != test.big {
+			t.Errorf("%s: BigHex error %v, want %v", test.input, err, test.big)
+		} else if err == nil && test.u64 == nil && b.ToInt().Uint64() != test.wantU64 {
+			t.Errorf("%s: BigHex value %v, want %d", test.input, b.ToInt(), test.wantU64)
+		}
+		var bs Bytes
+		if err := json.Unmarshal([]byte(test.input), &bs); err != test.bytes {
+			t.Errorf("%s: Bytes error %v, want %v", test.input, err, test.bytes)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"net/url"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -48,6 +49,14 @@ type httpConn struct {
 	mu        sync.Mutex // protects headers
 	headers   http.Header
 	auth      HTTPAuth
+
+	wire         WireCodec   // encoding of requests, nil for JSON
+	wireRejected atomic.Bool // set when the server doesn't support wire
+
+	binaryPayloads int         // see WithBinaryPayloads
+	blobsRejected  atomic.Bool // set when the server doesn't support binary payloads
+
+	propagateDeadline bool // see WithDeadlinePropagation
 }
 
 // httpConn implements ServerCodec, but it is treated specially by Client
@@ -154,13 +163,24 @@ func newClientTransportHTTP(endpoint string, cfg *clientConfig) reconnectFunc {
 	if client == nil {
 		client = new(http.Client)
 	}
+	if cfg.h2c {
+		var err error
+		if client, err = h2cClient(client); err != nil {
+			return func(context.Context) (ServerCodec, error) { return nil, err }
+		}
+	}
 
 	hc := &httpConn{
 		client:  client,
 		headers: headers,
 		url:     endpoint,
 		auth:    cfg.httpAuth,
+		wire:    cfg.wireCodec,
 		closeCh: make(chan interface{}),
+
+		binaryPayloads: cfg.binaryPayloads,
+
+		propagateDeadline: cfg.propagateDeadline,
 	}
 
 	return func(ctx context.Context) (ServerCodec, error) {
@@ -206,6 +226,24 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	if err != nil {
 		return nil, err
 	}
+	wire := hc.wire
+	if hc.wireRejected.Load() {
+		wire = nil
+	}
+	var multipartType string
+	if wire != nil {
+		if body, err = wire.FromJSON(nil, body); err != nil {
+			return nil, err
+		}
+	} else if hc.binaryPayloads > 0 && !hc.blobsRejected.Load() {
+		if js, blobs := extractBlobs(body, hc.binaryPayloads); blobs != nil {
+			var buf bytes.Buffer
+			if multipartType, err = writeMultipart(&buf, js, blobs); err != nil {
+				return nil, err
+			}
+			body = buf.Bytes()
+		}
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hc.url, io.NopCloser(bytes.NewReader(body)))
 	if err != nil {
 		return nil, err
@@ -218,6 +256,30 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	req.Header = hc.headers.Clone()
 	hc.mu.Unlock()
 	setHeaders(req.Header, headersFromContext(ctx))
+	if b := BaggageFromContext(ctx); len(b) > 0 && req.Header.Get(BaggageHeader) == "" {
+		req.Header.Set(BaggageHeader, b.String())
+	}
+	if sc, ok := SpanContextFromContext(ctx); ok && req.Header.Get(TraceparentHeader) == "" {
+		req.Header.Set(TraceparentHeader, sc.Traceparent())
+		if sc.TraceState != "" {
+			req.Header.Set(TracestateHeader, sc.TraceState)
+		}
+	}
+	if hc.propagateDeadline {
+		if ms, ok := timeoutMillis(ctx); ok {
+			req.Header.Set(RequestTimeoutHeader, strconv.FormatInt(ms, 10))
+		}
+	}
+
+	if wire != nil {
+		req.Header.Set("content-type", wire.ContentType())
+		req.Header.Set("accept", wire.ContentType()+", "+contentType)
+	} else if hc.binaryPayloads > 0 {
+		req.Header.Set("accept", contentType+", "+multipartRelated)
+		if multipartType != "" {
+			req.Header.Set("content-type", multipartType)
+		}
+	}
 
 	if hc.auth != nil {
 		if err := hc.auth(req.Header); err != nil {
@@ -230,6 +292,18 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode == http.StatusUnsupportedMediaType && wire != nil {
+		// The server doesn't support the wire codec, use JSON from now on.
+		resp.Body.Close()
+		hc.wireRejected.Store(true)
+		return hc.doRequest(ctx, msg)
+	}
+	if resp.StatusCode == http.StatusUnsupportedMediaType && multipartType != "" {
+		// The server doesn't support binary payloads, send plain JSON from now on.
+		resp.Body.Close()
+		hc.blobsRejected.Store(true)
+		return hc.doRequest(ctx, msg)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		var buf bytes.Buffer
 		var body []byte
@@ -241,24 +315,71 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 			Status:     resp.Status,
 			StatusCode: resp.StatusCode,
 			Body:       body,
+			Header:     resp.Header,
+		}
+	}
+	if wire != nil && mediaType(resp.Header) == wire.ContentType() {
+		return wireResponseBody{wireBody(wire, resp.Body), resp.Body}, nil
+	}
+	if hc.binaryPayloads > 0 {
+		if body := multipartBody(resp.Header, resp.Body, 0); body != nil {
+			return wireResponseBody{body, resp.Body}, nil
 		}
 	}
 	return resp.Body, nil
 }
 
+// wireResponseBody provides the JSON form of a response body in a wire encoding or
+// with binary payloads.
+type wireResponseBody struct {
+	io.Reader
+	io.Closer
+}
+
 // httpServerConn turns a HTTP connection into a Conn.
 type httpServerConn struct {
 	io.Reader
 	io.Writer
 	r *http.Request
+
+	streamBudget int64     // see Server.SetHTTPStreamBudget
+	streamFailed bool      // set when writing a streamed result failed
+	wire         WireCodec // encoding of the request and response, nil for JSON
 }
 
 func (s *Server) newHTTPServerConn(r *http.Request, w http.ResponseWriter) ServerCodec {
 	body := io.LimitReader(r.Body, int64(s.httpBodyLimit))
-	conn := &httpServerConn{Reader: body, Writer: w, r: r}
+	wire := wireCodecForContentType(r.Header.Get("content-type"), s.wireCodecs)
+	if wire != nil {
+		body = wireBody(wire, body)
+		w.Header().Set("content-type", wire.ContentType())
+	}
+	var blobThreshold int
+	if s.binaryPayloads > 0 {
+		if mp := multipartBody(r.Header, body, int64(s.httpBodyLimit)); mp != nil {
+			body = mp
+		}
+		if wire == nil && acceptsMultipart(r) {
+			blobThreshold = s.binaryPayloads
+		}
+	}
+	conn := &httpServerConn{Reader: body, Writer: w, r: r, streamBudget: s.httpStreamBudget, wire: wire}
 
 	encoder := func(v any, isErrorResponse bool) error {
-		if !isErrorResponse {
+		setComputeUnitHeaders(w.Header(), v)
+		if ttl := responseCacheTTL(v); ttl >= time.Second {
+			w.Header().Set("cache-control", "max-age="+strconv.Itoa(int(ttl/time.Second)))
+		}
+		switch responseReplaySafety(v) {
+		case replaySafe:
+			w.Header().Set(ReplaySafeHeader, "true")
+		case replayUnsafe:
+			w.Header().Set(ReplaySafeHeader, "false")
+		}
+		if msg, ok := v.(*jsonrpcMessage); ok && msg.stream != nil {
+			return conn.writeStream(w, msg)
+		}
+		if !isErrorResponse && wire == nil && blobThreshold == 0 {
 			return json.NewEncoder(conn).Encode(v)
 		}
 
@@ -267,11 +388,27 @@ func (s *Server) newHTTPServerConn(r *http.Request, w http.ResponseWriter) Serve
 		// In case of a timeout error, the response must be written before the HTTP
 		// server's write timeout occurs. So we need to flush the response. The
 		// Content-Length header also needs to be set to ensure the client knows
-		// when it has the full response.
+		// when it has the full response. Responses in a wire encoding or with binary
+		// payloads are written the same way.
 		encdata, err := json.Marshal(v)
 		if err != nil {
 			return err
 		}
+		if wire != nil {
+			if encdata, err = wire.FromJSON(nil, encdata); err != nil {
+				return err
+			}
+		} else if blobThreshold > 0 {
+			if js, blobs := extractBlobs(encdata, blobThreshold); blobs != nil {
+				var buf bytes.Buffer
+				ct, err := writeMultipart(&buf, js, blobs)
+				if err != nil {
+					return err
+				}
+				encdata = buf.Bytes()
+				w.Header().Set("content-type", ct)
+			}
+		}
 		w.Header().Set("content-length", strconv.Itoa(len(encdata)))
 
 		// If this request is wrapped in a handler that might remove Content-Length (such
@@ -307,24 +444,81 @@ func (t *httpServerConn) SetWriteDeadline(time.Time) error { return nil }
 
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.serveHTTP(w, r, nil)
+}
+
+// serveHTTP serves a JSON-RPC request received on the given route of a router, which
+// is nil for requests served by ServeHTTP.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request, route *httpRoute) {
+	if s.serveHealthPath(w, r) {
+		return
+	}
+	if s.shuttingDown() {
+		s.rejectShutdown(w)
+		return
+	}
 	// Permit dumb empty requests for remote health-checks (AWS)
 	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
+		if s.draining.Load() {
+			s.drainHealthCheck(w)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		return
 	}
+	if s.draining.Load() {
+		w.Header().Set("Connection", "close")
+	}
 	if code, err := s.validateRequest(r); err != nil {
 		http.Error(w, err.Error(), code)
 		return
 	}
 
+	fail := func(msg string) { http.Error(w, msg, http.StatusUnauthorized) }
+	principal, ok := s.authenticate(r, fail)
+	if !ok {
+		return
+	}
+	if principal, ok = route.authenticate(r, principal, fail); !ok {
+		return
+	}
+
 	// Create request-scoped context.
-	connInfo := PeerInfo{Transport: "http", RemoteAddr: r.RemoteAddr}
+	connInfo := PeerInfo{Transport: "http", RemoteAddr: normalizeRemoteAddr(r.RemoteAddr), Principal: principal}
+	connInfo.setRoute(route)
 	connInfo.HTTP.Version = r.Proto
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	if err := s.admit(&connInfo, r.Header); err != nil {
+		rejectAdmission(w, err)
+		return
+	}
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
+	if r.Header.Get(ExecutionReportHeader) != "" {
+		ctx = context.WithValue(ctx, executionReportKey{}, true)
+	}
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		ctx = context.WithValue(ctx, idempotencyKeyKey{}, key)
+	}
+	if token, ok := parseConsistencyHeader(r.Header.Get(ConsistencyTokenHeader)); ok {
+		ctx = context.WithValue(ctx, consistencyTokenKey{}, token)
+	}
+	if versions := parseAPIVersions(r.Header.Get(APIVersionHeader)); versions != nil {
+		ctx = context.WithValue(ctx, apiVersionsKey{}, versions)
+	}
+	if b := baggageFromHeader(r.Header.Values(BaggageHeader)); len(b) > 0 {
+		ctx = NewContextWithBaggage(ctx, b)
+	}
+	if sc, ok := spanContextFromHeader(r.Header); ok {
+		ctx = NewContextWithSpanContext(ctx, sc)
+	}
+	if timeout, ok := parseRequestTimeout(r.Header.Get(RequestTimeoutHeader)); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	// All checks passed, create a codec that reads directly from the request body
 	// until EOF, writes the response to w, and orders the server to process a
@@ -333,6 +527,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	codec := s.newHTTPServerConn(r, w)
 	defer codec.close()
 	s.serveSingleRequest(ctx, codec)
+	if conn, ok := codec.(*jsonCodec).conn.(*httpServerConn); ok && conn.streamFailed {
+		// Abort the response, so the client doesn't see a complete response body.
+		panic(http.ErrAbortHandler)
+	}
 }
 
 // validateRequest returns a non-zero response code and error message if the
@@ -341,6 +539,9 @@ func (s *Server) validateRequest(r *http.Request) (int, error) {
 	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
 		return http.StatusMethodNotAllowed, errors.New("method not allowed")
 	}
+	if s.headerTooLarge(r) {
+		return http.StatusRequestHeaderFieldsTooLarge, errors.New("request header too large")
+	}
 	if r.ContentLength > int64(s.httpBodyLimit) {
 		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, s.httpBodyLimit)
 		return http.StatusRequestEntityTooLarge, err
@@ -357,6 +558,12 @@ func (s *Server) validateRequest(r *http.Request) (int, error) {
 			}
 		}
 	}
+	if wireCodecForContentType(r.Header.Get("content-type"), s.wireCodecs) != nil {
+		return 0, nil
+	}
+	if s.binaryPayloads > 0 && mediaType(r.Header) == multipartRelated {
+		return 0, nil
+	}
 	// Invalid content-type
 	err := fmt.Errorf("invalid content type, only %s is supported", contentType)
 	return http.StatusUnsupportedMediaType, err
@@ -0,0 +1,57 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTP/2 is negotiated automatically by the HTTP transport when the server is reached
+// over TLS. Unlike HTTP/1.1, where a call occupies a connection until it is answered,
+// HTTP/2 multiplexes any number of concurrent calls, such as long-polling calls, over a
+// single connection. When the client gives up on a call, only its stream is reset,
+// which cancels the context of the method on the server even if the connection is
+// kept open, e.g. by a proxy.
+//
+// HTTP/2 without TLS (h2c) must be enabled explicitly, using ConfigureHTTP2 on the
+// server and WithH2C on the client. It requires Go 1.24 or later.
+
+var (
+	errH2CUnsupported = errors.New("h2c requires Go 1.24 or later")
+	errH2CTransport   = errors.New("h2c requires an *http.Transport")
+)
+
+// WithH2C makes the client use HTTP/2 without TLS (h2c) for http:// endpoints. The
+// server must accept h2c connections, see ConfigureHTTP2. If the client also has a
+// custom HTTP client, see WithHTTPClient, its transport must be an *http.Transport.
+func WithH2C() ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.h2c = true
+	})
+}
+
+// h2cClient returns a copy of client which uses h2c for http:// URLs.
+func h2cClient(client *http.Client) (*http.Client, error) {
+	transport, err := h2cTransport(client.Transport)
+	if err != nil {
+		return nil, err
+	}
+	c := *client
+	c.Transport = transport
+	return &c, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build go1.24
+// +build go1.24
+
+package rpc
+
+import "net/http"
+
+// ConfigureHTTP2 enables HTTP/2 on srv, an HTTP server serving the HTTP transport of a
+// Server. HTTP/2 over TLS is enabled in any case. If cleartext is set, HTTP/2 is also
+// accepted on connections without TLS (h2c) from clients which use it with prior
+// knowledge, see WithH2C. HTTP/1.1 remains available on all connections.
+func ConfigureHTTP2(srv *http.Server, cleartext bool) error {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(cleartext)
+	srv.Protocols = protocols
+	return nil
+}
+
+// h2cTransport returns a copy of rt which uses h2c for http:// URLs and HTTP/2 over TLS
+// for https:// URLs.
+func h2cTransport(rt http.RoundTripper) (http.RoundTripper, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errH2CTransport
+	}
+	t = t.Clone()
+	t.Protocols = new(http.Protocols)
+	t.Protocols.SetHTTP2(true)
+	t.Protocols.SetUnencryptedHTTP2(true)
+	return t, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build go1.24
+// +build go1.24
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestH2CMultiplexedCalls(t *testing.T) {
+	t.Parallel()
+
+	server, svc := newDisconnectServer()
+	defer server.Stop()
+	httpsrv := httptest.NewUnstartedServer(server)
+	if err := ConfigureHTTP2(httpsrv.Config, true); err != nil {
+		t.Fatal(err)
+	}
+	conns := countConns(httpsrv)
+	httpsrv.Start()
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithH2C())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	checkMultiplexedCalls(t, client, svc, conns)
+}
+
+func TestH2CTransportRequired(t *testing.T) {
+	t.Parallel()
+
+	_, err := DialOptions(context.Background(), "http://127.0.0.1:1", WithH2C(), WithHTTPClient(&http.Client{Transport: roundTripperFunc(nil)}))
+	if err != errH2CTransport {
+		t.Fatalf("wrong error %v", err)
+	}
+}
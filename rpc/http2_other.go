@@ -0,0 +1,36 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !go1.24
+// +build !go1.24
+
+package rpc
+
+import "net/http"
+
+// ConfigureHTTP2 enables HTTP/2 on srv, an HTTP server serving the HTTP transport of a
+// Server. HTTP/2 over TLS is enabled by default. Accepting HTTP/2 without TLS (h2c)
+// requires Go 1.24 or later, so an error is returned if cleartext is set.
+func ConfigureHTTP2(srv *http.Server, cleartext bool) error {
+	if cleartext {
+		return errH2CUnsupported
+	}
+	return nil
+}
+
+func h2cTransport(rt http.RoundTripper) (http.RoundTripper, error) {
+	return nil, errH2CUnsupported
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// disconnectService blocks calls until they are canceled.
+type disconnectService struct {
+	started  chan string // HTTP version of started calls
+	canceled chan struct{}
+}
+
+func (s *disconnectService) Wait(ctx context.Context, pad *string) error {
+	s.started <- PeerInfoFromContext(ctx).HTTP.Version
+	<-ctx.Done()
+	s.canceled <- struct{}{}
+	return ctx.Err()
+}
+
+func newDisconnectServer() (*Server, *disconnectService) {
+	server := NewServer()
+	svc := &disconnectService{started: make(chan string, 16), canceled: make(chan struct{}, 16)}
+	server.RegisterName("dc", svc)
+	return server, svc
+}
+
+// countConns makes httpsrv count the connections it accepts.
+func countConns(httpsrv *httptest.Server) *atomic.Int32 {
+	conns := new(atomic.Int32)
+	httpsrv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	return conns
+}
+
+// checkDisconnectCancels checks that the call context of a method is canceled when the
+// client gives up on the call.
+func checkDisconnectCancels(t *testing.T, client *Client, svc *disconnectService) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- client.CallContext(ctx, nil, "dc_wait", strings.Repeat("x", 1<<20)) }()
+	select {
+	case <-svc.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call not started")
+	}
+	cancel()
+	if err := <-errc; err == nil {
+		t.Fatal("canceled call succeeded")
+	}
+	select {
+	case <-svc.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call context not canceled after client disconnect")
+	}
+}
+
+// checkMultiplexedCalls checks that concurrent blocking calls share a single connection,
+// and that canceling them doesn't close it.
+func checkMultiplexedCalls(t *testing.T, client *Client, svc *disconnectService, conns *atomic.Int32) {
+	t.Helper()
+	// Establish the connection first, concurrent calls would otherwise race to dial it.
+	if err := client.Call(nil, "rpc_modules"); err != nil {
+		t.Fatal(err)
+	}
+	const n = 10
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, n)
+	for range n {
+		go func() { errc <- client.CallContext(ctx, nil, "dc_wait", nil) }()
+	}
+	for range n {
+		select {
+		case version := <-svc.started:
+			if version != "HTTP/2.0" {
+				t.Fatalf("call served over %s", version)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("calls not started")
+		}
+	}
+	cancel()
+	for range n {
+		<-errc
+		select {
+		case <-svc.canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("call context not canceled")
+		}
+	}
+	checkDisconnectCancels(t, client, svc)
+	if c := conns.Load(); c != 1 {
+		t.Fatalf("client opened %d connections, want 1", c)
+	}
+}
+
+func TestHTTP2MultiplexedCalls(t *testing.T) {
+	t.Parallel()
+
+	server, svc := newDisconnectServer()
+	defer server.Stop()
+	httpsrv := httptest.NewUnstartedServer(server)
+	httpsrv.EnableHTTP2 = true
+	conns := countConns(httpsrv)
+	httpsrv.StartTLS()
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithHTTPClient(httpsrv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	checkMultiplexedCalls(t, client, svc, conns)
+}
+
+func TestHTTPDisconnectCancelsCall(t *testing.T) {
+	t.Parallel()
+
+	server, svc := newDisconnectServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	checkDisconnectCancels(t, client, svc)
+}
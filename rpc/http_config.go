@@ -0,0 +1,100 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http"
+	"time"
+)
+
+// HTTPConfig configures the limits of requests served over HTTP and websocket, see
+// SetHTTPConfig. Zero values keep the defaults.
+type HTTPConfig struct {
+	// MaxRequestBytes limits the size of HTTP request bodies and of websocket messages
+	// received by the server. The defaults are 5MB for HTTP requests and 32MB for
+	// websocket messages.
+	MaxRequestBytes int
+
+	// MaxHeaderBytes limits the size of the request headers, including the request
+	// line. Requests with larger headers are rejected with status 431. By default,
+	// only the limit of the http.Server applies, which is 1MB unless configured.
+	MaxHeaderBytes int
+
+	// Timeouts of the http.Server created by NewHTTPServer. The defaults are the
+	// values of DefaultHTTPTimeouts. The write timeout also bounds the execution of
+	// calls, see ContextRequestTimeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+// SetHTTPConfig sets the request limits of HTTP and websocket connections. Large limits
+// are needed to support calls with big payloads, e.g. large batches or trace results.
+// The timeouts of the configuration apply to servers created using NewHTTPServer.
+//
+// This method should be called before processing any requests via ServeHTTP or
+// WebsocketHandler.
+func (s *Server) SetHTTPConfig(cfg HTTPConfig) {
+	s.httpConfig = cfg
+	s.httpBodyLimit = defaultBodyLimit
+	s.wsReadLimit = wsDefaultReadLimit
+	if cfg.MaxRequestBytes > 0 {
+		s.httpBodyLimit = cfg.MaxRequestBytes
+		s.wsReadLimit = int64(cfg.MaxRequestBytes)
+	}
+}
+
+// NewHTTPServer creates an http.Server for handler which applies the header limit and
+// timeouts configured with SetHTTPConfig. If handler is nil, the server serves JSON-RPC
+// over HTTP. Use WebsocketHandler or a mux as handler to serve websocket connections.
+func (s *Server) NewHTTPServer(handler http.Handler) *http.Server {
+	if handler == nil {
+		handler = s
+	}
+	cfg := s.httpConfig
+	timeout := func(d, def time.Duration) time.Duration {
+		if d > 0 {
+			return d
+		}
+		return def
+	}
+	return &http.Server{
+		Handler:        handler,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+		ReadTimeout:    timeout(cfg.ReadTimeout, DefaultHTTPTimeouts.ReadTimeout),
+		WriteTimeout:   timeout(cfg.WriteTimeout, DefaultHTTPTimeouts.WriteTimeout),
+		IdleTimeout:    timeout(cfg.IdleTimeout, DefaultHTTPTimeouts.IdleTimeout),
+
+		ReadHeaderTimeout: timeout(cfg.ReadTimeout, DefaultHTTPTimeouts.ReadHeaderTimeout),
+	}
+}
+
+// headerTooLarge reports whether the headers of r exceed the configured limit.
+func (s *Server) headerTooLarge(r *http.Request) bool {
+	limit := s.httpConfig.MaxHeaderBytes
+	if limit <= 0 {
+		return false
+	}
+	// Estimate the size as sent by the client: request line and "Key: value\r\n".
+	size := len(r.Method) + len(r.RequestURI) + len(r.Proto) + 4
+	for key, values := range r.Header {
+		for _, v := range values {
+			size += len(key) + len(v) + 4
+		}
+	}
+	return size > limit
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPConfigLimits(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetHTTPConfig(HTTPConfig{MaxRequestBytes: 1000, MaxHeaderBytes: 2000})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	post := func(body string, header string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		if header != "" {
+			req.Header.Set("X-Test", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	call := func(arg string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["` + arg + `",1]}`
+	}
+	if code := post(call("x"), ""); code != http.StatusOK {
+		t.Errorf("small request: status %d", code)
+	}
+	if code := post(call(strings.Repeat("x", 1000)), ""); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large request: status %d", code)
+	}
+	if code := post(call("x"), strings.Repeat("x", 2000)); code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("large header: status %d", code)
+	}
+}
+
+func TestHTTPConfigWebsocket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetHTTPConfig(HTTPConfig{MaxRequestBytes: 1000})
+	ts := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer ts.Close()
+
+	client, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(ts.URL, "http:"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(&result, "test_echo", strings.Repeat("x", 1000), 1); err == nil {
+		t.Fatal("no error for message exceeding the limit")
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	srv := server.NewHTTPServer(nil)
+	if srv.Handler != server {
+		t.Error("default handler is not the server")
+	}
+	if srv.ReadTimeout != DefaultHTTPTimeouts.ReadTimeout || srv.WriteTimeout != DefaultHTTPTimeouts.WriteTimeout || srv.IdleTimeout != DefaultHTTPTimeouts.IdleTimeout {
+		t.Errorf("wrong default timeouts: read %v, write %v, idle %v", srv.ReadTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+
+	server.SetHTTPConfig(HTTPConfig{MaxHeaderBytes: 4096, ReadTimeout: time.Second, WriteTimeout: 2 * time.Second, IdleTimeout: 3 * time.Second})
+	ws := server.WebsocketHandler([]string{"*"})
+	srv = server.NewHTTPServer(ws)
+	if srv.MaxHeaderBytes != 4096 {
+		t.Errorf("wrong header limit %d", srv.MaxHeaderBytes)
+	}
+	if srv.ReadTimeout != time.Second || srv.ReadHeaderTimeout != time.Second || srv.WriteTimeout != 2*time.Second || srv.IdleTimeout != 3*time.Second {
+		t.Errorf("wrong timeouts: read %v, write %v, idle %v", srv.ReadTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+var errStreamBudgetExceeded = errors.New("streamed response exceeds write budget")
+
+// StreamedResult is a method result whose JSON encoding is produced while the response
+// is written. Methods returning very large results, such as execution traces, can return
+// a *StreamedResult to avoid holding the encoded result in memory.
+//
+// Over HTTP, the result of a single (non-batch) call is streamed to the client using
+// chunked transfer encoding. In all other cases, the result is encoded in memory like any
+// other result. Response checksums, canonical JSON encoding and memory ceilings require
+// the encoded result and disable streaming. Truncation policies and response caching
+// don't apply to streamed results.
+//
+// A StreamedResult can be written only once.
+type StreamedResult struct {
+	writeTo func(w io.Writer) error
+}
+
+// StreamReader returns a result which is read from r. The content of r must be the JSON
+// encoding of the result, it is not validated when streamed. If r implements io.Closer,
+// it is closed once the result has been written.
+func StreamReader(r io.Reader) *StreamedResult {
+	return &StreamedResult{writeTo: func(w io.Writer) error {
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		_, err := io.Copy(w, r)
+		return err
+	}}
+}
+
+// StreamJSON returns a result which is encoded directly into the response, without
+// intermediate copies of the encoding. v can be any value accepted by encoding/json,
+// typically a json.Marshaler.
+func StreamJSON(v interface{}) *StreamedResult {
+	return &StreamedResult{writeTo: func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(v)
+	}}
+}
+
+// MarshalJSON encodes the result in memory. This is used when the result can't be
+// streamed.
+func (s *StreamedResult) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.writeTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SetHTTPStreamBudget limits the number of bytes written for a streamed HTTP response,
+// see StreamedResult. When the budget is exceeded, the response is aborted and the
+// client sees an incomplete response. A budget of zero, the default, means no limit.
+//
+// This method should be called before processing any requests via ServeHTTP.
+func (s *Server) SetHTTPStreamBudget(budget int64) {
+	s.httpStreamBudget = budget
+}
+
+// streamable returns the streamed result of a call, if it can be streamed.
+func (h *handler) streamable(ctx context.Context, result *MethodResult) (*StreamedResult, bool) {
+	stream, ok := result.Result.(*StreamedResult)
+	if !ok || !h.streamResults || h.checksums || h.canonicalJSON {
+		return nil, false
+	}
+	if _, charged := ctx.Value(memoryAccountKey{}).(*memoryAccount); charged {
+		return nil, false
+	}
+	return stream, true
+}
+
+// canStream reports whether codec can write streamed results. Responses in a wire
+// encoding are translated as a whole, so their results can't be streamed.
+func canStream(codec ServerCodec) bool {
+	if jc, ok := codec.(*jsonCodec); ok {
+		if conn, ok := jc.conn.(*httpServerConn); ok {
+			return conn.wire == nil
+		}
+	}
+	return true
+}
+
+// writeStream writes a response message with a streamed result to w.
+func (hc *httpServerConn) writeStream(w http.ResponseWriter, msg *jsonrpcMessage) error {
+	// Encode the message without result, then insert the result before the closing brace.
+	envelope, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	bw := &budgetWriter{w: w, remaining: hc.streamBudget}
+	if hc.streamBudget <= 0 {
+		bw.remaining = -1
+	}
+	err = bw.writeAll(envelope[:len(envelope)-1], []byte(`,"result":`))
+	if err == nil {
+		err = msg.stream.writeTo(bw)
+	}
+	if err == nil {
+		err = bw.writeAll([]byte("}\n"))
+	}
+	if err != nil {
+		// Part of the response has been sent, it can't be replaced by an error response.
+		hc.streamFailed = true
+	}
+	return err
+}
+
+// budgetWriter fails writes exceeding its remaining budget. A negative budget means no
+// limit.
+type budgetWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (bw *budgetWriter) Write(p []byte) (int, error) {
+	if bw.remaining >= 0 {
+		if int64(len(p)) > bw.remaining {
+			return 0, errStreamBudgetExceeded
+		}
+		bw.remaining -= int64(len(p))
+	}
+	return bw.w.Write(p)
+}
+
+func (bw *budgetWriter) writeAll(chunks ...[]byte) error {
+	for _, c := range chunks {
+		if _, err := bw.Write(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type streamTestService struct{}
+
+// Items streams a JSON array of n strings.
+func (s *streamTestService) Items(n int) *StreamedResult {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = "item" + strconv.Itoa(i)
+	}
+	return StreamJSON(items)
+}
+
+// Raw streams the given JSON text.
+func (s *streamTestService) Raw(text string) *StreamedResult {
+	return StreamReader(strings.NewReader(text))
+}
+
+func TestHTTPStreamedResult(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("stream", new(streamTestService))
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	// Large results are sent with chunked transfer encoding.
+	body := `{"jsonrpc":"2.0","id":1,"method":"stream_items","params":[2000]}`
+	resp, err := http.Post(httpsrv.URL, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(resp.TransferEncoding) != 1 || resp.TransferEncoding[0] != "chunked" {
+		t.Errorf("wrong transfer encoding %v", resp.TransferEncoding)
+	}
+
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var items []string
+	if err := client.Call(&items, "stream_items", 2000); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2000 || items[1999] != "item1999" {
+		t.Fatalf("wrong result: %d items", len(items))
+	}
+	var raw map[string]int
+	if err := client.Call(&raw, "stream_raw", `{"a":1}`); err != nil {
+		t.Fatal(err)
+	}
+	if raw["a"] != 1 {
+		t.Fatalf("wrong result %v", raw)
+	}
+
+	// In batches, results are encoded in memory.
+	batch := []BatchElem{
+		{Method: "stream_items", Args: []interface{}{3}, Result: new([]string)},
+		{Method: "stream_raw", Args: []interface{}{`"x"`}, Result: new(string)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if r := *batch[0].Result.(*[]string); batch[0].Error != nil || len(r) != 3 {
+		t.Fatalf("wrong batch result 0: %v %v", r, batch[0].Error)
+	}
+	if r := *batch[1].Result.(*string); batch[1].Error != nil || r != "x" {
+		t.Fatalf("wrong batch result 1: %q %v", r, batch[1].Error)
+	}
+}
+
+func TestHTTPStreamBudget(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("stream", new(streamTestService))
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	server.SetHTTPStreamBudget(4096)
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var items []string
+	if err := client.Call(&items, "stream_items", 10); err != nil {
+		t.Fatal("small result failed:", err)
+	}
+	if err := client.Call(&items, "stream_items", 5000); err == nil {
+		t.Fatal("no error for result exceeding the budget")
+	}
+}
+
+func TestStreamedResultInProc(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("stream", new(streamTestService)); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var items []string
+	if err := client.Call(&items, "stream_items", 5); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 || items[4] != "item4" {
+		t.Fatalf("wrong result %v", items)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// ServerMetrics receives instrumentation events from the server. It can be used to feed
+// call statistics into a monitoring system such as Prometheus. Implementations must be
+// safe for concurrent use and should not block, since they are called on the goroutines
+// processing requests.
+type ServerMetrics interface {
+	// CallStarted is called when the server starts processing a method call or
+	// notification. The method is "other" if it doesn't exist or exceeds the
+	// cardinality limit, see SetMetricsCardinality.
+	CallStarted(method string)
+
+	// CallFinished is called when processing of a call started with CallStarted has
+	// ended. The code is the JSON-RPC error code of the response, or zero if the call
+	// succeeded. Codes exceeding the cardinality limit are reported as -32000.
+	CallFinished(method string, duration time.Duration, code int)
+
+	// BatchReceived is called with the number of messages of each batch request.
+	BatchReceived(size int)
+}
+
+// SetMetrics installs m to receive instrumentation events. Passing nil removes it.
+func (s *Server) SetMetrics(m ServerMetrics) {
+	s.services.updateConfig(func(c *registryConfig) { c.metrics = m })
+}
+
+// instrumentedMethod returns the method name reported to ServerMetrics. Names of unknown
+// methods are not reported to avoid creating unbounded numbers of metrics, see
+// SetMetricsCardinality.
+func (r *serviceRegistry) instrumentedMethod(method string) string {
+	namespace, name, found := strings.Cut(method, serviceMethodSeparator)
+	if !found {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.factories[namespace] != nil {
+		return method
+	}
+	svc, ok := r.services[r.serviceName(namespace, nil)]
+	switch {
+	case !ok:
+		return ""
+	case svc.callbacks[name] != nil:
+		return method
+	case len(svc.subscriptions) > 0 && (method == namespace+subscribeMethodSuffix || method == namespace+unsubscribeMethodSuffix):
+		return method
+	default:
+		return ""
+	}
+}
+
+// NewServerMetrics returns a ServerMetrics implementation which maintains metrics in the
+// given go-ethereum metrics registry, or the default registry if it is nil. Registries
+// can be exported to Prometheus using the metrics/prometheus package. The following
+// metrics are created:
+//
+//	rpc/inflight                  gauge of calls being processed
+//	rpc/calls/<method>            counter of calls, by method
+//	rpc/calls/<method>/duration   timer of call durations, by method
+//	rpc/errors/<code>             counter of error responses, by error code
+//	rpc/batch/size                histogram of batch sizes
+func NewServerMetrics(registry metrics.Registry) ServerMetrics {
+	return &registryMetrics{
+		registry:  registry,
+		inflight:  metrics.GetOrRegisterGauge("rpc/inflight", registry),
+		batchSize: metrics.GetOrRegisterHistogram("rpc/batch/size", registry, metrics.NewExpDecaySample(1028, 0.015)),
+	}
+}
+
+type registryMetrics struct {
+	registry  metrics.Registry
+	inflight  *metrics.Gauge
+	batchSize metrics.Histogram
+}
+
+func (m *registryMetrics) CallStarted(method string) {
+	m.inflight.Inc(1)
+}
+
+func (m *registryMetrics) CallFinished(method string, duration time.Duration, code int) {
+	m.inflight.Dec(1)
+	metrics.GetOrRegisterCounter("rpc/calls/"+method, m.registry).Inc(1)
+	metrics.GetOrRegisterTimer("rpc/calls/"+method+"/duration", m.registry).Update(duration)
+	if code != 0 {
+		metrics.GetOrRegisterCounter(fmt.Sprintf("rpc/errors/%d", code), m.registry).Inc(1)
+	}
+}
+
+func (m *registryMetrics) BatchReceived(size int) {
+	m.batchSize.Update(int64(size))
+}
+
+// instrumentCall reports the start of a call to the server metrics. The returned function
+// must be called with the response when the call has been processed.
+func (h *handler) instrumentCall(cfg *registryConfig, msg *jsonrpcMessage) func(resp *jsonrpcMessage) {
+	m := cfg.metrics
+	if m == nil {
+		return func(*jsonrpcMessage) {}
+	}
+	method := h.reg.labels.method(h.reg.instrumentedMethod(msg.Method))
+	start := time.Now()
+	m.CallStarted(method)
+	return func(resp *jsonrpcMessage) {
+		var code int
+		if resp != nil && resp.Error != nil {
+			code = resp.Error.Code
+		}
+		m.CallFinished(method, time.Since(start), h.reg.labels.errorCode(code))
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	inflight int
+	calls    []string // method:code
+	batches  []int
+}
+
+func (m *recordingMetrics) CallStarted(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight++
+}
+
+func (m *recordingMetrics) CallFinished(method string, duration time.Duration, code int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight--
+	m.calls = append(m.calls, fmt.Sprintf("%s:%d", method, code))
+}
+
+func (m *recordingMetrics) BatchReceived(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, size)
+}
+
+func TestServerMetrics(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	m := new(recordingMetrics)
+	server.SetMetrics(m)
+	server.SetSynchronousExecution(true)
+	client := DialInProc(server)
+	defer client.Close()
+
+	client.Call(nil, "test_echo", "x", 1)
+	client.Call(nil, "test_returnError")
+	client.Call(nil, "test_nonexistent")
+	client.BatchCall([]BatchElem{
+		{Method: "test_echo", Args: []interface{}{"x", 1}},
+		{Method: "test_echo", Args: []interface{}{"y", 2}},
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wantCalls := []string{"test_echo:0", "test_returnError:444", "other:-32601", "test_echo:0", "test_echo:0"}
+	if !reflect.DeepEqual(m.calls, wantCalls) {
+		t.Errorf("wrong calls %v, want %v", m.calls, wantCalls)
+	}
+	if !reflect.DeepEqual(m.batches, []int{2}) {
+		t.Errorf("wrong batches %v", m.batches)
+	}
+	if m.inflight != 0 {
+		t.Errorf("wrong in-flight count %d", m.inflight)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// openrpcVersion is the version of the OpenRPC specification of generated documents.
+const openrpcVersion = "1.2.6"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+)
+
+// MethodInfo describes a method or subscription of a registered service. It is
+// returned by rpc_methods.
+type MethodInfo struct {
+	Name         string      `json:"name"`                   // full method name, or subscription name
+	Params       []ParamInfo `json:"params"`                 // parameters, excluding the context
+	Result       *ParamInfo  `json:"result,omitempty"`       // nil if the method has no result
+	Subscription bool        `json:"subscription,omitempty"` // subscribed to using <namespace>_subscribe
+	Deprecated   bool        `json:"deprecated,omitempty"`   // deprecated alias, see Server.Alias
+}
+
+// ParamInfo describes a parameter or result of a method.
+type ParamInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`     // Go type
+	Required bool   `json:"required"` // trailing pointer parameters may be omitted
+}
+
+// Methods returns the methods and subscriptions of all registered services, keyed by
+// namespace. Versioned namespaces are listed under their versioned name, e.g. "eth@2".
+// Methods of service factories and proxies are not known before they are called and
+// aren't included. Raw handlers are listed without parameters and result.
+func (s *RPCService) Methods() map[string][]MethodInfo {
+	return s.server.services.methods()
+}
+
+// Discover returns the OpenRPC document of the server, see Server.OpenRPCSchema.
+func (s *RPCService) Discover() (json.RawMessage, error) {
+	return s.server.OpenRPCSchema()
+}
+
+// OpenRPCSchema generates an OpenRPC document describing the methods of all registered
+// services, for use by tooling and client code generators. Parameter and result
+// schemas are derived from the Go types of the methods the way encoding/json encodes
+// them: named struct types are placed in the "components" section and referenced.
+// Parameters are named by position, e.g. "arg0".
+//
+// Subscriptions, raw handlers and the methods of factories and proxies can't be
+// described and are omitted. Aliases are described like their targets.
+//
+// The document can be passed to NewParamValidator to validate calls against it.
+func (s *Server) OpenRPCSchema() ([]byte, error) {
+	b := newSchemaBuilder()
+	doc := map[string]any{
+		"openrpc": openrpcVersion,
+		"info":    map[string]string{"title": "JSON-RPC API", "version": "1.0.0"},
+	}
+	var methods []map[string]any
+	for _, namespace := range s.services.namespaces() {
+		for _, m := range s.services.namespaceMethods(namespace) {
+			if m.Subscription || m.callb.raw != nil {
+				continue
+			}
+			methods = append(methods, b.method(m))
+		}
+	}
+	doc["methods"] = methods
+	if len(b.schemas) > 0 {
+		doc["components"] = map[string]any{"schemas": b.schemas}
+	}
+	return json.Marshal(doc)
+}
+
+// methodEntry is a method along with its callback.
+type methodEntry struct {
+	MethodInfo
+	callb *callback
+}
+
+// namespaces returns the names of all registered services in order.
+func (r *serviceRegistry) namespaces() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.services))
+	for name := range r.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// methods implements RPCService.Methods.
+func (r *serviceRegistry) methods() map[string][]MethodInfo {
+	result := make(map[string][]MethodInfo)
+	for _, namespace := range r.namespaces() {
+		entries := r.namespaceMethods(namespace)
+		infos := make([]MethodInfo, len(entries))
+		for i, e := range entries {
+			infos[i] = e.MethodInfo
+		}
+		result[namespace] = infos
+	}
+	return result
+}
+
+// namespaceMethods describes the methods and subscriptions of a service, ordered by
+// name with subscriptions last. Aliases in the namespace are included.
+func (r *serviceRegistry) namespaceMethods(namespace string) []methodEntry {
+	r.mu.Lock()
+	svc := r.services[namespace]
+	var entries, subs []methodEntry
+	for name, cb := range svc.callbacks {
+		entries = append(entries, methodEntry{describeCallback(namespace+serviceMethodSeparator+name, cb), cb})
+	}
+	for name, cb := range svc.subscriptions {
+		e := methodEntry{describeCallback(name, cb), cb}
+		e.Subscription = true
+		subs = append(subs, e)
+	}
+	r.mu.Unlock()
+
+	for name, alias := range r.snapshot().aliases {
+		if before, _, _ := strings.Cut(name, serviceMethodSeparator); before != namespace {
+			continue
+		}
+		if cb := r.callback(alias.target); cb != nil {
+			e := methodEntry{describeCallback(name, cb), cb}
+			e.Deprecated = alias.deprecated
+			entries = append(entries, e)
+		}
+	}
+	byName := func(list []methodEntry) {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+	byName(entries)
+	byName(subs)
+	return append(entries, subs...)
+}
+
+// describeCallback derives the description of a method from its Go signature.
+func describeCallback(name string, cb *callback) MethodInfo {
+	info := MethodInfo{Name: name, Params: []ParamInfo{}}
+	if cb.raw != nil {
+		return info
+	}
+	optional := len(cb.argTypes)
+	for optional > 0 && cb.argTypes[optional-1].Kind() == reflect.Pointer {
+		optional--
+	}
+	for i, t := range cb.argTypes {
+		info.Params = append(info.Params, ParamInfo{Name: fmt.Sprintf("arg%d", i), Type: t.String(), Required: i < optional})
+	}
+	if t := cb.resultType(); t != nil && !cb.isSubscribe {
+		info.Result = &ParamInfo{Name: "result", Type: t.String(), Required: true}
+	}
+	return info
+}
+
+// resultType returns the type of the non-error result of the callback, or nil.
+func (c *callback) resultType() reflect.Type {
+	if !c.fn.IsValid() {
+		return nil
+	}
+	fntype := c.fn.Type()
+	if fntype.NumOut() == 0 || c.errPos == 0 {
+		return nil
+	}
+	return fntype.Out(0)
+}
+
+// schemaBuilder derives JSON schemas from Go types.
+type schemaBuilder struct {
+	schemas map[string]any          // named schemas of struct types
+	names   map[reflect.Type]string // component names of struct types
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: make(map[string]any), names: make(map[reflect.Type]string)}
+}
+
+// method creates the OpenRPC method object of m.
+func (b *schemaBuilder) method(m methodEntry) map[string]any {
+	params := make([]map[string]any, len(m.Params))
+	for i, p := range m.Params {
+		params[i] = map[string]any{
+			"name":     p.Name,
+			"required": p.Required,
+			"schema":   b.schema(m.callb.argTypes[i]),
+		}
+	}
+	obj := map[string]any{"name": m.Name, "params": params}
+	if t := m.callb.resultType(); t != nil {
+		obj["result"] = map[string]any{"name": "result", "schema": b.schema(t)}
+	} else {
+		obj["result"] = map[string]any{"name": "result", "schema": map[string]any{"type": "null"}}
+	}
+	if m.Deprecated {
+		obj["deprecated"] = true
+	}
+	return obj
+}
+
+// schema returns the JSON schema of values of type t.
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == bigIntType:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Pointer && t.Elem() == bigIntType:
+		return nullable(map[string]any{"type": "integer"})
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]any{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(b.schema(t.Elem()))
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(map[string]any{"type": "string", "contentEncoding": "base64"})
+		}
+		return nullable(map[string]any{"type": "array", "items": b.schema(t.Elem())})
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return nullable(map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + b.component(t)}
+	default:
+		// Interfaces accept any value. Other kinds can't be encoded.
+		return map[string]any{}
+	}
+}
+
+// component returns the component name of a named struct type, adding its schema to
+// the components on first use.
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for i := 2; b.schemas[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", t.Name(), i)
+	}
+	// Reserve the name before building the schema, for recursive types.
+	b.names[t] = name
+	b.schemas[name] = map[string]any{}
+	b.schemas[name] = b.object(t)
+	return name
+}
+
+// object returns the schema of a struct type. Fields are not required since
+// encoding/json accepts objects with missing fields.
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	b.addFields(props, t)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+// addFields adds the schemas of the fields of struct type t to props, following the
+// encoding/json rules for field names and embedded structs. Fields of embedded structs
+// don't replace fields of the outer struct.
+func (b *schemaBuilder) addFields(props map[string]any, t reflect.Type) {
+	outer := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(outer, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(","+opts+",", ",string,") {
+			props[name] = map[string]any{"type": "string"}
+		} else {
+			props[name] = b.schema(ft)
+		}
+	}
+	for name, schema := range outer {
+		if _, ok := props[name]; !ok {
+			props[name] = schema
+		}
+	}
+}
+
+// nullable extends a schema to accept null.
+func nullable(s map[string]any) map[string]any {
+	if len(s) == 0 {
+		return s
+	}
+	if t, ok := s["type"].(string); ok {
+		s["type"] = []string{t, "null"}
+		return s
+	}
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRPCMethods(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.Alias("test_oldEcho", "test_echo", DeprecationWarning); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var methods map[string][]MethodInfo
+	if err := client.Call(&methods, "rpc_methods"); err != nil {
+		t.Fatal(err)
+	}
+	find := func(namespace, name string) *MethodInfo {
+		for _, m := range methods[namespace] {
+			if m.Name == name {
+				return &m
+			}
+		}
+		t.Fatalf("method %s missing in namespace %s", name, namespace)
+		return nil
+	}
+
+	echo := find("test", "test_echo")
+	want := MethodInfo{
+		Name: "test_echo",
+		Params: []ParamInfo{
+			{Name: "arg0", Type: "string", Required: true},
+			{Name: "arg1", Type: "int", Required: true},
+			{Name: "arg2", Type: "*rpc.echoArgs", Required: false},
+		},
+		Result: &ParamInfo{Name: "result", Type: "rpc.echoResult", Required: true},
+	}
+	if !reflect.DeepEqual(*echo, want) {
+		t.Errorf("wrong test_echo description\nhave %+v\nwant %+v", *echo, want)
+	}
+	if m := find("test", "test_returnError"); m.Result != nil || len(m.Params) != 0 {
+		t.Errorf("wrong test_returnError description %+v", *m)
+	}
+	if m := find("test", "test_oldEcho"); !m.Deprecated || len(m.Params) != 3 {
+		t.Errorf("wrong alias description %+v", *m)
+	}
+	if m := find("nftest", "someSubscription"); !m.Subscription || m.Result != nil || len(m.Params) != 2 {
+		t.Errorf("wrong subscription description %+v", *m)
+	}
+}
+
+func TestOpenRPCSchema(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc json.RawMessage
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		OpenRPC string `json:"openrpc"`
+		Methods []struct {
+			Name string `json:"name"`
+		} `json:"methods"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(doc, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.OpenRPC != openrpcVersion {
+		t.Errorf("wrong openrpc version %q", parsed.OpenRPC)
+	}
+	names := make(map[string]bool)
+	for _, m := range parsed.Methods {
+		names[m.Name] = true
+	}
+	if !names["test_echo"] || !names["rpc_modules"] {
+		t.Errorf("methods missing in document: %v", names)
+	}
+	if names["someSubscription"] {
+		t.Error("subscription included in document")
+	}
+	wantResult := `{"properties":{"Args":{"anyOf":[{"$ref":"#/components/schemas/echoArgs"},{"type":"null"}]},"Int":{"type":"integer"},"String":{"type":"string"}},"type":"object"}`
+	if s := string(parsed.Components.Schemas["echoResult"]); s != wantResult {
+		t.Errorf("wrong echoResult schema\nhave %s\nwant %s", s, wantResult)
+	}
+
+	// The document can be used to validate calls.
+	v, err := NewParamValidator(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, params := range []string{`["x", 1]`, `["x", 1, null]`, `["x", 1, {"S": "y"}]`} {
+		if err := v.Validate("test_echo", json.RawMessage(params)); err != nil {
+			t.Errorf("params %s rejected: %v", params, err)
+		}
+	}
+	for _, params := range []string{`["x"]`, `["x", "1"]`, `["x", 1, {"S": 2}]`} {
+		if err := v.Validate("test_echo", json.RawMessage(params)); err == nil {
+			t.Errorf("params %s accepted", params)
+		}
+	}
+}
+
+type schemaTestInner struct {
+	A string `json:"a"`
+	B int    `json:"b"`
+}
+
+type schemaTestOuter struct {
+	schemaTestInner
+	B     string            `json:"b"`
+	Skip  int               `json:"-"`
+	Num   int               `json:"num,string,omitempty"`
+	Bytes []byte            `json:"bytes"`
+	Next  *schemaTestOuter  `json:"next"`
+	Tags  map[string]uint64 `json:"tags"`
+	Raw   json.RawMessage   `json:"raw"`
+	skip  int
+}
+
+func TestSchemaBuilder(t *testing.T) {
+	b := newSchemaBuilder()
+	ref, _ := json.Marshal(b.schema(reflect.TypeOf(schemaTestOuter{})))
+	if want := `{"$ref":"#/components/schemas/schemaTestOuter"}`; string(ref) != want {
+		t.Fatalf("wrong reference %s", ref)
+	}
+	have, _ := json.Marshal(b.schemas["schemaTestOuter"])
+	want := `{"properties":{` +
+		`"a":{"type":"string"},` +
+		`"b":{"type":"string"},` +
+		`"bytes":{"contentEncoding":"base64","type":["string","null"]},` +
+		`"next":{"anyOf":[{"$ref":"#/components/schemas/schemaTestOuter"},{"type":"null"}]},` +
+		`"num":{"type":"string"},` +
+		`"raw":{},` +
+		`"tags":{"additionalProperties":{"type":"integer"},"type":["object","null"]}` +
+		`},"type":"object"}`
+	if string(have) != want {
+		t.Errorf("wrong schema\nhave %s\nwant %s", have, want)
+	}
+}
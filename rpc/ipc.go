@@ -19,6 +19,7 @@ package rpc
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
@@ -35,7 +36,13 @@ func (s *Server) ServeListener(l net.Listener) error {
 			return err
 		}
 		log.Trace("Accepted RPC connection", "conn", conn.RemoteAddr())
-		go s.ServeCodec(NewCodec(conn), 0)
+		codec := NewCodec(conn)
+		if s.services.snapshot().ipcPolicy != nil && codec.peerInfo().Cred == nil {
+			log.Debug("Rejected RPC connection without peer credentials", "conn", conn.RemoteAddr())
+			codec.close()
+			continue
+		}
+		go s.ServeCodec(codec, 0)
 	}
 }
 
@@ -44,15 +51,23 @@ func (s *Server) ServeListener(l net.Listener) error {
 // identifier for a named pipe.
 //
 // The context is used for the initial connection establishment. It does not
-// affect subsequent interactions with the client.
-func DialIPC(ctx context.Context, endpoint string) (*Client, error) {
+// affect subsequent interactions with the client. Use WithReconnect to configure the
+// dial timeout and make the client redial the endpoint when the connection is lost.
+func DialIPC(ctx context.Context, endpoint string, options ...ClientOption) (*Client, error) {
 	cfg := new(clientConfig)
-	return newClient(ctx, cfg, newClientTransportIPC(endpoint))
+	for _, opt := range options {
+		opt.applyOption(cfg)
+	}
+	return newClient(ctx, cfg, newClientTransportIPC(endpoint, cfg))
 }
 
-func newClientTransportIPC(endpoint string) reconnectFunc {
+func newClientTransportIPC(endpoint string, cfg *clientConfig) reconnectFunc {
+	var timeout time.Duration
+	if cfg.reconnectPolicy != nil {
+		timeout = cfg.reconnectPolicy.DialTimeout
+	}
 	return func(ctx context.Context) (ServerCodec, error) {
-		conn, err := newIPCConnection(ctx, endpoint)
+		conn, err := newIPCConnection(ctx, endpoint, timeout)
 		if err != nil {
 			return nil, err
 		}
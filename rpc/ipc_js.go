@@ -23,6 +23,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"time"
 )
 
 var errNotSupported = errors.New("rpc: not supported")
@@ -33,6 +34,6 @@ func ipcListen(endpoint string) (net.Listener, error) {
 }
 
 // newIPCConnection will connect to a named pipe with the given endpoint as name.
-func newIPCConnection(ctx context.Context, endpoint string) (net.Conn, error) {
+func newIPCConnection(ctx context.Context, endpoint string, timeout time.Duration) (net.Conn, error) {
 	return nil, errNotSupported
 }
@@ -0,0 +1,62 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"slices"
+)
+
+// PeerCred holds the credentials of the process on the other end of an IPC
+// connection, as reported by the operating system.
+type PeerCred struct {
+	PID int
+	UID int
+	GID int
+}
+
+// IPCPolicy decides whether a peer with the given credentials may call methods in
+// namespace. It is consulted for every call received over IPC.
+type IPCPolicy func(cred PeerCred, namespace string) bool
+
+// NamespacesByUID returns a policy which allows each uid to access only the listed
+// namespaces. Peers whose uid is not in allowed are restricted to fallback.
+func NamespacesByUID(allowed map[int][]string, fallback []string) IPCPolicy {
+	return func(cred PeerCred, namespace string) bool {
+		list, ok := allowed[cred.UID]
+		if !ok {
+			list = fallback
+		}
+		return slices.Contains(list, namespace)
+	}
+}
+
+// SetIPCPolicy restricts the namespaces available to IPC clients based on their peer
+// credentials. While a policy is set, ServeListener closes connections whose peer
+// credentials cannot be determined, which includes all connections on platforms other
+// than Linux. In-process and stdio connections are not restricted. Passing nil removes
+// the policy.
+func (s *Server) SetIPCPolicy(policy IPCPolicy) {
+	s.services.updateConfig(func(c *registryConfig) { c.ipcPolicy = policy })
+}
+
+// ipcAllowed reports whether peer may call methods in namespace under the IPC policy.
+func (c *registryConfig) ipcAllowed(peer PeerInfo, namespace string) bool {
+	if c.ipcPolicy == nil || peer.Cred == nil {
+		return true
+	}
+	return c.ipcPolicy(*peer.Cred, namespace)
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build linux
+// +build linux
+
+package rpc
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestIPCPeerCredentials(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client, l := ipcTestClient(server, nil)
+	defer l.Close()
+	defer client.Close()
+
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	want := PeerCred{PID: os.Getpid(), UID: os.Getuid(), GID: os.Getgid()}
+	if info.Cred == nil || *info.Cred != want {
+		t.Fatalf("wrong peer credentials %+v, want %+v", info.Cred, want)
+	}
+}
+
+func TestIPCPolicy(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetIPCPolicy(NamespacesByUID(map[int][]string{os.Getuid(): {"rpc"}}, nil))
+	client, l := ipcTestClient(server, nil)
+	defer l.Close()
+	defer client.Close()
+
+	var modules map[string]string
+	if err := client.Call(&modules, "rpc_modules"); err != nil {
+		t.Fatal("rpc_modules should be allowed:", err)
+	}
+	var rerr Error
+	err := client.Call(nil, "test_echo", "x", 1, nil)
+	if !errors.As(err, &rerr) || rerr.ErrorCode() != -32601 {
+		t.Fatalf("expected method not found error, got %v", err)
+	}
+
+	// Removing the policy makes all namespaces available again.
+	server.SetIPCPolicy(nil)
+	if err := client.Call(nil, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNamespacesByUID(t *testing.T) {
+	policy := NamespacesByUID(map[int][]string{0: {"admin", "eth"}}, []string{"eth"})
+	tests := []struct {
+		uid       int
+		namespace string
+		want      bool
+	}{
+		{0, "admin", true},
+		{0, "eth", true},
+		{1000, "eth", true},
+		{1000, "admin", false},
+	}
+	for _, test := range tests {
+		if got := policy(PeerCred{UID: test.uid}, test.namespace); got != test.want {
+			t.Errorf("uid %d, namespace %q: got %t, want %t", test.uid, test.namespace, got, test.want)
+		}
+	}
+}
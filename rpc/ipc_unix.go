@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -58,7 +59,13 @@ func ipcListen(endpoint string) (net.Listener, error) {
 	return l, nil
 }
 
-// newIPCConnection will connect to a Unix socket on the given endpoint.
-func newIPCConnection(ctx context.Context, endpoint string) (net.Conn, error) {
+// newIPCConnection will connect to a Unix socket on the given endpoint. If timeout is
+// positive, the connection attempt is aborted after that time.
+func newIPCConnection(ctx context.Context, endpoint string, timeout time.Duration) (net.Conn, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	return new(net.Dialer).DialContext(ctx, "unix", endpoint)
 }
@@ -27,7 +27,7 @@ import (
 	"github.com/Microsoft/go-winio"
 )
 
-// This is used if the dialing context has no deadline. It is much smaller than the
+// This is used if no dial timeout is configured. It is much smaller than the
 // defaultDialTimeout because named pipes are local and there is no need to wait so long.
 const defaultPipeDialTimeout = 2 * time.Second
 
@@ -36,9 +36,13 @@ func ipcListen(endpoint string) (net.Listener, error) {
 	return winio.ListenPipe(endpoint, nil)
 }
 
-// newIPCConnection will connect to a named pipe with the given endpoint as name.
-func newIPCConnection(ctx context.Context, endpoint string) (net.Conn, error) {
-	ctx, cancel := context.WithTimeout(ctx, defaultPipeDialTimeout)
+// newIPCConnection will connect to a named pipe with the given endpoint as name. If
+// timeout is zero, defaultPipeDialTimeout is used.
+func newIPCConnection(ctx context.Context, endpoint string, timeout time.Duration) (net.Conn, error) {
+	if timeout <= 0 {
+		timeout = defaultPipeDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	return winio.DialPipeContext(ctx, endpoint)
 }
@@ -0,0 +1,656 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// JournalState is the state of a journaled call.
+type JournalState int
+
+const (
+	// JournalPending means the call was recorded, but the client did not finish writing
+	// it to the connection.
+	JournalPending JournalState = iota
+	// JournalSent means the call was written to the connection, but no response was
+	// received. The server may or may not have processed it.
+	JournalSent
+	// JournalAcknowledged means a response was received.
+	JournalAcknowledged
+	// JournalFailed means sending the call failed. Note that for HTTP, the request may
+	// still have reached the server.
+	JournalFailed
+)
+
+func (s JournalState) String() string {
+	switch s {
+	case JournalPending:
+		return "pending"
+	case JournalSent:
+		return "sent"
+	case JournalAcknowledged:
+		return "acknowledged"
+	case JournalFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("JournalState(%d)", int(s))
+	}
+}
+
+// JournalSyncPolicy determines which journal records are synced to disk before the call
+// proceeds.
+type JournalSyncPolicy int
+
+const (
+	// JournalSyncCritical syncs the records of calls being started and sent. Records of
+	// responses may be lost in a crash, in which case the call appears as sent. This is
+	// the default.
+	JournalSyncCritical JournalSyncPolicy = iota
+	// JournalSyncAll syncs every record.
+	JournalSyncAll
+	// JournalSyncNone leaves syncing to the operating system. Records may be lost in a
+	// crash of the machine.
+	JournalSyncNone
+)
+
+// JournalConfig configures the storage of a journal. The zero value keeps all records in
+// a single file, which only shrinks when the journal is compacted.
+type JournalConfig struct {
+	// MaxSize is the size at which the journal file is rotated. On rotation, the file is
+	// moved to an archive next to it, and a new file containing the unfinished calls is
+	// started. Zero disables rotation.
+	MaxSize int64
+
+	// Codec compresses archives, e.g. GzipCodec. Archives are not compressed if nil.
+	Codec StorageCodec
+
+	// MaxAge is the age at which archives are deleted. Zero keeps archives regardless of
+	// their age.
+	MaxAge time.Duration
+
+	// MaxArchives is the number of archives kept. The oldest archives are deleted first.
+	// Zero keeps all archives.
+	MaxArchives int
+
+	// Sync is the policy for syncing records to disk.
+	Sync JournalSyncPolicy
+
+	// Encryption provides the keys for encrypting journal records, see NewEncryptedCodec.
+	// When set, every record is encrypted before it is written, including the records
+	// of archives. Records written without encryption remain readable.
+	Encryption StorageKeys
+}
+
+// JournalEntry is a call recorded in a Journal.
+type JournalEntry struct {
+	Seq    uint64          // sequence number, unique within the journal
+	Method string          // method name
+	Params json.RawMessage // encoded parameters
+	Time   time.Time       // when the call was started
+	State  JournalState
+	Result json.RawMessage // result of an acknowledged call
+	Error  string          // error of an acknowledged or failed call
+}
+
+// Journal is a client-side write-ahead log of state-changing calls, such as transaction
+// submissions. Every call is recorded before it is sent, and its progress is appended as
+// the call proceeds. After a crash, the journal can be read with ReadJournal to find out
+// which calls were sent and whether they were acknowledged by the server.
+//
+// Records are synced to disk before the call is written to the connection, so a call
+// that reached the server always has an entry. See JournalConfig for rotating and
+// archiving the journal file.
+type Journal struct {
+	mu      sync.Mutex
+	f       *os.File
+	size    int64 // size of f
+	config  JournalConfig
+	records StorageCodec // encrypts records, nil if disabled
+	nextSeq uint64
+	methods map[string]bool
+}
+
+type journalRecord struct {
+	Op     string          `json:"op"`
+	Seq    uint64          `json:"seq"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Time   int64           `json:"time,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+const (
+	journalOpBegin  = "begin"
+	journalOpSent   = "sent"
+	journalOpAck    = "ack"
+	journalOpFailed = "failed"
+)
+
+// OpenJournal opens or creates the journal file at path. Only calls of the given methods
+// are recorded.
+func OpenJournal(path string, methods ...string) (*Journal, error) {
+	return OpenJournalWithConfig(path, JournalConfig{}, methods...)
+}
+
+// OpenJournalWithConfig is like OpenJournal, but applies the given configuration.
+func OpenJournalWithConfig(path string, config JournalConfig, methods ...string) (*Journal, error) {
+	if len(methods) == 0 {
+		return nil, errors.New("no methods to journal")
+	}
+	entries, err := ReadJournalWithConfig(path, config)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// Terminate a truncated last record, so it doesn't corrupt the next one.
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			f.Write([]byte{'\n'})
+		}
+	}
+	j := &Journal{f: f, config: config, nextSeq: 1, methods: make(map[string]bool, len(methods))}
+	if config.Encryption != nil {
+		j.records = NewEncryptedCodec(config.Encryption, nil)
+	}
+	if info, err := f.Stat(); err == nil {
+		j.size = info.Size()
+	}
+	for _, e := range entries {
+		j.nextSeq = max(j.nextSeq, e.Seq+1)
+	}
+	for _, m := range methods {
+		j.methods[m] = true
+	}
+	return j, nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.f.Close()
+}
+
+// ReadJournal reads all entries of the journal file at path and its archives, ordered by
+// sequence number. Truncated records at the end of the file, which can be left behind by
+// a crash, are ignored. Archives compressed with GzipCodec can always be read, archives
+// compressed with other codecs require passing the codec. Encrypted journals must be read
+// using ReadJournalWithConfig.
+func ReadJournal(path string, codecs ...StorageCodec) ([]JournalEntry, error) {
+	return journalReader{codecs: codecs}.read(path)
+}
+
+// ReadJournalWithConfig is like ReadJournal, but uses the codec and encryption keys of
+// the given configuration.
+func ReadJournalWithConfig(path string, config JournalConfig) ([]JournalEntry, error) {
+	var r journalReader
+	if config.Codec != nil {
+		r.codecs = append(r.codecs, config.Codec)
+	}
+	if config.Encryption != nil {
+		r.records = NewEncryptedCodec(config.Encryption, nil)
+	}
+	return r.read(path)
+}
+
+// journalReader reads journal files.
+type journalReader struct {
+	codecs  []StorageCodec // codecs of archives
+	records StorageCodec   // decrypts records, nil if not available
+}
+
+func (jr journalReader) read(path string) ([]JournalEntry, error) {
+	entries := make(map[uint64]*JournalEntry)
+	archives, err := journalArchives(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range archives {
+		if err := jr.readArchive(a, entries); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer f.Close()
+		if err := jr.readRecords(f, entries); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, os.ErrNotExist) || len(archives) == 0:
+		return nil, err
+	}
+	list := make([]JournalEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, *e)
+	}
+	slices.SortFunc(list, func(a, b JournalEntry) int { return cmp.Compare(a.Seq, b.Seq) })
+	return list, nil
+}
+
+// readArchive adds the records of an archive to entries.
+func (jr journalReader) readArchive(a journalArchive, entries map[uint64]*JournalEntry) error {
+	var codec StorageCodec
+	switch {
+	case a.codec == "":
+	case a.codec == GzipCodec.Name():
+		codec = GzipCodec
+	default:
+		i := slices.IndexFunc(jr.codecs, func(c StorageCodec) bool { return c.Name() == a.codec })
+		if i < 0 {
+			return fmt.Errorf("no codec for journal archive %s", a.path)
+		}
+		codec = jr.codecs[i]
+	}
+	f, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if codec == nil {
+		return jr.readRecords(f, entries)
+	}
+	r, err := codec.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := jr.readRecords(r, entries); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	return nil
+}
+
+// readRecords applies the records read from r to entries.
+func (jr journalReader) readRecords(r io.Reader, entries map[uint64]*JournalEntry) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) > 0 && line[0] != '{' {
+			var err error
+			if line, err = jr.decryptRecord(line); err != nil {
+				return err
+			}
+		}
+		var rec journalRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		if rec.Op == journalOpBegin {
+			// Unfinished calls are carried over into the new file on rotation.
+			if entries[rec.Seq] == nil {
+				entries[rec.Seq] = &JournalEntry{
+					Seq:    rec.Seq,
+					Method: rec.Method,
+					Params: rec.Params,
+					Time:   time.UnixMilli(rec.Time),
+				}
+			}
+			continue
+		}
+		e := entries[rec.Seq]
+		if e == nil {
+			continue
+		}
+		switch rec.Op {
+		case journalOpSent:
+			e.State = JournalSent
+		case journalOpAck:
+			e.State, e.Result, e.Error = JournalAcknowledged, rec.Result, rec.Error
+		case journalOpFailed:
+			e.State, e.Error = JournalFailed, rec.Error
+		}
+	}
+	return scanner.Err()
+}
+
+// decryptRecord decrypts an encrypted record. Truncated records are returned as invalid
+// JSON, so they are skipped like truncated plain records.
+func (jr journalReader) decryptRecord(line []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil {
+		return nil, nil
+	}
+	if jr.records == nil {
+		return nil, errors.New("journal is encrypted")
+	}
+	rec, err := decodeStored(jr.records, data)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil
+	}
+	return rec, err
+}
+
+// Compact rewrites the journal, dropping entries of acknowledged and failed calls.
+// Archives are not modified.
+func (j *Journal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.rewrite()
+}
+
+// rewrite replaces the journal file with a file containing only the unfinished calls.
+func (j *Journal) rewrite() error {
+	path := j.f.Name()
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	entries := make(map[uint64]*JournalEntry)
+	err = journalReader{records: j.records}.readRecords(f, entries)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	seqs := make([]uint64, 0, len(entries))
+	for seq := range entries {
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".journal-*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	var size int64
+	for _, seq := range seqs {
+		e := entries[seq]
+		if e.State == JournalAcknowledged || e.State == JournalFailed {
+			continue
+		}
+		recs := []journalRecord{{Op: journalOpBegin, Seq: e.Seq, Method: e.Method, Params: e.Params, Time: e.Time.UnixMilli()}}
+		if e.State == JournalSent {
+			recs = append(recs, journalRecord{Op: journalOpSent, Seq: e.Seq})
+		}
+		for _, rec := range recs {
+			line, err := j.encodeRecord(rec)
+			if err != nil {
+				tmp.Close()
+				os.Remove(tmp.Name())
+				return err
+			}
+			n, _ := w.Write(line)
+			size += int64(n)
+		}
+	}
+	if err = w.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	nf, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	j.f.Close()
+	j.f, j.size = nf, size
+	return nil
+}
+
+// rotate moves the journal file to an archive and starts a new file containing the
+// unfinished calls. Archives exceeding the age and count limits are deleted.
+func (j *Journal) rotate() error {
+	path := j.f.Name()
+	archive := fmt.Sprintf("%s.%020d", path, time.Now().UnixNano())
+	if j.config.Codec != nil {
+		archive += "." + j.config.Codec.Name()
+	}
+	if err := writeJournalArchive(path, archive, j.config.Codec); err != nil {
+		return err
+	}
+	if err := j.rewrite(); err != nil {
+		return err
+	}
+	return j.expireArchives()
+}
+
+// writeJournalArchive copies the journal file to archive, compressing it with codec.
+// The archive is created atomically.
+func writeJournalArchive(path, archive string, codec StorageCodec) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".journal-archive-*")
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		if codec == nil {
+			_, err := io.Copy(tmp, src)
+			return err
+		}
+		w, err := codec.NewWriter(tmp)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+		return w.Close()
+	}()
+	if err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err == nil {
+		err = os.Rename(tmp.Name(), archive)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// expireArchives deletes archives exceeding the age and count limits.
+func (j *Journal) expireArchives() error {
+	if j.config.MaxAge == 0 && j.config.MaxArchives == 0 {
+		return nil
+	}
+	archives, err := journalArchives(j.f.Name())
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-j.config.MaxAge)
+	for i, a := range archives {
+		tooMany := j.config.MaxArchives > 0 && len(archives)-i > j.config.MaxArchives
+		tooOld := j.config.MaxAge > 0 && a.created.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(a.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// journalArchive is an archived journal file.
+type journalArchive struct {
+	path    string
+	created time.Time
+	codec   string // name of the codec, empty if uncompressed
+}
+
+// journalArchives returns the archives of the journal at path, oldest first. Archives are
+// named <path>.<creation time in ns>[.<codec>].
+func journalArchives(path string) ([]journalArchive, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var archives []journalArchive
+	for _, f := range files {
+		suffix, ok := strings.CutPrefix(f.Name(), base+".")
+		if !ok || f.IsDir() {
+			continue
+		}
+		stamp, codec, _ := strings.Cut(suffix, ".")
+		ns, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil || len(stamp) != 20 {
+			continue
+		}
+		archives = append(archives, journalArchive{
+			path:    filepath.Join(dir, f.Name()),
+			created: time.Unix(0, ns),
+			codec:   codec,
+		})
+	}
+	// Names sort by creation time, since the time is zero-padded.
+	slices.SortFunc(archives, func(a, b journalArchive) int { return strings.Compare(a.path, b.path) })
+	return archives, nil
+}
+
+// syncDir syncs a directory, making renames within it durable. Errors are ignored since
+// directories can't be synced on all platforms.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// tracks reports whether calls of method are journaled.
+func (j *Journal) tracks(method string) bool {
+	return j != nil && j.methods[method]
+}
+
+// begin records a call before it is sent.
+func (j *Journal) begin(method string, params json.RawMessage) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	seq := j.nextSeq
+	j.nextSeq++
+	rec := journalRecord{Op: journalOpBegin, Seq: seq, Method: method, Params: params, Time: time.Now().UnixMilli()}
+	if err := j.append(rec, true); err != nil {
+		return 0, fmt.Errorf("journal write failed: %w", err)
+	}
+	return seq, nil
+}
+
+// sent records that a call was written to the connection.
+func (j *Journal) sent(seq uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.append(journalRecord{Op: journalOpSent, Seq: seq}, true)
+}
+
+// acknowledged records the response of a call.
+func (j *Journal) acknowledged(seq uint64, resp *jsonrpcMessage) {
+	rec := journalRecord{Op: journalOpAck, Seq: seq, Result: resp.Result}
+	if resp.Error != nil {
+		rec.Error = resp.Error.Error()
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.append(rec, false)
+}
+
+// failed records that sending a call failed.
+func (j *Journal) failed(seq uint64, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.append(journalRecord{Op: journalOpFailed, Seq: seq, Error: err.Error()}, false)
+}
+
+// encodeRecord encodes a record as a line of the journal file.
+func (j *Journal) encodeRecord(rec journalRecord) ([]byte, error) {
+	enc, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	if j.records != nil {
+		data, err := encodeStored(j.records, enc)
+		if err != nil {
+			return nil, err
+		}
+		enc = base64.StdEncoding.AppendEncode(nil, data)
+	}
+	return append(enc, '\n'), nil
+}
+
+// append writes a record. Critical records are synced unless syncing is disabled.
+func (j *Journal) append(rec journalRecord, critical bool) error {
+	line, err := j.encodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	n, err := j.f.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return err
+	}
+	switch j.config.Sync {
+	case JournalSyncAll:
+		err = j.f.Sync()
+	case JournalSyncCritical:
+		if critical {
+			err = j.f.Sync()
+		}
+	}
+	if err == nil && j.config.MaxSize > 0 && j.size >= j.config.MaxSize {
+		if err := j.rotate(); err != nil {
+			log.Warn("Failed to rotate RPC journal", "path", j.f.Name(), "err", err)
+		}
+	}
+	return err
+}
+
+// WithJournal makes the client record calls of the journal's methods made through
+// CallContext. See Journal for details.
+func WithJournal(j *Journal) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.journal = j
+	})
+}
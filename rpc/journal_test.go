@@ -0,0 +1,276 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "journal")
+	journal, err := OpenJournal(path, "test_echo", "test_returnError", "test_block")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := dialInProcWithOptions(server, WithJournal(journal))
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "test_block"); err == nil {
+		t.Fatal("expected timeout")
+	}
+	// Untracked method.
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	want := []struct {
+		method string
+		state  JournalState
+	}{
+		{"test_echo", JournalAcknowledged},
+		{"test_returnError", JournalAcknowledged},
+		{"test_block", JournalSent},
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Seq != uint64(i+1) || e.Method != w.method || e.State != w.state {
+			t.Errorf("entry %d: got seq %d %s %v, want %s %v", i, e.Seq, e.Method, e.State, w.method, w.state)
+		}
+	}
+	if string(entries[0].Params) != `["x",1]` || len(entries[0].Result) == 0 {
+		t.Errorf("wrong echo entry %+v", entries[0])
+	}
+	if entries[1].Error != "testError" {
+		t.Errorf("wrong error %q", entries[1].Error)
+	}
+
+	// Compacting drops finished calls.
+	if err := journal.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = ReadJournal(path)
+	if len(entries) != 1 || entries[0].Method != "test_block" || entries[0].State != JournalSent {
+		t.Fatalf("wrong entries after compaction: %+v", entries)
+	}
+	if err := client.Call(&result, "test_echo", "y", 2); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = ReadJournal(path)
+	if len(entries) != 2 || entries[1].Seq != 4 {
+		t.Fatalf("wrong entries after compaction and call: %+v", entries)
+	}
+}
+
+func TestJournalReopen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "journal")
+	journal, err := OpenJournal(path, "test_echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := journal.begin("test_echo", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	journal.Close()
+
+	// Simulate a crash in the middle of writing a record.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"op":"begin","seq":2,"meth`)
+	f.Close()
+
+	journal, err = OpenJournal(path, "test_echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	seq, err := journal.begin("test_echo", []byte(`[]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 2 {
+		t.Fatalf("got seq %d after reopen, want 2", seq)
+	}
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].State != JournalPending {
+		t.Fatalf("wrong entries %+v", entries)
+	}
+}
+
+func TestJournalRotation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "journal")
+	config := JournalConfig{MaxSize: 256, Codec: GzipCodec, MaxArchives: 2, Sync: JournalSyncNone}
+	journal, err := OpenJournalWithConfig(path, config, "test_echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	// The first call stays unfinished and must survive all rotations.
+	if _, err := journal.begin("test_echo", []byte(`["pending"]`)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		seq, err := journal.begin("test_echo", []byte(`["x"]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		journal.failed(seq, errors.New("failed"))
+	}
+
+	archives, err := journalArchives(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 2 {
+		t.Fatalf("got %d archives, want 2", len(archives))
+	}
+	for _, a := range archives {
+		if a.codec != "gz" {
+			t.Errorf("archive %s not compressed", a.path)
+		}
+	}
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Seq != 1 || entries[0].State != JournalPending || string(entries[0].Params) != `["pending"]` {
+		t.Fatalf("unfinished call lost: %+v", entries[0])
+	}
+	last := entries[len(entries)-1]
+	if last.Seq != 21 || last.State != JournalFailed {
+		t.Fatalf("wrong last entry %+v", last)
+	}
+
+	// Reopening continues the sequence.
+	journal.Close()
+	journal, err = OpenJournalWithConfig(path, config, "test_echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq, _ := journal.begin("test_echo", []byte(`[]`)); seq != 22 {
+		t.Fatalf("got seq %d after reopen, want 22", seq)
+	}
+}
+
+func TestJournalArchiveExpiry(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "journal")
+	old := fmt.Sprintf("%s.%020d", path, time.Now().Add(-48*time.Hour).UnixNano())
+	if err := os.WriteFile(old, []byte(`{"op":"begin","seq":1,"method":"test_echo"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := JournalConfig{MaxSize: 64, MaxAge: 24 * time.Hour}
+	journal, err := OpenJournalWithConfig(path, config, "test_echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	if seq, _ := journal.begin("test_echo", []byte(`["a long enough parameter"]`)); seq != 2 {
+		t.Fatalf("got seq %d, want 2", seq)
+	}
+
+	if _, err := os.Stat(old); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expired archive not deleted: %v", err)
+	}
+	archives, _ := journalArchives(path)
+	if len(archives) != 1 || archives[0].codec != "" {
+		t.Fatalf("wrong archives %+v", archives)
+	}
+}
+
+func TestJournalEncryption(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "journal")
+	config := JournalConfig{MaxSize: 1024, Encryption: StaticStorageKey(make([]byte, 32))}
+	journal, err := OpenJournalWithConfig(path, config, "test_echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	for i := 0; i < 10; i++ {
+		seq, err := journal.begin("test_echo", []byte(`["secret"]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 {
+			journal.failed(seq, errors.New("failed"))
+		}
+	}
+
+	archives, _ := journalArchives(path)
+	if len(archives) == 0 {
+		t.Fatal("journal not rotated")
+	}
+	for _, file := range []string{path, archives[0].path} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(content, []byte("secret")) || bytes.Contains(content, []byte("test_echo")) {
+			t.Fatalf("%s contains plaintext", file)
+		}
+	}
+	if _, err := ReadJournal(path); err == nil {
+		t.Fatal("read encrypted journal without keys")
+	}
+	entries, err := ReadJournalWithConfig(path, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 10 || string(entries[0].Params) != `["secret"]` || entries[0].State != JournalPending {
+		t.Fatalf("wrong entries %+v", entries)
+	}
+}
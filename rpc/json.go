@@ -44,11 +44,13 @@ var null = json.RawMessage("null")
 type subscriptionResult struct {
 	ID     string          `json:"subscription"`
 	Result json.RawMessage `json:"result,omitempty"`
+	Seq    uint64          `json:"seq,omitempty"`
 }
 
 type subscriptionResultEnc struct {
 	ID     string `json:"subscription"`
 	Result any    `json:"result"`
+	Seq    uint64 `json:"seq,omitempty"`
 }
 
 type jsonrpcSubscriptionNotification struct {
@@ -66,6 +68,67 @@ type jsonrpcMessage struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 	Error   *jsonError      `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
+
+	// Checksum of the result, see Server.SetResponseChecksums.
+	Checksum string `json:"checksum,omitempty"`
+
+	// Extension metadata, see Server.SetExecutionReports, Server.SetConsistencyTokens
+	// and Server.SetTruncationPolicies.
+	Ext json.RawMessage `json:"ext,omitempty"`
+
+	report   *ExecutionReport // timing of the call, set by the handler
+	cacheTTL time.Duration    // cache TTL of the result, set by the handler
+	rejected error            // set if the call was rejected by the method filter
+	stream   *StreamedResult  // result written by the HTTP codec, see StreamedResult
+	replay   replaySafety     // whether the call may be replayed, see Server.SetReplayProtection
+
+	computeUnits *ComputeUnitUsage // units charged for the call, see Server.SetComputeUnits
+}
+
+// requestExt is the "ext" member of a request.
+type requestExt struct {
+	Timing      bool   `json:"timing,omitempty"`
+	Consistency uint64 `json:"consistency,omitempty"`
+	Truncate    bool   `json:"truncate,omitempty"`
+	Cursor      string `json:"cursor,omitempty"`
+	Baggage     string `json:"baggage,omitempty"`
+	DryRun      bool   `json:"dryRun,omitempty"`
+	TimeoutMs   int64  `json:"timeoutMs,omitempty"`
+}
+
+// responseExt is the "ext" member of a response.
+type responseExt struct {
+	Timing      *executionReportJSON `json:"timing,omitempty"`
+	Consistency uint64               `json:"consistency,omitempty"`
+	CacheTTLMs  int64                `json:"cacheTtlMs,omitempty"`
+	Truncated   bool                 `json:"truncated,omitempty"`
+	Cursor      string               `json:"cursor,omitempty"`
+
+	ComputeUnits *ComputeUnitUsage  `json:"cu,omitempty"`
+	Deprecation  *DeprecationNotice `json:"deprecation,omitempty"`
+}
+
+// requestExt decodes the "ext" member of a request. Invalid members are ignored.
+func (msg *jsonrpcMessage) requestExt() (ext requestExt) {
+	if len(msg.Ext) > 0 {
+		json.Unmarshal(msg.Ext, &ext)
+	}
+	return ext
+}
+
+// responseExt decodes the "ext" member of a response. Invalid members are ignored.
+func (msg *jsonrpcMessage) responseExt() (ext responseExt) {
+	if len(msg.Ext) > 0 {
+		json.Unmarshal(msg.Ext, &ext)
+	}
+	return ext
+}
+
+// updateResponseExt modifies the "ext" member of a response.
+func (msg *jsonrpcMessage) updateResponseExt(fn func(*responseExt)) {
+	ext := msg.responseExt()
+	fn(&ext)
+	msg.Ext, _ = json.Marshal(ext)
 }
 
 func (msg *jsonrpcMessage) isNotification() bool {
@@ -101,6 +164,12 @@ func (msg *jsonrpcMessage) namespace() string {
 	return before
 }
 
+// encodedSizeHint returns the approximate size of the JSON encoding of a request message.
+func (msg *jsonrpcMessage) encodedSizeHint() int {
+	const overhead = len(`{"jsonrpc":"2.0","id":,"method":"","params":},`)
+	return overhead + len(msg.ID) + len(msg.Method) + len(msg.Params)
+}
+
 func (msg *jsonrpcMessage) String() string {
 	b, _ := json.Marshal(msg)
 	return string(b)
@@ -113,6 +182,9 @@ func (msg *jsonrpcMessage) errorResponse(err error) *jsonrpcMessage {
 }
 
 func (msg *jsonrpcMessage) response(result interface{}) *jsonrpcMessage {
+	if fwd, ok := result.(forwardedJSON); ok {
+		return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: json.RawMessage(fwd)}
+	}
 	enc, err := json.Marshal(result)
 	if err != nil {
 		return msg.errorResponse(&internalServerError{errcodeMarshalError, err.Error()})
@@ -179,12 +251,16 @@ type ConnRemoteAddr interface {
 // support for parsing arguments and serializing (result) objects.
 type jsonCodec struct {
 	remote  string
+	cred    *PeerCred
 	closer  sync.Once        // close closed channel once
 	closeCh chan interface{} // closed on Close
 	decode  decodeFunc       // decoder to allow multiple transports
 	encMu   sync.Mutex       // guards the encoder
 	encode  encodeFunc       // encoder to allow multiple transports
 	conn    deadlineCloser
+
+	filter   *methodFilterHook // method filter of the server, nil on the client side
+	metadata map[string]any    // see SetAdmissionHook
 }
 
 type encodeFunc = func(v interface{}, isErrorResponse bool) error
@@ -204,6 +280,7 @@ func NewFuncCodec(conn deadlineCloser, encode encodeFunc, decode decodeFunc) Ser
 	if ra, ok := conn.(ConnRemoteAddr); ok {
 		codec.remote = ra.RemoteAddr()
 	}
+	codec.cred = peerCredentials(conn)
 	return codec
 }
 
@@ -222,7 +299,7 @@ func NewCodec(conn Conn) ServerCodec {
 
 func (c *jsonCodec) peerInfo() PeerInfo {
 	// This returns "ipc" because all other built-in transports have a separate codec type.
-	return PeerInfo{Transport: "ipc", RemoteAddr: c.remote}
+	return PeerInfo{Transport: "ipc", RemoteAddr: c.remote, Cred: c.cred, Metadata: c.metadata}
 }
 
 func (c *jsonCodec) remoteAddr() string {
@@ -236,7 +313,11 @@ func (c *jsonCodec) readBatch() (messages []*jsonrpcMessage, batch bool, err err
 	if err := c.decode(&rawmsg); err != nil {
 		return nil, false, err
 	}
-	messages, batch = parseMessage(rawmsg)
+	if f := c.methodFilter(); f != nil {
+		messages, batch = parseFiltered(rawmsg, f)
+	} else {
+		messages, batch = parseMessage(rawmsg)
+	}
 	for i, msg := range messages {
 		if msg == nil {
 			// Message is JSON 'null'. Replace with zero value so it
@@ -247,6 +328,20 @@ func (c *jsonCodec) readBatch() (messages []*jsonrpcMessage, batch bool, err err
 	return messages, batch, nil
 }
 
+// methodFilter returns the function checking methods of incoming messages, or nil if
+// no filter is configured.
+func (c *jsonCodec) methodFilter() func(string) error {
+	if c.filter == nil {
+		return nil
+	}
+	filter := c.filter.reg.snapshot().methodFilter
+	if filter == nil {
+		return nil
+	}
+	peer := c.filter.peer
+	return func(method string) error { return filter(peer, method) }
+}
+
 func (c *jsonCodec) writeJSON(ctx context.Context, v interface{}, isErrorResponse bool) error {
 	c.encMu.Lock()
 	defer c.encMu.Unlock()
@@ -303,11 +398,20 @@ func isBatch(raw json.RawMessage) bool {
 	return false
 }
 
+// decodeConfig configures the decoding of arguments.
+type decodeConfig struct {
+	numbers NumberPolicy
+	times   TimeFormat
+}
+
 // parsePositionalArguments tries to parse the given args to an array of values with the
 // given types. It returns the parsed values or an error when the args could not be
 // parsed. Missing optional arguments are returned as reflect.Zero values.
-func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]reflect.Value, error) {
+func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type, cfg decodeConfig) ([]reflect.Value, error) {
 	dec := json.NewDecoder(bytes.NewReader(rawArgs))
+	if cfg.numbers != NumberFloat64 {
+		dec.UseNumber()
+	}
 	var args []reflect.Value
 	tok, err := dec.Token()
 	switch {
@@ -318,9 +422,12 @@ func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]
 		return nil, err
 	case tok == json.Delim('['):
 		// Read argument array.
-		if args, err = parseArgumentArray(dec, types); err != nil {
+		if args, err = parseArgumentArray(dec, types, cfg); err != nil {
 			return nil, err
 		}
+		for _, arg := range args {
+			cfg.numbers.convert(arg)
+		}
 	default:
 		return nil, errors.New("non-array args")
 	}
@@ -334,14 +441,22 @@ func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]
 	return args, nil
 }
 
-func parseArgumentArray(dec *json.Decoder, types []reflect.Type) ([]reflect.Value, error) {
+func parseArgumentArray(dec *json.Decoder, types []reflect.Type, cfg decodeConfig) ([]reflect.Value, error) {
 	args := make([]reflect.Value, 0, len(types))
 	for i := 0; dec.More(); i++ {
 		if i >= len(types) {
 			return args, fmt.Errorf("too many arguments, want at most %d", len(types))
 		}
 		argval := reflect.New(types[i])
-		if err := dec.Decode(argval.Interface()); err != nil {
+		if cfg.times != TimeFormatDefault && isTimeType(types[i]) {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return args, fmt.Errorf("invalid argument %d: %v", i, err)
+			}
+			if err := decodeTimeParam(raw, argval); err != nil {
+				return args, fmt.Errorf("invalid argument %d: %v", i, err)
+			}
+		} else if err := dec.Decode(argval.Interface()); err != nil {
 			return args, fmt.Errorf("invalid argument %d: %v", i, err)
 		}
 		if argval.IsNil() && types[i].Kind() != reflect.Ptr {
@@ -0,0 +1,201 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var errMissingToken = errors.New("missing token")
+
+// JWTClaims are the claims of a validated JSON web token.
+type JWTClaims map[string]interface{}
+
+// JWTClaimsFromContext returns the claims of the token the client was authenticated
+// with, or nil if the client was not authenticated by a JWT.
+func JWTClaimsFromContext(ctx context.Context) JWTClaims {
+	if p := PeerInfoFromContext(ctx).Principal; p != nil {
+		return p.Claims
+	}
+	return nil
+}
+
+// TokenValidator validates HS256-signed JSON web tokens, like the authentication of
+// the engine API. See SetTokenValidator.
+type TokenValidator struct {
+	// Secrets are the accepted signing keys. A token is valid if it is signed by any of
+	// them, which allows rotating keys without interruption: add the new secret, move
+	// clients over, then remove the old one.
+	Secrets [][]byte
+
+	// Issuer and Audience, if set, must match the "iss" claim and be contained in the
+	// "aud" claim of tokens.
+	Issuer   string
+	Audience string
+
+	// IssuedAtWindow, if set, requires tokens to have an "iat" claim within this
+	// duration of the current time. The engine API uses 60 seconds.
+	IssuedAtWindow time.Duration
+
+	// RolesClaim is the claim holding the roles of the principal, as a list of strings
+	// or a space-separated string.
+	RolesClaim string
+}
+
+// SetTokenValidator requires HTTP, websocket and gRPC requests to carry a valid token
+// in the Authorization header ("Bearer <token>"). Requests without valid token are
+// rejected with status 401. For websocket connections, the token is checked once on the
+// handshake request.
+//
+// The principal of the request is taken from the "sub" claim and the roles claim of the
+// token, unless the authenticator of the ACL returns a principal. The claims are
+// available to methods and middlewares through JWTClaimsFromContext. Passing nil removes
+// the requirement.
+func (s *Server) SetTokenValidator(v *TokenValidator) {
+	if v != nil {
+		cpy := *v
+		cpy.Secrets = slices.Clone(v.Secrets)
+		v = &cpy
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.tokenValidator = v })
+}
+
+// Validate checks the token and returns its claims.
+func (v *TokenValidator) Validate(token string) (JWTClaims, error) {
+	return v.validate(token, time.Now())
+}
+
+func (v *TokenValidator) validate(token string, now time.Time) (JWTClaims, error) {
+	claims, err := verifyJWT(token, v.Secrets, now)
+	if err != nil {
+		return nil, err
+	}
+	if v.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.Issuer {
+			return nil, errors.New("invalid token issuer")
+		}
+	}
+	if v.Audience != "" {
+		var ok bool
+		switch aud := claims["aud"].(type) {
+		case string:
+			ok = aud == v.Audience
+		case []interface{}:
+			ok = slices.Contains(aud, interface{}(v.Audience))
+		}
+		if !ok {
+			return nil, errors.New("invalid token audience")
+		}
+	}
+	if v.IssuedAtWindow > 0 {
+		iat, ok := claims["iat"].(float64)
+		if !ok {
+			return nil, errors.New("missing token issuance time")
+		}
+		if d := now.Sub(time.Unix(int64(iat), 0)); d > v.IssuedAtWindow || d < -v.IssuedAtWindow {
+			return nil, errors.New("stale token")
+		}
+	}
+	return claims, nil
+}
+
+// principal validates the token and returns the principal it identifies.
+func (v *TokenValidator) principal(token string, now time.Time) (*Principal, error) {
+	claims, err := v.validate(token, now)
+	if err != nil {
+		return nil, err
+	}
+	p := &Principal{Claims: claims}
+	p.Name, _ = claims["sub"].(string)
+	switch roles := claims[v.RolesClaim].(type) {
+	case string:
+		p.Roles = strings.Fields(roles)
+	case []interface{}:
+		for _, role := range roles {
+			if s, ok := role.(string); ok {
+				p.Roles = append(p.Roles, s)
+			}
+		}
+	}
+	return p, nil
+}
+
+// bearerToken returns the token in the Authorization header of r.
+func bearerToken(r *http.Request) (string, bool) {
+	return strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// verifyJWT checks the signature and validity period of an HS256 token and returns
+// its claims. The token must be signed by one of secrets.
+func verifyJWT(token string, secrets [][]byte, now time.Time) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "HS256" {
+		return nil, errors.New("unsupported token algorithm " + header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	valid := slices.ContainsFunc(secrets, func(secret []byte) bool {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(parts[0] + "." + parts[1]))
+		return hmac.Equal(sig, mac.Sum(nil))
+	})
+	if !valid {
+		return nil, errors.New("invalid token signature")
+	}
+	var claims JWTClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return nil, errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return nil, errors.New("token not valid yet")
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
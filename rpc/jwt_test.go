@@ -0,0 +1,134 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenValidatorRotation(t *testing.T) {
+	t.Parallel()
+
+	oldSecret, newSecret := []byte("old"), []byte("new")
+	server := newTestServer()
+	defer server.Stop()
+	server.SetTokenValidator(&TokenValidator{Secrets: [][]byte{oldSecret, newSecret}})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	call := func(secret []byte) error {
+		token := makeTestJWT(secret, map[string]interface{}{"sub": "x"})
+		client := dialACLTest(t, httpsrv.URL, WithHeader("Authorization", "Bearer "+token))
+		return client.Call(nil, "test_noArgsRets")
+	}
+	if err := call(oldSecret); err != nil {
+		t.Fatal("old secret rejected:", err)
+	}
+	if err := call(newSecret); err != nil {
+		t.Fatal("new secret rejected:", err)
+	}
+
+	server.SetTokenValidator(&TokenValidator{Secrets: [][]byte{newSecret}})
+	var httpErr HTTPError
+	if err := call(oldSecret); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for retired secret, got %v", err)
+	}
+	if err := call(newSecret); err != nil {
+		t.Fatal("new secret rejected:", err)
+	}
+
+	// Requests without token are rejected.
+	client := dialACLTest(t, httpsrv.URL)
+	if err := client.Call(nil, "test_noArgsRets"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %v", err)
+	}
+}
+
+func TestTokenValidatorClaims(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+	v := &TokenValidator{
+		Secrets:        [][]byte{secret},
+		Issuer:         "node",
+		Audience:       "rpc",
+		IssuedAtWindow: time.Minute,
+	}
+	tests := []struct {
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{map[string]interface{}{"iss": "node", "aud": "rpc", "iat": now.Unix()}, true},
+		{map[string]interface{}{"iss": "node", "aud": []string{"other", "rpc"}, "iat": now.Unix()}, true},
+		{map[string]interface{}{"iss": "other", "aud": "rpc", "iat": now.Unix()}, false},
+		{map[string]interface{}{"iss": "node", "aud": "other", "iat": now.Unix()}, false},
+		{map[string]interface{}{"aud": "rpc", "iat": now.Unix()}, false},
+		{map[string]interface{}{"iss": "node", "aud": "rpc"}, false},
+		{map[string]interface{}{"iss": "node", "aud": "rpc", "iat": now.Add(-2 * time.Minute).Unix()}, false},
+		{map[string]interface{}{"iss": "node", "aud": "rpc", "iat": now.Add(2 * time.Minute).Unix()}, false},
+	}
+	for i, test := range tests {
+		_, err := v.validate(makeTestJWT(secret, test.claims), now)
+		if test.valid && err != nil {
+			t.Errorf("test %d: valid token rejected: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("test %d: invalid token accepted", i)
+		}
+	}
+}
+
+func TestTokenValidatorWebsocketContext(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	server := newTestServer()
+	defer server.Stop()
+	server.SetTokenValidator(&TokenValidator{Secrets: [][]byte{secret}, RolesClaim: "roles"})
+	seen := make(chan JWTClaims, 1)
+	server.SetMiddlewares([]Middleware{
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			seen <- JWTClaimsFromContext(ctx)
+			return next(ctx, method, args)
+		},
+	})
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	if _, err := DialOptions(context.Background(), wsURL); err == nil {
+		t.Fatal("dial without token succeeded")
+	}
+	token := makeTestJWT(secret, map[string]interface{}{"sub": "dave", "roles": []string{"ops"}, "tenant": "a"})
+	client := dialACLTest(t, wsURL, WithHeader("Authorization", "Bearer "+token))
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Principal == nil || info.Principal.Name != "dave" || !info.Principal.HasRole("ops") {
+		t.Fatalf("wrong principal %+v", info.Principal)
+	}
+	if claims := <-seen; claims["tenant"] != "a" {
+		t.Fatalf("wrong claims in middleware context: %v", claims)
+	}
+}
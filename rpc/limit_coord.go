@@ -0,0 +1,234 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const defaultLimitSyncInterval = 5 * time.Second
+
+// LimitCoordinator coordinates global limits between the servers of a fleet. It is
+// typically a client of a central service or datastore shared by the servers.
+type LimitCoordinator interface {
+	// Rebalance reports the number of calls per key counted by this server since the
+	// last report, and returns the number of calls per window this server may accept
+	// for each key. Keys missing in the result keep their current limit.
+	Rebalance(ctx context.Context, usage map[string]int64) (limits map[string]int64, err error)
+}
+
+// CoordinatedLimit configures a CoordinatedLimiter.
+type CoordinatedLimit struct {
+	GlobalLimit int64         // calls per window allowed across all servers
+	Window      time.Duration // length of the window
+	Instances   int           // expected number of servers, for the initial share
+
+	// SyncInterval is the time between calls to the coordinator. The default is
+	// five seconds.
+	SyncInterval time.Duration
+
+	// Key returns the key under which calls are counted, see Quota.Key.
+	Key func(peer PeerInfo, method string) string
+
+	Coordinator LimitCoordinator
+}
+
+// CoordinatedLimiter enforces per-key limits which hold approximately across a fleet of
+// servers. Each server enforces a local share of the global limit. Usage is reported to
+// the coordinator in the background, which answers with new shares, so that the global
+// limit is distributed according to where the traffic arrives. Calls are never delayed
+// by the coordinator. When it is unavailable, servers keep their current shares.
+type CoordinatedLimiter struct {
+	cfg     CoordinatedLimit
+	key     func(peer PeerInfo, method string) string
+	counts  *MemoryState
+	initial int64
+
+	mu     sync.Mutex
+	limits map[string]int64
+	usage  map[string]int64
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewCoordinatedLimiter creates a limiter and starts its coordination loop. Install it
+// using Server.SetMethodFilter(l.Filter()).
+func NewCoordinatedLimiter(cfg CoordinatedLimit) *CoordinatedLimiter {
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = defaultLimitSyncInterval
+	}
+	l := &CoordinatedLimiter{
+		cfg:     cfg,
+		key:     cfg.Key,
+		counts:  NewMemoryState(),
+		initial: cfg.GlobalLimit / int64(max(cfg.Instances, 1)),
+		limits:  make(map[string]int64),
+		usage:   make(map[string]int64),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if l.key == nil {
+		l.key = quotaKeyByIP
+	}
+	go l.loop()
+	return l
+}
+
+// Filter returns the method filter which applies the limits.
+func (l *CoordinatedLimiter) Filter() MethodFilter {
+	return func(peer PeerInfo, method string) error {
+		k := l.key(peer, method)
+		if k == "" {
+			return nil
+		}
+		l.mu.Lock()
+		l.usage[k]++
+		limit, ok := l.limits[k]
+		l.mu.Unlock()
+		if !ok {
+			limit = l.initial
+		}
+		if n, _ := l.counts.Add(k, 1, l.cfg.Window); n > limit {
+			return &quotaExceededError{limit: limit, window: l.cfg.Window}
+		}
+		return nil
+	}
+}
+
+// Close stops the coordination loop.
+func (l *CoordinatedLimiter) Close() {
+	select {
+	case <-l.quit:
+	default:
+		close(l.quit)
+	}
+	<-l.done
+}
+
+func (l *CoordinatedLimiter) loop() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.cfg.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.sync()
+		case <-l.quit:
+			return
+		}
+	}
+}
+
+// sync reports usage to the coordinator and applies the new limits.
+func (l *CoordinatedLimiter) sync() {
+	l.mu.Lock()
+	usage := l.usage
+	l.usage = make(map[string]int64, len(usage))
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.SyncInterval)
+	defer cancel()
+	limits, err := l.cfg.Coordinator.Rebalance(ctx, usage)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		log.Debug("Rate limit coordination failed", "err", err)
+		// Report the usage again next time.
+		for k, n := range usage {
+			l.usage[k] += n
+		}
+		return
+	}
+	for k, limit := range limits {
+		l.limits[k] = limit
+	}
+}
+
+// LimitBalancer is a reference implementation of the coordination logic. It divides
+// the global limit of each key between servers in proportion to their usage in the
+// last reporting period. Every server which has reported gets a share of at least one
+// call. A central service can use it to answer requests from the servers of a fleet,
+// and it can be used directly by servers running in the same process.
+type LimitBalancer struct {
+	global int64
+
+	mu        sync.Mutex
+	instances map[string]struct{}
+	usage     map[string]map[string]int64 // key -> instance -> calls in last period
+}
+
+// NewLimitBalancer creates a balancer for the given global limit.
+func NewLimitBalancer(globalLimit int64) *LimitBalancer {
+	return &LimitBalancer{
+		global:    globalLimit,
+		instances: make(map[string]struct{}),
+		usage:     make(map[string]map[string]int64),
+	}
+}
+
+// Coordinator returns the coordinator for the server with the given unique id.
+func (b *LimitBalancer) Coordinator(instance string) LimitCoordinator {
+	return &balancerCoordinator{b, instance}
+}
+
+// Rebalance records usage of a server and computes its new shares.
+func (b *LimitBalancer) Rebalance(instance string, usage map[string]int64) map[string]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.instances[instance] = struct{}{}
+	for _, byInstance := range b.usage {
+		delete(byInstance, instance)
+	}
+	for k, n := range usage {
+		if b.usage[k] == nil {
+			b.usage[k] = make(map[string]int64)
+		}
+		b.usage[k][instance] = n
+	}
+	limits := make(map[string]int64, len(b.usage))
+	for k, byInstance := range b.usage {
+		if len(byInstance) == 0 {
+			delete(b.usage, k)
+			continue
+		}
+		// Every server gets one extra unit of weight, so that idle servers receive a
+		// share as well.
+		total := int64(len(b.instances))
+		for _, n := range byInstance {
+			total += n
+		}
+		limits[k] = max(b.global*(byInstance[instance]+1)/total, 1)
+	}
+	return limits
+}
+
+type balancerCoordinator struct {
+	b        *LimitBalancer
+	instance string
+}
+
+func (c *balancerCoordinator) Rebalance(ctx context.Context, usage map[string]int64) (map[string]int64, error) {
+	return c.b.Rebalance(c.instance, usage), nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoordinatedLimiter(t *testing.T) {
+	balancer := NewLimitBalancer(10)
+	newLimiter := func(instance string) *CoordinatedLimiter {
+		l := NewCoordinatedLimiter(CoordinatedLimit{
+			GlobalLimit:  10,
+			Window:       time.Hour,
+			Instances:    2,
+			SyncInterval: time.Hour, // synced manually below
+			Coordinator:  balancer.Coordinator(instance),
+		})
+		t.Cleanup(l.Close)
+		return l
+	}
+	a, b := newLimiter("a"), newLimiter("b")
+	peer := PeerInfo{Transport: "http", RemoteAddr: "192.0.2.1:1234"}
+
+	// Before coordination, each server allows its initial share.
+	allowed := func(l *CoordinatedLimiter, calls int) (n int) {
+		filter := l.Filter()
+		for range calls {
+			if filter(peer, "eth_call") == nil {
+				n++
+			}
+		}
+		return n
+	}
+	if n := allowed(a, 6); n != 5 {
+		t.Fatalf("server a allowed %d calls, want 5", n)
+	}
+
+	// All traffic arrives at a, so it receives most of the global limit.
+	b.sync()
+	a.sync()
+	if n := allowed(a, 10); n != 2 {
+		t.Fatalf("server a allowed %d calls after rebalancing, want 2", n)
+	}
+	b.sync()
+	if n := allowed(b, 10); n != 1 {
+		t.Fatalf("server b allowed %d calls after rebalancing, want 1", n)
+	}
+}
+
+type failingCoordinator struct {
+	usage []map[string]int64
+}
+
+func (c *failingCoordinator) Rebalance(ctx context.Context, usage map[string]int64) (map[string]int64, error) {
+	c.usage = append(c.usage, usage)
+	if len(c.usage) == 1 {
+		return nil, errors.New("unavailable")
+	}
+	return map[string]int64{"k": 100}, nil
+}
+
+func TestCoordinatedLimiterFailure(t *testing.T) {
+	coord := new(failingCoordinator)
+	l := NewCoordinatedLimiter(CoordinatedLimit{
+		GlobalLimit:  1,
+		Window:       time.Hour,
+		SyncInterval: time.Hour,
+		Key:          func(PeerInfo, string) string { return "k" },
+		Coordinator:  coord,
+	})
+	defer l.Close()
+	filter := l.Filter()
+
+	filter(PeerInfo{}, "m")
+	if filter(PeerInfo{}, "m") == nil {
+		t.Fatal("call over initial limit allowed")
+	}
+	// Usage is reported again after a failed coordination.
+	l.sync()
+	filter(PeerInfo{}, "m")
+	l.sync()
+	if got := coord.usage[1]["k"]; got != 3 {
+		t.Fatalf("wrong usage reported after failure: %d", got)
+	}
+	if err := filter(PeerInfo{}, "m"); err != nil {
+		t.Fatalf("call rejected after limit was raised: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// MemoryCeiling limits the approximate amount of memory attributable to a single call of
+// a method. The accounting is soft: it is based on the size of the call parameters, a
+// declared weight and the size of the encoded result, not on actual allocations.
+type MemoryCeiling struct {
+	// Limit is the number of bytes a call may account for.
+	Limit int64
+
+	// Weight is charged to every call of the method. It accounts for memory used while
+	// executing the method which is not visible in its parameters or result.
+	Weight int64
+}
+
+// SetMemoryCeilings configures memory ceilings by method name. The ceiling with key "*"
+// applies to all methods without their own entry. Calls are charged the size of their
+// parameters and the weight of the method before they run, further amounts declared by
+// the method handler using ChargeMemory, and the size of the encoded result. A call
+// exceeding its ceiling fails with an error describing the usage, and its result is
+// discarded instead of being sent. Passing nil removes all ceilings.
+func (s *Server) SetMemoryCeilings(ceilings map[string]MemoryCeiling) {
+	cpy := make(map[string]MemoryCeiling, len(ceilings))
+	for method, c := range ceilings {
+		cpy[method] = c
+	}
+	if len(cpy) == 0 {
+		cpy = nil
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.memoryCeilings = cpy })
+}
+
+// memoryCeiling returns the ceiling of method.
+func (cfg *registryConfig) memoryCeiling(method string) (MemoryCeiling, bool) {
+	if cfg.memoryCeilings == nil {
+		return MemoryCeiling{}, false
+	}
+	if c, ok := cfg.memoryCeilings[method]; ok {
+		return c, true
+	}
+	c, ok := cfg.memoryCeilings["*"]
+	return c, ok
+}
+
+type memoryAccountKey struct{}
+
+// memoryAccount tracks the memory charged to a call.
+type memoryAccount struct {
+	method string
+	limit  int64
+	used   atomic.Int64
+}
+
+// charge adds n bytes to the account. It fails if the ceiling is exceeded.
+func (a *memoryAccount) charge(n int64) error {
+	if used := a.used.Add(n); used > a.limit {
+		memoryCeilingExceededCounter.Inc(1)
+		return &memoryCeilingError{method: a.method, limit: a.limit, used: used}
+	}
+	return nil
+}
+
+// ChargeMemory declares that the current call uses n additional bytes of memory, for
+// example for an intermediate data structure. It returns an error if the memory ceiling
+// of the method is exceeded, which the handler should return to abort the call. Outside
+// of calls to methods with a ceiling, ChargeMemory does nothing.
+func ChargeMemory(ctx context.Context, n int64) error {
+	if a, ok := ctx.Value(memoryAccountKey{}).(*memoryAccount); ok {
+		return a.charge(n)
+	}
+	return nil
+}
+
+// chargeResult charges the encoded result of resp to the account in ctx. If the ceiling
+// is exceeded, the result is replaced by an error.
+func chargeResult(ctx context.Context, msg, resp *jsonrpcMessage) *jsonrpcMessage {
+	a, ok := ctx.Value(memoryAccountKey{}).(*memoryAccount)
+	if !ok || resp.Error != nil {
+		return resp
+	}
+	if err := a.charge(int64(len(resp.Result))); err != nil {
+		return msg.errorResponse(err)
+	}
+	return resp
+}
+
+// memoryCeilingError is returned for calls exceeding their memory ceiling.
+type memoryCeilingError struct {
+	method      string
+	limit, used int64
+}
+
+func (e *memoryCeilingError) ErrorCode() int { return errcodeMemoryCeiling }
+
+func (e *memoryCeilingError) Error() string { return errMsgMemoryCeiling }
+
+func (e *memoryCeilingError) ErrorData() interface{} {
+	return map[string]interface{}{"method": e.method, "limit": e.limit, "used": e.used}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type memoryTestService struct{}
+
+func (memoryTestService) Echo(s string) string { return s }
+
+func (memoryTestService) Build(ctx context.Context, n int64) (string, error) {
+	if err := ChargeMemory(ctx, n); err != nil {
+		return "", err
+	}
+	return "ok", nil
+}
+
+func TestMemoryCeilings(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("mem", memoryTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetMemoryCeilings(map[string]MemoryCeiling{
+		"mem_echo": {Limit: 1000, Weight: 100},
+		"*":        {Limit: 500},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	checkCeilingError := func(err error, wantMethod string) {
+		t.Helper()
+		var dataErr DataError
+		if !errors.As(err, &dataErr) || err.(Error).ErrorCode() != errcodeMemoryCeiling {
+			t.Fatalf("expected memory ceiling error, got %v", err)
+		}
+		data := dataErr.ErrorData().(map[string]interface{})
+		if data["method"] != wantMethod {
+			t.Fatalf("wrong error data %v", data)
+		}
+	}
+
+	var res string
+	if err := client.Call(&res, "mem_echo", strings.Repeat("a", 300)); err != nil {
+		t.Fatal(err)
+	}
+	// Parameters and result each count, so this exceeds the limit when the result is
+	// encoded.
+	err := client.Call(&res, "mem_echo", strings.Repeat("a", 500))
+	checkCeilingError(err, "mem_echo")
+	// Parameters alone exceed the limit, the call is not executed.
+	err = client.Call(&res, "mem_echo", strings.Repeat("a", 1000))
+	checkCeilingError(err, "mem_echo")
+
+	// The default ceiling applies to other methods, including amounts charged by the
+	// handler.
+	if err := client.Call(&res, "mem_build", 100); err != nil {
+		t.Fatal(err)
+	}
+	err = client.Call(&res, "mem_build", 1000)
+	checkCeilingError(err, "mem_build")
+
+	// Without ceilings, everything is allowed.
+	server.SetMemoryCeilings(nil)
+	if err := client.Call(&res, "mem_build", 1000); err != nil {
+		t.Fatal(err)
+	}
+}
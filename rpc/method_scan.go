@@ -0,0 +1,329 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MethodFilter decides whether a call of method by peer is processed. It is invoked for
+// every incoming call and notification before the message is fully decoded, which makes
+// rejecting calls cheap. Filters can implement method blocklists or rate limiting. A
+// non-nil error rejects the call and is sent to the client as the error response, so it
+// should implement Error to set an appropriate code, see also RetryableError.
+type MethodFilter func(peer PeerInfo, method string) error
+
+// SetMethodFilter installs a filter which is consulted for every incoming call before the
+// call is decoded. Passing nil removes the filter.
+func (s *Server) SetMethodFilter(filter MethodFilter) {
+	s.services.updateConfig(func(c *registryConfig) { c.methodFilter = filter })
+}
+
+// methodFilterHook connects a codec to the method filter of a server.
+type methodFilterHook struct {
+	reg  *serviceRegistry
+	peer PeerInfo
+}
+
+// attachMethodFilter makes codec apply the method filter of the server while reading.
+func (s *Server) attachMethodFilter(codec ServerCodec, peer PeerInfo) {
+	if fc, ok := codec.(interface{ setMethodFilter(*methodFilterHook) }); ok {
+		fc.setMethodFilter(&methodFilterHook{reg: &s.services, peer: peer})
+	}
+}
+
+func (c *jsonCodec) setMethodFilter(hook *methodFilterHook) {
+	c.filter = hook
+}
+
+// parseFiltered is like parseMessage, but applies filter to every message. Messages
+// whose method can be found by scanning are filtered before being decoded. Rejected
+// messages are returned with only their ID and method set.
+func parseFiltered(raw json.RawMessage, filter func(string) error) ([]*jsonrpcMessage, bool) {
+	if !isBatch(raw) {
+		return []*jsonrpcMessage{parseFilteredMessage(raw, filter)}, false
+	}
+	elems, ok := scanArray(raw)
+	if !ok {
+		msgs, batch := parseMessage(raw)
+		for _, msg := range msgs {
+			if msg != nil && msg.Method != "" {
+				msg.rejected = filter(msg.Method)
+			}
+		}
+		return msgs, batch
+	}
+	msgs := make([]*jsonrpcMessage, len(elems))
+	for i, elem := range elems {
+		msgs[i] = parseFilteredMessage(elem, filter)
+	}
+	return msgs, true
+}
+
+func parseFilteredMessage(raw []byte, filter func(string) error) *jsonrpcMessage {
+	method, id, ok := scanRequest(raw)
+	if ok && method != "" {
+		if err := filter(method); err != nil {
+			return &jsonrpcMessage{Version: vsn, ID: id, Method: method, rejected: err}
+		}
+		msg := new(jsonrpcMessage)
+		json.Unmarshal(raw, msg)
+		return msg
+	}
+	// The method could not be determined by scanning, fall back to a full decode.
+	var msg *jsonrpcMessage
+	json.Unmarshal(raw, &msg)
+	if msg != nil && msg.Method != "" {
+		msg.rejected = filter(msg.Method)
+	}
+	return msg
+}
+
+// ScanMethod returns the "method" member of a JSON-RPC request without decoding the
+// entire message. It is meant for routing and filtering decisions on raw messages. The
+// result matches the method found by decoding the message with encoding/json. ok is
+// false if raw is not a JSON object or does not have a string "method" member.
+func ScanMethod(raw []byte) (method string, ok bool) {
+	method, _, ok = scanRequest(raw)
+	if !ok || method == "" {
+		return "", false
+	}
+	return method, true
+}
+
+// scanRequest finds the "method" and "id" members of a JSON object. Member names are
+// matched like encoding/json does, i.e. case-insensitively with the last occurrence
+// taking precedence.
+func scanRequest(raw []byte) (method string, id json.RawMessage, ok bool) {
+	s := jsonScanner{data: raw}
+	if !s.consume('{') {
+		return "", nil, false
+	}
+	if s.consume('}') {
+		return "", nil, s.atEnd()
+	}
+	for {
+		key, ok := s.scanString()
+		if !ok || !s.consume(':') {
+			return "", nil, false
+		}
+		s.skipSpace()
+		start := s.pos
+		if !s.skipValue() {
+			return "", nil, false
+		}
+		value := raw[start:s.pos]
+		switch string(foldName(key)) {
+		case "METHOD":
+			if method, ok = decodeString(value); !ok {
+				return "", nil, false
+			}
+		case "ID":
+			id = value
+		}
+		if s.consume('}') {
+			return method, id, s.atEnd()
+		}
+		if !s.consume(',') {
+			return "", nil, false
+		}
+	}
+}
+
+// scanArray returns the elements of a JSON array.
+func scanArray(raw []byte) ([][]byte, bool) {
+	s := jsonScanner{data: raw}
+	if !s.consume('[') {
+		return nil, false
+	}
+	var elems [][]byte
+	if s.consume(']') {
+		return elems, s.atEnd()
+	}
+	for {
+		s.skipSpace()
+		start := s.pos
+		if !s.skipValue() {
+			return nil, false
+		}
+		elems = append(elems, raw[start:s.pos])
+		if s.consume(']') {
+			return elems, s.atEnd()
+		}
+		if !s.consume(',') {
+			return nil, false
+		}
+	}
+}
+
+// decodeString decodes a JSON string value. Strings without escapes and non-ASCII
+// characters are converted directly.
+func decodeString(value []byte) (string, bool) {
+	if len(value) < 2 || value[0] != '"' {
+		return "", false
+	}
+	inner := value[1 : len(value)-1]
+	for _, c := range inner {
+		if c == '\\' || c >= utf8.RuneSelf {
+			var s string
+			err := json.Unmarshal(value, &s)
+			return s, err == nil
+		}
+	}
+	return string(inner), true
+}
+
+// jsonScanner walks over JSON text without decoding it.
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips whitespace and the given delimiter, if present.
+func (s *jsonScanner) consume(c byte) bool {
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *jsonScanner) atEnd() bool {
+	s.skipSpace()
+	return s.pos == len(s.data)
+}
+
+// scanString reads a string and returns its raw contents, without quotes.
+func (s *jsonScanner) scanString() ([]byte, bool) {
+	s.skipSpace()
+	start := s.pos
+	if !s.skipString() {
+		return nil, false
+	}
+	return s.data[start+1 : s.pos-1], true
+}
+
+func (s *jsonScanner) skipString() bool {
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		return false
+	}
+	start := s.pos + 1
+	for i := start; ; {
+		n := bytes.IndexByte(s.data[i:], '"')
+		if n < 0 {
+			return false
+		}
+		end := i + n
+		// The quote is escaped if it is preceded by an odd number of backslashes.
+		k := end
+		for k > start && s.data[k-1] == '\\' {
+			k--
+		}
+		if (end-k)%2 == 0 {
+			s.pos = end + 1
+			return true
+		}
+		i = end + 1
+	}
+}
+
+// skipValue advances over the value at the current position.
+func (s *jsonScanner) skipValue() bool {
+	if s.pos >= len(s.data) {
+		return false
+	}
+	switch s.data[s.pos] {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if !s.skipString() {
+					return false
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+			if depth == 0 {
+				return true
+			}
+		}
+		return false
+	default:
+		start := s.pos
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				return s.pos > start
+			}
+			s.pos++
+		}
+		return s.pos > start
+	}
+}
+
+// foldName returns the folded form of a member name, as used by encoding/json to match
+// names case-insensitively.
+func foldName(in []byte) []byte {
+	out := make([]byte, 0, len(in))
+	for i := 0; i < len(in); {
+		if c := in[i]; c < utf8.RuneSelf {
+			if 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			out = append(out, c)
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRune(in[i:])
+		out = utf8.AppendRune(out, foldRune(r))
+		i += n
+	}
+	return out
+}
+
+// foldRune returns the smallest rune in the case folding set of r.
+func foldRune(r rune) rune {
+	for {
+		r2 := unicode.SimpleFold(r)
+		if r2 <= r {
+			return r2
+		}
+		r = r2
+	}
+}
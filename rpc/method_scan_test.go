@@ -0,0 +1,161 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestScanMethod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`, "eth_call", true},
+		{` { "params" : [{"method":"x","a":[1,{"b":"}"}]}], "method" : "eth_call" } `, "eth_call", true},
+		{`{"method":"eth_call"}`, "eth_call", true},
+		{`{"METHOD":"eth_call"}`, "eth_call", true},
+		{`{"method":"a","Method":"b"}`, "b", true},
+		{`{"method":"café"}`, "café", true},
+		{`{"params":["\"method\":\"x\""],"method":"y"}`, "y", true},
+		{`{"params":["\\"],"method":"z"}`, "z", true},
+		{`{"id":1}`, "", false},
+		{`{"method":1}`, "", false},
+		{`{"method":"a"} x`, "", false},
+		{`[{"method":"a"}]`, "", false},
+		{`{"method":"a"`, "", false},
+		{`{"method" "a"}`, "", false},
+		{``, "", false},
+	}
+	for _, test := range tests {
+		method, ok := ScanMethod([]byte(test.input))
+		if method != test.want || ok != test.ok {
+			t.Errorf("ScanMethod(%s) = %q, %t, want %q, %t", test.input, method, ok, test.want, test.ok)
+		}
+		// Successful scans must agree with a full decode.
+		if ok {
+			var msg jsonrpcMessage
+			if err := json.Unmarshal([]byte(test.input), &msg); err != nil || msg.Method != method {
+				t.Errorf("ScanMethod(%s) = %q, decoding gives %q (err %v)", test.input, method, msg.Method, err)
+			}
+		}
+	}
+}
+
+func TestScanRequestID(t *testing.T) {
+	t.Parallel()
+
+	_, id, ok := scanRequest([]byte(`{"id": {"a":[1,2]} ,"method":"m"}`))
+	if !ok || string(id) != `{"a":[1,2]}` {
+		t.Fatalf("wrong id %s", id)
+	}
+	elems, ok := scanArray([]byte(` [ {"method":"a"} , null,{"x":"]"}] `))
+	if !ok || len(elems) != 3 || string(elems[2]) != `{"x":"]"}` {
+		t.Fatalf("wrong elements %q", elems)
+	}
+}
+
+func TestMethodFilter(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	var (
+		mu    sync.Mutex
+		peers []string
+	)
+	server.SetMethodFilter(func(peer PeerInfo, method string) error {
+		mu.Lock()
+		peers = append(peers, peer.Transport)
+		mu.Unlock()
+		if method == "test_echo" {
+			return &methodNotFoundError{method: method}
+		}
+		return nil
+	})
+
+	client := DialInProc(server)
+	defer client.Close()
+	var rerr Error
+	if err := client.Call(nil, "test_echo", "x", 1, nil); !errors.As(err, &rerr) || rerr.ErrorCode() != -32601 {
+		t.Fatalf("expected filtered call to fail, got %v", err)
+	}
+	if err := client.Call(nil, "test_null"); err != nil {
+		t.Fatal(err)
+	}
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []interface{}{"x", 1, nil}},
+		{Method: "test_null", Result: new(json.RawMessage)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error == nil || batch[1].Error != nil {
+		t.Fatalf("wrong batch errors: %v, %v", batch[0].Error, batch[1].Error)
+	}
+
+	// Over HTTP, the filter applies as well and receives the HTTP peer info.
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	resp, err := http.Post(httpsrv.URL, contentType, strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"test_echo","params":["x",1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var msg jsonrpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.ID) != "7" || msg.Error == nil || msg.Error.Code != -32601 {
+		t.Fatalf("wrong response %+v", msg)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if last := peers[len(peers)-1]; last != "http" {
+		t.Fatalf("filter received transport %q, want http", last)
+	}
+
+	// Removing the filter allows the call again.
+	server.SetMethodFilter(nil)
+	if err := client.Call(nil, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var scanBenchMessage = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x` + strings.Repeat("ab", 4096) + `"]}`)
+
+func BenchmarkScanMethod(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ScanMethod(scanBenchMessage)
+	}
+}
+
+func BenchmarkDecodeMethod(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var msg jsonrpcMessage
+		json.Unmarshal(scanBenchMessage, &msg)
+	}
+}
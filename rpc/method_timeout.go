@@ -0,0 +1,134 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// SetMethodTimeout limits the execution time of calls of the given method. When the
+// timeout expires, the context of the call is canceled and the call fails with the
+// timeout error (-32002) right away, even if the method doesn't return. A duration of
+// zero removes the timeout of the method, which then uses the default timeout.
+func (s *Server) SetMethodTimeout(method string, d time.Duration) {
+	s.services.updateConfig(func(c *registryConfig) {
+		timeouts := maps.Clone(c.methodTimeouts)
+		if d > 0 {
+			if timeouts == nil {
+				timeouts = make(map[string]time.Duration)
+			}
+			timeouts[method] = d
+		} else {
+			delete(timeouts, method)
+		}
+		c.methodTimeouts = timeouts
+	})
+}
+
+// SetDefaultMethodTimeout limits the execution time of calls of methods without their
+// own timeout, see SetMethodTimeout. Zero means no limit.
+func (s *Server) SetDefaultMethodTimeout(d time.Duration) {
+	s.services.updateConfig(func(c *registryConfig) { c.defaultMethodTimeout = max(d, 0) })
+}
+
+// methodTimeout returns the execution timeout of method, or zero if there is none.
+func (c *registryConfig) methodTimeout(method string) time.Duration {
+	if d, ok := c.methodTimeouts[method]; ok {
+		return d
+	}
+	return c.defaultMethodTimeout
+}
+
+// callWithTimeout runs fn in a new goroutine and waits for its result until the
+// timeout expires. On timeout, the error response is sent right away, but the call
+// keeps its concurrency slot and stays tracked by callWG until fn returns.
+func (h *handler) callWithTimeout(ctx context.Context, method string, args []reflect.Value, timeout time.Duration, fn func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hold, _ := ctx.Value(callHoldKey{}).(*callHold)
+	hold.retain()
+	h.callWG.Add(1)
+	done := make(chan *MethodResult, 1)
+	go func() {
+		defer h.callWG.Done()
+		defer hold.release()
+		done <- fn(ctx, method, args)
+	}()
+
+	timer := h.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result
+	case <-timer.C():
+		h.log.Warn("RPC method exceeded its timeout", "method", method, "timeout", timeout)
+		return &MethodResult{Error: &internalServerError{errcodeTimeout, errMsgTimeout}}
+	}
+}
+
+type callHoldKey struct{}
+
+// callHold keeps the resources of a call, such as its concurrency slot, until both the
+// call and any method goroutine abandoned by callWithTimeout have finished.
+type callHold struct {
+	mu       sync.Mutex
+	refs     int
+	releases []func()
+}
+
+func newCallHold() *callHold {
+	return &callHold{refs: 1}
+}
+
+// onRelease adds a function which is called when the hold is released.
+func (ch *callHold) onRelease(fn func()) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.releases = append(ch.releases, fn)
+}
+
+func (ch *callHold) retain() {
+	if ch == nil {
+		return
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.refs++
+}
+
+// release drops a reference. The last release runs the release functions in reverse
+// order.
+func (ch *callHold) release() {
+	if ch == nil {
+		return
+	}
+	ch.mu.Lock()
+	ch.refs--
+	var releases []func()
+	if ch.refs == 0 {
+		releases, ch.releases = ch.releases, nil
+	}
+	ch.mu.Unlock()
+	for i := len(releases) - 1; i >= 0; i-- {
+		releases[i]()
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type timeoutTestService struct {
+	release  chan struct{}
+	canceled chan error
+}
+
+// Stuck ignores its context.
+func (s *timeoutTestService) Stuck() {
+	<-s.release
+}
+
+// Wait returns when its context is canceled.
+func (s *timeoutTestService) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	s.canceled <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestMethodTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := &timeoutTestService{release: make(chan struct{}), canceled: make(chan error, 1)}
+	defer close(service.release)
+	if err := server.RegisterName("slow", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetMethodTimeout("slow_stuck", 50*time.Millisecond)
+	server.SetDefaultMethodTimeout(time.Minute)
+	client := DialInProc(server)
+	defer client.Close()
+
+	start := time.Now()
+	wantCallError(t, client.Call(nil, "slow_stuck"), errcodeTimeout)
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("timeout took %v", d)
+	}
+
+	// Other methods use the default timeout.
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	server.SetDefaultMethodTimeout(50 * time.Millisecond)
+	wantCallError(t, client.Call(nil, "slow_wait"), errcodeTimeout)
+	select {
+	case err := <-service.canceled:
+		if err != context.Canceled {
+			t.Fatalf("wrong context error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("context of timed out call not canceled")
+	}
+
+	// Removing the method timeout falls back to the default.
+	server.SetDefaultMethodTimeout(0)
+	server.SetMethodTimeout("slow_stuck", 0)
+	if d := server.services.snapshot().methodTimeout("slow_stuck"); d != 0 {
+		t.Fatalf("timeout still set: %v", d)
+	}
+}
+
+func TestMethodTimeoutKeepsCall(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := &timeoutTestService{release: make(chan struct{})}
+	if err := server.RegisterName("slow", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetMethodTimeout("slow_stuck", 20*time.Millisecond)
+	server.SetConcurrencyLimits(ConcurrencyLimits{MaxConcurrentCalls: 1})
+	client := DialInProc(server)
+	defer client.Close()
+
+	// The timed out call holds its slot until the method returns.
+	wantCallError(t, client.Call(nil, "slow_stuck"), errcodeTimeout)
+	if stats := server.ConcurrencyStats(); stats.Running != 1 {
+		t.Fatalf("running calls %d after timeout, want 1", stats.Running)
+	}
+	close(service.release)
+	for server.ConcurrencyStats().Running != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
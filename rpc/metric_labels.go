@@ -0,0 +1,134 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// otherMethodLabel replaces method names which are not used as metric labels.
+	otherMethodLabel = "other"
+
+	defaultMetricMethods    = 512
+	defaultMetricErrorCodes = 64
+
+	// maxLoggedMethodLength is the length at which method names are cut off in logs.
+	maxLoggedMethodLength = 64
+)
+
+// MetricsCardinality limits the number of distinct labels the server uses in metrics,
+// see SetMetricsCardinality.
+type MetricsCardinality struct {
+	// MaxMethods is the number of distinct method names used as labels. Default 512.
+	MaxMethods int
+
+	// MaxErrorCodes is the number of distinct error codes reported to ServerMetrics.
+	// Default 64.
+	MaxErrorCodes int
+}
+
+// SetMetricsCardinality sets the limits on metric labels. Metrics and ServerMetrics only
+// ever see the names of registered methods, and unknown methods are reported as "other",
+// so clients calling random method names can't create new metrics. The limits bound the
+// labels further: once MaxMethods method names have been reported, other methods are
+// reported as "other", and once MaxErrorCodes error codes have been reported, other
+// codes are reported as -32000. Request ids and parameters are never used as labels.
+//
+// Changing the limits forgets the labels reported so far.
+func (s *Server) SetMetricsCardinality(limits MetricsCardinality) {
+	s.services.labels.setLimits(limits)
+}
+
+// metricLabels tracks the labels reported to metrics.
+type metricLabels struct {
+	mu      sync.Mutex
+	limits  MetricsCardinality
+	methods map[string]struct{}
+	codes   map[int]struct{}
+}
+
+func (l *metricLabels) setLimits(limits MetricsCardinality) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+	l.methods, l.codes = nil, nil
+}
+
+// method returns the label of a call of a registered method. The empty name of an
+// unknown method is reported as "other".
+func (l *metricLabels) method(name string) string {
+	if name == "" {
+		return otherMethodLabel
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.methods[name]; ok {
+		return name
+	}
+	limit := l.limits.MaxMethods
+	if limit <= 0 {
+		limit = defaultMetricMethods
+	}
+	if len(l.methods) >= limit {
+		return otherMethodLabel
+	}
+	if l.methods == nil {
+		l.methods = make(map[string]struct{})
+	}
+	l.methods[name] = struct{}{}
+	return name
+}
+
+// errorCode returns the label of an error code.
+func (l *metricLabels) errorCode(code int) int {
+	if code == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.codes[code]; ok {
+		return code
+	}
+	limit := l.limits.MaxErrorCodes
+	if limit <= 0 {
+		limit = defaultMetricErrorCodes
+	}
+	if len(l.codes) >= limit {
+		return errcodeDefault
+	}
+	if l.codes == nil {
+		l.codes = make(map[int]struct{})
+	}
+	l.codes[code] = struct{}{}
+	return code
+}
+
+// logMethod sanitizes a method name sent by a client for use in log messages.
+func logMethod(name string) string {
+	if len(name) > maxLoggedMethodLength {
+		name = name[:maxLoggedMethodLength] + "..."
+	}
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return '?'
+		}
+		return r
+	}, name)
+}
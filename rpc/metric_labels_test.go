@@ -0,0 +1,70 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetricsCardinality(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	m := new(recordingMetrics)
+	server.SetMetrics(m)
+	server.SetSynchronousExecution(true)
+	server.SetMetricsCardinality(MetricsCardinality{MaxMethods: 2, MaxErrorCodes: 1})
+	client := DialInProc(server)
+	defer client.Close()
+
+	client.Call(nil, "test_echo", "x", 1)
+	client.Call(nil, "test_noArgsRets")
+	client.Call(nil, "test_null")
+	client.Call(nil, "test_echo", "x", 1)
+	client.Call(nil, "test_random123")
+	client.Call(nil, "test_returnError")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	want := []string{
+		"test_echo:0",
+		"test_noArgsRets:0",
+		"other:0",      // method limit reached
+		"test_echo:0",  // already known
+		"other:-32601", // unknown method
+		"other:-32000", // error code limit reached
+	}
+	if !reflect.DeepEqual(m.calls, want) {
+		t.Errorf("wrong calls %v, want %v", m.calls, want)
+	}
+}
+
+func TestLogMethod(t *testing.T) {
+	tests := map[string]string{
+		"eth_call":               "eth_call",
+		"eth_\x00call\n":         "eth_?call?",
+		strings.Repeat("a", 100): strings.Repeat("a", maxLoggedMethodLength) + "...",
+	}
+	for in, want := range tests {
+		if got := logMethod(in); got != want {
+			t.Errorf("logMethod(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
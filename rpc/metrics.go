@@ -32,6 +32,24 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	notificationsDroppedCounter  = metrics.NewRegisteredCounter("rpc/notifications/dropped", nil)
+	notificationsMergedCounter   = metrics.NewRegisteredCounter("rpc/notifications/merged", nil)
+	notificationsFilteredCounter = metrics.NewRegisteredCounter("rpc/notifications/filtered", nil)
+	notificationsOverflowCounter = metrics.NewRegisteredCounter("rpc/notifications/overflow", nil)
+
+	shadowSentCounter     = metrics.NewRegisteredCounter("rpc/shadow/sent", nil)
+	shadowSkippedCounter  = metrics.NewRegisteredCounter("rpc/shadow/skipped", nil)
+	shadowFailedCounter   = metrics.NewRegisteredCounter("rpc/shadow/failed", nil)
+	shadowDivergedCounter = metrics.NewRegisteredCounter("rpc/shadow/diverged", nil)
+
+	leakedGoroutinesCounter      = metrics.NewRegisteredCounter("rpc/guard/leaked", nil)
+	memoryCeilingExceededCounter = metrics.NewRegisteredCounter("rpc/memory/exceeded", nil)
+	encodesQueuedCounter         = metrics.NewRegisteredCounter("rpc/encode/queued", nil)
+	replayedCounter              = metrics.NewRegisteredCounter("rpc/replayed", nil)
+	coalescedCallCounter         = metrics.NewRegisteredCounter("rpc/coalesced", nil)
+
+	wsCompressionLevelGauge = metrics.NewRegisteredGauge("rpc/ws/compression/level", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -0,0 +1,196 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MiddlewareOption configures the position of a middleware added by UseMiddleware.
+type MiddlewareOption interface {
+	applyMiddlewareOption(*middlewareEntry)
+}
+
+type middlewareOptionFunc func(*middlewareEntry)
+
+func (fn middlewareOptionFunc) applyMiddlewareOption(e *middlewareEntry) {
+	fn(e)
+}
+
+// WithPriority sets the priority of a middleware. Middlewares with higher priority run
+// first, i.e. they wrap the middlewares of lower priority. Middlewares of equal priority
+// run in the order they were added. The default priority is zero.
+func WithPriority(n int) MiddlewareOption {
+	return middlewareOptionFunc(func(e *middlewareEntry) { e.Priority = n })
+}
+
+// Before places a middleware immediately before the named middleware, so it wraps it.
+// The middleware takes the priority of the named one.
+func Before(name string) MiddlewareOption {
+	return middlewareOptionFunc(func(e *middlewareEntry) { e.before, e.after = name, "" })
+}
+
+// After places a middleware immediately after the named middleware, so it is wrapped
+// by it. The middleware takes the priority of the named one.
+func After(name string) MiddlewareOption {
+	return middlewareOptionFunc(func(e *middlewareEntry) { e.before, e.after = "", name })
+}
+
+// MiddlewareInfo describes an entry of the middleware chain, see Server.Middlewares.
+type MiddlewareInfo struct {
+	Name     string
+	Priority int
+}
+
+type middlewareEntry struct {
+	MiddlewareInfo
+	fn            Middleware
+	before, after string
+}
+
+// UseMiddleware adds a named middleware to the chain of middlewares wrapping method
+// calls. By default it is placed after all middlewares of equal or higher priority,
+// see WithPriority, Before and After. The name must be unique, and an error is
+// returned if a middleware of that name exists already or the middleware named by
+// Before or After doesn't exist.
+//
+// The chain can be modified while the server is running. Calls in progress keep using
+// the chain which was in effect when they started.
+func (s *Server) UseMiddleware(name string, mw Middleware, opts ...MiddlewareOption) error {
+	entry := middlewareEntry{MiddlewareInfo: MiddlewareInfo{Name: name}, fn: mw}
+	for _, opt := range opts {
+		opt.applyMiddlewareOption(&entry)
+	}
+	var err error
+	s.services.updateConfig(func(cfg *registryConfig) {
+		var chain []middlewareEntry
+		if chain, err = insertMiddleware(cfg.middlewareChain, entry); err == nil {
+			cfg.setMiddlewareChain(chain)
+		}
+	})
+	return err
+}
+
+// RemoveMiddleware removes the named middleware from the chain. It returns false if
+// there is no middleware of that name.
+func (s *Server) RemoveMiddleware(name string) bool {
+	var found bool
+	s.services.updateConfig(func(cfg *registryConfig) {
+		i := slices.IndexFunc(cfg.middlewareChain, func(e middlewareEntry) bool { return e.Name == name })
+		if found = i >= 0; found {
+			cfg.setMiddlewareChain(slices.Delete(slices.Clone(cfg.middlewareChain), i, i+1))
+		}
+	})
+	return found
+}
+
+// Middlewares returns the middleware chain in execution order, outermost first.
+func (s *Server) Middlewares() []MiddlewareInfo {
+	chain := s.services.snapshot().middlewareChain
+	infos := make([]MiddlewareInfo, len(chain))
+	for i, e := range chain {
+		infos[i] = e.MiddlewareInfo
+	}
+	return infos
+}
+
+// scopedMiddleware is a middleware added by UseForNamespace.
+type scopedMiddleware struct {
+	pattern string
+	fn      Middleware
+}
+
+// UseForNamespace adds middlewares which only wrap calls of the given scope. The scope
+// is either a namespace like "debug", or a method name, which may end in '*' to match
+// all methods starting with the prefix, e.g. "eth_get*".
+//
+// Scoped middlewares run inside the global chain set by UseMiddleware and
+// SetMiddlewares, in the order they were added. Multiple calls for the same scope
+// append to its chain.
+func (s *Server) UseForNamespace(scope string, mws ...Middleware) {
+	pattern := scope
+	if !strings.Contains(scope, serviceMethodSeparator) && !strings.HasSuffix(scope, "*") {
+		pattern = scope + serviceMethodSeparator + "*"
+	}
+	s.services.updateConfig(func(cfg *registryConfig) {
+		scoped := slices.Clone(cfg.scopedMiddlewares)
+		for _, mw := range mws {
+			scoped = append(scoped, scopedMiddleware{pattern, mw})
+		}
+		cfg.scopedMiddlewares = scoped
+	})
+}
+
+// methodMiddlewares returns the middlewares wrapping calls of method, outermost first.
+func (cfg *registryConfig) methodMiddlewares(method string) []Middleware {
+	mws := slices.Clip(cfg.middlewares) // appending must not modify the snapshot
+	for _, e := range cfg.scopedMiddlewares {
+		if matchMethod([]string{e.pattern}, method) {
+			mws = append(mws, e.fn)
+		}
+	}
+	return mws
+}
+
+// insertMiddleware returns a copy of chain with e added at its position.
+func insertMiddleware(chain []middlewareEntry, e middlewareEntry) ([]middlewareEntry, error) {
+	if slices.ContainsFunc(chain, func(x middlewareEntry) bool { return x.Name == e.Name }) {
+		return nil, fmt.Errorf("middleware %q already exists", e.Name)
+	}
+	var pos int
+	switch {
+	case e.before != "" || e.after != "":
+		ref := e.before + e.after
+		i := slices.IndexFunc(chain, func(x middlewareEntry) bool { return x.Name == ref })
+		if i < 0 {
+			return nil, fmt.Errorf("middleware %q not found", ref)
+		}
+		e.Priority = chain[i].Priority
+		pos = i
+		if e.after != "" {
+			pos++
+		}
+	default:
+		pos = len(chain)
+		for pos > 0 && chain[pos-1].Priority < e.Priority {
+			pos--
+		}
+	}
+	e.before, e.after = "", ""
+	return slices.Insert(slices.Clone(chain), pos, e), nil
+}
+
+// setMiddlewareChain installs chain and the list of middlewares derived from it.
+func (cfg *registryConfig) setMiddlewareChain(chain []middlewareEntry) {
+	cfg.middlewareChain = chain
+	cfg.middlewares = make([]Middleware, len(chain))
+	for i, e := range chain {
+		cfg.middlewares[i] = e.fn
+	}
+}
+
+// anonymousMiddlewares names the middlewares installed by SetMiddlewares.
+func anonymousMiddlewares(middlewares []Middleware) []middlewareEntry {
+	chain := make([]middlewareEntry, len(middlewares))
+	for i, mw := range middlewares {
+		chain[i] = middlewareEntry{MiddlewareInfo: MiddlewareInfo{Name: "#" + strconv.Itoa(i)}, fn: mw}
+	}
+	return chain
+}
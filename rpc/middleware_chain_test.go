@@ -0,0 +1,159 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestUseMiddleware(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) Middleware {
+		return func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return next(ctx, method, args)
+		}
+	}
+	use := func(name string, opts ...MiddlewareOption) {
+		t.Helper()
+		if err := server.UseMiddleware(name, record(name), opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	use("metrics")
+	use("auth", WithPriority(10))
+	use("ratelimit", After("auth"))
+	use("tracing", Before("metrics"))
+	use("logging")
+	use("recover", WithPriority(100))
+
+	if err := server.UseMiddleware("auth", record("auth")); err == nil {
+		t.Error("duplicate name accepted")
+	}
+	if err := server.UseMiddleware("x", record("x"), Before("missing")); err == nil {
+		t.Error("reference to missing middleware accepted")
+	}
+
+	want := []MiddlewareInfo{
+		{"recover", 100},
+		{"auth", 10},
+		{"ratelimit", 10},
+		{"tracing", 0},
+		{"metrics", 0},
+		{"logging", 0},
+	}
+	if got := server.Middlewares(); !slices.Equal(got, want) {
+		t.Fatalf("wrong chain\ngot  %v\nwant %v", got, want)
+	}
+
+	client := DialInProc(server)
+	defer client.Close()
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"recover", "auth", "ratelimit", "tracing", "metrics", "logging"}; !slices.Equal(order, want) {
+		t.Fatalf("wrong execution order %v", order)
+	}
+
+	if !server.RemoveMiddleware("ratelimit") || server.RemoveMiddleware("ratelimit") {
+		t.Fatal("wrong result of RemoveMiddleware")
+	}
+	order = nil
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(order, "ratelimit") {
+		t.Fatalf("removed middleware still runs: %v", order)
+	}
+}
+
+func TestSetMiddlewaresNames(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	server.UseMiddleware("named", nil)
+	server.SetMiddlewares([]Middleware{nil, nil})
+
+	want := []MiddlewareInfo{{"#0", 0}, {"#1", 0}}
+	if got := server.Middlewares(); !slices.Equal(got, want) {
+		t.Fatalf("wrong chain %v", got)
+	}
+	if err := server.UseMiddleware("first", nil, Before("#0")); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.Middlewares(); got[0].Name != "first" {
+		t.Fatalf("wrong chain %v", got)
+	}
+}
+
+func TestUseForNamespace(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) Middleware {
+		return func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return next(ctx, method, args)
+		}
+	}
+	server.UseMiddleware("global", record("global"))
+	server.UseForNamespace("nftest", record("ns1"), record("ns2"))
+	server.UseForNamespace("test_echo*", record("echo"))
+	server.UseForNamespace("test_sleep", record("sleep"))
+	client := DialInProc(server)
+	defer client.Close()
+
+	tests := []struct {
+		method string
+		args   []any
+		want   []string
+	}{
+		{"test_echo", []any{"x", 1, nil}, []string{"global", "echo"}},
+		{"test_echoWithCtx", []any{"x", 1, nil}, []string{"global", "echo"}},
+		{"test_sleep", []any{0}, []string{"global", "sleep"}},
+		{"test_peerInfo", nil, []string{"global"}},
+		{"nftest_echo", []any{1}, []string{"global", "ns1", "ns2"}},
+	}
+	for _, test := range tests {
+		order = nil
+		if err := client.Call(nil, test.method, test.args...); err != nil {
+			t.Fatalf("%s: %v", test.method, err)
+		}
+		mu.Lock()
+		if !slices.Equal(order, test.want) {
+			t.Errorf("%s: wrong middlewares %v, want %v", test.method, order, test.want)
+		}
+		mu.Unlock()
+	}
+}
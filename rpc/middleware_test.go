@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -78,7 +79,7 @@ func TestMiddlewareExecution(t *testing.T) {
 
 	msg := &jsonrpcMessage{Method: "test_echo"}
 	args := []reflect.Value{reflect.ValueOf("hello")}
-	h.runMethod(context.Background(), msg, cb, args)
+	h.runMethod(context.Background(), h.reg.snapshot(), msg, cb, args)
 
 	if atomic.LoadInt32(&middlewareCalled) != 1 {
 		t.Errorf("Middleware was not called")
@@ -137,7 +138,7 @@ func TestMiddlewareChain(t *testing.T) {
 
 	msg := &jsonrpcMessage{Method: "test_add"}
 	args := []reflect.Value{reflect.ValueOf(1), reflect.ValueOf(2)}
-	h.runMethod(context.Background(), msg, cb, args)
+	h.runMethod(context.Background(), h.reg.snapshot(), msg, cb, args)
 
 	expected := []int{1, 2, 3, 4}
 	mu.Lock()
@@ -188,10 +189,80 @@ func TestServerMiddleware(t *testing.T) {
 	// Call the method
 	msg := &jsonrpcMessage{Method: "test_echo"}
 	args := []reflect.Value{reflect.ValueOf("hello")}
-	h.runMethod(context.Background(), msg, cb, args)
+	h.runMethod(context.Background(), h.reg.snapshot(), msg, cb, args)
 
 	// Verify middleware was called
 	if atomic.LoadInt32(&middlewareCalled) != 1 {
 		t.Errorf("Server middleware was not called")
 	}
 }
+
+type stableCodeError struct{ err error }
+
+func (e stableCodeError) Error() string          { return "request failed" }
+func (e stableCodeError) ErrorCode() int         { return -32099 }
+func (e stableCodeError) ErrorData() interface{} { return e.err.Error() }
+
+// TestMiddlewareRewritesResult tests that middlewares can replace results and errors.
+func TestMiddlewareRewritesResult(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	var cacheHits atomic.Int32
+	server.SetMiddlewares([]Middleware{
+		// Logging: observes metadata set by inner middlewares.
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			res := next(ctx, method, args)
+			if res.Metadata["cache"] == "hit" {
+				cacheHits.Add(1)
+			}
+			return res
+		},
+		// Redaction and error rewriting.
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			res := next(ctx, method, args)
+			if res.Error != nil {
+				res.Error = stableCodeError{res.Error}
+			} else if r, ok := res.Result.(echoResult); ok {
+				r.String = "redacted"
+				res.Result = r
+			}
+			return res
+		},
+		// Caching: answers without running the method.
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			if method == "test_echo" && args[1].Int() == 42 {
+				return &MethodResult{
+					Result:   echoResult{String: "cached", Int: 42},
+					Metadata: map[string]interface{}{"cache": "hit"},
+				}
+			}
+			return next(ctx, method, args)
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "secret", 1); err != nil {
+		t.Fatal(err)
+	}
+	if result.String != "redacted" || result.Int != 1 {
+		t.Errorf("result not redacted: %+v", result)
+	}
+	if err := client.Call(&result, "test_echo", "x", 42); err != nil {
+		t.Fatal(err)
+	}
+	if result.String != "redacted" || result.Int != 42 || cacheHits.Load() != 1 {
+		t.Errorf("wrong cached result %+v, %d hits", result, cacheHits.Load())
+	}
+
+	err := client.Call(nil, "test_returnError")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32099 || err.Error() != "request failed" {
+		t.Fatalf("error not rewritten: %v", err)
+	}
+	if data := err.(DataError).ErrorData(); data != "testError" {
+		t.Errorf("wrong error data %v", data)
+	}
+}
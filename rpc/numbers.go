@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberPolicy configures how JSON numbers are decoded into values of interface type,
+// e.g. interface{} parameters and results, or map[string]interface{}. Numbers decoded
+// into typed values are not affected.
+type NumberPolicy int
+
+const (
+	// NumberFloat64 decodes numbers as float64. This is the default behavior of
+	// encoding/json. Integers larger than 2^53 lose precision.
+	NumberFloat64 NumberPolicy = iota
+
+	// NumberJSONNumber decodes numbers as json.Number, preserving their text.
+	NumberJSONNumber
+
+	// NumberBigInt decodes integers as *big.Int and all other numbers as float64.
+	NumberBigInt
+)
+
+// SetNumberPolicy configures how numbers in call parameters are decoded when the
+// parameter type is an interface. The default is NumberFloat64.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetNumberPolicy(p NumberPolicy) {
+	s.numberPolicy = p
+}
+
+// unmarshal decodes data into v according to the policy.
+func (p NumberPolicy) unmarshal(data []byte, v interface{}) error {
+	if p == NumberFloat64 {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	p.convert(reflect.ValueOf(v))
+	return nil
+}
+
+// convert replaces the json.Number values stored in interface values within v,
+// if the policy requires it. The decoder must be configured with UseNumber.
+func (p NumberPolicy) convert(v reflect.Value) {
+	if p == NumberBigInt {
+		convertNumbers(v)
+	}
+}
+
+func convertNumbers(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if n, ok := v.Elem().Interface().(json.Number); ok {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(bigIntOrFloat(n)))
+			}
+			return
+		}
+		convertNumbers(v.Elem())
+	case reflect.Pointer:
+		if !v.IsNil() {
+			convertNumbers(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				convertNumbers(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			convertNumbers(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values aren't addressable, so convert a copy and store it back.
+			val := reflect.New(v.Type().Elem()).Elem()
+			val.Set(iter.Value())
+			convertNumbers(val)
+			v.SetMapIndex(iter.Key(), val)
+		}
+	}
+}
+
+// bigIntOrFloat converts integers to *big.Int and other numbers to float64.
+func bigIntOrFloat(n json.Number) interface{} {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		if i, ok := new(big.Int).SetString(s, 10); ok {
+			return i
+		}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestNumberPolicyUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	const input = `{"i": 123456789012345678901234567890, "f": 1.5, "l": [1, {"n": 2}], "t": 3}`
+	type typed struct {
+		I any
+		F any
+		L []any
+		T int
+	}
+	tests := []struct {
+		policy NumberPolicy
+		want   string
+	}{
+		{NumberFloat64, "float64 float64 float64 float64"},
+		{NumberJSONNumber, "json.Number json.Number json.Number json.Number"},
+		{NumberBigInt, "*big.Int float64 *big.Int *big.Int"},
+	}
+	for _, test := range tests {
+		// Decode into interface{}.
+		var v any
+		if err := test.policy.unmarshal([]byte(input), &v); err != nil {
+			t.Fatal(err)
+		}
+		m := v.(map[string]any)
+		l := m["l"].([]any)
+		got := fmt.Sprintf("%T %T %T %T", m["i"], m["f"], l[0], l[1].(map[string]any)["n"])
+		if got != test.want {
+			t.Errorf("policy %d: got types %s, want %s", test.policy, got, test.want)
+		}
+
+		// Decode into a struct with interface fields.
+		var s typed
+		if err := test.policy.unmarshal([]byte(input), &s); err != nil {
+			t.Fatal(err)
+		}
+		got = fmt.Sprintf("%T %T %T %T", s.I, s.F, s.L[0], s.L[1].(map[string]any)["n"])
+		if got != test.want {
+			t.Errorf("policy %d: got struct field types %s, want %s", test.policy, got, test.want)
+		}
+		if s.T != 3 {
+			t.Errorf("policy %d: wrong typed field %d", test.policy, s.T)
+		}
+	}
+
+	// Large integers are decoded precisely.
+	var v any
+	NumberBigInt.unmarshal([]byte("123456789012345678901234567890"), &v)
+	want, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	if v.(*big.Int).Cmp(want) != 0 {
+		t.Errorf("wrong value %v", v)
+	}
+}
+
+type numberTestService struct{}
+
+func (numberTestService) TypeOf(v any) string {
+	return fmt.Sprintf("%T", v)
+}
+
+func TestNumberPolicyParams(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	server.SetNumberPolicy(NumberBigInt)
+	server.RegisterName("num", numberTestService{})
+	defer server.Stop()
+	client := dialInProcWithOptions(server, WithNumberPolicy(NumberJSONNumber))
+	defer client.Close()
+
+	var typ string
+	if err := client.Call(&typ, "num_typeOf", json.RawMessage("99999999999999999999")); err != nil {
+		t.Fatal(err)
+	}
+	if typ != "*big.Int" {
+		t.Errorf("wrong parameter type %s", typ)
+	}
+
+	var result any
+	if err := client.Call(&result, "rpc_limits"); err != nil {
+		t.Fatal(err)
+	}
+	if n := result.(map[string]any)["httpBodyLimit"]; fmt.Sprintf("%T", n) != "json.Number" {
+		t.Errorf("wrong result number type %T", n)
+	}
+}
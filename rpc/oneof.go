@@ -0,0 +1,136 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OneOf2 is a method parameter type which accepts either of two JSON shapes, for
+// example a string tag or a number. When decoding, the alternatives are tried in order
+// and the first one that decodes without error is kept. Objects are decoded strictly,
+// i.e. unknown fields cause an alternative to be rejected.
+//
+// Which reports the index of the decoded alternative (1 for A, 2 for B), or zero if the
+// value was null or absent.
+type OneOf2[A, B any] struct {
+	A     A
+	B     B
+	which int
+}
+
+// Which returns the index of the set alternative, or zero if none is set.
+func (o OneOf2[A, B]) Which() int { return o.which }
+
+// SetA stores v as the first alternative.
+func (o *OneOf2[A, B]) SetA(v A) { *o = OneOf2[A, B]{A: v, which: 1} }
+
+// SetB stores v as the second alternative.
+func (o *OneOf2[A, B]) SetB(v B) { *o = OneOf2[A, B]{B: v, which: 2} }
+
+// MarshalJSON encodes the set alternative. An unset value encodes as null.
+func (o OneOf2[A, B]) MarshalJSON() ([]byte, error) {
+	return encodeOneOf(o.which, &o.A, &o.B)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OneOf2[A, B]) UnmarshalJSON(input []byte) error {
+	var v OneOf2[A, B]
+	which, err := decodeOneOf(input, &v.A, &v.B)
+	if err != nil {
+		return err
+	}
+	v.which = which
+	*o = v
+	return nil
+}
+
+// OneOf3 is like OneOf2, but accepts three alternative JSON shapes.
+type OneOf3[A, B, C any] struct {
+	A     A
+	B     B
+	C     C
+	which int
+}
+
+// Which returns the index of the set alternative, or zero if none is set.
+func (o OneOf3[A, B, C]) Which() int { return o.which }
+
+// SetA stores v as the first alternative.
+func (o *OneOf3[A, B, C]) SetA(v A) { *o = OneOf3[A, B, C]{A: v, which: 1} }
+
+// SetB stores v as the second alternative.
+func (o *OneOf3[A, B, C]) SetB(v B) { *o = OneOf3[A, B, C]{B: v, which: 2} }
+
+// SetC stores v as the third alternative.
+func (o *OneOf3[A, B, C]) SetC(v C) { *o = OneOf3[A, B, C]{C: v, which: 3} }
+
+// MarshalJSON encodes the set alternative. An unset value encodes as null.
+func (o OneOf3[A, B, C]) MarshalJSON() ([]byte, error) {
+	return encodeOneOf(o.which, &o.A, &o.B, &o.C)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OneOf3[A, B, C]) UnmarshalJSON(input []byte) error {
+	var v OneOf3[A, B, C]
+	which, err := decodeOneOf(input, &v.A, &v.B, &v.C)
+	if err != nil {
+		return err
+	}
+	v.which = which
+	*o = v
+	return nil
+}
+
+func encodeOneOf(which int, alternatives ...any) ([]byte, error) {
+	if which == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal(alternatives[which-1])
+}
+
+// decodeOneOf decodes input into the first matching alternative and returns its
+// one-based index. The alternatives must be pointers.
+func decodeOneOf(input []byte, alternatives ...any) (int, error) {
+	if bytes.Equal(bytes.TrimSpace(input), []byte("null")) {
+		return 0, nil
+	}
+	for i, alt := range alternatives {
+		dst := reflect.New(reflect.TypeOf(alt).Elem())
+		if err := decodeStrict(input, dst.Interface()); err != nil {
+			continue
+		}
+		reflect.ValueOf(alt).Elem().Set(dst.Elem())
+		return i + 1, nil
+	}
+	names := make([]string, len(alternatives))
+	for i, alt := range alternatives {
+		names[i] = reflect.TypeOf(alt).Elem().String()
+	}
+	return 0, fmt.Errorf("value does not match any of %s", strings.Join(names, ", "))
+}
+
+// decodeStrict decodes input into dst, rejecting unknown object fields.
+func decodeStrict(input []byte, dst any) error {
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.DisallowUnknownFields()
+	return dec.Decode(dst)
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type oneOfRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+type oneOfService struct{}
+
+func (oneOfService) Describe(v OneOf3[string, uint64, oneOfRange]) (string, error) {
+	switch v.Which() {
+	case 1:
+		return "tag " + v.A, nil
+	case 2:
+		return "number " + Uint64Hex(v.B).String(), nil
+	case 3:
+		return "range " + Uint64Hex(v.C.From).String() + "-" + Uint64Hex(v.C.To).String(), nil
+	}
+	return "none", nil
+}
+
+func TestOneOfUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		which int
+		err   bool
+	}{
+		{input: `"latest"`, which: 1},
+		{input: `17`, which: 2},
+		{input: `{"from":1,"to":2}`, which: 3},
+		{input: `null`, which: 0},
+		{input: `{"from":1,"until":2}`, err: true},
+		{input: `-1`, err: true},
+		{input: `[1]`, err: true},
+	}
+	for _, test := range tests {
+		var v OneOf3[string, uint64, oneOfRange]
+		err := json.Unmarshal([]byte(test.input), &v)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got alternative %d", test.input, v.Which())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.input, err)
+		} else if v.Which() != test.which {
+			t.Errorf("%s: got alternative %d, want %d", test.input, v.Which(), test.which)
+		}
+	}
+}
+
+func TestOneOfMarshal(t *testing.T) {
+	t.Parallel()
+
+	var v OneOf2[string, uint64]
+	if enc, _ := json.Marshal(v); string(enc) != "null" {
+		t.Errorf("unset value encoded as %s", enc)
+	}
+	v.SetB(5)
+	if enc, _ := json.Marshal(v); string(enc) != "5" {
+		t.Errorf("got %s, want 5", enc)
+	}
+	v.SetA("pending")
+	if enc, _ := json.Marshal(v); string(enc) != `"pending"` {
+		t.Errorf("got %s, want \"pending\"", enc)
+	}
+}
+
+func TestOneOfParam(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("oneof", oneOfService{}); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	for _, test := range []struct {
+		arg  any
+		want string
+	}{
+		{"latest", "tag latest"},
+		{31, "number 0x1f"},
+		{oneOfRange{From: 1, To: 16}, "range 0x1-0x10"},
+	} {
+		var result string
+		if err := client.Call(&result, "oneof_describe", test.arg); err != nil {
+			t.Fatalf("%v: %v", test.arg, err)
+		}
+		if result != test.want {
+			t.Errorf("%v: got %q, want %q", test.arg, result, test.want)
+		}
+	}
+
+	var result string
+	err := client.Call(&result, "oneof_describe", true)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32602 {
+		t.Fatalf("expected invalid params error, got %v", err)
+	}
+}
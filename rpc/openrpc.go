@@ -0,0 +1,514 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ParamValidator checks call parameters against the method descriptions of an OpenRPC
+// document. Parameter schemas are interpreted as JSON schema, supporting the keywords
+// $ref (local references into "components"), type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, minLength, maxLength, pattern, anyOf, oneOf and allOf. Other keywords
+// are ignored.
+//
+// A validator can be installed on a Server using SetParamValidator. It may also be used
+// directly, e.g. by proxies which forward methods they do not implement.
+type ParamValidator struct {
+	methods    map[string]*openrpcMethod
+	components map[string]map[string]json.RawMessage
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+type openrpcDocument struct {
+	Methods    []json.RawMessage                     `json:"methods"`
+	Components map[string]map[string]json.RawMessage `json:"components"`
+}
+
+type openrpcMethod struct {
+	Name   string                      `json:"name"`
+	Params []*openrpcContentDescriptor `json:"params"`
+}
+
+type openrpcContentDescriptor struct {
+	Ref      string          `json:"$ref"`
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Schema   json.RawMessage `json:"schema"`
+}
+
+// ParamViolation describes a single validation failure. Path locates the offending
+// value, starting with the parameter name.
+type ParamViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ParamValidationError is returned when call parameters do not match the schema. It is
+// sent to clients as an invalid params (-32602) error, with the list of violations as
+// error data.
+type ParamValidationError struct {
+	Method     string
+	Violations []ParamViolation
+}
+
+func (e *ParamValidationError) ErrorCode() int { return -32602 }
+
+func (e *ParamValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return fmt.Sprintf("invalid params for %s", e.Method)
+	}
+	v := e.Violations[0]
+	return fmt.Sprintf("invalid params for %s: %s: %s", e.Method, v.Path, v.Message)
+}
+
+func (e *ParamValidationError) ErrorData() interface{} { return e.Violations }
+
+// NewParamValidator creates a validator from an OpenRPC document.
+func NewParamValidator(document []byte) (*ParamValidator, error) {
+	var doc openrpcDocument
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenRPC document: %v", err)
+	}
+	v := &ParamValidator{
+		methods:    make(map[string]*openrpcMethod, len(doc.Methods)),
+		components: doc.Components,
+		patterns:   make(map[string]*regexp.Regexp),
+	}
+	for i, raw := range doc.Methods {
+		var m openrpcMethod
+		if err := v.resolve(raw, &m); err != nil {
+			return nil, fmt.Errorf("method %d: %v", i, err)
+		}
+		if m.Name == "" {
+			return nil, fmt.Errorf("method %d has no name", i)
+		}
+		for j, p := range m.Params {
+			if p.Ref == "" {
+				continue
+			}
+			raw, err := v.lookupRef(p.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("method %s param %d: %v", m.Name, j, err)
+			}
+			if err := json.Unmarshal(raw, p); err != nil {
+				return nil, fmt.Errorf("method %s param %d: %v", m.Name, j, err)
+			}
+		}
+		v.methods[m.Name] = &m
+	}
+	return v, nil
+}
+
+// Methods returns the names of all methods described by the document.
+func (v *ParamValidator) Methods() []string {
+	names := make([]string, 0, len(v.methods))
+	for name := range v.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks params against the description of method. Params may be a positional
+// array or an object of named parameters. Methods not described by the document are
+// accepted. The returned error is a *ParamValidationError.
+func (v *ParamValidator) Validate(method string, params json.RawMessage) error {
+	m := v.methods[method]
+	if m == nil {
+		return nil
+	}
+	var vs validationState
+	args, byName, err := decodeParams(params)
+	if err != nil {
+		vs.fail("params", err.Error())
+		return &ParamValidationError{Method: method, Violations: vs.violations}
+	}
+	if byName != nil {
+		for _, p := range m.Params {
+			val, ok := byName[p.Name]
+			if !ok {
+				if p.Required {
+					vs.fail(p.Name, "missing required parameter")
+				}
+				continue
+			}
+			delete(byName, p.Name)
+			v.validate(&vs, p.Name, p.Schema, val)
+		}
+		for _, name := range sortedKeys(byName) {
+			vs.fail(name, "unknown parameter")
+		}
+	} else {
+		if len(args) > len(m.Params) {
+			vs.fail("params", fmt.Sprintf("too many parameters, want at most %d", len(m.Params)))
+		}
+		for i, p := range m.Params {
+			if i >= len(args) {
+				if p.Required {
+					vs.fail(p.Name, "missing required parameter")
+				}
+				continue
+			}
+			v.validate(&vs, p.Name, p.Schema, args[i])
+		}
+	}
+	if len(vs.violations) > 0 {
+		return &ParamValidationError{Method: method, Violations: vs.violations}
+	}
+	return nil
+}
+
+type validationState struct {
+	violations []ParamViolation
+}
+
+func (vs *validationState) fail(path, msg string) {
+	vs.violations = append(vs.violations, ParamViolation{Path: path, Message: msg})
+}
+
+// validate checks val against the given schema and records violations in vs.
+func (v *ParamValidator) validate(vs *validationState, path string, schema json.RawMessage, val any) {
+	if len(schema) == 0 {
+		return
+	}
+	var s map[string]json.RawMessage
+	if err := v.resolve(schema, &s); err != nil {
+		vs.fail(path, "invalid schema: "+err.Error())
+		return
+	}
+	if s == nil {
+		// Boolean schemas: true accepts everything, false nothing.
+		if bytes.Equal(bytes.TrimSpace(schema), []byte("false")) {
+			vs.fail(path, "value not allowed")
+		}
+		return
+	}
+
+	if raw, ok := s["type"]; ok {
+		var types []string
+		if err := json.Unmarshal(raw, &types); err != nil {
+			var t string
+			json.Unmarshal(raw, &t)
+			types = []string{t}
+		}
+		if !matchesType(val, types) {
+			vs.fail(path, fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonType(val)))
+			return
+		}
+	}
+	if raw, ok := s["enum"]; ok {
+		var options []json.RawMessage
+		json.Unmarshal(raw, &options)
+		found := false
+		for _, o := range options {
+			if equalJSON(o, val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			vs.fail(path, "value is not one of "+string(raw))
+		}
+	}
+	if raw, ok := s["const"]; ok && !equalJSON(raw, val) {
+		vs.fail(path, "value must be "+string(raw))
+	}
+
+	switch val := val.(type) {
+	case map[string]any:
+		v.validateObject(vs, path, s, val)
+	case []any:
+		if n, ok := schemaInt(s, "minItems"); ok && len(val) < n {
+			vs.fail(path, fmt.Sprintf("array must have at least %d items", n))
+		}
+		if n, ok := schemaInt(s, "maxItems"); ok && len(val) > n {
+			vs.fail(path, fmt.Sprintf("array must have at most %d items", n))
+		}
+		if items, ok := s["items"]; ok {
+			for i, elem := range val {
+				v.validate(vs, fmt.Sprintf("%s[%d]", path, i), items, elem)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(val)
+		if min, ok := schemaInt(s, "minLength"); ok && n < min {
+			vs.fail(path, fmt.Sprintf("string must have at least %d characters", min))
+		}
+		if max, ok := schemaInt(s, "maxLength"); ok && n > max {
+			vs.fail(path, fmt.Sprintf("string must have at most %d characters", max))
+		}
+		if raw, ok := s["pattern"]; ok {
+			var pattern string
+			json.Unmarshal(raw, &pattern)
+			re, err := v.compilePattern(pattern)
+			if err != nil {
+				vs.fail(path, "invalid schema pattern: "+err.Error())
+			} else if !re.MatchString(val) {
+				vs.fail(path, fmt.Sprintf("string does not match pattern %q", pattern))
+			}
+		}
+	case json.Number:
+		validateNumber(vs, path, s, val)
+	}
+
+	if raw, ok := s["allOf"]; ok {
+		var schemas []json.RawMessage
+		json.Unmarshal(raw, &schemas)
+		for _, sub := range schemas {
+			v.validate(vs, path, sub, val)
+		}
+	}
+	if raw, ok := s["anyOf"]; ok {
+		if v.countMatches(path, raw, val) == 0 {
+			vs.fail(path, "value does not match any allowed schema")
+		}
+	}
+	if raw, ok := s["oneOf"]; ok {
+		if n := v.countMatches(path, raw, val); n != 1 {
+			vs.fail(path, fmt.Sprintf("value must match exactly one schema, matched %d", n))
+		}
+	}
+}
+
+func (v *ParamValidator) validateObject(vs *validationState, path string, s map[string]json.RawMessage, val map[string]any) {
+	var required []string
+	json.Unmarshal(s["required"], &required)
+	for _, name := range required {
+		if _, ok := val[name]; !ok {
+			vs.fail(path+"."+name, "missing required field")
+		}
+	}
+	var props map[string]json.RawMessage
+	json.Unmarshal(s["properties"], &props)
+	additional, hasAdditional := s["additionalProperties"]
+	for _, name := range sortedKeys(val) {
+		if sub, ok := props[name]; ok {
+			v.validate(vs, path+"."+name, sub, val[name])
+		} else if hasAdditional {
+			v.validate(vs, path+"."+name, additional, val[name])
+		}
+	}
+}
+
+func validateNumber(vs *validationState, path string, s map[string]json.RawMessage, val json.Number) {
+	n, _ := new(big.Float).SetString(val.String())
+	bound := func(key string) (*big.Float, bool) {
+		raw, ok := s[key]
+		if !ok {
+			return nil, false
+		}
+		f, ok := new(big.Float).SetString(string(bytes.TrimSpace(raw)))
+		return f, ok
+	}
+	if b, ok := bound("minimum"); ok && n.Cmp(b) < 0 {
+		vs.fail(path, fmt.Sprintf("value must be >= %v", b))
+	}
+	if b, ok := bound("maximum"); ok && n.Cmp(b) > 0 {
+		vs.fail(path, fmt.Sprintf("value must be <= %v", b))
+	}
+	if b, ok := bound("exclusiveMinimum"); ok && n.Cmp(b) <= 0 {
+		vs.fail(path, fmt.Sprintf("value must be > %v", b))
+	}
+	if b, ok := bound("exclusiveMaximum"); ok && n.Cmp(b) >= 0 {
+		vs.fail(path, fmt.Sprintf("value must be < %v", b))
+	}
+}
+
+// countMatches returns how many of the schemas in the given list accept val.
+func (v *ParamValidator) countMatches(path string, list json.RawMessage, val any) int {
+	var schemas []json.RawMessage
+	json.Unmarshal(list, &schemas)
+	matches := 0
+	for _, sub := range schemas {
+		var sv validationState
+		v.validate(&sv, path, sub, val)
+		if len(sv.violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// resolve decodes raw into dst, following a local $ref if raw is a reference object.
+func (v *ParamValidator) resolve(raw json.RawMessage, dst any) error {
+	for depth := 0; ; depth++ {
+		var ref struct {
+			Ref string `json:"$ref"`
+		}
+		if json.Unmarshal(raw, &ref) != nil || ref.Ref == "" {
+			break
+		}
+		if depth >= 32 {
+			return errors.New("too many nested references")
+		}
+		target, err := v.lookupRef(ref.Ref)
+		if err != nil {
+			return err
+		}
+		raw = target
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		return json.Unmarshal(raw, dst)
+	}
+	return nil
+}
+
+// lookupRef resolves a reference of the form "#/components/<kind>/<name>".
+func (v *ParamValidator) lookupRef(ref string) (json.RawMessage, error) {
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	if !strings.HasPrefix(ref, "#/") || len(parts) != 3 || parts[0] != "components" {
+		return nil, fmt.Errorf("unsupported reference %q", ref)
+	}
+	raw, ok := v.components[parts[1]][parts[2]]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %q", ref)
+	}
+	return raw, nil
+}
+
+func (v *ParamValidator) compilePattern(pattern string) (*regexp.Regexp, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// decodeParams decodes call parameters into either positional or named values.
+func decodeParams(params json.RawMessage) ([]any, map[string]any, error) {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, null) {
+		return nil, nil, nil
+	}
+	var val any
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	if err := dec.Decode(&val); err != nil {
+		return nil, nil, err
+	}
+	switch val := val.(type) {
+	case []any:
+		return val, nil, nil
+	case map[string]any:
+		return nil, val, nil
+	default:
+		return nil, nil, errors.New("params must be an array or object")
+	}
+}
+
+func matchesType(val any, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if n, ok := val.(json.Number); ok {
+				if f, ok := new(big.Float).SetString(n.String()); ok && f.IsInt() {
+					return true
+				}
+			}
+		case jsonType(val):
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func equalJSON(raw json.RawMessage, val any) bool {
+	var want any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if dec.Decode(&want) != nil {
+		return false
+	}
+	a, _ := canonicalizeJSON(mustMarshal(want))
+	b, _ := canonicalizeJSON(mustMarshal(val))
+	return bytes.Equal(a, b)
+}
+
+func mustMarshal(v any) []byte {
+	enc, _ := json.Marshal(v)
+	return enc
+}
+
+func schemaInt(s map[string]json.RawMessage, key string) (int, bool) {
+	raw, ok := s[key]
+	if !ok {
+		return 0, false
+	}
+	var n int
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// paramValidation is the validator configuration of a server.
+type paramValidation struct {
+	validator *ParamValidator
+	methods   map[string]bool // nil means all methods of the document
+}
+
+func (pv *paramValidation) validate(method string, params json.RawMessage) error {
+	if pv.methods != nil && !pv.methods[method] {
+		return nil
+	}
+	return pv.validator.Validate(method, params)
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testOpenRPCDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "test", "version": "1.0"},
+	"methods": [
+		{
+			"name": "test_echo",
+			"params": [
+				{"name": "str", "required": true, "schema": {"type": "string", "pattern": "^[a-z]+$"}},
+				{"name": "int", "schema": {"type": "integer", "minimum": 0, "maximum": 100}},
+				{"$ref": "#/components/contentDescriptors/Args"}
+			],
+			"result": {"name": "result", "schema": {}}
+		},
+		{
+			"name": "proxy_getRange",
+			"params": [
+				{"name": "range", "required": true, "schema": {"$ref": "#/components/schemas/Range"}}
+			]
+		}
+	],
+	"components": {
+		"contentDescriptors": {
+			"Args": {"name": "args", "schema": {"type": ["object", "null"], "properties": {"S": {"type": "string"}}}}
+		},
+		"schemas": {
+			"Range": {
+				"type": "object",
+				"required": ["from"],
+				"properties": {
+					"from": {"oneOf": [{"type": "integer"}, {"enum": ["latest", "pending"]}]},
+					"to": {"type": "integer"},
+					"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+				},
+				"additionalProperties": false
+			}
+		}
+	}
+}`
+
+func TestParamValidatorValidate(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewParamValidator([]byte(testOpenRPCDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if methods := v.Methods(); !reflect.DeepEqual(methods, []string{"proxy_getRange", "test_echo"}) {
+		t.Fatalf("wrong methods: %v", methods)
+	}
+
+	tests := []struct {
+		method     string
+		params     string
+		violations []ParamViolation
+	}{
+		{method: "test_echo", params: `["abc", 5, {"S": "x"}]`},
+		{method: "test_echo", params: `["abc"]`},
+		{method: "test_echo", params: `{"str": "abc", "args": null}`},
+		{method: "other_method", params: `[1, 2, 3]`},
+		{method: "proxy_getRange", params: `[{"from": "latest", "to": 10, "tags": ["a"]}]`},
+		{
+			method:     "test_echo",
+			params:     `[]`,
+			violations: []ParamViolation{{"str", "missing required parameter"}},
+		},
+		{
+			method: "test_echo",
+			params: `["ABC", 1.5, {"S": 1}, 4]`,
+			violations: []ParamViolation{
+				{"params", "too many parameters, want at most 3"},
+				{"str", `string does not match pattern "^[a-z]+$"`},
+				{"int", "expected integer, got number"},
+				{"args.S", "expected string, got number"},
+			},
+		},
+		{
+			method:     "test_echo",
+			params:     `["abc", 101]`,
+			violations: []ParamViolation{{"int", "value must be <= 100"}},
+		},
+		{
+			method:     "test_echo",
+			params:     `{"str": "abc", "extra": 1}`,
+			violations: []ParamViolation{{"extra", "unknown parameter"}},
+		},
+		{
+			method: "proxy_getRange",
+			params: `[{"from": "earliest", "tags": ["a", "b", "c"], "until": 5}]`,
+			violations: []ParamViolation{
+				{"range.from", "value must match exactly one schema, matched 0"},
+				{"range.tags", "array must have at most 2 items"},
+				{"range.until", "value not allowed"},
+			},
+		},
+		{
+			method:     "proxy_getRange",
+			params:     `[{}]`,
+			violations: []ParamViolation{{"range.from", "missing required field"}},
+		},
+		{
+			method:     "proxy_getRange",
+			params:     `"x"`,
+			violations: []ParamViolation{{"params", "params must be an array or object"}},
+		},
+	}
+	for _, test := range tests {
+		err := v.Validate(test.method, json.RawMessage(test.params))
+		if test.violations == nil {
+			if err != nil {
+				t.Errorf("%s %s: unexpected error: %v", test.method, test.params, err)
+			}
+			continue
+		}
+		var verr *ParamValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s %s: expected validation error, got %v", test.method, test.params, err)
+			continue
+		}
+		if !reflect.DeepEqual(verr.Violations, test.violations) {
+			t.Errorf("%s %s: wrong violations\ngot:  %v\nwant: %v", test.method, test.params, verr.Violations, test.violations)
+		}
+	}
+}
+
+func TestServerParamValidator(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewParamValidator([]byte(testOpenRPCDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer()
+	defer server.Stop()
+	server.SetParamValidator(v, "test_echo")
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "abc", 1, &echoArgs{S: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	err = client.Call(&result, "test_echo", "ABC", 1, &echoArgs{S: "x"})
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32602 {
+		t.Fatalf("expected invalid params error, got %v", err)
+	}
+	var dataErr DataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("expected error data, got %v", err)
+	}
+	data, _ := json.Marshal(dataErr.ErrorData())
+	if want := `[{"message":"string does not match pattern \"^[a-z]+$\"","path":"str"}]`; string(data) != want {
+		t.Fatalf("wrong error data %s", data)
+	}
+
+	// Validation can be disabled again.
+	server.SetParamValidator(nil)
+	if err := client.Call(&result, "test_echo", "ABC", 1, &echoArgs{S: "x"}); err != nil {
+		t.Fatal(err)
+	}
+}
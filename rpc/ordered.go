@@ -0,0 +1,84 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "slices"
+
+// SetOrderedNamespaces makes calls to methods of the given namespaces execute strictly in
+// arrival order on each connection, one at a time. This is meant for services whose
+// semantics depend on the order of calls, such as nonce management. Calls to other
+// namespaces still run concurrently. A batch containing calls to an ordered namespace
+// is ordered as a whole. Calling SetOrderedNamespaces without arguments disables
+// ordering.
+//
+// Note that ordering applies to requests received on the same connection. For HTTP,
+// every request is a connection of its own.
+func (s *Server) SetOrderedNamespaces(namespaces ...string) {
+	var ordered map[string]bool
+	if len(namespaces) > 0 {
+		ordered = make(map[string]bool, len(namespaces))
+		for _, ns := range namespaces {
+			ordered[ns] = true
+		}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.ordered = ordered })
+}
+
+// orderedNamespaces returns the ordered namespaces called by msgs.
+func (r *registryConfig) orderedNamespaces(msgs []*jsonrpcMessage) []string {
+	if len(r.ordered) == 0 {
+		return nil
+	}
+	var namespaces []string
+	for _, msg := range msgs {
+		ns := msg.namespace()
+		if r.ordered[ns] && !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// startOrderedCallProc is like startCallProcFor, but fn starts only after all earlier calls
+// to the given namespaces have finished. It must be called in arrival order, i.e. from
+// the read loop of the connection.
+func (h *handler) startOrderedCallProc(calls []*jsonrpcMessage, namespaces []string, fn func(*callProc)) {
+	if len(namespaces) == 0 {
+		h.startCallProcFor(calls, fn)
+		return
+	}
+	if h.orderTail == nil {
+		h.orderTail = make(map[string]chan struct{})
+	}
+	var (
+		prev = make([]chan struct{}, 0, len(namespaces))
+		done = make(chan struct{})
+	)
+	for _, ns := range namespaces {
+		if tail := h.orderTail[ns]; tail != nil {
+			prev = append(prev, tail)
+		}
+		h.orderTail[ns] = done
+	}
+	h.startCallProcFor(calls, func(cp *callProc) {
+		defer close(done)
+		for _, ch := range prev {
+			<-ch
+		}
+		fn(cp)
+	})
+}
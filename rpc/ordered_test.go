@@ -0,0 +1,92 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// nonceService records the order in which calls are executed.
+type nonceService struct {
+	mu      sync.Mutex
+	order   []int
+	release chan struct{}
+}
+
+// Use records n after sleeping. Earlier calls sleep longer.
+func (s *nonceService) Use(n int) {
+	time.Sleep(time.Duration(10-n) * 2 * time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order = append(s.order, n)
+}
+
+func (s *nonceService) Used() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order
+}
+
+func (s *nonceService) Block() {
+	<-s.release
+}
+
+func TestOrderedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	service := &nonceService{release: make(chan struct{})}
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("nonce", service)
+	server.SetOrderedNamespaces("nonce")
+	client := DialInProc(server)
+	defer client.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if err := client.Notify(ctx, "nonce_use", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var used []int
+	if err := client.Call(&used, "nonce_used"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(used, want) {
+		t.Fatalf("wrong execution order %v", used)
+	}
+
+	// Calls to other namespaces are not held up by ordered calls.
+	blocked := make(chan error, 1)
+	go func() { blocked <- client.Call(nil, "nonce_block") }()
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-blocked:
+		t.Fatal("blocking call returned early")
+	default:
+	}
+	close(service.release)
+	if err := <-blocked; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const errMsgPanic = "method handler crashed"
+
+// PanicHandler is called when a method handler panics, see Server.SetPanicHandler. It
+// receives the value passed to panic, the stack trace of the crashed goroutine and the
+// incident id which is sent to the client in the error data.
+type PanicHandler func(method string, recovered any, stack []byte, incident string)
+
+// panicPolicy configures the handling of panics in method handlers. The zero value
+// recovers panics and logs them.
+type panicPolicy struct {
+	handler   PanicHandler
+	noRecover bool
+}
+
+// SetPanicHandler sets a function which is called when a method handler or middleware
+// panics, e.g. to report the crash to an error tracker. The call fails with error code
+// -32603. While a handler is set, the error data contains an incident id, which
+// identifies the crash in logs and reports. Without a handler, the error has no data.
+// The handler runs on the goroutine of the crashed call. Passing nil removes the
+// handler, panics are still logged.
+func (s *Server) SetPanicHandler(fn PanicHandler) {
+	s.services.updateConfig(func(c *registryConfig) { c.panics.handler = fn })
+}
+
+// SetPanicRecovery controls whether panics in method handlers are recovered, which is
+// the default. When recovery is disabled, a panicking method handler crashes the
+// process, which makes panics fail loudly in tests.
+func (s *Server) SetPanicRecovery(enabled bool) {
+	s.services.updateConfig(func(c *registryConfig) { c.panics.noRecover = !enabled })
+}
+
+// recovered handles a panic of a method handler and returns the error of the call.
+func (p *panicPolicy) recovered(method string, r any) error {
+	const size = 64 << 10
+	buf := make([]byte, size)
+	buf = buf[:runtime.Stack(buf, false)]
+	incident := newIncidentID()
+	log.Error("RPC method "+method+" crashed: "+fmt.Sprintf("%v\n%s", r, buf), "incident", incident)
+	if p.handler == nil {
+		return &panicError{}
+	}
+	p.handler(method, r, buf, incident)
+	return &panicError{incident: incident}
+}
+
+// run calls fn, handling a panic in fn according to the policy.
+func (p *panicPolicy) run(method string, fn func() *MethodResult) (result *MethodResult) {
+	if !p.noRecover {
+		defer func() {
+			if r := recover(); r != nil {
+				result = &MethodResult{Error: p.recovered(method, r)}
+			}
+		}()
+	}
+	return fn()
+}
+
+// newIncidentID creates a random incident id.
+func newIncidentID() string {
+	var b [8]byte
+	crand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// panicError is returned for calls whose method handler panicked. The incident id is
+// only set when a panic handler is installed.
+type panicError struct{ incident string }
+
+func (e *panicError) ErrorCode() int { return errcodePanic }
+
+func (e *panicError) Error() string { return errMsgPanic }
+
+func (e *panicError) ErrorData() interface{} {
+	if e.incident == "" {
+		return nil
+	}
+	return map[string]string{"incident": e.incident}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanicHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	type crash struct {
+		method, incident string
+		recovered        any
+		stack            []byte
+	}
+	crashes := make(chan crash, 1)
+	server.SetPanicHandler(func(method string, recovered any, stack []byte, incident string) {
+		crashes <- crash{method, incident, recovered, stack}
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "test_panic")
+	wantCallError(t, err, errcodePanic)
+	if err.Error() != errMsgPanic {
+		t.Fatalf("wrong error message %q", err)
+	}
+	c := <-crashes
+	if c.method != "test_panic" || c.recovered != "service panic" {
+		t.Fatalf("wrong crash report %q %v", c.method, c.recovered)
+	}
+	if !strings.Contains(string(c.stack), "(*testService).Panic") {
+		t.Fatalf("stack doesn't contain the method:\n%s", c.stack)
+	}
+	var dataErr DataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("error has no data: %v", err)
+	}
+	data, _ := dataErr.ErrorData().(map[string]interface{})
+	if data["incident"] != c.incident || c.incident == "" {
+		t.Fatalf("wrong incident in error data %v, want %q", dataErr.ErrorData(), c.incident)
+	}
+}
+
+func TestPanicInMiddleware(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	crashes := make(chan string, 1)
+	server.SetPanicHandler(func(method string, recovered any, stack []byte, incident string) {
+		crashes <- method
+	})
+	server.UseMiddleware("crash", func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+		panic("middleware panic")
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "test_echo", "x", 1)
+	wantCallError(t, err, errcodePanic)
+	if method := <-crashes; method != "test_echo" {
+		t.Fatalf("wrong method %q in crash report", method)
+	}
+
+	// Calls with a timeout run the middlewares on another goroutine.
+	server.SetMethodTimeout("test_echo", time.Minute)
+	err = client.Call(nil, "test_echo", "x", 1)
+	wantCallError(t, err, errcodePanic)
+	<-crashes
+}
+
+func TestPanicWithoutHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "test_panic")
+	wantCallError(t, err, errcodePanic)
+	var dataErr DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		t.Fatalf("error has data %v without panic handler", dataErr.ErrorData())
+	}
+}
+
+func TestPanicRecoveryDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetPanicRecovery(false)
+	callb := server.services.callback("test_panic")
+	policy := server.services.snapshot().panics
+
+	defer func() {
+		if r := recover(); r != "service panic" {
+			t.Fatalf("wrong panic %v", r)
+		}
+	}()
+	callb.callWithPolicy(context.Background(), "test_panic", []reflect.Value{}, policy)
+	t.Fatal("panic was recovered")
+}
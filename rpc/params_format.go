@@ -0,0 +1,277 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the params formatter.
+const (
+	maxLogItems       = 4   // arrays with more items are summarized
+	maxLogFields      = 8   // objects with more members are summarized
+	maxLogString      = 40  // longer strings are truncated
+	maxLogBlobPrefix  = 10  // number of hex characters shown of byte blobs
+	maxLogHash        = 66  // hex strings up to this length are not truncated
+	maxLogParamsDepth = 3   // deeper values are summarized
+	maxLogParamsSize  = 512 // output size limit
+)
+
+// FormatParams renders the parameters of a call to method concisely for logging. Byte
+// blobs are truncated and reported with their size, long arrays are summarized as
+// "[N items]", and deeply nested values are elided. The parameter types of the method's
+// callback are used to recognize byte blobs, so that hex strings which are not blobs
+// are shown as strings. Parameters of unknown methods are formatted without this
+// information.
+func (s *Server) FormatParams(method string, params json.RawMessage) string {
+	return s.services.formatParams(method, params)
+}
+
+// SetParamsLogging controls whether the params of failed calls are added to the log
+// message of the call, formatted by FormatParams. This is disabled by default, because
+// params can contain secrets such as signed transactions, passwords and API keys.
+func (s *Server) SetParamsLogging(enabled bool) {
+	s.services.updateConfig(func(c *registryConfig) { c.logParams = enabled })
+}
+
+// formatParams renders the params of a call to method, see Server.FormatParams.
+func (r *serviceRegistry) formatParams(method string, params json.RawMessage) string {
+	var argTypes []reflect.Type
+	if strings.HasSuffix(method, subscribeMethodSuffix) {
+		service := strings.TrimSuffix(method, subscribeMethodSuffix)
+		if name, err := parseSubscriptionName(params); err == nil {
+			if cb := r.subscription(service, name); cb != nil {
+				argTypes = append([]reflect.Type{stringType}, cb.argTypes...)
+			}
+		}
+	} else if cb := r.callback(method); cb != nil {
+		argTypes = cb.argTypes
+	}
+	return formatParamsWithTypes(params, argTypes)
+}
+
+// formatParamsWithTypes renders positional params. The types are used for the
+// corresponding params, further params are formatted without type information.
+func formatParamsWithTypes(params json.RawMessage, argTypes []reflect.Type) string {
+	f := paramsFormatter{buf: new(bytes.Buffer)}
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || params[0] != '[' {
+		f.value(params, nil, 0)
+	} else {
+		var args []json.RawMessage
+		if err := json.Unmarshal(params, &args); err != nil {
+			return "(invalid params)"
+		}
+		f.buf.WriteByte('[')
+		for i, arg := range args {
+			if i > 0 {
+				f.buf.WriteString(", ")
+			}
+			var t reflect.Type
+			if i < len(argTypes) {
+				t = argTypes[i]
+			}
+			f.value(arg, t, 1)
+		}
+		f.buf.WriteByte(']')
+	}
+	out := f.buf.String()
+	if len(out) > maxLogParamsSize {
+		cut := maxLogParamsSize
+		for cut > 0 && !utf8.RuneStart(out[cut]) {
+			cut--
+		}
+		out = out[:cut] + "… (truncated)"
+	}
+	return out
+}
+
+type paramsFormatter struct {
+	buf *bytes.Buffer
+}
+
+// value writes a JSON value. t is the Go type it decodes into, or nil if unknown.
+func (f *paramsFormatter) value(raw json.RawMessage, t reflect.Type, depth int) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		f.buf.WriteString("null")
+		return
+	}
+	switch raw[0] {
+	case '[':
+		f.array(raw, t, depth)
+	case '{':
+		f.object(raw, t, depth)
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			f.buf.WriteString("(invalid string)")
+			return
+		}
+		f.string(s, t)
+	default:
+		f.buf.Write(raw)
+	}
+}
+
+func (f *paramsFormatter) array(raw json.RawMessage, t reflect.Type, depth int) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		f.buf.WriteString("(invalid array)")
+		return
+	}
+	if len(items) > maxLogItems || (depth >= maxLogParamsDepth && len(items) > 0) {
+		fmt.Fprintf(f.buf, "[%d items]", len(items))
+		return
+	}
+	var elem reflect.Type
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elem = t.Elem()
+	}
+	f.buf.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			f.buf.WriteString(", ")
+		}
+		f.value(item, elem, depth+1)
+	}
+	f.buf.WriteByte(']')
+}
+
+func (f *paramsFormatter) object(raw json.RawMessage, t reflect.Type, depth int) {
+	var (
+		dec    = json.NewDecoder(bytes.NewReader(raw))
+		keys   []string
+		values []json.RawMessage
+	)
+	dec.Token() // '{'
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			f.buf.WriteString("(invalid object)")
+			return
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			f.buf.WriteString("(invalid object)")
+			return
+		}
+		keys = append(keys, key.(string))
+		values = append(values, v)
+	}
+	if len(keys) > maxLogFields || (depth >= maxLogParamsDepth && len(keys) > 0) {
+		fmt.Fprintf(f.buf, "{%d fields}", len(keys))
+		return
+	}
+	f.buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			f.buf.WriteString(", ")
+		}
+		f.buf.WriteString(key)
+		f.buf.WriteString(": ")
+		f.value(values[i], memberType(t, key), depth+1)
+	}
+	f.buf.WriteByte('}')
+}
+
+func (f *paramsFormatter) string(s string, t reflect.Type) {
+	hex := isHex(s)
+	switch {
+	case isBlobType(t) || (t == nil && hex && len(s) > maxLogHash):
+		if len(s) > 2+maxLogBlobPrefix {
+			fmt.Fprintf(f.buf, "%s… (%d bytes)", s[:2+maxLogBlobPrefix], (len(s)-2)/2)
+		} else {
+			f.buf.WriteString(s)
+		}
+	case hex && len(s) <= maxLogHash:
+		// Hashes, addresses and quantities are shown in full.
+		f.buf.WriteString(s)
+	case utf8.RuneCountInString(s) > maxLogString:
+		n := 0
+		for i := range s {
+			if n == maxLogString {
+				fmt.Fprintf(f.buf, "%s… (%d chars)", strconv.Quote(s[:i]), utf8.RuneCountInString(s))
+				return
+			}
+			n++
+		}
+	default:
+		f.buf.WriteString(strconv.Quote(s))
+	}
+}
+
+// isBlobType reports whether values of type t are encoded as variable-length hex
+// byte strings.
+func isBlobType(t reflect.Type) bool {
+	return t != nil && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// isHex reports whether s is a 0x-prefixed hex string.
+func isHex(s string) bool {
+	if len(s) < 3 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	for _, c := range []byte(s[2:]) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// memberType returns the type of the object member key in values of type t.
+func memberType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		var fold reflect.Type
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			if name == key {
+				return field.Type
+			}
+			if fold == nil && strings.EqualFold(name, key) {
+				fold = field.Type
+			}
+		}
+		return fold
+	}
+	return nil
+}
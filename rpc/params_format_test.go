@@ -0,0 +1,82 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type formatTestBytes []byte
+
+type formatTestTx struct {
+	To    string          `json:"to"`
+	Input formatTestBytes `json:"input"`
+}
+
+type formatTestService struct{}
+
+func (formatTestService) Send(tx formatTestTx, blocks []uint64, note string) {}
+
+func TestFormatParams(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("fmt", formatTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		addr  = "0x" + strings.Repeat("ab", 20)
+		hash  = "0x" + strings.Repeat("cd", 32)
+		input = "0x0123456789" + strings.Repeat("ef", 295)
+	)
+	tests := []struct {
+		method, params, want string
+	}{
+		// Typed params: the input blob is truncated, the long array summarized.
+		{
+			"fmt_send",
+			`[{"to":"` + addr + `","input":"` + input + `"},[1,2,3,4,5,6],"short"]`,
+			`[{to: ` + addr + `, input: 0x0123456789… (300 bytes)}, [6 items], "short"]`,
+		},
+		// A hex string which isn't a blob is shown as a string.
+		{
+			"fmt_send",
+			`[{"to":"` + input + `"},[1],"x"]`,
+			`[{to: "0x0123456789efefefefefefefefefefefefefef"… (602 chars)}, [1], "x"]`,
+		},
+		// Untyped params: hashes are kept, long hex is treated as a blob.
+		{
+			"unknown_method",
+			`["` + hash + `","` + input + `",{"a":{"b":{"c":[1]}}},null,true]`,
+			`[` + hash + `, 0x0123456789… (300 bytes), {a: {b: {1 fields}}}, null, true]`,
+		},
+		{"unknown_method", `{"a":1}`, `{a: 1}`},
+		{"unknown_method", `[1`, `(invalid params)`},
+	}
+	for _, test := range tests {
+		got := server.FormatParams(test.method, json.RawMessage(test.params))
+		if got != test.want {
+			t.Errorf("%s %s\n got: %s\nwant: %s", test.method, test.params, got, test.want)
+		}
+	}
+
+	long := "[" + strings.Repeat(`"`+hash+`",`, 3) + `"` + strings.Repeat("x", 1000) + `"]`
+	if got := server.FormatParams("unknown_method", json.RawMessage(long)); len(got) > maxLogParamsSize+len("… (truncated)") {
+		t.Errorf("output not limited: %d bytes", len(got))
+	}
+}
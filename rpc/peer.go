@@ -0,0 +1,105 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"sort"
+)
+
+// Peer is a connection served by the server using ServeCodec, e.g. a websocket or IPC
+// connection. Such connections are bidirectional: the server can call the methods which
+// the client has registered using Client.RegisterName, without the client having to run
+// a server of its own.
+//
+// A service can associate peers with its own state by recording ConnIDFromContext in a
+// method called by the client, then look up the peer using Server.Peer whenever it
+// wants to push a call to it.
+type Peer struct {
+	ID   ConnID
+	Info PeerInfo
+
+	client *Client
+}
+
+// PeerFromContext returns the connection on which the current call was received. It
+// can be used by methods and middleware to push notifications or calls to the client
+// outside of the subscription framework. It returns false for calls not received on a
+// connection served by ServeCodec, e.g. for HTTP requests.
+func PeerFromContext(ctx context.Context) (*Peer, bool) {
+	c, ok := ClientFromContext(ctx)
+	if !ok || c.connID == 0 {
+		return nil, false
+	}
+	return &Peer{ID: c.connID, Info: PeerInfoFromContext(ctx), client: c}, true
+}
+
+// CallContext performs a JSON-RPC call on the peer, see Client.CallContext. It fails
+// with ErrClientQuit once the connection has been closed.
+func (p *Peer) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return p.client.CallContext(ctx, result, method, args...)
+}
+
+// Notify sends a notification to the peer, i.e. a request without id which the peer
+// doesn't answer. This is a lightweight way to deliver events to a client which handles
+// them with a method registered using Client.RegisterName. Unlike subscription
+// notifications, they don't require the client to subscribe first.
+func (p *Peer) Notify(ctx context.Context, method string, args ...interface{}) error {
+	return p.client.Notify(ctx, method, args...)
+}
+
+// Client returns the client used to perform calls on the peer. It is the same client
+// which is available to methods served on the connection through ClientFromContext.
+// The client is closed by the server when the connection terminates.
+func (p *Peer) Client() *Client {
+	return p.client
+}
+
+// Peer returns the connection with the given id, if it is still open. HTTP requests are
+// not peers since they can't carry calls from the server to the client.
+func (s *Server) Peer(id ConnID) (*Peer, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p, ok := s.peers[id]
+	return p, ok
+}
+
+// Peers returns all open connections of the server, ordered by id.
+func (s *Server) Peers() []*Peer {
+	s.mutex.Lock()
+	peers := make([]*Peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		peers = append(peers, p)
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	return peers
+}
+
+func (s *Server) addPeer(p *Peer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.peers[p.ID] = p
+}
+
+func (s *Server) removePeer(id ConnID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.peers, id)
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type peerRegistryService struct {
+	registered chan ConnID
+}
+
+func (s *peerRegistryService) Register(ctx context.Context) {
+	s.registered <- ConnIDFromContext(ctx)
+}
+
+type pushService struct{}
+
+func (pushService) Greet(name string) string {
+	return "hello " + name
+}
+
+func TestServerPeerReverseCall(t *testing.T) {
+	t.Parallel()
+
+	var (
+		server   = newTestServer()
+		registry = &peerRegistryService{registered: make(chan ConnID, 1)}
+		httpsrv  = httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+		wsURL    = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer server.Stop()
+	defer httpsrv.Close()
+	if err := server.RegisterName("registry", registry); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RegisterName("push", pushService{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "registry_register"); err != nil {
+		t.Fatal(err)
+	}
+	id := <-registry.registered
+
+	peer, ok := server.Peer(id)
+	if !ok {
+		t.Fatalf("peer %d not found", id)
+	}
+	if peer.Info.Transport != "ws" {
+		t.Errorf("wrong peer transport %q", peer.Info.Transport)
+	}
+	if peers := server.Peers(); len(peers) != 1 || peers[0] != peer {
+		t.Errorf("wrong peers %v", peers)
+	}
+	var greeting string
+	if err := peer.CallContext(context.Background(), &greeting, "push_greet", "server"); err != nil {
+		t.Fatal(err)
+	}
+	if greeting != "hello server" {
+		t.Errorf("wrong result %q", greeting)
+	}
+
+	// The peer goes away with the connection.
+	client.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := server.Peer(id); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("peer not removed after connection closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	err = peer.CallContext(context.Background(), &greeting, "push_greet", "server")
+	if !errors.Is(err, ErrClientQuit) {
+		t.Errorf("wrong error after close: %v", err)
+	}
+}
+
+type peerNotifyService struct{}
+
+func (peerNotifyService) Ping(ctx context.Context, n int) error {
+	peer, ok := PeerFromContext(ctx)
+	if !ok {
+		return errors.New("no peer in context")
+	}
+	return peer.Notify(ctx, "events_pong", n+1)
+}
+
+type eventsService struct {
+	pongs chan int
+}
+
+func (s *eventsService) Pong(n int) {
+	s.pongs <- n
+}
+
+func TestPeerFromContextNotify(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("peer", peerNotifyService{}); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+	events := &eventsService{pongs: make(chan int, 1)}
+	if err := client.RegisterName("events", events); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Call(nil, "peer_ping", 41); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-events.pongs:
+		if n != 42 {
+			t.Fatalf("wrong notification value %d", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not received")
+	}
+
+	// HTTP requests don't belong to a peer.
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	hc, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hc.Close()
+	if err := hc.Call(nil, "peer_ping", 1); err == nil || !strings.Contains(err.Error(), "no peer") {
+		t.Fatalf("wrong error over HTTP: %v", err)
+	}
+}
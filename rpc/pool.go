@@ -0,0 +1,376 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrNoEndpoint is returned by PooledClient when no endpoint could be used.
+var ErrNoEndpoint = errors.New("no RPC endpoint available")
+
+// PoolStrategy selects the endpoint used by a PooledClient.
+type PoolStrategy int
+
+const (
+	// PoolRoundRobin spreads requests evenly over the healthy endpoints.
+	PoolRoundRobin PoolStrategy = iota
+	// PoolLowestLatency sends requests to the healthy endpoint which answered the
+	// health checks fastest.
+	PoolLowestLatency
+	// PoolPrimaryFallback sends requests to the first healthy endpoint, in the order
+	// given to NewPooledClient.
+	PoolPrimaryFallback
+)
+
+// PoolConfig configures a PooledClient.
+type PoolConfig struct {
+	Strategy PoolStrategy
+
+	HealthInterval time.Duration // time between health checks (default 10s)
+	HealthTimeout  time.Duration // timeout of a health check (default 5s)
+	HealthMethod   string        // method called to check an endpoint (default "rpc_modules")
+
+	// Options are applied to the client of every endpoint.
+	Options []ClientOption
+}
+
+const (
+	defaultPoolHealthInterval = 10 * time.Second
+	defaultPoolHealthTimeout  = 5 * time.Second
+
+	// poolLatencyWeight is the weight of a new sample in the latency average.
+	poolLatencyWeight = 0.3
+)
+
+// PooledClient routes RPC requests to one of several endpoints serving the same API.
+// Endpoints are checked periodically, and requests go to healthy endpoints only, as
+// long as there are any. When a call fails because its endpoint can't be reached, it is
+// repeated on the next endpoint. Note that this may execute the call twice if the
+// connection broke after the request was sent.
+//
+// Subscriptions are sticky: they stay on the endpoint they were created on, and end
+// when the connection to it is lost (unless resubscription is enabled on the endpoint
+// clients, see WithResubscribe).
+type PooledClient struct {
+	config    PoolConfig
+	endpoints []*poolEndpoint
+	next      atomic.Uint64 // round-robin counter
+
+	closeOnce sync.Once
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// poolEndpoint is an endpoint of a PooledClient.
+type poolEndpoint struct {
+	url     string
+	healthy atomic.Bool
+	latency atomic.Int64 // moving average of the health check duration in nanoseconds
+
+	mu     sync.Mutex
+	client *Client // nil while the endpoint can't be dialed
+}
+
+// NewPooledClient creates a client which routes requests to the given endpoints. The
+// endpoint URLs are dialed like in DialOptions. Endpoints which can't be dialed are
+// retried during the health checks. An error is returned if no endpoint can be dialed.
+func NewPooledClient(endpoints []string, config PoolConfig) (*PooledClient, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	if config.HealthInterval <= 0 {
+		config.HealthInterval = defaultPoolHealthInterval
+	}
+	if config.HealthTimeout <= 0 {
+		config.HealthTimeout = defaultPoolHealthTimeout
+	}
+	if config.HealthMethod == "" {
+		config.HealthMethod = "rpc_modules"
+	}
+
+	p := &PooledClient{config: config, quit: make(chan struct{})}
+	var (
+		wg      sync.WaitGroup
+		lastErr error
+		mu      sync.Mutex
+	)
+	for _, url := range endpoints {
+		ep := &poolEndpoint{url: url}
+		p.endpoints = append(p.endpoints, ep)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.check(ep); err != nil {
+				mu.Lock()
+				lastErr = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if !slices.ContainsFunc(p.endpoints, func(ep *poolEndpoint) bool { return ep.getClient() != nil }) {
+		return nil, lastErr
+	}
+
+	p.wg.Add(1)
+	go p.healthLoop()
+	return p, nil
+}
+
+// Close closes the clients of all endpoints.
+func (p *PooledClient) Close() {
+	p.closeOnce.Do(func() {
+		close(p.quit)
+		p.wg.Wait()
+		for _, ep := range p.endpoints {
+			if c := ep.getClient(); c != nil {
+				c.Close()
+			}
+		}
+	})
+}
+
+// Call performs a JSON-RPC call on one of the endpoints, see Client.Call.
+func (p *PooledClient) Call(result interface{}, method string, args ...interface{}) error {
+	return p.CallContext(context.Background(), result, method, args...)
+}
+
+// CallContext performs a JSON-RPC call on one of the endpoints, see Client.CallContext.
+func (p *PooledClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return p.route(ctx, func(c *Client) error {
+		return c.CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCall sends a batch to one of the endpoints, see Client.BatchCall.
+func (p *PooledClient) BatchCall(b []BatchElem) error {
+	return p.BatchCallContext(context.Background(), b)
+}
+
+// BatchCallContext sends a batch to one of the endpoints, see Client.BatchCallContext.
+// The batch is only repeated on another endpoint if none of its elements were sent.
+func (p *PooledClient) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	return p.route(ctx, func(c *Client) error {
+		err := c.BatchCallContext(ctx, b)
+		var berr *BatchError
+		if errors.As(err, &berr) && len(berr.Indeterminate) > 0 {
+			return &noFailoverError{err}
+		}
+		return err
+	})
+}
+
+// Notify sends a notification to one of the endpoints, see Client.Notify.
+func (p *PooledClient) Notify(ctx context.Context, method string, args ...interface{}) error {
+	return p.route(ctx, func(c *Client) error {
+		return c.Notify(ctx, method, args...)
+	})
+}
+
+// Subscribe creates a subscription on one of the endpoints, see Client.Subscribe. The
+// subscription stays on that endpoint.
+func (p *PooledClient) Subscribe(ctx context.Context, namespace string, channel interface{}, args ...interface{}) (*ClientSubscription, error) {
+	var sub *ClientSubscription
+	err := p.route(ctx, func(c *Client) (err error) {
+		sub, err = c.Subscribe(ctx, namespace, channel, args...)
+		return err
+	})
+	return sub, err
+}
+
+// EthSubscribe registers a subscription under the "eth" namespace on one of the
+// endpoints, see Client.EthSubscribe.
+func (p *PooledClient) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*ClientSubscription, error) {
+	return p.Subscribe(ctx, "eth", channel, args...)
+}
+
+// route runs fn with the clients of the endpoints in the order chosen by the strategy,
+// until it succeeds or fails with an error which is not caused by the endpoint being
+// unreachable.
+func (p *PooledClient) route(ctx context.Context, fn func(*Client) error) error {
+	err := ErrNoEndpoint
+	for _, ep := range p.candidates() {
+		c := ep.getClient()
+		if c == nil {
+			continue
+		}
+		if err = fn(c); !isFailoverError(err) {
+			var nf *noFailoverError
+			if errors.As(err, &nf) {
+				return nf.err
+			}
+			return err
+		}
+		log.Debug("RPC endpoint failed, trying next", "url", ep.url, "err", err)
+		ep.healthy.Store(false)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return err
+}
+
+// candidates returns the endpoints in the order in which they should be tried. Healthy
+// endpoints come first.
+func (p *PooledClient) candidates() []*poolEndpoint {
+	var healthy, unhealthy []*poolEndpoint
+	for _, ep := range p.endpoints {
+		if ep.healthy.Load() {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	switch p.config.Strategy {
+	case PoolRoundRobin:
+		if len(healthy) > 1 {
+			n := int(p.next.Add(1) % uint64(len(healthy)))
+			healthy = slices.Concat(healthy[n:], healthy[:n])
+		}
+	case PoolLowestLatency:
+		slices.SortStableFunc(healthy, func(a, b *poolEndpoint) int {
+			return int(a.latency.Load() - b.latency.Load())
+		})
+	}
+	return append(healthy, unhealthy...)
+}
+
+// healthLoop checks all endpoints periodically.
+func (p *PooledClient) healthLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			var wg sync.WaitGroup
+			for _, ep := range p.endpoints {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					p.check(ep)
+				}()
+			}
+			wg.Wait()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// check dials ep if necessary and calls the health method, updating the health and
+// latency of the endpoint.
+func (p *PooledClient) check(ep *poolEndpoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.HealthTimeout)
+	defer cancel()
+
+	c, err := ep.dial(ctx, p.config.Options)
+	if err != nil {
+		log.Debug("Could not dial RPC endpoint", "url", ep.url, "err", err)
+		ep.healthy.Store(false)
+		return err
+	}
+	start := time.Now()
+	var result interface{}
+	if err := c.CallContext(ctx, &result, p.config.HealthMethod); err != nil {
+		log.Debug("RPC endpoint health check failed", "url", ep.url, "err", err)
+		ep.healthy.Store(false)
+		return err
+	}
+	ep.observeLatency(time.Since(start))
+	ep.healthy.Store(true)
+	return nil
+}
+
+func (ep *poolEndpoint) getClient() *Client {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.client
+}
+
+// dial returns the client of ep, creating it if necessary.
+func (ep *poolEndpoint) dial(ctx context.Context, options []ClientOption) (*Client, error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if ep.client == nil {
+		c, err := DialOptions(ctx, ep.url, options...)
+		if err != nil {
+			return nil, err
+		}
+		ep.client = c
+	}
+	return ep.client, nil
+}
+
+// observeLatency adds a sample to the latency average.
+func (ep *poolEndpoint) observeLatency(d time.Duration) {
+	for {
+		old := ep.latency.Load()
+		avg := int64(d)
+		if old != 0 {
+			avg = int64(float64(old)*(1-poolLatencyWeight) + float64(d)*poolLatencyWeight)
+		}
+		if ep.latency.CompareAndSwap(old, avg) {
+			return
+		}
+	}
+}
+
+// noFailoverError wraps an error which must not cause the request to be repeated on
+// another endpoint.
+type noFailoverError struct{ err error }
+
+func (e *noFailoverError) Error() string { return e.err.Error() }
+
+func (e *noFailoverError) Unwrap() error { return e.err }
+
+// isFailoverError reports whether err means that the endpoint could not be reached, so
+// the request should be tried on another endpoint.
+func isFailoverError(err error) bool {
+	var (
+		nf      *noFailoverError
+		rpcErr  Error
+		httpErr HTTPError
+		netErr  net.Error
+	)
+	switch {
+	case err == nil, errors.As(err, &nf):
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &rpcErr):
+		return false
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode >= 500
+	case errors.As(err, &netErr):
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, errDead) || errors.Is(err, errClientReconnected) || errors.Is(err, ErrClientQuit)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type poolTestService struct{ name string }
+
+func (s *poolTestService) Name() string { return s.name }
+
+func poolCallNames(t *testing.T, p *PooledClient, n int) []string {
+	t.Helper()
+	var names []string
+	for i := 0; i < n; i++ {
+		var name string
+		if err := p.Call(&name, "pool_name"); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestPooledClientRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	var urls []string
+	for _, name := range []string{"a", "b"} {
+		server := newTestServer()
+		defer server.Stop()
+		server.RegisterName("pool", &poolTestService{name})
+		httpsrv := httptest.NewServer(server)
+		defer httpsrv.Close()
+		urls = append(urls, httpsrv.URL)
+	}
+	p, err := NewPooledClient(urls, PoolConfig{Strategy: PoolRoundRobin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	count := make(map[string]int)
+	for _, name := range poolCallNames(t, p, 10) {
+		count[name]++
+	}
+	if count["a"] != 5 || count["b"] != 5 {
+		t.Fatalf("calls not distributed evenly: %v", count)
+	}
+}
+
+func TestPooledClientFailover(t *testing.T) {
+	t.Parallel()
+
+	var (
+		servers []*httptest.Server
+		urls    []string
+	)
+	for _, name := range []string{"primary", "fallback"} {
+		server := newTestServer()
+		defer server.Stop()
+		server.RegisterName("pool", &poolTestService{name})
+		httpsrv := httptest.NewServer(server)
+		defer httpsrv.Close()
+		servers = append(servers, httpsrv)
+		urls = append(urls, httpsrv.URL)
+	}
+	p, err := NewPooledClient(urls, PoolConfig{Strategy: PoolPrimaryFallback})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	for _, name := range poolCallNames(t, p, 3) {
+		if name != "primary" {
+			t.Fatalf("call went to %q with healthy primary", name)
+		}
+	}
+	// Calls fail over when the primary goes away.
+	servers[0].Close()
+	for _, name := range poolCallNames(t, p, 3) {
+		if name != "fallback" {
+			t.Fatalf("call went to %q with primary down", name)
+		}
+	}
+	if p.endpoints[0].healthy.Load() {
+		t.Fatal("failed endpoint still marked healthy")
+	}
+}
+
+func TestPooledClientHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	var (
+		servers []*httptest.Server
+		urls    []string
+	)
+	for _, name := range []string{"a", "b"} {
+		server := newTestServer()
+		defer server.Stop()
+		server.RegisterName("pool", &poolTestService{name})
+		httpsrv := httptest.NewServer(server)
+		defer httpsrv.Close()
+		servers = append(servers, httpsrv)
+		urls = append(urls, httpsrv.URL)
+	}
+	urls = append(urls, "http://127.0.0.1:0") // can't be reached
+	p, err := NewPooledClient(urls, PoolConfig{HealthInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if !p.endpoints[0].healthy.Load() || !p.endpoints[1].healthy.Load() || p.endpoints[2].healthy.Load() {
+		t.Fatal("wrong initial endpoint health")
+	}
+	servers[1].Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.endpoints[1].healthy.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("endpoint not marked unhealthy")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, name := range poolCallNames(t, p, 3) {
+		if name != "a" {
+			t.Fatalf("call went to unhealthy endpoint %q", name)
+		}
+	}
+}
+
+func TestPooledClientLowestLatency(t *testing.T) {
+	t.Parallel()
+
+	var urls []string
+	for _, name := range []string{"a", "b"} {
+		server := newTestServer()
+		defer server.Stop()
+		server.RegisterName("pool", &poolTestService{name})
+		httpsrv := httptest.NewServer(server)
+		defer httpsrv.Close()
+		urls = append(urls, httpsrv.URL)
+	}
+	p, err := NewPooledClient(urls, PoolConfig{Strategy: PoolLowestLatency})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	p.endpoints[0].latency.Store(int64(50 * time.Millisecond))
+	p.endpoints[1].latency.Store(int64(time.Millisecond))
+	for _, name := range poolCallNames(t, p, 3) {
+		if name != "b" {
+			t.Fatalf("call went to slower endpoint %q", name)
+		}
+	}
+}
+
+func TestPooledClientStickySubscription(t *testing.T) {
+	t.Parallel()
+
+	var urls []string
+	for range 2 {
+		server := newTestServer()
+		httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+		defer httpsrv.Close()
+		defer server.Stop()
+		urls = append(urls, "ws"+httpsrv.URL[len("http"):])
+	}
+	p, err := NewPooledClient(urls, PoolConfig{Strategy: PoolRoundRobin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ch := make(chan int, 10)
+	sub, err := p.Subscribe(context.Background(), "nftest", ch, "someSubscription", 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	// Calls are spread over the endpoints, notifications keep coming from one.
+	for i := range 3 {
+		if err := p.Call(nil, "test_echo", "x", i, nil); err != nil {
+			t.Fatal(err)
+		}
+		if v := <-ch; v != 1+i {
+			t.Fatalf("wrong notification %d", v)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+)
+
+// PreDecodeMiddleware wraps the processing of a method call before its parameters are
+// decoded. It receives the parameters as sent by the client and may inspect or replace
+// them before calling next. Like a Middleware, it can answer the call without calling
+// next, e.g. to reject it or to serve a cached value. This avoids the cost of decoding
+// parameters for calls which are not executed anyway.
+//
+// When next is called, the parameters are decoded and the method is run, including the
+// middlewares set by SetMiddlewares. The result returned by next holds the encoded
+// result as a json.RawMessage. PreDecodeMiddlewares must not return a nil result.
+//
+// Pre-decode middlewares run for calls of service methods. They don't run for
+// subscribe and unsubscribe calls and the built-in rpc_ methods.
+type PreDecodeMiddleware func(ctx context.Context, method string, params json.RawMessage, next func(ctx context.Context, method string, params json.RawMessage) *MethodResult) *MethodResult
+
+// SetPreDecodeMiddlewares sets the middlewares which run before the parameters of a call
+// are decoded. The first middleware is the outermost one.
+func (s *Server) SetPreDecodeMiddlewares(middlewares []PreDecodeMiddleware) {
+	middlewares = slices.Clone(middlewares)
+	s.services.updateConfig(func(c *registryConfig) { c.preDecode = middlewares })
+}
+
+// runPreDecode runs the pre-decode middlewares around the processing of a call.
+func (h *handler) runPreDecode(cp *callProc, msg *jsonrpcMessage, class string, middlewares []PreDecodeMiddleware) *jsonrpcMessage {
+	var (
+		answer       *jsonrpcMessage
+		answerResult *MethodResult
+	)
+	next := func(ctx context.Context, method string, params json.RawMessage) *MethodResult {
+		call := *msg
+		call.Params = params
+		answer = h.callMethod(cp.derive(ctx), &call, class)
+		answerResult = &MethodResult{Result: answer.Result}
+		if answer.Error != nil {
+			answerResult.Error = answer.Error
+		}
+		return answerResult
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware := middlewares[i]
+		nextFunc := next
+		next = func(ctx context.Context, method string, params json.RawMessage) *MethodResult {
+			return middleware(ctx, method, params, nextFunc)
+		}
+	}
+	result := next(cp.ctx, msg.Method, msg.Params)
+	switch {
+	case result == nil:
+		return msg.errorResponse(&internalServerError{errcodeDefault, "middleware returned no result"})
+	case result == answerResult:
+		// The answer of the method was passed through unchanged.
+		return answer
+	case result.Error != nil:
+		return msg.errorResponse(result.Error)
+	default:
+		return h.encodeResult(cp.ctx, cp.cfg, msg, result.Result)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+var decodedArgs atomic.Int32
+
+// countedArg counts how often it is decoded.
+type countedArg string
+
+func (a *countedArg) UnmarshalJSON(input []byte) error {
+	decodedArgs.Add(1)
+	return json.Unmarshal(input, (*string)(a))
+}
+
+type preDecodeService struct{}
+
+func (preDecodeService) Echo(a countedArg) string { return string(a) }
+
+func TestPreDecodeMiddleware(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	server.RegisterName("pre", preDecodeService{})
+
+	var order []string
+	errRejected := errors.New("rejected")
+	server.SetPreDecodeMiddlewares([]PreDecodeMiddleware{
+		func(ctx context.Context, method string, params json.RawMessage, next func(context.Context, string, json.RawMessage) *MethodResult) *MethodResult {
+			order = append(order, "outer")
+			switch {
+			case bytes.Contains(params, []byte(`"reject"`)):
+				return &MethodResult{Error: errRejected}
+			case bytes.Contains(params, []byte(`"cached"`)):
+				return &MethodResult{Result: "from cache"}
+			case bytes.Contains(params, []byte(`"rewrite"`)):
+				return next(ctx, method, json.RawMessage(`["rewritten"]`))
+			case bytes.Contains(params, []byte(`"wrap"`)):
+				res := next(ctx, method, params)
+				return &MethodResult{Result: "<" + string(res.Result.(json.RawMessage)) + ">"}
+			}
+			return next(ctx, method, params)
+		},
+		func(ctx context.Context, method string, params json.RawMessage, next func(context.Context, string, json.RawMessage) *MethodResult) *MethodResult {
+			order = append(order, "inner")
+			return next(ctx, method, params)
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	tests := []struct {
+		arg, want string
+		decoded   int32
+	}{
+		{"plain", "plain", 1},
+		{"cached", "from cache", 0},
+		{"rewrite", "rewritten", 1},
+		{"wrap", `<"wrap">`, 1},
+	}
+	for _, test := range tests {
+		decodedArgs.Store(0)
+		var result string
+		if err := client.Call(&result, "pre_echo", test.arg); err != nil {
+			t.Fatalf("%s: %v", test.arg, err)
+		}
+		if result != test.want {
+			t.Errorf("%s: got %q, want %q", test.arg, result, test.want)
+		}
+		if n := decodedArgs.Load(); n != test.decoded {
+			t.Errorf("%s: argument decoded %d times, want %d", test.arg, n, test.decoded)
+		}
+	}
+
+	decodedArgs.Store(0)
+	if err := client.Call(nil, "pre_echo", "reject"); err == nil || err.Error() != errRejected.Error() {
+		t.Fatalf("wrong error for rejected call: %v", err)
+	}
+	if n := decodedArgs.Load(); n != 0 {
+		t.Fatalf("rejected call decoded its argument")
+	}
+
+	order = nil
+	client.Call(nil, "pre_echo", "x")
+	if !slices.Equal(order, []string{"outer", "inner"}) {
+		t.Fatalf("wrong middleware order %v", order)
+	}
+}
+
+func TestPreDecodeMiddlewareNilResult(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	server.RegisterName("pre", preDecodeService{})
+	server.SetPreDecodeMiddlewares([]PreDecodeMiddleware{
+		func(context.Context, string, json.RawMessage, func(context.Context, string, json.RawMessage) *MethodResult) *MethodResult {
+			return nil
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	wantCallError(t, client.Call(nil, "pre_echo", "x"), errcodeDefault)
+}
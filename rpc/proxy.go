@@ -0,0 +1,198 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errProxyParams = errors.New("proxied calls require positional parameters")
+
+// RegisterProxy forwards calls in namespace which are not handled locally to upstream.
+// This lets the server act as a proxy in front of another endpoint, e.g. a full node,
+// serving some methods of the namespace itself and passing all others through. Local
+// services and factories registered for the namespace take precedence.
+//
+// Parameters and results are forwarded as JSON without decoding and encoding them
+// again. Parameters are sent upstream as received, so interceptors of upstream see them
+// as a single argument holding their encoding. Results are copied from the upstream
+// response into the response to the client. Messages are still written by the codec of
+// each connection, rather than splicing bytes from one connection to the other, because
+// the request id and message extensions differ on both sides. Errors returned by
+// upstream keep their code and data.
+//
+// Subscriptions are forwarded as well if upstream supports them: the server subscribes
+// upstream and relays the notifications until the client unsubscribes. If the upstream
+// subscription fails, the local one ends.
+func (s *Server) RegisterProxy(namespace string, upstream *Client) error {
+	if namespace == "" || strings.Contains(namespace, serviceMethodSeparator) {
+		return fmt.Errorf("invalid proxy namespace %q", namespace)
+	}
+	r := &s.services
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.proxies[namespace] != nil {
+		return fmt.Errorf("proxy for %s is already registered", namespace)
+	}
+	if r.proxies == nil {
+		r.proxies = make(map[string]*upstreamProxy)
+	}
+	r.proxies[namespace] = &upstreamProxy{namespace: namespace, client: upstream}
+	return nil
+}
+
+// upstreamProxy forwards the calls of a namespace to an upstream endpoint.
+type upstreamProxy struct {
+	namespace string
+	client    *Client
+}
+
+// proxy returns the proxy of the given namespace.
+func (r *serviceRegistry) proxy(namespace string) *upstreamProxy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.proxies[namespace]
+}
+
+// proxyMethod returns a callback forwarding method upstream, or nil if its namespace
+// isn't proxied.
+func (r *serviceRegistry) proxyMethod(method string) *callback {
+	namespace, _, found := strings.Cut(method, serviceMethodSeparator)
+	if !found {
+		return nil
+	}
+	p := r.proxy(namespace)
+	if p == nil {
+		return nil
+	}
+	return &callback{errPos: -1, forward: true, raw: func(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+		return p.call(ctx, method, params)
+	}}
+}
+
+// proxySubscription returns a callback forwarding subscriptions of namespace upstream,
+// or nil if namespace isn't proxied.
+func (r *serviceRegistry) proxySubscription(namespace string) *callback {
+	p := r.proxy(namespace)
+	if p == nil {
+		return nil
+	}
+	return &callback{errPos: -1, isSubscribe: true, raw: p.subscribe}
+}
+
+// call forwards a method call.
+func (p *upstreamProxy) call(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, error) {
+	args, err := proxyParams(params)
+	if err != nil {
+		return nil, err
+	}
+	var result json.RawMessage
+	if err := p.client.CallContext(ctx, &result, method, args); err != nil {
+		return nil, proxyError(err)
+	}
+	return result, nil
+}
+
+// subscribe creates an upstream subscription, and a local one relaying its
+// notifications. params includes the subscription name.
+func (p *upstreamProxy) subscribe(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+	n, ok := NotifierFromContext(ctx)
+	if !ok {
+		return nil, ErrNotificationsUnsupported
+	}
+	args, err := proxyParams(params)
+	if err != nil {
+		return nil, err
+	}
+	// The local subscription can only be created once upstream has accepted the
+	// subscription, so notifications wait for it.
+	var (
+		sub   *Subscription
+		ready = make(chan struct{})
+	)
+	relay := func(ctx context.Context, raw json.RawMessage) error {
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil
+		}
+		if err := n.Notify(sub.ID, raw); err != nil && err != ErrSubscriptionClosed {
+			return err
+		}
+		return nil
+	}
+	up, err := p.client.SubscribeWithHandler(ctx, p.namespace, relay, args)
+	if err != nil {
+		return nil, proxyError(err)
+	}
+	sub = n.CreateSubscription()
+	close(ready)
+	n.OnClose(func(error) { up.Unsubscribe() })
+	go func() {
+		if err := <-up.Err(); err != nil {
+			n.end(err)
+		}
+	}()
+	return json.Marshal(sub.ID)
+}
+
+// forwardedParams are the parameters of a proxied call or subscription. The client sends
+// them as they are instead of encoding the arguments.
+type forwardedParams json.RawMessage
+
+// MarshalJSON implements json.Marshaler.
+func (p forwardedParams) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(p).MarshalJSON()
+}
+
+// forwardedJSON is a result received from upstream. It was validated when the upstream
+// response was decoded, so the response uses it as is instead of encoding it again.
+type forwardedJSON json.RawMessage
+
+// MarshalJSON implements json.Marshaler.
+func (f forwardedJSON) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(f).MarshalJSON()
+}
+
+// proxyParams checks that params are positional, so they can be forwarded as they are.
+func proxyParams(params json.RawMessage) (forwardedParams, error) {
+	trimmed := bytes.TrimLeft(params, " \t\r\n")
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+	if trimmed[0] != '[' {
+		return nil, &invalidParamsError{errProxyParams.Error()}
+	}
+	return forwardedParams(params), nil
+}
+
+// proxyError converts a failure of an upstream call. Errors returned by upstream are
+// passed through, other failures are reported without their details.
+func proxyError(err error) error {
+	if _, ok := err.(Error); ok {
+		return err
+	}
+	if errors.Is(err, ErrNotificationsUnsupported) {
+		return ErrNotificationsUnsupported
+	}
+	return &internalServerError{errcodeDefault, "upstream unavailable"}
+}
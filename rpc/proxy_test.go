@@ -0,0 +1,212 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type proxyFrontService struct{}
+
+func (proxyFrontService) Local() string { return "local" }
+
+func TestProxyCalls(t *testing.T) {
+	backend := newTestServer()
+	defer backend.Stop()
+	upstream := DialInProc(backend)
+	defer upstream.Close()
+
+	front := NewServer()
+	defer front.Stop()
+	front.RegisterName("test", proxyFrontService{})
+	if err := front.RegisterProxy("test", upstream); err != nil {
+		t.Fatal(err)
+	}
+	if err := front.RegisterProxy("nftest", upstream); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(front)
+	defer client.Close()
+
+	var s string
+	if err := client.Call(&s, "test_local"); err != nil || s != "local" {
+		t.Fatalf("local method: %q, %v", s, err)
+	}
+	if err := client.Call(&s, "test_repeat", "a", 3); err != nil || s != "aaa" {
+		t.Fatalf("proxied method: %q, %v", s, err)
+	}
+	var echo echoResult
+	if err := client.Call(&echo, "test_echo", "x", 1, &echoArgs{S: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (echoResult{"x", 1, &echoArgs{"y"}}); !reflect.DeepEqual(echo, want) {
+		t.Fatalf("wrong proxied result %+v", echo)
+	}
+
+	// Upstream errors keep their code and data.
+	err := client.Call(nil, "test_returnError")
+	var de DataError
+	if !errors.As(err, &de) || de.ErrorData() != "testError data" {
+		t.Fatalf("wrong proxied error %v", err)
+	}
+	wantCallError(t, err, 444)
+
+	wantCallError(t, client.Call(nil, "other_method"), -32601)
+	modules, err := client.SupportedModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modules["nftest"] != "1.0" {
+		t.Fatalf("proxied namespace missing from modules %v", modules)
+	}
+
+	upstream.Close()
+	if err := client.Call(&s, "test_repeat", "a", 3); err == nil || err.Error() != "upstream unavailable" {
+		t.Fatalf("wrong error with upstream down: %v", err)
+	}
+}
+
+func TestProxyParams(t *testing.T) {
+	params := json.RawMessage(` [1,"a",{"b":null}]`)
+	fwd, err := proxyParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fwd, params) {
+		t.Fatalf("params modified: %s", fwd)
+	}
+	for _, params := range []json.RawMessage{nil, json.RawMessage(`null`)} {
+		if fwd, err := proxyParams(params); err != nil || fwd != nil {
+			t.Fatalf("wrong params for %q: %s, %v", params, fwd, err)
+		}
+	}
+	var rpcErr Error
+	if _, err := proxyParams(json.RawMessage(`{"msg":"a"}`)); !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32602 {
+		t.Fatalf("wrong error for object params: %v", err)
+	}
+}
+
+// TestProxyForwarding checks that parameters and results pass through the proxy
+// byte for byte.
+func TestProxyForwarding(t *testing.T) {
+	backend := newTestServer()
+	defer backend.Stop()
+	var got json.RawMessage
+	backend.RegisterRawHandler("raw_echo", func(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+		got = params
+		return json.RawMessage(`{"z":1, "a":[ 1.50 ]}`), nil
+	})
+	upstream := DialInProc(backend)
+	defer upstream.Close()
+
+	front := NewServer()
+	defer front.Stop()
+	if err := front.RegisterProxy("raw", upstream); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(front)
+	defer client.Close()
+
+	var result json.RawMessage
+	if err := client.Call(&result, "raw_echo", json.RawMessage(`{"y":2.0,"b":1e3}`)); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `[{"y":2.0,"b":1e3}]` {
+		t.Errorf("wrong upstream params %s", got)
+	}
+	if string(result) != `{"z":1,"a":[1.50]}` {
+		t.Errorf("wrong result %s", result)
+	}
+}
+
+func TestProxySubscription(t *testing.T) {
+	backend := newTestServer()
+	defer backend.Stop()
+	nfservice := &notificationTestService{unsubscribed: make(chan string, 1)}
+	backend.RegisterName("nftest", nfservice)
+	upstream := DialInProc(backend)
+	defer upstream.Close()
+
+	front := NewServer()
+	defer front.Stop()
+	if err := front.RegisterProxy("nftest", upstream); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(front)
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 3, 10)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case v := <-ch:
+			if v != 10+i {
+				t.Fatalf("wrong notification %d", v)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for proxied notification")
+		}
+	}
+	sub.Unsubscribe()
+	select {
+	case <-nfservice.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream subscription not ended")
+	}
+}
+
+func TestProxySubscriptionUpstreamFailure(t *testing.T) {
+	backend := newTestServer()
+	defer backend.Stop()
+	upstream := DialInProc(backend)
+	defer upstream.Close()
+
+	front := NewServer()
+	defer front.Stop()
+	if err := front.RegisterProxy("nftest", upstream); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(front)
+	defer client.Close()
+
+	sub, err := client.Subscribe(context.Background(), "nftest", make(chan int, 10), "someSubscription", 1, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	upstream.Close()
+	// The local subscription is gone on the server.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var ok bool
+		if err := client.Call(&ok, "nftest_unsubscribe", sub.id()); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("local subscription not ended")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
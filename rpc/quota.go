@@ -0,0 +1,75 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "time"
+
+// Quota limits the number of calls a client can make within a time window.
+type Quota struct {
+	Limit  int64         // number of calls allowed per window
+	Window time.Duration // length of the window
+
+	// Key returns the key under which calls are counted, or "" to exempt the call
+	// from the quota. By default, calls are counted per remote IP address, and calls
+	// from transports without IP address, such as IPC, are not limited.
+	Key func(peer PeerInfo, method string) string
+}
+
+// QuotaFilter returns a method filter which enforces a quota. The counters are kept in
+// state, so they can outlive the server and be shared between servers when state is
+// backed by a shared store. Calls over the quota are rejected with a retryable error.
+// If the state fails, calls are allowed.
+//
+// Use the filter with Server.SetMethodFilter.
+func QuotaFilter(state State, quota Quota) MethodFilter {
+	key := quota.Key
+	if key == nil {
+		key = quotaKeyByIP
+	}
+	return func(peer PeerInfo, method string) error {
+		k := key(peer, method)
+		if k == "" {
+			return nil
+		}
+		n, err := state.Add("quota/"+k, 1, quota.Window)
+		if err != nil || n <= quota.Limit {
+			return nil
+		}
+		return &quotaExceededError{limit: quota.Limit, window: quota.Window}
+	}
+}
+
+func quotaKeyByIP(peer PeerInfo, method string) string {
+	if ip, ok := peer.RemoteIP(); ok {
+		return ip.String()
+	}
+	return ""
+}
+
+// quotaExceededError is returned for calls over a quota.
+type quotaExceededError struct {
+	limit  int64
+	window time.Duration
+}
+
+func (e *quotaExceededError) ErrorCode() int { return errcodeQuotaExceeded }
+
+func (e *quotaExceededError) Error() string { return errMsgQuotaExceeded }
+
+func (e *quotaExceededError) ErrorData() interface{} {
+	return map[string]interface{}{"limit": e.limit, "window": e.window.String()}
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuotaFilter(t *testing.T) {
+	t.Parallel()
+
+	state := NewMemoryState()
+	quota := Quota{Limit: 2, Window: time.Hour}
+
+	// The counters are shared by servers using the same state.
+	var clients []*Client
+	for range 2 {
+		srv := newTestServer()
+		defer srv.Stop()
+		srv.SetMethodFilter(QuotaFilter(state, quota))
+		httpsrv := httptest.NewServer(srv)
+		defer httpsrv.Close()
+		client, err := DialHTTP(httpsrv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		clients = append(clients, client)
+	}
+	if err := clients[0].Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := clients[1].Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	err := clients[0].Call(nil, "test_echo", "x", 1)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeQuotaExceeded {
+		t.Fatalf("expected quota error, got %v", err)
+	}
+	if v, _ := state.Get("quota/127.0.0.1"); v != 3 {
+		t.Fatalf("wrong counter value %d", v)
+	}
+
+	// Calls without key are not limited.
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetMethodFilter(QuotaFilter(state, quota))
+	client := DialInProc(srv)
+	defer client.Close()
+	for range 3 {
+		if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
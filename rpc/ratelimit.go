@@ -0,0 +1,289 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// rateLimitSweepInterval is the interval at which idle per-peer buckets are removed.
+const rateLimitSweepInterval = time.Minute
+
+var (
+	rateLimitAllowedCounter = metrics.NewRegisteredCounter("rpc/ratelimit/allowed", nil)
+	rateLimitLimitedCounter = metrics.NewRegisteredCounter("rpc/ratelimit/limited", nil)
+)
+
+// RateLimit configures a token bucket. Tokens are added at Rate per second, up to
+// Burst. Every call takes one token. A Rate of zero means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int // defaults to Rate, rounded up
+}
+
+func (l RateLimit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return max(1, math.Ceil(l.Rate))
+}
+
+// RateLimitConfig configures a RateLimiter. Calls must pass all applicable limits.
+type RateLimitConfig struct {
+	// Methods limits calls of individual methods across all clients.
+	Methods map[string]RateLimit
+
+	// Peer limits the calls of each client across all methods.
+	Peer RateLimit
+
+	// PeerMethods limits calls of individual methods by each client.
+	PeerMethods map[string]RateLimit
+
+	// PeerKey identifies the client making a call, or returns "" to exempt the call
+	// from the per-client limits. By default, clients are identified by remote IP
+	// address, and clients on transports without IP address, such as IPC, are not
+	// limited.
+	PeerKey func(peer PeerInfo) string
+
+	// ErrorCode is the JSON-RPC error code returned for calls over the limit. It
+	// defaults to -32009.
+	ErrorCode int
+
+	// Clock is used to refill the buckets. It defaults to the system clock.
+	Clock mclock.Clock
+}
+
+// RateLimitStats contains the counters of a RateLimiter.
+type RateLimitStats struct {
+	Allowed uint64            // number of calls allowed
+	DryRun  uint64            // number of allowed calls which were dry runs
+	Limited uint64            // number of calls rejected
+	Methods map[string]uint64 // number of calls rejected, by method
+}
+
+// RateLimiter enforces per-method and per-client call rates using token buckets.
+// Install it on a server using its Middleware:
+//
+//	limiter := rpc.NewRateLimiter(rpc.RateLimitConfig{
+//		Methods: map[string]rpc.RateLimit{"eth_call": {Rate: 100}},
+//		Peer:    rpc.RateLimit{Rate: 20, Burst: 50},
+//	})
+//	server.SetMiddlewares([]rpc.Middleware{limiter.Middleware()})
+//
+// Calls over the limit are rejected with a retryable error. Its data contains the time
+// until the call would be allowed, in milliseconds.
+type RateLimiter struct {
+	config RateLimitConfig
+	clock  mclock.Clock
+
+	mu        sync.Mutex
+	methods   map[string]*tokenBucket
+	peers     map[string]*tokenBucket
+	peerCalls map[rateLimitKey]*tokenBucket
+	lastSweep mclock.AbsTime
+
+	allowed  atomic.Uint64
+	dryRun   atomic.Uint64
+	limited  atomic.Uint64
+	byMethod sync.Map // method -> *atomic.Uint64
+}
+
+type rateLimitKey struct {
+	peer, method string
+}
+
+// NewRateLimiter creates a rate limiter.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.PeerKey == nil {
+		config.PeerKey = rateLimitKeyByIP
+	}
+	if config.ErrorCode == 0 {
+		config.ErrorCode = errcodeRateLimited
+	}
+	clock := config.Clock
+	if clock == nil {
+		clock = mclock.System{}
+	}
+	return &RateLimiter{
+		config:    config,
+		clock:     clock,
+		methods:   make(map[string]*tokenBucket),
+		peers:     make(map[string]*tokenBucket),
+		peerCalls: make(map[rateLimitKey]*tokenBucket),
+		lastSweep: clock.Now(),
+	}
+}
+
+// Middleware returns a server middleware which applies the limits.
+func (rl *RateLimiter) Middleware() Middleware {
+	return func(ctx context.Context, method string, args []reflect.Value, next func(ctx context.Context, method string, args []reflect.Value) *MethodResult) *MethodResult {
+		if err := rl.Allow(PeerInfoFromContext(ctx), method); err != nil {
+			return &MethodResult{Error: err}
+		}
+		if IsDryRun(ctx) {
+			rl.dryRun.Add(1)
+		}
+		return next(ctx, method, args)
+	}
+}
+
+// Allow takes a token for a call of method by peer. It returns an error if the call
+// exceeds a limit.
+func (rl *RateLimiter) Allow(peer PeerInfo, method string) error {
+	if wait, ok := rl.take(peer, method); !ok {
+		rl.limited.Add(1)
+		rateLimitLimitedCounter.Inc(1)
+		counter, _ := rl.byMethod.LoadOrStore(method, new(atomic.Uint64))
+		counter.(*atomic.Uint64).Add(1)
+		return &rateLimitedError{code: rl.config.ErrorCode, retryAfter: wait}
+	}
+	rl.allowed.Add(1)
+	rateLimitAllowedCounter.Inc(1)
+	return nil
+}
+
+// Stats returns the counters of the rate limiter.
+func (rl *RateLimiter) Stats() RateLimitStats {
+	stats := RateLimitStats{
+		Allowed: rl.allowed.Load(),
+		DryRun:  rl.dryRun.Load(),
+		Limited: rl.limited.Load(),
+		Methods: make(map[string]uint64),
+	}
+	rl.byMethod.Range(func(k, v any) bool {
+		stats.Methods[k.(string)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return stats
+}
+
+// take checks all limits applicable to the call. Tokens are only taken if every limit
+// allows the call, otherwise the time until it would be allowed is returned.
+func (rl *RateLimiter) take(peer PeerInfo, method string) (time.Duration, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.clock.Now()
+	if now.Sub(rl.lastSweep) >= rateLimitSweepInterval {
+		rl.sweep(now)
+	}
+
+	var (
+		buckets = make([]*tokenBucket, 0, 3)
+		wait    time.Duration
+	)
+	add := func(b *tokenBucket) {
+		b.refill(now)
+		wait = max(wait, b.wait())
+		buckets = append(buckets, b)
+	}
+	if limit, ok := rl.config.Methods[method]; ok && limit.Rate > 0 {
+		add(bucketFor(rl.methods, method, limit, now))
+	}
+	if key := rl.config.PeerKey(peer); key != "" {
+		if limit := rl.config.Peer; limit.Rate > 0 {
+			add(bucketFor(rl.peers, key, limit, now))
+		}
+		if limit, ok := rl.config.PeerMethods[method]; ok && limit.Rate > 0 {
+			add(bucketFor(rl.peerCalls, rateLimitKey{key, method}, limit, now))
+		}
+	}
+	if wait > 0 {
+		return wait, false
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0, true
+}
+
+// sweep removes per-client buckets which have refilled completely. They are
+// indistinguishable from new buckets.
+func (rl *RateLimiter) sweep(now mclock.AbsTime) {
+	rl.lastSweep = now
+	for key, b := range rl.peers {
+		if b.refill(now); b.full() {
+			delete(rl.peers, key)
+		}
+	}
+	for key, b := range rl.peerCalls {
+		if b.refill(now); b.full() {
+			delete(rl.peerCalls, key)
+		}
+	}
+}
+
+func rateLimitKeyByIP(peer PeerInfo) string {
+	return quotaKeyByIP(peer, "")
+}
+
+// tokenBucket is the state of a single limit.
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     mclock.AbsTime
+}
+
+func bucketFor[K comparable](m map[K]*tokenBucket, key K, limit RateLimit, now mclock.AbsTime) *tokenBucket {
+	b := m[key]
+	if b == nil {
+		capacity := limit.capacity()
+		b = &tokenBucket{rate: limit.Rate, capacity: capacity, tokens: capacity, last: now}
+		m[key] = b
+	}
+	return b
+}
+
+func (b *tokenBucket) refill(now mclock.AbsTime) {
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+func (b *tokenBucket) full() bool {
+	return b.tokens >= b.capacity
+}
+
+// wait returns the time until a token is available.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+}
+
+// rateLimitedError is returned for calls over a rate limit.
+type rateLimitedError struct {
+	code       int
+	retryAfter time.Duration
+}
+
+func (e *rateLimitedError) ErrorCode() int { return e.code }
+
+func (e *rateLimitedError) Error() string { return errMsgRateLimited }
+
+func (e *rateLimitedError) ErrorData() interface{} {
+	return map[string]interface{}{"retryAfterMs": e.retryAfter.Milliseconds()}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = new(mclock.Simulated)
+		alice = PeerInfo{RemoteAddr: "10.0.0.1:1000"}
+		bob   = PeerInfo{RemoteAddr: "10.0.0.2:1000"}
+		ipc   = PeerInfo{Transport: "ipc"}
+	)
+	rl := NewRateLimiter(RateLimitConfig{
+		Methods:     map[string]RateLimit{"test_global": {Rate: 1, Burst: 3}},
+		Peer:        RateLimit{Rate: 2},
+		PeerMethods: map[string]RateLimit{"test_expensive": {Rate: 0.5}},
+		Clock:       clock,
+	})
+	check := func(peer PeerInfo, method string, want bool) {
+		t.Helper()
+		err := rl.Allow(peer, method)
+		if (err == nil) != want {
+			t.Fatalf("%s from %q: allowed=%t, want %t (err %v)", method, peer.RemoteAddr, err == nil, want, err)
+		}
+	}
+
+	// Per-peer limit: burst of two, refilled at two per second.
+	check(alice, "test_echo", true)
+	check(alice, "test_echo", true)
+	check(alice, "test_echo", false)
+	check(bob, "test_echo", true)
+	clock.Run(500 * time.Millisecond)
+	check(alice, "test_echo", true)
+	check(alice, "test_echo", false)
+
+	// Per-peer method limit. The rejected call must not consume a peer token.
+	clock.Run(time.Second)
+	check(alice, "test_expensive", true)
+	check(alice, "test_expensive", false)
+	check(alice, "test_echo", true)
+
+	// Method limit, shared by all peers. Peers without key are only subject to this.
+	clock.Run(time.Second)
+	check(ipc, "test_global", true)
+	check(ipc, "test_global", true)
+	check(bob, "test_global", true)
+	check(ipc, "test_global", false)
+	check(ipc, "test_echo", true)
+
+	err := rl.Allow(ipc, "test_global")
+	var rpcErr DataError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if code := err.(Error).ErrorCode(); code != errcodeRateLimited {
+		t.Errorf("wrong error code %d", code)
+	}
+	if data := rpcErr.ErrorData().(map[string]interface{}); data["retryAfterMs"] != int64(1000) {
+		t.Errorf("wrong error data %v", data)
+	}
+
+	stats := rl.Stats()
+	if stats.Allowed != 10 || stats.Limited != 5 {
+		t.Errorf("wrong counters %+v", stats)
+	}
+	if stats.Methods["test_global"] != 2 || stats.Methods["test_echo"] != 2 {
+		t.Errorf("wrong method counters %v", stats.Methods)
+	}
+
+	// Idle peer buckets are removed.
+	clock.Run(rateLimitSweepInterval)
+	check(bob, "test_echo", true)
+	if n := len(rl.peers); n != 1 {
+		t.Errorf("wrong number of peer buckets after sweep: %d", n)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	rl := NewRateLimiter(RateLimitConfig{
+		Methods:   map[string]RateLimit{"test_echo": {Rate: 1}},
+		ErrorCode: -39000,
+		Clock:     new(mclock.Simulated),
+	})
+	server.SetMiddlewares([]Middleware{rl.Middleware()})
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	err := client.Call(nil, "test_echo", "x", 1)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -39000 {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if err := client.Call(nil, "test_repeat", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// RawHandler handles an RPC method on the JSON encoding of its parameters and result,
+// see Server.RegisterRawHandler.
+type RawHandler func(ctx context.Context, params json.RawMessage) (json.RawMessage, error)
+
+// RegisterRawHandler registers fn as the handler of the RPC method name, e.g.
+// "eth_blockNumber". The handler receives the parameters as sent by the client, i.e. a
+// JSON array or object, or nil if there are none, and returns the JSON encoding of the
+// result. Argument decoding and invocation through reflection are skipped entirely,
+// which makes raw handlers suitable for hot methods with arguments that are cheap to
+// parse by hand. Middlewares see the parameters as a single json.RawMessage argument.
+//
+// The method is added to its namespace like a method of a service registered with
+// RegisterName, replacing an existing method of the same name. A version can be given
+// in the namespace, e.g. "eth@2_blockNumber".
+func (s *Server) RegisterRawHandler(name string, fn RawHandler) error {
+	return s.services.registerRaw(name, fn)
+}
+
+func (r *serviceRegistry) registerRaw(name string, fn RawHandler) error {
+	before, method, found := strings.Cut(name, serviceMethodSeparator)
+	if !found || before == "" || method == "" {
+		return fmt.Errorf("invalid method name %q", name)
+	}
+	namespace, version, err := parseVersionedName(before)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.factories[namespace] != nil {
+		return fmt.Errorf("service %s is already registered with a factory", namespace)
+	}
+	r.registerVersion(namespace, version)
+	svc := r.serviceLocked(versionedName(namespace, version))
+	svc.callbacks[method] = &callback{raw: fn, errPos: -1}
+	return nil
+}
+
+// callRaw invokes a raw handler. The parameters are the only argument.
+func (c *callback) callRaw(ctx context.Context, args []reflect.Value) (any, error) {
+	var params json.RawMessage
+	if len(args) == 1 && args[0].Type() == rawMessageType {
+		params = args[0].Bytes()
+	}
+	res, err := c.raw(ctx, params)
+	if c.forward && err == nil && len(res) > 0 {
+		return forwardedJSON(res), nil
+	}
+	return res, err
+}
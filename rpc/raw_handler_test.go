@@ -0,0 +1,165 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// rawSum adds the integers in its parameters.
+func rawSum(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+	var args []int
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, &invalidParamsError{err.Error()}
+		}
+	}
+	sum := 0
+	for _, a := range args {
+		sum += a
+	}
+	return json.Marshal(sum)
+}
+
+func TestRawHandler(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterRawHandler("test_sum", rawSum); err != nil {
+		t.Fatal(err)
+	}
+	fail := func(context.Context, json.RawMessage) (json.RawMessage, error) {
+		return nil, errors.New("raw failure")
+	}
+	if err := server.RegisterRawHandler("raw_fail", fail); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var sum int
+	if err := client.Call(&sum, "test_sum", 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 6 {
+		t.Fatalf("wrong sum %d", sum)
+	}
+	if err := client.Call(&sum, "test_sum"); err != nil || sum != 0 {
+		t.Fatalf("call without params: %d, %v", sum, err)
+	}
+	wantCallError(t, client.Call(&sum, "test_sum", "x"), -32602)
+	if err := client.Call(nil, "raw_fail"); err == nil || err.Error() != "raw failure" {
+		t.Fatalf("wrong error %v", err)
+	}
+
+	// Methods of the service registered in the same namespace still work.
+	var echo echoResult
+	if err := client.Call(&echo, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	// The namespace of a raw handler is a module.
+	modules, err := client.SupportedModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := modules["raw"]; !ok {
+		t.Fatalf("raw namespace missing from modules %v", modules)
+	}
+}
+
+func TestRawHandlerMiddleware(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	server.RegisterRawHandler("raw_sum", rawSum)
+	var seen []reflect.Value
+	server.SetMiddlewares([]Middleware{
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			seen = args
+			return next(ctx, method, args)
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var sum int
+	if err := client.Call(&sum, "raw_sum", 4, 5); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 1 || seen[0].Type() != rawMessageType || string(seen[0].Bytes()) != "[4,5]" {
+		t.Fatalf("middleware got wrong args %v", seen)
+	}
+}
+
+func TestRawHandlerNames(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	for _, name := range []string{"sum", "_sum", "raw_", "raw@0_sum"} {
+		if err := server.RegisterRawHandler(name, rawSum); err == nil {
+			t.Errorf("%q accepted", name)
+		}
+	}
+	server.RegisterFactory("conn", func(context.Context, PeerInfo) (any, error) { return nil, nil })
+	err := server.RegisterRawHandler("conn_sum", rawSum)
+	if err == nil || !strings.Contains(err.Error(), "factory") {
+		t.Errorf("wrong error for factory namespace: %v", err)
+	}
+
+	// Versioned namespaces.
+	if err := server.RegisterRawHandler("raw@2_sum", rawSum); err != nil {
+		t.Fatal(err)
+	}
+	if v := server.services.versions()["raw"]; !slices.Equal(v, []int{2}) {
+		t.Fatalf("wrong versions %v", v)
+	}
+}
+
+// BenchmarkRawHandler compares reflection-based argument decoding and invocation with a
+// raw handler.
+func BenchmarkRawHandler(b *testing.B) {
+	callbacks, err := receiverCallbacks(new(adaptedService))
+	if err != nil {
+		b.Fatal(err)
+	}
+	params := json.RawMessage(`[1,2]`)
+	ctx := context.Background()
+
+	b.Run("reflect", func(b *testing.B) {
+		cb := callbacks["add"]
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			args, err := parsePositionalArguments(params, cb.argTypes, decodeConfig{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			cb.call(ctx, "calc_add", args)
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		cb := &callback{raw: func(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+			return params[1:2], nil
+		}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cb.call(ctx, "calc_add", []reflect.Value{reflect.ValueOf(params)})
+		}
+	})
+}
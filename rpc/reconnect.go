@@ -0,0 +1,233 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReconnectPolicy configures the reconnecting mode of websocket and IPC clients, see
+// WithReconnect.
+type ReconnectPolicy struct {
+	DialTimeout time.Duration // timeout of each connection attempt, zero for the transport default
+	Backoff     time.Duration // delay before the first redial, doubled on every attempt (default 100ms)
+	MaxBackoff  time.Duration // upper bound of the redial delay (default 5s)
+	MaxAttempts int           // number of redials after losing the connection, zero for no limit
+
+	// ReplayMethods lists the methods whose calls are sent again when the connection is
+	// lost before their response has arrived. A pattern ending in '*' matches all
+	// methods with the given prefix, "*" replays every call. Calls are replayed once,
+	// after the connection has been re-established. Only list methods which are safe to
+	// execute twice.
+	ReplayMethods []string
+}
+
+// WithReconnect makes the client redial the server in the background as soon as the
+// connection is lost, instead of waiting for the next request. Redial attempts are
+// spaced out using exponential backoff.
+//
+// Calls which were in flight when the connection broke fail with the connection error
+// unless their method is listed in ReplayMethods. When resubscription is enabled with
+// WithResubscribe, active subscriptions are created again once the new connection has
+// been established.
+//
+// This option has no effect for HTTP clients.
+func WithReconnect(p ReconnectPolicy) ClientOption {
+	if p.Backoff <= 0 {
+		p.Backoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.reconnectPolicy = &p
+	})
+}
+
+// WithAutoReconnect makes websocket and IPC clients survive the loss of the connection
+// transparently. The client redials in the background, starting with the given backoff
+// (see WithReconnect), and creates all active subscriptions again on the new
+// connection. Notifications keep arriving on the channel passed to Subscribe. Since
+// notifications sent while disconnected are lost, each resubscription is followed by a
+// SubscriptionGapDetected event on the Lifecycle channel of the subscription.
+//
+// Unlike with WithResubscribe, resubscription is attempted until it succeeds, the
+// server rejects the subscription with an error, or the client is closed.
+func WithAutoReconnect(backoff time.Duration) ClientOption {
+	reconnect := WithReconnect(ReconnectPolicy{Backoff: backoff})
+	return optionFunc(func(cfg *clientConfig) {
+		reconnect.applyOption(cfg)
+		cfg.resubscribeAttempts = resubscribeForever
+	})
+}
+
+// reconnector tracks the state of the connection for the reconnect policy of a client.
+type reconnector struct {
+	policy ReconnectPolicy
+
+	mu   sync.Mutex
+	down chan struct{} // non-nil while disconnected, closed when the connection is restored
+}
+
+// lost marks the connection as lost. It returns the channel which is closed when the
+// connection is restored, and whether the caller should start redialing.
+func (r *reconnector) lost() (chan struct{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.down != nil {
+		return r.down, false
+	}
+	r.down = make(chan struct{})
+	return r.down, true
+}
+
+// restored marks the connection as established. This also releases waiters when
+// redialing has given up, because requests then reconnect on their own.
+func (r *reconnector) restored() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.down != nil {
+		close(r.down)
+		r.down = nil
+	}
+}
+
+// wait blocks until the client is connected.
+func (r *reconnector) wait(ctx context.Context, closing <-chan struct{}) error {
+	r.mu.Lock()
+	down := r.down
+	r.mu.Unlock()
+
+	if down == nil {
+		return nil
+	}
+	select {
+	case <-down:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closing:
+		return ErrClientQuit
+	}
+}
+
+// replays reports whether a call of method whose request op ended with err should be
+// sent again.
+func (r *reconnector) replays(method string, op *requestOp, err error) bool {
+	return r != nil && op.err != nil && err == op.err && err != ErrClientQuit && matchMethod(r.policy.ReplayMethods, method)
+}
+
+// connectionLost is called by dispatch when the read loop of codec has ended.
+func (c *Client) connectionLost(codec ServerCodec) {
+	if c.reconnector == nil || c.reconnectFunc == nil {
+		return
+	}
+	if down, start := c.reconnector.lost(); start {
+		go c.redial(codec, down)
+	}
+}
+
+// redial establishes a new connection after dead was lost. It stops when down is closed,
+// i.e. when a request has reconnected in the meantime.
+func (c *Client) redial(dead ServerCodec, down chan struct{}) {
+	var (
+		p       = c.reconnector.policy
+		backoff = p.Backoff
+	)
+	for attempt := 1; ; attempt++ {
+		timer := c.clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+		case <-down:
+			timer.Stop()
+			return
+		case <-c.closing:
+			timer.Stop()
+			return
+		}
+		backoff = min(2*backoff, p.MaxBackoff)
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.dialTimeout())
+		conn, err := c.reconnectFunc(ctx)
+		cancel()
+		if err == nil {
+			c.installConn(dead, conn)
+			return
+		}
+		log.Debug("RPC client redial failed", "attempt", attempt, "err", err)
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			log.Warn("RPC client gave up reconnecting", "attempts", attempt, "err", err)
+			c.reconnector.restored()
+			return
+		}
+	}
+}
+
+// installConn makes conn the connection of the client, unless a request has already
+// replaced the dead connection.
+func (c *Client) installConn(dead ServerCodec, conn ServerCodec) {
+	// Take the write lock, like a request does.
+	select {
+	case c.reqInit <- new(requestOp):
+	case <-c.closing:
+		conn.close()
+		return
+	}
+	defer func() { c.reqSent <- nil }()
+
+	if c.writeConn != nil && c.writeConn != jsonWriter(dead) {
+		conn.close()
+		return
+	}
+	select {
+	case c.reconnected <- conn:
+		c.writeConn = conn
+	case <-c.didClose:
+		conn.close()
+	}
+}
+
+// dialTimeout returns the timeout of connection attempts made after the initial one.
+func (c *Client) dialTimeout() time.Duration {
+	if c.reconnector != nil && c.reconnector.policy.DialTimeout > 0 {
+		return c.reconnector.policy.DialTimeout
+	}
+	return defaultDialTimeout
+}
+
+// replayCall sends msg again once the connection has been re-established.
+func (c *Client) replayCall(ctx context.Context, msg *jsonrpcMessage) ([]*jsonrpcMessage, error) {
+	if err := c.reconnector.wait(ctx, c.closing); err != nil {
+		return nil, err
+	}
+	log.Debug("Replaying RPC call after reconnect", "method", msg.Method)
+	op := &requestOp{
+		ids:  []json.RawMessage{msg.ID},
+		resp: make(chan []*jsonrpcMessage, 1),
+	}
+	if err := c.send(ctx, op, msg); err != nil {
+		return nil, err
+	}
+	return op.wait(ctx, c)
+}
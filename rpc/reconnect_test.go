@@ -0,0 +1,223 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (l *connTrackingListener) numConns() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.conns)
+}
+
+func TestClientReconnectRedial(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses unix sockets")
+	}
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	endpoint := filepath.Join(dir, "test.ipc")
+	l, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &connTrackingListener{Listener: l}
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(listener)
+
+	client, err := DialIPC(context.Background(), endpoint, WithReconnect(ReconnectPolicy{Backoff: 10 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The client redials without waiting for a request.
+	listener.breakConns()
+	deadline := time.Now().Add(5 * time.Second)
+	for listener.numConns() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("client did not reconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1, nil); err != nil {
+		t.Fatal("call after reconnect failed:", err)
+	}
+	if n := listener.numConns(); n != 1 {
+		t.Fatalf("client has %d connections, want 1", n)
+	}
+}
+
+func TestClientReconnectReplay(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses unix sockets")
+	}
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	endpoint := filepath.Join(dir, "test.ipc")
+	l, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &connTrackingListener{Listener: l}
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(listener)
+
+	client, err := DialIPC(context.Background(), endpoint, WithReconnect(ReconnectPolicy{
+		Backoff:       10 * time.Millisecond,
+		ReplayMethods: []string{"test_sleep"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	sleepErr := make(chan error, 1)
+	blockErr := make(chan error, 1)
+	go func() { sleepErr <- client.Call(nil, "test_sleep", 300*time.Millisecond) }()
+	go func() { blockErr <- client.Call(nil, "test_block") }()
+	time.Sleep(100 * time.Millisecond)
+	listener.breakConns()
+
+	// The replayed call completes on the new connection, the other one fails.
+	if err := <-sleepErr; err != nil {
+		t.Fatal("replayed call failed:", err)
+	}
+	if err := <-blockErr; err == nil {
+		t.Fatal("call of method not listed in ReplayMethods succeeded")
+	}
+}
+
+func TestClientReconnectResubscribe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses unix sockets")
+	}
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	endpoint := filepath.Join(dir, "test.ipc")
+	l, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &connTrackingListener{Listener: l}
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(listener)
+
+	client, err := DialIPC(context.Background(), endpoint,
+		WithReconnect(ReconnectPolicy{Backoff: 10 * time.Millisecond}),
+		WithResubscribe(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	<-ch
+	listener.breakConns()
+	for {
+		ev := nextSubscriptionEvent(t, sub.Lifecycle())
+		if ev.Kind == SubscriptionResubscribed {
+			break
+		}
+		if ev.Kind == SubscriptionClosed {
+			t.Fatal("subscription closed:", ev.Err)
+		}
+	}
+	if v := <-ch; v != 7 {
+		t.Fatalf("wrong notification after resubscribe %d", v)
+	}
+}
+
+func TestClientAutoReconnectWebsocket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewUnstartedServer(server.WebsocketHandler([]string{"*"}))
+	listener := &connTrackingListener{Listener: httpsrv.Listener}
+	httpsrv.Listener = listener
+	httpsrv.Start()
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	client, err := DialOptions(context.Background(), wsURL, WithAutoReconnect(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if ev := nextSubscriptionEvent(t, sub.Lifecycle()); ev.Kind != SubscriptionEstablished {
+		t.Fatalf("wrong first event %+v", ev)
+	}
+	<-ch
+
+	// Break the connection a few times. The subscription keeps delivering on the same
+	// channel and reports the gaps.
+	for i := 0; i < 3; i++ {
+		listener.breakConns()
+		if ev := nextSubscriptionEvent(t, sub.Lifecycle()); ev.Kind != SubscriptionResubscribed {
+			t.Fatalf("wrong event %+v, want resubscribed", ev)
+		}
+		if ev := nextSubscriptionEvent(t, sub.Lifecycle()); ev.Kind != SubscriptionGapDetected {
+			t.Fatalf("wrong event %+v, want gap", ev)
+		}
+		if v := <-ch; v != 7 {
+			t.Fatalf("wrong notification after resubscribe %d", v)
+		}
+	}
+}
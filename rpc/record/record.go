@@ -0,0 +1,243 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package record records JSON-RPC traffic of a server and replays it.
+//
+// A Recorder wraps the codecs and HTTP handlers of an rpc.Server and writes all inbound
+// requests and outbound responses to a file, one JSON object per line. Recorded traffic
+// can be re-issued against a server using Replay, e.g. for load testing or for
+// comparing the behavior of a new version with the recorded responses. NewReplayServer
+// creates a server answering calls with the recorded responses, which is useful as a
+// stand-in for an upstream node in tests.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/base/go-ethereum-rpc/rpc"
+)
+
+// Direction is the direction of a recorded message.
+type Direction string
+
+const (
+	Inbound  Direction = "in"  // message received by the server
+	Outbound Direction = "out" // message sent by the server
+)
+
+// Entry is a recorded message.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Conn identifies the connection the message was sent on. Each HTTP request is a
+	// connection of its own.
+	Conn uint64    `json:"conn"`
+	Dir  Direction `json:"dir"`
+	// Message is a JSON-RPC message or batch.
+	Message json.RawMessage `json:"msg"`
+}
+
+// Recorder writes JSON-RPC traffic to a file or stream. It is safe for concurrent use.
+type Recorder struct {
+	conns atomic.Uint64
+
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	err    error // first write error
+}
+
+// NewRecorder creates a recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w)}
+}
+
+// Create creates a recorder writing to the file at path. An existing file is truncated.
+func Create(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecorder(f)
+	r.closer = f
+	return r, nil
+}
+
+// Flush writes buffered entries to the underlying writer. It returns the first error
+// which occurred while writing entries.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flush()
+}
+
+func (r *Recorder) flush() error {
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// Close flushes the recorder and closes the file created by Create.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.flush()
+	if r.closer != nil {
+		if cerr := r.closer.Close(); err == nil {
+			err = cerr
+		}
+		r.closer = nil
+	}
+	return err
+}
+
+// record writes an entry. Write errors are reported by Flush and Close.
+func (r *Recorder) record(conn uint64, dir Direction, msg []byte) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, msg); err != nil {
+		return
+	}
+	line, err := json.Marshal(Entry{Time: time.Now(), Conn: conn, Dir: dir, Message: compact.Bytes()})
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.w.Write(line)
+	r.err = r.w.WriteByte('\n')
+}
+
+// NewCodec creates a server codec on conn, like rpc.NewCodec, which records all
+// messages read from and written to the connection.
+func (r *Recorder) NewCodec(conn rpc.Conn) rpc.ServerCodec {
+	var (
+		id  = r.conns.Add(1)
+		dec = json.NewDecoder(conn)
+	)
+	dec.UseNumber()
+	encode := func(v interface{}, isErrorResponse bool) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		r.record(id, Outbound, data)
+		_, err = conn.Write(append(data, '\n'))
+		return err
+	}
+	decode := func(v interface{}) error {
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+		if raw, ok := v.(*json.RawMessage); ok {
+			r.record(id, Inbound, *raw)
+		}
+		return nil
+	}
+	return rpc.NewFuncCodec(conn, encode, decode)
+}
+
+// Handler returns an HTTP handler recording the requests and responses of next, which
+// is usually an rpc.Server. Requests without body, such as health checks, are not
+// recorded.
+func (r *Recorder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Body == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		id := r.conns.Add(1)
+		r.record(id, Inbound, body)
+		rw := &recordingWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, req)
+		if rw.status == 0 || rw.status == http.StatusOK {
+			r.record(id, Outbound, rw.body.Bytes())
+		}
+	})
+}
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for use by http.ResponseController.
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ReadFile reads the entries recorded in the file at path.
+func ReadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadEntries(f)
+}
+
+// ReadEntries reads recorded entries from r.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	var (
+		entries []Entry
+		dec     = json.NewDecoder(r)
+	)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package record
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/base/go-ethereum-rpc/rpc"
+)
+
+type testService struct {
+	mu     sync.Mutex
+	height int
+}
+
+func (s *testService) Add(a, b int) int {
+	return a + b
+}
+
+func (s *testService) Height() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.height++
+	return s.height
+}
+
+func (s *testService) Fail() error {
+	return errors.New("failed")
+}
+
+// generateTraffic performs calls on client.
+func generateTraffic(t *testing.T, client *rpc.Client) {
+	var sum, height int
+	if err := client.Call(&sum, "test_add", 1, 2); err != nil || sum != 3 {
+		t.Fatalf("wrong result %d, %v", sum, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&height, "test_height"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Call(nil, "test_fail"); err == nil {
+		t.Fatal("expected error")
+	}
+	batch := []rpc.BatchElem{
+		{Method: "test_add", Args: []interface{}{3, 4}, Result: &sum},
+		{Method: "test_add", Args: []interface{}{5, 6}, Result: &sum},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordCodec(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	server.RegisterName("test", new(testService))
+	var (
+		buf                 bytes.Buffer
+		rec                 = NewRecorder(&buf)
+		serverConn, cliConn = net.Pipe()
+	)
+	go server.ServeCodec(rec.NewCodec(serverConn), 0)
+	client, err := rpc.DialIO(context.Background(), cliConn, cliConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generateTraffic(t, client)
+	cliConn.Close()
+	client.Close()
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadEntries(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Five requests, one of them a batch, and their responses.
+	if len(entries) != 10 {
+		t.Fatalf("wrong number of entries %d", len(entries))
+	}
+	var in, out int
+	for _, e := range entries {
+		if e.Conn != 1 {
+			t.Errorf("wrong connection %d", e.Conn)
+		}
+		switch e.Dir {
+		case Inbound:
+			in++
+		case Outbound:
+			out++
+		}
+	}
+	if in != 5 || out != 5 {
+		t.Fatalf("wrong directions: %d in, %d out", in, out)
+	}
+}
+
+func recordHTTP(t *testing.T, path string) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	server.RegisterName("test", new(testService))
+	rec, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(rec.Handler(server))
+	defer httpsrv.Close()
+	client, err := rpc.DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	generateTraffic(t, client)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recordHTTP(t, path)
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 10 {
+		t.Fatalf("wrong number of entries %d", len(entries))
+	}
+
+	// Replay against a new instance of the server, which must answer the same way.
+	server := rpc.NewServer()
+	defer server.Stop()
+	server.RegisterName("test", new(testService))
+	var (
+		client   = rpc.DialInProc(server)
+		differ   = rpc.NewShadowDiffer()
+		stats, _ = Replay(context.Background(), client, entries, ReplayConfig{Concurrency: 1, Compare: differ.Compare})
+	)
+	defer client.Close()
+	if stats.Requests != 5 || stats.Calls != 6 || stats.Errors != 1 || stats.Skipped != 0 {
+		t.Fatalf("wrong stats %+v", stats)
+	}
+	for _, r := range differ.Report() {
+		if r.Mismatches != 0 {
+			t.Errorf("mismatches for %s: %+v", r.Method, r.Samples)
+		}
+	}
+}
+
+func TestReplayServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recordHTTP(t, path)
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewReplayServer(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var sum int
+	if err := client.Call(&sum, "test_add", 5, 6); err != nil || sum != 11 {
+		t.Fatalf("wrong result %d, %v", sum, err)
+	}
+	if err := client.Call(&sum, "test_add", 7, 8); err == nil {
+		t.Fatal("expected error for call which wasn't recorded")
+	}
+	// Recorded responses are returned in order, the last one is repeated.
+	for _, want := range []int{1, 2, 2} {
+		var height int
+		if err := client.Call(&height, "test_height"); err != nil || height != want {
+			t.Fatalf("wrong height %d, want %d (err %v)", height, want, err)
+		}
+	}
+	var rpcErr rpc.Error
+	if err := client.Call(nil, "test_fail"); !errors.As(err, &rpcErr) || err.Error() != "failed" {
+		t.Fatalf("wrong error %v", err)
+	}
+}
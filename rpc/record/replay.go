@@ -0,0 +1,376 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/base/go-ethereum-rpc/rpc"
+)
+
+const defaultReplayConcurrency = 16
+
+var errNotRecorded = errors.New("no recorded response")
+
+// message is a recorded JSON-RPC message.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ResponseError  `json:"error,omitempty"`
+}
+
+func (m *message) isCall() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+func (m *message) isResponse() bool {
+	return m.Method == "" && len(m.ID) > 0 && (m.Result != nil || m.Error != nil)
+}
+
+// result returns the outcome of a recorded call.
+func (m *message) result() rpc.ShadowResult {
+	if m.Error != nil {
+		return rpc.ShadowResult{Err: m.Error}
+	}
+	return rpc.ShadowResult{Result: m.Result}
+}
+
+// args returns the positional parameters of a call. It returns false for calls with
+// named parameters.
+func (m *message) args() ([]interface{}, bool) {
+	if isEmptyParams(m.Params) {
+		return nil, true
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(m.Params, &params); err != nil {
+		return nil, false
+	}
+	args := make([]interface{}, len(params))
+	for i := range params {
+		args[i] = params[i]
+	}
+	return args, true
+}
+
+// parseMessages decodes a recorded message or batch.
+func parseMessages(raw json.RawMessage) (msgs []*message, batch bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		if json.Unmarshal(raw, &msgs) != nil {
+			return nil, true
+		}
+		return msgs, true
+	}
+	msg := new(message)
+	if json.Unmarshal(raw, msg) != nil {
+		return nil, false
+	}
+	return []*message{msg}, false
+}
+
+func isEmptyParams(params json.RawMessage) bool {
+	p := string(bytes.TrimSpace(params))
+	return p == "" || p == "null" || p == "[]"
+}
+
+// ResponseError is a recorded error response.
+type ResponseError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("json-rpc error %d", e.Code)
+	}
+	return e.Message
+}
+
+// ErrorCode returns the JSON-RPC error code.
+func (e *ResponseError) ErrorCode() int {
+	return e.Code
+}
+
+// ErrorData returns the JSON encoding of the error data.
+func (e *ResponseError) ErrorData() interface{} {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return e.Data
+}
+
+// ReplayConfig configures Replay.
+type ReplayConfig struct {
+	// Speed scales the timing of the recording: at 1, requests are issued at their
+	// recorded pace, at 2 twice as fast. At 0, requests are issued as fast as the
+	// concurrency limit allows.
+	Speed float64
+
+	// Concurrency limits the number of requests in flight. The default is 16.
+	Concurrency int
+
+	// Compare, if set, is called with the recorded and the replayed outcome of every
+	// call whose response was recorded, e.g. to compare them using rpc.ShadowDiffer. It
+	// is called concurrently.
+	Compare func(method string, recorded, replayed rpc.ShadowResult)
+}
+
+// ReplayStats summarizes a replay.
+type ReplayStats struct {
+	Requests int           // messages and batches sent
+	Calls    int           // calls sent
+	Skipped  int           // calls not replayed, i.e. subscriptions and calls with named parameters
+	Errors   int           // calls which failed
+	Duration time.Duration // time taken by the replay
+}
+
+// connMsgKey identifies a message of a recorded connection.
+type connMsgKey struct {
+	conn uint64
+	id   string
+}
+
+// Replay re-issues the recorded requests in entries using client. Requests of all
+// connections are sent on the client, batches are sent as batches. Notifications,
+// subscriptions and calls with named parameters are not replayed. Replay returns when
+// all requests have been answered, or when ctx is canceled.
+func Replay(ctx context.Context, client *rpc.Client, entries []Entry, cfg ReplayConfig) (ReplayStats, error) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultReplayConcurrency
+	}
+	var (
+		inbound   []Entry
+		responses = make(map[connMsgKey]*message)
+	)
+	for _, e := range entries {
+		switch e.Dir {
+		case Inbound:
+			inbound = append(inbound, e)
+		case Outbound:
+			msgs, _ := parseMessages(e.Message)
+			for _, msg := range msgs {
+				if msg != nil && msg.isResponse() {
+					responses[connMsgKey{e.Conn, string(msg.ID)}] = msg
+				}
+			}
+		}
+	}
+	sort.SliceStable(inbound, func(i, j int) bool { return inbound[i].Time.Before(inbound[j].Time) })
+
+	var (
+		stats ReplayStats
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, cfg.Concurrency)
+		start = time.Now()
+	)
+	record := func(calls, skipped, errs int) {
+		mu.Lock()
+		defer mu.Unlock()
+		stats.Calls += calls
+		stats.Skipped += skipped
+		stats.Errors += errs
+		if calls > 0 {
+			stats.Requests++
+		}
+	}
+	for _, e := range inbound {
+		if cfg.Speed > 0 {
+			offset := time.Duration(float64(e.Time.Sub(inbound[0].Time)) / cfg.Speed)
+			if err := sleepUntil(ctx, start.Add(offset)); err != nil {
+				break
+			}
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			record(replayEntry(ctx, client, e, responses, cfg.Compare))
+		}()
+	}
+	wg.Wait()
+	stats.Duration = time.Since(start)
+	return stats, ctx.Err()
+}
+
+// replayEntry sends the calls of a recorded request. It returns the number of calls
+// sent, skipped and failed.
+func replayEntry(ctx context.Context, client *rpc.Client, e Entry, responses map[connMsgKey]*message, compare func(string, rpc.ShadowResult, rpc.ShadowResult)) (calls, skipped, errs int) {
+	msgs, batch := parseMessages(e.Message)
+	var (
+		elems []rpc.BatchElem
+		sent  []*message
+	)
+	for _, msg := range msgs {
+		if msg == nil || !msg.isCall() {
+			continue
+		}
+		args, ok := msg.args()
+		if !ok || strings.HasSuffix(msg.Method, "_subscribe") || strings.HasSuffix(msg.Method, "_unsubscribe") {
+			skipped++
+			continue
+		}
+		elems = append(elems, rpc.BatchElem{Method: msg.Method, Args: args, Result: new(json.RawMessage)})
+		sent = append(sent, msg)
+	}
+	if len(elems) == 0 {
+		return 0, skipped, 0
+	}
+
+	if batch {
+		if err := client.BatchCallContext(ctx, elems); err != nil {
+			for i := range elems {
+				elems[i].Error = err
+			}
+		}
+	} else {
+		elems[0].Error = client.CallContext(ctx, elems[0].Result, elems[0].Method, elems[0].Args...)
+	}
+	for i, elem := range elems {
+		if elem.Error != nil {
+			errs++
+		}
+		if recorded, ok := responses[connMsgKey{e.Conn, string(sent[i].ID)}]; ok && compare != nil {
+			replayed := rpc.ShadowResult{Result: *elem.Result.(*json.RawMessage), Err: elem.Error}
+			compare(elem.Method, recorded.result(), replayed)
+		}
+	}
+	return len(elems), skipped, errs
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewReplayServer creates a server answering calls with the responses recorded in
+// entries. Calls are matched by method and parameters. If a call was recorded multiple
+// times, its responses are returned in recorded order, and the last one is repeated.
+// Calls of recorded methods with other parameters fail with an error.
+//
+// The server doesn't support subscriptions.
+func NewReplayServer(entries []Entry) (*rpc.Server, error) {
+	requests := make(map[connMsgKey]*message)
+	for _, e := range entries {
+		if e.Dir != Inbound {
+			continue
+		}
+		msgs, _ := parseMessages(e.Message)
+		for _, msg := range msgs {
+			if msg != nil && msg.isCall() {
+				requests[connMsgKey{e.Conn, string(msg.ID)}] = msg
+			}
+		}
+	}
+	methods := make(map[string]*replayMethod)
+	for _, e := range entries {
+		if e.Dir != Outbound {
+			continue
+		}
+		msgs, _ := parseMessages(e.Message)
+		for _, resp := range msgs {
+			if resp == nil || !resp.isResponse() {
+				continue
+			}
+			req, ok := requests[connMsgKey{e.Conn, string(resp.ID)}]
+			if !ok {
+				continue
+			}
+			m := methods[req.Method]
+			if m == nil {
+				m = &replayMethod{responses: make(map[string][]*message), next: make(map[string]int)}
+				methods[req.Method] = m
+			}
+			key := paramsKey(req.Params)
+			m.responses[key] = append(m.responses[key], resp)
+		}
+	}
+
+	server := rpc.NewServer()
+	for name, m := range methods {
+		if err := server.RegisterRawHandler(name, m.handle); err != nil {
+			server.Stop()
+			return nil, err
+		}
+	}
+	return server, nil
+}
+
+// replayMethod serves the recorded responses of a method.
+type replayMethod struct {
+	mu        sync.Mutex
+	responses map[string][]*message // by parameters
+	next      map[string]int        // index of the next response
+}
+
+func (m *replayMethod) handle(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+	key := paramsKey(params)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	responses := m.responses[key]
+	if len(responses) == 0 {
+		return nil, errNotRecorded
+	}
+	i := m.next[key]
+	if i < len(responses)-1 {
+		m.next[key] = i + 1
+	}
+	if err := responses[i].Error; err != nil {
+		return nil, err
+	}
+	return responses[i].Result, nil
+}
+
+// paramsKey returns the canonical form of call parameters.
+func paramsKey(params json.RawMessage) string {
+	if isEmptyParams(params) {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, params); err != nil {
+		return string(params)
+	}
+	return buf.String()
+}
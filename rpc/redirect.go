@@ -0,0 +1,177 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RedirectError tells the client to repeat the call on one of the given endpoints,
+// e.g. to steer clients to the cluster node which holds the requested data. Methods and
+// middleware can return it. The endpoints are sent as the error data
+// {"redirect": [...]}, in order of preference. Clients created with WithFollowRedirects
+// follow the redirect automatically.
+func RedirectError(endpoints ...string) error {
+	return &redirectError{endpoints: endpoints}
+}
+
+type redirectError struct {
+	endpoints []string
+}
+
+func (e *redirectError) Error() string { return errMsgRedirect }
+
+func (e *redirectError) ErrorCode() int { return errcodeRedirect }
+
+func (e *redirectError) ErrorData() interface{} {
+	return map[string]interface{}{"redirect": e.endpoints}
+}
+
+// redirectTargets returns the endpoints of a redirect error received from a server.
+func redirectTargets(err error) ([]string, bool) {
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeRedirect {
+		return nil, false
+	}
+	var dataErr DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	data, _ := dataErr.ErrorData().(map[string]interface{})
+	list, _ := data["redirect"].([]interface{})
+	var endpoints []string
+	for _, v := range list {
+		if s, ok := v.(string); ok && s != "" {
+			endpoints = append(endpoints, s)
+		}
+	}
+	return endpoints, len(endpoints) > 0
+}
+
+// WithFollowRedirects makes the client follow redirect errors (see RedirectError) of
+// calls made through CallContext. The call is repeated on the first endpoint of the
+// redirect which hasn't been visited by the call yet and can be reached, following at
+// most maxHops redirects. Connections to the endpoints are dialed with the options of the client
+// and kept open until the client is closed.
+//
+// Since the client options, including HTTP headers and authentication, are used for the
+// redirect targets, this option should only be enabled for trusted servers. It only
+// works for clients created using Dial, DialContext or DialOptions.
+func WithFollowRedirects(maxHops int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.maxRedirects = maxHops
+	})
+}
+
+// redirector follows redirect errors for a client.
+type redirector struct {
+	maxHops int
+	origin  string         // endpoint of the client
+	options []ClientOption // used to dial redirect targets
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	closed  bool
+}
+
+func newRedirector(maxHops int, origin string, options []ClientOption) *redirector {
+	return &redirector{
+		maxHops: maxHops,
+		origin:  origin,
+		options: options,
+		clients: make(map[string]*Client),
+	}
+}
+
+// follow repeats req on the targets of redirect error err. Targets which can't be
+// reached are skipped. It returns the result of the last attempt, or err if it isn't a
+// redirect.
+func (r *redirector) follow(ctx context.Context, req *ClientRequest, err error) error {
+	visited := []string{r.origin}
+	for hop := 0; hop < r.maxHops; hop++ {
+		targets, ok := redirectTargets(err)
+		if !ok {
+			return err
+		}
+		var (
+			result   = err
+			followed bool
+		)
+		for _, target := range targets {
+			if slices.Contains(visited, target) {
+				continue
+			}
+			visited = append(visited, target)
+			log.Trace("Following RPC redirect", "method", req.Method, "target", target)
+			c, dialErr := r.client(ctx, target)
+			if dialErr != nil {
+				result = dialErr
+			} else {
+				result = c.callContext(ctx, req.Result, req.Method, req.Args...)
+			}
+			if !isFailoverError(result) {
+				followed = true
+				break
+			}
+			log.Debug("RPC redirect target unreachable", "target", target, "err", result)
+		}
+		if !followed {
+			if result == err {
+				log.Debug("RPC redirect loop", "method", req.Method, "targets", targets)
+			}
+			return result
+		}
+		err = result
+	}
+	return err
+}
+
+// client returns the client of the given endpoint, dialing it if necessary.
+func (r *redirector) client(ctx context.Context, endpoint string) (*Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, ErrClientQuit
+	}
+	if c := r.clients[endpoint]; c != nil {
+		return c, nil
+	}
+	c, err := DialOptions(ctx, endpoint, r.options...)
+	if err != nil {
+		return nil, err
+	}
+	r.clients[endpoint] = c
+	return c, nil
+}
+
+// close closes the clients of redirect targets.
+func (r *redirector) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	for _, c := range r.clients {
+		c.Close()
+	}
+	clear(r.clients)
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+type redirectService struct {
+	name   string
+	target *string
+}
+
+func (s *redirectService) Get() (string, error) {
+	if *s.target != "" {
+		return "", RedirectError("http://127.0.0.1:0", *s.target)
+	}
+	return s.name, nil
+}
+
+func TestRedirect(t *testing.T) {
+	t.Parallel()
+
+	var targetA, targetB string
+	serverA := newTestServer()
+	defer serverA.Stop()
+	serverA.RegisterName("redir", &redirectService{"a", &targetA})
+	httpsrvA := httptest.NewServer(serverA)
+	defer httpsrvA.Close()
+	serverB := newTestServer()
+	defer serverB.Stop()
+	serverB.RegisterName("redir", &redirectService{"b", &targetB})
+	httpsrvB := httptest.NewServer(serverB)
+	defer httpsrvB.Close()
+	urlA, urlB := httpsrvA.URL, httpsrvB.URL
+	targetA = urlB
+
+	// Redirects are not followed by default.
+	plain, err := DialHTTP(urlA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	err = plain.Call(nil, "redir_get")
+	if targets, ok := redirectTargets(err); !ok || len(targets) != 2 || targets[1] != urlB {
+		t.Fatalf("expected redirect error, got %v", err)
+	}
+
+	client, err := DialOptions(context.Background(), urlA, WithFollowRedirects(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result string
+	if err := client.Call(&result, "redir_get"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "b" {
+		t.Fatalf("wrong result %q", result)
+	}
+
+	// A redirect loop ends with the redirect error.
+	targetB = urlA
+	err = client.Call(&result, "redir_get")
+	if _, ok := redirectTargets(err); !ok {
+		t.Fatalf("expected redirect error, got %v", err)
+	}
+}
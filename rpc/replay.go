@@ -0,0 +1,204 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+const (
+	// ReplaySafeHeader is set on HTTP responses when replay protection is enabled. Its
+	// value is "true" if the request only contained idempotent calls, so a proxy may
+	// safely send it again after a failure, and "false" otherwise.
+	ReplaySafeHeader = "X-Rpc-Replay-Safe"
+
+	// IdempotencyKeyHeader carries a client-chosen key identifying a request across
+	// retries. Only requests carrying the header are checked for replayed calls.
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+const (
+	defaultReplayWindow     = time.Minute
+	defaultReplayMaxEntries = 10000
+	replayMaxBytes          = 64 * 1024 * 1024
+)
+
+// ReplayProtection configures the detection of replayed calls to non-idempotent methods,
+// see Server.SetReplayProtection.
+type ReplayProtection struct {
+	// NonIdempotent lists the methods which must not be executed twice, e.g.
+	// "eth_sendRawTransaction". A name ending in '*' matches all methods with the given
+	// prefix.
+	NonIdempotent []string
+
+	// Window is the time during which replays are detected. The default is one minute.
+	Window time.Duration
+
+	// MaxEntries is the number of responses kept for answering replays. The default is
+	// 10000.
+	MaxEntries int
+}
+
+// SetReplayProtection enables the detection of replayed requests, such as requests sent
+// again by a load balancer after a connection failure. Calls to non-idempotent methods
+// are identified by their method, call id and the Idempotency-Key header of the HTTP
+// request. Requests without the header are always executed, since the client address
+// seen behind a load balancer doesn't tell clients apart. When the same call arrives
+// again within the window, it isn't executed again: the response of the first execution
+// is returned instead, or a retryable error while the first execution is still running.
+//
+// HTTP responses also carry the X-Rpc-Replay-Safe header, telling proxies whether the
+// request may be retried. Passing a config without non-idempotent methods disables
+// replay protection.
+func (s *Server) SetReplayProtection(p ReplayProtection) {
+	var g *replayGuard
+	if len(p.NonIdempotent) > 0 {
+		if p.Window <= 0 {
+			p.Window = defaultReplayWindow
+		}
+		if p.MaxEntries <= 0 {
+			p.MaxEntries = defaultReplayMaxEntries
+		}
+		g = &replayGuard{
+			cfg:      p,
+			clock:    s.clock,
+			done:     newTTLCache(p.MaxEntries, replayMaxBytes),
+			inflight: make(map[string]struct{}),
+		}
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.replay = g })
+}
+
+type idempotencyKeyKey struct{}
+
+// replaySafety is the replay classification of a response.
+type replaySafety uint8
+
+const (
+	replayUnknown replaySafety = iota
+	replaySafe
+	replayUnsafe
+)
+
+// replayGuard detects replayed calls to non-idempotent methods.
+type replayGuard struct {
+	cfg   ReplayProtection
+	clock mclock.Clock
+	done  *ttlCache // encoded responses of completed calls
+
+	mu       sync.Mutex
+	inflight map[string]struct{}
+}
+
+// safety returns the replay classification of method.
+func (g *replayGuard) safety(method string) replaySafety {
+	if matchMethod(g.cfg.NonIdempotent, method) {
+		return replayUnsafe
+	}
+	return replaySafe
+}
+
+// run executes a call to a non-idempotent method unless it is a replay.
+func (g *replayGuard) run(ctx context.Context, msg *jsonrpcMessage, call func() *jsonrpcMessage) *jsonrpcMessage {
+	key, ok := replayKey(ctx, msg)
+	if !ok {
+		return call()
+	}
+
+	g.mu.Lock()
+	if enc, _, ok := g.done.get(key, g.clock.Now()); ok {
+		g.mu.Unlock()
+		var resp jsonrpcMessage
+		if err := json.Unmarshal(enc, &resp); err == nil {
+			replayedCounter.Inc(1)
+			resp.ID = msg.ID
+			return &resp
+		}
+		return call()
+	}
+	if _, ok := g.inflight[key]; ok {
+		g.mu.Unlock()
+		replayedCounter.Inc(1)
+		return msg.errorResponse(&replayInProgressError{})
+	}
+	g.inflight[key] = struct{}{}
+	g.mu.Unlock()
+
+	var resp *jsonrpcMessage
+	defer func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		delete(g.inflight, key)
+		if resp == nil {
+			return
+		}
+		if enc, err := json.Marshal(resp); err == nil {
+			g.done.put(key, enc, g.cfg.Window, g.clock.Now())
+		}
+	}()
+	resp = call()
+	return resp
+}
+
+// replayKey identifies a call across replays. It returns false if the request has no
+// idempotency key.
+func replayKey(ctx context.Context, msg *jsonrpcMessage) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyKey{}).(string)
+	if !ok || key == "" {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(msg.Method))
+	h.Write([]byte{0})
+	h.Write(msg.ID)
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// responseReplaySafety returns the value of the replay safety header for the response v.
+func responseReplaySafety(v interface{}) replaySafety {
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		return v.replay
+	case []*jsonrpcMessage:
+		safety := replayUnknown
+		for _, msg := range v {
+			if msg != nil {
+				safety = max(safety, msg.replay)
+			}
+		}
+		return safety
+	}
+	return replayUnknown
+}
+
+// replayInProgressError is returned for replays of a call which is still running.
+type replayInProgressError struct{}
+
+func (e *replayInProgressError) ErrorCode() int { return errcodeReplayInProgress }
+
+func (e *replayInProgressError) Error() string { return errMsgReplayInProgress }
+
+func (e *replayInProgressError) ErrorData() interface{} { return retryHintData(0) }
@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type replayTestService struct {
+	calls   atomic.Int32
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (s *replayTestService) Submit(x int) int32 {
+	return s.calls.Add(1)
+}
+
+func (s *replayTestService) Slow() int32 {
+	s.started <- struct{}{}
+	<-s.unblock
+	return s.calls.Add(1)
+}
+
+func TestReplayProtection(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := &replayTestService{started: make(chan struct{}), unblock: make(chan struct{})}
+	if err := server.RegisterName("replay", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetReplayProtection(ReplayProtection{NonIdempotent: []string{"replay_s*"}})
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	post := func(body, idempotencyKey string) (*jsonrpcMessage, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var msg jsonrpcMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("invalid response %q: %v", data, err)
+		}
+		return &msg, resp.Header.Get(ReplaySafeHeader)
+	}
+	expectResult := func(msg *jsonrpcMessage, want string) {
+		t.Helper()
+		if msg.Error != nil || string(msg.Result) != want {
+			t.Fatalf("wrong response: result %s, error %v", msg.Result, msg.Error)
+		}
+	}
+
+	// Without an idempotency key, calls are always executed.
+	submit := `{"jsonrpc":"2.0","id":1,"method":"replay_submit","params":[1]}`
+	resp, safe := post(submit, "")
+	expectResult(resp, "1")
+	if safe != "false" {
+		t.Errorf("wrong %s header %q for non-idempotent call", ReplaySafeHeader, safe)
+	}
+	resp, _ = post(submit, "")
+	expectResult(resp, "2")
+
+	// With an idempotency key, a replayed call is answered with the response of the
+	// first execution. The key identifies the call.
+	resp, _ = post(`{"jsonrpc":"2.0","id":7,"method":"replay_submit","params":[3]}`, "k1")
+	expectResult(resp, "3")
+	resp, _ = post(`{"jsonrpc":"2.0","id":7,"method":"replay_submit","params":[4]}`, "k1")
+	expectResult(resp, "3")
+
+	// Calls with another key are executed.
+	resp, _ = post(`{"jsonrpc":"2.0","id":7,"method":"replay_submit","params":[3]}`, "k2")
+	expectResult(resp, "4")
+
+	// Idempotent calls are marked safe.
+	resp, safe = post(`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`, "k1")
+	if resp.Error != nil || safe != "true" {
+		t.Errorf("wrong %s header %q for idempotent call (error %v)", ReplaySafeHeader, safe, resp.Error)
+	}
+
+	// A replay of a running call fails with a retryable error.
+	slow := `{"jsonrpc":"2.0","id":1,"method":"replay_slow","params":[]}`
+	done := make(chan *jsonrpcMessage)
+	go func() {
+		resp, _ := post(slow, "k3")
+		done <- resp
+	}()
+	<-service.started
+	resp, _ = post(slow, "k3")
+	if resp.Error == nil || resp.Error.Code != errcodeReplayInProgress {
+		t.Fatalf("wrong response for replay of running call: %+v", resp.Error)
+	}
+	close(service.unblock)
+	expectResult(<-done, "5")
+	resp, _ = post(slow, "k3")
+	expectResult(resp, "5")
+}
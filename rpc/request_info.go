@@ -0,0 +1,72 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RequestInfo describes the JSON-RPC request of a method call. It is available to
+// middlewares and method handlers through RequestInfoFromContext, and carries the request
+// as received, so it can be audited or correlated without re-encoding the arguments.
+type RequestInfo struct {
+	// ID is the JSON-RPC id of the request. It is nil for notifications.
+	ID json.RawMessage
+	// Method is the name of the called method.
+	Method string
+	// Params holds the parameters as sent by the client. It must not be modified.
+	Params json.RawMessage
+	// Peer describes the connection on which the request was received.
+	Peer PeerInfo
+	// Transport is the name of the protocol, as in PeerInfo.
+	Transport string
+	// BatchIndex is the position of the call in its batch, or -1 if the call was not
+	// sent in a batch.
+	BatchIndex int
+	// DryRun is set for dry-run calls, see NewContextWithDryRun.
+	DryRun bool
+}
+
+type requestInfoKey struct{}
+
+// withRequestInfo attaches the info of msg to ctx.
+func withRequestInfo(ctx context.Context, msg *jsonrpcMessage) context.Context {
+	info := RequestInfo{
+		ID:         msg.ID,
+		Method:     msg.Method,
+		Params:     msg.Params,
+		Peer:       PeerInfoFromContext(ctx),
+		BatchIndex: -1,
+		DryRun:     IsDryRun(ctx),
+	}
+	info.Transport = info.Peer.Transport
+	if b, ok := BatchInfoFromContext(ctx); ok {
+		info.BatchIndex = b.Index
+	}
+	return context.WithValue(ctx, requestInfoKey{}, &info)
+}
+
+// RequestInfoFromContext returns information about the request of the current method
+// call. The boolean is false if ctx doesn't belong to a call served by the RPC server.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	if !ok {
+		return RequestInfo{}, false
+	}
+	return *info, true
+}
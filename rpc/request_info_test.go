@@ -0,0 +1,88 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRequestInfo(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		infos []RequestInfo
+	)
+	server := newTestServer()
+	defer server.Stop()
+	server.SetMiddlewares([]Middleware{
+		func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+			info, ok := RequestInfoFromContext(ctx)
+			if !ok {
+				t.Errorf("no request info for %s", method)
+			}
+			mu.Lock()
+			infos = append(infos, info)
+			mu.Unlock()
+			return next(ctx, method, args)
+		},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []any{"a", 2}, Result: new(echoResult)},
+		{Method: "test_echo", Args: []any{"b", 3}, Result: new(echoResult)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(infos) != 3 {
+		t.Fatalf("got %d request infos, want 3", len(infos))
+	}
+	want := []struct {
+		params string
+		index  int
+	}{
+		{`["x",1]`, -1},
+		{`["a",2]`, 0},
+		{`["b",3]`, 1},
+	}
+	for i, w := range want {
+		info := infos[i]
+		if info.Method != "test_echo" || string(info.Params) != w.params || info.BatchIndex != w.index {
+			t.Errorf("call %d: wrong request info %+v", i, info)
+		}
+		if len(info.ID) == 0 || info.Transport != "ipc" || info.Peer.Transport != "ipc" {
+			t.Errorf("call %d: missing id or peer: %+v", i, info)
+		}
+	}
+	if string(infos[1].ID) == string(infos[2].ID) {
+		t.Error("batch calls have the same id")
+	}
+
+	if _, ok := RequestInfoFromContext(context.Background()); ok {
+		t.Error("request info in background context")
+	}
+}
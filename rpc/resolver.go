@@ -0,0 +1,375 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Resolver resolves host names of HTTP and websocket endpoints, see WithResolver.
+// *net.Resolver implements this interface.
+type Resolver interface {
+	// LookupNetIP returns the addresses of host. The network is "ip", "ip4" or "ip6".
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// WithResolver makes the client resolve the host names of HTTP and websocket endpoints
+// using r instead of the system resolver. Use NewDoHResolver or NewDoTResolver in
+// environments where local DNS can't be trusted.
+//
+// This option can't be combined with WithHTTPClient, WithWebsocketDialer or
+// WithSOCKSProxy, which resolves names on the proxy.
+func WithResolver(r Resolver) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.resolver = r
+	})
+}
+
+// setupResolver installs an HTTP client and websocket dialer which resolve names using
+// the configured resolver. When multi-address dialing is enabled, the resolver is used
+// by setupEyeballs instead.
+func (cfg *clientConfig) setupResolver() error {
+	if cfg.resolver == nil {
+		return nil
+	}
+	if cfg.socksProxy != nil {
+		return errors.New("resolver can't be used with a SOCKS proxy")
+	}
+	if cfg.eyeballsDelay != 0 {
+		return nil
+	}
+	if cfg.httpClient != nil || cfg.wsDialer != nil {
+		return errors.New("resolver can't be used with a custom HTTP client or websocket dialer")
+	}
+	cfg.setDialer(resolvingDialer(cfg.resolver, cfg.dialTCP()), http.ProxyFromEnvironment)
+	return nil
+}
+
+// resolvingDialer returns a dial function which resolves the host of addr using r and
+// tries its addresses in order.
+func resolvingDialer(r Resolver, dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := netip.ParseAddr(host); err == nil {
+			return dial(ctx, network, addr)
+		}
+		ips, err := r.LookupNetIP(ctx, ipNetwork(network), host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no suitable address found for %s", host)
+		}
+		var firstErr error
+		for _, ip := range ips {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, firstErr
+	}
+}
+
+// ipNetwork returns the lookup network matching a dial network.
+func ipNetwork(network string) string {
+	switch network {
+	case "tcp4", "udp4":
+		return "ip4"
+	case "tcp6", "udp6":
+		return "ip6"
+	default:
+		return "ip"
+	}
+}
+
+const (
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+	dnsClassIN  = 1
+
+	dnsRcodeNameError = 3
+
+	// dnsQueryTimeout limits each query when the context has no deadline.
+	dnsQueryTimeout = 10 * time.Second
+)
+
+// SecureResolver is a Resolver which sends queries to a fixed set of DNS servers over
+// HTTPS (RFC 8484) or TLS (RFC 7858). Servers are tried in order until one answers.
+type SecureResolver struct {
+	servers  []string
+	exchange func(ctx context.Context, server string, query []byte) ([]byte, error)
+}
+
+// NewDoHResolver creates a resolver using DNS-over-HTTPS. The endpoints are URLs such as
+// "https://1.1.1.1/dns-query". Their hosts must be IP addresses, so the resolvers can be
+// reached without DNS.
+//
+// Server certificates are verified with tlsConfig, which may be nil to use the system
+// roots. Set RootCAs or VerifyPeerCertificate in tlsConfig to pin the certificates of
+// the resolvers.
+func NewDoHResolver(endpoints []string, tlsConfig *tls.Config) (*SecureResolver, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no DNS-over-HTTPS endpoints")
+	}
+	for _, e := range endpoints {
+		u, err := url.Parse(e)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("DNS-over-HTTPS endpoint %q is not an https URL", e)
+		}
+		if _, err := netip.ParseAddr(u.Hostname()); err != nil {
+			return nil, fmt.Errorf("host of DNS-over-HTTPS endpoint %q is not an IP address", e)
+		}
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:     tlsConfig.Clone(),
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        len(endpoints),
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+	exchange := func(ctx context.Context, endpoint string, query []byte) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(query))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/dns-message")
+		req.Header.Set("Accept", "application/dns-message")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("DNS-over-HTTPS query failed: %s", resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 65535))
+	}
+	return &SecureResolver{servers: endpoints, exchange: exchange}, nil
+}
+
+// NewDoTResolver creates a resolver using DNS-over-TLS. The servers are given as IP
+// address and port, e.g. "9.9.9.9:853".
+//
+// Server certificates are verified with tlsConfig, which may be nil to use the system
+// roots. The server name defaults to the IP address of the server. Set ServerName,
+// RootCAs or VerifyPeerCertificate in tlsConfig to pin the identity of the resolvers.
+func NewDoTResolver(servers []string, tlsConfig *tls.Config) (*SecureResolver, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("no DNS-over-TLS servers")
+	}
+	for _, s := range servers {
+		if _, err := netip.ParseAddrPort(s); err != nil {
+			return nil, fmt.Errorf("invalid DNS-over-TLS server %q: %v", s, err)
+		}
+	}
+	dialer := &tls.Dialer{Config: tlsConfig}
+	exchange := func(ctx context.Context, server string, query []byte) ([]byte, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", server)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		msg := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+		if _, err := conn.Write(append(msg, query...)); err != nil {
+			return nil, err
+		}
+		var size [2]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return nil, err
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	return &SecureResolver{servers: servers, exchange: exchange}, nil
+}
+
+// LookupNetIP resolves host, querying A and AAAA records as selected by network. IPv4
+// addresses are returned first.
+func (r *SecureResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	var types []uint16
+	switch network {
+	case "ip":
+		types = []uint16{dnsTypeA, dnsTypeAAAA}
+	case "ip4":
+		types = []uint16{dnsTypeA}
+	case "ip6":
+		types = []uint16{dnsTypeAAAA}
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dnsQueryTimeout)
+		defer cancel()
+	}
+	var addrs []netip.Addr
+	for _, qtype := range types {
+		found, err := r.lookup(ctx, host, qtype)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, found...)
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+// lookup queries records of one type, trying the servers in order.
+func (r *SecureResolver) lookup(ctx context.Context, host string, qtype uint16) ([]netip.Addr, error) {
+	query, id, err := newDNSQuery(host, qtype)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, server := range r.servers {
+		resp, err := r.exchange(ctx, server, query)
+		if err == nil {
+			var addrs []netip.Addr
+			if addrs, err = parseDNSResponse(resp, id, qtype); err == nil {
+				return addrs, nil
+			}
+		}
+		if firstErr == nil {
+			firstErr = &net.DNSError{Err: err.Error(), Name: host, Server: server}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// newDNSQuery encodes a recursive query for records of the given type.
+func newDNSQuery(host string, qtype uint16) (msg []byte, id uint16, err error) {
+	var idbuf [2]byte
+	rand.Read(idbuf[:])
+	id = binary.BigEndian.Uint16(idbuf[:])
+	msg = binary.BigEndian.AppendUint16(msg, id)
+	msg = append(msg, 0x01, 0x00) // recursion desired
+	msg = append(msg, 0, 1, 0, 0, 0, 0, 0, 0)
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, 0, fmt.Errorf("invalid host name %q", host)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, qtype)
+	msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+	return msg, id, nil
+}
+
+var errDNSMessage = errors.New("invalid DNS response")
+
+// parseDNSResponse returns the addresses of the given type in the answer section of a
+// DNS response. Other records, such as CNAMEs, are skipped.
+func parseDNSResponse(msg []byte, id uint16, qtype uint16) ([]netip.Addr, error) {
+	if len(msg) < 12 || binary.BigEndian.Uint16(msg) != id {
+		return nil, errDNSMessage
+	}
+	switch rcode := msg[3] & 0x0f; rcode {
+	case 0, dnsRcodeNameError:
+		// An unknown name has no records.
+	default:
+		return nil, fmt.Errorf("DNS server returned error code %d", rcode)
+	}
+	qdcount := binary.BigEndian.Uint16(msg[4:])
+	ancount := binary.BigEndian.Uint16(msg[6:])
+	off := 12
+	for i := 0; i < int(qdcount); i++ {
+		var ok bool
+		if off, ok = skipDNSName(msg, off); !ok || off+4 > len(msg) {
+			return nil, errDNSMessage
+		}
+		off += 4
+	}
+	var addrs []netip.Addr
+	for i := 0; i < int(ancount); i++ {
+		var ok bool
+		if off, ok = skipDNSName(msg, off); !ok || off+10 > len(msg) {
+			return nil, errDNSMessage
+		}
+		rtype := binary.BigEndian.Uint16(msg[off:])
+		class := binary.BigEndian.Uint16(msg[off+2:])
+		rdlen := int(binary.BigEndian.Uint16(msg[off+8:]))
+		off += 10
+		if off+rdlen > len(msg) {
+			return nil, errDNSMessage
+		}
+		if rtype == qtype && class == dnsClassIN {
+			if addr, ok := netip.AddrFromSlice(msg[off : off+rdlen]); ok {
+				addrs = append(addrs, addr)
+			}
+		}
+		off += rdlen
+	}
+	return addrs, nil
+}
+
+// skipDNSName returns the offset after the name starting at off.
+func skipDNSName(msg []byte, off int) (int, bool) {
+	for off < len(msg) {
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			return off + 1, true
+		case n&0xc0 == 0xc0:
+			// Compression pointer, which ends the name.
+			return off + 2, off+2 <= len(msg)
+		default:
+			off += 1 + n
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// testDNSAnswer answers a DNS query using the given records.
+func testDNSAnswer(query []byte, records map[string][]netip.Addr) []byte {
+	// Decode the question name.
+	var labels []string
+	off := 12
+	for query[off] != 0 {
+		n := int(query[off])
+		labels = append(labels, string(query[off+1:off+1+n]))
+		off += 1 + n
+	}
+	qtype := binary.BigEndian.Uint16(query[off+1:])
+	question := query[12 : off+5]
+
+	var answers [][]byte
+	for _, ip := range records[strings.Join(labels, ".")] {
+		if (qtype == dnsTypeA) != ip.Is4() {
+			continue
+		}
+		rr := []byte{0xc0, 12} // pointer to question name
+		rr = binary.BigEndian.AppendUint16(rr, qtype)
+		rr = binary.BigEndian.AppendUint16(rr, dnsClassIN)
+		rr = binary.BigEndian.AppendUint32(rr, 60)
+		rr = binary.BigEndian.AppendUint16(rr, uint16(ip.BitLen()/8))
+		answers = append(answers, append(rr, ip.AsSlice()...))
+	}
+	resp := append([]byte{}, query[:2]...)
+	resp = append(resp, 0x81, 0x80)
+	if _, ok := records[strings.Join(labels, ".")]; !ok {
+		resp[3] |= dnsRcodeNameError
+	}
+	resp = append(resp, 0, 1)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(answers)))
+	resp = append(resp, 0, 0, 0, 0)
+	resp = append(resp, question...)
+	for _, a := range answers {
+		resp = append(resp, a...)
+	}
+	return resp
+}
+
+func newTestDoHServer(t *testing.T, records map[string][]netip.Addr) (*httptest.Server, *tls.Config) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "wrong content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		query, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(testDNSAnswer(query, records))
+	}))
+	t.Cleanup(srv.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	return srv, &tls.Config{RootCAs: roots}
+}
+
+func TestDoHResolver(t *testing.T) {
+	t.Parallel()
+
+	doh, tlsConfig := newTestDoHServer(t, map[string][]netip.Addr{
+		"rpc.test": {netip.MustParseAddr("127.0.0.1")},
+	})
+	resolver, err := NewDoHResolver([]string{doh.URL + "/dns-query"}, tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	_, port, _ := net.SplitHostPort(httpsrv.Listener.Addr().String())
+
+	client, err := DialOptions(context.Background(), "http://rpc.test:"+port, WithResolver(resolver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown names fail.
+	_, err = resolver.LookupNetIP(context.Background(), "ip", "unknown.test")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("wrong error for unknown name: %v", err)
+	}
+
+	// The resolver certificate is verified.
+	untrusted, _ := NewDoHResolver([]string{doh.URL + "/dns-query"}, nil)
+	if _, err := untrusted.LookupNetIP(context.Background(), "ip", "rpc.test"); err == nil {
+		t.Fatal("lookup succeeded with untrusted resolver certificate")
+	}
+}
+
+func TestDoTResolver(t *testing.T) {
+	t.Parallel()
+
+	records := map[string][]netip.Addr{
+		"rpc.test": {netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("::1")},
+	}
+	// Borrow the certificate of an httptest server.
+	doh, tlsConfig := newTestDoHServer(t, records)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", doh.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			var size [2]byte
+			if _, err := io.ReadFull(conn, size[:]); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(size[:]))
+				io.ReadFull(conn, query)
+				resp := testDNSAnswer(query, records)
+				conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+			}
+			conn.Close()
+		}
+	}()
+
+	// The first server is unreachable.
+	dead, _ := net.Listen("tcp", "127.0.0.1:0")
+	deadAddr := dead.Addr().String()
+	dead.Close()
+	resolver, err := NewDoTResolver([]string{deadAddr, ln.Addr().String()}, tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs, err := resolver.LookupNetIP(context.Background(), "ip", "rpc.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 2 || addrs[0] != netip.MustParseAddr("127.0.0.1") || addrs[1] != netip.MustParseAddr("::1") {
+		t.Fatalf("wrong addresses %v", addrs)
+	}
+	addrs, _ = resolver.LookupNetIP(context.Background(), "ip6", "rpc.test")
+	if len(addrs) != 1 || !addrs[0].Is6() {
+		t.Fatalf("wrong IPv6 addresses %v", addrs)
+	}
+}
+
+func TestResolverConfig(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewDoHResolver([]string{"https://dns.example/dns-query"}, nil); err == nil {
+		t.Error("DoH resolver accepted host name")
+	}
+	if _, err := NewDoTResolver([]string{"dns.example:853"}, nil); err == nil {
+		t.Error("DoT resolver accepted host name")
+	}
+	_, err := DialOptions(context.Background(), "http://rpc.test:8545",
+		WithResolver(net.DefaultResolver), WithSOCKSProxy(SOCKSProxy{Addr: "127.0.0.1:1080"}))
+	if err == nil {
+		t.Error("resolver accepted with SOCKS proxy")
+	}
+}
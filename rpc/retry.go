@@ -0,0 +1,263 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RetryPolicy configures automatic retries of failed calls, see WithRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts int           // total number of attempts, including the first (default 3)
+	Backoff     time.Duration // delay before the first retry, doubled on every retry (default 100ms)
+	MaxBackoff  time.Duration // upper bound of the backoff delay (default 5s)
+
+	// UseErrorCatalog makes the client also retry errors whose code is declared
+	// retryable by the server's rpc_errorCatalog method. The catalog is fetched once,
+	// when the first retry decision needs it.
+	UseErrorCatalog bool
+
+	// RetryTransport makes the client also retry calls which failed without an answer
+	// from the server, e.g. because the connection was lost or the server responded
+	// with an HTTP 5xx status. Only idempotent methods are retried this way, see
+	// WithNonIdempotentMethods.
+	RetryTransport bool
+
+	// RetryCodes lists error codes which are retried although the server doesn't mark
+	// them retryable. Only idempotent methods are retried this way.
+	RetryCodes []int
+}
+
+// WithRetryPolicy makes the client retry calls made through CallContext when the server
+// marks the error as retryable. An error is considered retryable if
+//
+//   - its error data contains "retryable": true (see RetryableError), or
+//   - it is an HTTP 429 or 503 response carrying a Retry-After header, or
+//   - UseErrorCatalog is set and the server's error catalog declares the code retryable, or
+//   - the method is idempotent and the error is a transport error (see RetryTransport)
+//     or has one of the RetryCodes.
+//
+// Retry delays requested by the server through "retryAfterMs" or Retry-After take
+// precedence over the backoff, but are limited to MaxBackoff. Batch calls, notifications
+// and subscriptions are not retried.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.retryPolicy = &p
+	})
+}
+
+// RetryableError marks err as retryable. When returned by a method, the error data sent
+// to the client contains "retryable": true and, if after is positive, the delay after
+// which the call should be repeated as "retryAfterMs". The error code of err is kept.
+func RetryableError(err error, after time.Duration) error {
+	return &retryableError{err: err, after: after}
+}
+
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (e *retryableError) Unwrap() error { return e.err }
+
+func (e *retryableError) ErrorCode() int {
+	var rpcErr Error
+	if errors.As(e.err, &rpcErr) {
+		return rpcErr.ErrorCode()
+	}
+	return errcodeDefault
+}
+
+func (e *retryableError) ErrorData() interface{} {
+	return retryHintData(e.after)
+}
+
+// retryHintData returns the error data marking an error retryable.
+func retryHintData(after time.Duration) map[string]interface{} {
+	data := map[string]interface{}{"retryable": true}
+	if after > 0 {
+		data["retryAfterMs"] = after.Milliseconds()
+	}
+	return data
+}
+
+// WithNonIdempotentMethods declares methods which must not be sent twice, e.g.
+// "eth_sendTransaction". Patterns ending in "*" match all methods with the prefix. All
+// other methods are considered idempotent. Calls of non-idempotent methods are neither
+// hedged nor retried after transport errors or errors listed in RetryPolicy.RetryCodes,
+// but they are still retried when the server marks the error retryable, since that
+// means the call wasn't executed.
+func WithNonIdempotentMethods(patterns ...string) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.nonIdempotent = append(cfg.nonIdempotent, patterns...)
+	})
+}
+
+// isIdempotent reports whether method may be sent more than once.
+func (c *Client) isIdempotent(method string) bool {
+	return !matchMethod(c.nonIdempotent, method)
+}
+
+// retrier implements the retry policy of a client.
+type retrier struct {
+	policy RetryPolicy
+
+	catalogOnce sync.Once
+	retryable   map[int]bool // codes declared retryable by the server's error catalog
+}
+
+// do runs call of method until it succeeds, fails with a non-retryable error, or the
+// attempts are exhausted.
+func (r *retrier) do(ctx context.Context, c *Client, method string, call func() error) error {
+	backoff := r.policy.Backoff
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= r.policy.MaxAttempts {
+			return err
+		}
+		after, ok := r.retryHint(ctx, c, method, err)
+		if !ok {
+			return err
+		}
+		delay := max(after, backoff)
+		backoff = min(2*backoff, r.policy.MaxBackoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := c.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C():
+		}
+	}
+}
+
+// retryHint reports whether err may be retried, and the delay requested by the server.
+func (r *retrier) retryHint(ctx context.Context, c *Client, method string, err error) (time.Duration, bool) {
+	if after, ok := r.serverHint(ctx, c, err); ok {
+		return after, true
+	}
+	if !c.isIdempotent(method) {
+		return 0, false
+	}
+	if r.policy.RetryTransport && isFailoverError(err) && !errors.Is(err, ErrClientQuit) {
+		return 0, true
+	}
+	var rpcErr Error
+	if errors.As(err, &rpcErr) && slices.Contains(r.policy.RetryCodes, rpcErr.ErrorCode()) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// serverHint reports whether the server marked err retryable, and the delay it
+// requested.
+func (r *retrier) serverHint(ctx context.Context, c *Client, err error) (time.Duration, bool) {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.StatusCode != http.StatusTooManyRequests && httpErr.StatusCode != http.StatusServiceUnavailable {
+			return 0, false
+		}
+		return parseRetryAfter(httpErr.Header.Get("Retry-After"), r.policy.MaxBackoff)
+	}
+
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) {
+		return 0, false
+	}
+	var dataErr DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(map[string]interface{}); ok {
+			if retryable, _ := data["retryable"].(bool); retryable {
+				ms, _ := data["retryAfterMs"].(float64)
+				after, _ := retryDelay(ms, time.Millisecond, r.policy.MaxBackoff)
+				return after, true
+			}
+		}
+	}
+	if r.policy.UseErrorCatalog {
+		r.catalogOnce.Do(func() { r.loadCatalog(ctx, c) })
+		if r.retryable[rpcErr.ErrorCode()] {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// loadCatalog fetches the error catalog of the server.
+func (r *retrier) loadCatalog(ctx context.Context, c *Client) {
+	var catalog []ErrorCatalogEntry
+	if err := c.callContext(ctx, &catalog, "rpc_errorCatalog"); err != nil {
+		log.Debug("Could not fetch RPC error catalog", "err", err)
+		return
+	}
+	r.retryable = make(map[int]bool)
+	for _, e := range catalog {
+		if e.Retryable {
+			r.retryable[e.Code] = true
+		}
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of
+// seconds or an HTTP date. The delay is limited to maxDelay.
+func parseRetryAfter(v string, maxDelay time.Duration) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return retryDelay(float64(secs), time.Second, maxDelay)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return min(max(time.Until(t), 0), maxDelay), true
+	}
+	return 0, false
+}
+
+// retryDelay converts a delay of n units requested by the server, limiting it to
+// maxDelay. NaN and negative values are rejected.
+func retryDelay(n float64, unit, maxDelay time.Duration) (time.Duration, bool) {
+	if math.IsNaN(n) || n < 0 {
+		return 0, false
+	}
+	if n >= float64(maxDelay)/float64(unit) {
+		return maxDelay, true
+	}
+	return time.Duration(n * float64(unit)), true
+}
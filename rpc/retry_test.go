@@ -0,0 +1,288 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type retryTimeoutError struct{}
+
+func (retryTimeoutError) Error() string  { return "busy" }
+func (retryTimeoutError) ErrorCode() int { return errcodeTimeout }
+
+type retryTestService struct {
+	calls    atomic.Int64
+	failures int64
+	err      func() error
+}
+
+func (s *retryTestService) Flaky() (int64, error) {
+	n := s.calls.Add(1)
+	if n <= s.failures {
+		return 0, s.err()
+	}
+	return n, nil
+}
+
+func TestRetryPolicyHint(t *testing.T) {
+	t.Parallel()
+
+	svc := &retryTestService{failures: 2, err: func() error {
+		return RetryableError(errors.New("busy"), 5*time.Millisecond)
+	}}
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("retry", svc)
+	client := dialInProcWithOptions(server, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond}))
+	defer client.Close()
+
+	var n int64
+	if err := client.Call(&n, "retry_flaky"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("call succeeded on attempt %d, want 3", n)
+	}
+}
+
+func TestRetryPolicyAttemptsExhausted(t *testing.T) {
+	t.Parallel()
+
+	svc := &retryTestService{failures: 10, err: func() error {
+		return RetryableError(errors.New("busy"), 0)
+	}}
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("retry", svc)
+	client := dialInProcWithOptions(server, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	defer client.Close()
+
+	err := client.Call(nil, "retry_flaky")
+	var dataErr DataError
+	if !errors.As(err, &dataErr) || err.Error() != "busy" {
+		t.Fatalf("expected retryable error, got %v", err)
+	}
+	if calls := svc.calls.Load(); calls != 3 {
+		t.Fatalf("method called %d times, want 3", calls)
+	}
+}
+
+func TestRetryPolicyNotRetryable(t *testing.T) {
+	t.Parallel()
+
+	svc := &retryTestService{failures: 1, err: func() error { return retryTimeoutError{} }}
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("retry", svc)
+	client := dialInProcWithOptions(server, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	defer client.Close()
+
+	if err := client.Call(nil, "retry_flaky"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls := svc.calls.Load(); calls != 1 {
+		t.Fatalf("method called %d times, want 1", calls)
+	}
+}
+
+func TestRetryPolicyErrorCatalog(t *testing.T) {
+	t.Parallel()
+
+	svc := &retryTestService{failures: 1, err: func() error { return retryTimeoutError{} }}
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("retry", svc)
+	client := dialInProcWithOptions(server, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, UseErrorCatalog: true}))
+	defer client.Close()
+
+	var n int64
+	if err := client.Call(&n, "retry_flaky"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("call succeeded on attempt %d, want 2", n)
+	}
+}
+
+func TestRetryPolicyCodes(t *testing.T) {
+	t.Parallel()
+
+	svc := &retryTestService{failures: 2, err: func() error { return retryTimeoutError{} }}
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, RetryCodes: []int{errcodeTimeout}}
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("retry", svc)
+	client := dialInProcWithOptions(server, WithRetryPolicy(policy))
+	defer client.Close()
+
+	var n int64
+	if err := client.Call(&n, "retry_flaky"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("call succeeded on attempt %d, want 3", n)
+	}
+
+	// Non-idempotent methods aren't retried.
+	svc = &retryTestService{failures: 2, err: func() error { return retryTimeoutError{} }}
+	server = newTestServer()
+	defer server.Stop()
+	server.RegisterName("retry", svc)
+	client = dialInProcWithOptions(server, WithNonIdempotentMethods("retry_*"), WithRetryPolicy(policy))
+	defer client.Close()
+	if err := client.Call(nil, "retry_flaky"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls := svc.calls.Load(); calls != 1 {
+		t.Fatalf("method called %d times, want 1", calls)
+	}
+}
+
+func TestRetryPolicyTransport(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	var requests atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, RetryTransport: true}
+	client, err := DialOptions(context.Background(), ts.URL, WithRetryPolicy(policy), WithNonIdempotentMethods("test_repeat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("got %d requests, want 3", n)
+	}
+
+	// Non-idempotent methods aren't retried after transport errors.
+	requests.Store(0)
+	err = client.Call(nil, "test_repeat", "x", 1)
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected HTTP error, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("got %d requests, want 1", n)
+	}
+}
+
+func TestRetryPolicyHTTPRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	var requests atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case 2:
+			// No Retry-After header, not retryable.
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		default:
+			server.ServeHTTP(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := DialOptions(context.Background(), ts.URL, WithRetryPolicy(RetryPolicy{Backoff: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	err = client.Call(&result, "test_echo", "x", 1)
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected HTTP error, got %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("got %d requests, want 2", n)
+	}
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	if d, ok := parseRetryAfter("3", time.Minute); !ok || d != 3*time.Second {
+		t.Errorf("seconds: got %v %t", d, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date, 2*time.Hour); !ok || d < 59*time.Minute {
+		t.Errorf("date: got %v %t", d, ok)
+	}
+	// Delays are limited, and must not overflow.
+	for _, v := range []string{"86400000", "9223372036854775807", date} {
+		if d, ok := parseRetryAfter(v, 5*time.Second); !ok || d != 5*time.Second {
+			t.Errorf("%q: got %v %t, want clamped delay", v, d, ok)
+		}
+	}
+	for _, v := range []string{"", "-1", "soon"} {
+		if _, ok := parseRetryAfter(v, time.Minute); ok {
+			t.Errorf("%q: expected failure", v)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		n    float64
+		want time.Duration
+		ok   bool
+	}{
+		{250, 250 * time.Millisecond, true},
+		{1e300, 5 * time.Second, true},
+		{math.MaxInt64, 5 * time.Second, true},
+		{-1, 0, false},
+		{math.NaN(), 0, false},
+	}
+	for _, test := range tests {
+		d, ok := retryDelay(test.n, time.Millisecond, 5*time.Second)
+		if d != test.want || ok != test.ok {
+			t.Errorf("%v: got %v %t, want %v %t", test.n, d, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route is an HTTP endpoint of the server, see Server.Router.
+type Route struct {
+	// Prefix is the URL path prefix of the route, e.g. "/admin". It matches the path
+	// itself and all paths below it. The empty prefix matches all paths.
+	Prefix string
+
+	// Hosts lists the virtual hosts served by the route, matched case-insensitively
+	// against the host of the request without port. An empty list or "*" matches all
+	// hosts.
+	Hosts []string
+
+	// CORSOrigins lists the origins allowed to make cross-origin requests to the route,
+	// and to open websocket connections. "*" allows all origins. Without origins, no
+	// CORS headers are sent and websocket connections are only accepted from localhost.
+	CORSOrigins []string
+
+	// Namespaces and Methods restrict the methods available on the route. Methods are
+	// matched like ACL rules, i.e. a pattern ending in '*' matches all methods with the
+	// given prefix. Calls of other methods fail with the "method not found" error. If
+	// both are empty, all methods are available.
+	Namespaces []string
+	Methods    []string
+
+	// Authenticate determines the principal of requests to the route, in place of the
+	// authenticator of the server ACL. If set, requests for which it returns no
+	// principal are rejected with status 401.
+	Authenticate Authenticator
+
+	// Websocket enables websocket connections on the route.
+	Websocket bool
+}
+
+// httpRoute is a route of a router.
+type httpRoute struct {
+	Route
+	ws http.Handler
+}
+
+// allows reports whether method can be called on the route.
+func (r *httpRoute) allows(method string) bool {
+	if r == nil || (len(r.Namespaces) == 0 && len(r.Methods) == 0) {
+		return true
+	}
+	namespace, _, _ := strings.Cut(method, serviceMethodSeparator)
+	return slices.Contains(r.Namespaces, namespace) || matchMethod(r.Methods, method)
+}
+
+// matches reports whether the route serves the given host and path.
+func (r *httpRoute) matches(host, path string) bool {
+	if len(r.Hosts) > 0 && !slices.ContainsFunc(r.Hosts, func(h string) bool {
+		return h == "*" || strings.EqualFold(h, host)
+	}) {
+		return false
+	}
+	prefix := strings.TrimSuffix(r.Prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// authenticate determines the principal of a request to the route, see
+// Route.Authenticate.
+func (r *httpRoute) authenticate(req *http.Request, p *Principal, fail func(msg string)) (*Principal, bool) {
+	if r == nil || r.Authenticate == nil {
+		return p, true
+	}
+	p, err := r.Authenticate(req)
+	if err == nil && p == nil {
+		err = errors.New("no credentials")
+	}
+	if err != nil {
+		fail("authentication failed: " + err.Error())
+		return nil, false
+	}
+	return p, true
+}
+
+// Router returns an HTTP handler serving the given routes, which allows exposing
+// multiple endpoints with their own CORS policy and methods on a single listener. For
+// example, a route with prefix "/public" could offer a subset of the eth namespace
+// to all origins, while the debug namespace is available on "/admin" to authenticated
+// clients only.
+//
+// A request is served by the route with the longest prefix among the routes matching
+// its host. Requests matching no route are answered with status 404, except for the
+// health and readiness endpoints, see HealthPath. The route of a
+// call is available to methods as PeerInfo.Route.
+//
+// All routes share the configuration of the server, e.g. the token validator and ACL
+// also apply to the calls received on the routes.
+func (s *Server) Router(routes ...Route) (http.Handler, error) {
+	table := make([]*httpRoute, 0, len(routes))
+	for _, route := range routes {
+		if route.Prefix != "" && !strings.HasPrefix(route.Prefix, "/") {
+			return nil, fmt.Errorf("invalid route prefix %q", route.Prefix)
+		}
+		r := &httpRoute{Route: route}
+		r.Hosts = slices.Clone(route.Hosts)
+		r.CORSOrigins = slices.Clone(route.CORSOrigins)
+		r.Namespaces = slices.Clone(route.Namespaces)
+		r.Methods = slices.Clone(route.Methods)
+		if r.Websocket {
+			r.ws = s.websocketHandler(r.CORSOrigins, r)
+		}
+		table = append(table, r)
+	}
+	// Longer prefixes are tried first.
+	sort.SliceStable(table, func(i, j int) bool {
+		return len(strings.TrimSuffix(table[i].Prefix, "/")) > len(strings.TrimSuffix(table[j].Prefix, "/"))
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.serveHealthPath(w, r) {
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		for _, route := range table {
+			if route.matches(host, r.URL.Path) {
+				route.serveHTTP(s, w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}), nil
+}
+
+func (r *httpRoute) serveHTTP(s *Server, w http.ResponseWriter, req *http.Request) {
+	if r.ws != nil && isWebsocketUpgrade(req) {
+		r.ws.ServeHTTP(w, req)
+		return
+	}
+	if origin := req.Header.Get("Origin"); origin != "" && len(r.CORSOrigins) > 0 {
+		allowed := slices.Contains(r.CORSOrigins, "*") || slices.ContainsFunc(r.CORSOrigins, func(o string) bool {
+			return strings.EqualFold(o, origin)
+		})
+		w.Header().Add("Vary", "Origin")
+		preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if preflight {
+			if !allowed {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if headers := req.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	s.serveHTTP(w, req, r)
+}
+
+// corsMaxAge is the time in seconds browsers may cache the result of a preflight
+// request.
+const corsMaxAge = 600
+
+// isWebsocketUpgrade reports whether r is a websocket handshake request.
+func isWebsocketUpgrade(r *http.Request) bool {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, v := range strings.Split(r.Header.Get("Connection"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "upgrade") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testRoutes are the routes used by the router tests.
+var testRoutes = []Route{
+	{Prefix: "/public", CORSOrigins: []string{"https://app.example.com"}, Methods: []string{"test_echo", "test_peer*"}},
+	{
+		Prefix:     "/admin",
+		Namespaces: []string{"test", "nftest"},
+		Websocket:  true,
+		Authenticate: func(r *http.Request) (*Principal, error) {
+			if r.Header.Get("X-Admin") == "" {
+				return nil, nil
+			}
+			return &Principal{Name: "admin"}, nil
+		},
+	},
+	{Hosts: []string{"internal.example.com"}},
+}
+
+func TestRouterMethods(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	router, err := server.Router(testRoutes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(router)
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL + "/public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Route != "/public" {
+		t.Fatalf("wrong route %q", info.Route)
+	}
+	err = client.Call(nil, "test_returnError")
+	wantCallError(t, err, -32601)
+}
+
+func TestRouterAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	router, err := server.Router(testRoutes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(router)
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL + "/admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var httpErr HTTPError
+	if err := client.Call(nil, "test_echo", "x", 1); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 error, got %v", err)
+	}
+	client.SetHeader("X-Admin", "1")
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Principal == nil || info.Principal.Name != "admin" {
+		t.Fatalf("wrong principal %+v", info.Principal)
+	}
+}
+
+func TestRouterWebsocket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	router, err := server.Router(testRoutes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(router)
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	header := make(http.Header)
+	header.Set("X-Admin", "1")
+	client, err := DialOptions(context.Background(), wsURL+"/admin", WithHeaders(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Transport != "ws" || info.Route != "/admin" {
+		t.Fatalf("wrong peer info %+v", info)
+	}
+
+	// Websocket connections are not enabled on the public route.
+	if _, err := DialWebsocket(context.Background(), wsURL+"/public", ""); err == nil {
+		t.Fatal("websocket connection to public route succeeded")
+	}
+}
+
+func TestRouterMatching(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	router, err := server.Router(testRoutes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(router)
+	defer httpsrv.Close()
+
+	post := func(host, path string) int {
+		req, _ := http.NewRequest(http.MethodPost, httpsrv.URL+path, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`))
+		req.Header.Set("Content-Type", "application/json")
+		if host != "" {
+			req.Host = host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	tests := []struct {
+		host, path string
+		want       int
+	}{
+		{"", "/public", http.StatusOK},
+		{"", "/public/v1", http.StatusOK},
+		{"", "/publicity", http.StatusNotFound},
+		{"", "/", http.StatusNotFound},
+		{"internal.example.com:8545", "/", http.StatusOK},
+		{"INTERNAL.example.com", "/other", http.StatusOK},
+	}
+	for _, test := range tests {
+		if got := post(test.host, test.path); got != test.want {
+			t.Errorf("host %q path %q: got status %d, want %d", test.host, test.path, got, test.want)
+		}
+	}
+}
+
+func TestRouterCORS(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	router, err := server.Router(testRoutes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(router)
+	defer httpsrv.Close()
+
+	preflight := func(origin string) *http.Response {
+		req, _ := http.NewRequest(http.MethodOptions, httpsrv.URL+"/public", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	resp := preflight("https://app.example.com")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("wrong preflight status %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Fatalf("wrong Access-Control-Allow-Origin %q", got)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Headers"); got != "content-type" {
+		t.Fatalf("wrong Access-Control-Allow-Headers %q", got)
+	}
+	if resp := preflight("https://evil.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("wrong preflight status for disallowed origin %d", resp.StatusCode)
+	}
+}
+
+func TestRouterInvalidPrefix(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if _, err := server.Router(Route{Prefix: "admin"}); err == nil {
+		t.Fatal("expected error for prefix without leading slash")
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SchemaDriftKind is the kind of a difference between a result and its Go type.
+type SchemaDriftKind int
+
+const (
+	// SchemaUnknownField is reported for object members which don't correspond to a
+	// field of the Go struct, e.g. because the server added or renamed a field.
+	SchemaUnknownField SchemaDriftKind = iota
+
+	// SchemaTypeMismatch is reported for values whose JSON type can't be decoded into
+	// the Go type, e.g. a number sent for a string field.
+	SchemaTypeMismatch
+)
+
+func (k SchemaDriftKind) String() string {
+	switch k {
+	case SchemaUnknownField:
+		return "unknown field"
+	case SchemaTypeMismatch:
+		return "type mismatch"
+	default:
+		return fmt.Sprintf("SchemaDriftKind(%d)", int(k))
+	}
+}
+
+// SchemaDrift describes a difference between a result sent by the server and the Go
+// type it is decoded into.
+type SchemaDrift struct {
+	Method string          // method which returned the result
+	Type   reflect.Type    // Go type of the result
+	Kind   SchemaDriftKind // kind of difference
+	Path   string          // location of the value in the result, e.g. "txs[].gas"
+	Detail string          // the expected Go type for mismatches
+}
+
+func (d SchemaDrift) String() string {
+	s := fmt.Sprintf("%s: %s at %q of %v", d.Method, d.Kind, d.Path, d.Type)
+	if d.Detail != "" {
+		s += " (" + d.Detail + ")"
+	}
+	return s
+}
+
+// SchemaDriftError is returned for results of strict types which don't match their Go
+// type, see SchemaDriftConfig.
+type SchemaDriftError struct {
+	Drifts []SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	if len(e.Drifts) == 1 {
+		return "result schema drift: " + e.Drifts[0].String()
+	}
+	return fmt.Sprintf("result schema drift: %s (and %d more)", e.Drifts[0], len(e.Drifts)-1)
+}
+
+// SchemaDriftConfig configures the detection of schema drift in results, see
+// WithSchemaDrift.
+type SchemaDriftConfig struct {
+	// Report is called for every difference found in a result. Differences are
+	// reported once per result and path: members of array elements are reported with
+	// "[]" in their path.
+	Report func(SchemaDrift)
+
+	// Strict lists result types which are decoded strictly: results of these types
+	// which have unknown fields fail to decode with a *SchemaDriftError, like
+	// json.Decoder.DisallowUnknownFields. Types are given as values of the type or
+	// pointers to it, e.g. (*Block)(nil).
+	Strict []interface{}
+}
+
+// WithSchemaDrift makes the client compare the results of calls, batch calls and
+// subscription notifications with the Go types they are decoded into, so that changes
+// of the server's result schema are noticed before they break users. Results are
+// still decoded like encoding/json does, which ignores unknown fields, unless their
+// type is listed in cfg.Strict.
+//
+// Values of types implementing json.Unmarshaler or encoding.TextUnmarshaler, and
+// interface values, are not inspected.
+func WithSchemaDrift(cfg SchemaDriftConfig) ClientOption {
+	return optionFunc(func(c *clientConfig) {
+		c.schemaDrift = &cfg
+	})
+}
+
+// driftDetector implements SchemaDriftConfig.
+type driftDetector struct {
+	report func(SchemaDrift)
+	strict map[reflect.Type]bool
+}
+
+func newDriftDetector(cfg SchemaDriftConfig) *driftDetector {
+	d := &driftDetector{report: cfg.Report, strict: make(map[reflect.Type]bool)}
+	for _, v := range cfg.Strict {
+		d.strict[derefType(reflect.TypeOf(v))] = true
+	}
+	return d
+}
+
+// check compares data, the result of method, with the type of v. It returns an error
+// if the result has unknown fields and its type is strict.
+func (d *driftDetector) check(method string, data json.RawMessage, v interface{}) error {
+	typ := derefType(reflect.TypeOf(v))
+	if typ == nil {
+		return nil
+	}
+	w := driftWalker{seen: make(map[string]bool)}
+	w.walk(data, typ, "")
+	if len(w.drifts) == 0 {
+		return nil
+	}
+	var strictErr *SchemaDriftError
+	for _, drift := range w.drifts {
+		drift.Method, drift.Type = method, typ
+		if d.report != nil {
+			d.report(drift)
+		}
+		if drift.Kind == SchemaUnknownField && d.strict[typ] {
+			if strictErr == nil {
+				strictErr = new(SchemaDriftError)
+			}
+			strictErr.Drifts = append(strictErr.Drifts, drift)
+		}
+	}
+	if strictErr != nil {
+		return strictErr
+	}
+	return nil
+}
+
+// decodeResult decodes the result of method into v.
+func (c *Client) decodeResult(method string, data json.RawMessage, v interface{}) error {
+	if c.drift != nil {
+		if err := c.drift.check(method, data, v); err != nil {
+			return err
+		}
+	}
+	if raw, ok := v.(*json.RawMessage); ok && len(data) > 0 {
+		// data was validated when the response was decoded.
+		*raw = append((*raw)[:0], data...)
+		return nil
+	}
+	return c.numberPolicy.unmarshal(data, v)
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// driftWalker collects the differences between a JSON value and a Go type.
+type driftWalker struct {
+	drifts []SchemaDrift
+	seen   map[string]bool // reported paths
+}
+
+func (w *driftWalker) add(kind SchemaDriftKind, path, detail string) {
+	if w.seen[path] {
+		return
+	}
+	w.seen[path] = true
+	w.drifts = append(w.drifts, SchemaDrift{Kind: kind, Path: path, Detail: detail})
+}
+
+func (w *driftWalker) walk(data json.RawMessage, t reflect.Type, path string) {
+	t = derefType(t)
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) || t.Kind() == reflect.Interface {
+		return
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return
+	}
+	mismatch := func() { w.add(SchemaTypeMismatch, path, t.String()) }
+	switch t.Kind() {
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if data[0] != '{' || json.Unmarshal(data, &members) != nil {
+			mismatch()
+			return
+		}
+		fields := cachedJSONFields(t)
+		for name, value := range members {
+			f, ok := fields.lookup(name)
+			if !ok {
+				w.add(SchemaUnknownField, joinDriftPath(path, name), "")
+				continue
+			}
+			if f.quoted && len(value) > 0 && value[0] == '"' {
+				continue
+			}
+			w.walk(value, f.typ, joinDriftPath(path, name))
+		}
+	case reflect.Map:
+		var members map[string]json.RawMessage
+		if data[0] != '{' || json.Unmarshal(data, &members) != nil {
+			mismatch()
+			return
+		}
+		for _, value := range members {
+			w.walk(value, t.Elem(), path+"{}")
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && data[0] == '"' {
+			return // []byte is encoded as base64 string
+		}
+		var elems []json.RawMessage
+		if data[0] != '[' || json.Unmarshal(data, &elems) != nil {
+			mismatch()
+			return
+		}
+		for _, elem := range elems {
+			w.walk(elem, t.Elem(), path+"[]")
+		}
+	case reflect.String:
+		if data[0] != '"' {
+			mismatch()
+		}
+	case reflect.Bool:
+		if data[0] != 't' && data[0] != 'f' {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if data[0] != '-' && (data[0] < '0' || data[0] > '9') {
+			mismatch()
+		}
+	}
+}
+
+func joinDriftPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonField is a struct field as seen by encoding/json.
+type jsonField struct {
+	typ    reflect.Type
+	quoted bool // the field has the ",string" option
+}
+
+// jsonFields are the fields of a struct type, by JSON name.
+type jsonFields map[string]jsonField
+
+// lookup finds the field for an object member. Like encoding/json, it prefers an exact
+// match of the name, but also accepts a case-insensitive match.
+func (fs jsonFields) lookup(name string) (jsonField, bool) {
+	if f, ok := fs[name]; ok {
+		return f, true
+	}
+	for n, f := range fs {
+		if strings.EqualFold(n, name) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+var jsonFieldCache sync.Map // reflect.Type -> jsonFields
+
+func cachedJSONFields(t reflect.Type) jsonFields {
+	if fs, ok := jsonFieldCache.Load(t); ok {
+		return fs.(jsonFields)
+	}
+	fs := make(jsonFields)
+	collectJSONFields(t, fs, 0)
+	jsonFieldCache.Store(t, fs)
+	return fs
+}
+
+// collectJSONFields adds the fields of struct type t to fs. Fields of embedded structs
+// are promoted unless a shallower field has the same name.
+func collectJSONFields(t reflect.Type, fs jsonFields, depth int) {
+	if depth > 8 {
+		return
+	}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			if ft := derefType(sf.Type); ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if _, ok := fs[name]; !ok {
+			fs[name] = jsonField{typ: sf.Type, quoted: strings.Contains(","+opts+",", ",string,")}
+		}
+	}
+	for _, et := range embedded {
+		collectJSONFields(et, fs, depth+1)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type driftService struct{}
+
+func (driftService) Block() map[string]interface{} {
+	return map[string]interface{}{
+		"number":   1,
+		"hash":     "0x01",
+		"newField": "x",
+		"txs": []map[string]interface{}{
+			{"gas": 1, "extra": true},
+			{"gas": 2, "extra": false},
+		},
+	}
+}
+
+type driftBlock struct {
+	Number uint64    `json:"number"`
+	Hash   string    `json:"hash"`
+	Txs    []driftTx `json:"txs"`
+}
+
+type driftTx struct {
+	Gas uint64 `json:"gas"`
+}
+
+type driftBlockBadNumber struct {
+	Number string `json:"number"`
+}
+
+func driftPaths(drifts []SchemaDrift) []string {
+	var paths []string
+	for _, d := range drifts {
+		paths = append(paths, d.Kind.String()+" "+d.Path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestSchemaDriftReport(t *testing.T) {
+	t.Parallel()
+
+	var drifts []SchemaDrift
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("drift", driftService{})
+	client := dialInProcWithOptions(server, WithSchemaDrift(SchemaDriftConfig{
+		Report: func(d SchemaDrift) { drifts = append(drifts, d) },
+	}))
+	defer client.Close()
+
+	// Unknown fields are reported, but the result is decoded.
+	var block driftBlock
+	if err := client.Call(&block, "drift_block"); err != nil {
+		t.Fatal(err)
+	}
+	if block.Number != 1 || len(block.Txs) != 2 {
+		t.Fatalf("wrong result %+v", block)
+	}
+	want := []string{"unknown field newField", "unknown field txs[].extra"}
+	if got := driftPaths(drifts); !slices.Equal(got, want) {
+		t.Fatalf("wrong drifts %v, want %v", got, want)
+	}
+	if drifts[0].Method != "drift_block" || drifts[0].Type != reflect.TypeOf(block) {
+		t.Fatalf("wrong drift %v", drifts[0])
+	}
+
+	// Type mismatches are reported before decoding fails.
+	drifts = nil
+	var bad driftBlockBadNumber
+	if err := client.Call(&bad, "drift_block"); err == nil {
+		t.Fatal("expected decoding error")
+	}
+	if !slices.Contains(driftPaths(drifts), "type mismatch number") {
+		t.Fatalf("mismatch not reported: %v", drifts)
+	}
+
+	// Batch results are checked too.
+	drifts = nil
+	batch := []BatchElem{{Method: "drift_block", Result: new(driftBlock)}}
+	if err := client.BatchCall(batch); err != nil || batch[0].Error != nil {
+		t.Fatal(err, batch[0].Error)
+	}
+	if len(drifts) != 2 {
+		t.Fatalf("wrong drifts in batch %v", drifts)
+	}
+}
+
+func TestSchemaDriftStrict(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("drift", driftService{})
+	client := dialInProcWithOptions(server, WithSchemaDrift(SchemaDriftConfig{Strict: []interface{}{(*driftBlock)(nil)}}))
+	defer client.Close()
+	var block driftBlock
+	err := client.Call(&block, "drift_block")
+	var driftErr *SchemaDriftError
+	if !errors.As(err, &driftErr) {
+		t.Fatalf("expected schema drift error, got %v", err)
+	}
+	if got := driftPaths(driftErr.Drifts); len(got) != 2 {
+		t.Fatalf("wrong drifts %v", got)
+	}
+
+	// Other types are decoded leniently.
+	var result map[string]interface{}
+	if err := client.Call(&result, "drift_block"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type driftCachedService struct {
+	driftService
+	calls atomic.Int32
+}
+
+func (s *driftCachedService) Block() map[string]interface{} {
+	s.calls.Add(1)
+	return s.driftService.Block()
+}
+
+func (*driftCachedService) CachePolicies() map[string]CachePolicy {
+	return map[string]CachePolicy{"block": {TTL: time.Minute}}
+}
+
+func TestSchemaDriftCacheHit(t *testing.T) {
+	t.Parallel()
+
+	var drifts []SchemaDrift
+	server := newTestServer()
+	defer server.Stop()
+	service := new(driftCachedService)
+	server.RegisterName("drift", service)
+	client := dialInProcWithOptions(server, WithResponseCache(10), WithSchemaDrift(SchemaDriftConfig{
+		Report: func(d SchemaDrift) { drifts = append(drifts, d) },
+	}))
+	defer client.Close()
+
+	// The second call is answered from the cache, and checked like the first.
+	for i := 0; i < 2; i++ {
+		var block driftBlock
+		if err := client.Call(&block, "drift_block"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := service.calls.Load(); calls != 1 {
+		t.Fatalf("result not cached, %d calls", calls)
+	}
+	if len(drifts) != 4 {
+		t.Fatalf("wrong drifts %v", driftPaths(drifts))
+	}
+}
+
+func TestSchemaDriftWalk(t *testing.T) {
+	t.Parallel()
+
+	type inner struct {
+		Value string `json:"value"`
+	}
+	type result struct {
+		inner
+		Name    string          `json:"name"`
+		Count   int64           `json:"count,string"`
+		Ignored string          `json:"-"`
+		Big     *hexutil.Big    `json:"big"`
+		Any     interface{}     `json:"any"`
+		Bytes   []byte          `json:"bytes"`
+		Nested  map[string]bool `json:"nested"`
+	}
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{`{"value":"x","NAME":"y","count":"5","big":"0x1","any":[1],"bytes":"AQ==","nested":{"a":true}}`, nil},
+		{`{"Ignored":"x","big":5}`, []string{"unknown field Ignored"}},
+		{`{"value":1,"name":null,"nested":{"a":1,"b":2}}`, []string{"type mismatch nested{}", "type mismatch value"}},
+		{`[]`, []string{"type mismatch "}},
+	}
+	for _, test := range tests {
+		w := driftWalker{seen: make(map[string]bool)}
+		w.walk([]byte(test.data), reflect.TypeOf(result{}), "")
+		if got := driftPaths(w.drifts); !slices.Equal(got, test.want) {
+			t.Errorf("%s: drifts %v, want %v", test.data, got, test.want)
+		}
+	}
+}
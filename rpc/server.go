@@ -18,12 +18,17 @@ package rpc
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"io"
 	"net"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -47,21 +52,60 @@ const (
 type Server struct {
 	services serviceRegistry
 	idgen    func() ID
+	epochIDs bool   // ids created by idgen start with the epoch
+	epoch    string // see Epoch
 
 	mutex              sync.Mutex
 	codecs             map[ServerCodec]struct{}
+	peers              map[ConnID]*Peer
 	run                atomic.Bool
-	batchItemLimit     int
-	batchResponseLimit int
 	httpBodyLimit      int
+	httpConfig         HTTPConfig    // see SetHTTPConfig
+	admission          AdmissionHook // see SetAdmissionHook
+	httpStreamBudget   int64
+	checkDuplicateIDs  bool
+	synchronousCalls   bool
+	orderNotifications bool
+	subscriptionOwner  SubscriptionOwnerFunc
+	sessions           *sessionRegistry
+	wsFragmentSize     int
+	wsCompressors      []WebsocketCompressor
+	wsAdaptive         *compressionGovernor
+	wsSizePolicy       WebsocketMessageSizePolicy
+	wsReadLimit        int64 // default message size limit, see SetHTTPConfig
+	wsCoalesceWindow   time.Duration
+	wsCoalesceBytes    int
+	wireCodecs         []WireCodec // see SetWireCodecs
+	binaryPayloads     int         // see SetBinaryPayloads
+	responseChecksums  bool
+	executionReports   bool
+	canonicalJSON      bool
+	numberPolicy       NumberPolicy
+	timeFormat         TimeFormat
+	events             *serverEvents
+	errorCodes         map[int]ErrorCatalogEntry // see RegisterErrorCodes
+	healthChecks       map[string]healthCheck    // see RegisterHealthCheck
+	draining           atomic.Bool
+	drainHealthStatus  int
+	drainIdleTimeout   time.Duration
+	clock              mclock.Clock
 }
 
 // NewServer creates a new server instance with no registered handlers.
 func NewServer() *Server {
+	epoch := newEpoch()
 	server := &Server{
-		idgen:         randomIDGenerator(),
-		codecs:        make(map[ServerCodec]struct{}),
-		httpBodyLimit: defaultBodyLimit,
+		idgen:             prefixedIDGenerator(epoch),
+		epochIDs:          true,
+		epoch:             hex.EncodeToString(epoch),
+		codecs:            make(map[ServerCodec]struct{}),
+		peers:             make(map[ConnID]*Peer),
+		httpBodyLimit:     defaultBodyLimit,
+		wsReadLimit:       wsDefaultReadLimit,
+		events:            newServerEvents(),
+		drainHealthStatus: defaultDrainHealthStatus,
+		drainIdleTimeout:  defaultDrainIdleTimeout,
+		clock:             newTimerWheel(mclock.System{}, wheelTick),
 	}
 	server.run.Store(true)
 	// Register the default service providing meta information about the RPC service such
@@ -75,32 +119,151 @@ func NewServer() *Server {
 // is the maximum number of items in a batch. 'maxResponseSize' is the maximum number of
 // response bytes across all requests in a batch.
 //
-// This method should be called before processing any requests via ServeCodec, ServeHTTP,
-// ServeListener etc.
+// Batches exceeding a limit are not rejected as a whole. Calls beyond the item limit are
+// answered with an error of code -32600 each, and once the response size limit is reached,
+// the remaining calls are answered with an error of code -32003. The error data names the
+// exceeded limit, e.g. {"limit":"items","max":100}. Batches with more than twice the
+// item limit are rejected with a single error of code -32600.
+//
+// The limits may be changed while the server is running. New limits apply to connections
+// opened after the change, and to all HTTP requests received after it.
 func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
-	s.batchItemLimit = itemLimit
-	s.batchResponseLimit = maxResponseSize
+	s.services.setBatchLimits(itemLimit, maxResponseSize)
+}
+
+// ConfigVersion returns the version of the server's handler configuration. The version
+// is incremented whenever the batch limits, middlewares, parameter validator, request
+// classifier or blob store are changed. These settings can be changed safely while the server is
+// running; requests observe either the old or the new configuration.
+func (s *Server) ConfigVersion() uint64 {
+	return s.services.snapshot().version
 }
 
-// SetHTTPBodyLimit sets the size limit for HTTP requests.
+// SetHTTPBodyLimit sets the size limit for HTTP requests. See SetHTTPConfig for
+// configuring all limits of HTTP and websocket requests.
 //
 // This method should be called before processing any requests via ServeHTTP.
 func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetDuplicateIDCheck enables detection of duplicate request ids. When enabled, a call
+// whose id matches another call that is still being processed on the same connection, or
+// an earlier call in the same batch, is not executed. Instead, it is answered with an
+// 'invalid request' error carrying the duplicate id as error data.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetDuplicateIDCheck(enabled bool) {
+	s.checkDuplicateIDs = enabled
+}
+
+// SetSynchronousExecution makes the server run method calls on the goroutine reading
+// the connection, instead of starting a goroutine per request. Calls and batches are
+// then executed one at a time, in the order they were received. This is meant for
+// single-tenant embedding over in-process and IPC connections, where it reduces the
+// overhead of calls and makes their execution order deterministic.
+//
+// A slow call delays all requests sent after it on the same connection. Methods must not
+// use the Client from ClientFromContext to call back into the connection, since the
+// response can't be read until the method has returned. Request timeouts still produce
+// a timely error response. HTTP requests are not affected by this setting.
+//
+// This method should be called before processing any requests via ServeCodec,
+// ServeListener etc.
+func (s *Server) SetSynchronousExecution(enabled bool) {
+	s.synchronousCalls = enabled
+}
+
+// SetNotificationOrdering enables sequence numbers for subscription notifications. When
+// enabled, all notifications sent on a connection carry a 'seq' field in their params,
+// which is shared across the subscriptions of the connection and increases by one for
+// every notification. Clients can use it to reconstruct the order of events delivered
+// through related subscriptions, e.g. newHeads and logs. See SequencedNotification.
+//
+// This method should be called before processing any requests via ServeCodec,
+// ServeListener etc.
+func (s *Server) SetNotificationOrdering(enabled bool) {
+	s.orderNotifications = enabled
+}
+
+// SubscriptionOwnerFunc returns the identity of the caller of a request, e.g. derived from
+// authentication information stored in ctx.
+type SubscriptionOwnerFunc func(ctx context.Context) string
+
+// SetIDGenerator sets the function creating the ids of subscriptions, e.g. to issue
+// ULIDs or sequential ids which are easier to correlate across server and client logs,
+// see SequentialIDGenerator. The function must be safe for concurrent use and return
+// unique ids. Passing nil restores the default generator, which creates random ids
+// starting with the server epoch.
+//
+// Unknown ids are only reported as being issued by another server instance when the
+// default generator is used, see Epoch.
+//
+// This method should be called before processing any requests via ServeCodec,
+// ServeListener etc.
+func (s *Server) SetIDGenerator(gen func() ID) {
+	if gen == nil {
+		epoch, _ := hex.DecodeString(s.epoch)
+		s.idgen, s.epochIDs = prefixedIDGenerator(epoch), true
+		return
+	}
+	s.idgen, s.epochIDs = gen, false
+}
+
+// SetSubscriptionOwner configures ownership checks for subscriptions. Subscriptions
+// are always bound to the connection that created them. When fn is set, each subscription
+// is additionally bound to the identity returned by fn for the subscribe call, and
+// unsubscribing requires the same identity. This prevents callers sharing a connection,
+// e.g. through a gateway, from ending each other's subscriptions by guessing ids.
+//
+// This method should be called before processing any requests via ServeCodec,
+// ServeListener etc.
+func (s *Server) SetSubscriptionOwner(fn SubscriptionOwnerFunc) {
+	s.subscriptionOwner = fn
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either an RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
 // service collection this server provides to clients.
+//
+// A version can be appended to the name, e.g. "eth@2", to serve a new version of a
+// namespace with incompatible method signatures alongside the existing one, which is
+// version 1. Callers select versions with the APIVersionHeader or by calling
+// rpc_selectVersions on their connection, see also WithAPIVersions. Calls of callers
+// which don't select a version are served by the default version of the namespace,
+// see SetDefaultVersion. A version can also be called directly as "eth@2_method".
 func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetMiddlewares replaces the middleware chain with the given middlewares, the first
+// one being the outermost. They are named "#0", "#1" and so on, see UseMiddleware.
 func (s *Server) SetMiddlewares(middlewares []Middleware) {
 	s.services.setMiddlewares(middlewares)
 }
 
+// SetParamValidator installs a validator which checks the parameters of incoming calls
+// before they are dispatched. Only the given methods are validated; if none are given,
+// all methods described by the validator's document are. Calls with invalid parameters
+// are answered with an invalid params (-32602) error listing the violations. Passing a
+// nil validator disables validation.
+func (s *Server) SetParamValidator(v *ParamValidator, methods ...string) {
+	if v == nil {
+		s.services.setParamValidation(nil)
+		return
+	}
+	pv := &paramValidation{validator: v}
+	if len(methods) > 0 {
+		pv.methods = make(map[string]bool, len(methods))
+		for _, m := range methods {
+			pv.methods[m] = true
+		}
+	}
+	s.services.setParamValidation(pv)
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -109,18 +272,44 @@ func (s *Server) SetMiddlewares(middlewares []Middleware) {
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	defer codec.close()
 
+	if !s.admitCodec(codec) {
+		return
+	}
 	if !s.trackCodec(codec) {
 		return
 	}
 	defer s.untrackCodec(codec)
 
+	s.events.connEvent(ServerEventConnOpened, codec.peerInfo())
+	defer s.events.connEvent(ServerEventConnClosed, codec.peerInfo())
+	s.attachMethodFilter(codec, codec.peerInfo())
+
+	connID := ConnID(s.services.connIDs.Add(1))
+	limits := s.services.snapshot()
 	cfg := &clientConfig{
 		idgen:              s.idgen,
-		batchItemLimit:     s.batchItemLimit,
-		batchResponseLimit: s.batchResponseLimit,
+		epochIDs:           s.epochIDs,
+		epoch:              s.epoch,
+		batchItemLimit:     limits.batchItemLimit,
+		batchResponseLimit: limits.batchResponseLimit,
+		checkDuplicateIDs:  s.checkDuplicateIDs,
+		synchronousCalls:   s.synchronousCalls,
+		orderNotifications: s.orderNotifications,
+		subscriptionOwner:  s.subscriptionOwner,
+		sessions:           s.sessions,
+		responseChecksums:  s.responseChecksums,
+		executionReports:   s.executionReports,
+		canonicalJSON:      s.canonicalJSON,
+		numberPolicy:       s.numberPolicy,
+		timeFormat:         s.timeFormat,
+		serverEvents:       s.events,
+		connID:             connID,
+		clock:              s.clock,
 	}
 	c := initClient(codec, &s.services, cfg)
+	s.addPeer(&Peer{ID: connID, Info: codec.peerInfo(), client: c})
 	<-codec.closed()
+	s.removePeer(connID)
 	c.Close()
 }
 
@@ -128,10 +317,13 @@ func (s *Server) trackCodec(codec ServerCodec) bool {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if !s.run.Load() {
+	if !s.run.Load() || s.shuttingDown() {
 		return false // Don't serve if server is stopped.
 	}
 	s.codecs[codec] = struct{}{}
+	if s.draining.Load() {
+		s.drainCodec(codec)
+	}
 	return true
 }
 
@@ -151,10 +343,21 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
+	limits := s.services.snapshot()
+	h := newHandler(ctx, codec, s.idgen, &s.services, limits.batchItemLimit, limits.batchResponseLimit)
 	h.allowSubscribe = false
+	h.closer.disabled = true
+	h.checkDuplicateIDs = s.checkDuplicateIDs
+	h.events = s.events
+	h.checksums = s.responseChecksums
+	h.executionReports = s.executionReports
+	h.canonicalJSON = s.canonicalJSON
+	h.numberPolicy = s.numberPolicy
+	h.timeFormat = s.timeFormat
+	h.clock = s.clock
 	defer h.close(io.EOF, nil)
 
+	s.attachMethodFilter(codec, PeerInfoFromContext(ctx))
 	reqs, batch, err := codec.readBatch()
 	if err != nil {
 		if msg := messageForReadError(err); msg != "" {
@@ -166,6 +369,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 	if batch {
 		h.handleBatch(reqs)
 	} else {
+		h.streamResults = canStream(codec)
 		h.handleMsg(reqs[0])
 	}
 }
@@ -196,6 +400,12 @@ func (s *Server) Stop() {
 		for codec := range s.codecs {
 			codec.close()
 		}
+		if s.sessions != nil {
+			s.sessions.close()
+		}
+		if pool := s.services.workers.Swap(nil); pool != nil {
+			pool.stop()
+		}
 	}
 }
 
@@ -205,18 +415,47 @@ type RPCService struct {
 	server *Server
 }
 
-// Modules returns the list of RPC services with their version number
+// Modules returns the list of RPC services with their version number. The version of a
+// versioned namespace is the one serving callers which haven't selected a version.
 func (s *RPCService) Modules() map[string]string {
 	s.server.services.mu.Lock()
 	defer s.server.services.mu.Unlock()
 
 	modules := make(map[string]string)
 	for name := range s.server.services.services {
+		namespace, _, _ := strings.Cut(name, apiVersionSeparator)
+		_, version, _ := parseVersionedName(s.server.services.serviceName(namespace, nil))
+		modules[namespace] = strconv.Itoa(version) + ".0"
+	}
+	for name := range s.server.services.factories {
 		modules[name] = "1.0"
 	}
+	for name := range s.server.services.proxies {
+		if _, ok := modules[name]; !ok {
+			modules[name] = "1.0"
+		}
+	}
 	return modules
 }
 
+// ServerLimits describes the request limits of a server. It is returned by rpc_limits.
+// Zero values mean that no limit is applied.
+type ServerLimits struct {
+	BatchRequestLimit    int `json:"batchRequestLimit"`    // maximum number of calls in a batch
+	BatchResponseMaxSize int `json:"batchResponseMaxSize"` // maximum response size of a batch
+	HTTPBodyLimit        int `json:"httpBodyLimit"`        // maximum size of HTTP requests
+}
+
+// Limits returns the request limits configured on the server.
+func (s *RPCService) Limits() ServerLimits {
+	limits := s.server.services.snapshot()
+	return ServerLimits{
+		BatchRequestLimit:    limits.batchItemLimit,
+		BatchResponseMaxSize: limits.batchResponseLimit,
+		HTTPBodyLimit:        s.server.httpBodyLimit,
+	}
+}
+
 // PeerInfo contains information about the remote end of the network connection.
 //
 // This is available within RPC method handlers through the context. Call
@@ -224,7 +463,7 @@ func (s *RPCService) Modules() map[string]string {
 // the current method call.
 type PeerInfo struct {
 	// Transport is name of the protocol used by the client.
-	// This can be "http", "ws" or "ipc".
+	// This can be "http", "ws", "grpc" or "ipc".
 	Transport string
 
 	// Address of client. This will usually contain the IP address and port.
@@ -232,13 +471,35 @@ type PeerInfo struct {
 
 	// Additional information for HTTP and WebSocket connections.
 	HTTP struct {
-		// Protocol version, i.e. "HTTP/1.1". This is not set for WebSocket.
+		// Protocol version, i.e. "HTTP/1.1" or "HTTP/2.0". This is not set for WebSocket.
 		Version string
 		// Header values sent by the client.
 		UserAgent string
 		Origin    string
 		Host      string
 	}
+
+	// Credentials of the peer process. This is set for IPC connections over unix
+	// sockets on Linux and nil otherwise.
+	Cred *PeerCred
+
+	// Principal of the client, determined by the authenticator of the server ACL. This
+	// is nil for anonymous clients, see SetACL.
+	Principal *Principal
+
+	// Metadata attached by the admission hook of the server, see SetAdmissionHook.
+	Metadata map[string]any
+
+	// Route is the path prefix of the route the client connected to, see Server.Router.
+	Route string
+
+	route *httpRoute
+}
+
+func (info *PeerInfo) setRoute(route *httpRoute) {
+	if route != nil {
+		info.Route, info.route = route.Prefix, route
+	}
 }
 
 type peerInfoContextKey struct{}
@@ -0,0 +1,186 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Server event types.
+const (
+	ServerEventConnOpened   = "connectionOpened"
+	ServerEventConnClosed   = "connectionClosed"
+	ServerEventErrorSpike   = "errorSpike"
+	ServerEventLoadShedding = "loadShedding"
+)
+
+const (
+	defaultErrorSpikeThreshold = 100
+	defaultErrorSpikeWindow    = 10 * time.Second
+
+	// serverEventBuffer is the number of events buffered per subscriber. Events are
+	// dropped for subscribers that can't keep up.
+	serverEventBuffer = 128
+)
+
+// ServerEvent is emitted by the server events subscription.
+type ServerEvent struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Transport  string    `json:"transport,omitempty"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// serverEvents distributes server events to subscribers.
+type serverEvents struct {
+	mu          sync.Mutex
+	subs        map[chan ServerEvent]struct{}
+	subCount    atomic.Int32
+	threshold   int
+	window      time.Duration
+	windowStart time.Time
+	errors      int
+	spiked      bool // true if a spike was reported in the current window
+}
+
+func newServerEvents() *serverEvents {
+	return &serverEvents{
+		subs:      make(map[chan ServerEvent]struct{}),
+		threshold: defaultErrorSpikeThreshold,
+		window:    defaultErrorSpikeWindow,
+	}
+}
+
+func (e *serverEvents) subscribe() (<-chan ServerEvent, func()) {
+	ch := make(chan ServerEvent, serverEventBuffer)
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.subCount.Add(1)
+	e.mu.Unlock()
+
+	unsubscribe := func() {
+		e.mu.Lock()
+		delete(e.subs, ch)
+		e.subCount.Add(-1)
+		e.mu.Unlock()
+	}
+	return ch, unsubscribe
+}
+
+// publish sends ev to all subscribers.
+func (e *serverEvents) publish(ev ServerEvent) {
+	if e.subCount.Load() == 0 {
+		return
+	}
+	ev.Time = time.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (e *serverEvents) connEvent(typ string, info PeerInfo) {
+	e.publish(ServerEvent{Type: typ, Transport: info.Transport, RemoteAddr: info.RemoteAddr})
+}
+
+// recordError counts a failed call. An errorSpike event is published when the number of
+// errors within the current window reaches the threshold.
+func (e *serverEvents) recordError() {
+	if e.subCount.Load() == 0 {
+		return
+	}
+	e.mu.Lock()
+	now := time.Now()
+	if now.Sub(e.windowStart) > e.window {
+		e.windowStart, e.errors, e.spiked = now, 0, false
+	}
+	e.errors++
+	spike := e.threshold > 0 && e.errors >= e.threshold && !e.spiked
+	if spike {
+		e.spiked = true
+	}
+	e.mu.Unlock()
+
+	if spike {
+		detail := fmt.Sprintf("%d failed calls within %v", e.threshold, e.window)
+		e.publish(ServerEvent{Type: ServerEventErrorSpike, Detail: detail})
+	}
+}
+
+// SetErrorSpikeThreshold configures when errorSpike server events are emitted: an event
+// is emitted when 'count' calls fail within 'window'. Passing a count of zero disables
+// these events. The default is 100 failed calls within 10 seconds.
+//
+// This method should be called before processing any requests.
+func (s *Server) SetErrorSpikeThreshold(count int, window time.Duration) {
+	s.events.threshold = count
+	s.events.window = window
+}
+
+// ReportLoadShedding emits a loadShedding server event. Middlewares which reject requests
+// to protect the server from overload should call this so it is visible to subscribers of
+// server events.
+func (s *Server) ReportLoadShedding(reason string) {
+	s.events.publish(ServerEvent{Type: ServerEventLoadShedding, Detail: reason})
+}
+
+// ServerEventsAPI returns the API providing the server events subscription. Once
+// registered, clients can subscribe using admin_subscribe("serverEvents"):
+//
+//	api := server.ServerEventsAPI()
+//	server.RegisterName(api.Namespace, api.Service)
+func (s *Server) ServerEventsAPI() API {
+	return API{Namespace: "admin", Service: &serverEventsService{s}}
+}
+
+type serverEventsService struct {
+	server *Server
+}
+
+// ServerEvents streams server events to the subscriber.
+func (api *serverEventsService) ServerEvents(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	events, unsubscribe := api.server.events.subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				if err := notifier.Notify(sub.ID, ev); err != nil {
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
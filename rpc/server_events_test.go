@@ -0,0 +1,76 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestServerEventsSubscription(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	api := server.ServerEventsAPI()
+	if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetErrorSpikeThreshold(2, time.Minute)
+
+	client := DialInProc(server)
+	defer client.Close()
+	events := make(chan ServerEvent, 10)
+	sub, err := client.Subscribe(context.Background(), "admin", events, "serverEvents")
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+
+	expect := func(typ string) {
+		t.Helper()
+		select {
+		case ev := <-events:
+			if ev.Type != typ {
+				t.Fatalf("wrong event type %q, want %q", ev.Type, typ)
+			}
+		case err := <-sub.Err():
+			t.Fatal("subscription error:", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %q event", typ)
+		}
+	}
+
+	// Connection events.
+	other := DialInProc(server)
+	if err := other.Call(nil, "rpc_modules"); err != nil {
+		t.Fatal(err)
+	}
+	expect(ServerEventConnOpened)
+	other.Close()
+	expect(ServerEventConnClosed)
+
+	// Error spike, reported once per window.
+	for i := 0; i < 3; i++ {
+		client.Call(nil, "test_returnError")
+	}
+	expect(ServerEventErrorSpike)
+
+	server.ReportLoadShedding("test")
+	expect(ServerEventLoadShedding)
+}
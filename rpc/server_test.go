@@ -19,11 +19,14 @@ package rpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -202,3 +205,188 @@ func TestServerBatchResponseSizeLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestServerDuplicateIDs(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetDuplicateIDCheck(true)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0)
+	readbuf := bufio.NewReader(clientConn)
+
+	roundtrip := func(req, want string) {
+		t.Helper()
+		clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+		if req != "" {
+			if _, err := io.WriteString(clientConn, req+"\n"); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+		}
+		if want == "" {
+			return
+		}
+		resp, err := readbuf.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if resp = strings.TrimRight(resp, "\n"); resp != want {
+			t.Errorf("wrong response\ngot:  %s\nwant: %s", resp, want)
+		}
+	}
+
+	// Duplicates within a batch.
+	roundtrip(
+		`[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["y",2]}]`,
+		`[{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}},{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"duplicate request id","data":1}}]`,
+	)
+	// Duplicate of a pending call. The id can be reused after the first call has finished.
+	roundtrip(`{"jsonrpc":"2.0","id":"a","method":"test_sleep","params":[500000000]}`, "")
+	roundtrip(
+		`{"jsonrpc":"2.0","id":"a","method":"test_echo","params":["x",1]}`,
+		`{"jsonrpc":"2.0","id":"a","error":{"code":-32600,"message":"duplicate request id","data":"a"}}`,
+	)
+	roundtrip("", `{"jsonrpc":"2.0","id":"a","result":null}`)
+	roundtrip(
+		`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]}`,
+		`{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}}`,
+	)
+}
+
+func TestServerSynchronousExecution(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetSynchronousExecution(true)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0)
+	clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// The slow call finishes before the next request is processed.
+	reqs := `{"jsonrpc":"2.0","id":1,"method":"test_sleep","params":[100000000]}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",1]}` + "\n"
+	go io.WriteString(clientConn, reqs)
+	readbuf := bufio.NewReader(clientConn)
+	for _, want := range []string{
+		`{"jsonrpc":"2.0","id":1,"result":null}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"String":"x","Int":1,"Args":null}}`,
+	} {
+		resp, err := readbuf.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if resp = strings.TrimRight(resp, "\n"); resp != want {
+			t.Errorf("wrong response\ngot:  %s\nwant: %s", resp, want)
+		}
+	}
+}
+
+func TestServerConfigVersion(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if v := server.ConfigVersion(); v != 0 {
+		t.Fatalf("initial version %d, want 0", v)
+	}
+	server.SetBatchLimits(10, 1000)
+	server.SetMiddlewares(nil)
+	if v := server.ConfigVersion(); v != 2 {
+		t.Fatalf("version %d after two updates, want 2", v)
+	}
+	rpcService := &RPCService{server: server}
+	if l := rpcService.Limits(); l.BatchRequestLimit != 10 || l.BatchResponseMaxSize != 1000 {
+		t.Fatalf("wrong limits %+v", l)
+	}
+}
+
+// This test checks that the configuration can be changed while calls are processed.
+func TestServerConfigHotReload(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var called atomic.Int64
+	mw := func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+		called.Add(1)
+		return next(ctx, method, args)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			if i%2 == 0 {
+				server.SetMiddlewares([]Middleware{mw})
+			} else {
+				server.SetMiddlewares(nil)
+			}
+			server.SetBatchLimits(i, 0)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		var result echoResult
+		if err := client.Call(&result, "test_echo", "x", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	if v := server.ConfigVersion(); v != 200 {
+		t.Fatalf("version %d, want 200", v)
+	}
+
+	// The last update removed the middleware.
+	before := called.Load()
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if called.Load() != before {
+		t.Fatal("middleware called after removal")
+	}
+}
+
+func TestServerErrorCatalog(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterErrorCodes(
+		ErrorCatalogEntry{Code: 3, Name: "execution reverted", Description: "the call reverted"},
+		ErrorCatalogEntry{Code: -32002, Name: "timeout", Description: "custom timeout", Retryable: true},
+	)
+	client := DialInProc(server)
+	defer client.Close()
+
+	var catalog []ErrorCatalogEntry
+	if err := client.Call(&catalog, "rpc_errorCatalog"); err != nil {
+		t.Fatal(err)
+	}
+	byCode := make(map[int]ErrorCatalogEntry)
+	for i, e := range catalog {
+		if i > 0 && catalog[i-1].Code >= e.Code {
+			t.Fatalf("catalog not ordered by code: %v", catalog)
+		}
+		byCode[e.Code] = e
+	}
+	if len(catalog) != len(builtinErrors)+1 {
+		t.Fatalf("wrong catalog length %d", len(catalog))
+	}
+	if e := byCode[-32002]; e.Description != "custom timeout" || !e.Retryable {
+		t.Errorf("registered entry did not replace built-in entry: %+v", e)
+	}
+	if e := byCode[3]; e.Name != "execution reverted" || e.Retryable {
+		t.Errorf("wrong application entry: %+v", e)
+	}
+	if e := byCode[-32601]; e.Name != "method not found" || e.Retryable {
+		t.Errorf("wrong built-in entry: %+v", e)
+	}
+}
@@ -18,14 +18,14 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
-	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
-
-	"github.com/ethereum/go-ethereum/log"
 )
 
 var (
@@ -38,9 +38,70 @@ var (
 type serviceRegistry struct {
 	mu          sync.Mutex
 	services    map[string]service
-	middlewares []Middleware
+	factories   map[string]ServiceFactory // per-connection services, see RegisterFactory
+	proxies     map[string]*upstreamProxy // see RegisterProxy
+	onClose     []func(ConnID)            // connection close listeners
+	connIDs     atomic.Uint64             // last assigned connection id
+	config      atomic.Pointer[registryConfig]
+	durations   methodDurations
+	capture     requestCapture
+	labels      metricLabels
+	concurrency concurrencyLimiter
+	calls       callTracker
+	workers     atomic.Pointer[callPool] // see SetCallWorkers
+
+	apiVersions     map[string][]int // registered versions above 1 of each namespace
+	defaultVersions map[string]int   // see SetDefaultVersion
+}
+
+// registryConfig is a snapshot of the configuration visible to handlers. Snapshots are
+// immutable: updates create a new snapshot with an incremented version and swap it in
+// atomically, so handlers can read the configuration without locking.
+type registryConfig struct {
+	version              uint64
+	middlewares          []Middleware
+	middlewareChain      []middlewareEntry            // named middlewares, see UseMiddleware
+	scopedMiddlewares    []scopedMiddleware           // see UseForNamespace
+	preDecode            []PreDecodeMiddleware        // see SetPreDecodeMiddlewares
+	subInterceptors      []SubscriptionInterceptor    // see SetSubscriptionInterceptors
+	subQueues            map[string]SubscriptionQueue // see SetSubscriptionQueue
+	computeUnits         *computeUnits                // see SetComputeUnits
+	validation           *paramValidation
+	classifier           RequestClassifier
+	batchItemLimit       int
+	batchResponseLimit   int
+	blobs                *blobConfig
+	ipcPolicy            IPCPolicy
+	acl                  *ACL
+	challenge            ChallengeVerifier
+	tokenValidator       *TokenValidator
+	methodTimeouts       map[string]time.Duration
+	defaultMethodTimeout time.Duration
+	consistency          *consistencyConfig
+	responseCache        Cache
+	cachePolicies        map[string]CachePolicy
+	batchConcurrency     int
+	batchOrder           BatchOrder
+	methodFilter         MethodFilter
+	guard                *ExecutionGuard
+	memoryCeilings       map[string]MemoryCeiling
+	encodeLimits         *encodeLimiter
+	replay               *replayGuard
+	truncation           map[string]TruncationPolicy
+	metrics              ServerMetrics
+	tracer               Tracer
+	audit                *auditLog              // see SetAuditLog
+	ordered              map[string]bool        // namespaces executed in arrival order
+	pauseBufferSize      int                    // see SetSubscriptionPauseBuffer
+	aliases              map[string]methodAlias // see Alias
+	deprecationHook      DeprecationHook        // see SetDeprecationHook
+	panics               panicPolicy            // see SetPanicHandler
+	coalescing           *coalescer             // see SetCoalescing
+	logParams            bool                   // see SetParamsLogging
 }
 
+var emptyRegistryConfig = new(registryConfig)
+
 // service represents a registered object.
 type service struct {
 	name          string               // name for service
@@ -56,6 +117,10 @@ type callback struct {
 	hasCtx      bool           // method's first argument is a context (not included in argTypes)
 	errPos      int            // err return idx, of -1 when method cannot return error
 	isSubscribe bool           // true if this is a subscription callback
+	cachePolicy *CachePolicy   // declared cacheability of results, nil if not cacheable
+	adapter     MethodAdapter  // generated dispatch adapter, see AdapterProvider
+	raw         RawHandler     // set for raw handlers, see RegisterRawHandler
+	forward     bool           // raw handler forwarding results from upstream, see RegisterProxy
 }
 
 func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
@@ -63,13 +128,53 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 	if name == "" {
 		return fmt.Errorf("no service name for type %s", rcvrVal.Type().String())
 	}
-	callbacks := suitableCallbacks(rcvrVal)
-	if len(callbacks) == 0 {
-		return fmt.Errorf("service %T doesn't have any suitable methods/subscriptions to expose", rcvr)
+	namespace, version, err := parseVersionedName(name)
+	if err != nil {
+		return err
+	}
+	name = versionedName(namespace, version)
+	callbacks, err := receiverCallbacks(rcvr)
+	if err != nil {
+		return err
+	}
+	if provider, ok := rcvr.(CachePolicyProvider); ok {
+		delete(callbacks, "cachePolicies")
+		for method, policy := range provider.CachePolicies() {
+			cb := callbacks[method]
+			if cb == nil || cb.isSubscribe {
+				return fmt.Errorf("cache policy for unknown method %s%s%s", name, serviceMethodSeparator, method)
+			}
+			cb.cachePolicy = &policy
+		}
+	}
+	listener, isListener := rcvr.(ConnectionCloseListener)
+	if isListener {
+		delete(callbacks, "onConnectionClosed")
 	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.factories[namespace] != nil {
+		return fmt.Errorf("service %s is already registered with a factory", namespace)
+	}
+	r.registerVersion(namespace, version)
+	if isListener {
+		r.onClose = append(r.onClose, listener.OnConnectionClosed)
+	}
+	svc := r.serviceLocked(name)
+	for name, cb := range callbacks {
+		if cb.isSubscribe {
+			svc.subscriptions[name] = cb
+		} else {
+			svc.callbacks[name] = cb
+		}
+	}
+	return nil
+}
+
+// serviceLocked returns the service with the given name, creating it if necessary. It
+// must be called with r.mu held.
+func (r *serviceRegistry) serviceLocked(name string) service {
 	if r.services == nil {
 		r.services = make(map[string]service)
 	}
@@ -82,38 +187,104 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 		}
 		r.services[name] = svc
 	}
-	for name, cb := range callbacks {
-		if cb.isSubscribe {
-			svc.subscriptions[name] = cb
-		} else {
-			svc.callbacks[name] = cb
-		}
+	return svc
+}
+
+// receiverCallbacks returns the callbacks of a service receiver.
+func receiverCallbacks(rcvr interface{}) (map[string]*callback, error) {
+	callbacks := suitableCallbacks(reflect.ValueOf(rcvr))
+	if err := attachAdapters(rcvr, callbacks); err != nil {
+		return nil, err
 	}
-	return nil
+	if len(callbacks) == 0 {
+		return nil, fmt.Errorf("service %T doesn't have any suitable methods/subscriptions to expose", rcvr)
+	}
+	return callbacks, nil
 }
 
 // callback returns the callback corresponding to the given RPC method name.
 func (r *serviceRegistry) callback(method string) *callback {
+	return r.versionedCallback(method, nil)
+}
+
+// versionedCallback returns the callback of method in the versions of namespaces
+// selected by the caller.
+func (r *serviceRegistry) versionedCallback(method string, versions map[string]int) *callback {
 	before, after, found := strings.Cut(method, serviceMethodSeparator)
 	if !found {
 		return nil
 	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	return r.services[before].callbacks[after]
+	return r.services[r.serviceName(before, versions)].callbacks[after]
 }
 
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
+	return r.versionedSubscription(service, name, nil)
+}
+
+// versionedSubscription returns a subscription callback in the version of the given
+// service selected by the caller.
+func (r *serviceRegistry) versionedSubscription(service, name string, versions map[string]int) *callback {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	return r.services[service].subscriptions[name]
+	return r.services[r.serviceName(service, versions)].subscriptions[name]
 }
 
-func (r *serviceRegistry) setMiddlewares(middlewares []Middleware) {
+// snapshot returns the current configuration.
+func (r *serviceRegistry) snapshot() *registryConfig {
+	if cfg := r.config.Load(); cfg != nil {
+		return cfg
+	}
+	return emptyRegistryConfig
+}
+
+// updateConfig applies fn to a copy of the current configuration and installs the copy
+// as the new snapshot.
+func (r *serviceRegistry) updateConfig(fn func(*registryConfig)) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.middlewares = middlewares
+	next := *r.snapshot()
+	fn(&next)
+	next.version++
+	r.config.Store(&next)
+}
+
+func (r *serviceRegistry) setMiddlewares(middlewares []Middleware) {
+	chain := anonymousMiddlewares(middlewares)
+	r.updateConfig(func(cfg *registryConfig) { cfg.setMiddlewareChain(chain) })
+}
+
+func (r *serviceRegistry) setParamValidation(pv *paramValidation) {
+	r.updateConfig(func(cfg *registryConfig) { cfg.validation = pv })
+}
+
+func (r *serviceRegistry) setClassifier(fn RequestClassifier) {
+	r.updateConfig(func(cfg *registryConfig) { cfg.classifier = fn })
+}
+
+func (r *serviceRegistry) setBatchLimits(itemLimit, maxResponseSize int) {
+	r.updateConfig(func(cfg *registryConfig) {
+		cfg.batchItemLimit = itemLimit
+		cfg.batchResponseLimit = maxResponseSize
+	})
+}
+
+// validateParams checks call parameters against the configured validator, if any.
+func (cfg *registryConfig) validateParams(method string, params json.RawMessage) error {
+	if cfg.validation == nil {
+		return nil
+	}
+	return cfg.validation.validate(method, params)
+}
+
+// classify returns the class of a call, or the empty string if no classifier is set.
+func (cfg *registryConfig) classify(ctx context.Context, msg *jsonrpcMessage) string {
+	if cfg.classifier == nil {
+		return ""
+	}
+	return cfg.classifier(ctx, msg.Method, msg.Params)
 }
 
 // suitableCallbacks iterates over the methods of the given type. It determines if a method
@@ -186,8 +357,28 @@ func (c *callback) makeArgTypes() {
 	}
 }
 
-// call invokes the callback.
-func (c *callback) call(ctx context.Context, method string, args []reflect.Value) (res interface{}, errRes error) {
+// call invokes the callback. Panics are recovered and logged.
+func (c *callback) call(ctx context.Context, method string, args []reflect.Value) (interface{}, error) {
+	return c.callWithPolicy(ctx, method, args, panicPolicy{})
+}
+
+// callWithPolicy invokes the callback, handling panics according to policy.
+func (c *callback) callWithPolicy(ctx context.Context, method string, args []reflect.Value, policy panicPolicy) (res interface{}, errRes error) {
+	if !policy.noRecover {
+		// Catch panic while running the callback.
+		defer func() {
+			if r := recover(); r != nil {
+				errRes = policy.recovered(method, r)
+			}
+		}()
+	}
+	if c.raw != nil {
+		return c.callRaw(ctx, args)
+	}
+	if c.adapter != nil {
+		return c.adapter(ctx, args)
+	}
+
 	// Create the argument slice.
 	fullargs := make([]reflect.Value, 0, 2+len(args))
 	if c.rcvr.IsValid() {
@@ -198,16 +389,6 @@ func (c *callback) call(ctx context.Context, method string, args []reflect.Value
 	}
 	fullargs = append(fullargs, args...)
 
-	// Catch panic while running the callback.
-	defer func() {
-		if err := recover(); err != nil {
-			const size = 64 << 10
-			buf := make([]byte, size)
-			buf = buf[:runtime.Stack(buf, false)]
-			log.Error("RPC method " + method + " crashed: " + fmt.Sprintf("%v\n%s", err, buf))
-			errRes = &internalServerError{errcodePanic, "method handler crashed"}
-		}
-	}()
 	// Run the callback.
 	results := c.fn.Call(fullargs)
 	if len(results) == 0 {
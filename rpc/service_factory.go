@@ -0,0 +1,147 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ServiceFactory creates the receiver of a service for a single connection. It is called
+// with the context of the first call to the service on the connection, and with
+// information about the client. Returning an error fails that call; the factory is
+// called again for the next one.
+type ServiceFactory func(ctx context.Context, peer PeerInfo) (interface{}, error)
+
+// RegisterFactory registers a service which is instantiated separately for every
+// connection, using factory. This allows services to keep per-connection state, such as
+// the result of an authentication call. Note that for HTTP, every request is a connection
+// of its own.
+//
+// The methods of the receiver returned by factory are exposed in the same way as for
+// RegisterName. If the receiver implements io.Closer, its Close method is not exposed;
+// it is called when the connection is closed instead. Cache policies declared by the
+// receiver are ignored because cached results would be shared between connections.
+func (s *Server) RegisterFactory(name string, factory ServiceFactory) error {
+	return s.services.registerFactory(name, factory)
+}
+
+func (r *serviceRegistry) registerFactory(name string, factory ServiceFactory) error {
+	if name == "" {
+		return errors.New("no service name for factory")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.services[name]; ok || r.factories[name] != nil || r.apiVersions[name] != nil {
+		return fmt.Errorf("service %s is already registered", name)
+	}
+	if r.factories == nil {
+		r.factories = make(map[string]ServiceFactory)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// factory returns the factory of the given service.
+func (r *serviceRegistry) factory(name string) ServiceFactory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.factories[name]
+}
+
+// serviceInstanceKey is the ConnStorage key of a service created by a factory.
+type serviceInstanceKey struct{ name string }
+
+// serviceInstance is a service created by a factory for one connection.
+type serviceInstance struct {
+	mu  sync.Mutex
+	svc *service
+}
+
+// factoryMethod returns the callback for method if its service is registered with a
+// factory, instantiating the service for the connection of ctx if necessary.
+func (h *handler) factoryMethod(ctx context.Context, method string) (*callback, error) {
+	namespace, name, found := strings.Cut(method, serviceMethodSeparator)
+	if !found {
+		return nil, nil
+	}
+	svc, err := h.factoryService(ctx, namespace)
+	if svc == nil {
+		return nil, err
+	}
+	return svc.callbacks[name], nil
+}
+
+// factorySubscription is like factoryMethod for subscriptions.
+func (h *handler) factorySubscription(ctx context.Context, namespace, name string) (*callback, error) {
+	svc, err := h.factoryService(ctx, namespace)
+	if svc == nil {
+		return nil, err
+	}
+	return svc.subscriptions[name], nil
+}
+
+// factoryService returns the instance of a factory service for the connection of ctx.
+// It returns nil if no factory is registered for the name.
+func (h *handler) factoryService(ctx context.Context, name string) (*service, error) {
+	factory := h.reg.factory(name)
+	store := ConnStore(ctx)
+	if factory == nil || store == nil {
+		return nil, nil
+	}
+	v, _ := store.LoadOrStore(serviceInstanceKey{name}, new(serviceInstance))
+	inst := v.(*serviceInstance)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.svc != nil {
+		return inst.svc, nil
+	}
+	rcvr, err := factory(ctx, PeerInfoFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	callbacks, err := receiverCallbacks(rcvr)
+	if err != nil {
+		return nil, err
+	}
+	svc := &service{
+		name:          name,
+		callbacks:     make(map[string]*callback),
+		subscriptions: make(map[string]*callback),
+	}
+	if _, ok := rcvr.(CachePolicyProvider); ok {
+		delete(callbacks, "cachePolicies")
+	}
+	if closer, ok := rcvr.(io.Closer); ok {
+		delete(callbacks, "close")
+		store.OnClose(func() { closer.Close() })
+	}
+	for name, cb := range callbacks {
+		if cb.isSubscribe {
+			svc.subscriptions[name] = cb
+		} else {
+			svc.callbacks[name] = cb
+		}
+	}
+	inst.svc = svc
+	return svc, nil
+}
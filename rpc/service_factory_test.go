@@ -0,0 +1,131 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sessionService is instantiated per connection. It remembers the logged-in user.
+type sessionService struct {
+	user   string
+	closed chan<- string
+}
+
+func (s *sessionService) Login(user string) {
+	s.user = user
+}
+
+func (s *sessionService) WhoAmI() (string, error) {
+	if s.user == "" {
+		return "", errors.New("not logged in")
+	}
+	return s.user, nil
+}
+
+func (s *sessionService) Close() error {
+	s.closed <- s.user
+	return nil
+}
+
+func TestServiceFactory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		created atomic.Int32
+		closed  = make(chan string, 2)
+	)
+	server := newTestServer()
+	defer server.Stop()
+	err := server.RegisterFactory("session", func(ctx context.Context, peer PeerInfo) (interface{}, error) {
+		created.Add(1)
+		if peer.Transport != "ipc" {
+			t.Errorf("wrong transport %q", peer.Transport)
+		}
+		return &sessionService{closed: closed}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("session", new(testService)); err == nil {
+		t.Fatal("registering a service over a factory should fail")
+	}
+
+	alice, bob := DialInProc(server), DialInProc(server)
+	defer bob.Close()
+	if err := alice.Call(nil, "session_login", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	var user string
+	if err := alice.Call(&user, "session_whoAmI"); err != nil || user != "alice" {
+		t.Fatalf("wrong user %q, err %v", user, err)
+	}
+	if err := bob.Call(&user, "session_whoAmI"); err == nil {
+		t.Fatal("state leaked between connections")
+	}
+	if n := created.Load(); n != 2 {
+		t.Fatalf("factory called %d times, want 2", n)
+	}
+
+	// Close is not exposed, it is called on disconnect.
+	if err := alice.Call(nil, "session_close"); err == nil {
+		t.Fatal("Close method should not be exposed")
+	}
+	var modules map[string]string
+	if err := alice.Call(&modules, "rpc_modules"); err != nil || modules["session"] == "" {
+		t.Fatalf("factory service missing from modules: %v %v", modules, err)
+	}
+	alice.Close()
+	select {
+	case user := <-closed:
+		if user != "alice" {
+			t.Fatalf("wrong instance closed: %q", user)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("instance not closed on disconnect")
+	}
+}
+
+func TestServiceFactoryError(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	var fail atomic.Bool
+	fail.Store(true)
+	server.RegisterFactory("session", func(ctx context.Context, peer PeerInfo) (interface{}, error) {
+		if fail.Load() {
+			return nil, errors.New("unauthorized")
+		}
+		return &sessionService{closed: make(chan string, 1)}, nil
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "session_login", "x"); err == nil || err.Error() != "unauthorized" {
+		t.Fatalf("wrong error %v", err)
+	}
+	// Failures are not cached.
+	fail.Store(false)
+	if err := client.Call(nil, "session_login", "x"); err != nil {
+		t.Fatal(err)
+	}
+}
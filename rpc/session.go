@@ -0,0 +1,232 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+const (
+	newSessionMethod    = MetadataApi + "_newSession"
+	resumeSessionMethod = MetadataApi + "_resumeSession"
+)
+
+var (
+	errSessionNotFound   = errors.New("session not found")
+	errSessionInUse      = errors.New("session is attached to another connection")
+	errSessionExists     = errors.New("connection already has a session")
+	errSessionExpired    = errors.New("session expired")
+	errSessionBufferFull = errors.New("session resume buffer overflow")
+)
+
+// SetSessionResumption enables sessions, which allow clients to move their subscriptions
+// to a new connection, e.g. after being disconnected by a load balancer.
+//
+// A client starts a session by calling rpc_newSession on a connection, which returns a
+// session token. When the connection is lost, the subscriptions of the connection are
+// kept alive for the duration of ttl, and up to bufferSize notifications are buffered for
+// each subscription. Calling rpc_resumeSession with the token on another connection moves
+// the subscriptions to that connection and replays the buffered notifications. It returns
+// the ids of the resumed subscriptions. Subscriptions which exceeded the buffer size are
+// not resumed.
+//
+// This method should be called before processing any requests via ServeCodec,
+// ServeListener etc.
+func (s *Server) SetSessionResumption(ttl time.Duration, bufferSize int) {
+	s.sessions = &sessionRegistry{
+		ttl:        ttl,
+		bufferSize: bufferSize,
+		sessions:   make(map[string]*session),
+	}
+}
+
+// sessionRegistry tracks the sessions of a server.
+type sessionRegistry struct {
+	ttl        time.Duration
+	bufferSize int
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	closed   bool
+}
+
+type session struct {
+	token    string
+	attached bool                 // whether a connection currently holds the session
+	subs     map[ID]*Subscription // subscriptions while detached
+	expire   mclock.Timer         // runs while detached
+}
+
+// create starts a new session for the connection of h.
+func (r *sessionRegistry) create(h *handler) (string, error) {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+	if h.session != nil {
+		return "", errSessionExists
+	}
+
+	var token [16]byte
+	crand.Read(token[:])
+	s := &session{token: h.epoch + hex.EncodeToString(token[:]), attached: true}
+
+	r.mu.Lock()
+	r.sessions[s.token] = s
+	r.mu.Unlock()
+	h.session = s
+	return s.token, nil
+}
+
+// detach is called when the connection of h is closed. If the connection holds a session,
+// it takes the subscriptions of the connection and buffers their notifications until the
+// session is resumed or expires. It returns false if the connection has no session.
+func (r *sessionRegistry) detach(h *handler) bool {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+	s := h.session
+	if s == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		delete(r.sessions, s.token)
+		return false
+	}
+
+	h.session = nil
+	s.attached = false
+	s.subs = h.serverSubs
+	h.serverSubs = make(map[ID]*Subscription)
+	for _, sub := range s.subs {
+		sub.notifier.detach(r.bufferSize)
+	}
+	s.expire = h.clock.AfterFunc(r.ttl, func() { r.expire(s) })
+	return true
+}
+
+// close ends all detached sessions. Sessions of active connections end when the
+// connection is closed.
+func (r *sessionRegistry) close() {
+	r.mu.Lock()
+	r.closed = true
+	var detached []*session
+	for _, s := range r.sessions {
+		if !s.attached {
+			detached = append(detached, s)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, s := range detached {
+		r.expire(s)
+	}
+}
+
+// expire ends a detached session.
+func (r *sessionRegistry) expire(s *session) {
+	r.mu.Lock()
+	if r.sessions[s.token] != s || s.attached {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.sessions, s.token)
+	subs := s.subs
+	s.subs = nil
+	r.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close(errSessionExpired)
+	}
+}
+
+// resume attaches a detached session to the connection of h. The notifiers of the
+// resumed subscriptions are returned. They must be activated after the response to the
+// resume call has been sent.
+func (r *sessionRegistry) resume(h *handler, token string) ([]*Notifier, error) {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+	if h.session != nil {
+		return nil, errSessionExists
+	}
+
+	r.mu.Lock()
+	s := r.sessions[token]
+	switch {
+	case s == nil:
+		r.mu.Unlock()
+		if h.fromOtherEpoch(sessionEpoch(token)) {
+			return nil, &unknownSubscriptionError{h.epoch}
+		}
+		return nil, errSessionNotFound
+	case s.attached:
+		r.mu.Unlock()
+		return nil, errSessionInUse
+	}
+	s.expire.Stop()
+	s.attached = true
+	subs := s.subs
+	s.subs = nil
+	r.mu.Unlock()
+
+	h.session = s
+	notifiers := make([]*Notifier, 0, len(subs))
+	for _, sub := range subs {
+		if !sub.notifier.attach(h) {
+			sub.close(errSessionBufferFull)
+			continue
+		}
+		notifiers = append(notifiers, sub.notifier)
+	}
+	return notifiers, nil
+}
+
+// handleSession processes the rpc_newSession and rpc_resumeSession methods.
+func (h *handler) handleSession(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if !h.allowSubscribe {
+		return msg.errorResponse(ErrNotificationsUnsupported)
+	}
+	if msg.Method == newSessionMethod {
+		token, err := h.sessions.create(h)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		return msg.response(token)
+	}
+
+	args, err := parsePositionalArguments(msg.Params, []reflect.Type{stringType}, h.decodeConfig())
+	if err != nil {
+		return msg.errorResponse(&invalidParamsError{err.Error()})
+	}
+	notifiers, err := h.sessions.resume(h, args[0].String())
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	ids := make([]ID, len(notifiers))
+	for i, n := range notifiers {
+		ids[i] = n.sub.ID
+	}
+	// The subscriptions are registered and activated after the response is sent.
+	cp.notifiers = append(cp.notifiers, notifiers...)
+	return msg.response(ids)
+}
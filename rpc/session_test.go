@@ -0,0 +1,253 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// sessionTestService provides a subscription which sends the values received on a channel.
+type sessionTestService struct {
+	values   chan int
+	notified chan struct{}
+	ended    chan error
+}
+
+func newSessionTestService() *sessionTestService {
+	return &sessionTestService{
+		values:   make(chan int),
+		notified: make(chan struct{}),
+		ended:    make(chan error, 1),
+	}
+}
+
+func (s *sessionTestService) Feed(ctx context.Context) (*Subscription, error) {
+	notifier, _ := NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case v := <-s.values:
+				notifier.Notify(sub.ID, v)
+				s.notified <- struct{}{}
+			case err := <-sub.Err():
+				s.ended <- err
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// send makes the subscription send v and waits for Notify to return. It must not be
+// used while the notification is sent to a connection.
+func (s *sessionTestService) send(v int) {
+	s.values <- v
+	<-s.notified
+}
+
+// rawConn is a raw JSON connection to a server.
+type rawConn struct {
+	t    *testing.T
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+	id   int
+}
+
+func newRawConn(t *testing.T, server *Server) *rawConn {
+	p1, p2 := net.Pipe()
+	go server.ServeCodec(NewCodec(p1), 0)
+	t.Cleanup(func() { p2.Close() })
+	p2.SetDeadline(time.Now().Add(10 * time.Second))
+	return &rawConn{t: t, conn: p2, enc: json.NewEncoder(p2), dec: json.NewDecoder(p2)}
+}
+
+// call performs a call and returns the response.
+func (c *rawConn) call(method string, params ...any) *jsonrpcMessage {
+	c.t.Helper()
+	c.id++
+	p, _ := json.Marshal(params)
+	req := &jsonrpcMessage{Version: vsn, ID: json.RawMessage(fmt.Sprint(c.id)), Method: method, Params: p}
+	if err := c.enc.Encode(req); err != nil {
+		c.t.Fatal(err)
+	}
+	return c.read()
+}
+
+func (c *rawConn) read() *jsonrpcMessage {
+	var msg jsonrpcMessage
+	if err := c.dec.Decode(&msg); err != nil {
+		c.t.Helper()
+		c.t.Fatal(err)
+	}
+	return &msg
+}
+
+// readNotification reads a notification and checks its subscription id and value.
+func (c *rawConn) readNotification(subid string, want int) {
+	c.t.Helper()
+	msg := c.read()
+	var result subscriptionResult
+	if err := json.Unmarshal(msg.Params, &result); err != nil {
+		c.t.Fatal(err)
+	}
+	if result.ID != subid || string(result.Result) != fmt.Sprint(want) {
+		c.t.Fatalf("wrong notification %s, want value %d for %s", msg.Params, want, subid)
+	}
+}
+
+func waitSessionDetached(t *testing.T, server *Server, token string) {
+	for i := 0; i < 500; i++ {
+		server.sessions.mu.Lock()
+		s := server.sessions.sessions[token]
+		detached := s != nil && !s.attached
+		server.sessions.mu.Unlock()
+		if detached {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("session not detached")
+}
+
+func TestSessionResume(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	server.SetSessionResumption(time.Minute, 10)
+	defer server.Stop()
+	service := newSessionTestService()
+	server.RegisterName("session", service)
+
+	// Create a session and subscription on the first connection.
+	conn1 := newRawConn(t, server)
+	var token, subid string
+	json.Unmarshal(conn1.call("rpc_newSession").Result, &token)
+	json.Unmarshal(conn1.call("session_subscribe", "feed").Result, &subid)
+	if token == "" || subid == "" {
+		t.Fatal("missing session token or subscription id")
+	}
+	service.values <- 1
+	conn1.readNotification(subid, 1)
+	<-service.notified
+
+	// Drop the connection. Notifications are buffered.
+	conn1.conn.Close()
+	waitSessionDetached(t, server, token)
+	service.send(2)
+	service.send(3)
+
+	// Resume on another connection.
+	conn2 := newRawConn(t, server)
+	if resp := conn2.call("rpc_resumeSession", "invalid"); resp.Error == nil || resp.Error.Message != errSessionNotFound.Error() {
+		t.Fatalf("wrong response for invalid token: %+v", resp.Error)
+	}
+	resp := conn2.call("rpc_resumeSession", token)
+	var ids []string
+	if err := json.Unmarshal(resp.Result, &ids); err != nil || len(ids) != 1 || ids[0] != subid {
+		t.Fatalf("wrong resume result %s (error %v)", resp.Result, resp.Error)
+	}
+	conn2.readNotification(subid, 2)
+	conn2.readNotification(subid, 3)
+	service.values <- 4
+	conn2.readNotification(subid, 4)
+	<-service.notified
+
+	// Unsubscribing works on the new connection.
+	if resp := conn2.call("session_unsubscribe", subid); resp.Error != nil {
+		t.Fatal("unsubscribe failed:", resp.Error)
+	}
+	if err := <-service.ended; err != nil {
+		t.Fatal("unexpected subscription error:", err)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	clock := new(mclock.Simulated)
+	server.SetClock(clock)
+	server.SetSessionResumption(time.Minute, 1)
+	defer server.Stop()
+	service := newSessionTestService()
+	server.RegisterName("session", service)
+
+	conn := newRawConn(t, server)
+	var token string
+	json.Unmarshal(conn.call("rpc_newSession").Result, &token)
+	conn.call("session_subscribe", "feed")
+	conn.conn.Close()
+	waitSessionDetached(t, server, token)
+
+	// The session expires after the TTL has passed on the server clock.
+	clock.Run(time.Minute - time.Second)
+	select {
+	case err := <-service.ended:
+		t.Fatalf("subscription ended before the session expired: %v", err)
+	default:
+	}
+	clock.Run(time.Second)
+	select {
+	case err := <-service.ended:
+		if err != errSessionExpired {
+			t.Fatalf("wrong subscription error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended")
+	}
+	conn = newRawConn(t, server)
+	if resp := conn.call("rpc_resumeSession", token); resp.Error == nil {
+		t.Fatal("expired session resumed")
+	}
+}
+
+func TestSessionBufferOverflow(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	server.SetSessionResumption(time.Minute, 1)
+	defer server.Stop()
+	service := newSessionTestService()
+	server.RegisterName("session", service)
+
+	conn := newRawConn(t, server)
+	var token string
+	json.Unmarshal(conn.call("rpc_newSession").Result, &token)
+	conn.call("session_subscribe", "feed")
+	conn.conn.Close()
+	waitSessionDetached(t, server, token)
+	service.send(1)
+	service.send(2)
+
+	conn = newRawConn(t, server)
+	resp := conn.call("rpc_resumeSession", token)
+	if string(resp.Result) != "[]" {
+		t.Fatalf("wrong resume result %s (error %v)", resp.Result, resp.Error)
+	}
+	if err := <-service.ended; err != errSessionBufferFull {
+		t.Fatalf("wrong subscription error %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultShadowTimeout     = 10 * time.Second
+	defaultShadowMaxInflight = 64
+)
+
+// ShadowConfig configures mirroring of calls to a secondary 'shadow' endpoint. Shadow
+// calls are performed asynchronously after the primary call has completed, and their
+// results never reach the caller. This can be used to test a new server version with
+// production traffic.
+type ShadowConfig struct {
+	// Client is connected to the shadow endpoint.
+	Client *Client
+
+	// SampleRate is the fraction of calls mirrored to the shadow endpoint, between
+	// 0 and 1.
+	SampleRate float64
+
+	// Timeout bounds the duration of shadow calls. The default is 10s.
+	Timeout time.Duration
+
+	// MaxInflight limits the number of concurrent shadow calls. Calls are not mirrored
+	// while the limit is reached. The default is 64.
+	MaxInflight int
+
+	// Compare, if set, is called with the results of the primary and shadow calls.
+	// It is called on a background goroutine.
+	Compare func(method string, primary, shadow ShadowResult)
+}
+
+// ShadowResult is the outcome of a call made to the primary or shadow endpoint.
+type ShadowResult struct {
+	Result json.RawMessage
+	Err    error
+}
+
+// shadower mirrors calls according to a ShadowConfig.
+type shadower struct {
+	cfg      ShadowConfig
+	inflight atomic.Int32
+}
+
+func newShadower(cfg ShadowConfig) *shadower {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultShadowTimeout
+	}
+	if cfg.MaxInflight == 0 {
+		cfg.MaxInflight = defaultShadowMaxInflight
+	}
+	return &shadower{cfg: cfg}
+}
+
+// mirror sends the call made with ctx to the shadow endpoint if it is selected by
+// sampling.
+func (s *shadower) mirror(ctx context.Context, method string, params json.RawMessage, primary ShadowResult) {
+	if args, ok := s.prepare(method, params); ok {
+		s.send(ctx, method, args, primary)
+	}
+}
+
+// prepare decides whether a call is mirrored and returns its arguments. Calls with named
+// parameters and methods declared non-idempotent on the shadow client are not mirrored.
+// When it returns true, an inflight slot is reserved for the call, see acquire.
+func (s *shadower) prepare(method string, params json.RawMessage) ([]interface{}, bool) {
+	if !s.cfg.Client.isIdempotent(method) {
+		return nil, false
+	}
+	args, ok := shadowArgs(params)
+	if !ok || !s.acquire() {
+		return nil, false
+	}
+	return args, true
+}
+
+// shadowArgs splits the encoded positional parameters of a call.
+func shadowArgs(params json.RawMessage) ([]interface{}, bool) {
+	var raw []json.RawMessage
+	if len(params) > 0 && json.Unmarshal(params, &raw) != nil {
+		return nil, false
+	}
+	args := make([]interface{}, len(raw))
+	for i := range raw {
+		args[i] = raw[i]
+	}
+	return args, true
+}
+
+// acquire decides whether a call is mirrored, and reserves an inflight slot for it.
+func (s *shadower) acquire() bool {
+	if s.cfg.SampleRate <= 0 || rand.Float64() >= s.cfg.SampleRate {
+		return false
+	}
+	if int(s.inflight.Add(1)) > s.cfg.MaxInflight {
+		s.inflight.Add(-1)
+		shadowSkippedCounter.Inc(1)
+		return false
+	}
+	return true
+}
+
+// send performs the shadow call in the background, using a slot reserved by acquire.
+// The shadow call keeps the values and the deadline of ctx, the context of the primary
+// call, but isn't canceled when the primary call completes.
+func (s *shadower) send(ctx context.Context, method string, args []interface{}, primary ShadowResult) {
+	shadowSentCounter.Inc(1)
+
+	deadline, hasDeadline := ctx.Deadline()
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer s.inflight.Add(-1)
+
+		ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+		if hasDeadline {
+			var cancelDeadline context.CancelFunc
+			ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+			defer cancelDeadline()
+		}
+		var shadow ShadowResult
+		shadow.Err = s.cfg.Client.CallContext(ctx, &shadow.Result, method, args...)
+		if shadow.Err != nil {
+			if _, ok := shadow.Err.(Error); !ok {
+				// Only count transport failures, error responses are compared.
+				shadowFailedCounter.Inc(1)
+			}
+		}
+		if s.cfg.Compare != nil {
+			s.cfg.Compare(method, primary, shadow)
+		}
+	}()
+}
+
+// WithShadow makes the client mirror a sample of calls made through CallContext to a
+// shadow endpoint. See ShadowConfig for details.
+//
+// Note that batch calls, notifications and subscriptions are not mirrored. Neither are
+// dry runs and methods declared non-idempotent on either client, see
+// WithNonIdempotentMethods.
+func WithShadow(cfg ShadowConfig) ClientOption {
+	if cfg.Client == nil {
+		panic("nil shadow client")
+	}
+	return optionFunc(func(c *clientConfig) {
+		c.shadow = &cfg
+	})
+}
+
+// MirrorMiddleware returns a server middleware which mirrors a sample of calls to
+// upstream, e.g. a node running a new version, in order to validate it with production
+// traffic. The fraction of mirrored calls is given by sampleRate. Mirrored calls are
+// performed asynchronously after the call has completed, with the parameters sent by
+// the client, and never affect the response to the client.
+//
+// Results are compared like ShadowDiffer does, and divergences are counted by the
+// rpc/shadow/diverged metric. If compare is set, it is called with the results of every
+// mirrored call on a background goroutine. Pass ShadowDiffer.Compare to collect a
+// report of the divergences.
+//
+// Subscriptions, streamed results, dry runs and calls with named parameters are not
+// mirrored. Neither are methods declared non-idempotent on upstream, which must not be
+// sent twice, see WithNonIdempotentMethods.
+func MirrorMiddleware(upstream *Client, sampleRate float64, compare func(method string, primary, shadow ShadowResult)) Middleware {
+	if upstream == nil {
+		panic("nil mirror client")
+	}
+	differ := NewShadowDiffer()
+	s := newShadower(ShadowConfig{
+		Client:     upstream,
+		SampleRate: sampleRate,
+		Compare: func(method string, primary, shadow ShadowResult) {
+			if !differ.equal(primary, shadow) {
+				shadowDivergedCounter.Inc(1)
+			}
+			if compare != nil {
+				compare(method, primary, shadow)
+			}
+		},
+	})
+	return func(ctx context.Context, method string, args []reflect.Value, next func(ctx context.Context, method string, args []reflect.Value) *MethodResult) *MethodResult {
+		result := next(ctx, method, args)
+		if IsDryRun(ctx) {
+			return result
+		}
+		if _, ok := NotifierFromContext(ctx); ok {
+			return result
+		}
+		if _, ok := result.Result.(*StreamedResult); ok {
+			return result
+		}
+		info, ok := RequestInfoFromContext(ctx)
+		if !ok {
+			return result
+		}
+		mirrorArgs, ok := s.prepare(method, info.Params)
+		if !ok {
+			return result
+		}
+		primary := ShadowResult{Err: result.Error}
+		if result.Error == nil {
+			enc, err := json.Marshal(result.Result)
+			if err != nil {
+				s.inflight.Add(-1)
+				return result
+			}
+			primary.Result = enc
+		}
+		s.send(ctx, method, mirrorArgs, primary)
+		return result
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxShadowMismatchSamples is the number of mismatches kept per method.
+const maxShadowMismatchSamples = 10
+
+// ShadowDiffer compares the results of primary and shadow calls and aggregates
+// mismatches per method. Its Compare method can be used as ShadowConfig.Compare.
+//
+// Results are canonicalized before comparison: object key order is ignored, hex
+// strings are compared case-insensitively and configured volatile fields are removed.
+type ShadowDiffer struct {
+	ignore  map[string]struct{}
+	mu      sync.Mutex
+	methods map[string]*ShadowMethodReport
+}
+
+// ShadowMethodReport contains the comparison statistics of a single method.
+type ShadowMethodReport struct {
+	Method     string           `json:"method"`
+	Compared   uint64           `json:"compared"`
+	Mismatches uint64           `json:"mismatches"`
+	Samples    []ShadowMismatch `json:"samples,omitempty"`
+}
+
+// ShadowMismatch is a recorded difference between a primary and shadow result.
+type ShadowMismatch struct {
+	Time         time.Time       `json:"time"`
+	Primary      json.RawMessage `json:"primary,omitempty"`
+	Shadow       json.RawMessage `json:"shadow,omitempty"`
+	PrimaryError string          `json:"primaryError,omitempty"`
+	ShadowError  string          `json:"shadowError,omitempty"`
+}
+
+// NewShadowDiffer creates a differ. Object fields with any of the given names are
+// ignored when comparing results, at any nesting level.
+func NewShadowDiffer(ignoreFields ...string) *ShadowDiffer {
+	d := &ShadowDiffer{
+		ignore:  make(map[string]struct{}, len(ignoreFields)),
+		methods: make(map[string]*ShadowMethodReport),
+	}
+	for _, f := range ignoreFields {
+		d.ignore[f] = struct{}{}
+	}
+	return d
+}
+
+// Compare records the outcome of a shadowed call.
+func (d *ShadowDiffer) Compare(method string, primary, shadow ShadowResult) {
+	match := d.equal(primary, shadow)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r := d.methods[method]
+	if r == nil {
+		r = &ShadowMethodReport{Method: method}
+		d.methods[method] = r
+	}
+	r.Compared++
+	if match {
+		return
+	}
+	r.Mismatches++
+	m := ShadowMismatch{Time: time.Now(), Primary: primary.Result, Shadow: shadow.Result}
+	if primary.Err != nil {
+		m.PrimaryError = primary.Err.Error()
+	}
+	if shadow.Err != nil {
+		m.ShadowError = shadow.Err.Error()
+	}
+	if len(r.Samples) == maxShadowMismatchSamples {
+		r.Samples = slices.Delete(r.Samples, 0, 1)
+	}
+	r.Samples = append(r.Samples, m)
+}
+
+// Report returns the comparison statistics of all methods, sorted by method name.
+func (d *ShadowDiffer) Report() []ShadowMethodReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	report := make([]ShadowMethodReport, 0, len(d.methods))
+	for _, r := range d.methods {
+		cpy := *r
+		cpy.Samples = slices.Clone(r.Samples)
+		report = append(report, cpy)
+	}
+	slices.SortFunc(report, func(a, b ShadowMethodReport) int {
+		return strings.Compare(a.Method, b.Method)
+	})
+	return report
+}
+
+// Reset clears all recorded statistics.
+func (d *ShadowDiffer) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.methods)
+}
+
+// API returns the RPC API of the differ. It provides the method admin_shadowReport.
+func (d *ShadowDiffer) API() API {
+	return API{Namespace: "admin", Service: &shadowDifferService{d}}
+}
+
+type shadowDifferService struct {
+	differ *ShadowDiffer
+}
+
+// ShadowReport returns the aggregated shadow mismatch report.
+func (api *shadowDifferService) ShadowReport() []ShadowMethodReport {
+	return api.differ.Report()
+}
+
+func (d *ShadowDiffer) equal(primary, shadow ShadowResult) bool {
+	if primary.Err != nil || shadow.Err != nil {
+		return equalShadowErrors(primary.Err, shadow.Err)
+	}
+	a, errA := d.canonicalize(primary.Result)
+	b, errB := d.canonicalize(shadow.Result)
+	if errA != nil || errB != nil {
+		return bytes.Equal(primary.Result, shadow.Result)
+	}
+	return bytes.Equal(a, b)
+}
+
+// canonicalize re-encodes a JSON value in canonical form.
+func (d *ShadowDiffer) canonicalize(raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	// encoding/json sorts map keys, which takes care of object key order.
+	return json.Marshal(d.canonicalValue(v))
+}
+
+func (d *ShadowDiffer) canonicalValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			if _, ok := d.ignore[k]; ok {
+				delete(v, k)
+				continue
+			}
+			v[k] = d.canonicalValue(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = d.canonicalValue(elem)
+		}
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			return strings.ToLower(v)
+		}
+	}
+	return v
+}
+
+// equalShadowErrors reports whether two call errors are equivalent. Errors returned
+// by the servers are compared by code and message.
+func equalShadowErrors(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	var rpcA, rpcB Error
+	if errors.As(a, &rpcA) && errors.As(b, &rpcB) {
+		return rpcA.ErrorCode() == rpcB.ErrorCode() && rpcA.Error() == rpcB.Error()
+	}
+	return a.Error() == b.Error()
+}
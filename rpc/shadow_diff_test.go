@@ -0,0 +1,79 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestShadowDiffer(t *testing.T) {
+	t.Parallel()
+
+	d := NewShadowDiffer("timestamp")
+	result := func(s string) ShadowResult {
+		return ShadowResult{Result: json.RawMessage(s)}
+	}
+	tests := []struct {
+		method          string
+		primary, shadow ShadowResult
+		match           bool
+	}{
+		{"a", result(`{"x":1,"y":"0xAB"}`), result(`{"y":"0xab", "x":1}`), true},
+		{"a", result(`{"x":1,"timestamp":5}`), result(`{"x":1,"timestamp":6}`), true},
+		{"a", result(`[{"timestamp":5,"v":"0x1"}]`), result(`[{"v":"0x1"}]`), true},
+		{"a", result(`{"x":1}`), result(`{"x":2}`), false},
+		{"b", result(`"abc"`), result(`"ABC"`), false},
+		{"b", ShadowResult{Err: &jsonError{Code: 1, Message: "err"}}, ShadowResult{Err: &jsonError{Code: 1, Message: "err"}}, true},
+		{"b", ShadowResult{Err: &jsonError{Code: 1, Message: "err"}}, ShadowResult{Err: &jsonError{Code: 2, Message: "err"}}, false},
+		{"b", result(`1`), ShadowResult{Err: errors.New("timeout")}, false},
+	}
+	for i, test := range tests {
+		if got := d.equal(test.primary, test.shadow); got != test.match {
+			t.Errorf("test %d: equal = %v, want %v", i, got, test.match)
+		}
+		d.Compare(test.method, test.primary, test.shadow)
+	}
+
+	// Check the report via the admin API.
+	server := NewServer()
+	defer server.Stop()
+	api := d.API()
+	if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var report []ShadowMethodReport
+	if err := client.Call(&report, "admin_shadowReport"); err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("wrong report length %d", len(report))
+	}
+	if r := report[0]; r.Method != "a" || r.Compared != 4 || r.Mismatches != 1 || len(r.Samples) != 1 {
+		t.Errorf("wrong report for method a: %+v", r)
+	}
+	if r := report[1]; r.Method != "b" || r.Compared != 4 || r.Mismatches != 3 || len(r.Samples) != 3 {
+		t.Errorf("wrong report for method b: %+v", r)
+	}
+	if s := report[1].Samples[2]; s.PrimaryError != "" || s.ShadowError != "timeout" {
+		t.Errorf("wrong sample: %+v", s)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type shadowComparison struct {
+	method          string
+	primary, shadow ShadowResult
+}
+
+func TestClientShadow(t *testing.T) {
+	t.Parallel()
+
+	primarySrv, shadowSrv := newTestServer(), newTestServer()
+	defer primarySrv.Stop()
+	defer shadowSrv.Stop()
+	shadowClient := DialInProc(shadowSrv)
+	defer shadowClient.Close()
+
+	results := make(chan shadowComparison, 1)
+	client := dialInProcWithOptions(primarySrv, WithShadow(ShadowConfig{
+		Client:     shadowClient,
+		SampleRate: 1,
+		Compare: func(method string, primary, shadow ShadowResult) {
+			results <- shadowComparison{method, primary, shadow}
+		},
+	}), WithNonIdempotentMethods("test_sleep"))
+	defer client.Close()
+
+	var resp echoResult
+	if err := client.Call(&resp, "test_echo", "hello", 1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-results:
+		if r.method != "test_echo" {
+			t.Errorf("wrong method %q", r.method)
+		}
+		if r.primary.Err != nil || r.shadow.Err != nil {
+			t.Errorf("unexpected errors: %v, %v", r.primary.Err, r.shadow.Err)
+		}
+		if string(r.primary.Result) != string(r.shadow.Result) {
+			t.Errorf("results differ: %s != %s", r.primary.Result, r.shadow.Result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for shadow comparison")
+	}
+
+	// Dry runs and non-idempotent methods are not mirrored.
+	if err := client.CallContext(NewContextWithDryRun(context.Background()), nil, "test_echo", "dry", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_sleep", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Error responses are mirrored too.
+	client.Call(nil, "test_returnError")
+	r := <-results
+	if r.method != "test_returnError" {
+		t.Fatalf("wrong method mirrored: %q", r.method)
+	}
+	if r.primary.Err == nil || r.shadow.Err == nil || r.primary.Err.Error() != r.shadow.Err.Error() {
+		t.Errorf("wrong errors: %v, %v", r.primary.Err, r.shadow.Err)
+	}
+}
+
+func TestMirrorMiddleware(t *testing.T) {
+	t.Parallel()
+
+	primarySrv, upstreamSrv := newTestServer(), newTestServer()
+	defer primarySrv.Stop()
+	defer upstreamSrv.Stop()
+	upstream := dialInProcWithOptions(upstreamSrv, WithNonIdempotentMethods("test_sleep"))
+	defer upstream.Close()
+
+	results := make(chan shadowComparison, 2)
+	primarySrv.SetMiddlewares([]Middleware{MirrorMiddleware(upstream, 1, func(method string, primary, shadow ShadowResult) {
+		results <- shadowComparison{method, primary, shadow}
+	})})
+	client := DialInProc(primarySrv)
+	defer client.Close()
+
+	var resp echoResult
+	if err := client.Call(&resp, "test_echo", "hello", 1, &echoArgs{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-results:
+		if r.method != "test_echo" || r.primary.Err != nil || r.shadow.Err != nil {
+			t.Fatalf("wrong comparison %+v", r)
+		}
+		if !NewShadowDiffer().equal(r.primary, r.shadow) {
+			t.Errorf("results differ: %s != %s", r.primary.Result, r.shadow.Result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for mirrored call")
+	}
+
+	// Non-idempotent methods are not mirrored.
+	if err := client.Call(nil, "test_sleep", 0); err != nil {
+		t.Fatal(err)
+	}
+	client.Call(nil, "test_returnError")
+	r := <-results
+	if r.method != "test_returnError" {
+		t.Fatalf("wrong method mirrored: %q", r.method)
+	}
+	if !equalShadowErrors(r.primary.Err, r.shadow.Err) {
+		t.Errorf("errors differ: %v, %v", r.primary.Err, r.shadow.Err)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+var errSharedMemoryUnsupported = errors.New("rpc: shared memory transport is not supported on this platform")
+
+// ListenSharedMemory creates a listener for the experimental shared memory transport.
+// It is only available on unix platforms.
+func ListenSharedMemory(endpoint string, ringSize int) (net.Listener, error) {
+	return nil, errSharedMemoryUnsupported
+}
+
+// DialSharedMemory creates a client which connects to a server using the shared memory
+// transport. It is only available on unix platforms.
+func DialSharedMemory(ctx context.Context, endpoint string, options ...ClientOption) (*Client, error) {
+	return nil, errSharedMemoryUnsupported
+}
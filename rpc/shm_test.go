@@ -0,0 +1,152 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package rpc
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSharedMemoryTransport(t *testing.T) {
+	t.Parallel()
+
+	endpoint := filepath.Join(t.TempDir(), "shm.ipc")
+	l, err := ListenSharedMemory(endpoint, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(l)
+	client, err := DialSharedMemory(context.Background(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if res.String != "hello" || res.Int != 10 || res.Args.S != "world" {
+		t.Fatalf("wrong result %+v", res)
+	}
+	var info PeerInfo
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Transport != "ipc" {
+		t.Fatalf("wrong transport %q", info.Transport)
+	}
+
+	// Subscriptions work over the rings.
+	nc := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", nc, "someSubscription", 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	for i := range 3 {
+		if v := <-nc; v != i {
+			t.Fatalf("wrong notification %d, want %d", v, i)
+		}
+	}
+}
+
+func TestSharedMemoryLargeMessages(t *testing.T) {
+	t.Parallel()
+
+	// Messages are much larger than the rings and must be streamed through them.
+	endpoint := filepath.Join(t.TempDir(), "shm.ipc")
+	l, err := ListenSharedMemory(endpoint, minSharedMemoryRingSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(l)
+	client, err := DialSharedMemory(context.Background(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	large := strings.Repeat("x", 200*minSharedMemoryRingSize)
+	for range 3 {
+		var res echoResult
+		if err := client.Call(&res, "test_echo", large, 1, nil); err != nil {
+			t.Fatal(err)
+		}
+		if res.String != large {
+			t.Fatal("wrong result")
+		}
+	}
+}
+
+func TestSharedMemoryServerStop(t *testing.T) {
+	t.Parallel()
+
+	endpoint := filepath.Join(t.TempDir(), "shm.ipc")
+	l, err := ListenSharedMemory(endpoint, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(l)
+	client, err := DialSharedMemory(context.Background(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "test_noArgsRets"); err == nil || ctx.Err() != nil {
+		t.Fatalf("expected connection error after server stop, got %v", err)
+	}
+}
+
+func TestSharedMemoryRingSize(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{0, defaultSharedMemoryRingSize},
+		{1, minSharedMemoryRingSize},
+		{5000, 8192},
+		{1 << 16, 1 << 16},
+	}
+	for _, test := range tests {
+		if got, err := sharedMemoryRingSize(test.in); err != nil || got != test.want {
+			t.Errorf("sharedMemoryRingSize(%d) = %d, %v; want %d", test.in, got, err, test.want)
+		}
+	}
+	if _, err := sharedMemoryRingSize(-1); err == nil {
+		t.Error("negative size accepted")
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultSharedMemoryRingSize = 1 << 20
+	minSharedMemoryRingSize     = 4096
+	maxSharedMemoryRingSize     = 1 << 30
+
+	// Each ring starts with a header holding the write and read positions on separate
+	// cache lines.
+	shmHeaderSize = 256
+	shmWriteOff   = 0
+	shmReadOff    = 64
+
+	shmHandshakeTimeout = 5 * time.Second
+
+	// Waiting for the peer spins for shmSpins iterations before it starts to sleep,
+	// for at most shmMaxSleep at a time.
+	shmSpins    = 1000
+	shmMaxSleep = time.Millisecond
+)
+
+// shmMagic starts the handshake message, followed by the ring size.
+var shmMagic = [8]byte{'R', 'P', 'C', 'S', 'H', 'M', '0', '1'}
+
+var errBadSharedMemoryHandshake = errors.New("invalid shared memory handshake")
+
+// ListenSharedMemory creates a listener for the experimental shared memory transport.
+// Clients connect to a unix socket at endpoint, which is used to set up a pair of ring
+// buffers in shared memory, and to detect when the peer goes away. Messages are then
+// exchanged through the rings without system calls, using the standard JSON codec.
+// Serve the listener with Server.ServeListener, and connect with DialSharedMemory.
+//
+// The ringSize is the capacity of each direction in bytes, rounded up to a power of
+// two. Zero selects the default of 1 MiB. Messages larger than the ring are streamed
+// through it.
+//
+// Waiting for data spins before falling back to short sleeps, so the transport trades
+// CPU time for latency. It is meant for few, busy connections between processes on the
+// same host.
+func ListenSharedMemory(endpoint string, ringSize int) (net.Listener, error) {
+	size, err := sharedMemoryRingSize(ringSize)
+	if err != nil {
+		return nil, err
+	}
+	l, err := ipcListen(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &shmListener{Listener: l, ringSize: size}, nil
+}
+
+// DialSharedMemory creates a client which connects to a server using the shared memory
+// transport, see ListenSharedMemory.
+func DialSharedMemory(ctx context.Context, endpoint string, options ...ClientOption) (*Client, error) {
+	cfg := new(clientConfig)
+	for _, opt := range options {
+		opt.applyOption(cfg)
+	}
+	return newClient(ctx, cfg, func(ctx context.Context) (ServerCodec, error) {
+		conn, err := dialSharedMemory(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return NewCodec(conn), nil
+	})
+}
+
+func sharedMemoryRingSize(size int) (int, error) {
+	switch {
+	case size == 0:
+		return defaultSharedMemoryRingSize, nil
+	case size < 0 || size > maxSharedMemoryRingSize:
+		return 0, fmt.Errorf("invalid shared memory ring size %d", size)
+	}
+	n := minSharedMemoryRingSize
+	for n < size {
+		n <<= 1
+	}
+	return n, nil
+}
+
+type shmListener struct {
+	net.Listener
+	ringSize int
+}
+
+// Accept waits for a client and sets up the shared memory of the connection. Clients
+// which fail the handshake are dropped.
+func (l *shmListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		sc, err := acceptSharedMemory(conn.(*net.UnixConn), l.ringSize)
+		if err != nil {
+			log.Debug("Shared memory RPC handshake failed", "err", err)
+			conn.Close()
+			continue
+		}
+		return sc, nil
+	}
+}
+
+// acceptSharedMemory creates the shared memory of a connection and passes it to the
+// client.
+func acceptSharedMemory(ctrl *net.UnixConn, ringSize int) (*shmConn, error) {
+	ctrl.SetDeadline(time.Now().Add(shmHandshakeTimeout))
+	defer ctrl.SetDeadline(time.Time{})
+
+	f, err := createSharedMemoryFile()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	total := 2 * (shmHeaderSize + ringSize)
+	if err := f.Truncate(int64(total)); err != nil {
+		return nil, err
+	}
+	mem, err := syscall.Mmap(int(f.Fd()), 0, total, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	msg := make([]byte, len(shmMagic)+8)
+	copy(msg, shmMagic[:])
+	binary.LittleEndian.PutUint64(msg[len(shmMagic):], uint64(ringSize))
+	if _, _, err := ctrl.WriteMsgUnix(msg, syscall.UnixRights(int(f.Fd())), nil); err != nil {
+		syscall.Munmap(mem)
+		return nil, err
+	}
+	// The first ring carries data from the client to the server.
+	return newShmConn(ctrl, mem, ringSize, 0, 1), nil
+}
+
+// createSharedMemoryFile creates an unlinked file for the rings, in memory if possible.
+func createSharedMemoryFile() (*os.File, error) {
+	dir := os.TempDir()
+	if fi, err := os.Stat("/dev/shm"); err == nil && fi.IsDir() {
+		dir = "/dev/shm"
+	}
+	f, err := os.CreateTemp(dir, "rpc-shm-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	return f, nil
+}
+
+// dialSharedMemory connects to a shared memory listener.
+func dialSharedMemory(ctx context.Context, endpoint string) (*shmConn, error) {
+	conn, err := newIPCConnection(ctx, endpoint, 0)
+	if err != nil {
+		return nil, err
+	}
+	ctrl := conn.(*net.UnixConn)
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(shmHandshakeTimeout)
+	}
+	ctrl.SetDeadline(deadline)
+	sc, err := setupSharedMemory(ctrl)
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	ctrl.SetDeadline(time.Time{})
+	return sc, nil
+}
+
+// setupSharedMemory maps the shared memory received from the server.
+func setupSharedMemory(ctrl *net.UnixConn) (*shmConn, error) {
+	msg := make([]byte, len(shmMagic)+8)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := ctrl.ReadMsgUnix(msg, oob)
+	if err != nil {
+		return nil, err
+	}
+	cmsgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(cmsgs) != 1 {
+		return nil, errBadSharedMemoryHandshake
+	}
+	fds, err := syscall.ParseUnixRights(&cmsgs[0])
+	if err != nil || len(fds) != 1 {
+		return nil, errBadSharedMemoryHandshake
+	}
+	f := os.NewFile(uintptr(fds[0]), "rpc-shm")
+	defer f.Close()
+
+	if n != len(msg) || !bytes.Equal(msg[:len(shmMagic)], shmMagic[:]) {
+		return nil, errBadSharedMemoryHandshake
+	}
+	size := binary.LittleEndian.Uint64(msg[len(shmMagic):])
+	ringSize, err := sharedMemoryRingSize(int(min(size, maxSharedMemoryRingSize+1)))
+	if err != nil || uint64(ringSize) != size {
+		return nil, errBadSharedMemoryHandshake
+	}
+	total := 2 * (shmHeaderSize + ringSize)
+	if fi, err := f.Stat(); err != nil || fi.Size() < int64(total) {
+		return nil, errBadSharedMemoryHandshake
+	}
+	mem, err := syscall.Mmap(int(f.Fd()), 0, total, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return newShmConn(ctrl, mem, ringSize, 1, 0), nil
+}
+
+// shmRing is a single-producer, single-consumer byte ring in shared memory. The write
+// and read positions increase monotonically and are only advanced by the writer and
+// reader respectively.
+type shmRing struct {
+	write *uint64
+	read  *uint64
+	data  []byte
+	mask  uint64
+}
+
+func newShmRing(mem []byte, size int) *shmRing {
+	return &shmRing{
+		write: (*uint64)(unsafe.Pointer(&mem[shmWriteOff])),
+		read:  (*uint64)(unsafe.Pointer(&mem[shmReadOff])),
+		data:  mem[shmHeaderSize : shmHeaderSize+size],
+		mask:  uint64(size - 1),
+	}
+}
+
+// readSome moves buffered bytes into p. It returns zero if the ring is empty.
+func (r *shmRing) readSome(p []byte) int {
+	rd := atomic.LoadUint64(r.read)
+	n := min(uint64(len(p)), atomic.LoadUint64(r.write)-rd)
+	if n == 0 {
+		return 0
+	}
+	c := copy(p[:n], r.data[rd&r.mask:])
+	copy(p[c:n], r.data)
+	atomic.StoreUint64(r.read, rd+n)
+	return int(n)
+}
+
+// writeSome moves bytes of p into the ring. It returns zero if the ring is full.
+func (r *shmRing) writeSome(p []byte) int {
+	w := atomic.LoadUint64(r.write)
+	n := min(uint64(len(p)), uint64(len(r.data))-(w-atomic.LoadUint64(r.read)))
+	if n == 0 {
+		return 0
+	}
+	c := copy(r.data[w&r.mask:], p[:n])
+	copy(r.data, p[c:n])
+	atomic.StoreUint64(r.write, w+n)
+	return int(n)
+}
+
+// shmConn is a connection over a pair of shared memory rings. The control socket stays
+// open for the lifetime of the connection to detect when the peer is gone.
+type shmConn struct {
+	ctrl    *net.UnixConn
+	mem     []byte
+	in, out *shmRing
+
+	readMu, writeMu             sync.Mutex
+	readDeadline, writeDeadline atomic.Int64 // unix nanoseconds, zero means none
+
+	mapMu      sync.RWMutex // held by reads and writes, keeps the memory mapped
+	closed     atomic.Bool
+	peerClosed atomic.Bool
+	closeOnce  sync.Once
+}
+
+func newShmConn(ctrl *net.UnixConn, mem []byte, ringSize int, in, out int) *shmConn {
+	ring := func(i int) *shmRing {
+		off := i * (shmHeaderSize + ringSize)
+		return newShmRing(mem[off:off+shmHeaderSize+ringSize], ringSize)
+	}
+	c := &shmConn{ctrl: ctrl, mem: mem, in: ring(in), out: ring(out)}
+	go c.watchPeer()
+	return c
+}
+
+// watchPeer waits for the control socket to be closed by the peer.
+func (c *shmConn) watchPeer() {
+	buf := make([]byte, 1)
+	for {
+		if _, err := c.ctrl.Read(buf); err != nil {
+			c.peerClosed.Store(true)
+			return
+		}
+	}
+}
+
+func (c *shmConn) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	c.mapMu.RLock()
+	defer c.mapMu.RUnlock()
+
+	var w shmWaiter
+	for {
+		if c.closed.Load() {
+			return 0, net.ErrClosed
+		}
+		// Check for the peer going away before reading, so data written before it left
+		// is still delivered.
+		peerClosed := c.peerClosed.Load()
+		if n := c.in.readSome(p); n > 0 {
+			return n, nil
+		}
+		if peerClosed {
+			return 0, io.EOF
+		}
+		if err := w.wait(c.readDeadline.Load()); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (c *shmConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.mapMu.RLock()
+	defer c.mapMu.RUnlock()
+
+	var (
+		w       shmWaiter
+		written int
+	)
+	for written < len(p) {
+		if c.closed.Load() {
+			return written, net.ErrClosed
+		}
+		if c.peerClosed.Load() {
+			return written, io.ErrClosedPipe
+		}
+		if n := c.out.writeSome(p[written:]); n > 0 {
+			written += n
+			w = shmWaiter{}
+			continue
+		}
+		if err := w.wait(c.writeDeadline.Load()); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (c *shmConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closed.Store(true)
+		c.ctrl.Close()
+		// Wait for reads and writes to notice the close before unmapping.
+		c.mapMu.Lock()
+		syscall.Munmap(c.mem)
+		c.mapMu.Unlock()
+	})
+	return nil
+}
+
+func (c *shmConn) LocalAddr() net.Addr  { return c.ctrl.LocalAddr() }
+func (c *shmConn) RemoteAddr() net.Addr { return c.ctrl.RemoteAddr() }
+
+func (c *shmConn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+	return c.SetWriteDeadline(t)
+}
+
+func (c *shmConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.Store(deadlineNanos(t))
+	return nil
+}
+
+func (c *shmConn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.Store(deadlineNanos(t))
+	return nil
+}
+
+func deadlineNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// shmWaiter waits for the peer to make progress on a ring.
+type shmWaiter struct {
+	spins int
+	sleep time.Duration
+}
+
+func (w *shmWaiter) wait(deadline int64) error {
+	if deadline != 0 && time.Now().UnixNano() >= deadline {
+		return os.ErrDeadlineExceeded
+	}
+	if w.spins < shmSpins {
+		w.spins++
+		runtime.Gosched()
+		return nil
+	}
+	w.sleep = min(max(2*w.sleep, 10*time.Microsecond), shmMaxSleep)
+	time.Sleep(w.sleep)
+	return nil
+}
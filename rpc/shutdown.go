@@ -0,0 +1,175 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+// shutdownNoticeMethod is the method of the notification sent to clients on
+// non-websocket connections before they are closed by Shutdown.
+const shutdownNoticeMethod = MetadataApi + "_shutdown"
+
+var errShuttingDown = errors.New("server is shutting down")
+
+// Shutdown stops the server gracefully. It stops accepting connections and HTTP
+// requests, rejects new calls on existing connections, and waits for the calls being
+// processed to finish. If ctx expires first, the contexts of the remaining calls are
+// canceled. Websocket connections are then closed with a close frame, clients on other
+// connections receive an rpc_shutdown notification before the connection is closed.
+//
+// Shutdown returns the number of calls which were canceled, along with the error of ctx
+// if it expired.
+func (s *Server) Shutdown(ctx context.Context) (int, error) {
+	if !s.run.Load() || !s.services.calls.shutdown() {
+		return 0, nil
+	}
+	log.Debug("RPC server shutting down gracefully")
+
+	var canceled int
+	err := s.services.calls.wait(ctx)
+	if err != nil {
+		canceled = s.services.calls.cancelAll()
+		log.Warn("RPC server shutdown canceled calls", "count", canceled)
+	}
+
+	s.mutex.Lock()
+	codecs := make([]ServerCodec, 0, len(s.codecs))
+	for codec := range s.codecs {
+		codecs = append(codecs, codec)
+	}
+	s.mutex.Unlock()
+	notice := &jsonrpcMessage{Version: vsn, Method: shutdownNoticeMethod, Params: json.RawMessage("[]")}
+	for _, codec := range codecs {
+		if wc, ok := codec.(*websocketCodec); ok {
+			wc.closeWithStatus(websocket.CloseGoingAway, "server shutting down")
+			continue
+		}
+		wctx, cancel := context.WithTimeout(context.Background(), drainCloseWriteTimeout)
+		codec.writeJSON(wctx, notice, false)
+		cancel()
+	}
+	s.Stop()
+	return canceled, err
+}
+
+// shuttingDown reports whether Shutdown has been called.
+func (s *Server) shuttingDown() bool {
+	return s.services.calls.closing.Load()
+}
+
+// rejectShutdown answers an HTTP request received during shutdown.
+func (s *Server) rejectShutdown(w http.ResponseWriter) {
+	w.Header().Set("Connection", "close")
+	http.Error(w, errShuttingDown.Error(), http.StatusServiceUnavailable)
+}
+
+// callTracker tracks the calls being processed, for Shutdown.
+type callTracker struct {
+	closing atomic.Bool
+
+	mu    sync.Mutex
+	calls map[*trackedCall]struct{}
+	idle  chan struct{} // closed when no calls remain after shutdown
+}
+
+type trackedCall struct {
+	cancel   context.CancelFunc
+	canceled bool
+}
+
+// start registers a call. It returns the context of the call, which is canceled if the
+// call is still running when the shutdown deadline expires, and a function which must
+// be called when the call has finished.
+func (t *callTracker) start(ctx context.Context) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closing.Load() {
+		return nil, nil, errShuttingDown
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c := &trackedCall{cancel: cancel}
+	if t.calls == nil {
+		t.calls = make(map[*trackedCall]struct{})
+	}
+	t.calls[c] = struct{}{}
+	return ctx, func() { t.finish(c) }, nil
+}
+
+// finish unregisters a call. Its context is not canceled here because streamed results
+// may still use it, it ends with the context of the request.
+func (t *callTracker) finish(c *trackedCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.calls, c)
+	if t.idle != nil && len(t.calls) == 0 {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// shutdown stops accepting calls. It returns false if it was called before.
+func (t *callTracker) shutdown() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.closing.CompareAndSwap(false, true) {
+		return false
+	}
+	if len(t.calls) > 0 {
+		t.idle = make(chan struct{})
+	}
+	return true
+}
+
+// wait blocks until all calls have finished after shutdown.
+func (t *callTracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	idle := t.idle
+	t.mu.Unlock()
+	if idle == nil {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cancelAll cancels the contexts of all running calls and returns their number.
+func (t *callTracker) cancelAll() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var n int
+	for c := range t.calls {
+		if !c.canceled {
+			c.canceled = true
+			c.cancel()
+			n++
+		}
+	}
+	return n
+}
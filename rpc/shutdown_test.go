@@ -0,0 +1,148 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForCalls(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	gates := new(gateService)
+	server.RegisterName("gate", gates)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+	wsClient, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(wssrv.URL, "http:"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wsClient.Close()
+	other := DialInProc(server)
+	defer other.Close()
+
+	callErr := make(chan error, 1)
+	go func() { callErr <- wsClient.Call(nil, "gate_wait", "a") }()
+	waitConcurrency(t, server, 1, 0)
+
+	type result struct {
+		canceled int
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := server.Shutdown(context.Background())
+		done <- result{n, err}
+	}()
+	for !server.shuttingDown() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New calls and requests are rejected.
+	if err := other.Call(nil, "test_noArgsRets"); err == nil || !strings.Contains(err.Error(), errShuttingDown.Error()) {
+		t.Fatalf("expected shutdown error, got %v", err)
+	}
+	httpClient := dialACLTest(t, httpsrv.URL)
+	var httpErr HTTPError
+	if err := httpClient.Call(nil, "test_noArgsRets"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %v", err)
+	}
+	select {
+	case r := <-done:
+		t.Fatalf("shutdown returned before call finished: %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	gates.open("a")
+	if err := <-callErr; err != nil {
+		t.Fatal("in-flight call failed:", err)
+	}
+	r := <-done
+	if r.canceled != 0 || r.err != nil {
+		t.Fatalf("wrong shutdown result %+v", r)
+	}
+	if err := wsClient.Call(nil, "test_noArgsRets"); err == nil {
+		t.Fatal("websocket connection still open")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	service := &timeoutTestService{release: make(chan struct{}), canceled: make(chan error, 1)}
+	defer close(service.release)
+	if err := server.RegisterName("slow", service); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	go client.Call(nil, "slow_wait")
+	for server.services.concurrency.stats().Running != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	n, err := server.Shutdown(ctx)
+	if n != 1 || err != context.DeadlineExceeded {
+		t.Fatalf("wrong shutdown result %d, %v", n, err)
+	}
+	select {
+	case <-service.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call not canceled")
+	}
+}
+
+func TestShutdownNotice(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	p1, p2 := net.Pipe()
+	defer p2.Close()
+	go server.ServeCodec(NewCodec(p1), 0)
+
+	// Make sure the connection is being served.
+	dec := json.NewDecoder(p2)
+	p2.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"test_noArgsRets"}`))
+	var resp jsonrpcMessage
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Shutdown(context.Background())
+	var notice jsonrpcMessage
+	if err := dec.Decode(&notice); err != nil {
+		t.Fatal(err)
+	}
+	if notice.Method != shutdownNoticeMethod {
+		t.Fatalf("wrong notification %+v", notice)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// SOCKSProxy configures a SOCKS5 proxy for the client, see WithSOCKSProxy.
+type SOCKSProxy struct {
+	// Addr is the address of the proxy. It must be an IP address and port, e.g.
+	// "127.0.0.1:9050", because host names would have to be resolved locally.
+	Addr string
+
+	// Credentials for username/password authentication. They are optional. With Tor,
+	// connections using different credentials are routed through different circuits.
+	Username string
+	Password string
+}
+
+// WithSOCKSProxy makes the client connect through a SOCKS5 proxy such as Tor. Host
+// names of endpoint URLs are resolved by the proxy (socks5h semantics), and the client
+// never performs DNS lookups itself, so the hosts being contacted are not revealed to
+// the local resolver. Proxy settings from the environment are ignored.
+//
+// This mode supports HTTP and websocket URLs. It can't be combined with WithHTTPClient
+// or WithWebsocketDialer, since a custom transport could resolve host names.
+func WithSOCKSProxy(proxy SOCKSProxy) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.socksProxy = &proxy
+	})
+}
+
+const socksHandshakeTimeout = 30 * time.Second
+
+var errSOCKSAuth = errors.New("SOCKS proxy rejected authentication")
+
+// socksReplyErrors are the messages of SOCKS5 reply codes.
+var socksReplyErrors = [...]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// socksDialer opens connections through a SOCKS5 proxy.
+type socksDialer struct {
+	proxy netip.AddrPort
+	user  string
+	pass  string
+	dial  func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// setupSOCKS validates the proxy configuration and installs an HTTP client and
+// websocket dialer which connect through the proxy.
+func (cfg *clientConfig) setupSOCKS(scheme string) error {
+	if cfg.socksProxy == nil {
+		return nil
+	}
+	switch scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("SOCKS proxy can't be used with %q URLs", scheme)
+	}
+	if cfg.httpClient != nil || cfg.wsDialer != nil {
+		return errors.New("SOCKS proxy can't be used with a custom HTTP client or websocket dialer")
+	}
+	proxy, err := netip.ParseAddrPort(cfg.socksProxy.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid SOCKS proxy address: %v", err)
+	}
+	if len(cfg.socksProxy.Username) > 255 || len(cfg.socksProxy.Password) > 255 {
+		return errors.New("SOCKS proxy credentials too long")
+	}
+	d := &socksDialer{
+		proxy: proxy,
+		user:  cfg.socksProxy.Username,
+		pass:  cfg.socksProxy.Password,
+		dial:  cfg.dialTCP(),
+	}
+	cfg.setDialer(d.DialContext, nil)
+	return nil
+}
+
+// DialContext connects to addr through the proxy. The host part of addr is passed to
+// the proxy as is.
+func (d *socksDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("SOCKS proxy does not support network %q", network)
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+	conn, err := d.dial(ctx, "tcp", d.proxy.String())
+	if err != nil {
+		return nil, err
+	}
+	// Abort the handshake when ctx is canceled.
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(socksHandshakeTimeout)
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	err = d.handshake(conn, host, uint16(port))
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// handshake performs method negotiation, authentication and the CONNECT request.
+func (d *socksDialer) handshake(conn net.Conn, host string, port uint16) error {
+	method := byte(0x00) // no authentication
+	if d.user != "" || d.pass != "" {
+		method = 0x02 // username/password
+	}
+	if _, err := conn.Write([]byte{0x05, 1, method}); err != nil {
+		return err
+	}
+	var buf [2]byte
+	if _, err := io.ReadFull(conn, buf[:]); err != nil {
+		return err
+	}
+	if buf[0] != 0x05 {
+		return fmt.Errorf("unexpected SOCKS version %d", buf[0])
+	}
+	if buf[1] != method {
+		return errors.New("SOCKS proxy requires unsupported authentication method")
+	}
+	if method == 0x02 {
+		req := append([]byte{0x01, byte(len(d.user))}, d.user...)
+		req = append(append(req, byte(len(d.pass))), d.pass...)
+		if _, err := conn.Write(req); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, buf[:]); err != nil {
+			return err
+		}
+		if buf[1] != 0x00 {
+			return errSOCKSAuth
+		}
+	}
+
+	// Send the CONNECT request. Host names are sent as such, for resolution by the proxy.
+	req := []byte{0x05, 0x01, 0x00}
+	if ip, err := netip.ParseAddr(host); err == nil && ip.Zone() == "" {
+		if ip.Is4() {
+			req = append(req, 0x01)
+		} else {
+			req = append(req, 0x04)
+		}
+		req = append(req, ip.AsSlice()...)
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("host name too long: %s", host)
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// Read the reply. The bound address is discarded.
+	var reply [4]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		return err
+	}
+	if reply[1] != 0x00 {
+		if int(reply[1]) < len(socksReplyErrors) {
+			return fmt.Errorf("SOCKS connect to %s failed: %s", host, socksReplyErrors[reply[1]])
+		}
+		return fmt.Errorf("SOCKS connect to %s failed with code %d", host, reply[1])
+	}
+	var skip int
+	switch reply[3] {
+	case 0x01:
+		skip = 4
+	case 0x04:
+		skip = 16
+	case 0x03:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return err
+		}
+		skip = int(buf[0])
+	default:
+		return fmt.Errorf("unknown SOCKS address type %d", reply[3])
+	}
+	_, err := io.CopyN(io.Discard, conn, int64(skip)+2)
+	return err
+}
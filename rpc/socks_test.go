@@ -0,0 +1,200 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testSOCKSServer is a minimal SOCKS5 proxy which connects all requests to a fixed
+// target and records the requested addresses.
+type testSOCKSServer struct {
+	ln     net.Listener
+	target string
+	user   string
+	pass   string
+
+	mu        sync.Mutex
+	requested []string
+}
+
+func newTestSOCKSServer(t *testing.T, target, user, pass string) *testSOCKSServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSOCKSServer{ln: ln, target: target, user: user, pass: pass}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *testSOCKSServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var hdr [2]byte
+	if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+		return
+	}
+	methods := make([]byte, hdr[1])
+	io.ReadFull(conn, methods)
+	want := byte(0x00)
+	if s.user != "" {
+		want = 0x02
+	}
+	if !strings.ContainsRune(string(methods), rune(want)) {
+		conn.Write([]byte{0x05, 0xff})
+		return
+	}
+	conn.Write([]byte{0x05, want})
+	if want == 0x02 {
+		var b [1]byte
+		io.ReadFull(conn, hdr[:])
+		user := make([]byte, hdr[1])
+		io.ReadFull(conn, user)
+		io.ReadFull(conn, b[:])
+		pass := make([]byte, b[0])
+		io.ReadFull(conn, pass)
+		if string(user) != s.user || string(pass) != s.pass {
+			conn.Write([]byte{0x01, 0x01})
+			return
+		}
+		conn.Write([]byte{0x01, 0x00})
+	}
+
+	var req [4]byte
+	if _, err := io.ReadFull(conn, req[:]); err != nil {
+		return
+	}
+	var host string
+	switch req[3] {
+	case 0x01:
+		ip := make(net.IP, 4)
+		io.ReadFull(conn, ip)
+		host = ip.String()
+	case 0x03:
+		var n [1]byte
+		io.ReadFull(conn, n[:])
+		name := make([]byte, n[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	}
+	var port [2]byte
+	io.ReadFull(conn, port[:])
+	s.mu.Lock()
+	s.requested = append(s.requested, net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))))
+	s.mu.Unlock()
+
+	target, err := net.Dial("tcp", s.target)
+	if err != nil {
+		conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func (s *testSOCKSServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requested...)
+}
+
+func TestSOCKSProxy(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	httpsrv := httptest.NewServer(srv)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+
+	tests := []struct {
+		url, target string
+	}{
+		{"http://rpc.node.invalid:8545", httpsrv.Listener.Addr().String()},
+		{"ws://rpc.node.invalid:8546", wssrv.Listener.Addr().String()},
+	}
+	for _, test := range tests {
+		proxy := newTestSOCKSServer(t, test.target, "isolation", "secret")
+		client, err := DialOptions(context.Background(), test.url, WithSOCKSProxy(SOCKSProxy{
+			Addr:     proxy.ln.Addr().String(),
+			Username: "isolation",
+			Password: "secret",
+		}))
+		if err != nil {
+			t.Fatalf("%s: %v", test.url, err)
+		}
+		var result echoResult
+		if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+			t.Fatalf("%s: %v", test.url, err)
+		}
+		client.Close()
+		reqs := proxy.requests()
+		if len(reqs) == 0 || reqs[0] != strings.TrimPrefix(strings.TrimPrefix(test.url, "http://"), "ws://") {
+			t.Fatalf("%s: proxy received wrong requests %v", test.url, reqs)
+		}
+	}
+}
+
+func TestSOCKSProxyErrors(t *testing.T) {
+	t.Parallel()
+
+	proxy := newTestSOCKSServer(t, "127.0.0.1:1", "user", "pass")
+	_, err := DialOptions(context.Background(), "ws://rpc.node.invalid", WithSOCKSProxy(SOCKSProxy{
+		Addr:     proxy.ln.Addr().String(),
+		Username: "user",
+		Password: "wrong",
+	}))
+	if err == nil || !strings.Contains(err.Error(), errSOCKSAuth.Error()) {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		opts []ClientOption
+	}{
+		{"ws://rpc.node.invalid", []ClientOption{WithSOCKSProxy(SOCKSProxy{Addr: "localhost:9050"})}},
+		{"/tmp/geth.ipc", []ClientOption{WithSOCKSProxy(SOCKSProxy{Addr: "127.0.0.1:9050"})}},
+		{"http://rpc.node.invalid", []ClientOption{WithSOCKSProxy(SOCKSProxy{Addr: "127.0.0.1:9050"}), WithHTTPClient(new(http.Client))}},
+	}
+	for _, test := range tests {
+		if _, err := DialOptions(context.Background(), test.url, test.opts...); err == nil {
+			t.Errorf("%s: no error", test.url)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State stores expiring counters for rate limiting and quotas, see QuotaFilter.
+// Implementations backed by a shared store let replicas of a server share limits.
+// Implementations must be safe for concurrent use.
+type State interface {
+	// Get returns the value of the counter key. Missing and expired counters have
+	// value zero.
+	Get(key string) (int64, error)
+
+	// Add adds delta to the counter key and returns the new value. A counter which is
+	// missing or has expired is created with value delta and expires after ttl. Adding
+	// to an existing counter does not change its expiry time.
+	Add(key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// stateSweepInterval is the number of Add operations between removals of expired
+// counters from a MemoryState.
+const stateSweepInterval = 1024
+
+var errStateClosed = errors.New("state is closed")
+
+type stateEntry struct {
+	Value   int64 `json:"value"`
+	Expires int64 `json:"expires"` // unix milliseconds
+}
+
+func (e stateEntry) expired(now time.Time) bool {
+	return now.UnixMilli() >= e.Expires
+}
+
+// MemoryState is a State held in memory.
+type MemoryState struct {
+	mu      sync.Mutex
+	entries map[string]stateEntry
+	adds    int
+	now     func() time.Time
+}
+
+// NewMemoryState creates an empty MemoryState.
+func NewMemoryState() *MemoryState {
+	return &MemoryState{entries: make(map[string]stateEntry), now: time.Now}
+}
+
+// Get returns the value of a counter.
+func (s *MemoryState) Get(key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.expired(s.now()) {
+		return 0, nil
+	}
+	return e.Value, nil
+}
+
+// Add adds delta to a counter.
+func (s *MemoryState) Add(key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.adds++; s.adds%stateSweepInterval == 0 {
+		s.sweep(now)
+	}
+	e, ok := s.entries[key]
+	if !ok || e.expired(now) {
+		e = stateEntry{Expires: now.Add(ttl).UnixMilli()}
+	}
+	e.Value += delta
+	s.entries[key] = e
+	return e.Value, nil
+}
+
+// sweep removes expired counters. This assumes s.mu is held.
+func (s *MemoryState) sweep(now time.Time) {
+	for key, e := range s.entries {
+		if e.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// FileState is a State held in memory and saved to a file, so that counters survive
+// restarts of the server. Changes are written periodically and when the state is closed.
+// Counters changed after the last write are lost if the process crashes.
+type FileState struct {
+	mem      *MemoryState
+	path     string
+	dirty    bool
+	closed   bool
+	saveMu   sync.Mutex // serializes writes of the file
+	mu       sync.Mutex // protects dirty and closed
+	quit     chan struct{}
+	loopDone chan struct{}
+}
+
+// OpenFileState opens the state saved in the given file, or creates an empty state if
+// the file does not exist. Changes are written every saveInterval. The state must be
+// closed to save the final changes.
+func OpenFileState(path string, saveInterval time.Duration) (*FileState, error) {
+	s := &FileState{
+		mem:      NewMemoryState(),
+		path:     path,
+		quit:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &s.mem.entries); err != nil {
+			return nil, err
+		}
+		if s.mem.entries == nil {
+			s.mem.entries = make(map[string]stateEntry)
+		}
+		s.mem.sweep(time.Now())
+	}
+	go s.saveLoop(saveInterval)
+	return s, nil
+}
+
+// Get returns the value of a counter.
+func (s *FileState) Get(key string) (int64, error) {
+	return s.mem.Get(key)
+}
+
+// Add adds delta to a counter.
+func (s *FileState) Add(key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, errStateClosed
+	}
+	s.dirty = true
+	s.mu.Unlock()
+	return s.mem.Add(key, delta, ttl)
+}
+
+// Save writes the counters to the file.
+func (s *FileState) Save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	s.dirty = false
+	s.mu.Unlock()
+
+	s.mem.mu.Lock()
+	s.mem.sweep(s.mem.now())
+	data, err := json.Marshal(s.mem.entries)
+	s.mem.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Close stops periodic writes and saves the counters.
+func (s *FileState) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.quit)
+	<-s.loopDone
+	return s.Save()
+}
+
+func (s *FileState) saveLoop(interval time.Duration) {
+	defer close(s.loopDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			dirty := s.dirty
+			s.mu.Unlock()
+			if dirty {
+				s.Save()
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
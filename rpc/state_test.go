@@ -0,0 +1,96 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryStateExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryState()
+	s.now = func() time.Time { return now }
+
+	if v, _ := s.Add("a", 2, time.Second); v != 2 {
+		t.Fatalf("wrong value %d", v)
+	}
+	now = now.Add(500 * time.Millisecond)
+	// Adding does not extend the expiry time.
+	if v, _ := s.Add("a", 3, time.Hour); v != 5 {
+		t.Fatalf("wrong value %d", v)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if v, _ := s.Get("a"); v != 0 {
+		t.Fatalf("expired counter has value %d", v)
+	}
+	if v, _ := s.Add("a", 1, time.Second); v != 1 {
+		t.Fatalf("expired counter not reset, value %d", v)
+	}
+
+	// Expired counters are removed eventually.
+	for i := 0; i < stateSweepInterval; i++ {
+		now = now.Add(time.Second)
+		s.Add("b", 1, time.Millisecond)
+	}
+	if len(s.entries) > 1 {
+		t.Fatalf("expired counters not removed: %d entries", len(s.entries))
+	}
+}
+
+func TestFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := OpenFileState(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add("kept", 7, time.Hour)
+	s.Add("expiring", 1, time.Millisecond)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add("kept", 1, time.Hour); err != errStateClosed {
+		t.Fatalf("wrong error after close: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The counters survive reopening.
+	s, err = OpenFileState(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Get("kept"); v != 7 {
+		t.Fatalf("wrong value after reopening: %d", v)
+	}
+	if v, _ := s.Get("expiring"); v != 0 {
+		t.Fatalf("expired counter restored with value %d", v)
+	}
+
+	// Changes are saved periodically.
+	s.Add("kept", 1, time.Hour)
+	time.Sleep(100 * time.Millisecond)
+	s2, err := OpenFileState(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	if v, _ := s2.Get("kept"); v != 8 {
+		t.Fatalf("periodic save missing, value %d", v)
+	}
+	s.Close()
+}
@@ -0,0 +1,272 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StorageKeys provides the keys of an encrypting StorageCodec. Keys must be 16, 24 or 32
+// bytes long, selecting AES-128, AES-192 or AES-256.
+//
+// Implementations can fetch keys from a key management service. The id of the key used
+// for encryption is stored in the clear alongside the data, so keys can be rotated while
+// data encrypted with older keys remains readable.
+type StorageKeys interface {
+	// EncryptionKey returns the key for encrypting new data, and its id.
+	EncryptionKey() (id string, key []byte, err error)
+	// DecryptionKey returns the key with the given id.
+	DecryptionKey(id string) ([]byte, error)
+}
+
+// StaticStorageKey returns StorageKeys consisting of a single key.
+func StaticStorageKey(key []byte) StorageKeys {
+	return staticStorageKey(bytes.Clone(key))
+}
+
+type staticStorageKey []byte
+
+func (k staticStorageKey) EncryptionKey() (string, []byte, error) { return "", k, nil }
+
+func (k staticStorageKey) DecryptionKey(id string) ([]byte, error) {
+	if id != "" {
+		return nil, fmt.Errorf("unknown storage key %q", id)
+	}
+	return k, nil
+}
+
+var (
+	errStorageMagic     = errors.New("data is not encrypted")
+	errStorageDecrypt   = errors.New("can't decrypt stored data")
+	storageEncryptMagic = []byte("RPCENC1")
+)
+
+const storageChunkSize = 64 * 1024
+
+// NewEncryptedCodec returns a StorageCodec encrypting data with AES-GCM. If inner is
+// non-nil, data is processed by inner, e.g. GzipCodec, before it is encrypted.
+//
+// Data is encrypted in authenticated chunks, so truncation and reordering of stored data
+// are detected when it is read.
+func NewEncryptedCodec(keys StorageKeys, inner StorageCodec) StorageCodec {
+	return &encryptedCodec{keys: keys, inner: inner}
+}
+
+type encryptedCodec struct {
+	keys  StorageKeys
+	inner StorageCodec
+}
+
+func (c *encryptedCodec) Name() string {
+	if c.inner != nil {
+		return c.inner.Name() + ".enc"
+	}
+	return "enc"
+}
+
+func (c *encryptedCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	id, key, err := c.keys.EncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(id) > 255 {
+		return nil, errors.New("storage key id too long")
+	}
+	aead, err := newStorageAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := append(bytes.Clone(storageEncryptMagic), byte(len(id)))
+	if _, err := w.Write(append(header, id...)); err != nil {
+		return nil, err
+	}
+	ew := &encryptingWriter{w: w, aead: aead}
+	if c.inner == nil {
+		return ew, nil
+	}
+	iw, err := c.inner.NewWriter(ew)
+	if err != nil {
+		return nil, err
+	}
+	return &chainedWriteCloser{iw, ew}, nil
+}
+
+func (c *encryptedCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	header := make([]byte, len(storageEncryptMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(storageEncryptMagic)], storageEncryptMagic) {
+		return nil, errStorageMagic
+	}
+	id := make([]byte, header[len(header)-1])
+	if _, err := io.ReadFull(r, id); err != nil {
+		return nil, err
+	}
+	key, err := c.keys.DecryptionKey(string(id))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newStorageAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	dr := &decryptingReader{r: r, aead: aead}
+	if c.inner == nil {
+		return io.NopCloser(dr), nil
+	}
+	return c.inner.NewReader(dr)
+}
+
+func newStorageAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// storageChunkAD returns the additional data authenticating the position of a chunk.
+func storageChunkAD(index uint64, final bool) []byte {
+	ad := binary.BigEndian.AppendUint64(nil, index)
+	if final {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+// encryptingWriter writes data as a sequence of encrypted chunks. Each chunk is stored
+// as a final flag, the ciphertext length, the nonce and the ciphertext.
+type encryptingWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+}
+
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := min(len(p), storageChunkSize-len(ew.buf))
+		ew.buf = append(ew.buf, p[:take]...)
+		p = p[take:]
+		if len(ew.buf) == storageChunkSize {
+			if err := ew.flushChunk(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes the final chunk. It doesn't close the underlying writer.
+func (ew *encryptingWriter) Close() error {
+	return ew.flushChunk(true)
+}
+
+func (ew *encryptingWriter) flushChunk(final bool) error {
+	nonce := make([]byte, ew.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := ew.aead.Seal(nil, nonce, ew.buf, storageChunkAD(ew.index, final))
+	frame := make([]byte, 5, 5+len(nonce)+len(sealed))
+	if final {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(sealed)))
+	frame = append(append(frame, nonce...), sealed...)
+	if _, err := ew.w.Write(frame); err != nil {
+		return err
+	}
+	ew.buf = ew.buf[:0]
+	ew.index++
+	return nil
+}
+
+// decryptingReader reads the chunks written by encryptingWriter.
+type decryptingReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+	done  bool
+}
+
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.buf) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+		if err := dr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.buf)
+	dr.buf = dr.buf[n:]
+	return n, nil
+}
+
+func (dr *decryptingReader) readChunk() error {
+	head := make([]byte, 5+dr.aead.NonceSize())
+	if _, err := io.ReadFull(dr.r, head); err != nil {
+		if err == io.EOF {
+			// The final chunk is missing.
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	final := head[0] == 1
+	size := binary.BigEndian.Uint32(head[1:5])
+	if size > storageChunkSize+uint32(dr.aead.Overhead()) {
+		return errStorageDecrypt
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(dr.r, sealed); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	plain, err := dr.aead.Open(sealed[:0], head[5:], sealed, storageChunkAD(dr.index, final))
+	if err != nil {
+		return errStorageDecrypt
+	}
+	dr.buf, dr.done = plain, final
+	dr.index++
+	return nil
+}
+
+// chainedWriteCloser closes a writer and then the writer it writes to.
+type chainedWriteCloser struct {
+	io.WriteCloser
+	next io.Closer
+}
+
+func (c *chainedWriteCloser) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return c.next.Close()
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type testStorageKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+func (k *testStorageKeys) EncryptionKey() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *testStorageKeys) DecryptionKey(id string) ([]byte, error) {
+	if key, ok := k.keys[id]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", id)
+}
+
+func TestEncryptedCodec(t *testing.T) {
+	t.Parallel()
+
+	keys := &testStorageKeys{current: "k1", keys: map[string][]byte{"k1": make([]byte, 32)}}
+	rand.Read(keys.keys["k1"])
+	codec := NewEncryptedCodec(keys, GzipCodec)
+	if codec.Name() != "gz.enc" {
+		t.Errorf("wrong name %q", codec.Name())
+	}
+
+	// Use more than one chunk.
+	data := bytes.Repeat([]byte("secret request contents "), 2*storageChunkSize/10)
+	enc, err := encodeStored(codec, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := NewEncryptedCodec(keys, nil)
+	encPlain, err := encodeStored(plain, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encPlain, []byte("secret")) {
+		t.Fatal("encrypted data contains plaintext")
+	}
+
+	// Keys can be rotated.
+	keys.keys["k2"] = make([]byte, 16)
+	keys.current = "k2"
+	for _, c := range []struct {
+		codec StorageCodec
+		enc   []byte
+	}{{codec, enc}, {plain, encPlain}} {
+		dec, err := decodeStored(c.codec, c.enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(dec, data) {
+			t.Fatalf("%s: wrong decoded data", c.codec.Name())
+		}
+	}
+
+	// Truncation and tampering are detected.
+	if _, err := decodeStored(plain, encPlain[:len(encPlain)-50]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("wrong error for truncated data: %v", err)
+	}
+	firstChunk := len(storageEncryptMagic) + 3 + 5 + 12 + storageChunkSize + 16
+	if _, err := decodeStored(plain, encPlain[:firstChunk]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("wrong error for missing chunks: %v", err)
+	}
+	tampered := bytes.Clone(encPlain)
+	tampered[len(tampered)-1]++
+	if _, err := decodeStored(plain, tampered); err != errStorageDecrypt {
+		t.Errorf("wrong error for tampered data: %v", err)
+	}
+
+	// Unknown keys are reported.
+	delete(keys.keys, "k1")
+	if _, err := decodeStored(plain, encPlain); err == nil {
+		t.Error("decoded data without key")
+	}
+	if _, err := decodeStored(plain, []byte("plaintext data")); err != errStorageMagic {
+		t.Errorf("wrong error for plain data: %v", err)
+	}
+}
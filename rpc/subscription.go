@@ -24,11 +24,15 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"iter"
 	"math/rand"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
 )
 
 var (
@@ -45,6 +49,10 @@ var (
 
 	// ErrSubscriptionNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	// ErrSubscriptionClosed is returned by Notifier.Notify when the subscription has
+	// ended, because the client unsubscribed or disconnected.
+	ErrSubscriptionClosed = errors.New("subscription closed")
 )
 
 var globalGen = randomIDGenerator()
@@ -57,8 +65,25 @@ func NewID() ID {
 	return globalGen()
 }
 
+// SequentialIDGenerator returns an ID generator issuing the ids 0x1, 0x2, 0x3 and so
+// on, see Server.SetIDGenerator. Such ids are predictable and make logs easy to follow,
+// e.g. in tests. Since other callers sharing a connection can guess them, consider
+// binding subscriptions to their owner using Server.SetSubscriptionOwner.
+func SequentialIDGenerator() func() ID {
+	var seq atomic.Uint64
+	return func() ID {
+		return encodeID(binary.BigEndian.AppendUint64(nil, seq.Add(1)))
+	}
+}
+
 // randomIDGenerator returns a function generates a random IDs.
 func randomIDGenerator() func() ID {
+	return prefixedIDGenerator(nil)
+}
+
+// prefixedIDGenerator returns a function generating random IDs which start with the given
+// bytes.
+func prefixedIDGenerator(prefix []byte) func() ID {
 	var buf = make([]byte, 8)
 	var seed int64
 	if _, err := crand.Read(buf); err == nil {
@@ -75,7 +100,8 @@ func randomIDGenerator() func() ID {
 		mu.Lock()
 		defer mu.Unlock()
 		id := make([]byte, 16)
-		rng.Read(id)
+		n := copy(id, prefix)
+		rng.Read(id[n:])
 		return encodeID(id)
 	}
 }
@@ -102,12 +128,39 @@ func NotifierFromContext(ctx context.Context) (*Notifier, bool) {
 type Notifier struct {
 	h         *handler
 	namespace string
+	name      string          // subscription name, the first parameter of the subscribe call
+	ctx       context.Context // values of the subscribe call, see SubscriptionInterceptor
+	owner     string
 
 	mu           sync.Mutex
 	sub          *Subscription
 	buffer       []any
 	callReturned bool
 	activated    bool
+	closed       bool  // subscription has ended, see OnClose
+	closeErr     error // reason the subscription ended
+	onClose      func(err error)
+	cancelRunner context.CancelCauseFunc // stops the producer, see RunSubscription
+
+	// set while the subscription's session is detached, see SetSessionResumption
+	bufferLimit    int
+	bufferOverflow bool
+
+	// set while the client has paused the subscription, see SetSubscriptionPauseBuffer
+	paused       bool
+	pauseLimit   int
+	pauseDropped int
+
+	// rate limiting, see SetRateLimit
+	interval   time.Duration
+	merge      func(pending, data any) any
+	lastSent   mclock.AbsTime
+	sentAny    bool // lastSent is valid
+	pending    any
+	hasPending bool
+	flushTimer mclock.Timer
+
+	queue *notifyQueue // see SetSubscriptionQueue
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -123,12 +176,37 @@ func (n *Notifier) CreateSubscription() *Subscription {
 	} else if n.callReturned {
 		panic("can't create subscription after subscribe call has returned")
 	}
-	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1)}
+	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, owner: n.owner, notifier: n, err: make(chan error, 1)}
 	return n.sub
 }
 
+// ID returns the id of the subscription created by CreateSubscription. It returns the
+// empty ID before the subscription is created.
+func (n *Notifier) ID() ID {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.sub == nil {
+		return ""
+	}
+	return n.sub.ID
+}
+
+// SubscriptionIDFromContext returns the id of the subscription created by the current
+// subscribe call. It can be used by the subscription method after calling
+// CreateSubscription, and by middlewares once the method has returned, e.g. to log the
+// id for correlation with client logs.
+func SubscriptionIDFromContext(ctx context.Context) (ID, bool) {
+	n, ok := NotifierFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	id := n.ID()
+	return id, id != ""
+}
+
 // Notify sends a notification to the client with the given data as payload.
 // If an error occurs the RPC connection is closed and the error is returned.
+// ErrSubscriptionClosed is returned once the subscription has ended.
 func (n *Notifier) Notify(id ID, data any) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -138,19 +216,183 @@ func (n *Notifier) Notify(id ID, data any) error {
 	} else if n.sub.ID != id {
 		panic("Notify with wrong ID")
 	}
+	if n.closed {
+		return ErrSubscriptionClosed
+	}
+	data, ok := n.intercept(data)
+	if !ok {
+		return nil
+	}
+	if n.paused {
+		n.bufferPaused(data)
+		return nil
+	}
 	if n.activated {
+		if n.interval > 0 {
+			return n.sendLimited(data)
+		}
 		return n.send(n.sub, data)
 	}
+	n.bufferNotification(data)
+	return nil
+}
+
+// OnClose registers fn to be called when the subscription ends, so the producer can
+// stop generating notifications. The argument is nil if the client unsubscribed, and
+// the reason otherwise, e.g. ErrClientQuit when the connection was lost. fn is called
+// on its own goroutine. If the subscription has already ended, fn is called immediately.
+func (n *Notifier) OnClose(fn func(err error)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.onClose = fn
+	if n.closed && fn != nil {
+		go fn(n.closeErr)
+	}
+}
+
+// close marks the subscription as ended and runs the OnClose callback. It returns false
+// if the subscription had already ended.
+func (n *Notifier) close(err error) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return false
+	}
+	n.closed, n.closeErr = true, err
+	if n.cancelRunner != nil {
+		cause := err
+		if cause == nil {
+			cause = ErrSubscriptionClosed
+		}
+		n.cancelRunner(cause)
+	}
+	n.buffer, n.pending, n.hasPending = nil, nil, false
+	if n.queue != nil {
+		n.queue.clear()
+	}
+	if n.flushTimer != nil {
+		n.flushTimer.Stop()
+		n.flushTimer = nil
+	}
+	if n.onClose != nil {
+		go n.onClose(err)
+	}
+	return true
+}
+
+// bufferNotification stores a notification until the notifier is activated. It must be
+// called with n.mu held.
+func (n *Notifier) bufferNotification(data any) {
+	if n.bufferLimit > 0 && len(n.buffer) >= n.bufferLimit {
+		n.bufferOverflow = true
+		return
+	}
 	n.buffer = append(n.buffer, data)
+}
+
+// detach is called when the connection of the notifier's session is lost. Up to limit
+// notifications are buffered until the notifier is attached to another connection.
+func (n *Notifier) detach(limit int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.activated = false
+	n.bufferLimit = limit
+	n.bufferOverflow = false
+}
+
+// attach moves the notifier to the connection of h. It returns false if notifications
+// were lost while detached. Buffered notifications are sent when the notifier is
+// activated again.
+func (n *Notifier) attach(h *handler) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.bufferOverflow {
+		return false
+	}
+	n.h = h
+	n.bufferLimit = 0
+	return true
+}
+
+// SetRateLimit limits the rate of notifications sent by Notify to perSecond. A rate of
+// zero removes the limit.
+//
+// Notifications exceeding the limit are dropped if merge is nil. Otherwise they are
+// combined into a single pending notification using merge, which receives the pending
+// value (nil if there is none) and the new value. The pending notification is sent as
+// soon as the rate limit allows it.
+func (n *Notifier) SetRateLimit(perSecond float64, merge func(pending, data any) any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.interval = 0
+	if perSecond > 0 {
+		n.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	n.merge = merge
+}
+
+// sendLimited sends a notification, applying the rate limit. It must be called with
+// n.mu held.
+func (n *Notifier) sendLimited(data any) error {
+	now := n.h.clock.Now()
+	if !n.hasPending && (!n.sentAny || now.Sub(n.lastSent) >= n.interval) {
+		n.lastSent, n.sentAny = now, true
+		return n.send(n.sub, data)
+	}
+	if n.merge == nil {
+		notificationsDroppedCounter.Inc(1)
+		return nil
+	}
+	if n.hasPending {
+		notificationsMergedCounter.Inc(1)
+	}
+	n.pending = n.merge(n.pending, data)
+	n.hasPending = true
+	if n.flushTimer == nil {
+		n.flushTimer = n.h.clock.AfterFunc(n.lastSent.Add(n.interval).Sub(now), n.flush)
+	}
 	return nil
 }
 
-// takeSubscription returns the subscription (if one has been created). No subscription can
-// be created after this call.
+// flush sends the pending notification.
+func (n *Notifier) flush() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.flushTimer = nil
+	if n.closed || !n.hasPending {
+		return
+	}
+	data := n.pending
+	n.pending, n.hasPending = nil, false
+	if n.paused {
+		n.bufferPaused(data)
+		return
+	}
+	if !n.activated {
+		n.bufferNotification(data)
+		return
+	}
+	n.lastSent, n.sentAny = n.h.clock.Now(), true
+	if err := n.send(n.sub, data); err != nil {
+		n.h.log.Debug("Failed to send pending notification", "err", err)
+	}
+}
+
+// takeSubscription returns the subscription (if one has been created and hasn't ended
+// yet). No subscription can be created after this call.
 func (n *Notifier) takeSubscription() *Subscription {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.callReturned = true
+	if n.closed {
+		return nil
+	}
 	return n.sub
 }
 
@@ -161,16 +403,39 @@ func (n *Notifier) activate() error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	if n.paused {
+		// Buffered notifications are sent when the subscription is resumed.
+		n.activated = true
+		return nil
+	}
 	for _, data := range n.buffer {
-		if err := n.send(n.sub, data); err != nil {
+		var err error
+		if n.interval > 0 {
+			err = n.sendLimited(data)
+		} else {
+			err = n.send(n.sub, data)
+		}
+		if err != nil {
 			return err
 		}
 	}
+	n.buffer = nil
 	n.activated = true
 	return nil
 }
 
+// send writes a notification, or adds it to the send queue of the subscription if it has
+// one. It must be called with n.mu held.
 func (n *Notifier) send(sub *Subscription, data any) error {
+	if n.queue != nil {
+		n.queue.push(n, sub, data)
+		return nil
+	}
+	return n.write(n.h, sub, data)
+}
+
+// write sends a notification on the connection of h.
+func (n *Notifier) write(h *handler, sub *Subscription, data any) error {
 	msg := jsonrpcSubscriptionNotification{
 		Version: vsn,
 		Method:  n.namespace + notificationMethodSuffix,
@@ -179,14 +444,58 @@ func (n *Notifier) send(sub *Subscription, data any) error {
 			Result: data,
 		},
 	}
-	return n.h.conn.writeJSON(context.Background(), &msg, false)
+	if h.canonicalJSON {
+		if enc, err := json.Marshal(data); err == nil {
+			if enc, err = canonicalizeJSON(enc); err == nil {
+				msg.Params.Result = json.RawMessage(enc)
+			}
+		}
+	}
+	if seq := h.notifySeq; seq != nil {
+		// Hold the sequencer lock while writing, so notifications are sent in order.
+		seq.mu.Lock()
+		defer seq.mu.Unlock()
+		seq.last++
+		msg.Params.Seq = seq.last
+	}
+	var audit *auditLog
+	if h.reg != nil {
+		audit = h.reg.snapshot().audit
+	}
+	if audit == nil {
+		return h.conn.writeJSON(context.Background(), &msg, false)
+	}
+	start := time.Now()
+	err := h.conn.writeJSON(context.Background(), &msg, false)
+	audit.recordNotification(h, n, sub, data, start, err)
+	return err
+}
+
+// notificationSequencer numbers the notifications sent on a connection.
+type notificationSequencer struct {
+	mu   sync.Mutex
+	last uint64
+}
+
+// SequencedNotification can be used as the channel element type of a client
+// subscription to receive the sequence number of notifications along with their content.
+// Sequence numbers are shared across all subscriptions of a connection and are only
+// provided by servers which enable them using Server.SetNotificationOrdering. They start
+// at one, zero means the notification was not numbered.
+type SequencedNotification struct {
+	Seq    uint64          `json:"seq"`
+	Result json.RawMessage `json:"result"`
 }
 
+var sequencedNotificationType = reflect.TypeOf(SequencedNotification{})
+
 // A Subscription is created by a notifier and tied to that notifier. The client can use
 // this subscription to wait for an unsubscribe request for the client, see Err().
 type Subscription struct {
 	ID        ID
 	namespace string
+	owner     string // identity of the subscriber, see Server.SetSubscriptionOwner
+	notifier  *Notifier
 	err       chan error // closed on unsubscribe
 }
 
@@ -195,6 +504,17 @@ func (s *Subscription) Err() <-chan error {
 	return s.err
 }
 
+// close ends the subscription. The error, if non-nil, is delivered on the Err channel.
+func (s *Subscription) close(err error) {
+	if !s.notifier.close(err) {
+		return
+	}
+	if err != nil {
+		s.err <- err
+	}
+	close(s.err)
+}
+
 // MarshalJSON marshals a subscription as its ID.
 func (s *Subscription) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.ID)
@@ -207,7 +527,19 @@ type ClientSubscription struct {
 	etype     reflect.Type
 	channel   reflect.Value
 	namespace string
-	subid     string
+	sequenced bool                // etype is SequencedNotification
+	worker    int                 // dispatch worker, see WithDispatchWorkers
+	decode    notificationDecoder // decoder of typed subscriptions, see SubscribeTyped
+
+	// Notifications are spilled to disk when the buffer is full, if spillSize > 0.
+	spillDir  string
+	spillSize int64
+
+	// For subscriptions created by SubscribeWithHandler, notifications are passed to
+	// handler instead of channel. handlerCancel cancels the context given to handler.
+	handler       func(context.Context, json.RawMessage) error
+	handlerCtx    context.Context
+	handlerCancel context.CancelFunc
 
 	// The in channel receives notification values from client dispatcher.
 	in chan json.RawMessage
@@ -223,6 +555,20 @@ type ClientSubscription struct {
 	quit        chan error
 	forwardDone chan struct{}
 	unsubDone   chan struct{}
+
+	// The server-side id changes when the subscription is resubscribed using the
+	// arguments of the original subscribe call, see WithResubscribe.
+	idMu    sync.Mutex
+	subid   string
+	started bool          // forwarding loop is running
+	args    []interface{} // arguments of the subscribe call
+
+	pausedAt time.Time // see Pause
+
+	// Lifecycle events, see Lifecycle.
+	events       chan SubscriptionEvent
+	eventsMu     sync.Mutex
+	eventsClosed bool
 }
 
 // This is the sentinel value sent on sub.quit when Unsubscribe is called.
@@ -232,14 +578,34 @@ func newClientSubscription(c *Client, namespace string, channel reflect.Value) *
 	sub := &ClientSubscription{
 		client:      c,
 		namespace:   namespace,
+		spillDir:    c.spillDir,
+		spillSize:   c.spillSize,
 		etype:       channel.Type().Elem(),
+		sequenced:   channel.Type().Elem() == sequencedNotificationType,
 		channel:     channel,
 		in:          make(chan json.RawMessage),
 		quit:        make(chan error),
 		forwardDone: make(chan struct{}),
 		unsubDone:   make(chan struct{}),
 		err:         make(chan error, 1),
+		events:      make(chan SubscriptionEvent, subscriptionEventBuffer),
+	}
+	return sub
+}
+
+func newHandlerSubscription(c *Client, namespace string, fn func(context.Context, json.RawMessage) error) *ClientSubscription {
+	sub := &ClientSubscription{
+		client:      c,
+		namespace:   namespace,
+		handler:     fn,
+		in:          make(chan json.RawMessage),
+		quit:        make(chan error),
+		forwardDone: make(chan struct{}),
+		unsubDone:   make(chan struct{}),
+		err:         make(chan error, 1),
+		events:      make(chan SubscriptionEvent, subscriptionEventBuffer),
 	}
+	sub.handlerCtx, sub.handlerCancel = context.WithCancel(context.Background())
 	return sub
 }
 
@@ -259,6 +625,9 @@ func (sub *ClientSubscription) Err() <-chan error {
 // It can safely be called more than once.
 func (sub *ClientSubscription) Unsubscribe() {
 	sub.errOnce.Do(func() {
+		if sub.handlerCancel != nil {
+			sub.handlerCancel()
+		}
 		select {
 		case sub.quit <- errUnsubscribed:
 			<-sub.unsubDone
@@ -268,6 +637,47 @@ func (sub *ClientSubscription) Unsubscribe() {
 	})
 }
 
+// Events returns an iterator over the notifications of the subscription. It is an
+// alternative to receiving from the subscription channel and Err directly. Values have
+// the element type of the channel given to Subscribe.
+//
+// Iteration ends when the subscription ends. If it ended due to an error, the error is
+// yielded as the final element. Stopping the iteration early unsubscribes. The channel
+// must not be read elsewhere while iterating, and must allow receiving.
+//
+// Events cannot be used with subscriptions created by SubscribeWithHandler.
+func (sub *ClientSubscription) Events() iter.Seq2[any, error] {
+	if sub.handler != nil {
+		panic("Events called on subscription created by SubscribeWithHandler")
+	}
+	if sub.channel.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic("Events requires a subscription channel that can be received from")
+	}
+	return func(yield func(any, error) bool) {
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: sub.channel},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.err)},
+		}
+		for {
+			chosen, recv, recvOK := reflect.Select(cases)
+			switch {
+			case chosen == 0 && recvOK:
+				if !yield(recv.Interface(), nil) {
+					sub.Unsubscribe()
+					return
+				}
+			case chosen == 0:
+				return // channel closed by the user
+			default:
+				if recvOK && !recv.IsNil() {
+					yield(nil, recv.Interface().(error))
+				}
+				return
+			}
+		}
+	}
+}
+
 // deliver is called by the client's message dispatcher to send a notification value.
 func (sub *ClientSubscription) deliver(result json.RawMessage) (ok bool) {
 	select {
@@ -280,6 +690,9 @@ func (sub *ClientSubscription) deliver(result json.RawMessage) (ok bool) {
 
 // close is called by the client's message dispatcher when the connection is closed.
 func (sub *ClientSubscription) close(err error) {
+	if sub.handlerCancel != nil {
+		sub.handlerCancel()
+	}
 	select {
 	case sub.quit <- err:
 	case <-sub.forwardDone:
@@ -291,7 +704,15 @@ func (sub *ClientSubscription) close(err error) {
 func (sub *ClientSubscription) run() {
 	defer close(sub.unsubDone)
 
-	unsubscribe, err := sub.forward()
+	var (
+		unsubscribe bool
+		err         error
+	)
+	if sub.handler != nil {
+		unsubscribe, err = sub.forwardHandler()
+	} else {
+		unsubscribe, err = sub.forward()
+	}
 
 	// The client's dispatch loop won't be able to execute the unsubscribe call if it is
 	// blocked in sub.deliver() or sub.close(). Closing forwardDone unblocks them.
@@ -311,6 +732,7 @@ func (sub *ClientSubscription) run() {
 		}
 		sub.err <- err
 	}
+	sub.emit(SubscriptionEvent{Kind: SubscriptionClosed, ID: sub.id(), Err: err})
 }
 
 // forward is the forwarding loop. It takes in RPC notifications and sends them
@@ -322,6 +744,12 @@ func (sub *ClientSubscription) forward() (unsubscribeServer bool, err error) {
 		{Dir: reflect.SelectSend, Chan: sub.channel},
 	}
 	buffer := list.New()
+	var spill *spillRing // created when the buffer overflows
+	defer func() {
+		if spill != nil {
+			spill.close()
+		}
+	}()
 
 	for {
 		var chosen int
@@ -347,25 +775,97 @@ func (sub *ClientSubscription) forward() (unsubscribeServer bool, err error) {
 			return false, err
 
 		case 1: // <-sub.in
-			val, err := sub.unmarshal(recv.Interface().(json.RawMessage))
+			raw := recv.Interface().(json.RawMessage)
+			if spill != nil && spill.len() > 0 {
+				// Keep order: once spilling, new items go to disk until it is drained.
+				if err := sub.spill(spill, raw); err != nil {
+					return true, err
+				}
+				continue
+			}
+			val, err := sub.unmarshal(raw)
 			if err != nil {
 				return true, err
 			}
 			if buffer.Len() == maxClientSubscriptionBuffer {
-				return true, ErrSubscriptionQueueOverflow
+				if sub.spillSize <= 0 {
+					return true, ErrSubscriptionQueueOverflow
+				}
+				if spill == nil {
+					if spill, err = newSpillRing(sub.spillDir, sub.spillSize, sub.client.spillCodec); err != nil {
+						return true, err
+					}
+				}
+				if err := sub.spill(spill, raw); err != nil {
+					return true, err
+				}
+				continue
 			}
 			buffer.PushBack(val)
 
 		case 2: // sub.channel<-
 			cases[2].Send = reflect.Value{} // Don't hold onto the value.
 			buffer.Remove(buffer.Front())
+			if spill != nil && spill.len() > 0 {
+				raw, err := spill.pop()
+				if err != nil {
+					return true, err
+				}
+				val, err := sub.unmarshal(raw)
+				if err != nil {
+					return true, err
+				}
+				buffer.PushBack(val)
+			}
+		}
+	}
+}
+
+// forwardHandler is the forwarding loop of subscriptions created by SubscribeWithHandler.
+// It passes notifications to the handler function.
+func (sub *ClientSubscription) forwardHandler() (unsubscribeServer bool, err error) {
+	defer sub.handlerCancel()
+
+	for {
+		select {
+		case err := <-sub.quit:
+			if err == errUnsubscribed {
+				// Exiting because Unsubscribe was called, unsubscribe on server.
+				return true, nil
+			}
+			return false, err
+
+		case result := <-sub.in:
+			if err := sub.handler(sub.handlerCtx, result); err != nil {
+				if sub.handlerCtx.Err() != nil {
+					// The context was canceled by Unsubscribe or close, which are
+					// waiting to send on quit.
+					continue
+				}
+				return true, err
+			}
 		}
 	}
 }
 
+// spill stores a notification in the spill file.
+func (sub *ClientSubscription) spill(spill *spillRing, raw json.RawMessage) error {
+	ok, err := spill.push(raw)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSubscriptionQueueOverflow
+	}
+	return nil
+}
+
 func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, error) {
+	if sub.decode != nil {
+		return sub.decode(sub.client, sub.namespace+notificationMethodSuffix, result)
+	}
 	val := reflect.New(sub.etype)
-	err := json.Unmarshal(result, val.Interface())
+	err := sub.client.decodeResult(sub.namespace+notificationMethodSuffix, result, val.Interface())
 	return val.Elem().Interface(), err
 }
 
@@ -373,6 +873,6 @@ func (sub *ClientSubscription) requestUnsubscribe() error {
 	var result interface{}
 	ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
 	defer cancel()
-	err := sub.client.CallContext(ctx, &result, sub.namespace+unsubscribeMethodSuffix, sub.subid)
+	err := sub.client.CallContext(ctx, &result, sub.namespace+unsubscribeMethodSuffix, sub.id())
 	return err
 }
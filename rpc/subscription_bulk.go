@@ -0,0 +1,79 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"reflect"
+	"slices"
+)
+
+const (
+	unsubscribeManyMethod = MetadataApi + "_unsubscribeMany"
+	unsubscribeAllMethod  = MetadataApi + "_unsubscribeAll"
+)
+
+var idListType = reflect.TypeOf([]ID{})
+
+// UnsubscribeSummary is the result of the built-in rpc_unsubscribeMany and
+// rpc_unsubscribeAll methods. rpc_unsubscribeMany takes a list of subscription ids and
+// ends those subscriptions of the connection, while rpc_unsubscribeAll ends all
+// subscriptions of the connection. This lets clients clean up after partial failures
+// with a single call.
+type UnsubscribeSummary struct {
+	Unsubscribed []ID `json:"unsubscribed"`       // subscriptions which were ended
+	NotFound     []ID `json:"notFound,omitempty"` // requested ids without subscription
+}
+
+// handleBulkUnsubscribe processes the rpc_unsubscribeMany and rpc_unsubscribeAll methods.
+func (h *handler) handleBulkUnsubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if !h.allowSubscribe {
+		return msg.errorResponse(ErrNotificationsUnsupported)
+	}
+	var ids []ID
+	if msg.Method == unsubscribeManyMethod {
+		args, err := parsePositionalArguments(msg.Params, []reflect.Type{idListType}, h.decodeConfig())
+		if err != nil {
+			return msg.errorResponse(&invalidParamsError{err.Error()})
+		}
+		ids, _ = args[0].Interface().([]ID)
+	}
+
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if msg.Method == unsubscribeAllMethod {
+		for id := range h.serverSubs {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+	}
+	summary := UnsubscribeSummary{Unsubscribed: []ID{}}
+	for _, id := range ids {
+		s := h.lookupSubscription(cp.ctx, id)
+		if s == nil {
+			// Subscriptions of other owners are skipped by rpc_unsubscribeAll.
+			if msg.Method == unsubscribeManyMethod {
+				summary.NotFound = append(summary.NotFound, id)
+			}
+			continue
+		}
+		s.close(nil)
+		delete(h.serverSubs, id)
+		summary.Unsubscribed = append(summary.Unsubscribed, id)
+	}
+	return msg.response(summary)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// idleSubService creates subscriptions which never send notifications.
+type idleSubService struct{}
+
+func (idleSubService) Idle(ctx context.Context) (*Subscription, error) {
+	n, _ := NotifierFromContext(ctx)
+	return n.CreateSubscription(), nil
+}
+
+func subscribeIdle(t *testing.T, client *Client, n int) []ID {
+	t.Helper()
+	ids := make([]ID, n)
+	for i := range ids {
+		sub, err := client.Subscribe(context.Background(), "idle", make(chan int), "idle")
+		if err != nil {
+			t.Fatal("can't subscribe:", err)
+		}
+		ids[i] = ID(sub.id())
+	}
+	return ids
+}
+
+func TestUnsubscribeMany(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("idle", idleSubService{})
+	client := DialInProc(server)
+	defer client.Close()
+
+	ids := subscribeIdle(t, client, 3)
+	var summary UnsubscribeSummary
+	if err := client.Call(&summary, "rpc_unsubscribeMany", []ID{ids[0], "0x1234", ids[2]}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(summary.Unsubscribed, []ID{ids[0], ids[2]}) || !slices.Equal(summary.NotFound, []ID{"0x1234"}) {
+		t.Fatalf("wrong summary %+v", summary)
+	}
+
+	// The remaining subscription is still active.
+	var ok bool
+	if err := client.Call(&ok, "idle_unsubscribe", ids[1]); err != nil || !ok {
+		t.Fatalf("unsubscribe of remaining subscription: %v, %v", ok, err)
+	}
+	wantCallError(t, client.Call(&summary, "rpc_unsubscribeMany", "0x1"), -32602)
+}
+
+func TestUnsubscribeAll(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("idle", idleSubService{})
+	client := DialInProc(server)
+	defer client.Close()
+
+	ids := subscribeIdle(t, client, 3)
+	slices.Sort(ids)
+	var summary UnsubscribeSummary
+	if err := client.Call(&summary, "rpc_unsubscribeAll"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(summary.Unsubscribed, ids) || len(summary.NotFound) != 0 {
+		t.Fatalf("wrong summary %+v", summary)
+	}
+	if err := client.Call(&summary, "rpc_unsubscribeAll"); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Unsubscribed) != 0 {
+		t.Fatalf("subscriptions left after rpc_unsubscribeAll: %v", summary.Unsubscribed)
+	}
+}
+
+func TestUnsubscribeAllHTTP(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Call(nil, "rpc_unsubscribeAll"); err == nil || err.Error() != ErrNotificationsUnsupported.Error() {
+		t.Fatalf("wrong error %v", err)
+	}
+}
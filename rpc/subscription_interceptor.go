@@ -0,0 +1,73 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"slices"
+)
+
+// SubscriptionNotification is a notification passed to a SubscriptionInterceptor.
+type SubscriptionNotification struct {
+	Subscription ID
+	Namespace    string
+	Name         string // subscription name, e.g. "logs" for eth_subscribe("logs", ...)
+	Data         any    // the value given to Notifier.Notify
+}
+
+// SubscriptionInterceptor inspects a notification before it is sent. It can replace
+// n.Data to redact or transform the payload, and returns false to drop the notification.
+// The context carries the values of the subscribe call, such as PeerInfoFromContext and
+// ConnIDFromContext, which allows filtering notifications per client. It is not canceled
+// when the subscribe call returns.
+//
+// Interceptors run on the goroutine calling Notifier.Notify, before rate limiting and
+// serialization, while the notifier is locked. They must not call Notify themselves.
+type SubscriptionInterceptor func(ctx context.Context, n *SubscriptionNotification) bool
+
+// SetSubscriptionInterceptors installs interceptors for the notifications of all
+// subscriptions served by the server, including those which already exist. They run in
+// the given order, and a notification dropped by one interceptor is not passed to the
+// others. Calling SetSubscriptionInterceptors without arguments removes them.
+func (s *Server) SetSubscriptionInterceptors(interceptors ...SubscriptionInterceptor) {
+	interceptors = slices.Clone(interceptors)
+	s.services.updateConfig(func(cfg *registryConfig) { cfg.subInterceptors = interceptors })
+}
+
+// intercept runs the subscription interceptors on a notification. It returns the data to
+// send, and false if the notification was dropped. It must be called with n.mu held.
+func (n *Notifier) intercept(data any) (any, bool) {
+	if n.h.reg == nil {
+		return data, true
+	}
+	interceptors := n.h.reg.snapshot().subInterceptors
+	if len(interceptors) == 0 {
+		return data, true
+	}
+	ctx := n.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sn := &SubscriptionNotification{Subscription: n.sub.ID, Namespace: n.namespace, Name: n.name, Data: data}
+	for _, fn := range interceptors {
+		if !fn(ctx, sn) {
+			notificationsFilteredCounter.Inc(1)
+			return nil, false
+		}
+	}
+	return sn.Data, true
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionInterceptors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	seen := make(chan SubscriptionNotification, 20)
+	server.SetSubscriptionInterceptors(
+		func(ctx context.Context, n *SubscriptionNotification) bool {
+			if ConnIDFromContext(ctx) == 0 {
+				t.Error("interceptor context has no connection id")
+			}
+			seen <- *n
+			return n.Data.(int)%2 == 0 // drop odd values
+		},
+		func(ctx context.Context, n *SubscriptionNotification) bool {
+			n.Data = n.Data.(int) * 10
+			return true
+		},
+	)
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 6, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	for _, want := range []int{0, 20, 40} {
+		select {
+		case v := <-ch:
+			if v != want {
+				t.Fatalf("got notification %d, want %d", v, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("notification not received")
+		}
+	}
+	select {
+	case v := <-ch:
+		t.Fatalf("unexpected notification %d", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	n := <-seen
+	if n.Namespace != "nftest" || n.Name != "someSubscription" || string(n.Subscription) != sub.id() {
+		t.Errorf("wrong notification info %+v", n)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// resubscribeDelay is the time between resubscription attempts, see WithResubscribe.
+const resubscribeDelay = time.Second
+
+// resubscribeForever is the number of resubscription attempts set by WithAutoReconnect.
+const resubscribeForever = -1
+
+// subscriptionEventBuffer is the capacity of the ClientSubscription.Lifecycle channel.
+const subscriptionEventBuffer = 16
+
+// SubscriptionEventKind identifies the type of a SubscriptionEvent.
+type SubscriptionEventKind int
+
+const (
+	// SubscriptionEstablished is reported when the server has confirmed the subscription.
+	SubscriptionEstablished SubscriptionEventKind = iota
+	// SubscriptionResubscribed is reported when the subscription was created again on a
+	// new connection, see WithResubscribe.
+	SubscriptionResubscribed
+	// SubscriptionGapDetected is reported when notifications may have been lost. The
+	// From and To fields of the event give the time window of the gap.
+	SubscriptionGapDetected
+	// SubscriptionClosed is the last event of a subscription. The Err field of the event
+	// says why it ended.
+	SubscriptionClosed
+)
+
+func (k SubscriptionEventKind) String() string {
+	switch k {
+	case SubscriptionEstablished:
+		return "established"
+	case SubscriptionResubscribed:
+		return "resubscribed"
+	case SubscriptionGapDetected:
+		return "gap detected"
+	case SubscriptionClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("SubscriptionEventKind(%d)", int(k))
+	}
+}
+
+// SubscriptionEvent describes a change in the state of a ClientSubscription.
+type SubscriptionEvent struct {
+	Kind SubscriptionEventKind
+	ID   string // subscription id assigned by the server
+
+	// Set for SubscriptionResubscribed.
+	PreviousID string
+
+	// Set for SubscriptionGapDetected. Notifications sent by the server between From
+	// and To were not received.
+	From, To time.Time
+
+	// Set for SubscriptionClosed. Err is nil if the subscription ended because
+	// Unsubscribe was called or the client was closed.
+	Err error
+}
+
+// WithResubscribe makes websocket and IPC subscriptions survive the loss of the
+// connection. When the connection breaks, the client reconnects and repeats the
+// subscription request, trying up to attempts times one second apart. Notifications
+// sent by the server while no connection existed are lost. This is reported through
+// the Lifecycle channel of the subscription, so applications can fetch the missed
+// data. If all attempts fail, the subscription ends with the last error.
+func WithResubscribe(attempts int) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.resubscribeAttempts = attempts
+	})
+}
+
+// Lifecycle returns a channel receiving the lifecycle events of the subscription: its
+// establishment, resubscriptions and gaps (see WithResubscribe) and finally its end.
+// The channel is closed after the SubscriptionClosed event.
+//
+// The channel buffers a small number of events. Events are dropped if the buffer is
+// full, so applications using lifecycle events should read them promptly.
+func (sub *ClientSubscription) Lifecycle() <-chan SubscriptionEvent {
+	return sub.events
+}
+
+// emit delivers a lifecycle event without blocking.
+func (sub *ClientSubscription) emit(ev SubscriptionEvent) {
+	sub.eventsMu.Lock()
+	defer sub.eventsMu.Unlock()
+
+	if sub.eventsClosed {
+		return
+	}
+	select {
+	case sub.events <- ev:
+	default:
+		log.Debug("Dropped subscription lifecycle event", "id", ev.ID, "kind", ev.Kind)
+	}
+	if ev.Kind == SubscriptionClosed {
+		sub.eventsClosed = true
+		close(sub.events)
+	}
+}
+
+// id returns the current server-side id of the subscription.
+func (sub *ClientSubscription) id() string {
+	sub.idMu.Lock()
+	defer sub.idMu.Unlock()
+	return sub.subid
+}
+
+// ID returns the id assigned to the subscription by the server. The id changes when the
+// subscription is re-established, see WithResubscribe.
+func (sub *ClientSubscription) ID() ID {
+	return ID(sub.id())
+}
+
+// startClientSubscription registers sub under the id assigned by the server. The
+// forwarding loop is started when the subscription is first established.
+func (h *handler) startClientSubscription(sub *ClientSubscription, id string) {
+	sub.idMu.Lock()
+	resubscribed := sub.started
+	sub.subid, sub.started = id, true
+	sub.idMu.Unlock()
+
+	h.clientSubs[id] = sub
+	if h.dispatch != nil {
+		h.dispatch.assign(sub)
+	}
+	if !resubscribed {
+		go sub.run()
+		sub.emit(SubscriptionEvent{Kind: SubscriptionEstablished, ID: id})
+	}
+}
+
+// canResubscribe reports whether subscriptions are created again after the connection
+// is lost with the given error.
+func (c *Client) canResubscribe(err error) bool {
+	return c.resubscribeAttempts != 0 && c.reconnectFunc != nil && err != ErrClientQuit
+}
+
+// resubscribe creates sub again on a new connection. The previous connection was
+// lost with error cause.
+func (c *Client) resubscribe(sub *ClientSubscription, cause error) {
+	var (
+		lost   = time.Now()
+		prevID = sub.id()
+		err    = cause
+	)
+	forever := c.resubscribeAttempts == resubscribeForever
+	for attempt := 0; forever || attempt < c.resubscribeAttempts; attempt++ {
+		if attempt > 0 {
+			timer := c.clock.NewTimer(resubscribeDelay)
+			select {
+			case <-timer.C():
+			case <-sub.forwardDone:
+				timer.Stop()
+				return
+			case <-c.closing:
+				timer.Stop()
+				sub.close(ErrClientQuit)
+				return
+			}
+		}
+		select {
+		case <-sub.forwardDone:
+			return // unsubscribed while disconnected
+		default:
+		}
+		if c.reconnector != nil {
+			// Wait for the connection to be re-established in the background.
+			if c.reconnector.wait(context.Background(), c.closing) != nil {
+				sub.close(ErrClientQuit)
+				return
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+		_, err = c.subscribe(ctx, sub.namespace, sub, sub.args...)
+		cancel()
+		if err == nil {
+			select {
+			case <-sub.forwardDone:
+				// Unsubscribe was called during the attempt. The forwarding loop has
+				// already tried to remove the old subscription.
+				sub.requestUnsubscribe()
+				return
+			default:
+			}
+			id := sub.id()
+			log.Debug("RPC subscription resubscribed", "id", id, "previous", prevID)
+			sub.emit(SubscriptionEvent{Kind: SubscriptionResubscribed, ID: id, PreviousID: prevID})
+			sub.emit(SubscriptionEvent{Kind: SubscriptionGapDetected, ID: id, From: lost, To: time.Now()})
+			return
+		}
+		if err == ErrClientQuit {
+			break
+		}
+		var rpcErr Error
+		if forever && errors.As(err, &rpcErr) {
+			break // rejected by the server, retrying won't help
+		}
+		log.Debug("RPC resubscription failed", "id", prevID, "attempt", attempt+1, "err", err)
+	}
+	sub.close(err)
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connTrackingListener records accepted connections, so tests can break them.
+type connTrackingListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *connTrackingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns = append(l.conns, c)
+		l.mu.Unlock()
+	}
+	return c, err
+}
+
+func (l *connTrackingListener) breakConns() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range l.conns {
+		c.Close()
+	}
+	l.conns = nil
+}
+
+func nextSubscriptionEvent(t *testing.T, events <-chan SubscriptionEvent) SubscriptionEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscription event")
+		return SubscriptionEvent{}
+	}
+}
+
+func TestClientSubscriptionLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses unix sockets")
+	}
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	endpoint := filepath.Join(dir, "test.ipc")
+	l, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &connTrackingListener{Listener: l}
+	server := newTestServer()
+	defer server.Stop()
+	go server.ServeListener(listener)
+
+	client, err := DialOptions(context.Background(), endpoint, WithResubscribe(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := nextSubscriptionEvent(t, sub.Lifecycle())
+	if ev.Kind != SubscriptionEstablished || ev.ID == "" {
+		t.Fatalf("wrong first event %+v", ev)
+	}
+	firstID := ev.ID
+	if v := <-ch; v != 7 {
+		t.Fatalf("wrong notification %d", v)
+	}
+
+	// Break the connection. The subscription is created again on a new connection.
+	listener.breakConns()
+	ev = nextSubscriptionEvent(t, sub.Lifecycle())
+	if ev.Kind != SubscriptionResubscribed || ev.PreviousID != firstID || ev.ID == firstID {
+		t.Fatalf("wrong resubscribe event %+v", ev)
+	}
+	ev = nextSubscriptionEvent(t, sub.Lifecycle())
+	if ev.Kind != SubscriptionGapDetected || ev.To.Before(ev.From) {
+		t.Fatalf("wrong gap event %+v", ev)
+	}
+	if v := <-ch; v != 7 {
+		t.Fatalf("wrong notification after resubscribe %d", v)
+	}
+
+	// Resubscribing fails when the server is gone, ending the subscription.
+	listener.Close()
+	listener.breakConns()
+	ev = nextSubscriptionEvent(t, sub.Lifecycle())
+	if ev.Kind != SubscriptionClosed || ev.Err == nil {
+		t.Fatalf("wrong close event %+v", ev)
+	}
+	if _, ok := <-sub.Lifecycle(); ok {
+		t.Fatal("lifecycle channel not closed")
+	}
+	if err := <-sub.Err(); err == nil {
+		t.Fatal("no subscription error")
+	}
+}
+
+func TestClientSubscriptionLifecycleUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextSubscriptionEvent(t, sub.Lifecycle()); ev.Kind != SubscriptionEstablished {
+		t.Fatalf("wrong first event %+v", ev)
+	}
+	sub.Unsubscribe()
+	ev := nextSubscriptionEvent(t, sub.Lifecycle())
+	if ev.Kind != SubscriptionClosed || ev.Err != nil {
+		t.Fatalf("wrong close event %+v", ev)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	pauseMethodSuffix  = "_pauseSubscription"
+	resumeMethodSuffix = "_resumeSubscription"
+
+	// defaultPauseBufferSize is the number of notifications buffered for a paused
+	// subscription, see SetSubscriptionPauseBuffer.
+	defaultPauseBufferSize = 256
+)
+
+// SetSubscriptionPauseBuffer sets the number of notifications the server buffers for a
+// paused subscription. A size of zero restores the default of 256.
+//
+// Clients pause a subscription by calling <namespace>_pauseSubscription with its id,
+// e.g. while their UI is in the background, and call <namespace>_resumeSubscription to
+// receive the notifications buffered in the meantime. When the buffer is full, the
+// oldest notifications are dropped. Subscriptions with a rate limit merge function (see
+// Notifier.SetRateLimit) coalesce all notifications sent while paused into one instead.
+// resumeSubscription returns the number of notifications which were dropped.
+func (s *Server) SetSubscriptionPauseBuffer(size int) {
+	s.services.updateConfig(func(c *registryConfig) { c.pauseBufferSize = max(size, 0) })
+}
+
+// pauseBuffer returns the configured pause buffer size.
+func (cfg *registryConfig) pauseBuffer() int {
+	if cfg.pauseBufferSize > 0 {
+		return cfg.pauseBufferSize
+	}
+	return defaultPauseBufferSize
+}
+
+// isSubscriptionControl reports whether msg pauses or resumes a subscription.
+func (msg *jsonrpcMessage) isSubscriptionControl() bool {
+	return strings.HasSuffix(msg.Method, pauseMethodSuffix) || strings.HasSuffix(msg.Method, resumeMethodSuffix)
+}
+
+// handleSubscriptionControl processes the *_pauseSubscription and *_resumeSubscription
+// methods.
+func (h *handler) handleSubscriptionControl(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	args, err := parsePositionalArguments(msg.Params, []reflect.Type{stringType}, h.decodeConfig())
+	if err != nil {
+		return msg.errorResponse(&invalidParamsError{err.Error()})
+	}
+	pause := strings.HasSuffix(msg.Method, pauseMethodSuffix)
+	namespace := strings.TrimSuffix(strings.TrimSuffix(msg.Method, pauseMethodSuffix), resumeMethodSuffix)
+
+	h.subLock.Lock()
+	s := h.lookupSubscription(cp.ctx, ID(args[0].String()))
+	h.subLock.Unlock()
+	if s == nil || s.namespace != namespace {
+		return msg.errorResponse(ErrSubscriptionNotFound)
+	}
+	if pause {
+		s.notifier.pause(cp.cfg.pauseBuffer())
+		return msg.response(true)
+	}
+	dropped, err := s.notifier.resume()
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	return msg.response(dropped)
+}
+
+// pause makes the notifier hold back notifications, buffering up to limit of them.
+func (n *Notifier) pause(limit int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.paused {
+		n.paused, n.pauseLimit, n.pauseDropped = true, limit, 0
+	}
+}
+
+// resume sends the notifications buffered while the notifier was paused. It returns the
+// number of notifications which were dropped.
+func (n *Notifier) resume() (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.paused {
+		return 0, nil
+	}
+	n.paused = false
+	dropped := n.pauseDropped
+	if !n.activated {
+		// The session is detached. Buffered notifications are sent on activation.
+		return dropped, nil
+	}
+	buffer := n.buffer
+	n.buffer = nil
+	for _, data := range buffer {
+		if err := n.send(n.sub, data); err != nil {
+			return dropped, err
+		}
+	}
+	return dropped, nil
+}
+
+// bufferPaused holds back a notification while the notifier is paused. It must be called
+// with n.mu held.
+func (n *Notifier) bufferPaused(data any) {
+	switch {
+	case n.merge != nil && len(n.buffer) > 0:
+		last := len(n.buffer) - 1
+		n.buffer[last] = n.merge(n.buffer[last], data)
+		notificationsMergedCounter.Inc(1)
+	case len(n.buffer) >= n.pauseLimit:
+		copy(n.buffer, n.buffer[1:])
+		n.buffer[len(n.buffer)-1] = data
+		n.pauseDropped++
+		notificationsDroppedCounter.Inc(1)
+	default:
+		n.buffer = append(n.buffer, data)
+	}
+}
+
+// Pause asks the server to hold back the notifications of the subscription until Resume
+// is called. The server buffers a limited number of notifications in the meantime, see
+// Server.SetSubscriptionPauseBuffer. This is cheaper than unsubscribing and subscribing
+// again, and keeps the state of the subscription on the server.
+//
+// A subscription which is recreated by WithResubscribe is no longer paused.
+func (sub *ClientSubscription) Pause(ctx context.Context) error {
+	var ok bool
+	if err := sub.client.CallContext(ctx, &ok, sub.namespace+pauseMethodSuffix, sub.id()); err != nil {
+		return err
+	}
+	sub.idMu.Lock()
+	if sub.pausedAt.IsZero() {
+		sub.pausedAt = time.Now()
+	}
+	sub.idMu.Unlock()
+	return nil
+}
+
+// Resume delivers the notifications held back since Pause and continues the
+// subscription. It returns the number of notifications which the server had to drop
+// because its buffer was full. Lost notifications are also reported as a
+// SubscriptionGapDetected event on the Lifecycle channel.
+func (sub *ClientSubscription) Resume(ctx context.Context) (int, error) {
+	var dropped int
+	if err := sub.client.CallContext(ctx, &dropped, sub.namespace+resumeMethodSuffix, sub.id()); err != nil {
+		return 0, err
+	}
+	sub.idMu.Lock()
+	pausedAt := sub.pausedAt
+	sub.pausedAt = time.Time{}
+	sub.idMu.Unlock()
+	if dropped > 0 {
+		sub.emit(SubscriptionEvent{Kind: SubscriptionGapDetected, ID: sub.id(), From: pausedAt, To: time.Now()})
+	}
+	return dropped, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// pauseTestService hands out the notifier and subscription of each subscribe call.
+type pauseTestService struct {
+	subs chan pauseTestSub
+}
+
+type pauseTestSub struct {
+	n   *Notifier
+	sub *Subscription
+}
+
+func (s *pauseTestService) Values(ctx context.Context, merge bool) (*Subscription, error) {
+	n, _ := NotifierFromContext(ctx)
+	if merge {
+		n.SetRateLimit(0, func(pending, data any) any {
+			sum, _ := pending.(int)
+			return sum + data.(int)
+		})
+	}
+	sub := n.CreateSubscription()
+	s.subs <- pauseTestSub{n, sub}
+	return sub, nil
+}
+
+func (s pauseTestSub) notify(t *testing.T, values ...int) {
+	t.Helper()
+	for _, v := range values {
+		if err := s.n.Notify(s.sub.ID, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func receiveValues(t *testing.T, ch <-chan int, want ...int) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case v := <-ch:
+			if v != w {
+				t.Fatalf("got notification %d, want %d", v, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for notification %d", w)
+		}
+	}
+}
+
+func TestSubscriptionPause(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetSubscriptionPauseBuffer(2)
+	svc := &pauseTestService{subs: make(chan pauseTestSub, 1)}
+	server.RegisterName("pause", svc)
+	client := DialInProc(server)
+	defer client.Close()
+	ctx := context.Background()
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(ctx, "pause", ch, "values", false)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+	s := <-svc.subs
+	s.notify(t, 1)
+	receiveValues(t, ch, 1)
+
+	if err := sub.Pause(ctx); err != nil {
+		t.Fatal("pause failed:", err)
+	}
+	s.notify(t, 2, 3, 4)
+	select {
+	case v := <-ch:
+		t.Fatalf("received notification %d while paused", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	dropped, err := sub.Resume(ctx)
+	if err != nil {
+		t.Fatal("resume failed:", err)
+	}
+	if dropped != 1 {
+		t.Fatalf("wrong number of dropped notifications %d", dropped)
+	}
+	receiveValues(t, ch, 3, 4)
+	for ev := range sub.Lifecycle() {
+		if ev.Kind == SubscriptionGapDetected {
+			if ev.From.IsZero() || ev.To.Before(ev.From) {
+				t.Fatalf("wrong gap %v - %v", ev.From, ev.To)
+			}
+			break
+		}
+	}
+
+	// Notifications flow again after resuming.
+	s.notify(t, 5)
+	receiveValues(t, ch, 5)
+}
+
+func TestSubscriptionPauseMerge(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetSubscriptionPauseBuffer(2)
+	svc := &pauseTestService{subs: make(chan pauseTestSub, 1)}
+	server.RegisterName("pause", svc)
+	client := DialInProc(server)
+	defer client.Close()
+	ctx := context.Background()
+	ch := make(chan int, 10)
+	sub, err := client.Subscribe(ctx, "pause", ch, "values", true)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+	s := <-svc.subs
+
+	if err := sub.Pause(ctx); err != nil {
+		t.Fatal("pause failed:", err)
+	}
+	s.notify(t, 1, 2, 3, 4)
+	dropped, err := sub.Resume(ctx)
+	if err != nil || dropped != 0 {
+		t.Fatalf("resume: dropped %d, err %v", dropped, err)
+	}
+	receiveValues(t, ch, 10)
+}
+
+func TestSubscriptionPauseUnknown(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetSubscriptionPauseBuffer(2)
+	svc := &pauseTestService{subs: make(chan pauseTestSub, 1)}
+	server.RegisterName("pause", svc)
+	client := DialInProc(server)
+	defer client.Close()
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "pause", ch, "values", false)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	defer sub.Unsubscribe()
+	<-svc.subs
+
+	for _, call := range []struct{ method, id string }{
+		{"pause_pauseSubscription", "0x123"},
+		{"pause_resumeSubscription", "0x123"},
+		{"other_pauseSubscription", sub.id()},
+	} {
+		if err := client.Call(nil, call.method, call.id); err == nil || err.Error() != ErrSubscriptionNotFound.Error() {
+			t.Errorf("%s(%s): wrong error %v", call.method, call.id, err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// SubscriptionOverflowPolicy selects what happens to a notification which doesn't fit
+// into the send queue of its subscription, see SetSubscriptionQueue.
+type SubscriptionOverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest queued notification to make room.
+	OverflowDropOldest SubscriptionOverflowPolicy = iota
+	// OverflowDropNewest discards the new notification.
+	OverflowDropNewest
+	// OverflowDisconnect closes the connection of the subscriber.
+	OverflowDisconnect
+	// OverflowCoalesce combines the new notification with the newest queued one.
+	OverflowCoalesce
+)
+
+// SubscriptionQueue configures the send queue of subscriptions.
+type SubscriptionQueue struct {
+	// Size is the number of notifications queued per subscription.
+	Size int
+
+	Policy SubscriptionOverflowPolicy
+
+	// Merge combines notifications for OverflowCoalesce. It receives the newest queued
+	// notification and the new one, and returns the value replacing the queued
+	// notification. When Merge is nil, the new notification replaces the queued one.
+	Merge func(queued, data any) any
+}
+
+// SetSubscriptionQueue makes the subscriptions of the given namespace send their
+// notifications from a queue of bounded size, so that Notifier.Notify doesn't block
+// while the subscriber is slow to receive them. The namespace "" sets the default for
+// all namespaces without a queue of their own. A queue size of zero removes the queue
+// configuration of the namespace.
+//
+// When a notification doesn't fit into the queue, the policy decides whether to drop a
+// notification, to coalesce notifications or to disconnect the subscriber. Dropped
+// notifications are counted in the rpc/notifications/overflow metric. The configuration
+// applies to subscriptions created afterwards.
+func (s *Server) SetSubscriptionQueue(namespace string, queue SubscriptionQueue) {
+	s.services.updateConfig(func(cfg *registryConfig) {
+		cfg.subQueues = maps.Clone(cfg.subQueues)
+		if queue.Size <= 0 {
+			delete(cfg.subQueues, namespace)
+			return
+		}
+		if cfg.subQueues == nil {
+			cfg.subQueues = make(map[string]SubscriptionQueue)
+		}
+		cfg.subQueues[namespace] = queue
+	})
+}
+
+// subscriptionQueue creates the send queue for a subscription of the given namespace.
+// It returns nil if subscriptions of the namespace don't use a queue.
+func (r *registryConfig) subscriptionQueue(namespace string) *notifyQueue {
+	cfg, ok := r.subQueues[namespace]
+	if !ok {
+		if cfg, ok = r.subQueues[""]; !ok {
+			return nil
+		}
+	}
+	return &notifyQueue{cfg: cfg}
+}
+
+// notifyQueue is the send queue of a subscription. Notifications are written by a
+// goroutine which runs while the queue isn't empty.
+type notifyQueue struct {
+	cfg SubscriptionQueue
+
+	mu      sync.Mutex
+	items   []any
+	sending bool
+}
+
+// push adds a notification to the queue, applying the overflow policy. It is called
+// with n.mu held.
+func (q *notifyQueue) push(n *Notifier, sub *Subscription, data any) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) >= q.cfg.Size {
+		switch q.cfg.Policy {
+		case OverflowDropOldest:
+			notificationsOverflowCounter.Inc(1)
+			q.items[0] = nil
+			q.items = q.items[1:]
+		case OverflowDropNewest:
+			notificationsOverflowCounter.Inc(1)
+			return
+		case OverflowDisconnect:
+			notificationsOverflowCounter.Inc(1)
+			q.items = nil
+			n.h.log.Debug("Closing RPC connection of slow subscriber", "id", sub.ID)
+			go n.h.closeConn(websocket.ClosePolicyViolation, "subscription queue overflow")
+			return
+		case OverflowCoalesce:
+			notificationsMergedCounter.Inc(1)
+			last := len(q.items) - 1
+			if q.cfg.Merge != nil {
+				data = q.cfg.Merge(q.items[last], data)
+			}
+			q.items[last] = data
+			return
+		}
+	}
+	q.items = append(q.items, data)
+	if !q.sending {
+		q.sending = true
+		go q.send(n, sub)
+	}
+}
+
+// pop removes the oldest notification. It returns false and ends sending if the queue
+// is empty.
+func (q *notifyQueue) pop() (any, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		q.items = nil
+		q.sending = false
+		return nil, false
+	}
+	data := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	return data, true
+}
+
+// clear drops all queued notifications.
+func (q *notifyQueue) clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = nil
+}
+
+// send writes queued notifications until the queue is empty.
+func (q *notifyQueue) send(n *Notifier, sub *Subscription) {
+	for {
+		data, ok := q.pop()
+		if !ok {
+			return
+		}
+		n.mu.Lock()
+		h, closed := n.h, n.closed
+		n.mu.Unlock()
+		if closed {
+			continue // the queue was cleared
+		}
+		if err := n.write(h, sub, data); err != nil {
+			h.log.Debug("Failed to send queued notification", "err", err)
+			q.clear()
+		}
+	}
+}
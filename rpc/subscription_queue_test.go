@@ -0,0 +1,194 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// queueTestService hands the notifiers of new subscriptions to the test.
+type queueTestService struct {
+	notifiers chan *Notifier
+}
+
+func (s *queueTestService) Values(ctx context.Context) (*Subscription, error) {
+	notifier, _ := NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	s.notifiers <- notifier
+	return sub, nil
+}
+
+// queueTestConn is the client side of a synchronous connection to the server, so that
+// notifications pile up in the send queue while the test doesn't read them.
+type queueTestConn struct {
+	t    *testing.T
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+// subscribe creates a connection to the server and subscribes to the queue service.
+func (s *queueTestService) subscribe(t *testing.T, server *Server) (*queueTestConn, *Notifier) {
+	t.Helper()
+	p1, p2 := net.Pipe()
+	go server.ServeCodec(NewCodec(p1), 0)
+	c := &queueTestConn{t: t, conn: p2, dec: json.NewDecoder(p2)}
+	p2.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(p2, `{"jsonrpc":"2.0","id":1,"method":"queue_subscribe","params":["values"]}`); err != nil {
+		t.Fatal(err)
+	}
+	var resp jsonrpcMessage
+	if err := c.dec.Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("subscribe failed: %v %v", err, resp.Error)
+	}
+	return c, <-s.notifiers
+}
+
+// notifyAll sends the given values as notifications.
+func notifyAll(n *Notifier, values ...int) {
+	for _, v := range values {
+		n.Notify(n.sub.ID, v)
+	}
+}
+
+// read reads one notification.
+func (c *queueTestConn) read() (int, error) {
+	var msg struct {
+		Params struct {
+			Result int `json:"result"`
+		} `json:"params"`
+	}
+	err := c.dec.Decode(&msg)
+	return msg.Params.Result, err
+}
+
+// readAvailable reads notifications until none arrives for a while.
+func (c *queueTestConn) readAvailable() []int {
+	c.t.Helper()
+	var values []int
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		v, err := c.read()
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			// The decoder keeps returning the error, so replace it.
+			c.dec = json.NewDecoder(c.conn)
+			return values
+		}
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+}
+
+func TestSubscriptionQueueDropOldest(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &queueTestService{notifiers: make(chan *Notifier, 1)}
+	server.RegisterName("queue", svc)
+	server.SetSubscriptionQueue("queue", SubscriptionQueue{Size: 2, Policy: OverflowDropOldest})
+	c, n := svc.subscribe(t, server)
+	defer c.conn.Close()
+	notifyAll(n, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	// One notification may already be in flight, followed by the two newest.
+	values := c.readAvailable()
+	if len(values) < 2 || len(values) > 3 || !slices.Equal(values[len(values)-2:], []int{8, 9}) {
+		t.Fatalf("wrong notifications %v", values)
+	}
+}
+
+func TestSubscriptionQueueDropNewest(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &queueTestService{notifiers: make(chan *Notifier, 1)}
+	server.RegisterName("queue", svc)
+	server.SetSubscriptionQueue("queue", SubscriptionQueue{Size: 2, Policy: OverflowDropNewest})
+	c, n := svc.subscribe(t, server)
+	defer c.conn.Close()
+	notifyAll(n, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	// One notification may already be in flight, in addition to the two queued ones.
+	values := c.readAvailable()
+	if len(values) < 2 || len(values) > 3 || !slices.Equal(values, []int{0, 1, 2}[:len(values)]) {
+		t.Fatalf("wrong notifications %v", values)
+	}
+
+	// The queue accepts notifications again once drained.
+	notifyAll(n, 100)
+	if values := c.readAvailable(); !slices.Equal(values, []int{100}) {
+		t.Fatalf("wrong notifications %v after draining queue", values)
+	}
+}
+
+func TestSubscriptionQueueCoalesce(t *testing.T) {
+	t.Parallel()
+
+	sum := func(queued, data any) any { return queued.(int) + data.(int) }
+	server := newTestServer()
+	defer server.Stop()
+	svc := &queueTestService{notifiers: make(chan *Notifier, 1)}
+	server.RegisterName("queue", svc)
+	server.SetSubscriptionQueue("queue", SubscriptionQueue{Size: 1, Policy: OverflowCoalesce, Merge: sum})
+	c, n := svc.subscribe(t, server)
+	defer c.conn.Close()
+	notifyAll(n, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+
+	total := 0
+	for total < 55 {
+		v, err := c.read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += v
+	}
+	if total != 55 {
+		t.Fatalf("coalesced notifications add up to %d, want 55", total)
+	}
+}
+
+func TestSubscriptionQueueDisconnect(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &queueTestService{notifiers: make(chan *Notifier, 1)}
+	server.RegisterName("queue", svc)
+	server.SetSubscriptionQueue("queue", SubscriptionQueue{Size: 1, Policy: OverflowDisconnect})
+	c, n := svc.subscribe(t, server)
+	defer c.conn.Close()
+	notifyAll(n, 0, 1, 2, 3)
+	for {
+		if _, err := c.read(); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrClosedPipe) {
+				t.Fatalf("wrong error %v", err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errSubscriptionRunnerPanic ends a subscription whose producer has crashed.
+var errSubscriptionRunnerPanic = errors.New("subscription producer crashed")
+
+// SubscriptionRunner produces the notifications of a subscription. It sends them using
+// notify and should return when ctx is canceled, which happens when the client
+// unsubscribes or the connection is closed. context.Cause(ctx) tells why.
+//
+// Returning ends the subscription. A non-nil error is delivered to the subscription,
+// i.e. it is sent on its Err channel and passed to the OnClose callback of the
+// notifier. Returning nil ends the subscription as if the client had unsubscribed.
+type SubscriptionRunner func(ctx context.Context, notify func(data any) error) error
+
+// RunSubscription creates a subscription using the notifier of ctx, and starts run on a
+// new goroutine to produce its notifications. It is meant to be returned directly from
+// a subscription method:
+//
+//	func (api *API) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+//		return rpc.RunSubscription(ctx, func(ctx context.Context, notify func(any) error) error {
+//			...
+//		})
+//	}
+//
+// The goroutine is tied to the lifetime of the subscription, so it doesn't leak when
+// the client goes away. A panic in run is recovered and ends the subscription with an
+// error.
+func RunSubscription(ctx context.Context, run SubscriptionRunner) (*Subscription, error) {
+	n, ok := NotifierFromContext(ctx)
+	if !ok {
+		return nil, ErrNotificationsUnsupported
+	}
+	sub := n.CreateSubscription()
+
+	// The producer outlives the subscribe call, so it only keeps the values of ctx.
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	n.mu.Lock()
+	n.cancelRunner = cancel
+	n.mu.Unlock()
+
+	go func() {
+		err := runSubscription(runCtx, sub, run)
+		if runCtx.Err() != nil {
+			return // the subscription has already ended
+		}
+		if err != nil {
+			log.Debug("Subscription producer failed", "id", sub.ID, "namespace", sub.namespace, "err", err)
+		}
+		n.end(err)
+	}()
+	return sub, nil
+}
+
+// runSubscription calls run, turning a panic into an error.
+func runSubscription(ctx context.Context, sub *Subscription, run SubscriptionRunner) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			log.Error("Producer of subscription " + string(sub.ID) + " crashed: " + fmt.Sprintf("%v\n%s", r, buf))
+			err = errSubscriptionRunnerPanic
+		}
+	}()
+	notify := func(data any) error {
+		return sub.notifier.Notify(sub.ID, data)
+	}
+	return run(ctx, notify)
+}
+
+// end terminates the subscription from the server side.
+func (n *Notifier) end(err error) {
+	n.mu.Lock()
+	h, sub := n.h, n.sub
+	n.mu.Unlock()
+	h.endSubscription(sub, err)
+}
+
+// endSubscription removes sub from the handler and ends it with err.
+func (h *handler) endSubscription(sub *Subscription, err error) {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if h.serverSubs[sub.ID] == sub {
+		delete(h.serverSubs, sub.ID)
+	}
+	sub.close(err)
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errProducerTest = errors.New("producer failed")
+
+type runnerTestService struct {
+	stopped chan error // cause of producer cancellation
+	closed  chan error // OnClose argument
+}
+
+func (s *runnerTestService) run(ctx context.Context, run SubscriptionRunner) (*Subscription, error) {
+	sub, err := RunSubscription(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := NotifierFromContext(ctx)
+	n.OnClose(func(err error) { s.closed <- err })
+	return sub, nil
+}
+
+func (s *runnerTestService) Count(ctx context.Context) (*Subscription, error) {
+	return s.run(ctx, func(ctx context.Context, notify func(any) error) error {
+		for i := 0; ; i++ {
+			if err := notify(i); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				s.stopped <- context.Cause(ctx)
+				return nil
+			case <-time.After(time.Millisecond):
+			}
+		}
+	})
+}
+
+func (s *runnerTestService) Fail(ctx context.Context) (*Subscription, error) {
+	return s.run(ctx, func(ctx context.Context, notify func(any) error) error {
+		notify(1)
+		return errProducerTest
+	})
+}
+
+func (s *runnerTestService) Crash(ctx context.Context) (*Subscription, error) {
+	return s.run(ctx, func(ctx context.Context, notify func(any) error) error {
+		panic("boom")
+	})
+}
+
+func waitError(t *testing.T, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+		return nil
+	}
+}
+
+func TestSubscriptionRunnerUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &runnerTestService{stopped: make(chan error, 1), closed: make(chan error, 1)}
+	server.RegisterName("runner", svc)
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "runner", ch, "count")
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	for i := 0; i < 3; i++ {
+		if v := <-ch; v != i {
+			t.Fatalf("wrong notification %d, want %d", v, i)
+		}
+	}
+	sub.Unsubscribe()
+	if err := waitError(t, svc.stopped); err != ErrSubscriptionClosed {
+		t.Errorf("wrong cancellation cause: %v", err)
+	}
+	if err := waitError(t, svc.closed); err != nil {
+		t.Errorf("wrong close reason: %v", err)
+	}
+}
+
+func TestSubscriptionRunnerDisconnect(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	svc := &runnerTestService{stopped: make(chan error, 1), closed: make(chan error, 1)}
+	server.RegisterName("runner", svc)
+	client := DialInProc(server)
+	ch := make(chan int)
+	if _, err := client.Subscribe(context.Background(), "runner", ch, "count"); err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	<-ch
+	client.Close()
+	if err := waitError(t, svc.stopped); err == nil || err == ErrSubscriptionClosed {
+		t.Errorf("wrong cancellation cause: %v", err)
+	}
+}
+
+func TestSubscriptionRunnerError(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name string
+		want error
+	}{
+		{"fail", errProducerTest},
+		{"crash", errSubscriptionRunnerPanic},
+	} {
+		server := newTestServer()
+		defer server.Stop()
+		svc := &runnerTestService{stopped: make(chan error, 1), closed: make(chan error, 1)}
+		server.RegisterName("runner", svc)
+		client := DialInProc(server)
+		defer client.Close()
+
+		sub, err := client.Subscribe(context.Background(), "runner", make(chan int, 1), test.name)
+		if err != nil {
+			t.Fatal("can't subscribe:", err)
+		}
+		if err := waitError(t, svc.closed); err != test.want {
+			t.Errorf("%s: wrong close reason: %v", test.name, err)
+		}
+		// The subscription is gone on the server.
+		var ok bool
+		if err := client.Call(&ok, "runner_unsubscribe", sub.id()); err == nil {
+			t.Errorf("%s: unsubscribe succeeded", test.name)
+		}
+		server.Stop()
+	}
+}
+
+func TestRunSubscriptionWithoutNotifier(t *testing.T) {
+	_, err := RunSubscription(context.Background(), func(context.Context, func(any) error) error { return nil })
+	if err != ErrNotificationsUnsupported {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
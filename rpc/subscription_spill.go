@@ -0,0 +1,118 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/binary"
+	"os"
+)
+
+// spillRing is a bounded FIFO of notifications stored in a file. Items are written
+// with a length prefix, wrapping around at the end of the file.
+type spillRing struct {
+	f     *os.File
+	codec StorageCodec // compresses items, nil if disabled
+	size  int64        // capacity in bytes
+	head  int64        // offset of the oldest item
+	used  int64        // number of bytes in use
+	count int          // number of items
+}
+
+func newSpillRing(dir string, size int64, codec StorageCodec) (*spillRing, error) {
+	f, err := os.CreateTemp(dir, "rpc-subscription-*.spill")
+	if err != nil {
+		return nil, err
+	}
+	return &spillRing{f: f, codec: codec, size: size}, nil
+}
+
+// push appends an item. It returns false if the item doesn't fit.
+func (r *spillRing) push(data []byte) (bool, error) {
+	if r.codec != nil {
+		var err error
+		if data, err = encodeStored(r.codec, data); err != nil {
+			return false, err
+		}
+	}
+	n := int64(4 + len(data))
+	if r.used+n > r.size {
+		return false, nil
+	}
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	if err := r.writeAt(buf, (r.head+r.used)%r.size); err != nil {
+		return false, err
+	}
+	r.used += n
+	r.count++
+	return true, nil
+}
+
+// pop removes the oldest item and returns it.
+func (r *spillRing) pop() ([]byte, error) {
+	var lenbuf [4]byte
+	if err := r.readAt(lenbuf[:], r.head); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(lenbuf[:]))
+	if err := r.readAt(data, (r.head+4)%r.size); err != nil {
+		return nil, err
+	}
+	n := int64(4 + len(data))
+	r.head = (r.head + n) % r.size
+	r.used -= n
+	r.count--
+	if r.codec != nil {
+		return decodeStored(r.codec, data)
+	}
+	return data, nil
+}
+
+func (r *spillRing) len() int {
+	return r.count
+}
+
+// close closes and removes the file.
+func (r *spillRing) close() {
+	r.f.Close()
+	os.Remove(r.f.Name())
+}
+
+func (r *spillRing) writeAt(b []byte, off int64) error {
+	first := min(int64(len(b)), r.size-off)
+	if _, err := r.f.WriteAt(b[:first], off); err != nil {
+		return err
+	}
+	if first < int64(len(b)) {
+		_, err := r.f.WriteAt(b[first:], 0)
+		return err
+	}
+	return nil
+}
+
+func (r *spillRing) readAt(b []byte, off int64) error {
+	first := min(int64(len(b)), r.size-off)
+	if _, err := r.f.ReadAt(b[:first], off); err != nil {
+		return err
+	}
+	if first < int64(len(b)) {
+		_, err := r.f.ReadAt(b[first:], 0)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSpillRing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("plain", func(t *testing.T) { testSpillRing(t, 64, nil) })
+	t.Run("gzip", func(t *testing.T) { testSpillRing(t, 256, GzipCodec) })
+	t.Run("encrypted", func(t *testing.T) {
+		codec := NewEncryptedCodec(StaticStorageKey(make([]byte, 32)), nil)
+		testSpillRing(t, 256, codec)
+	})
+}
+
+func testSpillRing(t *testing.T, size int64, codec StorageCodec) {
+	r, err := newSpillRing(t.TempDir(), size, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.close()
+
+	// Push and pop items repeatedly, so the ring wraps around several times.
+	var next, popped int
+	for round := 0; round < 20; round++ {
+		for {
+			ok, err := r.push([]byte(fmt.Sprintf("item-%03d", next)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				break
+			}
+			next++
+		}
+		for i := 0; i < 3; i++ {
+			data, err := r.pop()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("item-%03d", popped); string(data) != want {
+				t.Fatalf("wrong item %q, want %q", data, want)
+			}
+			popped++
+		}
+	}
+	if r.len() != next-popped {
+		t.Fatalf("wrong length %d, want %d", r.len(), next-popped)
+	}
+}
+
+func TestClientSubscriptionSpill(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	dir := t.TempDir()
+	client := dialInProcWithOptions(server, WithSubscriptionSpill(dir, 1<<20))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	count := maxClientSubscriptionBuffer + 5000
+	nc := make(chan int)
+	sub, err := client.Subscribe(ctx, "nftest", nc, "someSubscription", count, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+
+	// Wait for the spill file to appear before consuming notifications.
+	for {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) > 0 {
+			break
+		}
+		select {
+		case err := <-sub.Err():
+			t.Fatal("subscription error:", err)
+		case <-ctx.Done():
+			t.Fatal("spill file not created")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case val := <-nc:
+			if val != i {
+				t.Fatalf("(%d/%d) unexpected value %d", i, count, val)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("(%d/%d) subscription error: %v", i, count, err)
+		case <-ctx.Done():
+			t.Fatalf("(%d/%d) timeout", i, count)
+		}
+	}
+
+	// The spill file is removed when the subscription ends.
+	sub.Unsubscribe()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("spill file not removed: %v", entries)
+	}
+}
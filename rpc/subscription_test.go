@@ -20,11 +20,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -282,3 +285,376 @@ func TestNotify(t *testing.T) {
 		t.Errorf("have:\n%v\nwant:\n%v\n", have, want)
 	}
 }
+
+func TestNotificationOrdering(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	server.SetNotificationOrdering(true)
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	const count = 50
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var (
+		ch1   = make(chan SequencedNotification, count)
+		ch2   = make(chan SequencedNotification, count)
+		plain = make(chan int, count)
+	)
+	for _, ch := range []any{ch1, ch2, plain} {
+		sub, err := client.Subscribe(ctx, "nftest", ch, "someSubscription", count, 0)
+		if err != nil {
+			t.Fatal("can't subscribe:", err)
+		}
+		defer sub.Unsubscribe()
+	}
+
+	// Sequence numbers must increase within each subscription and be unique across them.
+	seen := make(map[uint64]bool)
+	for _, ch := range []chan SequencedNotification{ch1, ch2} {
+		var last uint64
+		for i := 0; i < count; i++ {
+			var n SequencedNotification
+			select {
+			case n = <-ch:
+			case <-ctx.Done():
+				t.Fatal("timeout")
+			}
+			if n.Seq <= last {
+				t.Fatalf("notification %d: seq %d not greater than %d", i, n.Seq, last)
+			}
+			if seen[n.Seq] {
+				t.Fatalf("notification %d: duplicate seq %d", i, n.Seq)
+			}
+			if string(n.Result) != fmt.Sprint(i) {
+				t.Fatalf("notification %d: wrong result %s", i, n.Result)
+			}
+			last, seen[n.Seq] = n.Seq, true
+		}
+	}
+	// Plain subscriptions are unaffected.
+	for i := 0; i < count; i++ {
+		if v := <-plain; v != i {
+			t.Fatalf("wrong value %d, want %d", v, i)
+		}
+	}
+	if len(seen) != 2*count {
+		t.Fatalf("got %d distinct sequence numbers", len(seen))
+	}
+}
+
+func TestClientSubscriptionEvents(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Stopping early unsubscribes.
+	sub, err := client.Subscribe(ctx, "nftest", make(chan int), "someSubscription", 10, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	var got []int
+	for v, err := range sub.Events() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v.(int))
+		if len(got) == 3 {
+			break
+		}
+	}
+	if fmt.Sprint(got) != "[0 1 2]" {
+		t.Fatalf("wrong values %v", got)
+	}
+	select {
+	case _, ok := <-sub.Err():
+		if ok {
+			t.Fatal("error channel not closed after break")
+		}
+	case <-ctx.Done():
+		t.Fatal("not unsubscribed")
+	}
+
+	// Errors end the iteration.
+	sub, err = client.Subscribe(ctx, "nftest", make(chan int), "someSubscription", 2, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	var count int
+	for _, err := range sub.Events() {
+		if err != nil {
+			if !errors.Is(err, ErrSubscriptionQueueOverflow) {
+				t.Fatalf("wrong error %v", err)
+			}
+			break
+		}
+		if count++; count == 2 {
+			// Inject an error by failing the forwarding loop.
+			sub.quit <- ErrSubscriptionQueueOverflow
+		}
+	}
+	if count != 2 {
+		t.Fatalf("got %d values", count)
+	}
+}
+
+func TestClientSubscribeWithHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The handler is slower than the server, but no notifications are lost.
+	const count = 100
+	values := make(chan int, count)
+	handler := func(ctx context.Context, msg json.RawMessage) error {
+		var v int
+		if err := json.Unmarshal(msg, &v); err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+		values <- v
+		return nil
+	}
+	sub, err := client.SubscribeWithHandler(ctx, "nftest", handler, "someSubscription", count, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case v := <-values:
+			if v != i {
+				t.Fatalf("wrong value %d, want %d", v, i)
+			}
+		case err := <-sub.Err():
+			t.Fatal("subscription error:", err)
+		case <-ctx.Done():
+			t.Fatal("timeout")
+		}
+	}
+	sub.Unsubscribe()
+
+	// Handler errors end the subscription.
+	handlerErr := errors.New("handler failed")
+	sub, err = client.SubscribeWithHandler(ctx, "nftest", func(ctx context.Context, msg json.RawMessage) error {
+		return handlerErr
+	}, "someSubscription", 1, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	select {
+	case err := <-sub.Err():
+		if err != handlerErr {
+			t.Fatalf("wrong error %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timeout")
+	}
+
+	// Unsubscribe cancels the handler context.
+	started := make(chan struct{})
+	sub, err = client.SubscribeWithHandler(ctx, "nftest", func(ctx context.Context, msg json.RawMessage) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, "someSubscription", 1, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	<-started
+	sub.Unsubscribe()
+	if err, ok := <-sub.Err(); ok {
+		t.Fatalf("unexpected error %v after Unsubscribe", err)
+	}
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	test := func(sum bool, want []int) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ch := make(chan int, 10)
+		sub, err := client.Subscribe(ctx, "nftest", ch, "rateLimitedSubscription", 10, sum)
+		if err != nil {
+			t.Fatal("can't subscribe:", err)
+		}
+		defer sub.Unsubscribe()
+
+		var got []int
+		for len(got) < len(want) {
+			select {
+			case v := <-ch:
+				got = append(got, v)
+			case <-ctx.Done():
+				t.Fatalf("sum=%t: timeout, got %v", sum, got)
+			}
+		}
+		// Ensure nothing else arrives.
+		select {
+		case v := <-ch:
+			got = append(got, v)
+		case <-time.After(300 * time.Millisecond):
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("sum=%t: got %v, want %v", sum, got, want)
+		}
+	}
+	test(false, []int{1})
+	test(true, []int{1, 54})
+}
+
+func TestSubscriptionOwner(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		owner = "alice"
+	)
+	setOwner := func(o string) {
+		mu.Lock()
+		owner = o
+		mu.Unlock()
+	}
+	server := newTestServer()
+	server.SetSubscriptionOwner(func(ctx context.Context) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return owner
+	})
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	sub, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+
+	// Another identity can't unsubscribe.
+	setOwner("mallory")
+	var ok bool
+	err = client.Call(&ok, "nftest_unsubscribe", sub.subid)
+	if err == nil || err.Error() != ErrSubscriptionNotFound.Error() {
+		t.Fatalf("wrong error for foreign unsubscribe: %v", err)
+	}
+
+	// The owner can.
+	setOwner("alice")
+	if err := client.Call(&ok, "nftest_unsubscribe", sub.subid); err != nil || !ok {
+		t.Fatalf("owner can't unsubscribe: %v", err)
+	}
+}
+
+// closeTrackingService has a subscription which reports how it ended.
+type closeTrackingService struct {
+	closed   chan error // receives the OnClose argument
+	notified chan error // receives the result of Notify after close
+}
+
+func (s *closeTrackingService) Track(ctx context.Context) (*Subscription, error) {
+	notifier, _ := NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	notifier.OnClose(func(err error) {
+		s.closed <- err
+		s.notified <- notifier.Notify(sub.ID, 1)
+	})
+	return sub, nil
+}
+
+func TestNotifierClosed(t *testing.T) {
+	t.Parallel()
+
+	service := &closeTrackingService{closed: make(chan error, 1), notified: make(chan error, 1)}
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("close", service); err != nil {
+		t.Fatal(err)
+	}
+	check := func(wantNil bool) {
+		t.Helper()
+		select {
+		case err := <-service.closed:
+			if (err == nil) != wantNil {
+				t.Errorf("wrong close reason: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("OnClose callback not called")
+		}
+		if err := <-service.notified; err != ErrSubscriptionClosed {
+			t.Errorf("wrong Notify error after close: %v", err)
+		}
+	}
+
+	// Unsubscribe.
+	client := DialInProc(server)
+	sub, err := client.Subscribe(context.Background(), "close", make(chan int), "track")
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	sub.Unsubscribe()
+	check(true)
+
+	// Disconnect.
+	if _, err := client.Subscribe(context.Background(), "close", make(chan int), "track"); err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	client.Close()
+	check(false)
+}
+
+func TestServerIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetIDGenerator(SequentialIDGenerator())
+	ids := make(chan ID, 2)
+	server.SetMiddlewares([]Middleware{func(ctx context.Context, method string, args []reflect.Value, next func(context.Context, string, []reflect.Value) *MethodResult) *MethodResult {
+		result := next(ctx, method, args)
+		if id, ok := SubscriptionIDFromContext(ctx); ok {
+			ids <- id
+		}
+		return result
+	}})
+	client := DialInProc(server)
+	defer client.Close()
+
+	for _, want := range []ID{"0x1", "0x2"} {
+		sub, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sub.ID() != want {
+			t.Fatalf("wrong subscription id %q, want %q", sub.ID(), want)
+		}
+		if id := <-ids; id != want {
+			t.Fatalf("wrong id in middleware %q, want %q", id, want)
+		}
+		sub.Unsubscribe()
+	}
+
+	// Ids which look like they were issued by another server instance are not found.
+	err := client.Call(nil, "nftest_unsubscribe", ID("0x"+strings.Repeat("1", 32)))
+	if err == nil || err.Error() != ErrSubscriptionNotFound.Error() {
+		t.Fatalf("wrong error for unknown id: %v", err)
+	}
+}
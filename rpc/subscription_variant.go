@@ -0,0 +1,88 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// VariantHandler dispatches the notifications of subscriptions which send values of
+// different shapes, such as eth_subscribe("syncing"), which notifies either a status
+// object or false. Register a callback for each variant type with OnVariant, then pass
+// Handle to Client.SubscribeWithHandler:
+//
+//	var h rpc.VariantHandler
+//	rpc.OnVariant(&h, func(ctx context.Context, status SyncStatus) error { ... })
+//	rpc.OnVariant(&h, func(ctx context.Context, syncing bool) error { ... })
+//	sub, err := client.SubscribeWithHandler(ctx, "eth", h.Handle, "syncing")
+//
+// Variants are tried in the order they were registered, following the rules of OneOf2:
+// the first type which decodes the notification without error is chosen, and objects
+// are decoded strictly. On the server side, such notifications can be sent as OneOf2 or
+// OneOf3 values.
+type VariantHandler struct {
+	variants []variantCase
+	unknown  func(context.Context, json.RawMessage) error
+}
+
+type variantCase struct {
+	typ    reflect.Type
+	handle func(ctx context.Context, raw json.RawMessage) (matched bool, err error)
+}
+
+// OnVariant registers fn as the callback for notifications which decode as T.
+func OnVariant[T any](h *VariantHandler, fn func(context.Context, T) error) {
+	h.variants = append(h.variants, variantCase{
+		typ: reflect.TypeFor[T](),
+		handle: func(ctx context.Context, raw json.RawMessage) (bool, error) {
+			var v T
+			if err := decodeStrict(raw, &v); err != nil {
+				return false, nil
+			}
+			return true, fn(ctx, v)
+		},
+	})
+}
+
+// OnUnknown sets the callback for notifications which match no variant, including
+// null. Without it, such notifications end the subscription with an error.
+func (h *VariantHandler) OnUnknown(fn func(context.Context, json.RawMessage) error) {
+	h.unknown = fn
+}
+
+// Handle dispatches a notification to the callback of its variant.
+func (h *VariantHandler) Handle(ctx context.Context, raw json.RawMessage) error {
+	if !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		for _, v := range h.variants {
+			if matched, err := v.handle(ctx, raw); matched {
+				return err
+			}
+		}
+	}
+	if h.unknown != nil {
+		return h.unknown(ctx, raw)
+	}
+	names := make([]string, len(h.variants))
+	for i, v := range h.variants {
+		names[i] = v.typ.String()
+	}
+	return fmt.Errorf("notification %s does not match any of %v", raw, names)
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type syncStatusTest struct {
+	Current uint64 `json:"current"`
+	Highest uint64 `json:"highest"`
+}
+
+type syncingTestService struct{}
+
+// Syncing sends two status objects followed by false.
+func (syncingTestService) Syncing(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		var v OneOf2[syncStatusTest, bool]
+		for i := uint64(1); i <= 2; i++ {
+			v.SetA(syncStatusTest{Current: i, Highest: 2})
+			notifier.Notify(sub.ID, v)
+		}
+		v.SetB(false)
+		notifier.Notify(sub.ID, v)
+	}()
+	return sub, nil
+}
+
+func TestVariantHandlerSubscription(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", syncingTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	events := make(chan string, 3)
+	var h VariantHandler
+	OnVariant(&h, func(ctx context.Context, s syncStatusTest) error {
+		events <- "status"
+		return nil
+	})
+	OnVariant(&h, func(ctx context.Context, syncing bool) error {
+		if syncing {
+			events <- "true"
+		} else {
+			events <- "false"
+		}
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sub, err := client.SubscribeWithHandler(ctx, "eth", h.Handle, "syncing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	for _, want := range []string{"status", "status", "false"} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("got %s notification, want %s", got, want)
+			}
+		case err := <-sub.Err():
+			t.Fatal("subscription error:", err)
+		case <-ctx.Done():
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func TestVariantHandlerUnknown(t *testing.T) {
+	t.Parallel()
+
+	var h VariantHandler
+	OnVariant(&h, func(ctx context.Context, s syncStatusTest) error { return nil })
+	ctx := context.Background()
+
+	if err := h.Handle(ctx, json.RawMessage(`{"current":1,"extra":2}`)); err == nil || !strings.Contains(err.Error(), "syncStatusTest") {
+		t.Fatalf("expected no-match error, got %v", err)
+	}
+	if err := h.Handle(ctx, json.RawMessage(`null`)); err == nil {
+		t.Fatal("null matched a variant")
+	}
+	var unknown []string
+	h.OnUnknown(func(ctx context.Context, raw json.RawMessage) error {
+		unknown = append(unknown, string(raw))
+		return nil
+	})
+	if err := h.Handle(ctx, json.RawMessage(`"text"`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(unknown) != 1 || unknown[0] != `"text"` {
+		t.Fatalf("wrong unknown notifications %v", unknown)
+	}
+}
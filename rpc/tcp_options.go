@@ -0,0 +1,102 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// TCPOptions configures the TCP connections of HTTP and websocket clients, see
+// WithTCPOptions.
+type TCPOptions struct {
+	// KeepAlive configures keep-alive probes. When KeepAlive.Enable is false, probes are
+	// sent with the defaults of package net. Set the probe timing below the idle timeout
+	// of NAT devices on the path to keep long-lived subscription connections open.
+	KeepAlive net.KeepAliveConfig
+
+	// DisableNoDelay enables Nagle's algorithm by clearing TCP_NODELAY. This reduces
+	// the number of packets sent for many small requests, at the cost of latency.
+	DisableNoDelay bool
+
+	// UserTimeout sets TCP_USER_TIMEOUT, the maximum time that sent data may remain
+	// unacknowledged before the connection is closed. It makes dead connections fail
+	// within a known time even while data is being written. This is only supported on
+	// Linux. Zero leaves the system default.
+	UserTimeout time.Duration
+}
+
+var errUserTimeoutUnsupported = errors.New("TCP user timeout is not supported on this platform")
+
+// WithTCPOptions configures the TCP connections opened by the client. The options
+// apply to HTTP and websocket connections, including connections to a SOCKS proxy and
+// each attempt of multi-address dialing. They can't be combined with WithHTTPClient or
+// WithWebsocketDialer.
+func WithTCPOptions(opts TCPOptions) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.tcpOptions = &opts
+	})
+}
+
+// dialTCP returns the function used to open TCP connections.
+func (cfg *clientConfig) dialTCP() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	opts := cfg.tcpOptions
+	if opts == nil {
+		var nd net.Dialer
+		return nd.DialContext
+	}
+	nd := &net.Dialer{KeepAliveConfig: opts.KeepAlive}
+	if opts.UserTimeout > 0 {
+		nd.Control = func(network, address string, c syscall.RawConn) error {
+			return setUserTimeout(c, opts.UserTimeout)
+		}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := nd.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if tc, ok := conn.(*net.TCPConn); ok && opts.DisableNoDelay {
+			tc.SetNoDelay(false)
+		}
+		return conn, nil
+	}
+}
+
+// setupTCPOptions installs an HTTP client and websocket dialer which apply the TCP
+// options. This is done by setupSOCKS, setupEyeballs or setupResolver if they are
+// enabled.
+func (cfg *clientConfig) setupTCPOptions() error {
+	if cfg.tcpOptions == nil {
+		return nil
+	}
+	if cfg.tcpOptions.UserTimeout > 0 && !userTimeoutSupported {
+		return errUserTimeoutUnsupported
+	}
+	if cfg.socksProxy != nil || cfg.eyeballsDelay != 0 || cfg.resolver != nil {
+		return nil
+	}
+	if cfg.httpClient != nil || cfg.wsDialer != nil {
+		return errors.New("TCP options can't be used with a custom HTTP client or websocket dialer")
+	}
+	cfg.setDialer(cfg.dialTCP(), http.ProxyFromEnvironment)
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build linux
+// +build linux
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTCPOptionsDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			defer c.Close()
+			c.Read(make([]byte, 1))
+		}
+	}()
+
+	cfg := &clientConfig{tcpOptions: &TCPOptions{
+		KeepAlive:      net.KeepAliveConfig{Enable: true, Idle: 20 * time.Second, Interval: 5 * time.Second, Count: 3},
+		DisableNoDelay: true,
+		UserTimeout:    7 * time.Second,
+	}}
+	conn, err := cfg.dialTCP()(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct{ level, opt, value int }{
+		"SO_KEEPALIVE":     {syscall.SOL_SOCKET, syscall.SO_KEEPALIVE, 1},
+		"TCP_KEEPIDLE":     {syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE, 20},
+		"TCP_KEEPINTVL":    {syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, 5},
+		"TCP_KEEPCNT":      {syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT, 3},
+		"TCP_NODELAY":      {syscall.IPPROTO_TCP, syscall.TCP_NODELAY, 0},
+		"TCP_USER_TIMEOUT": {syscall.IPPROTO_TCP, tcpUserTimeout, 7000},
+	}
+	raw.Control(func(fd uintptr) {
+		for name, opt := range want {
+			v, err := syscall.GetsockoptInt(int(fd), opt.level, opt.opt)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			} else if v != opt.value {
+				t.Errorf("%s = %d, want %d", name, v, opt.value)
+			}
+		}
+	})
+}
+
+func TestTCPOptionsClient(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	opts := TCPOptions{UserTimeout: 5 * time.Second}
+	client, err := DialOptions(context.Background(), wsURL, WithTCPOptions(opts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DialOptions(context.Background(), wsURL, WithTCPOptions(opts), WithHTTPClient(new(http.Client)))
+	if err == nil {
+		t.Fatal("TCP options accepted with custom HTTP client")
+	}
+}
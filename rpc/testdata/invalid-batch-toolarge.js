@@ -1,13 +1,20 @@
 // This file checks the behavior of the batch item limit code.
 // In tests, the batch item limit is set to 4. So to trigger the error,
-// all batches in this file have 5 elements.
+// all batches in this file have more than 4 elements.
 
-// For batches that do not contain any calls, a response message with "id" == null
-// is returned.
+// The first four calls are executed, the remaining call gets an error.
+--> [{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",3]},{"jsonrpc":"2.0","id":4,"method":"test_echo","params":["x",4]},{"jsonrpc":"2.0","id":5,"method":"test_echo","params":["x",5]}]
+<-- [{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}},{"jsonrpc":"2.0","id":2,"result":{"String":"x","Int":2,"Args":null}},{"jsonrpc":"2.0","id":3,"result":{"String":"x","Int":3,"Args":null}},{"jsonrpc":"2.0","id":4,"result":{"String":"x","Int":4,"Args":null}},{"jsonrpc":"2.0","id":5,"error":{"code":-32600,"message":"batch too large","data":{"limit":"items","max":4}}}]
 
---> [{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]}]
+// Notifications beyond the limit are dropped.
+--> [{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","id":6,"method":"test_echo","params":["x",6]}]
+<-- [{"jsonrpc":"2.0","id":2,"result":{"String":"x","Int":2,"Args":null}},{"jsonrpc":"2.0","id":6,"error":{"code":-32600,"message":"batch too large","data":{"limit":"items","max":4}}}]
+
+// Batches with more than twice the limit are rejected as a whole. For batches that do
+// not contain any calls, a response message with "id" == null is returned.
+--> [{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]}]
 <-- [{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch too large"}}]
 
 // For batches with at least one call, the call's "id" is used.
---> [{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]}]
+--> [{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]},{"jsonrpc":"2.0","method":"test_echo","params":["x",99]}]
 <-- [{"jsonrpc":"2.0","id":3,"error":{"code":-32600,"message":"batch too large"}}]
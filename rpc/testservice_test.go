@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"net"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +38,20 @@ func newTestServer() *Server {
 	return server
 }
 
+// dialInProcWithOptions is like DialInProc, but applies client options.
+func dialInProcWithOptions(server *Server, options ...ClientOption) *Client {
+	cfg := new(clientConfig)
+	for _, opt := range options {
+		opt.applyOption(cfg)
+	}
+	c, _ := newClient(context.Background(), cfg, func(context.Context) (ServerCodec, error) {
+		p1, p2 := net.Pipe()
+		go server.ServeCodec(NewCodec(p1), 0)
+		return NewCodec(p2), nil
+	})
+	return c
+}
+
 func sequentialIDGenerator() func() ID {
 	var (
 		mu      sync.Mutex
@@ -203,6 +218,30 @@ func (s *notificationTestService) SomeSubscription(ctx context.Context, n, val i
 	return subscription, nil
 }
 
+// RateLimitedSubscription sends the values 1..n with a rate limit of 10/s. If sum is set,
+// values exceeding the limit are summed up, otherwise they are dropped.
+func (s *notificationTestService) RateLimitedSubscription(ctx context.Context, n int, sum bool) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	var merge func(pending, data any) any
+	if sum {
+		merge = func(pending, data any) any {
+			if pending == nil {
+				return data
+			}
+			return pending.(int) + data.(int)
+		}
+	}
+	notifier.SetRateLimit(10, merge)
+	subscription := notifier.CreateSubscription()
+	for i := 1; i <= n; i++ {
+		notifier.Notify(subscription.ID, i)
+	}
+	return subscription, nil
+}
+
 // HangSubscription blocks on s.unblockHangSubscription before sending anything.
 func (s *notificationTestService) HangSubscription(ctx context.Context, val int) (*Subscription, error) {
 	notifier, supported := NotifierFromContext(ctx)
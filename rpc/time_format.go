@@ -0,0 +1,184 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFormat selects the JSON representation of time.Time and time.Duration parameters
+// and results.
+type TimeFormat int
+
+const (
+	// TimeFormatDefault uses the encoding/json representation: RFC 3339 strings for
+	// time.Time and integer nanoseconds for time.Duration.
+	TimeFormatDefault TimeFormat = iota
+
+	// TimeFormatRFC3339 represents time.Time as an RFC 3339 string and time.Duration
+	// as a Go duration string, e.g. "1m30s".
+	TimeFormatRFC3339
+
+	// TimeFormatUnix represents time.Time as integer unix seconds and time.Duration as
+	// a number of seconds.
+	TimeFormatUnix
+
+	// TimeFormatHex represents time.Time as unix seconds and time.Duration as seconds,
+	// both encoded as hexadecimal quantities, e.g. "0x5f5e100".
+	TimeFormatHex
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	errInvalidTime = errors.New("invalid time value")
+)
+
+// SetTimeFormat configures the representation of time.Time and time.Duration values
+// used as method parameters and results. Only top-level parameters and results (and
+// pointers to them) are converted, values nested in other types use their own JSON
+// encoding.
+//
+// When a format other than TimeFormatDefault is set, parameters are accepted in any of the
+// formats: strings starting with "0x" are decoded as hexadecimal seconds, other strings as
+// RFC 3339 times or Go durations, and numbers as seconds.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetTimeFormat(f TimeFormat) {
+	s.timeFormat = f
+}
+
+// isTimeType reports whether t is converted by time formats.
+func isTimeType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == timeType || t == durationType
+}
+
+// encodeResult converts time values to their representation in format f.
+func (f TimeFormat) encodeResult(v interface{}) interface{} {
+	if f == TimeFormatDefault {
+		return v
+	}
+	switch v := v.(type) {
+	case *time.Time:
+		if v != nil {
+			return f.encodeTime(*v)
+		}
+	case time.Time:
+		return f.encodeTime(v)
+	case *time.Duration:
+		if v != nil {
+			return f.encodeDuration(*v)
+		}
+	case time.Duration:
+		return f.encodeDuration(v)
+	}
+	return v
+}
+
+func (f TimeFormat) encodeTime(t time.Time) interface{} {
+	switch f {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatHex:
+		return "0x" + strconv.FormatUint(uint64(t.Unix()), 16)
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+func (f TimeFormat) encodeDuration(d time.Duration) interface{} {
+	switch f {
+	case TimeFormatUnix:
+		return d.Seconds()
+	case TimeFormatHex:
+		return "0x" + strconv.FormatUint(uint64(d/time.Second), 16)
+	default:
+		return d.String()
+	}
+}
+
+// decodeTimeParam decodes a time parameter. The argument must be a pointer to a value of
+// type time.Time, time.Duration or a pointer to these types.
+func decodeTimeParam(raw json.RawMessage, arg reflect.Value) error {
+	if string(raw) == "null" {
+		return nil
+	}
+	t := arg.Elem().Type()
+	if t.Kind() == reflect.Pointer {
+		arg.Elem().Set(reflect.New(t.Elem()))
+		arg = arg.Elem()
+		t = t.Elem()
+	}
+	var (
+		seconds float64 // used for numbers and hex values
+		text    string  // used for other strings
+	)
+	switch {
+	case len(raw) > 0 && raw[0] == '"':
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return err
+		}
+		if strings.HasPrefix(text, "0x") {
+			n, err := strconv.ParseUint(text[2:], 16, 63)
+			if err != nil {
+				return errInvalidTime
+			}
+			seconds, text = float64(n), ""
+		}
+	default:
+		if err := json.Unmarshal(raw, &seconds); err != nil {
+			return err
+		}
+	}
+
+	if t == timeType {
+		var v time.Time
+		if text != "" {
+			var err error
+			if v, err = time.Parse(time.RFC3339Nano, text); err != nil {
+				return err
+			}
+		} else {
+			sec, frac := math.Modf(seconds)
+			v = time.Unix(int64(sec), int64(frac*1e9))
+		}
+		arg.Elem().Set(reflect.ValueOf(v))
+		return nil
+	}
+	var v time.Duration
+	if text != "" {
+		var err error
+		if v, err = time.ParseDuration(text); err != nil {
+			return err
+		}
+	} else {
+		v = time.Duration(seconds * float64(time.Second))
+	}
+	arg.Elem().Set(reflect.ValueOf(v))
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type timeTestService struct{}
+
+func (timeTestService) Add(t time.Time, d time.Duration) time.Time {
+	return t.Add(d)
+}
+
+func (timeTestService) Double(d *time.Duration) *time.Duration {
+	if d == nil {
+		return nil
+	}
+	r := 2 * *d
+	return &r
+}
+
+func TestTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format     TimeFormat
+		time, dur  string // params
+		wantAdd    string
+		wantDouble string
+	}{
+		{TimeFormatDefault, `"2020-01-01T00:00:00Z"`, `1000000000`, `"2020-01-01T00:00:01Z"`, `2000000000`},
+		{TimeFormatRFC3339, `"2020-01-01T00:00:00Z"`, `"1m30s"`, `"2020-01-01T00:01:30Z"`, `"3m0s"`},
+		{TimeFormatUnix, `1577836800`, `1.5`, `1577836801`, `3`},
+		{TimeFormatHex, `"0x5e0be100"`, `"0x3c"`, `"0x5e0be13c"`, `"0x78"`},
+		// Other representations are accepted as parameters.
+		{TimeFormatUnix, `"2020-01-01T00:00:00Z"`, `"0x1"`, `1577836801`, `2`},
+		{TimeFormatHex, `1577836800`, `"1s"`, `"0x5e0be101"`, `"0x2"`},
+	}
+	for i, test := range tests {
+		server := NewServer()
+		server.SetTimeFormat(test.format)
+		server.RegisterName("time", timeTestService{})
+		client := DialInProc(server)
+
+		var res json.RawMessage
+		err := client.Call(&res, "time_add", json.RawMessage(test.time), json.RawMessage(test.dur))
+		if err != nil {
+			t.Errorf("test %d: add error: %v", i, err)
+		} else if string(res) != test.wantAdd {
+			t.Errorf("test %d: add result %s, want %s", i, res, test.wantAdd)
+		}
+		err = client.Call(&res, "time_double", json.RawMessage(test.dur))
+		if err != nil {
+			t.Errorf("test %d: double error: %v", i, err)
+		} else if string(res) != test.wantDouble {
+			t.Errorf("test %d: double result %s, want %s", i, res, test.wantDouble)
+		}
+		client.Close()
+		server.Stop()
+	}
+}
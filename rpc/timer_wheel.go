@@ -0,0 +1,262 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+const (
+	wheelTick   = time.Millisecond
+	wheelBits   = 8
+	wheelSlots  = 1 << wheelBits
+	wheelLevels = 4 // covers 2^32 ticks, about 49 days at the default tick
+	wheelMax    = 1<<(wheelBits*wheelLevels) - wheelSlots
+)
+
+// timerWheel is a hierarchical timing wheel implementing the timers of mclock.Clock on
+// top of a base clock. It is the default clock of the server, with the system clock as
+// its base.
+//
+// Servers arm a timer for every request with a timeout, and stop almost all of them
+// again when the call returns. Runtime timers make both operations comparatively costly
+// at high request rates. Timers on the wheel are inserted and removed in constant time
+// under a single lock, and a single goroutine advances the wheel while timers are
+// pending. Timers fire up to one tick late, but never early. Like with the system
+// clock, AfterFunc callbacks run on a goroutine of their own. Timers longer than the span
+// of the wheel are armed on the base clock instead.
+type timerWheel struct {
+	mclock.Clock // base clock: Now, Sleep and timers beyond wheelMax
+
+	tick  time.Duration
+	start mclock.AbsTime
+
+	mu      sync.Mutex
+	now     uint64 // ticks since start processed by the wheel
+	slots   [wheelLevels][wheelSlots]*wheelTimer
+	pending int
+	running bool // advance goroutine is active
+}
+
+// wheelTimer is a timer on the wheel. Pending timers are kept in doubly-linked lists,
+// one per slot.
+type wheelTimer struct {
+	w          *timerWheel
+	at         uint64
+	fn         func()              // set for AfterFunc
+	ch         chan mclock.AbsTime // set for NewTimer
+	prev, next *wheelTimer
+	slot       **wheelTimer // list containing the timer, nil when not pending
+	base       mclock.Timer // set while armed on the base clock
+}
+
+func newTimerWheel(clock mclock.Clock, tick time.Duration) *timerWheel {
+	return &timerWheel{Clock: clock, tick: tick, start: clock.Now()}
+}
+
+// AfterFunc runs fn on a new goroutine after d has elapsed.
+func (w *timerWheel) AfterFunc(d time.Duration, fn func()) mclock.Timer {
+	t := &wheelTimer{w: w, fn: fn}
+	w.schedule(t, d)
+	return t
+}
+
+// NewTimer creates a timer which can be rescheduled.
+func (w *timerWheel) NewTimer(d time.Duration) mclock.ChanTimer {
+	t := &wheelTimer{w: w, ch: make(chan mclock.AbsTime, 1)}
+	w.schedule(t, d)
+	return t
+}
+
+// After returns a channel which receives the current time after d has elapsed.
+func (w *timerWheel) After(d time.Duration) <-chan mclock.AbsTime {
+	return w.NewTimer(d).C()
+}
+
+// Stop cancels the timer. It returns false if the timer has already expired or been
+// stopped.
+func (t *wheelTimer) Stop() bool {
+	t.w.mu.Lock()
+	defer t.w.mu.Unlock()
+	return t.w.stop(t)
+}
+
+// Reset reschedules the timer. Like with the system clock, it should be invoked only on
+// stopped or expired timers with drained channels.
+func (t *wheelTimer) Reset(d time.Duration) {
+	t.w.mu.Lock()
+	t.w.stop(t)
+	t.w.mu.Unlock()
+	t.w.schedule(t, d)
+}
+
+// C returns the channel of a timer created by NewTimer.
+func (t *wheelTimer) C() <-chan mclock.AbsTime {
+	if t.ch == nil {
+		panic("rpc: C() on timer created by AfterFunc")
+	}
+	return t.ch
+}
+
+// fire runs the timer function or sends on the timer channel.
+func (t *wheelTimer) fire() {
+	if t.fn != nil {
+		go t.fn()
+		return
+	}
+	// This send is non-blocking, like for timers of the system clock.
+	select {
+	case t.ch <- t.w.Now():
+	default:
+	}
+}
+
+// elapsed returns the number of whole ticks since the wheel was created.
+func (w *timerWheel) elapsed() uint64 {
+	return uint64(w.Now().Sub(w.start) / w.tick)
+}
+
+func (w *timerWheel) schedule(t *wheelTimer, d time.Duration) {
+	if d <= 0 {
+		t.fire()
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if d/w.tick >= wheelMax-1 {
+		t.base = w.Clock.AfterFunc(d, t.fire)
+		return
+	}
+	now := w.elapsed()
+	if !w.running {
+		// The wheel is empty while it isn't running, so it can skip ahead.
+		w.now = now
+		w.running = true
+		go w.run()
+	}
+	// Round up, and account for the fraction of the current tick which has passed.
+	ticks := uint64((d+w.tick-1)/w.tick) + 1
+	t.at = now + ticks
+	w.insert(t)
+	w.pending++
+}
+
+// insert adds t to the slot for its expiry time. This assumes w.mu is held.
+func (w *timerWheel) insert(t *wheelTimer) {
+	level, delta := 0, t.at-w.now
+	for delta >= wheelSlots && level < wheelLevels-1 {
+		delta >>= wheelBits
+		level++
+	}
+	slot := &w.slots[level][(t.at>>(wheelBits*level))&(wheelSlots-1)]
+	t.prev, t.next, t.slot = nil, *slot, slot
+	if t.next != nil {
+		t.next.prev = t
+	}
+	*slot = t
+}
+
+// stop cancels t, reporting whether it was pending. This assumes w.mu is held.
+func (w *timerWheel) stop(t *wheelTimer) bool {
+	if t.base != nil {
+		base := t.base
+		t.base = nil
+		return base.Stop()
+	}
+	return w.remove(t)
+}
+
+// remove unlinks t from its slot, reporting whether it was pending. This assumes w.mu
+// is held.
+func (w *timerWheel) remove(t *wheelTimer) bool {
+	if t.slot == nil {
+		return false
+	}
+	if t.prev != nil {
+		t.prev.next = t.next
+	} else {
+		*t.slot = t.next
+	}
+	if t.next != nil {
+		t.next.prev = t.prev
+	}
+	t.prev, t.next, t.slot = nil, nil, nil
+	w.pending--
+	return true
+}
+
+// run advances the wheel until no timers are pending.
+func (w *timerWheel) run() {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+
+	var expired []*wheelTimer
+	for range ticker.C {
+		w.mu.Lock()
+		for target := w.elapsed(); w.now < target; {
+			w.now++
+			expired = w.advance(expired)
+		}
+		done := w.pending == 0
+		if done {
+			w.running = false
+		}
+		w.mu.Unlock()
+
+		for i, t := range expired {
+			t.fire()
+			expired[i] = nil
+		}
+		expired = expired[:0]
+		if done {
+			return
+		}
+	}
+}
+
+// advance processes the slots of tick w.now, appending expired timers to the given
+// slice. Timers of higher levels are moved down when their slot comes up. This assumes
+// w.mu is held.
+func (w *timerWheel) advance(expired []*wheelTimer) []*wheelTimer {
+	for level := 1; level < wheelLevels; level++ {
+		if w.now&(1<<(wheelBits*level)-1) != 0 {
+			break
+		}
+		slot := &w.slots[level][(w.now>>(wheelBits*level))&(wheelSlots-1)]
+		t := *slot
+		*slot = nil
+		for t != nil {
+			next := t.next
+			w.insert(t)
+			t = next
+		}
+	}
+	slot := &w.slots[0][w.now&(wheelSlots-1)]
+	for t := *slot; t != nil; {
+		next := t.next
+		if t.at <= w.now {
+			w.remove(t)
+			expired = append(expired, t)
+		}
+		t = next
+	}
+	return expired
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// TestTimerWheelCascade advances the wheel by hand and checks that timers on all levels
+// expire exactly at their tick.
+func TestTimerWheelCascade(t *testing.T) {
+	w := newTimerWheel(mclock.System{}, time.Hour) // elapsed stays zero
+	w.running = true                               // don't start the advance goroutine
+
+	durations := []time.Duration{1, 2, 255, 256, 257, 1000, 65535, 65536, 70000, 1 << 20, 1<<24 + 3}
+	timers := make(map[*wheelTimer]bool)
+	for _, d := range durations {
+		tm := w.AfterFunc(d*time.Hour, func() {}).(*wheelTimer)
+		timers[tm] = true
+	}
+	stopped := w.AfterFunc(500*time.Hour, func() {}).(*wheelTimer)
+	if !stopped.Stop() {
+		t.Fatal("Stop returned false for pending timer")
+	}
+	if stopped.Stop() {
+		t.Fatal("Stop returned true for stopped timer")
+	}
+
+	var expired []*wheelTimer
+	for len(timers) > 0 {
+		if w.now > 1<<25 {
+			t.Fatalf("%d timers did not expire", len(timers))
+		}
+		w.now++
+		expired = w.advance(expired[:0])
+		for _, tm := range expired {
+			if tm.at != w.now {
+				t.Fatalf("timer for tick %d expired at tick %d", tm.at, w.now)
+			}
+			if !timers[tm] {
+				t.Fatalf("timer for tick %d expired twice", tm.at)
+			}
+			delete(timers, tm)
+		}
+	}
+	if w.pending != 0 {
+		t.Fatalf("%d timers pending after all expired", w.pending)
+	}
+}
+
+// TestTimerWheelLong checks that timers longer than the span of the wheel are armed on
+// the base clock and don't fire early.
+func TestTimerWheelLong(t *testing.T) {
+	clock := new(mclock.Simulated)
+	w := newTimerWheel(clock, wheelTick)
+	d := wheelMax*wheelTick + time.Hour
+
+	fired := make(chan struct{}, 1)
+	w.AfterFunc(d, func() { fired <- struct{}{} })
+	timer := w.NewTimer(d)
+	stopped := w.AfterFunc(d, func() { t.Error("stopped timer fired") })
+	if !stopped.Stop() {
+		t.Fatal("Stop returned false for pending timer")
+	}
+	if w.pending != 0 || w.running {
+		t.Fatal("long timers kept on the wheel")
+	}
+
+	clock.Run(d - time.Millisecond)
+	select {
+	case <-fired:
+		t.Fatal("timer fired early")
+	case <-timer.C():
+		t.Fatal("channel timer fired early")
+	default:
+	}
+	clock.Run(time.Millisecond)
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("channel timer did not fire")
+	}
+	if timer.Stop() {
+		t.Fatal("Stop returned true for expired timer")
+	}
+}
+
+func TestTimerWheel(t *testing.T) {
+	t.Parallel()
+
+	w := newTimerWheel(mclock.System{}, wheelTick)
+	start := time.Now()
+	fired := make(chan time.Duration, 3)
+	for _, d := range []time.Duration{30 * time.Millisecond, 5 * time.Millisecond, 300 * time.Millisecond} {
+		w.AfterFunc(d, func() {
+			elapsed := time.Since(start)
+			if elapsed < d {
+				t.Errorf("timer for %v fired early after %v", d, elapsed)
+			}
+			fired <- d
+		})
+	}
+	stopped := w.AfterFunc(10*time.Millisecond, func() { t.Error("stopped timer fired") })
+	stopped.Stop()
+
+	var order []time.Duration
+	for range 3 {
+		select {
+		case d := <-fired:
+			order = append(order, d)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timers did not fire")
+		}
+	}
+	if order[0] != 5*time.Millisecond || order[2] != 300*time.Millisecond {
+		t.Errorf("timers fired in wrong order %v", order)
+	}
+
+	// Channel timers can be reset after they fired.
+	timer := w.NewTimer(time.Millisecond)
+	for range 2 {
+		select {
+		case <-timer.C():
+		case <-time.After(5 * time.Second):
+			t.Fatal("channel timer did not fire")
+		}
+		timer.Reset(2 * time.Millisecond)
+	}
+	timer.Stop()
+
+	// The advance goroutine exits when the wheel is empty.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		running := w.running
+		w.mu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("wheel still running without timers")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// These benchmarks measure the cost of arming and stopping a request timeout, which is
+// the common case for requests that finish in time.
+func BenchmarkTimerWheelAfterFunc(b *testing.B) {
+	benchmarkAfterFunc(b, newTimerWheel(mclock.System{}, wheelTick))
+}
+
+func BenchmarkSystemAfterFunc(b *testing.B) {
+	benchmarkAfterFunc(b, mclock.System{})
+}
+
+func benchmarkAfterFunc(b *testing.B, clock mclock.Clock) {
+	fn := func() {}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			clock.AfterFunc(10*time.Second, fn).Stop()
+		}
+	})
+}
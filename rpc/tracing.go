@@ -0,0 +1,150 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Headers of the W3C Trace Context specification.
+const (
+	TraceparentHeader = "Traceparent"
+	TracestateHeader  = "Tracestate"
+)
+
+// SpanContext identifies a span of a distributed trace, as carried in the W3C
+// Traceparent and Tracestate headers.
+//
+// The server extracts the span context of HTTP requests and WebSocket connections and
+// adds it to the context of method calls, see SpanContextFromContext. Clients send the
+// span context of the call context in the Traceparent header of HTTP requests, so it is
+// forwarded by services which make calls using the context of their method call.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	TraceState string
+}
+
+// IsValid reports whether the trace and span id are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Traceparent returns the Traceparent header value of the span context.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + hex.EncodeToString([]byte{sc.TraceFlags})
+}
+
+// ParseTraceparent parses a Traceparent header value.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	// version-traceid-spanid-flags. Later versions may append fields.
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' || (len(s) > 55 && s[55] != '-') {
+		return sc, errors.New("malformed traceparent")
+	}
+	version, err := hex.DecodeString(s[:2])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(s) != 55) {
+		return sc, errors.New("invalid traceparent version")
+	}
+	if strings.ToLower(s[:55]) != s[:55] {
+		return sc, errors.New("traceparent must be lowercase")
+	}
+	flags := make([]byte, 1)
+	if _, err := hex.Decode(sc.TraceID[:], []byte(s[3:35])); err != nil {
+		return sc, errors.New("invalid trace id")
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(s[36:52])); err != nil {
+		return sc, errors.New("invalid span id")
+	}
+	if _, err := hex.Decode(flags, []byte(s[53:55])); err != nil {
+		return sc, errors.New("invalid trace flags")
+	}
+	sc.TraceFlags = flags[0]
+	if !sc.IsValid() {
+		return sc, errors.New("zero trace or span id")
+	}
+	return sc, nil
+}
+
+// spanContextFromHeader extracts the span context from HTTP headers.
+func spanContextFromHeader(h http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(h.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = strings.Join(h.Values(TracestateHeader), ",")
+	return sc, true
+}
+
+type spanContextKey struct{}
+
+// NewContextWithSpanContext returns a new context carrying the given span context.
+func NewContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of ctx.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Tracer creates spans for method calls. It connects the server to a tracing system
+// such as OpenTelemetry: an implementation typically starts a span whose remote parent
+// is given by SpanContextFromContext, and returns a context holding the new span so that
+// it is visible to middlewares and the method handler.
+//
+// The server starts a span named after the method for every call, and a span named
+// "rpc.batch" for every batch request. The spans of calls in a batch are children of the
+// batch span. Implementations must be safe for concurrent use.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SpanContext returns the identity of the span. It is added to the context of the
+	// call, so calls made by the method handler are traced as children of the span.
+	SpanContext() SpanContext
+
+	// End finishes the span. The error is the error returned by the method, if any.
+	End(err error)
+}
+
+// SetTracer installs a tracer which creates spans for method calls. Passing nil removes
+// it.
+func (s *Server) SetTracer(t Tracer) {
+	s.services.updateConfig(func(c *registryConfig) { c.tracer = t })
+}
+
+// startSpan starts a span using the configured tracer. It returns a nil span if tracing
+// is disabled.
+func (r *registryConfig) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if r.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := r.tracer.StartSpan(ctx, name)
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = NewContextWithSpanContext(ctx, sc)
+	}
+	return ctx, span
+}
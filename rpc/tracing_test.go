@@ -0,0 +1,184 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	t.Parallel()
+
+	valid := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceFlags != 1 || sc.SpanID[7] != 0xb7 {
+		t.Errorf("wrong span context %+v", sc)
+	}
+	if s := sc.Traceparent(); s != valid {
+		t.Errorf("wrong encoding %q", s)
+	}
+	// Future versions may add fields.
+	if _, err := ParseTraceparent("01" + valid[2:] + "-extra"); err != nil {
+		t.Errorf("future version rejected: %v", err)
+	}
+	for _, s := range []string{
+		"",
+		valid + "-extra",
+		"ff" + valid[2:],
+		strings.ToUpper(valid),
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(s); err == nil {
+			t.Errorf("no error for %q", s)
+		}
+	}
+}
+
+type traceService struct{}
+
+// Current returns the traceparent of the call context.
+func (traceService) Current(ctx context.Context) string {
+	sc, _ := SpanContextFromContext(ctx)
+	return sc.Traceparent()
+}
+
+type recordedSpan struct {
+	name   string
+	parent string // traceparent of the context passed to StartSpan
+	sc     SpanContext
+	ended  bool
+	err    error
+}
+
+// recordingTracer creates spans with sequential span ids.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (tr *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	parent, _ := SpanContextFromContext(ctx)
+	span := &recordedSpan{name: name, parent: parent.Traceparent(), sc: parent}
+	span.sc.SpanID = [8]byte{7: byte(len(tr.spans) + 1)}
+	tr.spans = append(tr.spans, span)
+	return ctx, &tracedSpan{tr, span}
+}
+
+type tracedSpan struct {
+	tr   *recordingTracer
+	span *recordedSpan
+}
+
+func (s *tracedSpan) SpanContext() SpanContext { return s.span.sc }
+
+func (s *tracedSpan) End(err error) {
+	s.tr.mu.Lock()
+	defer s.tr.mu.Unlock()
+	s.span.ended, s.span.err = true, err
+}
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("trace", traceService{})
+	tracer := new(recordingTracer)
+	server.SetTracer(tracer)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := NewContextWithSpanContext(context.Background(), remote)
+	var current [2]string
+	batch := []BatchElem{
+		{Method: "trace_current", Result: &current[0]},
+		{Method: "test_returnError"},
+	}
+	if err := client.BatchCallContext(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.spans) != 3 {
+		t.Fatalf("wrong number of spans: %d", len(tracer.spans))
+	}
+	batchSpan, call1, call2 := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if batchSpan.name != "rpc.batch" || batchSpan.parent != remote.Traceparent() {
+		t.Errorf("wrong batch span %+v", batchSpan)
+	}
+	for _, span := range []*recordedSpan{call1, call2} {
+		if span.parent != batchSpan.sc.Traceparent() {
+			t.Errorf("span %s has wrong parent %s", span.name, span.parent)
+		}
+		if !span.ended {
+			t.Errorf("span %s not ended", span.name)
+		}
+	}
+	if call1.name != "trace_current" || call2.name != "test_returnError" {
+		t.Errorf("wrong span names %q, %q", call1.name, call2.name)
+	}
+	if !errors.Is(call2.err, testError{}) {
+		t.Errorf("wrong span error %v", call2.err)
+	}
+	// The method sees its own span.
+	if current[0] != call1.sc.Traceparent() {
+		t.Errorf("method saw span %s, want %s", current[0], call1.sc.Traceparent())
+	}
+}
+
+func TestTracingWebsocket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.RegisterName("trace", traceService{})
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	client, err := DialOptions(context.Background(), "ws"+strings.TrimPrefix(httpsrv.URL, "http"), WithHeader(TraceparentHeader, traceparent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var current string
+	if err := client.Call(&current, "trace_current"); err != nil {
+		t.Fatal(err)
+	}
+	if current != traceparent {
+		t.Errorf("wrong span context %q", current)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TruncationPolicy limits the array results of a method returned to clients which
+// accept partial results, see Server.SetTruncationPolicies. Zero fields impose no limit.
+type TruncationPolicy struct {
+	MaxItems int // maximum number of items per response
+	MaxBytes int // maximum size of the encoded result
+}
+
+// SetTruncationPolicies configures truncation of array results by method name. When a
+// client asks for partial results (see Client.CallPage), results exceeding the policy of
+// the method are cut after the last item that fits. The response then carries a
+// "truncated" flag and a continuation cursor in its metadata. Calling the method again
+// with the same parameters and the cursor returns the next items. At least one item is
+// returned per response, even if it exceeds MaxBytes.
+//
+// The method runs again for every page, so truncation should only be configured for
+// methods which return the same items when called repeatedly with the same parameters,
+// e.g. logs of a fixed block range. Clients which don't ask for partial results always
+// receive the complete result. Passing nil removes all policies.
+func (s *Server) SetTruncationPolicies(policies map[string]TruncationPolicy) {
+	cpy := make(map[string]TruncationPolicy, len(policies))
+	for method, p := range policies {
+		cpy[method] = p
+	}
+	if len(cpy) == 0 {
+		cpy = nil
+	}
+	s.services.updateConfig(func(c *registryConfig) { c.truncation = cpy })
+}
+
+// pageRequest is the position of a call for partial results.
+type pageRequest struct {
+	policy TruncationPolicy
+	offset int
+	digest string
+}
+
+// wantsPage reports whether the request asks for partial results.
+func (ext requestExt) wantsPage() bool {
+	return ext.Truncate || ext.Cursor != ""
+}
+
+// pageRequest returns the page requested by msg. It returns nil if the method has no
+// truncation policy or the client did not ask for partial results.
+func (cfg *registryConfig) pageRequest(msg *jsonrpcMessage) (*pageRequest, error) {
+	policy, ok := cfg.truncation[msg.Method]
+	if !ok || len(msg.Ext) == 0 {
+		return nil, nil
+	}
+	ext := msg.requestExt()
+	if !ext.wantsPage() {
+		return nil, nil
+	}
+	p := &pageRequest{policy: policy, digest: pageDigest(msg.Method, msg.Params)}
+	if ext.Cursor != "" {
+		offset, digest, ok := strings.Cut(ext.Cursor, ".")
+		n, err := strconv.Atoi(offset)
+		if !ok || err != nil || n < 0 || digest != p.digest {
+			return nil, &invalidParamsError{"invalid continuation cursor"}
+		}
+		p.offset = n
+	}
+	return p, nil
+}
+
+// pageDigest identifies the call a cursor belongs to.
+func pageDigest(method string, params json.RawMessage) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write(params)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// apply cuts the result of resp to the requested page. Results which are not arrays
+// are returned unchanged.
+func (p *pageRequest) apply(resp *jsonrpcMessage) *jsonrpcMessage {
+	if resp.Error != nil {
+		return resp
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(resp.Result, &items); err != nil {
+		return resp
+	}
+	items = items[min(p.offset, len(items)):]
+	n, size := 0, 2
+	for n < len(items) {
+		if p.policy.MaxItems > 0 && n == p.policy.MaxItems {
+			break
+		}
+		itemSize := len(items[n])
+		if n > 0 {
+			itemSize++ // comma
+		}
+		if p.policy.MaxBytes > 0 && n > 0 && size+itemSize > p.policy.MaxBytes {
+			break
+		}
+		size += itemSize
+		n++
+	}
+	if p.offset == 0 && n == len(items) {
+		return resp // complete result fits
+	}
+	var buf bytes.Buffer
+	buf.Grow(size)
+	buf.WriteByte('[')
+	for i, item := range items[:n] {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(item)
+	}
+	buf.WriteByte(']')
+	resp.Result = buf.Bytes()
+	if n < len(items) {
+		cursor := fmt.Sprintf("%d.%s", p.offset+n, p.digest)
+		resp.updateResponseExt(func(ext *responseExt) {
+			ext.Truncated = true
+			ext.Cursor = cursor
+		})
+	}
+	return resp
+}
+
+// CallPage performs a call which accepts a partial result. Servers configured to
+// truncate the array results of the method (see Server.SetTruncationPolicies) return
+// the first items of the result and a continuation cursor. Pass the cursor to the next
+// call with the same method and arguments to receive the following items, starting
+// with an empty cursor. The returned cursor is empty when the last item has been
+// received. Servers without a truncation policy for the method return the complete
+// result.
+//
+// Partial results are not stored in the client's response cache.
+func (c *Client) CallPage(ctx context.Context, result interface{}, cursor string, method string, args ...interface{}) (next string, err error) {
+	if result != nil && reflect.TypeOf(result).Kind() != reflect.Ptr {
+		return "", fmt.Errorf("call result parameter must be pointer or nil interface: %v", result)
+	}
+	call := func() error {
+		msg, err := c.newMessage(method, args...)
+		if err != nil {
+			return err
+		}
+		ext := msg.requestExt()
+		ext.Truncate, ext.Cursor = true, cursor
+		msg.Ext, _ = json.Marshal(ext)
+		resp, err := c.callMessage(ctx, result, msg)
+		if resp != nil {
+			next = resp.responseExt().Cursor
+		}
+		return err
+	}
+	if c.retry != nil {
+		err = c.retry.do(ctx, c, method, call)
+	} else {
+		err = call()
+	}
+	return next, err
+}
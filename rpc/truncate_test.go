@@ -0,0 +1,133 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type rangeService struct{}
+
+func (rangeService) Range(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+func (rangeService) Count(n int) int { return n }
+
+func TestTruncationPolicy(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("items", rangeService{}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetTruncationPolicies(map[string]TruncationPolicy{
+		"items_range": {MaxItems: 4},
+		"items_count": {MaxItems: 4},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+	ctx := context.Background()
+
+	// Clients which don't ask for partial results receive everything.
+	var full []int
+	if err := client.Call(&full, "items_range", 10); err != nil {
+		t.Fatal(err)
+	}
+	if len(full) != 10 {
+		t.Fatalf("wrong result length %d, want 10", len(full))
+	}
+
+	// Page through the result.
+	var (
+		all    []int
+		cursor string
+		pages  int
+	)
+	for {
+		var page []int
+		next, err := client.CallPage(ctx, &page, cursor, "items_range", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) > 4 {
+			t.Fatalf("page %d has %d items", pages, len(page))
+		}
+		all = append(all, page...)
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if pages != 3 || !slices.Equal(all, full) {
+		t.Fatalf("wrong pages: %d pages, items %v", pages, all)
+	}
+
+	// Cursors are bound to the parameters of the call.
+	var page []int
+	_, err := client.CallPage(ctx, &page, cursor, "items_range", 11)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32602 {
+		t.Fatalf("wrong error for mismatched cursor: %v", err)
+	}
+
+	// Results which are not arrays are not truncated.
+	var count int
+	next, err := client.CallPage(ctx, &count, "", "items_count", 10)
+	if err != nil || count != 10 || next != "" {
+		t.Fatalf("wrong non-array result %d, cursor %q, err %v", count, next, err)
+	}
+}
+
+func TestTruncationPolicyMaxBytes(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("items", rangeService{}); err != nil {
+		t.Fatal(err)
+	}
+	// "[0,1,2,3]" is 9 bytes.
+	server.SetTruncationPolicies(map[string]TruncationPolicy{"items_range": {MaxBytes: 9}})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var page []int
+	next, err := client.CallPage(context.Background(), &page, "", "items_range", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(page, []int{0, 1, 2, 3}) || next == "" {
+		t.Fatalf("wrong first page %v, cursor %q", page, next)
+	}
+	next, err = client.CallPage(context.Background(), &page, next, "items_range", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(page, []int{4, 5}) || next != "" {
+		t.Fatalf("wrong last page %v, cursor %q", page, next)
+	}
+}
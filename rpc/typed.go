@@ -0,0 +1,78 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// CallTyped performs a JSON-RPC call and returns its result as a value of type T. It is
+// equivalent to calling CallContext with a pointer to a T:
+//
+//	block, err := rpc.CallTyped[*types.Header](ctx, client, "eth_getBlockByNumber", "latest", false)
+func CallTyped[T any](ctx context.Context, c *Client, method string, args ...interface{}) (T, error) {
+	var result T
+	err := c.CallContext(ctx, &result, method, args...)
+	return result, err
+}
+
+// SubscribeTyped registers a subscription under the given namespace like
+// Client.Subscribe, delivering notifications as values of type T on ch. Unlike
+// Subscribe, the type of the channel is checked at compile time. Notifications are
+// decoded by a decoder shared by all typed subscriptions of T, without reflection on
+// every notification.
+//
+//	heads := make(chan *types.Header)
+//	sub, err := rpc.SubscribeTyped(ctx, client, "eth", heads, "newHeads")
+func SubscribeTyped[T any](ctx context.Context, c *Client, namespace string, ch chan<- T, args ...interface{}) (*ClientSubscription, error) {
+	if ch == nil {
+		panic("channel given to SubscribeTyped must not be nil")
+	}
+	if c.isHTTP {
+		return nil, ErrNotificationsUnsupported
+	}
+	decode := typedDecoder[T]()
+	return c.interceptSubscribe(ctx, namespace, args, func(namespace string) *ClientSubscription {
+		sub := newClientSubscription(c, namespace, reflect.ValueOf(ch))
+		sub.decode = decode
+		return sub
+	})
+}
+
+// notificationDecoder decodes a notification of a subscription. The client is used
+// for its decoding options.
+type notificationDecoder func(c *Client, method string, raw json.RawMessage) (interface{}, error)
+
+// typedDecoders holds the notification decoders of typed subscriptions, by type.
+var typedDecoders sync.Map // reflect.Type -> notificationDecoder
+
+// typedDecoder returns the notification decoder for values of type T.
+func typedDecoder[T any]() notificationDecoder {
+	typ := reflect.TypeFor[T]()
+	if dec, ok := typedDecoders.Load(typ); ok {
+		return dec.(notificationDecoder)
+	}
+	dec, _ := typedDecoders.LoadOrStore(typ, notificationDecoder(func(c *Client, method string, raw json.RawMessage) (interface{}, error) {
+		var v T
+		err := c.decodeResult(method, raw, &v)
+		return v, err
+	}))
+	return dec.(notificationDecoder)
+}
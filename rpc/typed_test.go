@@ -0,0 +1,86 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCallTyped(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	result, err := CallTyped[echoResult](context.Background(), client, "test_echo", "hello", 10, &echoArgs{"world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (echoResult{"hello", 10, &echoArgs{"world"}}); !reflect.DeepEqual(result, want) {
+		t.Fatalf("wrong result %+v, want %+v", result, want)
+	}
+	_, err = CallTyped[string](context.Background(), client, "test_returnError")
+	wantCallError(t, err, 444)
+}
+
+func TestSubscribeTyped(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan int)
+	sub, err := SubscribeTyped(context.Background(), client, "nftest", ch, "someSubscription", 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if sub.decode == nil {
+		t.Fatal("typed decoder not installed")
+	}
+	for i := 0; i < 5; i++ {
+		if v := <-ch; v != 10+i {
+			t.Fatalf("wrong notification %d, want %d", v, 10+i)
+		}
+	}
+}
+
+func TestTypedDecoder(t *testing.T) {
+	t.Parallel()
+
+	dec := typedDecoder[echoResult]()
+	if _, ok := typedDecoders.Load(reflect.TypeFor[echoResult]()); !ok {
+		t.Fatal("decoder not stored")
+	}
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+	v, err := dec(client, "test_subscription", []byte(`{"String":"x","Int":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (echoResult{String: "x", Int: 1}); v != want {
+		t.Fatalf("wrong value %+v, want %+v", v, want)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/gorilla/websocket"
 )
@@ -48,6 +49,12 @@ var wsBufferPool = new(sync.Pool)
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
+	return s.websocketHandler(allowedOrigins, nil)
+}
+
+// websocketHandler returns the websocket handler of a route, see Server.Router. The
+// route is nil for handlers created by WebsocketHandler.
+func (s *Server) websocketHandler(allowedOrigins []string, route *httpRoute) http.Handler {
 	var upgrader = websocket.Upgrader{
 		ReadBufferSize:  wsReadBuffer,
 		WriteBufferSize: wsWriteBuffer,
@@ -55,16 +62,93 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 		CheckOrigin:     wsHandshakeValidator(allowedOrigins),
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
+		if s.headerTooLarge(r) {
+			http.Error(w, "request header too large", http.StatusRequestHeaderFieldsTooLarge)
+			return
+		}
+		fail := func(msg string) { http.Error(w, msg, http.StatusUnauthorized) }
+		principal, ok := s.authenticate(r, fail)
+		if !ok {
+			return
+		}
+		if principal, ok = route.authenticate(r, principal, fail); !ok {
+			return
+		}
+		info := PeerInfo{Transport: "ws", RemoteAddr: normalizeRemoteAddr(r.RemoteAddr), Principal: principal}
+		info.setRoute(route)
+		info.HTTP.Host = r.Host
+		info.HTTP.Origin = r.Header.Get("Origin")
+		info.HTTP.UserAgent = r.Header.Get("User-Agent")
+		if err := s.admit(&info, r.Header); err != nil {
+			rejectAdmission(w, err)
+			return
+		}
+		respHeader := make(http.Header)
+		comp := selectCompressor(r.Header.Get(WebsocketCompressionHeader), s.wsCompressors)
+		if comp != nil {
+			respHeader.Set(WebsocketCompressionHeader, comp.Name())
+			if s.wsAdaptive != nil {
+				comp = s.wsAdaptive.wrap(comp, s.clock)
+			}
+		}
+		readLimit, writeLimit, sizeHeader := negotiateMessageSize(r, s.wsSizePolicy, s.wsReadLimit)
+		if sizeHeader != "" {
+			respHeader.Set(WebsocketMessageSizeHeader, sizeHeader)
+		}
+		wire := selectWireCodec(websocket.Subprotocols(r), s.wireCodecs)
+		if wire != nil {
+			respHeader.Set("Sec-Websocket-Protocol", wire.Name())
+		}
+		var blobThreshold int
+		if s.binaryPayloads > 0 && wire == nil && r.Header.Get(BinaryPayloadHeader) != "" {
+			blobThreshold = s.binaryPayloads
+			respHeader.Set(BinaryPayloadHeader, "1")
+		}
+		fragments := r.Header.Get(WebsocketFragmentationHeader) == "1"
+		fragmentSize := 0
+		if fragments {
+			fragmentSize = s.wsFragmentSize
+			respHeader.Set(WebsocketFragmentationHeader, "1")
+		}
+		coalesce := s.wsCoalesceWindow > 0 && acceptsCoalescing(r) && wire == nil
+		if coalesce {
+			respHeader.Set(WebsocketCoalesceHeader, "1")
+		}
+		conn, err := upgrader.Upgrade(w, r, respHeader)
 		if err != nil {
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
+		codec := newWebsocketCodec(conn, r.Host, r.Header, readLimit, writeLimit, fragments, fragmentSize, comp, wire, blobThreshold, s.clock)
+		if coalesce {
+			codec.(*websocketCodec).enableCoalescing(s.wsCoalesceWindow, s.wsCoalesceBytes, writeLimit)
+		}
+		if sc, ok := spanContextFromHeader(r.Header); ok {
+			codec.(*websocketCodec).trace = sc
+		}
+		codec.(*websocketCodec).info.Principal = principal
+		codec.(*websocketCodec).info.Metadata = info.Metadata
+		codec.(*websocketCodec).info.setRoute(route)
 		s.ServeCodec(codec, 0)
 	})
 }
 
+// SetWebsocketFragmentSize makes the websocket handler split messages larger than size
+// bytes into multiple websocket messages. Use this when messages must pass through
+// infrastructure which limits the size of websocket messages. Clients must support
+// reassembly of such messages, see WithWebsocketFragmentSize. The total size of a
+// reassembled message is still limited by the read limit. A size of zero disables
+// fragmentation, which is the default.
+//
+// Fragmentation is negotiated in the handshake, see WebsocketFragmentationHeader.
+// Fragmented messages sent by clients are accepted on connections where it was
+// negotiated, regardless of the fragment size.
+//
+// This method should be called before serving any websocket connections.
+func (s *Server) SetWebsocketFragmentSize(size int) {
+	s.wsFragmentSize = size
+}
+
 // wsHandshakeValidator returns a handler that verifies the origin during the
 // websocket upgrade process. When a '*' is specified as an allowed origins all
 // connections are accepted.
@@ -239,6 +323,20 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 	for key, values := range cfg.httpHeaders {
 		header[key] = values
 	}
+	offerCompressors(header, cfg.wsCompressors)
+	if cfg.wireCodec != nil {
+		header.Set("Sec-Websocket-Protocol", cfg.wireCodec.Name())
+	}
+	messageSizeLimit := int64(wsDefaultReadLimit)
+	if cfg.wsMessageSizeLimit != nil && *cfg.wsMessageSizeLimit >= 0 {
+		messageSizeLimit = *cfg.wsMessageSizeLimit
+	}
+	requestMessageSize(header, messageSizeLimit)
+	header.Set(WebsocketCoalesceHeader, "1")
+	header.Set(WebsocketFragmentationHeader, "1")
+	if cfg.binaryPayloads > 0 {
+		header.Set(BinaryPayloadHeader, "1")
+	}
 
 	connect := func(ctx context.Context) (ServerCodec, error) {
 		header := header.Clone()
@@ -255,11 +353,25 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 			}
 			return nil, hErr
 		}
-		messageSizeLimit := int64(wsDefaultReadLimit)
-		if cfg.wsMessageSizeLimit != nil && *cfg.wsMessageSizeLimit >= 0 {
-			messageSizeLimit = *cfg.wsMessageSizeLimit
+		comp, err := acceptedCompressor(resp, cfg.wsCompressors)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		var wire WireCodec
+		if cfg.wireCodec != nil {
+			wire = wireCodecByName(conn.Subprotocol(), []WireCodec{cfg.wireCodec})
 		}
-		return newWebsocketCodec(conn, dialURL, header, messageSizeLimit), nil
+		var blobThreshold int
+		if wire == nil && resp.Header.Get(BinaryPayloadHeader) != "" {
+			blobThreshold = cfg.binaryPayloads
+		}
+		fragments := resp.Header.Get(WebsocketFragmentationHeader) == "1"
+		fragmentSize := 0
+		if fragments {
+			fragmentSize = cfg.wsFragmentSize
+		}
+		return newWebsocketCodec(conn, dialURL, header, messageSizeLimit, 0, fragments, fragmentSize, comp, wire, blobThreshold, cfg.clock), nil
 	}
 	return connect, nil
 }
@@ -289,27 +401,36 @@ type websocketCodec struct {
 	wg           sync.WaitGroup
 	pingReset    chan struct{}
 	pongReceived chan struct{}
+	coalescer    *wsCoalescer   // combines outgoing messages, nil if disabled
+	activity     connActivity   // detects idleness while draining
+	clock        mclock.Clock   // schedules pings
+	trace        SpanContext    // span context of the handshake request
+	versions     map[string]int // API versions selected in the handshake request
 }
 
-func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readLimit int64) ServerCodec {
-	conn.SetReadLimit(readLimit)
-	encode := func(v interface{}, isErrorResponse bool) error {
-		return conn.WriteJSON(v)
+func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readLimit, writeLimit int64, fragments bool, fragmentSize int, comp WebsocketCompressor, wire WireCodec, blobThreshold int, clock mclock.Clock) ServerCodec {
+	if clock == nil {
+		clock = mclock.System{}
 	}
+	conn.SetReadLimit(readLimit)
+	encode := wsEncoder(conn, fragmentSize, writeLimit, comp, wire, blobThreshold)
+	decode := wsDecoder(conn, readLimit, fragments, comp, wire, blobThreshold > 0)
 	wc := &websocketCodec{
-		jsonCodec:    NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec),
+		jsonCodec:    NewFuncCodec(conn, encode, decode).(*jsonCodec),
 		conn:         conn,
 		pingReset:    make(chan struct{}, 1),
 		pongReceived: make(chan struct{}),
+		clock:        clock,
 		info: PeerInfo{
 			Transport:  "ws",
-			RemoteAddr: conn.RemoteAddr().String(),
+			RemoteAddr: normalizeRemoteAddr(conn.RemoteAddr().String()),
 		},
 	}
 	// Fill in connection details.
 	wc.info.HTTP.Host = host
 	wc.info.HTTP.Origin = req.Get("Origin")
 	wc.info.HTTP.UserAgent = req.Get("User-Agent")
+	wc.versions = parseAPIVersions(req.Get(APIVersionHeader))
 	// Start pinger.
 	conn.SetPongHandler(func(appData string) error {
 		select {
@@ -323,6 +444,16 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	return wc
 }
 
+// spanContext returns the span context sent by the client in the handshake request.
+func (wc *websocketCodec) spanContext() (SpanContext, bool) {
+	return wc.trace, wc.trace.IsValid()
+}
+
+// apiVersions returns the API versions selected by the client in the handshake request.
+func (wc *websocketCodec) apiVersions() map[string]int {
+	return wc.versions
+}
+
 func (wc *websocketCodec) close() {
 	wc.jsonCodec.close()
 	wc.wg.Wait()
@@ -332,7 +463,24 @@ func (wc *websocketCodec) peerInfo() PeerInfo {
 	return wc.info
 }
 
+func (wc *websocketCodec) readBatch() ([]*jsonrpcMessage, bool, error) {
+	msgs, batch, err := wc.jsonCodec.readBatch()
+	if err == nil {
+		wc.activity.read(msgs)
+	}
+	return msgs, batch, err
+}
+
 func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}, isError bool) error {
+	defer wc.activity.wrote(v)
+	if wc.coalescer != nil {
+		return wc.coalescer.add(v)
+	}
+	return wc.writeNow(ctx, v, isError)
+}
+
+// writeNow writes v to the connection.
+func (wc *websocketCodec) writeNow(ctx context.Context, v interface{}, isError bool) error {
 	err := wc.jsonCodec.writeJSON(ctx, v, isError)
 	if err == nil {
 		// Notify pingLoop to delay the next idle ping.
@@ -346,7 +494,7 @@ func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}, isError
 
 // pingLoop sends periodic ping frames when the connection is idle.
 func (wc *websocketCodec) pingLoop() {
-	var pingTimer = time.NewTimer(wsPingInterval)
+	var pingTimer = wc.clock.NewTimer(wsPingInterval)
 	defer wc.wg.Done()
 	defer pingTimer.Stop()
 
@@ -357,11 +505,11 @@ func (wc *websocketCodec) pingLoop() {
 
 		case <-wc.pingReset:
 			if !pingTimer.Stop() {
-				<-pingTimer.C
+				<-pingTimer.C()
 			}
 			pingTimer.Reset(wsPingInterval)
 
-		case <-pingTimer.C:
+		case <-pingTimer.C():
 			wc.jsonCodec.encMu.Lock()
 			wc.conn.SetWriteDeadline(time.Now().Add(wsPingWriteTimeout))
 			wc.conn.WriteMessage(websocket.PingMessage, nil)
@@ -0,0 +1,154 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// CompressionLevel is the compression effort selected by adaptive compression.
+type CompressionLevel int32
+
+const (
+	// CompressionDefault uses the compressor at its default level.
+	CompressionDefault CompressionLevel = iota
+	// CompressionFast trades compression ratio for speed.
+	CompressionFast
+	// CompressionNone sends messages uncompressed.
+	CompressionNone
+)
+
+// WebsocketLevelCompressor is implemented by websocket compressors which support more
+// than one compression level. Adaptive compression uses CompressLevel to reduce the
+// effort spent on compression under CPU pressure. Compressors which don't implement it
+// are used at their default level until compression is disabled.
+type WebsocketLevelCompressor interface {
+	WebsocketCompressor
+	// CompressLevel appends the compressed form of src to dst, using the given level.
+	// The level is never CompressionNone.
+	CompressLevel(dst, src []byte, level CompressionLevel) ([]byte, error)
+}
+
+// AdaptiveCompression configures how websocket compression responds to CPU pressure.
+type AdaptiveCompression struct {
+	// Pressure reports the current CPU pressure, from 0 (idle) to 1 (saturated). A
+	// typical implementation reports the recent CPU utilization of the process or the
+	// PSI metrics of the system.
+	Pressure func() float64
+
+	// When the pressure exceeds ReduceAbove, messages are compressed with
+	// CompressionFast. When it exceeds DisableAbove, messages are sent uncompressed.
+	// The defaults are 0.6 and 0.85.
+	ReduceAbove  float64
+	DisableAbove float64
+
+	// Interval is the time between samples of the pressure signal. The default is one
+	// second.
+	Interval time.Duration
+}
+
+const (
+	defaultCompressionReduceAbove  = 0.6
+	defaultCompressionDisableAbove = 0.85
+	defaultCompressionInterval     = time.Second
+)
+
+// SetAdaptiveCompression makes websocket compression adjust to CPU pressure, so that
+// compression saves bandwidth in quiet periods without becoming the bottleneck during
+// load spikes. The level applies to all websocket connections using a compressor
+// configured by SetWebsocketCompressors. Passing a config without Pressure function
+// disables adaptive compression.
+//
+// This method should be called before serving any websocket connections.
+func (s *Server) SetAdaptiveCompression(cfg AdaptiveCompression) {
+	if cfg.Pressure == nil {
+		s.wsAdaptive = nil
+		return
+	}
+	if cfg.ReduceAbove <= 0 {
+		cfg.ReduceAbove = defaultCompressionReduceAbove
+	}
+	if cfg.DisableAbove <= 0 {
+		cfg.DisableAbove = defaultCompressionDisableAbove
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultCompressionInterval
+	}
+	s.wsAdaptive = &compressionGovernor{cfg: cfg}
+}
+
+// compressionGovernor selects the compression level from the pressure signal. It is
+// shared by all connections of a server.
+type compressionGovernor struct {
+	cfg   AdaptiveCompression
+	next  atomic.Int64 // time of the next sample
+	level atomic.Int32
+}
+
+// currentLevel returns the compression level, sampling the pressure signal if the
+// interval has passed. Only one caller samples at a time, others use the last level.
+func (g *compressionGovernor) currentLevel(now mclock.AbsTime) CompressionLevel {
+	next := g.next.Load()
+	if int64(now) >= next && g.next.CompareAndSwap(next, int64(now.Add(g.cfg.Interval))) {
+		level := CompressionDefault
+		switch p := g.cfg.Pressure(); {
+		case p > g.cfg.DisableAbove:
+			level = CompressionNone
+		case p > g.cfg.ReduceAbove:
+			level = CompressionFast
+		}
+		g.level.Store(int32(level))
+		wsCompressionLevelGauge.Update(int64(level))
+	}
+	return CompressionLevel(g.level.Load())
+}
+
+// wrap returns a compressor which applies the level selected by the governor.
+func (g *compressionGovernor) wrap(comp WebsocketCompressor, clock mclock.Clock) WebsocketCompressor {
+	return &adaptiveCompressor{WebsocketCompressor: comp, gov: g, clock: clock}
+}
+
+// adaptiveCompressor is a compressor whose level is controlled by a compressionGovernor.
+type adaptiveCompressor struct {
+	WebsocketCompressor
+	gov   *compressionGovernor
+	clock mclock.Clock
+}
+
+// enabled reports whether the next message should be compressed.
+func (c *adaptiveCompressor) enabled() bool {
+	return c.gov.currentLevel(c.clock.Now()) != CompressionNone
+}
+
+func (c *adaptiveCompressor) Compress(dst, src []byte) ([]byte, error) {
+	level := CompressionLevel(c.gov.level.Load())
+	if lc, ok := c.WebsocketCompressor.(WebsocketLevelCompressor); ok && level == CompressionFast {
+		return lc.CompressLevel(dst, src, level)
+	}
+	return c.WebsocketCompressor.Compress(dst, src)
+}
+
+// compressionEnabled reports whether messages should currently be compressed with comp.
+func compressionEnabled(comp WebsocketCompressor) bool {
+	if ac, ok := comp.(*adaptiveCompressor); ok {
+		return ac.enabled()
+	}
+	return comp != nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// levelTestCompressor is a gzipTestCompressor which supports compression levels.
+type levelTestCompressor struct {
+	gzipTestCompressor
+	fast atomic.Int64
+}
+
+func (c *levelTestCompressor) CompressLevel(dst, src []byte, level CompressionLevel) ([]byte, error) {
+	if level == CompressionFast {
+		c.fast.Add(1)
+	}
+	return c.gzipTestCompressor.Compress(dst, src)
+}
+
+func TestWebsocketAdaptiveCompression(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv      = newTestServer()
+		httpsrv  = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL    = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+		clock    = new(mclock.Simulated)
+		serverGz = &levelTestCompressor{gzipTestCompressor: gzipTestCompressor{name: "gzip-test"}}
+		clientGz = &gzipTestCompressor{name: "gzip-test"}
+		pressure atomic.Int64 // percent
+	)
+	srv.SetClock(clock)
+	srv.SetWebsocketCompressors(serverGz)
+	srv.SetAdaptiveCompression(AdaptiveCompression{
+		Pressure: func() float64 { return float64(pressure.Load()) / 100 },
+	})
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	client, err := DialOptions(context.Background(), wsURL, WithWebsocketCompressors(clientGz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// call sets the pressure, advances the clock past the sampling interval and
+	// performs a call with a large response. It returns the number of messages
+	// compressed by the server at default and fast level.
+	call := func(percent int64) (normal, fast int64) {
+		t.Helper()
+		pressure.Store(percent)
+		clock.Run(defaultCompressionInterval)
+		compressed, fastBefore := serverGz.compressed.Load(), serverGz.fast.Load()
+		var res string
+		if err := client.Call(&res, "test_repeat", strings.Repeat("ab", 1000), 10); err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 20000 {
+			t.Fatalf("wrong result length %d", len(res))
+		}
+		fast = serverGz.fast.Load() - fastBefore
+		return serverGz.compressed.Load() - compressed - fast, fast
+	}
+
+	if normal, fast := call(10); normal != 1 || fast != 0 {
+		t.Fatalf("low pressure: %d normal, %d fast compressions", normal, fast)
+	}
+	if normal, fast := call(70); normal != 0 || fast != 1 {
+		t.Fatalf("moderate pressure: %d normal, %d fast compressions", normal, fast)
+	}
+	if normal, fast := call(95); normal != 0 || fast != 0 {
+		t.Fatalf("high pressure: %d normal, %d fast compressions", normal, fast)
+	}
+	if normal, fast := call(10); normal != 1 || fast != 0 {
+		t.Fatalf("recovered pressure: %d normal, %d fast compressions", normal, fast)
+	}
+}
+
+func TestCompressionGovernorInterval(t *testing.T) {
+	t.Parallel()
+
+	var samples int
+	srv := NewServer()
+	srv.SetAdaptiveCompression(AdaptiveCompression{
+		Pressure: func() float64 { samples++; return 0.9 },
+		Interval: time.Second,
+	})
+	gov := srv.wsAdaptive
+	start := mclock.AbsTime(time.Hour)
+	for _, now := range []mclock.AbsTime{start, start.Add(500 * time.Millisecond), start.Add(time.Second)} {
+		if level := gov.currentLevel(now); level != CompressionNone {
+			t.Fatalf("wrong level %d at %v", level, now)
+		}
+	}
+	if samples != 2 {
+		t.Fatalf("pressure sampled %d times, want 2", samples)
+	}
+}
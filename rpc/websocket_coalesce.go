@@ -0,0 +1,196 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// WebsocketCoalesceHeader is the handshake header in which clients announce that they
+// accept responses and notifications combined into a single JSON array.
+const WebsocketCoalesceHeader = "X-Rpc-Ws-Coalesce"
+
+const defaultCoalesceBytes = 64 * 1024
+
+var errCoalescerClosed = errors.New("connection closed")
+
+// SetWebsocketCoalescing makes the websocket handler combine outgoing messages which are
+// sent within window of each other into a single websocket message, up to maxBytes per
+// message. This reduces the number of writes under high notification rates, at the cost
+// of delaying messages by up to window. Combined messages are sent as a JSON array, so
+// coalescing is only used with clients which announce support in the handshake. Clients
+// of this package do. A maxBytes of zero selects a default of 64KB, a zero window
+// disables coalescing.
+//
+// This method should be called before serving any websocket connections.
+func (s *Server) SetWebsocketCoalescing(window time.Duration, maxBytes int) {
+	if maxBytes <= 0 {
+		maxBytes = defaultCoalesceBytes
+	}
+	s.wsCoalesceWindow = window
+	s.wsCoalesceBytes = maxBytes
+}
+
+// acceptsCoalescing reports whether the client sending the handshake request r
+// supports combined messages.
+func acceptsCoalescing(r *http.Request) bool {
+	return r.Header.Get(WebsocketCoalesceHeader) == "1"
+}
+
+// wsCoalescer collects encoded messages of a websocket connection and writes them in
+// batches. Each queued unit is a single message or a complete batch response.
+type wsCoalescer struct {
+	window     time.Duration
+	maxBytes   int
+	writeLimit int64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []json.RawMessage
+	size    int
+	closed  bool
+	wake    chan struct{}
+}
+
+// enableCoalescing makes wc write messages through a coalescer. writeLimit is the
+// negotiated message size limit of the connection, zero if unlimited.
+func (wc *websocketCodec) enableCoalescing(window time.Duration, maxBytes int, writeLimit int64) {
+	if writeLimit > 0 {
+		maxBytes = int(min(int64(maxBytes), writeLimit))
+	}
+	c := &wsCoalescer{window: window, maxBytes: maxBytes, writeLimit: writeLimit, wake: make(chan struct{}, 1)}
+	c.cond = sync.NewCond(&c.mu)
+	wc.coalescer = c
+	wc.wg.Add(1)
+	go wc.coalesceLoop()
+}
+
+// add queues v for writing. It blocks while a large amount of data is waiting to be
+// written, which happens when the connection is slower than the producers of messages.
+func (c *wsCoalescer) add(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if c.writeLimit > 0 && int64(len(data)) > c.writeLimit {
+		if data, err = replaceTooLarge(v); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.size >= 4*c.maxBytes && !c.closed {
+		c.cond.Wait()
+	}
+	if c.closed {
+		return errCoalescerClosed
+	}
+	c.pending = append(c.pending, data)
+	c.size += len(data)
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// take removes the next group of queued units and returns them as a single message.
+// Units are combined as long as the message stays within maxBytes. It returns nil if
+// nothing is queued.
+func (c *wsCoalescer) take() json.RawMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return nil
+	}
+	n, size := 1, len(c.pending[0])
+	for n < len(c.pending) && size+len(c.pending[n])+1 <= c.maxBytes {
+		size += len(c.pending[n]) + 1
+		n++
+	}
+	var out json.RawMessage
+	if n == 1 {
+		out = c.pending[0]
+	} else {
+		out = make(json.RawMessage, 0, size+2)
+		out = append(out, '[')
+		for i, unit := range c.pending[:n] {
+			if i > 0 {
+				out = append(out, ',')
+			}
+			// Batch responses are spliced into the combined array.
+			if unit[0] == '[' {
+				unit = unit[1 : len(unit)-1]
+			}
+			out = append(out, unit...)
+		}
+		out = append(out, ']')
+	}
+	for i := range n {
+		c.size -= len(c.pending[i])
+		c.pending[i] = nil
+	}
+	c.pending = c.pending[n:]
+	c.cond.Broadcast()
+	return out
+}
+
+func (c *wsCoalescer) close() {
+	c.mu.Lock()
+	c.closed = true
+	c.cond.Broadcast()
+	c.mu.Unlock()
+}
+
+// coalesceLoop writes queued messages. After the first message arrives, it waits for
+// the coalescing window to collect more.
+func (wc *websocketCodec) coalesceLoop() {
+	defer wc.wg.Done()
+	c := wc.coalescer
+	defer c.close()
+
+	timer := time.NewTimer(0)
+	<-timer.C
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.wake:
+		case <-wc.closed():
+			return
+		}
+		timer.Reset(c.window)
+		select {
+		case <-timer.C:
+		case <-wc.closed():
+			return
+		}
+		for msg := c.take(); msg != nil; msg = c.take() {
+			if err := wc.writeNow(context.Background(), msg, false); err != nil {
+				log.Debug("WebSocket write failed", "err", err)
+				wc.jsonCodec.close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WebsocketCompressionHeader is the handshake header used to negotiate message
+// compression between websocket clients and servers of this package. The client lists
+// the names of its compressors in order of preference, the server responds with the name
+// of the selected one.
+const WebsocketCompressionHeader = "X-Rpc-Ws-Compression"
+
+// WebsocketCompressor is a message compression algorithm for websocket connections. It
+// can be used to add compressors such as zstd or snappy, which are not part of the
+// standard websocket protocol. Compression is used only when both sides of a connection
+// support the same compressor.
+//
+// An adapter for a zstd library could look like this:
+//
+//	type zstdCompressor struct{ enc *zstd.Encoder; dec *zstd.Decoder }
+//
+//	func (c zstdCompressor) Name() string { return "zstd" }
+//	func (c zstdCompressor) Compress(dst, src []byte) ([]byte, error) {
+//		return c.enc.EncodeAll(src, dst), nil
+//	}
+//	func (c zstdCompressor) Decompress(dst, src []byte, limit int) ([]byte, error) { ... }
+type WebsocketCompressor interface {
+	// Name returns the name used in negotiation, e.g. "zstd".
+	Name() string
+	// Compress appends the compressed form of src to dst.
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress appends the decompressed form of src to dst. It must fail if the
+	// decompressed size exceeds limit bytes. A limit of zero means no limit.
+	Decompress(dst, src []byte, limit int) ([]byte, error)
+}
+
+// Compressed messages are sent as binary websocket messages starting with compressMagic.
+var compressMagic = []byte{0, 'R', 'P', 'C', 'Z'}
+
+// wsCompressMinSize is the size below which messages are sent uncompressed.
+const wsCompressMinSize = 256
+
+var errCompressionNotNegotiated = errors.New("received compressed message without negotiated compression")
+
+// SetWebsocketCompressors configures the compressors offered to websocket clients. The
+// first compressor named by the client which is also in this list is used. Clients
+// request compression using WithWebsocketCompressors.
+//
+// This method should be called before serving any websocket connections.
+func (s *Server) SetWebsocketCompressors(compressors ...WebsocketCompressor) {
+	s.wsCompressors = compressors
+}
+
+// WithWebsocketCompressors makes the websocket client offer the given compressors to the
+// server, in order of preference. Messages are compressed if the server supports one of
+// them, see Server.SetWebsocketCompressors.
+func WithWebsocketCompressors(compressors ...WebsocketCompressor) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.wsCompressors = compressors
+	})
+}
+
+// offerCompressors adds the compression header to a client handshake request.
+func offerCompressors(header http.Header, compressors []WebsocketCompressor) {
+	if len(compressors) == 0 {
+		return
+	}
+	names := make([]string, len(compressors))
+	for i, c := range compressors {
+		names[i] = c.Name()
+	}
+	header.Set(WebsocketCompressionHeader, strings.Join(names, ", "))
+}
+
+// selectCompressor picks the compressor for a connection, given the offer sent by the
+// client. It returns nil if there is no common compressor.
+func selectCompressor(offer string, supported []WebsocketCompressor) WebsocketCompressor {
+	for _, name := range strings.Split(offer, ",") {
+		name = strings.TrimSpace(name)
+		for _, c := range supported {
+			if c.Name() == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// acceptedCompressor returns the compressor selected by the server in its handshake
+// response.
+func acceptedCompressor(resp *http.Response, offered []WebsocketCompressor) (WebsocketCompressor, error) {
+	if resp == nil {
+		return nil, nil
+	}
+	name := resp.Header.Get(WebsocketCompressionHeader)
+	if name == "" {
+		return nil, nil
+	}
+	for _, c := range offered {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("server selected unknown websocket compressor %q", name)
+}
+
+// compressMessage returns the compressed message for data.
+func compressMessage(c WebsocketCompressor, data []byte) ([]byte, error) {
+	out := make([]byte, len(compressMagic), len(compressMagic)+len(data)/2)
+	copy(out, compressMagic)
+	return c.Compress(out, data)
+}
+
+// decompressMessage decompresses a message produced by compressMessage.
+func decompressMessage(c WebsocketCompressor, data []byte, limit int64) ([]byte, error) {
+	if c == nil {
+		return nil, errCompressionNotNegotiated
+	}
+	return c.Decompress(nil, bytes.TrimPrefix(data, compressMagic), int(limit))
+}
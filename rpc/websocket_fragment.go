@@ -0,0 +1,209 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebsocketFragmentationHeader is the handshake header in which clients announce that
+// they reassemble fragmented messages. The server echoes it to confirm that it does too.
+// Fragments are only sent and accepted on connections where both sides announced it.
+const WebsocketFragmentationHeader = "X-Rpc-Ws-Fragmentation"
+
+// Messages exceeding the fragment size of a websocket connection are sent as a sequence
+// of binary messages. The first message starts with fragmentMagic and the total size of
+// the JSON message as a big-endian uint64. It is followed by continuation messages
+// containing the remaining data.
+var fragmentMagic = []byte{0, 'R', 'P', 'C', 'F'}
+
+const (
+	fragmentHeaderSize  = 5 + 8
+	minFragmentSize     = 64
+	maxFragmentPrealloc = 64 * 1024 // buffer allocated before fragments arrive
+)
+
+var (
+	errFragmentTooLarge = errors.New("fragmented message exceeds read limit")
+	errFragmentInvalid  = errors.New("invalid fragmented message")
+	errFragmentDisabled = errors.New("fragmentation not negotiated")
+)
+
+// wsEncoder returns the encode function of a websocket codec. Messages are translated
+// to the wire encoding, if any, and compressed if a compressor is given. Messages larger
+// than fragmentSize are split into fragments. A fragment size of zero disables
+// fragmentation. Responses whose encoding exceeds writeLimit are replaced by errors, a
+// limit of zero means no limit. Hex strings of at least blobThreshold bytes are sent as
+// binary payloads, zero disables them.
+func wsEncoder(conn *websocket.Conn, fragmentSize int, writeLimit int64, comp WebsocketCompressor, wire WireCodec, blobThreshold int) encodeFunc {
+	if fragmentSize <= 0 && writeLimit <= 0 && comp == nil && wire == nil && blobThreshold <= 0 {
+		return func(v interface{}, isErrorResponse bool) error {
+			return conn.WriteJSON(v)
+		}
+	}
+	if fragmentSize > 0 {
+		fragmentSize = max(fragmentSize, minFragmentSize)
+	}
+	return func(v interface{}, isErrorResponse bool) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if writeLimit > 0 && int64(len(data)) > writeLimit {
+			if data, err = replaceTooLarge(v); err != nil {
+				return err
+			}
+		}
+		typ := websocket.TextMessage
+		if wire != nil {
+			if data, err = wire.FromJSON(nil, data); err != nil {
+				return err
+			}
+			typ = websocket.BinaryMessage
+		} else if blobThreshold > 0 {
+			if js, blobs := extractBlobs(data, blobThreshold); blobs != nil {
+				data = packBlobs(js, blobs)
+				typ = websocket.BinaryMessage
+			}
+		}
+		if len(data) >= wsCompressMinSize && compressionEnabled(comp) {
+			if data, err = compressMessage(comp, data); err != nil {
+				return err
+			}
+			typ = websocket.BinaryMessage
+		}
+		if fragmentSize <= 0 || len(data) <= fragmentSize {
+			return conn.WriteMessage(typ, data)
+		}
+		first := make([]byte, fragmentHeaderSize, fragmentSize)
+		copy(first, fragmentMagic)
+		binary.BigEndian.PutUint64(first[len(fragmentMagic):], uint64(len(data)))
+		n := fragmentSize - fragmentHeaderSize
+		first = append(first, data[:n]...)
+		if err := conn.WriteMessage(websocket.BinaryMessage, first); err != nil {
+			return err
+		}
+		for data = data[n:]; len(data) > 0; data = data[n:] {
+			n = min(len(data), fragmentSize)
+			if err := conn.WriteMessage(websocket.BinaryMessage, data[:n]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// wsDecoder returns the decode function of a websocket codec. Fragmented messages are
+// reassembled if fragmentation was negotiated, and compressed messages are decompressed
+// using comp. With a wire codec, binary messages are in its encoding. Messages with
+// binary payloads are accepted if blobs is set. The total size of such messages, and
+// their size once payloads are restored, is limited by readLimit. A limit of zero means
+// no limit.
+func wsDecoder(conn *websocket.Conn, readLimit int64, fragments bool, comp WebsocketCompressor, wire WireCodec, blobs bool) decodeFunc {
+	return func(v interface{}) error {
+		typ, r, err := conn.NextReader()
+		if err != nil {
+			return err
+		}
+		if typ == websocket.BinaryMessage {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			if bytes.HasPrefix(data, fragmentMagic) {
+				if !fragments {
+					return errFragmentDisabled
+				}
+				if data, err = readFragments(conn, data, readLimit); err != nil {
+					return err
+				}
+			}
+			if bytes.HasPrefix(data, compressMagic) {
+				if data, err = decompressMessage(comp, data, readLimit); err != nil {
+					return err
+				}
+			}
+			if bytes.HasPrefix(data, blobMagic) {
+				if !blobs {
+					return errBlobsDisabled
+				}
+				if data, err = unpackBlobs(data, readLimit); err != nil {
+					return err
+				}
+				return json.Unmarshal(data, v)
+			}
+			if wire != nil {
+				if data, err = wire.ToJSON(nil, data); err != nil {
+					return err
+				}
+			}
+			return json.Unmarshal(data, v)
+		}
+		// This is the same as conn.ReadJSON.
+		err = json.NewDecoder(r).Decode(v)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+}
+
+// readFragments reads the remaining fragments of a message. The buffer grows as
+// fragments arrive, so the size announced by the peer is never allocated up front.
+func readFragments(conn *websocket.Conn, first []byte, readLimit int64) ([]byte, error) {
+	if len(first) < fragmentHeaderSize {
+		return nil, errFragmentInvalid
+	}
+	size := binary.BigEndian.Uint64(first[len(fragmentMagic):])
+	if readLimit > 0 && size > uint64(readLimit) {
+		return nil, errFragmentTooLarge
+	}
+	data := make([]byte, 0, min(size, maxFragmentPrealloc))
+	data = append(data, first[fragmentHeaderSize:]...)
+	for uint64(len(data)) < size {
+		typ, r, err := conn.NextReader()
+		if err != nil {
+			return nil, err
+		}
+		if typ != websocket.BinaryMessage {
+			return nil, errFragmentInvalid
+		}
+		if data, err = readAppend(data, r); err != nil {
+			return nil, err
+		}
+		if uint64(len(data)) > size {
+			return nil, errFragmentInvalid
+		}
+	}
+	if uint64(len(data)) != size {
+		return nil, errFragmentInvalid
+	}
+	return data, nil
+}
+
+// readAppend appends all data from r to buf.
+func readAppend(buf []byte, r io.Reader) ([]byte, error) {
+	b := bytes.NewBuffer(buf)
+	_, err := b.ReadFrom(r)
+	return b.Bytes(), err
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// WebsocketMessageSizeHeader is the handshake header used to negotiate the maximum
+// message size of a websocket connection. The client sends the limit it would like to
+// use, the server responds with the limit it grants.
+const WebsocketMessageSizeHeader = "X-Rpc-Ws-Max-Message-Size"
+
+var errMessageTooLarge = errors.New("message exceeds negotiated size limit")
+
+// WebsocketMessageSizePolicy decides the maximum message size granted to a websocket
+// client which requested a limit above the default. r is the handshake request, which
+// can be used to identify the client. The policy returns the granted limit. Values
+// below the default limit are raised to it, values above the requested limit are
+// lowered to it.
+type WebsocketMessageSizePolicy func(r *http.Request, requested int64) int64
+
+// SetWebsocketMessageSizePolicy enables negotiation of the maximum message size for
+// websocket connections. The negotiated limit applies to messages in both directions:
+// the server does not accept larger requests, and responses exceeding the limit are
+// replaced by an error response. Clients which do not request a larger limit are
+// held to the default limit of 32MB, or the limit set by SetHTTPConfig.
+//
+// Without a policy, requests are limited to the default and responses are not limited.
+//
+// This method should be called before serving any websocket connections.
+func (s *Server) SetWebsocketMessageSizePolicy(policy WebsocketMessageSizePolicy) {
+	s.wsSizePolicy = policy
+}
+
+// negotiateMessageSize returns the read and write limits of a server-side websocket
+// connection and the response header value announcing them. base is the default limit.
+func negotiateMessageSize(r *http.Request, policy WebsocketMessageSizePolicy, base int64) (readLimit, writeLimit int64, header string) {
+	if policy == nil {
+		return base, 0, ""
+	}
+	limit := base
+	requested, err := strconv.ParseInt(r.Header.Get(WebsocketMessageSizeHeader), 10, 64)
+	if err == nil && requested > limit {
+		limit = min(max(policy(r, requested), limit), requested)
+	}
+	return limit, limit, strconv.FormatInt(limit, 10)
+}
+
+// requestMessageSize adds the message size request for limit to the handshake header
+// of a client. A limit of zero requests the largest size the server will grant.
+func requestMessageSize(header http.Header, limit int64) {
+	if limit == 0 {
+		limit = math.MaxInt64
+	}
+	header.Set(WebsocketMessageSizeHeader, strconv.FormatInt(limit, 10))
+}
+
+// replaceTooLarge returns the encoding of error responses which replace the responses
+// in v when their encoding exceeds the write limit of a connection. Messages other than
+// responses cannot be replaced.
+func replaceTooLarge(v interface{}) ([]byte, error) {
+	tooLarge := &internalServerError{errcodeResponseTooLarge, errMsgResponseTooLarge}
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		if v.isResponse() {
+			return json.Marshal(v.errorResponse(tooLarge))
+		}
+	case []*jsonrpcMessage:
+		resp := make([]*jsonrpcMessage, len(v))
+		for i, msg := range v {
+			if !msg.isResponse() {
+				return nil, errMessageTooLarge
+			}
+			resp[i] = msg.errorResponse(tooLarge)
+		}
+		return json.Marshal(resp)
+	}
+	return nil, errMessageTooLarge
+}
@@ -17,12 +17,21 @@
 package rpc
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -391,3 +400,357 @@ func wsPingTestHandler(t *testing.T, conn *websocket.Conn, shutdown, sendPing <-
 		}
 	}
 }
+
+func TestWebsocketFragmentation(t *testing.T) {
+	t.Parallel()
+
+	const fragmentSize = 1024
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	srv.SetWebsocketFragmentSize(fragmentSize)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	// Check that the server doesn't fragment unless the client offered reassembly.
+	plain, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	plain.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"test_repeat","params":["A",5000]}`))
+	if typ, _, err := plain.ReadMessage(); err != nil || typ != websocket.TextMessage {
+		t.Fatalf("unexpected message type %d, err %v", typ, err)
+	}
+
+	// Check that the server sends large responses as fragments.
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{WebsocketFragmentationHeader: {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if resp.Header.Get(WebsocketFragmentationHeader) != "1" {
+		t.Fatal("server didn't confirm fragmentation")
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"test_repeat","params":["A",5000]}`))
+	var frames, total int
+	for total < 5000 {
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != websocket.BinaryMessage || len(data) > fragmentSize {
+			t.Fatalf("unexpected message type %d, size %d", typ, len(data))
+		}
+		frames++
+		total += len(data)
+	}
+	if frames < 5 {
+		t.Fatalf("response sent in %d frames", frames)
+	}
+
+	// Check that the client reassembles responses and fragments large requests.
+	client, err := DialOptions(context.Background(), wsURL, WithWebsocketFragmentSize(fragmentSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var res string
+	if err := client.Call(&res, "test_repeat", "A", 5000); err != nil {
+		t.Fatal(err)
+	}
+	if res != strings.Repeat("A", 5000) {
+		t.Fatal("wrong result")
+	}
+	var echo echoResult
+	arg := strings.Repeat("x", 5000)
+	if err := client.Call(&echo, "test_echo", arg, 1); err != nil {
+		t.Fatal(err)
+	}
+	if echo.String != arg {
+		t.Fatal("wrong string echoed")
+	}
+}
+
+// This test checks that the size announced in a fragment header is not trusted.
+func TestWebsocketFragmentSizeUntrusted(t *testing.T) {
+	t.Parallel()
+
+	upgrader := websocket.Upgrader{}
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, http.Header{WebsocketFragmentationHeader: {"1"}})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.ReadMessage()
+		header := binary.BigEndian.AppendUint64(slices.Clone(fragmentMagic), math.MaxUint64)
+		conn.WriteMessage(websocket.BinaryMessage, header)
+	}))
+	defer httpsrv.Close()
+
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	client, err := DialOptions(context.Background(), wsURL, WithWebsocketMessageSizeLimit(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "test_echo"); err == nil {
+		t.Fatal("call succeeded")
+	}
+}
+
+// gzipTestCompressor is a WebsocketCompressor based on gzip, for testing.
+type gzipTestCompressor struct {
+	name       string
+	compressed atomic.Int64
+}
+
+func (c *gzipTestCompressor) Name() string { return c.name }
+
+func (c *gzipTestCompressor) Compress(dst, src []byte) ([]byte, error) {
+	c.compressed.Add(1)
+	buf := bytes.NewBuffer(dst)
+	w := gzip.NewWriter(buf)
+	w.Write(src)
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *gzipTestCompressor) Decompress(dst, src []byte, limit int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	var lr io.Reader = r
+	if limit > 0 {
+		lr = io.LimitReader(r, int64(limit)+1)
+	}
+	out, err := readAppend(dst, lr)
+	if limit > 0 && len(out)-len(dst) > limit {
+		return nil, errors.New("message too large")
+	}
+	return out, err
+}
+
+func TestWebsocketCompression(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv       = newTestServer()
+		httpsrv   = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL     = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+		serverGz  = &gzipTestCompressor{name: "gzip-test"}
+		clientGz  = &gzipTestCompressor{name: "gzip-test"}
+		unknownGz = &gzipTestCompressor{name: "unknown"}
+	)
+	srv.SetWebsocketCompressors(serverGz)
+	srv.SetWebsocketFragmentSize(1024)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	// The first common compressor is selected, and used together with fragmentation.
+	client, err := DialOptions(context.Background(), wsURL,
+		WithWebsocketCompressors(unknownGz, clientGz),
+		WithWebsocketFragmentSize(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var res string
+	if err := client.Call(&res, "test_repeat", strings.Repeat("ab", 2000), 20); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 80000 {
+		t.Fatalf("wrong result length %d", len(res))
+	}
+	if clientGz.compressed.Load() == 0 || serverGz.compressed.Load() == 0 {
+		t.Fatalf("compression not used: client %d, server %d", clientGz.compressed.Load(), serverGz.compressed.Load())
+	}
+	if unknownGz.compressed.Load() != 0 {
+		t.Fatal("unsupported compressor used")
+	}
+
+	// Without a common compressor, messages are sent uncompressed.
+	plain, err := DialOptions(context.Background(), wsURL, WithWebsocketCompressors(unknownGz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	before := serverGz.compressed.Load()
+	if err := plain.Call(&res, "test_repeat", strings.Repeat("ab", 2000), 2); err != nil {
+		t.Fatal(err)
+	}
+	if serverGz.compressed.Load() != before || unknownGz.compressed.Load() != 0 {
+		t.Fatal("compression used without negotiation")
+	}
+}
+
+func TestWebsocketMessageSizeNegotiation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+		big     = int64(64 * 1024 * 1024)
+	)
+	srv.SetWebsocketMessageSizePolicy(func(r *http.Request, requested int64) int64 {
+		if r.Header.Get("X-Customer") == "archive" {
+			return big
+		}
+		return 0
+	})
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	// A response larger than the default limit is replaced by an error for clients
+	// which are not granted a larger limit. The connection remains usable.
+	for _, opts := range [][]ClientOption{
+		nil,
+		{WithWebsocketMessageSizeLimit(big)},
+	} {
+		client, err := DialOptions(context.Background(), wsURL, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res string
+		err = client.Call(&res, "test_repeat", strings.Repeat("a", 1024*1024), 33)
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeResponseTooLarge {
+			t.Fatalf("expected response too large error, got %v", err)
+		}
+		if err := client.Call(nil, "test_echo", "x", 1, nil); err != nil {
+			t.Fatal("connection unusable after oversized response:", err)
+		}
+		client.Close()
+	}
+
+	// A trusted client receives the large response.
+	client, err := DialOptions(context.Background(), wsURL,
+		WithWebsocketMessageSizeLimit(big),
+		WithHeader("X-Customer", "archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var res string
+	if err := client.Call(&res, "test_repeat", strings.Repeat("a", 1024*1024), 33); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 33*1024*1024 {
+		t.Fatalf("wrong result length %d", len(res))
+	}
+}
+
+func TestNegotiateMessageSize(t *testing.T) {
+	t.Parallel()
+
+	grant := func(r *http.Request, requested int64) int64 { return 100 * 1024 * 1024 }
+	tests := []struct {
+		policy    WebsocketMessageSizePolicy
+		request   string
+		wantLimit int64
+		wantWrite int64
+	}{
+		{nil, "1000000000", wsDefaultReadLimit, 0},
+		{grant, "", wsDefaultReadLimit, wsDefaultReadLimit},
+		{grant, "1024", wsDefaultReadLimit, wsDefaultReadLimit},
+		{grant, "invalid", wsDefaultReadLimit, wsDefaultReadLimit},
+		{grant, "50000000", 50000000, 50000000},
+		{grant, "1000000000", 100 * 1024 * 1024, 100 * 1024 * 1024},
+	}
+	for i, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.request != "" {
+			r.Header.Set(WebsocketMessageSizeHeader, test.request)
+		}
+		read, write, _ := negotiateMessageSize(r, test.policy, wsDefaultReadLimit)
+		if read != test.wantLimit || write != test.wantWrite {
+			t.Errorf("test %d: got limits %d/%d, want %d/%d", i, read, write, test.wantLimit, test.wantWrite)
+		}
+	}
+}
+
+func TestWebsocketCoalescing(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	srv.SetWebsocketCoalescing(5*time.Millisecond, 0)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	// readMessages subscribes to 20 notifications and returns the number of websocket
+	// messages which carried the response and notifications.
+	readMessages := func(header http.Header) (messages int) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"nftest_subscribe","params":["someSubscription",20,1]}`))
+		for total := 0; total < 21; messages++ {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var batch []json.RawMessage
+			if err := json.Unmarshal(data, &batch); err == nil {
+				total += len(batch)
+			} else {
+				total++
+			}
+		}
+		return messages
+	}
+	if n := readMessages(nil); n != 21 {
+		t.Fatalf("client without coalescing support received %d messages, want 21", n)
+	}
+	if n := readMessages(http.Header{WebsocketCoalesceHeader: {"1"}}); n >= 21 {
+		t.Fatalf("messages were not coalesced")
+	}
+
+	// The client handles combined responses and notifications.
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ch := make(chan int, 100)
+	sub, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	errc := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			var res echoResult
+			err := client.Call(&res, "test_echo", "x", i, nil)
+			if err == nil && res.Int != i {
+				err = fmt.Errorf("call %d got response for %d", i, res.Int)
+			}
+			errc <- err
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if v := <-ch; v != i {
+			t.Fatalf("notification %d has value %d", i, v)
+		}
+	}
+}
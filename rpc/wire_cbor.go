@@ -0,0 +1,358 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// cborCodec implements CBORCodec.
+type cborCodec struct{}
+
+// CBOR major types.
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+
+	cborTagPosBignum = 2
+	cborTagNegBignum = 3
+)
+
+func (cborCodec) Name() string        { return "cbor" }
+func (cborCodec) ContentType() string { return "application/cbor" }
+
+func (c cborCodec) FromJSON(dst, src []byte) ([]byte, error) {
+	return jsonToWire(dst, src, c)
+}
+
+func (cborCodec) ToJSON(dst, src []byte) ([]byte, error) {
+	d := cborDecoder{data: src}
+	out, err := d.value(dst, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) > 0 {
+		return nil, fmt.Errorf("%d bytes of trailing data", len(d.data))
+	}
+	return out, nil
+}
+
+// appendCBORHead appends the head of a data item of major type mt with argument v.
+func appendCBORHead(dst []byte, mt byte, v uint64) []byte {
+	switch {
+	case v < 24:
+		return append(dst, mt|byte(v))
+	case v <= math.MaxUint8:
+		return append(dst, mt|24, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, mt|25), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, mt|26), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, mt|27), v)
+	}
+}
+
+func (cborCodec) appendNull(dst []byte) []byte { return append(dst, cborSimple|22) }
+
+func (cborCodec) appendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, cborSimple|21)
+	}
+	return append(dst, cborSimple|20)
+}
+
+func (cborCodec) appendInt(dst []byte, v int64) []byte {
+	if v < 0 {
+		return appendCBORHead(dst, cborNegInt, uint64(-1-v))
+	}
+	return appendCBORHead(dst, cborUint, uint64(v))
+}
+
+func (cborCodec) appendUint(dst []byte, v uint64) []byte {
+	return appendCBORHead(dst, cborUint, v)
+}
+
+func (cborCodec) appendBigInt(dst []byte, v *big.Int) []byte {
+	if v.Sign() < 0 {
+		dst = appendCBORHead(dst, cborTag, cborTagNegBignum)
+		v = new(big.Int).Sub(new(big.Int).Neg(v), big.NewInt(1)) // -1 - v
+	} else {
+		dst = appendCBORHead(dst, cborTag, cborTagPosBignum)
+	}
+	b := v.Bytes()
+	return append(appendCBORHead(dst, cborBytes, uint64(len(b))), b...)
+}
+
+func (cborCodec) appendFloat(dst []byte, v float64) []byte {
+	if f32 := float32(v); float64(f32) == v {
+		return binary.BigEndian.AppendUint32(append(dst, cborSimple|26), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, cborSimple|27), math.Float64bits(v))
+}
+
+func (cborCodec) appendString(dst []byte, v string) []byte {
+	return append(appendCBORHead(dst, cborText, uint64(len(v))), v...)
+}
+
+func (cborCodec) appendArrayHeader(dst []byte, n int) []byte {
+	return appendCBORHead(dst, cborArray, uint64(n))
+}
+
+func (cborCodec) appendMapHeader(dst []byte, n int) []byte {
+	return appendCBORHead(dst, cborMap, uint64(n))
+}
+
+// cborDecoder converts CBOR data items to JSON.
+type cborDecoder struct {
+	data []byte
+}
+
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)) {
+		return nil, errWireTruncated
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// head reads the head of a data item. For indefinite lengths, indefinite is set.
+func (d *cborDecoder) head() (mt byte, info byte, arg uint64, err error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	mt, info = b[0]&0xe0, b[0]&0x1f
+	switch {
+	case info < 24:
+		return mt, info, uint64(info), nil
+	case info <= 27:
+		v, err := d.next(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return mt, info, beUint(v), nil
+	case info == cborIndefinite && mt != cborUint && mt != cborNegInt && mt != cborTag:
+		return mt, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid CBOR head 0x%x", b[0])
+	}
+}
+
+func (d *cborDecoder) value(dst []byte, depth int) ([]byte, error) {
+	if depth > maxWireDepth {
+		return nil, errWireDepth
+	}
+	mt, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch mt {
+	case cborUint:
+		return strconv.AppendUint(dst, arg, 10), nil
+	case cborNegInt:
+		if arg <= math.MaxInt64 {
+			return strconv.AppendInt(dst, -1-int64(arg), 10), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return n.Neg(n).Sub(n, big.NewInt(1)).Append(dst, 10), nil
+	case cborBytes:
+		b, err := d.bytes(mt, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst, base64.StdEncoding.EncodeToString(b)), nil
+	case cborText:
+		b, err := d.bytes(mt, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst, string(b)), nil
+	case cborArray:
+		if info != cborIndefinite && arg > uint64(len(d.data)) {
+			return nil, errWireTruncated // every element takes at least one byte
+		}
+		dst = append(dst, '[')
+		for i := uint64(0); info == cborIndefinite || i < arg; i++ {
+			if info == cborIndefinite && d.atBreak() {
+				break
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = d.value(dst, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case cborMap:
+		if info != cborIndefinite && arg > uint64(len(d.data))/2 {
+			return nil, errWireTruncated
+		}
+		dst = append(dst, '{')
+		for i := uint64(0); info == cborIndefinite || i < arg; i++ {
+			if info == cborIndefinite && d.atBreak() {
+				break
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = d.key(dst); err != nil {
+				return nil, err
+			}
+			dst = append(dst, ':')
+			if dst, err = d.value(dst, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case cborTag:
+		if arg == cborTagPosBignum || arg == cborTagNegBignum {
+			return d.bignum(dst, arg == cborTagNegBignum)
+		}
+		// Other tags only add semantics to the tagged item.
+		return d.value(dst, depth+1)
+	default:
+		return d.simple(dst, info, arg)
+	}
+}
+
+// atBreak reports whether the next byte ends an indefinite-length item, and consumes it.
+func (d *cborDecoder) atBreak() bool {
+	if len(d.data) > 0 && d.data[0] == cborBreak {
+		d.data = d.data[1:]
+		return true
+	}
+	return false
+}
+
+// bytes reads the content of a byte or text string, concatenating the chunks of
+// indefinite-length strings.
+func (d *cborDecoder) bytes(mt, info byte, n uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		return d.next(n)
+	}
+	var out []byte
+	for !d.atBreak() {
+		cmt, cinfo, cn, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if cmt != mt || cinfo == cborIndefinite {
+			return nil, fmt.Errorf("invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.next(cn)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+	return out, nil
+}
+
+func (d *cborDecoder) bignum(dst []byte, negative bool) ([]byte, error) {
+	mt, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if mt != cborBytes {
+		return nil, fmt.Errorf("invalid bignum")
+	}
+	b, err := d.bytes(mt, info, n)
+	if err != nil {
+		return nil, err
+	}
+	v := new(big.Int).SetBytes(b)
+	if negative {
+		v.Neg(v).Sub(v, big.NewInt(1))
+	}
+	return v.Append(dst, 10), nil
+}
+
+func (d *cborDecoder) simple(dst []byte, info byte, arg uint64) ([]byte, error) {
+	switch info {
+	case 20:
+		return append(dst, "false"...), nil
+	case 21:
+		return append(dst, "true"...), nil
+	case 22, 23: // null, undefined
+		return append(dst, "null"...), nil
+	case 25:
+		return appendJSONFloat(dst, float64(halfToFloat32(uint16(arg))))
+	case 26:
+		return appendJSONFloat(dst, float64(math.Float32frombits(uint32(arg))))
+	case 27:
+		return appendJSONFloat(dst, math.Float64frombits(arg))
+	default:
+		return nil, fmt.Errorf("unsupported CBOR simple value %d", arg)
+	}
+}
+
+// key converts a map key. Like encoding/json, integer keys are converted to strings.
+func (d *cborDecoder) key(dst []byte) ([]byte, error) {
+	if len(d.data) == 0 {
+		return nil, errWireTruncated
+	}
+	switch d.data[0] & 0xe0 {
+	case cborText:
+		return d.value(dst, 0)
+	case cborUint, cborNegInt:
+		start := len(dst)
+		dst, err := d.value(dst, 0)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst[:start], string(dst[start:])), nil
+	default:
+		return nil, errWireMapKey
+	}
+}
+
+// halfToFloat32 converts an IEEE 754 half-precision number.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch exp {
+	case 0:
+		// Zero or subnormal.
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+	}
+}
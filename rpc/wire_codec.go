@@ -0,0 +1,335 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WireCodec is an encoding of JSON-RPC messages other than JSON, such as MessagePack or
+// CBOR. Messages are processed as JSON internally, so a wire codec translates messages
+// between JSON and its encoding when they are sent or received. Compact encodings reduce
+// the bandwidth used by numbers, nested structures and binary data.
+//
+// Wire codecs are negotiated per connection or request: over HTTP, the client sends
+// requests with the content type of the codec, and the server responds in kind. Over
+// WebSocket, the codec is selected as the subprotocol of the connection. JSON remains
+// the default, and is used when the other side doesn't support the codec.
+type WireCodec interface {
+	// Name identifies the codec. It is used as the websocket subprotocol, so it must be
+	// a valid HTTP token, e.g. "msgpack".
+	Name() string
+	// ContentType returns the media type of HTTP messages, e.g. "application/msgpack".
+	ContentType() string
+	// FromJSON appends the encoding of the JSON value src to dst.
+	FromJSON(dst, src []byte) ([]byte, error)
+	// ToJSON appends the JSON encoding of the encoded value src to dst.
+	ToJSON(dst, src []byte) ([]byte, error)
+}
+
+// Built-in wire codecs.
+var (
+	// MessagePackCodec encodes messages in MessagePack. JSON integers outside the
+	// 64-bit range are encoded as floating point numbers, losing precision.
+	MessagePackCodec WireCodec = msgpackCodec{}
+
+	// CBORCodec encodes messages in CBOR (RFC 8949). JSON integers outside the 64-bit
+	// range are encoded as bignums.
+	CBORCodec WireCodec = cborCodec{}
+)
+
+const maxWireDepth = 10000 // same as encoding/json
+
+var (
+	errWireTruncated = errors.New("truncated message")
+	errWireDepth     = errors.New("exceeded max depth")
+	errWireMapKey    = errors.New("unsupported map key type")
+	errWireFloat     = errors.New("NaN and infinite numbers can't be converted to JSON")
+)
+
+// SetWireCodecs configures the wire codecs supported by the server in addition to JSON.
+// Over HTTP, requests sent with the content type of a codec are answered using that
+// codec. Over WebSocket, the first codec in this list offered by the client as a
+// subprotocol is used for the connection. Clients select a codec using WithWireCodec.
+//
+// This method should be called before processing any requests via ServeHTTP or
+// WebsocketHandler.
+func (s *Server) SetWireCodecs(codecs ...WireCodec) {
+	s.wireCodecs = codecs
+}
+
+// WithWireCodec makes HTTP and WebSocket clients encode messages using codec instead of
+// JSON. WebSocket clients fall back to JSON if the server doesn't accept the codec as
+// subprotocol. HTTP clients fall back to JSON when the server rejects the content type of
+// the codec, see Server.SetWireCodecs.
+func WithWireCodec(codec WireCodec) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.wireCodec = codec
+	})
+}
+
+// wireCodecForContentType returns the codec for an HTTP content type header value.
+func wireCodecForContentType(ct string, codecs []WireCodec) WireCodec {
+	if len(codecs) == 0 {
+		return nil
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return nil
+	}
+	for _, c := range codecs {
+		if c.ContentType() == mt {
+			return c
+		}
+	}
+	return nil
+}
+
+// wireCodecByName returns the codec with the given name, used as websocket subprotocol.
+func wireCodecByName(name string, codecs []WireCodec) WireCodec {
+	for _, c := range codecs {
+		if name != "" && c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// selectWireCodec picks the codec of a websocket connection from the subprotocols
+// offered by the client.
+func selectWireCodec(offered []string, supported []WireCodec) WireCodec {
+	for _, c := range supported {
+		if slices.Contains(offered, c.Name()) {
+			return c
+		}
+	}
+	return nil
+}
+
+// wireBody returns a reader of the JSON form of the message read from r.
+func wireBody(codec WireCodec, r io.Reader) io.Reader {
+	return &wireReader{codec: codec, r: r}
+}
+
+// wireReader reads a message encoded by a wire codec and provides its JSON form.
+type wireReader struct {
+	codec WireCodec
+	r     io.Reader
+	json  *bytes.Reader
+}
+
+func (wr *wireReader) Read(p []byte) (int, error) {
+	if wr.json == nil {
+		data, err := io.ReadAll(wr.r)
+		if err != nil {
+			return 0, err
+		}
+		if len(data) == 0 {
+			return 0, io.EOF
+		}
+		js, err := wr.codec.ToJSON(nil, data)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s message: %w", wr.codec.Name(), err)
+		}
+		wr.json = bytes.NewReader(js)
+	}
+	return wr.json.Read(p)
+}
+
+// mediaType returns the media type of the HTTP message with header h.
+func mediaType(h http.Header) string {
+	mt, _, _ := mime.ParseMediaType(h.Get("content-type"))
+	return mt
+}
+
+// wireFormat writes values in a wire encoding, see jsonToWire.
+type wireFormat interface {
+	appendNull(dst []byte) []byte
+	appendBool(dst []byte, v bool) []byte
+	appendInt(dst []byte, v int64) []byte
+	appendUint(dst []byte, v uint64) []byte
+	appendBigInt(dst []byte, v *big.Int) []byte
+	appendFloat(dst []byte, v float64) []byte
+	appendString(dst []byte, v string) []byte
+	appendArrayHeader(dst []byte, n int) []byte
+	appendMapHeader(dst []byte, n int) []byte
+}
+
+// jsonToWire appends the encoding of the JSON value src in format f to dst.
+func jsonToWire(dst, src []byte, f wireFormat) ([]byte, error) {
+	t := &jsonTranscoder{dec: json.NewDecoder(bytes.NewReader(src)), f: f, out: dst}
+	t.dec.UseNumber()
+	tok, err := t.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.value(tok); err != nil {
+		return nil, err
+	}
+	if _, err := t.dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: trailing data")
+	}
+	return t.out, nil
+}
+
+type jsonTranscoder struct {
+	dec    *json.Decoder
+	f      wireFormat
+	out    []byte
+	header []byte
+}
+
+func (t *jsonTranscoder) value(tok json.Token) error {
+	switch v := tok.(type) {
+	case nil:
+		t.out = t.f.appendNull(t.out)
+	case bool:
+		t.out = t.f.appendBool(t.out, v)
+	case string:
+		t.out = t.f.appendString(t.out, v)
+	case json.Number:
+		t.out = t.number(v)
+	case json.Delim:
+		start, n := len(t.out), 0
+		for t.dec.More() {
+			tok, err := t.dec.Token()
+			if err != nil {
+				return err
+			}
+			if v == '{' {
+				t.out = t.f.appendString(t.out, tok.(string))
+				if tok, err = t.dec.Token(); err != nil {
+					return err
+				}
+			}
+			if err := t.value(tok); err != nil {
+				return err
+			}
+			n++
+		}
+		if _, err := t.dec.Token(); err != nil {
+			return err
+		}
+		// The length of the container precedes its elements.
+		if v == '{' {
+			t.header = t.f.appendMapHeader(t.header[:0], n)
+		} else {
+			t.header = t.f.appendArrayHeader(t.header[:0], n)
+		}
+		t.out = slices.Insert(t.out, start, t.header...)
+	}
+	return nil
+}
+
+func (t *jsonTranscoder) number(n json.Number) []byte {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return t.f.appendInt(t.out, v)
+		}
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return t.f.appendUint(t.out, v)
+		}
+		if v, ok := new(big.Int).SetString(s, 10); ok {
+			return t.f.appendBigInt(t.out, v)
+		}
+	}
+	v, _ := strconv.ParseFloat(s, 64) // out of range values become infinite
+	return t.f.appendFloat(t.out, v)
+}
+
+// appendJSONString appends s as a JSON string, escaping it like encoding/json, except
+// for HTML characters. Invalid UTF-8 is replaced by U+FFFD.
+func appendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// appendJSONFloat appends f as a JSON number, formatted like encoding/json.
+func appendJSONFloat(dst []byte, f float64) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, errWireFloat
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst, nil
+}
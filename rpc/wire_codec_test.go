@@ -0,0 +1,300 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var wireTestValues = []string{
+	`null`,
+	`true`,
+	`false`,
+	`0`,
+	`-1`,
+	`-33`,
+	`255`,
+	`-32769`,
+	`4294967296`,
+	`9223372036854775807`,
+	`18446744073709551615`,
+	`-9223372036854775808`,
+	`1.5`,
+	`0.1`,
+	`1e-7`,
+	`""`,
+	`"\u0000\n\"\\ ünïcødé  "`,
+	`"` + strings.Repeat("x", 70000) + `"`,
+	`[]`,
+	`{}`,
+	`[1,[2,[3,{"a":[]}]]]`,
+	`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1,{"S":"y"}]}`,
+}
+
+func TestWireCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	large := make([]int, 70000)
+	largeJSON, _ := json.Marshal(large)
+	values := append(wireTestValues, string(largeJSON))
+	for _, codec := range []WireCodec{MessagePackCodec, CBORCodec} {
+		for _, v := range values {
+			enc, err := codec.FromJSON(nil, []byte(v))
+			if err != nil {
+				t.Fatalf("%s: can't encode %.40s: %v", codec.Name(), v, err)
+			}
+			dec, err := codec.ToJSON(nil, enc)
+			if err != nil {
+				t.Fatalf("%s: can't decode %.40s: %v", codec.Name(), v, err)
+			}
+			if !jsonEqual(t, dec, []byte(v)) {
+				t.Errorf("%s: round trip of %.40s returned %.40s", codec.Name(), v, dec)
+			}
+		}
+	}
+}
+
+func TestWireCodecBigIntegers(t *testing.T) {
+	t.Parallel()
+
+	// CBOR represents large integers exactly, MessagePack approximates them.
+	big := `[18446744073709551616,-18446744073709551617]`
+	enc, err := CBORCodec.FromJSON(nil, []byte(big))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "82c249010000000000000000c349010000000000000000"; hex.EncodeToString(enc) != want {
+		t.Fatalf("wrong CBOR encoding %x, want %s", enc, want)
+	}
+	if dec, _ := CBORCodec.ToJSON(nil, enc); string(dec) != big {
+		t.Fatalf("wrong CBOR round trip %s", dec)
+	}
+	enc, err = MessagePackCodec.FromJSON(nil, []byte(big))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec, _ := MessagePackCodec.ToJSON(nil, enc); string(dec) != `[18446744073709552000,-18446744073709552000]` {
+		t.Fatalf("wrong MessagePack round trip %s", dec)
+	}
+}
+
+func TestWireCodecDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		codec WireCodec
+		input string // hex
+		want  string
+	}{
+		// MessagePack
+		{MessagePackCodec, "81a16101", `{"a":1}`},
+		{MessagePackCodec, "c403010203", `"AQID"`},      // bin
+		{MessagePackCodec, "8101a162", `{"1":"b"}`},     // integer key
+		{MessagePackCodec, "d0ff", `-1`},                // int8
+		{MessagePackCodec, "ca3fc00000", `1.5`},         // float32
+		{MessagePackCodec, "dc0002c0c3", `[null,true]`}, // array16
+		{MessagePackCodec, "d9036162636465", ``},        // trailing data
+		{MessagePackCodec, "dd7fffffff", ``},            // length beyond input
+		{MessagePackCodec, "d40100", ``},                // ext
+		{MessagePackCodec, "81c0c0", ``},                // nil key
+		{MessagePackCodec, "cb7ff0000000000000", ``},    // +Inf
+		{MessagePackCodec, "a3616263", `"abc"`},         // fixstr
+		{MessagePackCodec, "a2ff41", `"\ufffdA"`},       // invalid UTF-8
+		// CBOR
+		{CBORCodec, "a1616101", `{"a":1}`},
+		{CBORCodec, "9f0102ff", `[1,2]`},                           // indefinite array
+		{CBORCodec, "bf6161f5ff", `{"a":true}`},                    // indefinite map
+		{CBORCodec, "7f61616162ff", `"ab"`},                        // indefinite text
+		{CBORCodec, "f93e00", `1.5`},                               // half float
+		{CBORCodec, "f97c00", ``},                                  // half float +Inf
+		{CBORCodec, "c11a514b67b0", `1363896240`},                  // epoch time tag
+		{CBORCodec, "4401020304", `"AQIDBA=="`},                    // bytes
+		{CBORCodec, "f7", `null`},                                  // undefined
+		{CBORCodec, "3bffffffffffffffff", `-18446744073709551616`}, // negint beyond int64
+		{CBORCodec, "9b00000000ffffffff", ``},                      // length beyond input
+		{CBORCodec, "a1f6f6", ``},                                  // null key
+		{CBORCodec, "1f", ``},                                      // indefinite integer
+		{CBORCodec, "9f01", ``},                                    // missing break
+		{CBORCodec, "f8ff", ``},                                    // simple value
+	}
+	for _, test := range tests {
+		input, _ := hex.DecodeString(test.input)
+		out, err := test.codec.ToJSON(nil, input)
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("%s %s: expected error, got %s", test.codec.Name(), test.input, out)
+		case test.want != "" && err != nil:
+			t.Errorf("%s %s: error %v", test.codec.Name(), test.input, err)
+		case test.want != "" && string(out) != test.want:
+			t.Errorf("%s %s: got %s, want %s", test.codec.Name(), test.input, out, test.want)
+		}
+	}
+}
+
+func TestWireCodecDepthLimit(t *testing.T) {
+	t.Parallel()
+
+	for _, codec := range []WireCodec{MessagePackCodec, CBORCodec} {
+		input := bytes.Repeat([]byte{0x91}, maxWireDepth+10) // msgpack array of 1
+		if codec == CBORCodec {
+			input = bytes.Repeat([]byte{0x81}, maxWireDepth+10)
+		}
+		if _, err := codec.ToJSON(nil, input); err != errWireDepth {
+			t.Errorf("%s: wrong error %v", codec.Name(), err)
+		}
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("invalid JSON %.40s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("invalid JSON %.40s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func checkWireCalls(t *testing.T, client *Client) {
+	t.Helper()
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "x ", 3, &echoArgs{S: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, echoResult{"x ", 3, &echoArgs{"y"}}) {
+		t.Fatalf("wrong result %+v", res)
+	}
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []interface{}{"a", 1, nil}, Result: new(echoResult)},
+		{Method: "test_returnError", Result: new(interface{})},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil || batch[0].Result.(*echoResult).String != "a" {
+		t.Fatalf("wrong batch result %+v %v", batch[0].Result, batch[0].Error)
+	}
+	if batch[1].Error == nil {
+		t.Fatal("expected error in batch")
+	}
+}
+
+func TestWireCodecHTTP(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetWireCodecs(MessagePackCodec, CBORCodec)
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	for _, codec := range []WireCodec{MessagePackCodec, CBORCodec} {
+		var contentTypes []string
+		hc := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			resp, err := http.DefaultTransport.RoundTrip(r)
+			if err == nil {
+				contentTypes = append(contentTypes, mediaType(r.Header)+" "+mediaType(resp.Header))
+			}
+			return resp, err
+		})}
+		client, err := DialOptions(context.Background(), httpsrv.URL, WithWireCodec(codec), WithHTTPClient(hc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkWireCalls(t, client)
+		client.Close()
+		want := codec.ContentType() + " " + codec.ContentType()
+		if len(contentTypes) != 2 || contentTypes[0] != want || contentTypes[1] != want {
+			t.Fatalf("%s: wrong content types %q", codec.Name(), contentTypes)
+		}
+	}
+}
+
+func TestWireCodecHTTPFallback(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	client, err := DialOptions(context.Background(), httpsrv.URL, WithWireCodec(CBORCodec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	checkWireCalls(t, client)
+	if !client.writeConn.(*httpConn).wireRejected.Load() {
+		t.Fatal("client didn't fall back to JSON")
+	}
+}
+
+func TestWireCodecWebsocket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetWireCodecs(CBORCodec)
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+	url := "ws:" + strings.TrimPrefix(wssrv.URL, "http:")
+	for _, codec := range []WireCodec{CBORCodec, MessagePackCodec} {
+		client, err := DialOptions(context.Background(), url, WithWireCodec(codec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The server only supports CBOR, MessagePack clients use JSON.
+		negotiated := client.writeConn.(*websocketCodec).conn.Subprotocol()
+		if want := map[WireCodec]string{CBORCodec: "cbor", MessagePackCodec: ""}[codec]; negotiated != want {
+			t.Fatalf("negotiated %q, want %q", negotiated, want)
+		}
+		checkWireCalls(t, client)
+
+		ch := make(chan int)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		sub, err := client.Subscribe(ctx, "nftest", ch, "someSubscription", 3, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			select {
+			case v := <-ch:
+				if v != 10+i {
+					t.Fatalf("wrong notification %d", v)
+				}
+			case <-ctx.Done():
+				t.Fatal("notification timeout")
+			}
+		}
+		sub.Unsubscribe()
+		cancel()
+		client.Close()
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return fn(r) }
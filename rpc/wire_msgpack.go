@@ -0,0 +1,324 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// msgpackCodec implements MessagePackCodec.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string        { return "msgpack" }
+func (msgpackCodec) ContentType() string { return "application/msgpack" }
+
+func (c msgpackCodec) FromJSON(dst, src []byte) ([]byte, error) {
+	return jsonToWire(dst, src, c)
+}
+
+func (msgpackCodec) ToJSON(dst, src []byte) ([]byte, error) {
+	d := msgpackDecoder{data: src}
+	out, err := d.value(dst, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) > 0 {
+		return nil, fmt.Errorf("%d bytes of trailing data", len(d.data))
+	}
+	return out, nil
+}
+
+func (msgpackCodec) appendNull(dst []byte) []byte { return append(dst, 0xc0) }
+
+func (msgpackCodec) appendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 0xc3)
+	}
+	return append(dst, 0xc2)
+}
+
+func (c msgpackCodec) appendInt(dst []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return c.appendUint(dst, uint64(v))
+	case v >= -32:
+		return append(dst, byte(v))
+	case v >= math.MinInt8:
+		return append(dst, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(v))
+	}
+}
+
+func (msgpackCodec) appendUint(dst []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(dst, byte(v))
+	case v <= math.MaxUint8:
+		return append(dst, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), v)
+	}
+}
+
+func (c msgpackCodec) appendBigInt(dst []byte, v *big.Int) []byte {
+	f, _ := new(big.Float).SetInt(v).Float64()
+	return c.appendFloat(dst, f)
+}
+
+func (msgpackCodec) appendFloat(dst []byte, v float64) []byte {
+	if f32 := float32(v); float64(f32) == v {
+		return binary.BigEndian.AppendUint32(append(dst, 0xca), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(v))
+}
+
+func (msgpackCodec) appendString(dst []byte, v string) []byte {
+	switch n := len(v); {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+	return append(dst, v...)
+}
+
+func (msgpackCodec) appendArrayHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, 0xdd), uint32(n))
+	}
+}
+
+func (msgpackCodec) appendMapHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, 0xdf), uint32(n))
+	}
+}
+
+// msgpackDecoder converts MessagePack values to JSON.
+type msgpackDecoder struct {
+	data []byte
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data) < n {
+		return nil, errWireTruncated
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// length reads a big-endian length of size bytes.
+func (d *msgpackDecoder) length(size int) (int, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, x := range b {
+		n = n<<8 | uint64(x)
+	}
+	if n > uint64(len(d.data)) {
+		return 0, errWireTruncated // every element takes at least one byte
+	}
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) value(dst []byte, depth int) ([]byte, error) {
+	if depth > maxWireDepth {
+		return nil, errWireDepth
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	switch t := b[0]; {
+	case t <= 0x7f:
+		return strconv.AppendUint(dst, uint64(t), 10), nil
+	case t >= 0xe0:
+		return strconv.AppendInt(dst, int64(int8(t)), 10), nil
+	case t >= 0xa0 && t <= 0xbf:
+		return d.str(dst, int(t&0x1f))
+	case t >= 0x90 && t <= 0x9f:
+		return d.array(dst, int(t&0x0f), depth)
+	case t >= 0x80 && t <= 0x8f:
+		return d.object(dst, int(t&0x0f), depth)
+	}
+	switch t := b[0]; t {
+	case 0xc0:
+		return append(dst, "null"...), nil
+	case 0xc2:
+		return append(dst, "false"...), nil
+	case 0xc3:
+		return append(dst, "true"...), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.next(1 << (t - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendUint(dst, beUint(v), 10), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (t - 0xd0)
+		v, err := d.next(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size
+		return strconv.AppendInt(dst, int64(beUint(v)<<shift)>>shift, 10), nil
+	case 0xca:
+		v, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONFloat(dst, float64(math.Float32frombits(uint32(beUint(v)))))
+	case 0xcb:
+		v, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONFloat(dst, math.Float64frombits(beUint(v)))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (t - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(dst, n)
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (t - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst, base64.StdEncoding.EncodeToString(v)), nil
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (t - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(dst, n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (t - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(dst, n, depth)
+	default:
+		return nil, fmt.Errorf("unsupported msgpack type 0x%x", t)
+	}
+}
+
+func (d *msgpackDecoder) str(dst []byte, n int) ([]byte, error) {
+	v, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return appendJSONString(dst, string(v)), nil
+}
+
+func (d *msgpackDecoder) array(dst []byte, n int, depth int) ([]byte, error) {
+	dst = append(dst, '[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = d.value(dst, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+func (d *msgpackDecoder) object(dst []byte, n int, depth int) ([]byte, error) {
+	dst = append(dst, '{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = d.key(dst); err != nil {
+			return nil, err
+		}
+		dst = append(dst, ':')
+		if dst, err = d.value(dst, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// key converts a map key. Like encoding/json, integer keys are converted to strings.
+func (d *msgpackDecoder) key(dst []byte) ([]byte, error) {
+	if len(d.data) == 0 {
+		return nil, errWireTruncated
+	}
+	t := d.data[0]
+	isString := t >= 0xa0 && t <= 0xbf || t >= 0xd9 && t <= 0xdb
+	isInt := t <= 0x7f || t >= 0xe0 || t >= 0xcc && t <= 0xd3
+	switch {
+	case isString:
+		return d.value(dst, 0)
+	case isInt:
+		start := len(dst)
+		dst, err := d.value(dst, 0)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst[:start], string(dst[start:])), nil
+	default:
+		return nil, errWireMapKey
+	}
+}
+
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return v
+}